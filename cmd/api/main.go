@@ -8,6 +8,7 @@ import (
 	"github.com/digitalmonsters/litit-mono/internal/ads"
 	"github.com/digitalmonsters/litit-mono/internal/comments"
 	"github.com/digitalmonsters/litit-mono/internal/configurator"
+	"github.com/digitalmonsters/litit-mono/internal/music"
 	"github.com/digitalmonsters/litit-mono/internal/notifications"
 	"github.com/digitalmonsters/litit-mono/internal/user"
 	"github.com/go-chi/chi/v5"
@@ -16,6 +17,9 @@ import (
 var Version = "dev"
 
 func main() {
+	configurator.Migrate()
+	music.Migrate()
+
 	r := chi.NewRouter()
 
 	// health & version
@@ -33,6 +37,7 @@ func main() {
 		notifications.RegisterRoutes(v)
 		comments.RegisterRoutes(v)
 		ads.RegisterRoutes(v)
+		music.RegisterRoutes(v)
 	})
 
 	log.Println("listening on :8080")
@@ -13,3 +13,5 @@ replace github.com/digitalmonsters/comments => ./internal/comments
 replace github.com/digitalmonsters/notification-handler => ./internal/notifications
 
 replace github.com/digitalmonsters/configurator => ./internal/configurator
+
+replace github.com/digitalmonsters/music => ./internal/music
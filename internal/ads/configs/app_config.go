@@ -19,7 +19,7 @@ func GetAppConfig() AppConfig {
 		return boilerplate_testing.GetMockAppConfig(mockAppConfigs)
 	}
 
-	return cfgService.Values
+	return cfgService.GetValues()
 }
 
 type AppConfig struct {
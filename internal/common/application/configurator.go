@@ -2,9 +2,13 @@ package application
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"reflect"
 	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/hashicorp/go-multierror"
 	"github.com/pkg/errors"
@@ -12,25 +16,212 @@ import (
 )
 
 type Configurator[T any] struct {
-	builder ConfiguratorBuilder[T]
-	raw     map[string]string
-	keys    []string
-	Values  T
+	builder   ConfiguratorBuilder[T]
+	raw       map[string]string
+	keys      []string
+	fields    []configField
+	Values    T // kept for compatibility, the field is replaced on refresh, so read it with GetValues
+	mutex     sync.RWMutex
+	callbacks map[string][]func(change ConfigChange[T])
+	status    ConfiguratorStatus
+}
+
+// ConfigChange is passed to OnChange callbacks after a new value has been applied
+type ConfigChange[T any] struct {
+	Key      string
+	OldValue string
+	NewValue string
+	Values   T
+}
+
+type ConfiguratorStatus struct {
+	LastRefreshAt time.Time `json:"last_refresh_at"`
+	LastEventAt   time.Time `json:"last_event_at"`
+	LastError     string    `json:"last_error"`
+	LastErrorAt   time.Time `json:"last_error_at"`
+}
+
+// GetValues returns current snapshot of the config. Values are replaced on refresh, so read it once per operation
+// when several fields should be consistent
+func (c *Configurator[T]) GetValues() T {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	return c.Values
 }
 
 func (c *Configurator[T]) GetRawData() map[string]string {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
 	return c.raw
 }
 
+// OnChange registers fn to be called every time the value of key changes, either by polling or by config_upsert event
+func (c *Configurator[T]) OnChange(key string, fn func(change ConfigChange[T])) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.callbacks == nil {
+		c.callbacks = map[string][]func(change ConfigChange[T]){}
+	}
+
+	c.callbacks[key] = append(c.callbacks[key], fn)
+}
+
+func (c *Configurator[T]) Status() ConfiguratorStatus {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	return c.status
+}
+
+// Apply sets a single key received from config_upsert event. Keys which are not part of T are ignored.
+// Copy, merge and swap are done under one write lock, so concurrent events and refreshes do not lose updates
+func (c *Configurator[T]) Apply(key string, value string) error {
+	if !c.hasKey(key) {
+		return nil
+	}
+
+	c.mutex.Lock()
+
+	updated := make(map[string]string, len(c.raw))
+	for k, v := range c.raw {
+		updated[k] = v
+	}
+
+	updated[key] = value
+
+	toCall, err := c.applyLocked(updated)
+	if err != nil {
+		c.setErrorLocked(err)
+		c.mutex.Unlock()
+
+		return err
+	}
+
+	c.status.LastEventAt = time.Now().UTC()
+	c.mutex.Unlock()
+
+	for _, fn := range toCall { // outside of lock, so callbacks can read configurator
+		fn()
+	}
+
+	return nil
+}
+
+func (c *Configurator[T]) hasKey(key string) bool {
+	for _, k := range c.keys {
+		if k == key {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c *Configurator[T]) setError(err error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.setErrorLocked(err)
+}
+
+func (c *Configurator[T]) setErrorLocked(err error) {
+	c.status.LastError = err.Error()
+	c.status.LastErrorAt = time.Now().UTC()
+}
+
+// applyLocked swaps values and returns OnChange callbacks to call after unlock. Caller should hold write lock
+func (c *Configurator[T]) applyLocked(inputData map[string]string) ([]func(), error) {
+	old := c.raw
+
+	if err := c.setValues(inputData); err != nil {
+		return nil, err
+	}
+
+	values := c.Values
+
+	var toCall []func()
+
+	for _, key := range c.keys {
+		oldValue, existed := old[key]
+		newValue := inputData[key]
+
+		if old == nil || (existed && oldValue == newValue) {
+			continue
+		}
+
+		change := ConfigChange[T]{
+			Key:      key,
+			OldValue: oldValue,
+			NewValue: newValue,
+			Values:   values,
+		}
+
+		for _, fn := range c.callbacks[key] {
+			callback := fn
+			toCall = append(toCall, func() { callback(change) })
+		}
+	}
+
+	return toCall, nil
+}
+
+// configField describes how struct field is mapped to config key.
+// Supported tags:
+//
+//	config:"KEY_NAME"          - overrides key name, by default field name is used
+//	config:"KEY_NAME,optional" - missing key is not an error, field keeps zero or default value
+//	default:"value"            - value used when key is missing, makes key optional
+type configField struct {
+	index        int
+	key          string
+	optional     bool
+	defaultValue *string
+}
+
+func parseConfigField(index int, fieldData reflect.StructField) configField {
+	result := configField{
+		index: index,
+		key:   fieldData.Name,
+	}
+
+	if tag, ok := fieldData.Tag.Lookup("config"); ok {
+		parts := strings.Split(tag, ",")
+
+		if name := strings.TrimSpace(parts[0]); len(name) > 0 {
+			result.key = name
+		}
+
+		for _, option := range parts[1:] {
+			switch strings.TrimSpace(option) {
+			case "optional":
+				result.optional = true
+			case "required":
+				result.optional = false
+			}
+		}
+	}
+
+	if defaultValue, ok := fieldData.Tag.Lookup("default"); ok {
+		result.defaultValue = &defaultValue
+		result.optional = true
+	}
+
+	return result
+}
+
 func (c *Configurator[T]) init() {
 	var updated T
 
 	typeData := reflect.TypeOf(updated)
 
 	for i := 0; i < typeData.NumField(); i++ {
-		fieldData := typeData.Field(i)
+		field := parseConfigField(i, typeData.Field(i))
 
-		c.keys = append(c.keys, fieldData.Name)
+		c.fields = append(c.fields, field)
+		c.keys = append(c.keys, field.key)
 	}
 }
 
@@ -39,20 +230,22 @@ func (c *Configurator[T]) setValues(inputData map[string]string) error {
 
 	var err error
 
-	typeData := reflect.TypeOf(updated)
-
-	for i := 0; i < typeData.NumField(); i++ {
-		fieldData := typeData.Field(i)
-
-		inputField, ok := inputData[fieldData.Name]
+	for _, field := range c.fields {
+		inputField, ok := inputData[field.key]
 
 		if !ok {
-			err = multierror.Append(err, errors.New(fmt.Sprintf("key [%v] not found", fieldData.Name)))
+			if field.defaultValue != nil {
+				inputField = *field.defaultValue
+			} else if field.optional {
+				continue
+			} else {
+				err = multierror.Append(err, errors.New(fmt.Sprintf("key [%v] not found", field.key)))
 
-			continue
+				continue
+			}
 		}
 
-		if parseErr := c.setValue(&updated, fieldData.Name, inputField); parseErr != nil {
+		if parseErr := c.setValue(&updated, field, inputField); parseErr != nil {
 			err = multierror.Append(err, parseErr)
 		}
 	}
@@ -67,30 +260,27 @@ func (c *Configurator[T]) setValues(inputData map[string]string) error {
 	return err
 }
 
-func (c Configurator[T]) setValue(instance *T, key string, value string) error {
-	typeData := reflect.TypeOf(instance).Elem()
+func (c *Configurator[T]) setValue(instance *T, field configField, value string) error {
+	fieldData := reflect.TypeOf(instance).Elem().Field(field.index)
+	fieldSetData := reflect.ValueOf(instance).Elem().Field(field.index)
 
-	for i := 0; i < typeData.NumField(); i++ {
-		fieldData := typeData.Field(i)
-
-		if key == fieldData.Name {
-			fieldSetData := reflect.ValueOf(instance).Elem().Field(i)
-			if !fieldSetData.CanSet() {
-				return errors.New(fmt.Sprintf("field %v can not be set by reflection", fieldData.Name))
-			}
+	if !fieldSetData.CanSet() {
+		return errors.New(fmt.Sprintf("field %v can not be set by reflection", fieldData.Name))
+	}
 
-			if parsedValue, err := c.parseValue(fieldData, value); err != nil {
-				return err
-			} else {
-				fieldSetData.Set(parsedValue)
-			}
-		}
+	parsedValue, err := c.parseValue(fieldData, field.key, value)
+	if err != nil {
+		return err
 	}
 
+	fieldSetData.Set(parsedValue)
+
 	return nil
 }
 
-func (c Configurator[T]) parseValue(fieldData reflect.StructField, value string) (reflect.Value, error) {
+// parseValue converts raw value of the key to the field type. Flags get the config key, not the field name, since the key
+// is the bucket seed of percentage rollout and has to be the same in every service
+func (c *Configurator[T]) parseValue(fieldData reflect.StructField, key string, value string) (reflect.Value, error) {
 	typeName := fieldData.Type.String()
 
 	switch typeName {
@@ -109,6 +299,30 @@ func (c Configurator[T]) parseValue(fieldData reflect.StructField, value string)
 		}
 	case "string":
 		return reflect.ValueOf(value), nil
+	case "application.FeatureFlag":
+		if parsed, err := ParseFeatureFlag(key, value); err != nil {
+			return reflect.Value{}, err
+		} else {
+			return reflect.ValueOf(parsed), nil
+		}
+	case "time.Duration":
+		if parsed, err := parseDuration(value); err != nil {
+			return reflect.Value{}, errors.New(fmt.Sprintf("field %v can not be parsed to %v because of error: %v",
+				fieldData.Name, typeName, err.Error()))
+		} else {
+			return reflect.ValueOf(parsed), nil
+		}
+	case "float32":
+		fallthrough
+	case "float64":
+		if parsed, err := strconv.ParseFloat(value, 64); err != nil {
+			return reflect.Value{}, errors.WithStack(err)
+		} else {
+			if typeName == "float32" {
+				return reflect.ValueOf(float32(parsed)), nil
+			}
+			return reflect.ValueOf(parsed), nil
+		}
 	case "int64":
 		fallthrough
 	case "int":
@@ -120,16 +334,106 @@ func (c Configurator[T]) parseValue(fieldData reflect.StructField, value string)
 			}
 			return reflect.ValueOf(parsed), nil
 		}
+	case "[]string":
+		if !isJsonArray(value) {
+			return reflect.ValueOf(splitList(value)), nil
+		}
+	case "[]int64":
+		if !isJsonArray(value) {
+			var result []int64
+
+			for _, item := range splitList(value) {
+				parsed, err := strconv.ParseInt(item, 10, 64)
+				if err != nil {
+					return reflect.Value{}, errors.New(fmt.Sprintf("field %v can not be parsed to %v because of error: %v",
+						fieldData.Name, typeName, err.Error()))
+				}
+
+				result = append(result, parsed)
+			}
+
+			return reflect.ValueOf(result), nil
+		}
+	}
+
+	switch fieldData.Type.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Ptr:
+		return parseJsonValue(fieldData, value)
 	default:
 		return reflect.Value{}, errors.New(fmt.Sprintf("field %v has unsupported type by parser %v", fieldData.Name, typeName))
 	}
 }
 
+// parseDuration accepts go duration format (1m30s) or plain integer as seconds
+func parseDuration(value string) (time.Duration, error) {
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Duration(seconds) * time.Second, nil
+	}
+
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+
+	return parsed, nil
+}
+
+func isJsonArray(value string) bool {
+	return strings.HasPrefix(strings.TrimSpace(value), "[")
+}
+
+func splitList(value string) []string {
+	var result []string
+
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); len(item) > 0 {
+			result = append(result, item)
+		}
+	}
+
+	return result
+}
+
+func parseJsonValue(fieldData reflect.StructField, value string) (reflect.Value, error) {
+	target := reflect.New(fieldData.Type)
+
+	if len(strings.TrimSpace(value)) == 0 {
+		return target.Elem(), nil
+	}
+
+	if err := json.Unmarshal([]byte(value), target.Interface()); err != nil {
+		return reflect.Value{}, errors.New(fmt.Sprintf("field %v can not be parsed to %v because of error: %v",
+			fieldData.Name, fieldData.Type.String(), err.Error()))
+	}
+
+	return target.Elem(), nil
+}
+
 func (c *Configurator[T]) Refresh(ctx context.Context) error {
 	values, err := c.builder.retriever.Retrieve(c.keys, ctx)
 	if err != nil {
-		return fmt.Errorf("refresh err: %s", err.Error())
+		err = fmt.Errorf("refresh err: %s", err.Error())
+		c.setError(err)
+
+		return err
+	}
+
+	c.mutex.Lock()
+
+	toCall, err := c.applyLocked(values)
+	if err != nil {
+		c.setErrorLocked(err)
+		c.mutex.Unlock()
+
+		return err
 	}
 
-	return c.setValues(values)
+	c.status.LastRefreshAt = time.Now().UTC()
+	c.mutex.Unlock()
+
+	for _, fn := range toCall {
+		fn()
+	}
+
+	return nil
 }
@@ -2,13 +2,18 @@ package application
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"time"
 
 	"github.com/digitalmonsters/go-common/apm_helper"
 	"github.com/digitalmonsters/go-common/boilerplate"
+	"github.com/digitalmonsters/go-common/eventsourcing"
+	"github.com/digitalmonsters/go-common/kafka_listener"
+	"github.com/digitalmonsters/go-common/ops"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
+	"github.com/segmentio/kafka-go"
 )
 
 type ConfiguratorBuilder[T any] struct {
@@ -17,6 +22,10 @@ type ConfiguratorBuilder[T any] struct {
 	migrator    Migrator
 	logger      zerolog.Logger
 	interval    time.Duration
+	kafkaCfg    *boilerplate.KafkaListenerConfiguration
+	ctx         context.Context
+	statusSrv   *ops.PrivateHttpServer
+	statusName  string
 }
 
 func NewConfigurator[T any]() *ConfiguratorBuilder[T] {
@@ -40,6 +49,23 @@ func (c ConfiguratorBuilder[T]) WithRetriever(retriever Retriever) ConfiguratorB
 	return c
 }
 
+// WithKafkaListener subscribes configurator to config_upsert events, so changes are applied without waiting for interval.
+// GroupId should be empty, then every instance reads all partitions and receives every event.
+func (c ConfiguratorBuilder[T]) WithKafkaListener(cfg boilerplate.KafkaListenerConfiguration, ctx context.Context) ConfiguratorBuilder[T] {
+	c.kafkaCfg = &cfg
+	c.ctx = ctx
+
+	return c
+}
+
+// WithStatusProvider exposes configurator Status under name on /status of the private http server
+func (c ConfiguratorBuilder[T]) WithStatusProvider(server *ops.PrivateHttpServer, name string) ConfiguratorBuilder[T] {
+	c.statusSrv = server
+	c.statusName = name
+
+	return c
+}
+
 func (c ConfiguratorBuilder[T]) WithMigrator(migrator Migrator, configsMap map[string]MigrateConfigModel) ConfiguratorBuilder[T] {
 	c.migrator = migrator
 	c.migrator.SetMigratorMap(configsMap)
@@ -54,13 +80,15 @@ func (c ConfiguratorBuilder[T]) MustInit() *Configurator[T] {
 		c.logger.Panic().Err(errors.New("configuration client already initialized")).Msg("[SERVICE] : configurator failed")
 	}
 
-	result := Configurator[T]{builder: c}
+	result := &Configurator[T]{builder: c}
 
-	resp, err := c.migrator.Migrate(context.Background())
-	if err != nil {
-		c.logger.Panic().Err(errors.New("migrate failed - " + err.Error())).Msg("[SERVICE] : configurator failed")
-	} else {
-		c.logger.Info().Interface("value", resp).Msg("[SERVICE] : configurator migration successful")
+	if c.migrator != nil {
+		resp, err := c.migrator.Migrate(context.Background())
+		if err != nil {
+			c.logger.Panic().Err(errors.New("migrate failed - " + err.Error())).Msg("[SERVICE] : configurator failed")
+		} else {
+			c.logger.Info().Interface("value", resp).Msg("[SERVICE] : configurator migration successful")
+		}
 	}
 
 	result.init()
@@ -69,7 +97,13 @@ func (c ConfiguratorBuilder[T]) MustInit() *Configurator[T] {
 		c.logger.Panic().Err(errors.New("result failed - " + err.Error())).Msg("[SERVICE] : configurator failed")
 	}
 
-	c.logger.Info().Interface("value", result.Values).Msg("[SERVICE] : configurator successful")
+	c.logger.Info().Interface("value", result.GetValues()).Msg("[SERVICE] : configurator successful")
+
+	if c.statusSrv != nil {
+		c.statusSrv.AddStatusProvider(c.statusName, func() interface{} {
+			return result.Status()
+		})
+	}
 
 	if c.interval > 0 {
 		c.logger.Info().Msgf("starting configuration watcher with interval [%v]", c.interval)
@@ -85,7 +119,7 @@ func (c ConfiguratorBuilder[T]) MustInit() *Configurator[T] {
 					apmTx, log.Logger)
 
 				if err := result.Refresh(ctx); err != nil {
-					c.logger.Panic().Err(errors.New("result failed - " + err.Error())).Msg("[SERVICE] : configurator periodic failed")
+					c.logger.Error().Err(errors.New("result failed - " + err.Error())).Msg("[SERVICE] : configurator periodic failed")
 					apm_helper.LogError(err, ctx)
 					apmTx.End()
 					continue
@@ -96,5 +130,37 @@ func (c ConfiguratorBuilder[T]) MustInit() *Configurator[T] {
 		}()
 	}
 
-	return &result
+	if c.kafkaCfg != nil {
+		c.logger.Info().Msgf("starting configuration listener for topic [%v]", c.kafkaCfg.Topic)
+
+		kafka_listener.NewSingleListener(*c.kafkaCfg, kafka_listener.NewCommand("configurator",
+			result.listenerCommand(), false), c.ctx).ListenAsync()
+	}
+
+	return result
+}
+
+func (c *Configurator[T]) listenerCommand() kafka_listener.CommandFunc {
+	return func(executionData kafka_listener.ExecutionData, request ...kafka.Message) []kafka.Message {
+		var successfullyProcessed []kafka.Message
+
+		for _, message := range request {
+			var event eventsourcing.ConfigEvent
+
+			if err := json.Unmarshal(message.Value, &event); err != nil {
+				apm_helper.LogError(err, executionData.Context)
+				successfullyProcessed = append(successfullyProcessed, message)
+
+				continue
+			}
+
+			if err := c.Apply(event.Key, event.Value); err != nil {
+				apm_helper.LogError(err, executionData.Context)
+			}
+
+			successfullyProcessed = append(successfullyProcessed, message)
+		}
+
+		return successfullyProcessed
+	}
 }
@@ -10,7 +10,7 @@ import (
 )
 
 const (
-	HttpMigratorDefaultUrl = "http://configurator/internal/json/migrator"
+	HttpMigratorDefaultUrl = "http://configurator/v1/config/internal/json/migrator"
 )
 
 type Migrator interface {
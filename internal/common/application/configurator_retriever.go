@@ -11,7 +11,7 @@ import (
 )
 
 const (
-	HttpRetrieverDefaultUrl = "http://configurator/internal/json"
+	HttpRetrieverDefaultUrl = "http://configurator/v1/config/internal/json"
 )
 
 type Retriever interface {
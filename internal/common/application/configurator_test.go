@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
+	"sync"
 	"testing"
 	"time"
 )
@@ -79,3 +80,166 @@ func TestTimeBasedChanges(t *testing.T) {
 	assert.NotEqual(t, 1, configurator.Values.IntVal)
 	assert.Equal(t, mm.counter, configurator.Values.IntVal)
 }
+
+func TestApplyAndOnChange(t *testing.T) {
+	configurator := NewConfigurator[miniConfig]().
+		WithRetriever(&mockRetriever{}).
+		WithInterval(0).
+		MustInit()
+
+	assert.Equal(t, 1, configurator.Values.IntVal)
+	assert.False(t, configurator.Status().LastRefreshAt.IsZero())
+
+	var changes []ConfigChange[miniConfig]
+
+	configurator.OnChange("IntVal", func(change ConfigChange[miniConfig]) {
+		changes = append(changes, change)
+	})
+
+	assert.Nil(t, configurator.Apply("UnknownKey", "15"))
+	assert.Nil(t, configurator.Apply("IntVal", "15"))
+	assert.Nil(t, configurator.Apply("IntVal", "15"))
+
+	assert.Equal(t, 15, configurator.Values.IntVal)
+	assert.Equal(t, 1, len(changes))
+	assert.Equal(t, "1", changes[0].OldValue)
+	assert.Equal(t, "15", changes[0].NewValue)
+	assert.Equal(t, 15, changes[0].Values.IntVal)
+
+	assert.NotNil(t, configurator.Apply("IntVal", "not a number"))
+	assert.Equal(t, 15, configurator.Values.IntVal)
+	assert.NotEmpty(t, configurator.Status().LastError)
+	assert.False(t, configurator.Status().LastEventAt.IsZero())
+}
+
+func TestApplyConcurrentRead(t *testing.T) {
+	configurator := NewConfigurator[miniConfig]().
+		WithRetriever(&mockRetriever{}).
+		WithInterval(0).
+		MustInit()
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		for i := 0; i < 100; i++ {
+			assert.Nil(t, configurator.Apply("IntVal", fmt.Sprint(i)))
+		}
+	}()
+
+	for i := 0; i < 100; i++ {
+		assert.GreaterOrEqual(t, configurator.GetValues().IntVal, 0)
+	}
+
+	<-done
+
+	assert.Equal(t, 99, configurator.GetValues().IntVal)
+}
+
+type staticRetriever struct {
+	values map[string]string
+}
+
+func (s *staticRetriever) Retrieve(_ []string, _ context.Context) (map[string]string, error) {
+	return s.values, nil
+}
+
+type typedConfig struct {
+	Timeout time.Duration
+	Ratio   float64
+	Names   []string
+	Ids     []int64 `config:"USER_IDS"`
+	Labels  map[string]string
+	Limits  typedLimits
+	Retries int         `default:"3"`
+	Comment string      `config:",optional"`
+	Player  FeatureFlag `config:"NEW_PLAYER"`
+}
+
+type typedLimits struct {
+	Daily   int      `json:"daily"`
+	Regions []string `json:"regions"`
+}
+
+func TestTypedParsing(t *testing.T) {
+	configurator := NewConfigurator[typedConfig]().
+		WithRetriever(&staticRetriever{values: map[string]string{
+			"Timeout":    "1m30s",
+			"Ratio":      "0.25",
+			"Names":      "first, second",
+			"USER_IDS":   "[1, 2, 3]",
+			"Labels":     `{"a":"b"}`,
+			"Limits":     `{"daily":10,"regions":["eu"]}`,
+			"NEW_PLAYER": `{"enabled":true,"default_variant":"on"}`,
+		}}).
+		WithInterval(0).
+		MustInit()
+
+	assert.Equal(t, 90*time.Second, configurator.Values.Timeout)
+	assert.Equal(t, 0.25, configurator.Values.Ratio)
+	assert.Equal(t, []string{"first", "second"}, configurator.Values.Names)
+	assert.Equal(t, []int64{1, 2, 3}, configurator.Values.Ids)
+	assert.Equal(t, map[string]string{"a": "b"}, configurator.Values.Labels)
+	assert.Equal(t, typedLimits{Daily: 10, Regions: []string{"eu"}}, configurator.Values.Limits)
+	assert.Equal(t, 3, configurator.Values.Retries)
+	assert.Equal(t, "", configurator.Values.Comment)
+	assert.Equal(t, "NEW_PLAYER", configurator.Values.Player.Key)
+	assert.True(t, configurator.Values.Player.Enabled)
+
+	assert.Nil(t, configurator.Apply("Timeout", "15"))
+	assert.Equal(t, 15*time.Second, configurator.Values.Timeout)
+
+	assert.Nil(t, configurator.Apply("USER_IDS", "4,5"))
+	assert.Equal(t, []int64{4, 5}, configurator.Values.Ids)
+
+	assert.Nil(t, configurator.Apply("Labels", " "))
+	assert.Nil(t, configurator.Values.Labels)
+
+	assert.NotNil(t, configurator.Apply("Limits", `{"daily":"x"}`))
+	assert.NotNil(t, configurator.setValues(map[string]string{"Timeout": "1s"}))
+}
+
+func TestApplyConcurrentKeys(t *testing.T) {
+	configurator := NewConfigurator[typedConfig]().
+		WithRetriever(&staticRetriever{values: map[string]string{
+			"Timeout":    "1s",
+			"Ratio":      "0",
+			"Names":      "",
+			"USER_IDS":   "",
+			"Labels":     "",
+			"Limits":     "",
+			"NEW_PLAYER": `{"enabled":false}`,
+		}}).
+		WithInterval(0).
+		MustInit()
+
+	keys := []string{"Timeout", "Ratio", "Retries", "Comment"}
+
+	// events of different keys arrive at the same time, none of them should be lost
+	for round := 1; round <= 500; round++ {
+		var wg sync.WaitGroup
+
+		start := make(chan struct{})
+
+		for _, key := range keys {
+			wg.Add(1)
+
+			go func(key string) {
+				defer wg.Done()
+				<-start
+				assert.Nil(t, configurator.Apply(key, fmt.Sprint(round)))
+			}(key)
+		}
+
+		close(start)
+		wg.Wait()
+
+		values := configurator.GetValues()
+
+		assert.Equal(t, time.Duration(round)*time.Second, values.Timeout)
+		assert.Equal(t, float64(round), values.Ratio)
+		assert.Equal(t, round, values.Retries)
+		assert.Equal(t, fmt.Sprint(round), values.Comment)
+	}
+}
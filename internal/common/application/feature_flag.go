@@ -0,0 +1,266 @@
+package application
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+
+	"github.com/digitalmonsters/go-common/common"
+	"github.com/digitalmonsters/go-common/router"
+	"github.com/pkg/errors"
+)
+
+const (
+	FlagVariantOff = "off"
+	FlagVariantOn  = "on"
+)
+
+// client attributes of FlagSubject, read from route values, query args or headers of the request
+const (
+	flagSubjectCountryCodeKey    = "country-code"
+	flagSubjectPlatformKey       = "platform"
+	flagSubjectReleaseVersionKey = "app-version"
+)
+
+// FeatureFlag is a value of ConfigTypeFlag config. Deny list wins over everything, then allow list, then the first
+// matching rule. When nothing matched (or the flag is disabled) DefaultVariant is returned
+type FeatureFlag struct {
+	Key            string            `json:"-"`
+	Enabled        bool              `json:"enabled"`
+	DefaultVariant string            `json:"default_variant"`
+	ReleaseVersion string            `json:"release_version"` // subjects on older app versions get DefaultVariant
+	AllowUserIds   []int64           `json:"allow_user_ids"`
+	DenyUserIds    []int64           `json:"deny_user_ids"`
+	Rules          []FeatureFlagRule `json:"rules"`
+}
+
+// FeatureFlagRule matches when all of its non-empty conditions match. Percentage is a sticky rollout by user id hash
+type FeatureFlagRule struct {
+	Variant           string              `json:"variant"`
+	CountryCodes      []string            `json:"country_codes"`
+	Platforms         []common.DeviceType `json:"platforms"`
+	MinReleaseVersion string              `json:"min_release_version"`
+	Percentage        *float64            `json:"percentage"`
+}
+
+// FlagSubject describes who the flag is evaluated for, use NewFlagSubject to build it for the request
+type FlagSubject struct {
+	UserId         int64
+	CountryCode    string
+	Platform       common.DeviceType
+	ReleaseVersion string
+}
+
+// NewFlagSubject takes user id from execution data and client attributes from the request
+func NewFlagSubject(executionData router.MethodExecutionData) FlagSubject {
+	return FlagSubject{
+		UserId:         executionData.UserId,
+		CountryCode:    strings.ToUpper(executionDataString(executionData, flagSubjectCountryCodeKey)),
+		Platform:       common.DeviceType(strings.ToLower(executionDataString(executionData, flagSubjectPlatformKey))),
+		ReleaseVersion: executionDataString(executionData, flagSubjectReleaseVersionKey),
+	}
+}
+
+func executionDataString(executionData router.MethodExecutionData, key string) string {
+	switch v := executionData.GetUserValue(key).(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case []byte:
+		return strings.TrimSpace(string(v))
+	}
+
+	return ""
+}
+
+func ParseFeatureFlag(key string, value string) (FeatureFlag, error) {
+	var flag FeatureFlag
+
+	if err := json.Unmarshal([]byte(value), &flag); err != nil {
+		return flag, errors.Wrap(err, fmt.Sprintf("flag %v is not a valid json", key))
+	}
+
+	flag.Key = key
+
+	return flag, flag.Validate()
+}
+
+func (f FeatureFlag) Validate() error {
+	if len(f.ReleaseVersion) > 0 {
+		if _, err := parseReleaseVersion(f.ReleaseVersion); err != nil {
+			return err
+		}
+	}
+
+	for i, rule := range f.Rules {
+		if rule.Percentage != nil && (*rule.Percentage < 0 || *rule.Percentage > 100) {
+			return errors.New(fmt.Sprintf("rule %v: percentage should be between 0 and 100", i))
+		}
+
+		if len(rule.MinReleaseVersion) > 0 {
+			if _, err := parseReleaseVersion(rule.MinReleaseVersion); err != nil {
+				return errors.Wrap(err, fmt.Sprintf("rule %v", i))
+			}
+		}
+	}
+
+	return nil
+}
+
+// Evaluate returns the variant for the subject
+func (f FeatureFlag) Evaluate(subject FlagSubject) string {
+	defaultVariant := f.DefaultVariant
+	if len(defaultVariant) == 0 {
+		defaultVariant = FlagVariantOff
+	}
+
+	if !f.Enabled {
+		return defaultVariant
+	}
+
+	if subject.UserId > 0 && containsUserId(f.DenyUserIds, subject.UserId) {
+		return defaultVariant
+	}
+
+	if len(f.ReleaseVersion) > 0 && !isReleaseVersionAtLeast(subject.ReleaseVersion, f.ReleaseVersion) {
+		return defaultVariant
+	}
+
+	if subject.UserId > 0 && containsUserId(f.AllowUserIds, subject.UserId) {
+		return FlagVariantOn
+	}
+
+	for _, rule := range f.Rules {
+		if rule.matches(f.Key, subject) {
+			if len(rule.Variant) == 0 {
+				return FlagVariantOn
+			}
+
+			return rule.Variant
+		}
+	}
+
+	return defaultVariant
+}
+
+// IsEnabled is a shortcut for on/off flags
+func (f FeatureFlag) IsEnabled(subject FlagSubject) bool {
+	return f.Evaluate(subject) != FlagVariantOff
+}
+
+func (r FeatureFlagRule) matches(key string, subject FlagSubject) bool {
+	if len(r.CountryCodes) > 0 && !containsFold(r.CountryCodes, subject.CountryCode) {
+		return false
+	}
+
+	if len(r.Platforms) > 0 {
+		found := false
+
+		for _, platform := range r.Platforms {
+			if platform == subject.Platform {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	if len(r.MinReleaseVersion) > 0 && !isReleaseVersionAtLeast(subject.ReleaseVersion, r.MinReleaseVersion) {
+		return false
+	}
+
+	if r.Percentage != nil {
+		if subject.UserId <= 0 {
+			return false
+		}
+
+		return float64(rolloutBucket(key, subject.UserId)) < *r.Percentage*100
+	}
+
+	return true
+}
+
+// rolloutBucket returns stable bucket in [0, 10000) for the user, buckets differ between flags
+func rolloutBucket(key string, userId int64) uint32 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(fmt.Sprintf("%v:%v", key, userId)))
+
+	return h.Sum32() % 10000
+}
+
+func containsUserId(ids []int64, userId int64) bool {
+	for _, id := range ids {
+		if id == userId {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsFold(items []string, value string) bool {
+	for _, item := range items {
+		if strings.EqualFold(item, value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isReleaseVersionAtLeast compares dotted numeric versions, unknown or invalid subject version never matches
+func isReleaseVersionAtLeast(version string, minVersion string) bool {
+	parsed, err := parseReleaseVersion(version)
+	if err != nil {
+		return false
+	}
+
+	parsedMin, err := parseReleaseVersion(minVersion)
+	if err != nil {
+		return false
+	}
+
+	for i := 0; i < len(parsed) || i < len(parsedMin); i++ {
+		var a, b int64
+
+		if i < len(parsed) {
+			a = parsed[i]
+		}
+
+		if i < len(parsedMin) {
+			b = parsedMin[i]
+		}
+
+		if a != b {
+			return a > b
+		}
+	}
+
+	return true
+}
+
+func parseReleaseVersion(version string) ([]int64, error) {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+
+	if len(version) == 0 {
+		return nil, errors.New("release version is empty")
+	}
+
+	parts := strings.Split(version, ".")
+	result := make([]int64, 0, len(parts))
+
+	for _, part := range parts {
+		parsed, err := strconv.ParseInt(part, 10, 64)
+		if err != nil || parsed < 0 {
+			return nil, errors.New(fmt.Sprintf("invalid release version %v", version))
+		}
+
+		result = append(result, parsed)
+	}
+
+	return result, nil
+}
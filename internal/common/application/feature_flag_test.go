@@ -0,0 +1,85 @@
+package application
+
+import (
+	"github.com/digitalmonsters/go-common/common"
+	"github.com/digitalmonsters/go-common/router"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestFeatureFlagEvaluate(t *testing.T) {
+	flag, err := ParseFeatureFlag("MUSIC_FEED", `{
+		"enabled": true,
+		"release_version": "2.1",
+		"allow_user_ids": [1],
+		"deny_user_ids": [2],
+		"rules": [
+			{"variant": "b", "country_codes": ["us"], "platforms": ["ios"]},
+			{"min_release_version": "3.0.0"}
+		]
+	}`)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "MUSIC_FEED", flag.Key)
+
+	subject := FlagSubject{UserId: 1, ReleaseVersion: "2.1.0"}
+	assert.Equal(t, FlagVariantOn, flag.Evaluate(subject))
+
+	subject.UserId = 2
+	subject.CountryCode = "US"
+	subject.Platform = common.DeviceTypeIos
+	assert.Equal(t, FlagVariantOff, flag.Evaluate(subject))
+
+	subject.UserId = 3
+	assert.Equal(t, "b", flag.Evaluate(subject))
+
+	subject.ReleaseVersion = "2.0.9"
+	assert.Equal(t, FlagVariantOff, flag.Evaluate(subject))
+
+	subject = FlagSubject{UserId: 3, Platform: common.DeviceTypeAndroid, ReleaseVersion: "3.0"}
+	assert.True(t, flag.IsEnabled(subject))
+
+	subject.ReleaseVersion = "2.9.99"
+	assert.False(t, flag.IsEnabled(subject))
+
+	flag.Enabled = false
+	flag.DefaultVariant = "control"
+	assert.Equal(t, "control", flag.Evaluate(FlagSubject{UserId: 1, ReleaseVersion: "3.0"}))
+}
+
+func TestFeatureFlagPercentage(t *testing.T) {
+	flag, err := ParseFeatureFlag("ROLLOUT", `{"enabled": true, "rules": [{"percentage": 30}]}`)
+	assert.Nil(t, err)
+
+	enabled := 0
+
+	for userId := int64(1); userId <= 10000; userId++ {
+		first := flag.IsEnabled(FlagSubject{UserId: userId})
+
+		assert.Equal(t, first, flag.IsEnabled(FlagSubject{UserId: userId}))
+
+		if first {
+			enabled++
+		}
+	}
+
+	assert.InDelta(t, 3000, enabled, 300)
+	assert.False(t, flag.IsEnabled(FlagSubject{}))
+}
+
+func TestFeatureFlagValidate(t *testing.T) {
+	_, err := ParseFeatureFlag("INVALID", `{"rules": [{"percentage": 101}]}`)
+	assert.NotNil(t, err)
+
+	_, err = ParseFeatureFlag("INVALID", `{"release_version": "1.x"}`)
+	assert.NotNil(t, err)
+
+	_, err = ParseFeatureFlag("INVALID", `not a json`)
+	assert.NotNil(t, err)
+}
+
+func TestNewFlagSubject(t *testing.T) {
+	subject := NewFlagSubject(router.MethodExecutionData{UserId: 15})
+
+	assert.Equal(t, FlagSubject{UserId: 15}, subject)
+}
@@ -19,6 +19,7 @@ const (
 	ConfigTypeInteger = ConfigType("integer")
 	ConfigTypeObject  = ConfigType("object")
 	ConfigTypeBool    = ConfigType("bool")
+	ConfigTypeFlag    = ConfigType("flag")
 )
 
 type ConfigCategory string
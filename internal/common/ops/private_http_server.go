@@ -1,12 +1,14 @@
 package ops
 
 import (
+	"encoding/json"
 	"fmt"
 	fastRouter "github.com/fasthttp/router"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/rs/zerolog/log"
 	"github.com/valyala/fasthttp"
 	"github.com/valyala/fasthttp/fasthttpadaptor"
+	"sync"
 	"time"
 )
 
@@ -15,17 +17,21 @@ type PrivateHttpServer struct {
 	realRouter *fastRouter.Router
 	ready      bool
 	healthy    bool
+	statuses   map[string]func() interface{}
+	mutex      sync.RWMutex
 }
 
 func NewPrivateHttpServer() *PrivateHttpServer {
 	h := &PrivateHttpServer{
 		realRouter: fastRouter.New(),
 		healthy:    true,
+		statuses:   map[string]func() interface{}{},
 	}
 
 	h.registerHttpReadinessCheck()
 	h.registerHttpHealthCheck()
 	h.registerMetrics()
+	h.registerStatus()
 
 	return h
 }
@@ -54,6 +60,38 @@ func (r *PrivateHttpServer) registerHttpHealthCheck() {
 	})
 }
 
+func (r *PrivateHttpServer) registerStatus() {
+	r.realRouter.GET("/status", func(ctx *fasthttp.RequestCtx) {
+		r.mutex.RLock()
+		result := make(map[string]interface{}, len(r.statuses))
+
+		for name, fn := range r.statuses {
+			result[name] = fn()
+		}
+		r.mutex.RUnlock()
+
+		data, err := json.Marshal(result)
+
+		if err != nil {
+			ctx.Response.SetStatusCode(500)
+			return
+		}
+
+		ctx.Response.Header.SetContentType("application/json")
+		ctx.Response.SetBody(data)
+	})
+}
+
+// AddStatusProvider exposes result of fn under name on /status, e.g. configurator last refresh time
+func (r *PrivateHttpServer) AddStatusProvider(name string, fn func() interface{}) *PrivateHttpServer {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.statuses[name] = fn
+
+	return r
+}
+
 func (r *PrivateHttpServer) Ready() {
 	r.ready = true
 }
@@ -0,0 +1,91 @@
+package router
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/digitalmonsters/go-common/wrappers/auth_go"
+	"github.com/pkg/errors"
+	"go.elastic.co/apm"
+)
+
+// HttpErrorWriter writes an error in the response format of the service
+type HttpErrorWriter func(w http.ResponseWriter, status int, err error)
+
+// HttpAuth authorizes net/http routes with the same rules as AdminCommand and the public commands:
+// identity headers are trusted only when external auth allowed the request, admin routes are checked by rbac object
+type HttpAuth struct {
+	authWrapper auth_go.IAuthGoWrapper
+	writeError  HttpErrorWriter
+}
+
+func NewHttpAuth(authWrapper auth_go.IAuthGoWrapper, writeError HttpErrorWriter) *HttpAuth {
+	return &HttpAuth{
+		authWrapper: authWrapper,
+		writeError:  writeError,
+	}
+}
+
+// AdminIdFromHttpRequest returns admin id set by external auth, zero when the request was not allowed
+func AdminIdFromHttpRequest(r *http.Request) int64 {
+	return idFromHttpHeader(r, "Admin-Id")
+}
+
+// UserIdFromHttpRequest returns user id set by external auth, zero for guests or when the request was not allowed
+func UserIdFromHttpRequest(r *http.Request) int64 {
+	return idFromHttpHeader(r, "User-Id")
+}
+
+func idFromHttpHeader(r *http.Request, header string) int64 {
+	if !strings.EqualFold(r.Header.Get("X-Ext-Authz-Check-Result"), "allowed") {
+		return 0
+	}
+
+	id, _ := strconv.ParseInt(r.Header.Get(header), 10, 64)
+
+	return id
+}
+
+// RequireUser rejects requests without an authorized user
+func (a *HttpAuth) RequireUser(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if UserIdFromHttpRequest(r) == 0 {
+			a.writeError(w, http.StatusUnauthorized, errors.New("user authorization required"))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RequireAdmin rejects requests without an authorized admin or when the admin has no access to rbacObj.
+// Empty rbacObj only requires an admin, like AccessLevelPublic admin commands
+func (a *HttpAuth) RequireAdmin(rbacObj string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			adminId := AdminIdFromHttpRequest(r)
+
+			if adminId == 0 {
+				a.writeError(w, http.StatusUnauthorized, errors.New("admin authorization required"))
+				return
+			}
+
+			if rbacObj != "" {
+				resp := <-a.authWrapper.CheckAdminPermissions(adminId, rbacObj, apm.TransactionFromContext(r.Context()), false)
+
+				if resp.Error != nil {
+					a.writeError(w, http.StatusInternalServerError, resp.Error.ToError())
+					return
+				}
+
+				if !resp.Resp.HasAccess {
+					a.writeError(w, http.StatusForbidden, errors.New("admin user does not have access to this method"))
+					return
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
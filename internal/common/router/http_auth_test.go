@@ -0,0 +1,89 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/digitalmonsters/go-common/wrappers/auth_go"
+	"github.com/stretchr/testify/assert"
+	"go.elastic.co/apm"
+)
+
+func newTestHttpAuth(hasAccess bool, checkedObjects *[]string) *HttpAuth {
+	return NewHttpAuth(&auth_go.AuthGoWrapperMock{
+		CheckAdminPermissionsFn: func(userId int64, obj string, transaction *apm.Transaction, forceLog bool) chan auth_go.CheckAdminPermissionsResponseChan {
+			*checkedObjects = append(*checkedObjects, obj)
+
+			ch := make(chan auth_go.CheckAdminPermissionsResponseChan, 1)
+			ch <- auth_go.CheckAdminPermissionsResponseChan{
+				Resp: auth_go.CheckAdminPermissionsResponse{UserId: userId, HasAccess: hasAccess},
+			}
+
+			return ch
+		},
+	}, func(w http.ResponseWriter, status int, err error) {
+		http.Error(w, err.Error(), status)
+	})
+}
+
+func serveWithHeaders(handler http.Handler, headers map[string]string) int {
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	return rec.Code
+}
+
+func TestHttpAuth_RequireAdmin(t *testing.T) {
+	a := assert.New(t)
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal(int64(10), AdminIdFromHttpRequest(r))
+	})
+
+	var checked []string
+
+	handler := newTestHttpAuth(true, &checked).RequireAdmin("music:moderation:edit")(ok)
+
+	a.Equal(http.StatusUnauthorized, serveWithHeaders(handler, map[string]string{"Admin-Id": "10"}))
+	a.Empty(checked)
+
+	a.Equal(http.StatusOK, serveWithHeaders(handler, map[string]string{
+		"Admin-Id": "10", "X-Ext-Authz-Check-Result": "allowed"}))
+	a.Equal([]string{"music:moderation:edit"}, checked)
+
+	checked = nil
+	handler = newTestHttpAuth(false, &checked).RequireAdmin("music:moderation:edit")(ok)
+
+	a.Equal(http.StatusForbidden, serveWithHeaders(handler, map[string]string{
+		"Admin-Id": "10", "X-Ext-Authz-Check-Result": "allowed"}))
+
+	checked = nil
+	handler = newTestHttpAuth(false, &checked).RequireAdmin("")(ok)
+
+	a.Equal(http.StatusOK, serveWithHeaders(handler, map[string]string{
+		"Admin-Id": "10", "X-Ext-Authz-Check-Result": "allowed"}))
+	a.Empty(checked)
+}
+
+func TestHttpAuth_RequireUser(t *testing.T) {
+	a := assert.New(t)
+
+	var checked []string
+
+	handler := newTestHttpAuth(true, &checked).RequireUser(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal(int64(7), UserIdFromHttpRequest(r))
+	}))
+
+	a.Equal(http.StatusUnauthorized, serveWithHeaders(handler, map[string]string{"User-Id": "7"}))
+	a.Equal(http.StatusUnauthorized, serveWithHeaders(handler, map[string]string{
+		"User-Id": "7", "X-Ext-Authz-Check-Result": "denied"}))
+	a.Equal(http.StatusOK, serveWithHeaders(handler, map[string]string{
+		"User-Id": "7", "X-Ext-Authz-Check-Result": "Allowed"}))
+	a.Empty(checked)
+}
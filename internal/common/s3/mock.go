@@ -1,14 +1,21 @@
 package s3
 
 import (
+	"io"
 	"time"
 )
 
 type UploaderMock struct {
-	GetObjectSignedUrlFn func(path string, urlExpiration time.Duration) (string, error)
-	PutObjectSignedUrlFn func(path string, urlExpiration time.Duration, acl string) (string, error)
-	GetObjectSizeFn      func(path string) (int64, error)
-	UploadObjectFn       func(path string, data []byte, contentType string) error
+	GetObjectSignedUrlFn      func(path string, urlExpiration time.Duration) (string, error)
+	PutObjectSignedUrlFn      func(path string, urlExpiration time.Duration, acl string) (string, error)
+	GetObjectSizeFn           func(path string) (int64, error)
+	UploadObjectFn            func(path string, data []byte, contentType string) error
+	UploadObjectFromReaderFn  func(path string, body io.ReadSeeker, contentType string) error
+	GetObjectFn               func(path string) (io.ReadCloser, error)
+	CreateMultipartUploadFn   func(path string, contentType string) (string, error)
+	UploadPartFn              func(path string, uploadId string, partNumber int64, body io.ReadSeeker) (string, error)
+	CompleteMultipartUploadFn func(path string, uploadId string, parts []CompletedPart) error
+	AbortMultipartUploadFn    func(path string, uploadId string) error
 }
 
 func (u *UploaderMock) GetObjectSignedUrl(path string, urlExpiration time.Duration) (string, error) {
@@ -24,6 +31,26 @@ func (u *UploaderMock) UploadObject(path string, data []byte, contentType string
 	return u.UploadObjectFn(path, data, contentType)
 }
 
+func (u *UploaderMock) UploadObjectFromReader(path string, body io.ReadSeeker, contentType string) error {
+	return u.UploadObjectFromReaderFn(path, body, contentType)
+}
+func (u *UploaderMock) GetObject(path string) (io.ReadCloser, error) {
+	return u.GetObjectFn(path)
+}
+
+func (u *UploaderMock) CreateMultipartUpload(path string, contentType string) (string, error) {
+	return u.CreateMultipartUploadFn(path, contentType)
+}
+func (u *UploaderMock) UploadPart(path string, uploadId string, partNumber int64, body io.ReadSeeker) (string, error) {
+	return u.UploadPartFn(path, uploadId, partNumber, body)
+}
+func (u *UploaderMock) CompleteMultipartUpload(path string, uploadId string, parts []CompletedPart) error {
+	return u.CompleteMultipartUploadFn(path, uploadId, parts)
+}
+func (u *UploaderMock) AbortMultipartUpload(path string, uploadId string) error {
+	return u.AbortMultipartUploadFn(path, uploadId)
+}
+
 func GetMock() IUploader { // for compiler errors
 	return &UploaderMock{}
 }
@@ -6,6 +6,7 @@ import (
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/digitalmonsters/go-common/boilerplate"
+	"io"
 	"time"
 )
 
@@ -14,6 +15,17 @@ type IUploader interface {
 	PutObjectSignedUrl(path string, urlExpiration time.Duration, acl string) (string, error)
 	GetObjectSize(path string) (int64, error)
 	UploadObject(path string, data []byte, contentType string) error
+	UploadObjectFromReader(path string, body io.ReadSeeker, contentType string) error
+	GetObject(path string) (io.ReadCloser, error)
+	CreateMultipartUpload(path string, contentType string) (string, error)
+	UploadPart(path string, uploadId string, partNumber int64, body io.ReadSeeker) (string, error)
+	CompleteMultipartUpload(path string, uploadId string, parts []CompletedPart) error
+	AbortMultipartUpload(path string, uploadId string) error
+}
+
+type CompletedPart struct {
+	PartNumber int64
+	ETag       string
 }
 
 type Uploader struct {
@@ -105,6 +117,116 @@ func (u *Uploader) UploadObject(path string, data []byte, contentType string) er
 	return err
 }
 
+// UploadObjectFromReader uploads file without reading it into memory, e.g. an opened multipart or temp file
+func (u *Uploader) UploadObjectFromReader(path string, body io.ReadSeeker, contentType string) error {
+	client, err := u.getClient()
+	if err != nil {
+		return err
+	}
+	_, err = client.PutObject(&s3.PutObjectInput{
+		Body:        body,
+		Key:         aws.String(path),
+		Bucket:      aws.String(u.config.Bucket),
+		ContentType: aws.String(contentType),
+	})
+	return err
+}
+
+// GetObject returns object body, caller should close it
+func (u *Uploader) GetObject(path string) (io.ReadCloser, error) {
+	client, err := u.getClient()
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String(u.config.Bucket),
+		Key:    aws.String(path),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Body, nil
+}
+
+// CreateMultipartUpload starts multipart upload and returns its id. Parts except the last one should be at least 5MB
+func (u *Uploader) CreateMultipartUpload(path string, contentType string) (string, error) {
+	client, err := u.getClient()
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := client.CreateMultipartUpload(&s3.CreateMultipartUploadInput{
+		Bucket:      aws.String(u.config.Bucket),
+		Key:         aws.String(path),
+		ContentType: aws.String(contentType),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return *resp.UploadId, nil
+}
+
+// UploadPart uploads single part and returns its etag. Upload of the same part number replaces previous one
+func (u *Uploader) UploadPart(path string, uploadId string, partNumber int64, body io.ReadSeeker) (string, error) {
+	client, err := u.getClient()
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := client.UploadPart(&s3.UploadPartInput{
+		Bucket:     aws.String(u.config.Bucket),
+		Key:        aws.String(path),
+		UploadId:   aws.String(uploadId),
+		PartNumber: aws.Int64(partNumber),
+		Body:       body,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return *resp.ETag, nil
+}
+
+func (u *Uploader) CompleteMultipartUpload(path string, uploadId string, parts []CompletedPart) error {
+	client, err := u.getClient()
+	if err != nil {
+		return err
+	}
+
+	var completedParts []*s3.CompletedPart
+	for _, p := range parts {
+		completedParts = append(completedParts, &s3.CompletedPart{
+			ETag:       aws.String(p.ETag),
+			PartNumber: aws.Int64(p.PartNumber),
+		})
+	}
+
+	_, err = client.CompleteMultipartUpload(&s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(u.config.Bucket),
+		Key:             aws.String(path),
+		UploadId:        aws.String(uploadId),
+		MultipartUpload: &s3.CompletedMultipartUpload{Parts: completedParts},
+	})
+	return err
+}
+
+func (u *Uploader) AbortMultipartUpload(path string, uploadId string) error {
+	client, err := u.getClient()
+	if err != nil {
+		return err
+	}
+
+	_, err = client.AbortMultipartUpload(&s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(u.config.Bucket),
+		Key:      aws.String(path),
+		UploadId: aws.String(uploadId),
+	})
+	return err
+}
+
 func (u *Uploader) getClient() (*s3.S3, error) {
 	if u.session == nil {
 		if sess, err := session.NewSession(&aws.Config{Region: aws.String(u.config.Region)}); err != nil {
@@ -38,6 +38,7 @@ type IGoTokenomicsWrapper interface {
 	GetMyReferredUsersWatchedVideoInfo(referrerId, page, count int64, apmTransaction *apm.Transaction, forceLog bool) chan wrappers.GenericResponseChan[GetMyReferredUsersWatchedVideoInfoResponse]
 	DeductVaultPointsForIntroFeed(userId int64, apmTransaction *apm.Transaction, forceLog bool) chan wrappers.GenericResponseChan[DeductVaultPointsForIntroFeedResponse]
 	AddPointsToVault(userId int64, points decimal.Decimal, apmTransaction *apm.Transaction, forceLog bool) chan wrappers.GenericResponseChan[AddPointsToVaultResponse]
+	RequestMusicRoyaltyPayout(req MusicRoyaltyPayoutRequest, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[MusicRoyaltyPayoutResponse]
 }
 
 func NewGoTokenomicsWrapper(config boilerplate.WrapperConfig) IGoTokenomicsWrapper {
@@ -244,3 +245,8 @@ func (w *Wrapper) AddPointsToVault(userId int64, points decimal.Decimal, apmTran
 		Amount: points,
 	}, map[string]string{}, w.defaultTimeout, apmTransaction, w.serviceName, forceLog)
 }
+
+func (w *Wrapper) RequestMusicRoyaltyPayout(req MusicRoyaltyPayoutRequest, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[MusicRoyaltyPayoutResponse] {
+	return wrappers.ExecuteRpcRequestAsync[MusicRoyaltyPayoutResponse](w.baseWrapper, w.apiUrl, "RequestMusicRoyaltyPayout", req,
+		map[string]string{}, w.defaultTimeout, apm.TransactionFromContext(ctx), w.serviceName, forceLog)
+}
@@ -23,6 +23,7 @@ type GoTokenomicsWrapperMock struct {
 	GetMyReferredUsersWatchedVideoInfoFn  func(referrerId, page, count int64, apmTransaction *apm.Transaction, forceLog bool) chan wrappers.GenericResponseChan[GetMyReferredUsersWatchedVideoInfoResponse]
 	DeductVaultPointsForIntroFeedFn       func(userId int64, apmTransaction *apm.Transaction, forceLog bool) chan wrappers.GenericResponseChan[DeductVaultPointsForIntroFeedResponse]
 	AddPointsToVaultFn                    func(userId int64, points decimal.Decimal, apmTransaction *apm.Transaction, forceLog bool) chan wrappers.GenericResponseChan[AddPointsToVaultResponse]
+	RequestMusicRoyaltyPayoutFn           func(req MusicRoyaltyPayoutRequest, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[MusicRoyaltyPayoutResponse]
 }
 
 func (w *GoTokenomicsWrapperMock) GetUsersTokenomicsInfo(userIds []int64, filters []filters.Filter, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[map[int64]UserTokenomicsInfo] {
@@ -75,6 +76,10 @@ func (w *GoTokenomicsWrapperMock) AddPointsToVault(userId int64, points decimal.
 	return w.AddPointsToVaultFn(userId, points, apmTransaction, forceLog)
 }
 
+func (w *GoTokenomicsWrapperMock) RequestMusicRoyaltyPayout(req MusicRoyaltyPayoutRequest, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[MusicRoyaltyPayoutResponse] {
+	return w.RequestMusicRoyaltyPayoutFn(req, ctx, forceLog)
+}
+
 func GetMock() IGoTokenomicsWrapper {
 	return &GoTokenomicsWrapperMock{}
 }
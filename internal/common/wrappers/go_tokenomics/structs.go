@@ -271,6 +271,21 @@ type AddPointsToVaultResponse struct {
 	VaultPoints decimal.Decimal `json:"vault_points"`
 }
 
+// MusicRoyaltyPayoutRequest pays royalty points of music creator statement. StatementId is an idempotency key, repeated
+// request for the same statement returns the existing payout
+type MusicRoyaltyPayoutRequest struct {
+	UserId      int64           `json:"user_id" validate:"required"`
+	StatementId int64           `json:"statement_id" validate:"required"`
+	Amount      decimal.Decimal `json:"amount" validate:"required"`
+	PeriodStart time.Time       `json:"period_start"`
+	PeriodEnd   time.Time       `json:"period_end"`
+}
+
+type MusicRoyaltyPayoutResponse struct {
+	PayoutId string          `json:"payout_id"`
+	Amount   decimal.Decimal `json:"amount"`
+}
+
 type ReferredUsersWatchTimeInfo struct {
 	Id          int64     `json:"id"`
 	UserId      int64     `json:"user_id"`
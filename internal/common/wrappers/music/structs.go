@@ -16,6 +16,7 @@ const (
 	CreatorSongStatusPublished = CreatorSongStatus(1)
 	CreatorSongStatusRejected  = CreatorSongStatus(2)
 	CreatorSongStatusApproved  = CreatorSongStatus(3)
+	CreatorSongStatusPending   = CreatorSongStatus(4)
 )
 
 type SimpleMusic struct {
@@ -0,0 +1,232 @@
+package configurator
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/digitalmonsters/configurator/pkg/configs"
+	"github.com/digitalmonsters/go-common/application"
+	"github.com/digitalmonsters/go-common/callback"
+	"github.com/digitalmonsters/go-common/eventsourcing"
+	"github.com/digitalmonsters/go-common/router"
+	"github.com/digitalmonsters/go-common/wrappers/auth_go"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"go.elastic.co/apm"
+	"gopkg.in/guregu/null.v4"
+	"gorm.io/gorm"
+)
+
+const testAdminId = int64(1)
+
+// newAdminTestRouter registers config routes with auth-go mock which grants only allowedObj
+func newAdminTestRouter(service configs.IConfigService, allowedObj string, checkedObjects *[]string) chi.Router {
+	authWrapper := &auth_go.AuthGoWrapperMock{
+		CheckAdminPermissionsFn: func(userId int64, obj string, transaction *apm.Transaction, forceLog bool) chan auth_go.CheckAdminPermissionsResponseChan {
+			*checkedObjects = append(*checkedObjects, obj)
+
+			ch := make(chan auth_go.CheckAdminPermissionsResponseChan, 1)
+			ch <- auth_go.CheckAdminPermissionsResponseChan{
+				Resp: auth_go.CheckAdminPermissionsResponse{UserId: userId, HasAccess: obj == allowedObj},
+			}
+
+			return ch
+		},
+	}
+
+	r := chi.NewRouter()
+	registerRoutes(r, service, nil, router.NewHttpAuth(authWrapper, writeError))
+
+	return r
+}
+
+func doAdminRequest(r http.Handler, path string, req interface{}, authorized bool) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(req)
+	httpReq := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(body))
+
+	httpReq.Header.Set("Admin-Id", "1")
+
+	if authorized {
+		httpReq.Header.Set("X-Ext-Authz-Check-Result", "allowed")
+	}
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httpReq)
+
+	return rec
+}
+
+func TestAdminRoutesPermissions(t *testing.T) {
+	cases := map[string]string{
+		"/config/admin/configs":          "configs:view",
+		"/config/admin/configurations":   "configs:view",
+		"/config/admin/logs":             "config:logs:view",
+		"/config/admin/upsert":           "configs:upsert",
+		"/config/admin/rules":            "configs:upsert",
+		"/config/admin/changes/approve":  "configs:upsert",
+		"/config/admin/rollback":         "configs:upsert",
+		"/config/admin/scheduled/list":   "configs:view",
+		"/config/admin/scheduled/create": "configs:upsert",
+		"/config/admin/export":           "configs:view",
+		"/config/admin/import":           "configs:upsert",
+		"/config/admin/diff":             "configs:view",
+		"/config/admin/changes/reject":   "configs:upsert",
+		"/config/admin/scheduled/cancel": "configs:upsert",
+	}
+
+	for path, obj := range cases {
+		var checked []string
+
+		// admin without access to the object is rejected before the handler, so service mock is empty
+		rec := doAdminRequest(newAdminTestRouter(&configs.ConfigServiceMock{}, "other", &checked), path, nil, true)
+
+		assert.Equal(t, http.StatusForbidden, rec.Code, path)
+		assert.Equal(t, []string{obj}, checked, path)
+	}
+}
+
+func TestAdminRoutesRequireExternalAuth(t *testing.T) {
+	var checked []string
+
+	rec := doAdminRequest(newAdminTestRouter(&configs.ConfigServiceMock{}, "configs:view", &checked),
+		"/config/admin/configs", configs.GetConfigRequest{}, false)
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Empty(t, checked)
+}
+
+func TestGetConfigs(t *testing.T) {
+	var checked []string
+
+	service := &configs.ConfigServiceMock{
+		AdminGetConfigsFn: func(db *gorm.DB, req configs.GetConfigRequest, executionData router.MethodExecutionData) (*configs.GetConfigResponse, error) {
+			assert.Equal(t, testAdminId, executionData.UserId)
+			assert.Equal(t, 10, req.Limit)
+
+			return &configs.GetConfigResponse{
+				Items: []*configs.ConfigModelWithRelatedUserInfo{
+					{
+						ConfigModel: application.ConfigModel{
+							Key:      "test_key1",
+							Value:    "45",
+							Type:     application.ConfigTypeInteger,
+							Category: application.ConfigCategoryAd,
+						},
+					},
+					{
+						ConfigModel: application.ConfigModel{
+							Key:      "test_key2",
+							Value:    "some text",
+							Type:     application.ConfigTypeString,
+							Category: application.ConfigCategoryContent,
+						},
+					},
+				},
+				TotalCount: 2,
+			}, nil
+		},
+	}
+
+	rec := doAdminRequest(newAdminTestRouter(service, "configs:view", &checked), "/config/admin/configs",
+		configs.GetConfigRequest{
+			CreatedFrom: null.TimeFrom(time.Now().UTC().Add(-15 * time.Minute)),
+			CreatedTo:   null.TimeFrom(time.Now().UTC()),
+			Limit:       10,
+		}, true)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var resp struct {
+		Data configs.GetConfigResponse `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, 2, len(resp.Data.Items))
+	assert.Equal(t, int64(2), resp.Data.TotalCount)
+}
+
+func TestGetConfigLogs(t *testing.T) {
+	var checked []string
+
+	service := &configs.ConfigServiceMock{
+		AdminGetConfigLogsFn: func(db *gorm.DB, req configs.GetConfigLogsRequest, executionData router.MethodExecutionData) (*configs.GetConfigLogsResponse, error) {
+			assert.Equal(t, []string{"test_key1", "test_key2"}, req.Keys)
+
+			return &configs.GetConfigLogsResponse{
+				Items: []configs.ConfigLogModel{
+					{Id: 1, Key: "test_key1", Value: "45", RelatedUserId: null.IntFrom(1)},
+					{Id: 2, Key: "test_key2", Value: "some text", RelatedUserId: null.IntFrom(1)},
+				},
+				TotalCount: 2,
+			}, nil
+		},
+	}
+
+	rec := doAdminRequest(newAdminTestRouter(service, "config:logs:view", &checked), "/config/admin/logs",
+		configs.GetConfigLogsRequest{Keys: []string{"test_key1", "test_key2"}, Limit: 10}, true)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var resp struct {
+		Data configs.GetConfigLogsResponse `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, 2, len(resp.Data.Items))
+	assert.Equal(t, int64(2), resp.Data.TotalCount)
+}
+
+func TestUpsertConfig(t *testing.T) {
+	var checked []string
+
+	service := &configs.ConfigServiceMock{
+		AdminUpsertConfigFn: func(db *gorm.DB, req configs.UpsertConfigRequest, userId int64,
+			publisher eventsourcing.Publisher[eventsourcing.ConfigEvent]) (*configs.UpsertConfigResponse, []callback.Callback, error) {
+			assert.Equal(t, testAdminId, userId)
+
+			return &configs.UpsertConfigResponse{
+				ConfigModel: application.ConfigModel{
+					Key:         req.Key,
+					Value:       req.Value,
+					Type:        req.Type,
+					Description: req.Description,
+					Category:    req.Category,
+				},
+			}, nil, nil
+		},
+	}
+
+	req := configs.UpsertConfigRequest{
+		Key:         "test_key3",
+		Value:       "657",
+		Type:        application.ConfigTypeInteger,
+		Description: "test number",
+		Category:    application.ConfigCategoryTokens,
+	}
+
+	rec := doAdminRequest(newAdminTestRouter(service, "configs:upsert", &checked), "/config/admin/upsert", req, true)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, []string{"configs:upsert"}, checked)
+
+	var resp struct {
+		Data configs.UpsertConfigResponse `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, req.Type, resp.Data.Type)
+	assert.Equal(t, req.Value, resp.Data.Value)
+	assert.Equal(t, req.Category, resp.Data.Category)
+	assert.False(t, resp.Data.AdminOnly)
+	assert.Equal(t, req.Description, resp.Data.Description)
+}
@@ -0,0 +1,83 @@
+package configurator
+
+import (
+	"net/http"
+
+	"github.com/digitalmonsters/configurator/pkg/configs"
+	"github.com/digitalmonsters/configurator/pkg/database"
+	"github.com/digitalmonsters/go-common/eventsourcing"
+	"github.com/digitalmonsters/go-common/router"
+)
+
+func setConfigRules(service configs.IConfigService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req configs.SetConfigRulesRequest
+
+		if err := decodeRequest(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		resp, err := service.AdminSetConfigRules(database.GetDbWithContext(database.DbTypeMaster, r.Context()), req,
+			router.AdminIdFromHttpRequest(r))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		writeResponse(w, resp)
+	}
+}
+
+func approveConfigChange(service configs.IConfigService, publisher eventsourcing.Publisher[eventsourcing.ConfigEvent]) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req configs.ReviewConfigChangeRequest
+
+		if err := decodeRequest(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		tx := database.GetDbWithContext(database.DbTypeMaster, r.Context()).Begin()
+		defer tx.Rollback()
+
+		resp, callbacks, err := service.AdminApproveConfigChange(tx, req, router.AdminIdFromHttpRequest(r), publisher)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := tx.Commit().Error; err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		for _, callbackFn := range callbacks {
+			if err := callbackFn(r.Context()); err != nil {
+				writeError(w, http.StatusInternalServerError, err)
+				return
+			}
+		}
+
+		writeResponse(w, resp)
+	}
+}
+
+func rejectConfigChange(service configs.IConfigService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req configs.ReviewConfigChangeRequest
+
+		if err := decodeRequest(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := service.AdminRejectConfigChange(database.GetDbWithContext(database.DbTypeMaster, r.Context()), req,
+			router.AdminIdFromHttpRequest(r)); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		writeResponse(w, nil)
+	}
+}
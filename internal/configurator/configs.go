@@ -0,0 +1,94 @@
+package configurator
+
+import (
+	"net/http"
+
+	"github.com/digitalmonsters/configurator/pkg/configs"
+	"github.com/digitalmonsters/configurator/pkg/database"
+	"github.com/digitalmonsters/go-common/eventsourcing"
+	"github.com/digitalmonsters/go-common/router"
+	"go.elastic.co/apm"
+)
+
+// executionDataFromRequest builds execution data expected by service methods shared with rpc commands
+func executionDataFromRequest(r *http.Request) router.MethodExecutionData {
+	return router.MethodExecutionData{
+		ApmTransaction: apm.TransactionFromContext(r.Context()),
+		Context:        r.Context(),
+		UserId:         router.AdminIdFromHttpRequest(r),
+	}
+}
+
+func getConfigs(service configs.IConfigService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req configs.GetConfigRequest
+
+		if err := decodeRequest(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		resp, err := service.AdminGetConfigs(database.GetDbWithContext(database.DbTypeReadonly, r.Context()), req,
+			executionDataFromRequest(r))
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		writeResponse(w, resp)
+	}
+}
+
+func getConfigLogs(service configs.IConfigService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req configs.GetConfigLogsRequest
+
+		if err := decodeRequest(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		resp, err := service.AdminGetConfigLogs(database.GetDbWithContext(database.DbTypeReadonly, r.Context()), req,
+			executionDataFromRequest(r))
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		writeResponse(w, resp)
+	}
+}
+
+func upsertConfig(service configs.IConfigService, publisher eventsourcing.Publisher[eventsourcing.ConfigEvent]) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req configs.UpsertConfigRequest
+
+		if err := decodeRequest(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		tx := database.GetDbWithContext(database.DbTypeMaster, r.Context()).Begin()
+		defer tx.Rollback()
+
+		resp, callbacks, err := service.AdminUpsertConfig(tx, req, router.AdminIdFromHttpRequest(r), publisher)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := tx.Commit().Error; err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		for _, callbackFn := range callbacks {
+			if err := callbackFn(r.Context()); err != nil {
+				writeError(w, http.StatusInternalServerError, err)
+				return
+			}
+		}
+
+		writeResponse(w, resp)
+	}
+}
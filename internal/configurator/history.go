@@ -0,0 +1,121 @@
+package configurator
+
+import (
+	"net/http"
+
+	"github.com/digitalmonsters/configurator/pkg/configs"
+	"github.com/digitalmonsters/configurator/pkg/database"
+	"github.com/digitalmonsters/go-common/eventsourcing"
+	"github.com/digitalmonsters/go-common/router"
+)
+
+func rollbackConfig(service configs.IConfigService, publisher eventsourcing.Publisher[eventsourcing.ConfigEvent]) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req configs.RollbackConfigRequest
+
+		if err := decodeRequest(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		tx := database.GetDbWithContext(database.DbTypeMaster, r.Context()).Begin()
+		defer tx.Rollback()
+
+		resp, callbacks, err := service.AdminRollbackConfig(tx, req, router.AdminIdFromHttpRequest(r), publisher)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := tx.Commit().Error; err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		for _, callbackFn := range callbacks {
+			if err := callbackFn(r.Context()); err != nil {
+				writeError(w, http.StatusInternalServerError, err)
+				return
+			}
+		}
+
+		writeResponse(w, resp)
+	}
+}
+
+func getConfigDiff(service configs.IConfigService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req configs.GetConfigDiffRequest
+
+		if err := decodeRequest(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		resp, err := service.AdminGetConfigDiff(database.GetDbWithContext(database.DbTypeReadonly, r.Context()), req)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		writeResponse(w, resp)
+	}
+}
+
+func scheduleConfigChange(service configs.IConfigService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req configs.ScheduleConfigChangeRequest
+
+		if err := decodeRequest(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		resp, err := service.AdminScheduleConfigChange(database.GetDbWithContext(database.DbTypeMaster, r.Context()), req,
+			router.AdminIdFromHttpRequest(r))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		writeResponse(w, resp)
+	}
+}
+
+func cancelScheduledChange(service configs.IConfigService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req configs.CancelScheduledChangeRequest
+
+		if err := decodeRequest(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := service.AdminCancelScheduledChange(database.GetDbWithContext(database.DbTypeMaster, r.Context()), req,
+			router.AdminIdFromHttpRequest(r)); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		writeResponse(w, nil)
+	}
+}
+
+func listScheduledChanges(service configs.IConfigService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req configs.GetScheduledChangesRequest
+
+		if err := decodeRequest(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		resp, err := service.AdminGetScheduledChanges(database.GetDbWithContext(database.DbTypeReadonly, r.Context()), req)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		writeResponse(w, resp)
+	}
+}
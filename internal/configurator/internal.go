@@ -0,0 +1,93 @@
+package configurator
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/digitalmonsters/configurator/pkg/configs"
+	"github.com/digitalmonsters/configurator/pkg/database"
+	"github.com/digitalmonsters/go-common/application"
+	"github.com/digitalmonsters/go-common/eventsourcing"
+	"github.com/rs/zerolog/log"
+)
+
+type configRequest struct {
+	Items []string `json:"items"`
+}
+
+// writeRaw writes body without api response envelope, as application.HttpRetriever and HttpMigrator expect plain maps
+func writeRaw(w http.ResponseWriter, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		log.Err(err).Send()
+	}
+}
+
+// internalJson is used by application.HttpRetriever
+func internalJson(service configs.IConfigService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req configRequest
+
+		if err := decodeRequest(r, &req); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		items, err := service.GetConfigsByIds(database.GetDbWithContext(database.DbTypeReadonly, r.Context()), req.Items)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		res := map[string]string{}
+
+		for _, c := range items {
+			res[c.Key] = c.Value
+		}
+
+		writeRaw(w, res)
+	}
+}
+
+// internalJsonMigrator is used by application.HttpMigrator
+func internalJsonMigrator(service configs.IConfigService, publisher eventsourcing.Publisher[eventsourcing.ConfigEvent]) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req application.MigratorRequest
+
+		if err := decodeRequest(r, &req); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		tx := database.GetDbWithContext(database.DbTypeMaster, r.Context()).Begin()
+		defer tx.Rollback()
+
+		newConfigs, callbacks, err := service.MigrateConfigs(tx, req.Configs, publisher)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if err := tx.Commit().Error; err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		for _, callbackFn := range callbacks {
+			if err := callbackFn(r.Context()); err != nil {
+				log.Err(err).Send()
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+
+		res := map[string]application.ConfigModel{}
+
+		for _, c := range newConfigs {
+			res[c.Key] = c
+		}
+
+		writeRaw(w, res)
+	}
+}
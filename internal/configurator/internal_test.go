@@ -0,0 +1,114 @@
+package configurator
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/digitalmonsters/configurator/pkg/configs"
+	"github.com/digitalmonsters/configurator/pkg/database"
+	"github.com/digitalmonsters/go-common/application"
+	"github.com/digitalmonsters/go-common/callback"
+	"github.com/digitalmonsters/go-common/eventsourcing"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestInternalJson(t *testing.T) {
+	service := &configs.ConfigServiceMock{
+		GetConfigsByIdsFn: func(db *gorm.DB, ids []string) ([]database.Config, error) {
+			assert.Equal(t, 3, len(ids))
+
+			return []database.Config{
+				{Key: "a", Value: "a1"},
+				{Key: "b", Value: "b1"},
+				{Key: "c", Value: "c1"},
+			}, nil
+		},
+	}
+
+	body, _ := json.Marshal(configRequest{Items: []string{"a", "b", "c"}})
+	rec := httptest.NewRecorder()
+
+	internalJson(service)(rec, httptest.NewRequest(http.MethodPost, "/config/internal/json", bytes.NewReader(body)))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var results map[string]string
+	if err := json.Unmarshal(rec.Body.Bytes(), &results); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, map[string]string{"a": "a1", "b": "b1", "c": "c1"}, results)
+}
+
+func TestInternalJsonMigrator(t *testing.T) {
+	service := &configs.ConfigServiceMock{
+		MigrateConfigsFn: func(db *gorm.DB, newConfigs map[string]application.MigrateConfigModel,
+			publisher eventsourcing.Publisher[eventsourcing.ConfigEvent]) ([]application.ConfigModel, []callback.Callback, error) {
+			var configsArr []application.ConfigModel
+
+			for _, val := range newConfigs {
+				configsArr = append(configsArr, application.ConfigModel{
+					Key:            val.Key,
+					Value:          val.Value,
+					Type:           val.Type,
+					Description:    val.Description,
+					Category:       val.Category,
+					ReleaseVersion: val.ReleaseVersion,
+					CreatedAt:      time.Now().UTC(),
+					UpdatedAt:      time.Now().UTC(),
+				})
+			}
+
+			return configsArr, nil, nil
+		},
+	}
+
+	reqValues := map[string]application.MigrateConfigModel{
+		"test_key_1": {
+			Key:            "test_key_1",
+			Value:          "50",
+			Type:           application.ConfigTypeInteger,
+			Description:    "test_key_1 description",
+			Category:       application.ConfigCategoryContent,
+			ReleaseVersion: "v1.5",
+		},
+		"test_key_2": {
+			Key:            "test_key_2",
+			Value:          "some text",
+			Type:           application.ConfigTypeString,
+			Description:    "test_key_2 description",
+			Category:       application.ConfigCategoryAd,
+			ReleaseVersion: "v2.69",
+		},
+	}
+
+	body, _ := json.Marshal(application.MigratorRequest{Configs: reqValues})
+	rec := httptest.NewRecorder()
+
+	internalJsonMigrator(service, nil)(rec, httptest.NewRequest(http.MethodPost, "/config/internal/json/migrator",
+		bytes.NewReader(body)))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var resultsMap map[string]application.ConfigModel
+	if err := json.Unmarshal(rec.Body.Bytes(), &resultsMap); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, 2, len(resultsMap))
+
+	for _, reqModel := range reqValues {
+		resultModel, ok := resultsMap[reqModel.Key]
+
+		assert.True(t, ok)
+		assert.Equal(t, reqModel.Value, resultModel.Value)
+		assert.Equal(t, reqModel.Type, resultModel.Type)
+		assert.Equal(t, reqModel.Category, resultModel.Category)
+		assert.Equal(t, reqModel.ReleaseVersion, resultModel.ReleaseVersion)
+	}
+}
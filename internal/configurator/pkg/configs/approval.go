@@ -0,0 +1,213 @@
+package configs
+
+import (
+	"time"
+
+	"github.com/digitalmonsters/configurator/pkg/database"
+	"github.com/digitalmonsters/go-common/callback"
+	"github.com/digitalmonsters/go-common/eventsourcing"
+	"github.com/pkg/errors"
+	"gopkg.in/guregu/null.v4"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AdminSetConfigRules changes validation and approval of config. When config requires approval, turning approval off or
+// loosening validation waits for approval of another admin, otherwise one admin could skip the review of value changes
+func (c *ConfigService) AdminSetConfigRules(tx *gorm.DB, req SetConfigRulesRequest, userId int64) (*SetConfigRulesResponse, error) {
+	var currentConfig database.Config
+	if err := tx.Where("key = ?", req.Key).Find(&currentConfig).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if len(currentConfig.Key) == 0 {
+		return nil, errors.New("config doesn't exist")
+	}
+
+	rules := database.ConfigRules{Validation: req.Validation, RequiresApproval: req.RequiresApproval}
+
+	if err := validateConfigRules(rules, currentConfig); err != nil {
+		return nil, err
+	}
+
+	if currentConfig.RequiresApproval && (!rules.RequiresApproval || rulesLoosened(currentConfig.Validation, rules.Validation)) {
+		if err := ensureNoPendingChange(tx, currentConfig.Key); err != nil {
+			return nil, err
+		}
+
+		pending := database.ConfigLog{
+			Key:           currentConfig.Key,
+			OldValue:      currentConfig.Value,
+			Value:         currentConfig.Value,
+			RelatedUserId: null.IntFrom(userId),
+			Status:        database.ConfigLogStatusPending,
+			Rules:         &rules,
+		}
+		if err := tx.Create(&pending).Error; err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		return &SetConfigRulesResponse{
+			ConfigModelWithRelatedUserInfo: configRulesModel(currentConfig),
+			Status:                         database.ConfigLogStatusPending,
+			PendingLogId:                   null.IntFrom(pending.Id),
+		}, nil
+	}
+
+	updated, err := setConfigRules(tx, currentConfig, rules, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SetConfigRulesResponse{
+		ConfigModelWithRelatedUserInfo: configRulesModel(*updated),
+		Status:                         database.ConfigLogStatusApplied,
+	}, nil
+}
+
+func validateConfigRules(rules database.ConfigRules, currentConfig database.Config) error {
+	if rules.Validation == nil {
+		return nil
+	}
+
+	if err := validateRulesDefinition(*rules.Validation, currentConfig.Type); err != nil {
+		return err
+	}
+
+	if err := validateRules(*rules.Validation, currentConfig.Type, currentConfig.Value); err != nil {
+		return errors.Wrap(err, "current value does not satisfy new rules")
+	}
+
+	return nil
+}
+
+func setConfigRules(tx *gorm.DB, currentConfig database.Config, rules database.ConfigRules, userId int64) (*database.Config, error) {
+	var validation interface{}
+
+	if rules.Validation != nil {
+		validation = rules.Validation
+	}
+
+	if err := tx.Model(database.Config{}).Where("key = ?", currentConfig.Key).Updates(map[string]interface{}{
+		"validation":         validation,
+		"requires_approval":  rules.RequiresApproval,
+		"last_changed_by_id": userId,
+		"updated_at":         time.Now().UTC(),
+	}).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	currentConfig.Validation = rules.Validation
+	currentConfig.RequiresApproval = rules.RequiresApproval
+	currentConfig.LastChangedById = null.IntFrom(userId)
+
+	return &currentConfig, nil
+}
+
+func configRulesModel(cfg database.Config) ConfigModelWithRelatedUserInfo {
+	return ConfigModelWithRelatedUserInfo{
+		ConfigModel:      toConfigModel(cfg),
+		RelatedUserId:    cfg.LastChangedById,
+		Validation:       cfg.Validation,
+		RequiresApproval: cfg.RequiresApproval,
+	}
+}
+
+// AdminApproveConfigChange applies pending change. Change should be approved by admin other than the one who requested it
+func (c *ConfigService) AdminApproveConfigChange(tx *gorm.DB, req ReviewConfigChangeRequest, userId int64,
+	publisher eventsourcing.Publisher[eventsourcing.ConfigEvent]) (*UpsertConfigResponse, []callback.Callback, error) {
+	var pending database.ConfigLog
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? and status = ?", req.LogId, database.ConfigLogStatusPending).Find(&pending).Error; err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+	if pending.Id == 0 {
+		return nil, nil, errors.New("pending change not found")
+	}
+	if pending.RelatedUserId.Valid && pending.RelatedUserId.Int64 == userId {
+		return nil, nil, errors.New("change should be approved by another admin")
+	}
+
+	var currentConfig database.Config
+	if err := tx.Where("key = ?", pending.Key).Find(&currentConfig).Error; err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+	if len(currentConfig.Key) == 0 {
+		return nil, nil, errors.New("config doesn't exist")
+	}
+	if pending.Rules != nil {
+		return c.approveConfigRulesChange(tx, pending, currentConfig, req, userId)
+	}
+	if currentConfig.Value != pending.OldValue {
+		return nil, nil, errors.New("config was changed after the request, reject it and request again")
+	}
+
+	updated, err := setConfigValue(tx, currentConfig, pending.Value, pending.RelatedUserId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := tx.Model(database.ConfigLog{}).Where("id = ?", pending.Id).Updates(map[string]interface{}{
+		"status":         database.ConfigLogStatusApproved,
+		"value":          updated.Value,
+		"reviewed_by_id": userId,
+		"reviewed_at":    time.Now().UTC(),
+		"comment":        req.Comment,
+		"updated_at":     time.Now().UTC(),
+	}).Error; err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+
+	return &UpsertConfigResponse{
+		ConfigModel: toConfigModel(*updated),
+		Status:      database.ConfigLogStatusApproved,
+	}, configChangedCallbacks(*updated, publisher), nil
+}
+
+// approveConfigRulesChange applies pending rules, value is not changed so there is nothing to publish
+func (c *ConfigService) approveConfigRulesChange(tx *gorm.DB, pending database.ConfigLog, currentConfig database.Config,
+	req ReviewConfigChangeRequest, userId int64) (*UpsertConfigResponse, []callback.Callback, error) {
+	// value could be changed after the request, rules are checked against the current one
+	if err := validateConfigRules(*pending.Rules, currentConfig); err != nil {
+		return nil, nil, err
+	}
+
+	updated, err := setConfigRules(tx, currentConfig, *pending.Rules, pending.RelatedUserId.ValueOrZero())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := tx.Model(database.ConfigLog{}).Where("id = ?", pending.Id).Updates(map[string]interface{}{
+		"status":         database.ConfigLogStatusApproved,
+		"reviewed_by_id": userId,
+		"reviewed_at":    time.Now().UTC(),
+		"comment":        req.Comment,
+		"updated_at":     time.Now().UTC(),
+	}).Error; err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+
+	return &UpsertConfigResponse{
+		ConfigModel: toConfigModel(*updated),
+		Status:      database.ConfigLogStatusApproved,
+	}, nil, nil
+}
+
+func (c *ConfigService) AdminRejectConfigChange(tx *gorm.DB, req ReviewConfigChangeRequest, userId int64) error {
+	res := tx.Model(database.ConfigLog{}).
+		Where("id = ? and status = ?", req.LogId, database.ConfigLogStatusPending).
+		Updates(map[string]interface{}{
+			"status":         database.ConfigLogStatusRejected,
+			"reviewed_by_id": userId,
+			"reviewed_at":    time.Now().UTC(),
+			"comment":        req.Comment,
+			"updated_at":     time.Now().UTC(),
+		})
+	if res.Error != nil {
+		return errors.WithStack(res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return errors.New("pending change not found")
+	}
+
+	return nil
+}
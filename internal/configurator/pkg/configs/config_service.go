@@ -2,6 +2,7 @@ package configs
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"github.com/digitalmonsters/configurator/pkg/database"
 	"github.com/digitalmonsters/go-common/apm_helper"
@@ -15,16 +16,30 @@ import (
 	"github.com/thoas/go-funk"
 	"gopkg.in/guregu/null.v4"
 	"gorm.io/gorm"
+	"regexp"
 	"strconv"
+	"strings"
+	"time"
 )
 
 type IConfigService interface {
 	GetAllConfigs(db *gorm.DB) ([]database.Config, error)
 	GetConfigsByIds(db *gorm.DB, ids []string) ([]database.Config, error)
 	AdminGetConfigs(db *gorm.DB, req GetConfigRequest, executionData router.MethodExecutionData) (*GetConfigResponse, error)
-	AdminUpsertConfig(db *gorm.DB, req UpsertConfigRequest, userId int64, publisher eventsourcing.Publisher[eventsourcing.ConfigEvent]) (*application.ConfigModel, []callback.Callback, error)
+	AdminUpsertConfig(db *gorm.DB, req UpsertConfigRequest, userId int64, publisher eventsourcing.Publisher[eventsourcing.ConfigEvent]) (*UpsertConfigResponse, []callback.Callback, error)
 	AdminGetConfigLogs(db *gorm.DB, req GetConfigLogsRequest, executionData router.MethodExecutionData) (*GetConfigLogsResponse, error)
 	MigrateConfigs(db *gorm.DB, newConfigs map[string]application.MigrateConfigModel, publisher eventsourcing.Publisher[eventsourcing.ConfigEvent]) ([]application.ConfigModel, []callback.Callback, error)
+	AdminRollbackConfig(db *gorm.DB, req RollbackConfigRequest, userId int64, publisher eventsourcing.Publisher[eventsourcing.ConfigEvent]) (*UpsertConfigResponse, []callback.Callback, error)
+	AdminSetConfigRules(db *gorm.DB, req SetConfigRulesRequest, userId int64) (*SetConfigRulesResponse, error)
+	AdminApproveConfigChange(db *gorm.DB, req ReviewConfigChangeRequest, userId int64, publisher eventsourcing.Publisher[eventsourcing.ConfigEvent]) (*UpsertConfigResponse, []callback.Callback, error)
+	AdminRejectConfigChange(db *gorm.DB, req ReviewConfigChangeRequest, userId int64) error
+	AdminGetConfigDiff(db *gorm.DB, req GetConfigDiffRequest) (*GetConfigDiffResponse, error)
+	AdminScheduleConfigChange(db *gorm.DB, req ScheduleConfigChangeRequest, userId int64) (*database.ConfigScheduledChange, error)
+	AdminCancelScheduledChange(db *gorm.DB, req CancelScheduledChangeRequest, userId int64) error
+	AdminGetScheduledChanges(db *gorm.DB, req GetScheduledChangesRequest) (*GetScheduledChangesResponse, error)
+	ProcessScheduledChanges(db *gorm.DB, now time.Time, publisher eventsourcing.Publisher[eventsourcing.ConfigEvent], ctx context.Context) error
+	AdminExportConfigs(db *gorm.DB, req ExportConfigsRequest) (*ConfigSnapshot, error)
+	AdminImportConfigs(db *gorm.DB, req ImportConfigsRequest, userId int64, publisher eventsourcing.Publisher[eventsourcing.ConfigEvent]) (*ImportConfigsResponse, []callback.Callback, error)
 }
 
 type ConfigService struct {
@@ -36,7 +51,7 @@ func NewConfigService(authWrapper auth_go.IAuthGoWrapper) *ConfigService {
 }
 
 var allConfigTypes = []application.ConfigType{application.ConfigTypeDecimal, application.ConfigTypeInteger, application.ConfigTypeBool,
-	application.ConfigTypeString, application.ConfigTypeObject}
+	application.ConfigTypeString, application.ConfigTypeObject, application.ConfigTypeFlag}
 
 func (c *ConfigService) GetAllConfigs(db *gorm.DB) ([]database.Config, error) {
 	var cfg []database.Config
@@ -124,7 +139,9 @@ func (c *ConfigService) AdminGetConfigs(db *gorm.DB, req GetConfigRequest, execu
 				Category:       c.Category,
 				ReleaseVersion: c.ReleaseVersion,
 			},
-			RelatedUserId: c.LastChangedById,
+			RelatedUserId:    c.LastChangedById,
+			Validation:       c.Validation,
+			RequiresApproval: c.RequiresApproval,
 		})
 	}
 
@@ -188,11 +205,82 @@ func validateValueAccordingToType(reqType application.ConfigType, value string)
 		if value != "true" && value != "false" {
 			return errors.New("invalid value")
 		}
+	case application.ConfigTypeFlag:
+		_, err = application.ParseFeatureFlag("", value)
+	case application.ConfigTypeObject:
+		err = validateObjectValue(value)
 	}
 	return err
 }
 
-func (c ConfigService) AdminUpsertConfig(tx *gorm.DB, req UpsertConfigRequest, userId int64, publisher eventsourcing.Publisher[eventsourcing.ConfigEvent]) (*application.ConfigModel, []callback.Callback, error) {
+// validateObjectValue accepts only json object or array, as object configs are decoded into structs, maps and slices.
+// Blank value and null are accepted too, services decode them into zero value (see application parseJsonValue)
+func validateObjectValue(value string) error {
+	if len(strings.TrimSpace(value)) == 0 {
+		return nil
+	}
+
+	var parsed interface{}
+
+	if err := json.Unmarshal([]byte(value), &parsed); err != nil {
+		return errors.Wrap(err, "invalid json value")
+	}
+
+	switch parsed.(type) {
+	case map[string]interface{}, []interface{}, nil:
+		return nil
+	default:
+		return errors.New("object value should be json object or array")
+	}
+}
+
+// appReleaseVersionRegexp matches app versions like 2.5.0, release versions of older configs are dates like 29.07.22
+var appReleaseVersionRegexp = regexp.MustCompile(`^v?(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)$`)
+
+// isAppReleaseVersion reports whether version can be compared with client app version. Dates without leading zeros
+// (19.10.2026) look like versions too, so anything parsed as a date is rejected
+func isAppReleaseVersion(version string) bool {
+	if !appReleaseVersionRegexp.MatchString(version) {
+		return false
+	}
+
+	for _, layout := range []string{"02.01.2006", "02.01.06"} {
+		if _, err := time.Parse(layout, version); err == nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+// withFlagReleaseGate makes flag inherit config release version, so the flag is off for older app versions.
+// Release version set in the flag itself wins
+func withFlagReleaseGate(value string, releaseVersion string) (string, error) {
+	flag, err := application.ParseFeatureFlag("", value)
+	if err != nil {
+		return "", err
+	}
+
+	if len(flag.ReleaseVersion) > 0 {
+		return value, nil
+	}
+
+	if !isAppReleaseVersion(releaseVersion) {
+		return "", errors.New(fmt.Sprintf("config release version [%v] is not an app version, flag can not be gated by it",
+			releaseVersion))
+	}
+
+	flag.ReleaseVersion = releaseVersion
+
+	gated, err := json.Marshal(flag)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	return string(gated), nil
+}
+
+func (c ConfigService) AdminUpsertConfig(tx *gorm.DB, req UpsertConfigRequest, userId int64, publisher eventsourcing.Publisher[eventsourcing.ConfigEvent]) (*UpsertConfigResponse, []callback.Callback, error) {
 	if err := validateNewConfigRequest(req); err != nil {
 		return nil, nil, err
 	}
@@ -201,7 +289,6 @@ func (c ConfigService) AdminUpsertConfig(tx *gorm.DB, req UpsertConfigRequest, u
 		return nil, nil, err
 	}
 	if len(currentConfig.Key) > 0 {
-		var oldValue = currentConfig.Value
 		if len(currentConfig.Type) == 0 {
 			currentConfig.Type = req.Type
 		}
@@ -212,29 +299,127 @@ func (c ConfigService) AdminUpsertConfig(tx *gorm.DB, req UpsertConfigRequest, u
 			currentConfig.ReleaseVersion = req.ReleaseVersion
 		}
 		currentConfig.Description = req.Description
-		currentConfig.Value = req.Value
-		currentConfig.LastChangedById = null.IntFrom(userId)
+	} else {
+		return nil, nil, errors.New("config doesn't exist")
+	}
 
-		if err := tx.Where("key = ?", currentConfig.Key).Save(&currentConfig).Error; err != nil {
+	value := req.Value
+
+	if currentConfig.Type == application.ConfigTypeFlag && req.GateFlagByReleaseVersion {
+		gated, err := withFlagReleaseGate(value, currentConfig.ReleaseVersion)
+		if err != nil {
 			return nil, nil, err
 		}
-		if err := tx.Create(&database.ConfigLog{
-			Key:           req.Key,
-			OldValue:      oldValue,
-			Value:         req.Value,
-			RelatedUserId: null.IntFrom(userId),
-		}).Error; err != nil {
+
+		value = gated
+	}
+
+	return c.requestConfigChange(tx, currentConfig, value, userId, publisher)
+}
+
+// requestConfigChange applies new value, or stores it as pending change when config requires approval
+func (c *ConfigService) requestConfigChange(tx *gorm.DB, currentConfig database.Config, value string, userId int64,
+	publisher eventsourcing.Publisher[eventsourcing.ConfigEvent]) (*UpsertConfigResponse, []callback.Callback, error) {
+	if !currentConfig.RequiresApproval {
+		resp, callbacks, err := c.applyConfigValue(tx, currentConfig, value, null.IntFrom(userId), publisher)
+		if err != nil {
 			return nil, nil, err
 		}
-	} else {
-		return nil, nil, errors.New("config doesn't exist")
+
+		return &UpsertConfigResponse{ConfigModel: *resp, Status: database.ConfigLogStatusApplied}, callbacks, nil
 	}
-	callbacks := []callback.Callback{
+
+	if err := validateConfigValue(currentConfig, value); err != nil {
+		return nil, nil, err
+	}
+
+	if err := ensureNoPendingChange(tx, currentConfig.Key); err != nil {
+		return nil, nil, err
+	}
+
+	// description and other metadata are not sensitive, only the value waits for approval
+	if err := tx.Where("key = ?", currentConfig.Key).Save(&currentConfig).Error; err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+
+	pending := database.ConfigLog{
+		Key:           currentConfig.Key,
+		OldValue:      currentConfig.Value,
+		Value:         value,
+		RelatedUserId: null.IntFrom(userId),
+		Status:        database.ConfigLogStatusPending,
+	}
+	if err := tx.Create(&pending).Error; err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+
+	return &UpsertConfigResponse{
+		ConfigModel:  toConfigModel(currentConfig),
+		Status:       database.ConfigLogStatusPending,
+		PendingLogId: null.IntFrom(pending.Id),
+	}, nil, nil
+}
+
+func ensureNoPendingChange(tx *gorm.DB, key string) error {
+	var pendingCount int64
+	if err := tx.Model(database.ConfigLog{}).Where("key = ? and status = ?", key,
+		database.ConfigLogStatusPending).Count(&pendingCount).Error; err != nil {
+		return errors.WithStack(err)
+	}
+	if pendingCount > 0 {
+		return errors.New("config already has pending change")
+	}
+
+	return nil
+}
+
+// applyConfigValue saves new value of existing config, writes config log and returns callback which publishes ConfigEvent
+func (c *ConfigService) applyConfigValue(tx *gorm.DB, currentConfig database.Config, value string, userId null.Int,
+	publisher eventsourcing.Publisher[eventsourcing.ConfigEvent]) (*application.ConfigModel, []callback.Callback, error) {
+	var oldValue = currentConfig.Value
+
+	updated, err := setConfigValue(tx, currentConfig, value, userId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := tx.Create(&database.ConfigLog{
+		Key:           updated.Key,
+		OldValue:      oldValue,
+		Value:         updated.Value,
+		RelatedUserId: userId,
+	}).Error; err != nil {
+		return nil, nil, err
+	}
+
+	model := toConfigModel(*updated)
+
+	return &model, configChangedCallbacks(*updated, publisher), nil
+}
+
+// setConfigValue validates and saves new value without writing config log
+func setConfigValue(tx *gorm.DB, currentConfig database.Config, value string, userId null.Int) (*database.Config, error) {
+	if err := validateConfigValue(currentConfig, value); err != nil {
+		return nil, err
+	}
+
+	currentConfig.Value = value
+	currentConfig.LastChangedById = userId
+
+	if err := tx.Where("key = ?", currentConfig.Key).Save(&currentConfig).Error; err != nil {
+		return nil, err
+	}
+
+	return &currentConfig, nil
+}
+
+func configChangedCallbacks(cfg database.Config, publisher eventsourcing.Publisher[eventsourcing.ConfigEvent]) []callback.Callback {
+	return []callback.Callback{
 		func(ctx context.Context) error {
 			if publisher != nil {
 				err := <-publisher.PublishImmediate(ctx, eventsourcing.ConfigEvent{
-					Key:   currentConfig.Key,
-					Value: currentConfig.Value,
+					Key:   cfg.Key,
+					Value: cfg.Value,
 				})
 				if err != nil {
 					return err
@@ -243,17 +428,20 @@ func (c ConfigService) AdminUpsertConfig(tx *gorm.DB, req UpsertConfigRequest, u
 			return nil
 		},
 	}
-	return &application.ConfigModel{
-		Key:            currentConfig.Key,
-		Value:          currentConfig.Value,
-		Type:           currentConfig.Type,
-		Description:    currentConfig.Description,
-		AdminOnly:      currentConfig.AdminOnly,
-		CreatedAt:      currentConfig.CreatedAt,
-		UpdatedAt:      currentConfig.UpdatedAt,
-		Category:       currentConfig.Category,
-		ReleaseVersion: currentConfig.ReleaseVersion,
-	}, callbacks, nil
+}
+
+func toConfigModel(cfg database.Config) application.ConfigModel {
+	return application.ConfigModel{
+		Key:            cfg.Key,
+		Value:          cfg.Value,
+		Type:           cfg.Type,
+		Description:    cfg.Description,
+		AdminOnly:      cfg.AdminOnly,
+		CreatedAt:      cfg.CreatedAt,
+		UpdatedAt:      cfg.UpdatedAt,
+		Category:       cfg.Category,
+		ReleaseVersion: cfg.ReleaseVersion,
+	}
 }
 
 func (c *ConfigService) AdminGetConfigLogs(db *gorm.DB, req GetConfigLogsRequest, executionData router.MethodExecutionData) (*GetConfigLogsResponse, error) {
@@ -269,6 +457,9 @@ func (c *ConfigService) AdminGetConfigLogs(db *gorm.DB, req GetConfigLogsRequest
 	if req.KeyContains.Valid {
 		q = q.Where("key ilike ?", fmt.Sprintf("%%%s%%", req.KeyContains.ValueOrZero()))
 	}
+	if len(req.Statuses) > 0 {
+		q = q.Where("status in ?", req.Statuses)
+	}
 	if len(req.RelatedUserIds) > 0 {
 		q = q.Where("related_user_id in ?", req.RelatedUserIds)
 	}
@@ -321,6 +512,11 @@ func (c *ConfigService) AdminGetConfigLogs(db *gorm.DB, req GetConfigLogsRequest
 			CreatedAt:     it.CreatedAt,
 			UpdatedAt:     it.UpdatedAt,
 			RelatedUserId: it.RelatedUserId,
+			Status:        it.Status,
+			ReviewedById:  it.ReviewedById,
+			ReviewedAt:    it.ReviewedAt,
+			Comment:       it.Comment,
+			Rules:         it.Rules,
 		}
 		if usersMap != nil && it.RelatedUserId.Valid {
 			if userResp, ok := usersMap[it.RelatedUserId.ValueOrZero()]; ok {
@@ -406,3 +602,71 @@ func (c *ConfigService) MigrateConfigs(tx *gorm.DB, newConfigs map[string]applic
 	}
 	return configModels, callbacks, nil
 }
+
+// AdminRollbackConfig restores config to the value from config log, the rollback itself is logged as a new change
+func (c *ConfigService) AdminRollbackConfig(tx *gorm.DB, req RollbackConfigRequest, userId int64,
+	publisher eventsourcing.Publisher[eventsourcing.ConfigEvent]) (*UpsertConfigResponse, []callback.Callback, error) {
+	var configLog database.ConfigLog
+	if err := tx.Where("id = ?", req.LogId).Find(&configLog).Error; err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+	if configLog.Id == 0 {
+		return nil, nil, errors.New("config log not found")
+	}
+	if configLog.Rules != nil {
+		return nil, nil, errors.New("rules change can not be rolled back, set the rules again")
+	}
+	// pending and rejected values were never applied, rolling back to them would skip the approval
+	if !funk.Contains(database.AppliedConfigLogStatuses, configLog.Status) {
+		return nil, nil, errors.New(fmt.Sprintf("config log with status %v can not be rolled back", configLog.Status))
+	}
+
+	var currentConfig database.Config
+	if err := tx.Where("key = ?", configLog.Key).Find(&currentConfig).Error; err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+	if len(currentConfig.Key) == 0 {
+		return nil, nil, errors.New("config doesn't exist")
+	}
+
+	value := configLog.Value
+	if req.RestoreOldValue {
+		value = configLog.OldValue
+	}
+
+	return c.requestConfigChange(tx, currentConfig, value, userId, publisher)
+}
+
+// AdminGetConfigDiff returns value of every config changed in [from, to] at the beginning and at the end of the range
+func (c *ConfigService) AdminGetConfigDiff(db *gorm.DB, req GetConfigDiffRequest) (*GetConfigDiffResponse, error) {
+	if req.From.IsZero() || req.To.IsZero() {
+		return nil, errors.New("from and to are required")
+	}
+	if req.To.Before(req.From) {
+		return nil, errors.New("to should be after from")
+	}
+
+	q := db.Model(database.ConfigLog{}).
+		Select(`key,
+(array_agg(old_value order by created_at, id))[1] as from_value,
+(array_agg(value order by created_at desc, id desc))[1] as to_value,
+count(*) as changes_count,
+max(created_at) as last_change_at`).
+		Where("created_at >= ? and created_at <= ?", req.From, req.To).
+		Where("status in ? and rules is null", database.AppliedConfigLogStatuses)
+
+	if len(req.Keys) > 0 {
+		q = q.Where("key in ?", req.Keys)
+	}
+
+	var items []ConfigDiffItem
+	if err := q.Group("key").Order("key").Scan(&items).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	for i := range items {
+		items[i].Changed = items[i].FromValue != items[i].ToValue
+	}
+
+	return &GetConfigDiffResponse{Items: items}, nil
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/digitalmonsters/go-common/eventsourcing"
 	"github.com/digitalmonsters/go-common/router"
 	"github.com/digitalmonsters/go-common/wrappers/auth_go"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/thoas/go-funk"
 	"go.elastic.co/apm"
@@ -445,7 +446,7 @@ func TestConfigService_AdminUpsertConfig(t *testing.T) {
 	if err := gormDb.Where("key = ?", req.Key).Find(&cfg).Error; err != nil {
 		t.Fatal(err)
 	}
-	checkConfigModel(t, cfg, *resp)
+	checkConfigModel(t, cfg, resp.ConfigModel)
 
 	req = UpsertConfigRequest{
 		Key:            "test key 3",
@@ -480,7 +481,7 @@ func TestConfigService_AdminUpsertConfig(t *testing.T) {
 	if err := gormDb.Where("key = ?", req.Key).Find(&cfg2).Error; err != nil {
 		t.Fatal(err)
 	}
-	checkConfigModel(t, cfg2, *resp)
+	checkConfigModel(t, cfg2, resp.ConfigModel)
 
 	configsResp, err := service.GetAllConfigs(gormDb)
 	if err != nil {
@@ -489,6 +490,212 @@ func TestConfigService_AdminUpsertConfig(t *testing.T) {
 	assert.Equal(t, 1, len(configsResp))
 }
 
+func TestConfigService_AdminSetConfigRules(t *testing.T) {
+	if err := boilerplate_testing.FlushPostgresAllTables(config.MasterDb, []string{"public.config"}, t); err != nil {
+		t.Fatal(err)
+	}
+
+	min := decimal.NewFromInt(10)
+	if err := gormDb.Create(&database.Config{
+		Key:              "approved key",
+		Value:            "50",
+		Type:             application.ConfigTypeInteger,
+		Validation:       &database.ValidationRules{Min: &min},
+		RequiresApproval: true,
+	}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	// tightening is applied at once
+	max := decimal.NewFromInt(100)
+	resp, err := service.AdminSetConfigRules(gormDb, SetConfigRulesRequest{
+		Key:              "approved key",
+		Validation:       &database.ValidationRules{Min: &min, Max: &max},
+		RequiresApproval: true,
+	}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, database.ConfigLogStatusApplied, resp.Status)
+	assert.True(t, resp.Validation.Max.Equal(max))
+
+	// turning approval off waits for another admin
+	resp, err = service.AdminSetConfigRules(gormDb, SetConfigRulesRequest{Key: "approved key"}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, database.ConfigLogStatusPending, resp.Status)
+	assert.True(t, resp.RequiresApproval)
+
+	var cfg database.Config
+	if err := gormDb.Where("key = ?", "approved key").Find(&cfg).Error; err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, cfg.RequiresApproval)
+	assert.NotNil(t, cfg.Validation)
+
+	_, _, err = service.AdminApproveConfigChange(gormDb, ReviewConfigChangeRequest{LogId: resp.PendingLogId.Int64}, 1, nil)
+	assert.NotNil(t, err)
+
+	approved, callbacks, err := service.AdminApproveConfigChange(gormDb,
+		ReviewConfigChangeRequest{LogId: resp.PendingLogId.Int64}, 2, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, database.ConfigLogStatusApproved, approved.Status)
+	assert.Equal(t, "50", approved.Value)
+	assert.Empty(t, callbacks)
+
+	cfg = database.Config{}
+	if err := gormDb.Where("key = ?", "approved key").Find(&cfg).Error; err != nil {
+		t.Fatal(err)
+	}
+	assert.False(t, cfg.RequiresApproval)
+	assert.Nil(t, cfg.Validation)
+}
+
+func TestConfigService_AdminRollbackConfig(t *testing.T) {
+	if err := boilerplate_testing.FlushPostgresAllTables(config.MasterDb, []string{"public.config"}, t); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := gormDb.Create(&database.Config{
+		Key:   "rollback key",
+		Value: "50",
+		Type:  application.ConfigTypeInteger,
+	}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := service.AdminUpsertConfig(gormDb, UpsertConfigRequest{
+		Key:            "rollback key",
+		Value:          "70",
+		Type:           application.ConfigTypeInteger,
+		Description:    "rollback",
+		Category:       application.ConfigCategoryTokens,
+		ReleaseVersion: "1.0.0",
+	}, 1, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	var changeLog database.ConfigLog
+	if err := gormDb.Where("key = ? and value = ?", "rollback key", "70").Order("id desc").Find(&changeLog).Error; err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "50", changeLog.OldValue)
+
+	resp, _, err := service.AdminRollbackConfig(gormDb, RollbackConfigRequest{LogId: changeLog.Id, RestoreOldValue: true}, 2, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "50", resp.Value)
+	assert.Equal(t, database.ConfigLogStatusApplied, resp.Status)
+
+	resp, _, err = service.AdminRollbackConfig(gormDb, RollbackConfigRequest{LogId: changeLog.Id}, 2, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "70", resp.Value)
+
+	var cfg database.Config
+	if err := gormDb.Where("key = ?", "rollback key").Find(&cfg).Error; err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "70", cfg.Value)
+
+	// every rollback is logged as a new change
+	var logs []database.ConfigLog
+	if err := gormDb.Where("key = ? and id > ?", "rollback key", changeLog.Id).Order("id").Find(&logs).Error; err != nil {
+		t.Fatal(err)
+	}
+	if assert.Equal(t, 2, len(logs)) {
+		assert.Equal(t, "70", logs[0].OldValue)
+		assert.Equal(t, "50", logs[0].Value)
+		assert.Equal(t, "50", logs[1].OldValue)
+		assert.Equal(t, "70", logs[1].Value)
+	}
+}
+
+func TestConfigService_AdminGetConfigDiff(t *testing.T) {
+	if err := boilerplate_testing.FlushPostgresAllTables(config.MasterDb, []string{"public.config"}, t); err != nil {
+		t.Fatal(err)
+	}
+
+	from := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+
+	if err := gormDb.Create(&[]database.ConfigLog{
+		{Key: "diff key", OldValue: "1", Value: "2", CreatedAt: from.Add(-time.Hour)}, // before the range
+		{Key: "diff key", OldValue: "2", Value: "3", CreatedAt: from.Add(time.Hour)},
+		{Key: "diff key", OldValue: "3", Value: "4", CreatedAt: from.Add(2 * time.Hour), Status: database.ConfigLogStatusRejected},
+		{Key: "diff key", OldValue: "3", Value: "5", CreatedAt: from.Add(3 * time.Hour)},
+		{Key: "diff key", OldValue: "5", Value: "6", CreatedAt: to.Add(time.Hour)}, // after the range
+		{Key: "diff same key", OldValue: "a", Value: "b", CreatedAt: from.Add(time.Hour)},
+		{Key: "diff same key", OldValue: "b", Value: "a", CreatedAt: from.Add(2 * time.Hour)},
+	}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := service.AdminGetConfigDiff(gormDb, GetConfigDiffRequest{
+		From: from,
+		To:   to,
+		Keys: []string{"diff key", "diff same key"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if assert.Equal(t, 2, len(resp.Items)) {
+		assert.Equal(t, "diff key", resp.Items[0].Key)
+		assert.Equal(t, "2", resp.Items[0].FromValue)
+		assert.Equal(t, "5", resp.Items[0].ToValue)
+		assert.True(t, resp.Items[0].Changed)
+		assert.Equal(t, int64(2), resp.Items[0].ChangesCount)
+		assert.True(t, resp.Items[0].LastChangeAt.Equal(from.Add(3*time.Hour)))
+
+		assert.Equal(t, "diff same key", resp.Items[1].Key)
+		assert.Equal(t, "a", resp.Items[1].FromValue)
+		assert.Equal(t, "a", resp.Items[1].ToValue)
+		assert.False(t, resp.Items[1].Changed)
+	}
+
+	_, err = service.AdminGetConfigDiff(gormDb, GetConfigDiffRequest{From: to, To: from})
+	assert.NotNil(t, err)
+}
+
+func TestConfigService_AdminRollbackConfig_NotApplied(t *testing.T) {
+	if err := boilerplate_testing.FlushPostgresAllTables(config.MasterDb, []string{"public.config"}, t); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := gormDb.Create(&database.Config{
+		Key:   "rollback key",
+		Value: "50",
+		Type:  application.ConfigTypeInteger,
+	}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	logs := []database.ConfigLog{
+		{Key: "rollback key", Value: "70", OldValue: "50", Status: database.ConfigLogStatusPending},
+		{Key: "rollback key", Value: "90", OldValue: "50", Status: database.ConfigLogStatusRejected},
+	}
+	if err := gormDb.Create(&logs).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	for _, configLog := range logs {
+		_, _, err := service.AdminRollbackConfig(gormDb, RollbackConfigRequest{LogId: configLog.Id}, 1, nil)
+		assert.NotNil(t, err, configLog.Status)
+	}
+
+	var cfg database.Config
+	if err := gormDb.Where("key = ?", "rollback key").Find(&cfg).Error; err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "50", cfg.Value)
+}
+
 func TestConfigService_MigrateConfigs(t *testing.T) {
 	if err := boilerplate_testing.FlushPostgresAllTables(config.MasterDb, []string{"public.config"}, t); err != nil {
 		t.Fatal(err)
@@ -654,3 +861,41 @@ func TestConfigService_MigrateConfigs(t *testing.T) {
 	}
 	assert.Equal(t, 1, foundCounter)
 }
+
+func TestWithFlagReleaseGate(t *testing.T) {
+	gated, err := withFlagReleaseGate(`{"enabled":true,"default_variant":"on"}`, "2.5.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flag, err := application.ParseFeatureFlag("", gated)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "2.5.0", flag.ReleaseVersion)
+	assert.True(t, flag.Enabled)
+
+	// release version of the flag itself is kept
+	value := `{"enabled":true,"release_version":"3.0.0"}`
+
+	gated, err = withFlagReleaseGate(value, "2.5.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, value, gated)
+
+	_, err = withFlagReleaseGate(`{"enabled":`, "2.5.0")
+	assert.NotNil(t, err)
+
+	// release versions of older configs are dates, not app versions
+	for _, releaseVersion := range []string{"29.07.22", "29.07.2022", "19.10.2026", "2.5", ""} {
+		_, err = withFlagReleaseGate(`{"enabled":true}`, releaseVersion)
+		assert.NotNil(t, err, releaseVersion)
+	}
+
+	gated, err = withFlagReleaseGate(`{"enabled":true}`, "v10.0.1")
+	assert.Nil(t, err)
+	assert.Contains(t, gated, "v10.0.1")
+}
@@ -1,6 +1,9 @@
 package configs
 
 import (
+	"context"
+	"time"
+
 	"github.com/digitalmonsters/configurator/pkg/database"
 	"github.com/digitalmonsters/go-common/application"
 	"github.com/digitalmonsters/go-common/callback"
@@ -10,12 +13,23 @@ import (
 )
 
 type ConfigServiceMock struct {
-	GetAllConfigsFn      func(db *gorm.DB) ([]database.Config, error)
-	GetConfigsByIdsFn    func(db *gorm.DB, ids []string) ([]database.Config, error)
-	AdminGetConfigsFn    func(db *gorm.DB, req GetConfigRequest, executionData router.MethodExecutionData) (*GetConfigResponse, error)
-	AdminUpsertConfigFn  func(db *gorm.DB, req UpsertConfigRequest, userId int64, publisher eventsourcing.Publisher[eventsourcing.ConfigEvent]) (*application.ConfigModel, []callback.Callback, error)
-	AdminGetConfigLogsFn func(db *gorm.DB, req GetConfigLogsRequest, executionData router.MethodExecutionData) (*GetConfigLogsResponse, error)
-	MigrateConfigsFn     func(db *gorm.DB, newConfigs map[string]application.MigrateConfigModel, publisher eventsourcing.Publisher[eventsourcing.ConfigEvent]) ([]application.ConfigModel, []callback.Callback, error)
+	GetAllConfigsFn              func(db *gorm.DB) ([]database.Config, error)
+	GetConfigsByIdsFn            func(db *gorm.DB, ids []string) ([]database.Config, error)
+	AdminGetConfigsFn            func(db *gorm.DB, req GetConfigRequest, executionData router.MethodExecutionData) (*GetConfigResponse, error)
+	AdminUpsertConfigFn          func(db *gorm.DB, req UpsertConfigRequest, userId int64, publisher eventsourcing.Publisher[eventsourcing.ConfigEvent]) (*UpsertConfigResponse, []callback.Callback, error)
+	AdminGetConfigLogsFn         func(db *gorm.DB, req GetConfigLogsRequest, executionData router.MethodExecutionData) (*GetConfigLogsResponse, error)
+	MigrateConfigsFn             func(db *gorm.DB, newConfigs map[string]application.MigrateConfigModel, publisher eventsourcing.Publisher[eventsourcing.ConfigEvent]) ([]application.ConfigModel, []callback.Callback, error)
+	AdminRollbackConfigFn        func(db *gorm.DB, req RollbackConfigRequest, userId int64, publisher eventsourcing.Publisher[eventsourcing.ConfigEvent]) (*UpsertConfigResponse, []callback.Callback, error)
+	AdminSetConfigRulesFn        func(db *gorm.DB, req SetConfigRulesRequest, userId int64) (*SetConfigRulesResponse, error)
+	AdminApproveConfigChangeFn   func(db *gorm.DB, req ReviewConfigChangeRequest, userId int64, publisher eventsourcing.Publisher[eventsourcing.ConfigEvent]) (*UpsertConfigResponse, []callback.Callback, error)
+	AdminRejectConfigChangeFn    func(db *gorm.DB, req ReviewConfigChangeRequest, userId int64) error
+	AdminGetConfigDiffFn         func(db *gorm.DB, req GetConfigDiffRequest) (*GetConfigDiffResponse, error)
+	AdminScheduleConfigChangeFn  func(db *gorm.DB, req ScheduleConfigChangeRequest, userId int64) (*database.ConfigScheduledChange, error)
+	AdminCancelScheduledChangeFn func(db *gorm.DB, req CancelScheduledChangeRequest, userId int64) error
+	AdminGetScheduledChangesFn   func(db *gorm.DB, req GetScheduledChangesRequest) (*GetScheduledChangesResponse, error)
+	ProcessScheduledChangesFn    func(db *gorm.DB, now time.Time, publisher eventsourcing.Publisher[eventsourcing.ConfigEvent], ctx context.Context) error
+	AdminExportConfigsFn         func(db *gorm.DB, req ExportConfigsRequest) (*ConfigSnapshot, error)
+	AdminImportConfigsFn         func(db *gorm.DB, req ImportConfigsRequest, userId int64, publisher eventsourcing.Publisher[eventsourcing.ConfigEvent]) (*ImportConfigsResponse, []callback.Callback, error)
 }
 
 func (c *ConfigServiceMock) GetAllConfigs(db *gorm.DB) ([]database.Config, error) {
@@ -28,7 +42,7 @@ func (c *ConfigServiceMock) AdminGetConfigs(db *gorm.DB, req GetConfigRequest, e
 	return c.AdminGetConfigsFn(db, req, executionData)
 }
 func (c *ConfigServiceMock) AdminUpsertConfig(db *gorm.DB, req UpsertConfigRequest, userId int64,
-	publisher eventsourcing.Publisher[eventsourcing.ConfigEvent]) (*application.ConfigModel, []callback.Callback, error) {
+	publisher eventsourcing.Publisher[eventsourcing.ConfigEvent]) (*UpsertConfigResponse, []callback.Callback, error) {
 	return c.AdminUpsertConfigFn(db, req, userId, publisher)
 }
 func (c *ConfigServiceMock) AdminGetConfigLogs(db *gorm.DB, req GetConfigLogsRequest, executionData router.MethodExecutionData) (*GetConfigLogsResponse, error) {
@@ -38,6 +52,45 @@ func (c *ConfigServiceMock) MigrateConfigs(db *gorm.DB, newConfigs map[string]ap
 	return c.MigrateConfigsFn(db, newConfigs, publisher)
 }
 
+func (c *ConfigServiceMock) AdminRollbackConfig(db *gorm.DB, req RollbackConfigRequest, userId int64,
+	publisher eventsourcing.Publisher[eventsourcing.ConfigEvent]) (*UpsertConfigResponse, []callback.Callback, error) {
+	return c.AdminRollbackConfigFn(db, req, userId, publisher)
+}
+func (c *ConfigServiceMock) AdminSetConfigRules(db *gorm.DB, req SetConfigRulesRequest, userId int64) (*SetConfigRulesResponse, error) {
+	return c.AdminSetConfigRulesFn(db, req, userId)
+}
+func (c *ConfigServiceMock) AdminApproveConfigChange(db *gorm.DB, req ReviewConfigChangeRequest, userId int64,
+	publisher eventsourcing.Publisher[eventsourcing.ConfigEvent]) (*UpsertConfigResponse, []callback.Callback, error) {
+	return c.AdminApproveConfigChangeFn(db, req, userId, publisher)
+}
+func (c *ConfigServiceMock) AdminRejectConfigChange(db *gorm.DB, req ReviewConfigChangeRequest, userId int64) error {
+	return c.AdminRejectConfigChangeFn(db, req, userId)
+}
+func (c *ConfigServiceMock) AdminGetConfigDiff(db *gorm.DB, req GetConfigDiffRequest) (*GetConfigDiffResponse, error) {
+	return c.AdminGetConfigDiffFn(db, req)
+}
+func (c *ConfigServiceMock) AdminScheduleConfigChange(db *gorm.DB, req ScheduleConfigChangeRequest, userId int64) (*database.ConfigScheduledChange, error) {
+	return c.AdminScheduleConfigChangeFn(db, req, userId)
+}
+func (c *ConfigServiceMock) AdminCancelScheduledChange(db *gorm.DB, req CancelScheduledChangeRequest, userId int64) error {
+	return c.AdminCancelScheduledChangeFn(db, req, userId)
+}
+func (c *ConfigServiceMock) AdminGetScheduledChanges(db *gorm.DB, req GetScheduledChangesRequest) (*GetScheduledChangesResponse, error) {
+	return c.AdminGetScheduledChangesFn(db, req)
+}
+func (c *ConfigServiceMock) ProcessScheduledChanges(db *gorm.DB, now time.Time,
+	publisher eventsourcing.Publisher[eventsourcing.ConfigEvent], ctx context.Context) error {
+	return c.ProcessScheduledChangesFn(db, now, publisher, ctx)
+}
+
+func (c *ConfigServiceMock) AdminExportConfigs(db *gorm.DB, req ExportConfigsRequest) (*ConfigSnapshot, error) {
+	return c.AdminExportConfigsFn(db, req)
+}
+func (c *ConfigServiceMock) AdminImportConfigs(db *gorm.DB, req ImportConfigsRequest, userId int64,
+	publisher eventsourcing.Publisher[eventsourcing.ConfigEvent]) (*ImportConfigsResponse, []callback.Callback, error) {
+	return c.AdminImportConfigsFn(db, req, userId, publisher)
+}
+
 func GetMock() IConfigService {
 	return &ConfigServiceMock{}
 }
@@ -0,0 +1,251 @@
+package configs
+
+import (
+	"context"
+	"time"
+
+	"github.com/digitalmonsters/configurator/pkg/database"
+	"github.com/digitalmonsters/go-common/apm_helper"
+	"github.com/digitalmonsters/go-common/callback"
+	"github.com/digitalmonsters/go-common/eventsourcing"
+	"github.com/pkg/errors"
+	"gopkg.in/guregu/null.v4"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const scheduledChangesBatchSize = 100
+
+func (c *ConfigService) AdminScheduleConfigChange(db *gorm.DB, req ScheduleConfigChangeRequest, userId int64) (*database.ConfigScheduledChange, error) {
+	if req.ApplyAt.IsZero() {
+		return nil, errors.New("apply_at is required")
+	}
+	if req.ApplyAt.Before(time.Now().UTC().Add(-1 * time.Minute)) {
+		return nil, errors.New("apply_at should be in future")
+	}
+	if req.RevertAfterMinutes < 0 {
+		return nil, errors.New("revert_after_minutes should be positive")
+	}
+
+	var currentConfig database.Config
+	if err := db.Where("key = ?", req.Key).Find(&currentConfig).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if len(currentConfig.Key) == 0 {
+		return nil, errors.New("config doesn't exist")
+	}
+	if currentConfig.RequiresApproval {
+		return nil, errors.New("config requires approval and can not be scheduled")
+	}
+	if err := validateConfigValue(currentConfig, req.Value); err != nil {
+		return nil, err
+	}
+
+	change := database.ConfigScheduledChange{
+		Key:         req.Key,
+		Value:       req.Value,
+		ApplyAt:     req.ApplyAt.UTC(),
+		Status:      database.ScheduledChangeStatusPending,
+		CreatedById: userId,
+	}
+
+	if req.RevertAfterMinutes > 0 {
+		change.RevertAt = null.TimeFrom(change.ApplyAt.Add(time.Duration(req.RevertAfterMinutes) * time.Minute))
+	}
+
+	if err := db.Create(&change).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return &change, nil
+}
+
+// AdminCancelScheduledChange cancels pending change. For already applied change it cancels only the pending revert
+func (c *ConfigService) AdminCancelScheduledChange(db *gorm.DB, req CancelScheduledChangeRequest, userId int64) error {
+	res := db.Model(database.ConfigScheduledChange{}).
+		Where("id = ? and status = ?", req.Id, database.ScheduledChangeStatusPending).
+		Updates(map[string]interface{}{
+			"status":          database.ScheduledChangeStatusCancelled,
+			"cancelled_by_id": userId,
+			"updated_at":      time.Now().UTC(),
+		})
+	if res.Error != nil {
+		return errors.WithStack(res.Error)
+	}
+	if res.RowsAffected > 0 {
+		return nil
+	}
+
+	res = db.Model(database.ConfigScheduledChange{}).
+		Where("id = ? and status = ? and revert_at is not null", req.Id, database.ScheduledChangeStatusApplied).
+		Updates(map[string]interface{}{
+			"revert_at":       nil,
+			"cancelled_by_id": userId,
+			"updated_at":      time.Now().UTC(),
+		})
+	if res.Error != nil {
+		return errors.WithStack(res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return errors.New("scheduled change not found or already processed")
+	}
+
+	return nil
+}
+
+func (c *ConfigService) AdminGetScheduledChanges(db *gorm.DB, req GetScheduledChangesRequest) (*GetScheduledChangesResponse, error) {
+	var items []database.ConfigScheduledChange
+
+	q := db.Model(database.ConfigScheduledChange{})
+	if len(req.Keys) > 0 {
+		q = q.Where("key in ?", req.Keys)
+	}
+	if len(req.Statuses) > 0 {
+		q = q.Where("status in ?", req.Statuses)
+	}
+
+	var count int64
+	if err := q.Count(&count).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if req.Limit > 0 {
+		q = q.Limit(req.Limit)
+	}
+
+	if err := q.Order("apply_at desc").Offset(req.Offset).Find(&items).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return &GetScheduledChangesResponse{
+		Items:      items,
+		TotalCount: count,
+	}, nil
+}
+
+// ProcessScheduledChanges applies due changes and reverts expired ones. Every change is processed in own transaction,
+// rows are locked with skip locked, so it is safe to run on several instances
+func (c *ConfigService) ProcessScheduledChanges(db *gorm.DB, now time.Time,
+	publisher eventsourcing.Publisher[eventsourcing.ConfigEvent], ctx context.Context) error {
+	var applyIds []int64
+	if err := db.Model(database.ConfigScheduledChange{}).
+		Where("status = ? and apply_at <= ?", database.ScheduledChangeStatusPending, now).
+		Order("apply_at").Limit(scheduledChangesBatchSize).Pluck("id", &applyIds).Error; err != nil {
+		return errors.WithStack(err)
+	}
+
+	for _, id := range applyIds {
+		if err := c.processScheduledChange(db, id, now, false, publisher, ctx); err != nil {
+			apm_helper.LogError(err, ctx)
+		}
+	}
+
+	var revertIds []int64
+	if err := db.Model(database.ConfigScheduledChange{}).
+		Where("status = ? and revert_at <= ?", database.ScheduledChangeStatusApplied, now).
+		Order("revert_at").Limit(scheduledChangesBatchSize).Pluck("id", &revertIds).Error; err != nil {
+		return errors.WithStack(err)
+	}
+
+	for _, id := range revertIds {
+		if err := c.processScheduledChange(db, id, now, true, publisher, ctx); err != nil {
+			apm_helper.LogError(err, ctx)
+		}
+	}
+
+	return nil
+}
+
+func (c *ConfigService) processScheduledChange(db *gorm.DB, id int64, now time.Time, revert bool,
+	publisher eventsourcing.Publisher[eventsourcing.ConfigEvent], ctx context.Context) error {
+	expectedStatus := database.ScheduledChangeStatusPending
+	if revert {
+		expectedStatus = database.ScheduledChangeStatusApplied
+	}
+
+	tx := db.Begin()
+	defer tx.Rollback()
+
+	var change database.ConfigScheduledChange
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("id = ? and status = ?", id, expectedStatus).Find(&change).Error; err != nil {
+		return errors.WithStack(err)
+	}
+	if change.Id == 0 { // processed by another instance or cancelled
+		return nil
+	}
+
+	var currentConfig database.Config
+	if err := tx.Where("key = ?", change.Key).Find(&currentConfig).Error; err != nil {
+		return errors.WithStack(err)
+	}
+
+	var callbacks []callback.Callback
+	updates := map[string]interface{}{
+		"updated_at": now,
+	}
+
+	switch {
+	case len(currentConfig.Key) == 0:
+		updates["status"] = database.ScheduledChangeStatusFailed
+		updates["error"] = "config doesn't exist"
+	case currentConfig.RequiresApproval:
+		updates["status"] = database.ScheduledChangeStatusFailed
+		updates["error"] = "config requires approval"
+	case revert && currentConfig.Value != change.Value:
+		updates["status"] = database.ScheduledChangeStatusRevertSkipped
+		updates["error"] = "config was changed after scheduled change was applied"
+	default:
+		value := change.Value
+		if revert {
+			value = change.PreviousValue.ValueOrZero()
+		}
+
+		resp, applyCallbacks, err := c.applyConfigValue(tx, currentConfig, value, null.IntFrom(change.CreatedById), publisher)
+		if err != nil {
+			tx.Rollback()
+
+			return c.failScheduledChange(db, change.Id, now, err)
+		}
+
+		callbacks = applyCallbacks
+
+		if revert {
+			updates["status"] = database.ScheduledChangeStatusReverted
+			updates["reverted_at"] = now
+		} else {
+			updates["status"] = database.ScheduledChangeStatusApplied
+			updates["applied_at"] = now
+			updates["previous_value"] = currentConfig.Value
+			updates["value"] = resp.Value // saved value, used to detect manual changes before revert
+		}
+	}
+
+	if err := tx.Model(database.ConfigScheduledChange{}).Where("id = ?", change.Id).Updates(updates).Error; err != nil {
+		return errors.WithStack(err)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return errors.WithStack(err)
+	}
+
+	for _, callbackFn := range callbacks {
+		if err := callbackFn(ctx); err != nil {
+			apm_helper.LogError(err, ctx)
+		}
+	}
+
+	return nil
+}
+
+func (c *ConfigService) failScheduledChange(db *gorm.DB, id int64, now time.Time, cause error) error {
+	if err := db.Model(database.ConfigScheduledChange{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":     database.ScheduledChangeStatusFailed,
+		"error":      cause.Error(),
+		"updated_at": now,
+	}).Error; err != nil {
+		return errors.WithStack(err)
+	}
+
+	return cause
+}
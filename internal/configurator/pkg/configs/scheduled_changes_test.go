@@ -0,0 +1,152 @@
+package configs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/digitalmonsters/configurator/pkg/database"
+	"github.com/digitalmonsters/go-common/application"
+	"github.com/digitalmonsters/go-common/boilerplate_testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func createScheduledChangeConfig(t *testing.T, key string, value string) {
+	if err := boilerplate_testing.FlushPostgresAllTables(config.MasterDb, []string{"public.config"}, t); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := gormDb.Create(&database.Config{
+		Key:   key,
+		Value: value,
+		Type:  application.ConfigTypeInteger,
+	}).Error; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func getScheduledChange(t *testing.T, id int64) database.ConfigScheduledChange {
+	var change database.ConfigScheduledChange
+	if err := gormDb.Where("id = ?", id).Find(&change).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	return change
+}
+
+func getConfigValue(t *testing.T, key string) string {
+	var cfg database.Config
+	if err := gormDb.Where("key = ?", key).Find(&cfg).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	return cfg.Value
+}
+
+func TestConfigService_ProcessScheduledChanges_Revert(t *testing.T) {
+	createScheduledChangeConfig(t, "scheduled key", "50")
+
+	now := time.Now().UTC()
+	change, err := service.AdminScheduleConfigChange(gormDb, ScheduleConfigChangeRequest{
+		Key:                "scheduled key",
+		Value:              "70",
+		ApplyAt:            now,
+		RevertAfterMinutes: 10,
+	}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := service.ProcessScheduledChanges(gormDb, now, nil, context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	applied := getScheduledChange(t, change.Id)
+	assert.Equal(t, database.ScheduledChangeStatusApplied, applied.Status)
+	assert.Equal(t, "50", applied.PreviousValue.ValueOrZero())
+	assert.Equal(t, "70", getConfigValue(t, "scheduled key"))
+
+	if err := service.ProcessScheduledChanges(gormDb, now.Add(11*time.Minute), nil, context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, database.ScheduledChangeStatusReverted, getScheduledChange(t, change.Id).Status)
+	assert.Equal(t, "50", getConfigValue(t, "scheduled key"))
+}
+
+func TestConfigService_ProcessScheduledChanges_RevertSkippedAfterManualChange(t *testing.T) {
+	createScheduledChangeConfig(t, "scheduled key", "50")
+
+	now := time.Now().UTC()
+	change, err := service.AdminScheduleConfigChange(gormDb, ScheduleConfigChangeRequest{
+		Key:                "scheduled key",
+		Value:              "70",
+		ApplyAt:            now,
+		RevertAfterMinutes: 10,
+	}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := service.ProcessScheduledChanges(gormDb, now, nil, context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := service.AdminUpsertConfig(gormDb, UpsertConfigRequest{
+		Key:            "scheduled key",
+		Value:          "80",
+		Type:           application.ConfigTypeInteger,
+		Description:    "scheduled",
+		Category:       application.ConfigCategoryTokens,
+		ReleaseVersion: "1.0.0",
+	}, 2, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := service.ProcessScheduledChanges(gormDb, now.Add(11*time.Minute), nil, context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	skipped := getScheduledChange(t, change.Id)
+	assert.Equal(t, database.ScheduledChangeStatusRevertSkipped, skipped.Status)
+	assert.True(t, skipped.Error.Valid)
+	assert.Equal(t, "80", getConfigValue(t, "scheduled key"))
+}
+
+func TestConfigService_AdminCancelScheduledChange_AppliedRevert(t *testing.T) {
+	createScheduledChangeConfig(t, "scheduled key", "50")
+
+	now := time.Now().UTC()
+	change, err := service.AdminScheduleConfigChange(gormDb, ScheduleConfigChangeRequest{
+		Key:                "scheduled key",
+		Value:              "70",
+		ApplyAt:            now,
+		RevertAfterMinutes: 10,
+	}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := service.ProcessScheduledChanges(gormDb, now, nil, context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := service.AdminCancelScheduledChange(gormDb, CancelScheduledChangeRequest{Id: change.Id}, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	cancelled := getScheduledChange(t, change.Id)
+	assert.Equal(t, database.ScheduledChangeStatusApplied, cancelled.Status)
+	assert.False(t, cancelled.RevertAt.Valid)
+	assert.Equal(t, int64(2), cancelled.CancelledById.ValueOrZero())
+
+	if err := service.ProcessScheduledChanges(gormDb, now.Add(11*time.Minute), nil, context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, database.ScheduledChangeStatusApplied, getScheduledChange(t, change.Id).Status)
+	assert.Equal(t, "70", getConfigValue(t, "scheduled key"))
+
+	// nothing is left to cancel
+	assert.NotNil(t, service.AdminCancelScheduledChange(gormDb, CancelScheduledChangeRequest{Id: change.Id}, 2))
+}
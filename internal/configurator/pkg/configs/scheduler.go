@@ -0,0 +1,40 @@
+package configs
+
+import (
+	"context"
+	"time"
+
+	"github.com/digitalmonsters/configurator/pkg/database"
+	"github.com/digitalmonsters/go-common/apm_helper"
+	"github.com/digitalmonsters/go-common/boilerplate"
+	"github.com/digitalmonsters/go-common/eventsourcing"
+	"github.com/rs/zerolog/log"
+)
+
+const ScheduledChangesInterval = 15 * time.Second
+
+// StartScheduledChangesWorker periodically applies and reverts scheduled config changes until ctx is done
+func StartScheduledChangesWorker(service IConfigService, publisher eventsourcing.Publisher[eventsourcing.ConfigEvent],
+	interval time.Duration, ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				apmTx := apm_helper.StartNewApmTransaction("ProcessScheduledChanges", "configurator", nil, nil)
+				txCtx := boilerplate.CreateCustomContext(ctx, apmTx, log.Logger)
+
+				if err := service.ProcessScheduledChanges(database.GetDbWithContext(database.DbTypeMaster, txCtx),
+					time.Now().UTC(), publisher, txCtx); err != nil {
+					apm_helper.LogError(err, txCtx)
+				}
+
+				apmTx.End()
+			}
+		}
+	}()
+}
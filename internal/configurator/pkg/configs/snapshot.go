@@ -0,0 +1,261 @@
+package configs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/digitalmonsters/configurator/pkg/database"
+	"github.com/digitalmonsters/go-common/boilerplate"
+	"github.com/digitalmonsters/go-common/callback"
+	"github.com/digitalmonsters/go-common/eventsourcing"
+	"github.com/pkg/errors"
+	"gopkg.in/guregu/null.v4"
+	"gopkg.in/yaml.v3"
+	"gorm.io/gorm"
+)
+
+func (c *ConfigService) AdminExportConfigs(db *gorm.DB, req ExportConfigsRequest) (*ConfigSnapshot, error) {
+	var items []database.Config
+
+	q := db.Model(database.Config{})
+	if len(req.Categories) > 0 {
+		q = q.Where("category in ?", req.Categories)
+	}
+	if len(req.Keys) > 0 {
+		q = q.Where("key in ?", req.Keys)
+	}
+
+	if err := q.Order("key").Find(&items).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	snapshot := ConfigSnapshot{
+		Environment: boilerplate.GetCurrentEnvironment().ToString(),
+		ExportedAt:  time.Now().UTC(),
+		Items:       make([]ConfigSnapshotItem, 0, len(items)),
+	}
+
+	for _, item := range items {
+		snapshot.Items = append(snapshot.Items, ConfigSnapshotItem{
+			Key:            item.Key,
+			Value:          item.Value,
+			Type:           item.Type,
+			Description:    item.Description,
+			AdminOnly:      item.AdminOnly,
+			Category:       item.Category,
+			ReleaseVersion: item.ReleaseVersion,
+		})
+	}
+
+	return &snapshot, nil
+}
+
+func MarshalSnapshot(snapshot ConfigSnapshot, format SnapshotFormat) ([]byte, error) {
+	switch format {
+	case SnapshotFormatYaml:
+		data, err := yaml.Marshal(snapshot)
+		return data, errors.WithStack(err)
+	case SnapshotFormatJson, "":
+		data, err := json.MarshalIndent(snapshot, "", "  ")
+		return data, errors.WithStack(err)
+	default:
+		return nil, errors.New(fmt.Sprintf("unsupported format %v", format))
+	}
+}
+
+func UnmarshalSnapshot(content []byte, format SnapshotFormat) (*ConfigSnapshot, error) {
+	var snapshot ConfigSnapshot
+
+	switch format {
+	case SnapshotFormatYaml:
+		if err := yaml.Unmarshal(content, &snapshot); err != nil {
+			return nil, errors.Wrap(err, "invalid yaml snapshot")
+		}
+	case SnapshotFormatJson, "":
+		if err := json.Unmarshal(content, &snapshot); err != nil {
+			return nil, errors.Wrap(err, "invalid json snapshot")
+		}
+	default:
+		return nil, errors.New(fmt.Sprintf("unsupported format %v", format))
+	}
+
+	return &snapshot, nil
+}
+
+// AdminImportConfigs compares snapshot with current configs and applies it in one transaction.
+// Snapshot with conflicts is never applied partially, dry run only returns the report
+func (c *ConfigService) AdminImportConfigs(tx *gorm.DB, req ImportConfigsRequest, userId int64,
+	publisher eventsourcing.Publisher[eventsourcing.ConfigEvent]) (*ImportConfigsResponse, []callback.Callback, error) {
+	snapshot := req.Snapshot
+	if snapshot == nil {
+		if len(req.Content) == 0 {
+			return nil, nil, errors.New("snapshot or content is required")
+		}
+
+		parsed, err := UnmarshalSnapshot([]byte(req.Content), req.Format)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		snapshot = parsed
+	}
+
+	var keys []string
+	for _, item := range snapshot.Items {
+		keys = append(keys, item.Key)
+	}
+
+	var current []database.Config
+	if len(keys) > 0 {
+		if err := tx.Where("key in ?", keys).Find(&current).Error; err != nil {
+			return nil, nil, errors.WithStack(err)
+		}
+	}
+
+	currentMap := make(map[string]database.Config, len(current))
+	for _, cfg := range current {
+		currentMap[cfg.Key] = cfg
+	}
+
+	resp := &ImportConfigsResponse{
+		DryRun:    req.DryRun,
+		Changes:   []ConfigImportChange{},
+		Conflicts: []ConfigImportConflict{},
+	}
+
+	seen := map[string]bool{}
+
+	for _, item := range snapshot.Items {
+		if seen[item.Key] {
+			resp.Conflicts = append(resp.Conflicts, ConfigImportConflict{Key: item.Key, Reason: "duplicated key in snapshot"})
+			continue
+		}
+		seen[item.Key] = true
+
+		resp.Changes, resp.Conflicts = compareSnapshotItem(item, currentMap, req.CreateMissing, resp.Changes, resp.Conflicts)
+	}
+
+	if req.DryRun || len(resp.Conflicts) > 0 {
+		return resp, nil, nil
+	}
+
+	items := make(map[string]ConfigSnapshotItem, len(snapshot.Items))
+	for _, item := range snapshot.Items {
+		items[item.Key] = item
+	}
+
+	var callbacks []callback.Callback
+
+	for _, change := range resp.Changes {
+		item := items[change.Key]
+
+		switch change.Action {
+		case ImportActionCreate:
+			if err := tx.Create(&database.Config{
+				Key:             item.Key,
+				Value:           item.Value,
+				Type:            item.Type,
+				Description:     item.Description,
+				AdminOnly:       item.AdminOnly,
+				Category:        item.Category,
+				ReleaseVersion:  item.ReleaseVersion,
+				LastChangedById: null.IntFrom(userId),
+			}).Error; err != nil {
+				return nil, nil, errors.WithStack(err)
+			}
+			if err := tx.Create(&database.ConfigLog{
+				Key:           item.Key,
+				Value:         item.Value,
+				RelatedUserId: null.IntFrom(userId),
+			}).Error; err != nil {
+				return nil, nil, errors.WithStack(err)
+			}
+
+			key, value := item.Key, item.Value
+			callbacks = append(callbacks, func(ctx context.Context) error {
+				if publisher != nil {
+					return <-publisher.PublishImmediate(ctx, eventsourcing.ConfigEvent{Key: key, Value: value})
+				}
+				return nil
+			})
+		case ImportActionUpdate, ImportActionMetadata:
+			currentConfig := currentMap[item.Key]
+			currentConfig.Description = item.Description
+			currentConfig.ReleaseVersion = item.ReleaseVersion
+			currentConfig.Category = item.Category
+
+			if change.Action == ImportActionMetadata {
+				if err := tx.Where("key = ?", currentConfig.Key).Save(&currentConfig).Error; err != nil {
+					return nil, nil, errors.WithStack(err)
+				}
+
+				continue
+			}
+
+			_, applyCallbacks, err := c.applyConfigValue(tx, currentConfig, item.Value, null.IntFrom(userId), publisher)
+			if err != nil {
+				return nil, nil, errors.Wrapf(err, "can not apply config %v", item.Key)
+			}
+
+			callbacks = append(callbacks, applyCallbacks...)
+		}
+	}
+
+	resp.Applied = true
+
+	return resp, callbacks, nil
+}
+
+func compareSnapshotItem(item ConfigSnapshotItem, currentMap map[string]database.Config, createMissing bool,
+	changes []ConfigImportChange, conflicts []ConfigImportConflict) ([]ConfigImportChange, []ConfigImportConflict) {
+	if len(item.Key) == 0 {
+		return changes, append(conflicts, ConfigImportConflict{Reason: "empty key"})
+	}
+
+	if err := validateValueAccordingToType(item.Type, item.Value); err != nil {
+		return changes, append(conflicts, ConfigImportConflict{Key: item.Key,
+			Reason: fmt.Sprintf("invalid value for type %v: %v", item.Type, err.Error())})
+	}
+
+	currentConfig, ok := currentMap[item.Key]
+	if !ok {
+		if !createMissing {
+			return changes, append(conflicts, ConfigImportConflict{Key: item.Key, Reason: "config doesn't exist"})
+		}
+
+		return append(changes, ConfigImportChange{Key: item.Key, Action: ImportActionCreate, NewValue: item.Value}), conflicts
+	}
+
+	if len(currentConfig.Type) > 0 && currentConfig.Type != item.Type {
+		return changes, append(conflicts, ConfigImportConflict{Key: item.Key,
+			Reason: fmt.Sprintf("type mismatch: current %v, imported %v", currentConfig.Type, item.Type)})
+	}
+
+	change := ConfigImportChange{
+		Key:      item.Key,
+		Action:   ImportActionUnchanged,
+		OldValue: currentConfig.Value,
+		NewValue: item.Value,
+	}
+
+	if currentConfig.Value != item.Value {
+		if currentConfig.RequiresApproval {
+			return changes, append(conflicts, ConfigImportConflict{Key: item.Key, Reason: "config requires approval"})
+		}
+
+		if currentConfig.Validation != nil {
+			if err := validateRules(*currentConfig.Validation, currentConfig.Type, item.Value); err != nil {
+				return changes, append(conflicts, ConfigImportConflict{Key: item.Key, Reason: err.Error()})
+			}
+		}
+
+		change.Action = ImportActionUpdate
+	} else if currentConfig.Description != item.Description || currentConfig.ReleaseVersion != item.ReleaseVersion ||
+		currentConfig.Category != item.Category {
+		change.Action = ImportActionMetadata
+	}
+
+	return append(changes, change), conflicts
+}
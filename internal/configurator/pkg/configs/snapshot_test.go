@@ -0,0 +1,65 @@
+package configs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/digitalmonsters/configurator/pkg/database"
+	"github.com/digitalmonsters/go-common/application"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	snapshot := ConfigSnapshot{
+		Environment: "staging",
+		ExportedAt:  time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC),
+		Items: []ConfigSnapshotItem{
+			{Key: "A", Value: `{"a":1}`, Type: application.ConfigTypeObject, Category: application.ConfigCategoryAd},
+		},
+	}
+
+	for _, format := range []SnapshotFormat{SnapshotFormatJson, SnapshotFormatYaml} {
+		data, err := MarshalSnapshot(snapshot, format)
+		assert.Nil(t, err)
+
+		parsed, err := UnmarshalSnapshot(data, format)
+		assert.Nil(t, err)
+		assert.Equal(t, snapshot, *parsed)
+	}
+}
+
+func TestCompareSnapshotItem(t *testing.T) {
+	current := map[string]database.Config{
+		"INT":  {Key: "INT", Value: "1", Type: application.ConfigTypeInteger},
+		"BOOL": {Key: "BOOL", Value: "true", Type: application.ConfigTypeBool, Description: "old"},
+	}
+
+	items := []ConfigSnapshotItem{
+		{Key: "INT", Value: "2", Type: application.ConfigTypeInteger},
+		{Key: "BOOL", Value: "true", Type: application.ConfigTypeBool, Description: "new"},
+		{Key: "MISSING", Value: "x", Type: application.ConfigTypeString},
+		{Key: "INT", Value: "true", Type: application.ConfigTypeBool},
+		{Key: "BOOL", Value: "yes", Type: application.ConfigTypeBool},
+	}
+
+	var changes []ConfigImportChange
+	var conflicts []ConfigImportConflict
+
+	for _, item := range items {
+		changes, conflicts = compareSnapshotItem(item, current, false, changes, conflicts)
+	}
+
+	assert.Equal(t, 2, len(changes))
+	assert.Equal(t, ImportActionUpdate, changes[0].Action)
+	assert.Equal(t, "1", changes[0].OldValue)
+	assert.Equal(t, ImportActionMetadata, changes[1].Action)
+
+	assert.Equal(t, 3, len(conflicts))
+	assert.Equal(t, "MISSING", conflicts[0].Key)
+	assert.Equal(t, "INT", conflicts[1].Key)
+	assert.Equal(t, "BOOL", conflicts[2].Key)
+
+	changes, conflicts = compareSnapshotItem(items[2], current, true, nil, nil)
+	assert.Equal(t, ImportActionCreate, changes[0].Action)
+	assert.Equal(t, 0, len(conflicts))
+}
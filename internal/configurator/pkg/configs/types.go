@@ -1,6 +1,7 @@
 package configs
 
 import (
+	"github.com/digitalmonsters/configurator/pkg/database"
 	"github.com/digitalmonsters/go-common/application"
 	"gopkg.in/guregu/null.v4"
 	"time"
@@ -30,9 +31,11 @@ type GetConfigResponse struct {
 
 type ConfigModelWithRelatedUserInfo struct {
 	application.ConfigModel
-	RelatedUserId null.Int `json:"related_user_id"`
-	Username      string   `json:"username"`
-	Email         string   `json:"email"`
+	RelatedUserId    null.Int                  `json:"related_user_id"`
+	Username         string                    `json:"username"`
+	Email            string                    `json:"email"`
+	Validation       *database.ValidationRules `json:"validation"`
+	RequiresApproval bool                      `json:"requires_approval"`
 }
 
 type UpsertConfigRequest struct {
@@ -43,19 +46,49 @@ type UpsertConfigRequest struct {
 	//AdminOnly      bool                       `json:"admin_only"`
 	Category       application.ConfigCategory `json:"category"`
 	ReleaseVersion string                     `json:"release_version"`
+	// GateFlagByReleaseVersion turns flag off for app versions older than config release version, which should be an
+	// app version like 2.5.0
+	GateFlagByReleaseVersion bool `json:"gate_flag_by_release_version"`
+}
+
+type UpsertConfigResponse struct {
+	application.ConfigModel
+	// Status is pending when config requires approval, then PendingLogId references the change to approve
+	Status       database.ConfigLogStatus `json:"status"`
+	PendingLogId null.Int                 `json:"pending_log_id"`
+}
+
+type SetConfigRulesRequest struct {
+	Key              string                    `json:"key"`
+	Validation       *database.ValidationRules `json:"validation"`
+	RequiresApproval bool                      `json:"requires_approval"`
+}
+
+// SetConfigRulesResponse has pending status when the change loosens rules of config which requires approval,
+// then PendingLogId references the change to approve
+type SetConfigRulesResponse struct {
+	ConfigModelWithRelatedUserInfo
+	Status       database.ConfigLogStatus `json:"status"`
+	PendingLogId null.Int                 `json:"pending_log_id"`
+}
+
+type ReviewConfigChangeRequest struct {
+	LogId   int64  `json:"log_id"`
+	Comment string `json:"comment"`
 }
 
 type GetConfigLogsRequest struct {
-	Keys           []string    `json:"keys"`
-	KeyEquals      null.String `json:"key_equals"`
-	KeyContains    null.String `json:"key_contains"`
-	RelatedUserIds []int64     `json:"related_user_ids"`
-	CreatedFrom    null.Time   `json:"created_from"`
-	CreatedTo      null.Time   `json:"created_to"`
-	UpdatedFrom    null.Time   `json:"updated_from"`
-	UpdatedTo      null.Time   `json:"updated_to"`
-	Limit          int         `json:"limit"`
-	Offset         int         `json:"offset"`
+	Keys           []string                   `json:"keys"`
+	KeyEquals      null.String                `json:"key_equals"`
+	KeyContains    null.String                `json:"key_contains"`
+	RelatedUserIds []int64                    `json:"related_user_ids"`
+	Statuses       []database.ConfigLogStatus `json:"statuses"`
+	CreatedFrom    null.Time                  `json:"created_from"`
+	CreatedTo      null.Time                  `json:"created_to"`
+	UpdatedFrom    null.Time                  `json:"updated_from"`
+	UpdatedTo      null.Time                  `json:"updated_to"`
+	Limit          int                        `json:"limit"`
+	Offset         int                        `json:"offset"`
 }
 
 type ConfigLogModel struct {
@@ -68,9 +101,130 @@ type ConfigLogModel struct {
 	RelatedUserId null.Int
 	Username      string
 	Email         string
+	Status        database.ConfigLogStatus
+	ReviewedById  null.Int
+	ReviewedAt    null.Time
+	Comment       string
+	Rules         *database.ConfigRules
 }
 
 type GetConfigLogsResponse struct {
 	Items      []ConfigLogModel `json:"items"`
 	TotalCount int64            `json:"total_count"`
 }
+
+type RollbackConfigRequest struct {
+	LogId int64 `json:"log_id"`
+	// RestoreOldValue restores value which was set before the logged change, otherwise value set by the change
+	RestoreOldValue bool `json:"restore_old_value"`
+}
+
+type GetConfigDiffRequest struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+	Keys []string  `json:"keys"`
+}
+
+type ConfigDiffItem struct {
+	Key          string    `json:"key"`
+	FromValue    string    `json:"from_value"`
+	ToValue      string    `json:"to_value"`
+	Changed      bool      `json:"changed"`
+	ChangesCount int64     `json:"changes_count"`
+	LastChangeAt time.Time `json:"last_change_at"`
+}
+
+type GetConfigDiffResponse struct {
+	Items []ConfigDiffItem `json:"items"`
+}
+
+type ScheduleConfigChangeRequest struct {
+	Key     string    `json:"key"`
+	Value   string    `json:"value"`
+	ApplyAt time.Time `json:"apply_at"`
+	// RevertAfterMinutes restores previous value after given period, 0 means keep new value
+	RevertAfterMinutes int `json:"revert_after_minutes"`
+}
+
+type CancelScheduledChangeRequest struct {
+	Id int64 `json:"id"`
+}
+
+type GetScheduledChangesRequest struct {
+	Keys     []string                         `json:"keys"`
+	Statuses []database.ScheduledChangeStatus `json:"statuses"`
+	Limit    int                              `json:"limit"`
+	Offset   int                              `json:"offset"`
+}
+
+type GetScheduledChangesResponse struct {
+	Items      []database.ConfigScheduledChange `json:"items"`
+	TotalCount int64                            `json:"total_count"`
+}
+
+type SnapshotFormat string
+
+const (
+	SnapshotFormatJson = SnapshotFormat("json")
+	SnapshotFormatYaml = SnapshotFormat("yaml")
+)
+
+type ExportConfigsRequest struct {
+	Categories []application.ConfigCategory `json:"categories"`
+	Keys       []string                     `json:"keys"`
+	Format     SnapshotFormat               `json:"format"`
+}
+
+type ConfigSnapshot struct {
+	Environment string               `json:"environment" yaml:"environment"`
+	ExportedAt  time.Time            `json:"exported_at" yaml:"exported_at"`
+	Items       []ConfigSnapshotItem `json:"items" yaml:"items"`
+}
+
+type ConfigSnapshotItem struct {
+	Key            string                     `json:"key" yaml:"key"`
+	Value          string                     `json:"value" yaml:"value"`
+	Type           application.ConfigType     `json:"type" yaml:"type"`
+	Description    string                     `json:"description" yaml:"description"`
+	AdminOnly      bool                       `json:"admin_only" yaml:"admin_only"`
+	Category       application.ConfigCategory `json:"category" yaml:"category"`
+	ReleaseVersion string                     `json:"release_version" yaml:"release_version"`
+}
+
+type ImportConfigsRequest struct {
+	// Snapshot or Content with Format should be set
+	Snapshot *ConfigSnapshot `json:"snapshot"`
+	Content  string          `json:"content"`
+	Format   SnapshotFormat  `json:"format"`
+	DryRun   bool            `json:"dry_run"`
+	// CreateMissing creates configs which do not exist in current environment, otherwise they are reported as conflicts
+	CreateMissing bool `json:"create_missing"`
+}
+
+type ImportAction string
+
+const (
+	ImportActionCreate    = ImportAction("create")
+	ImportActionUpdate    = ImportAction("update")
+	ImportActionMetadata  = ImportAction("metadata")
+	ImportActionUnchanged = ImportAction("unchanged")
+)
+
+type ConfigImportChange struct {
+	Key      string       `json:"key"`
+	Action   ImportAction `json:"action"`
+	OldValue string       `json:"old_value"`
+	NewValue string       `json:"new_value"`
+}
+
+type ConfigImportConflict struct {
+	Key    string `json:"key"`
+	Reason string `json:"reason"`
+}
+
+type ImportConfigsResponse struct {
+	DryRun    bool                   `json:"dry_run"`
+	Applied   bool                   `json:"applied"`
+	Changes   []ConfigImportChange   `json:"changes"`
+	Conflicts []ConfigImportConflict `json:"conflicts"`
+}
@@ -0,0 +1,178 @@
+package configs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/digitalmonsters/configurator/pkg/database"
+	"github.com/digitalmonsters/go-common/application"
+	"github.com/pkg/errors"
+	"github.com/santhosh-tekuri/jsonschema"
+	"github.com/shopspring/decimal"
+	"github.com/thoas/go-funk"
+)
+
+const jsonSchemaResource = "config.json"
+
+// validateConfigValue checks value against config type and its validation rules
+func validateConfigValue(cfg database.Config, value string) error {
+	if err := validateValueAccordingToType(cfg.Type, value); err != nil {
+		return err
+	}
+
+	if cfg.Validation == nil {
+		return nil
+	}
+
+	return validateRules(*cfg.Validation, cfg.Type, value)
+}
+
+func validateRules(rules database.ValidationRules, configType application.ConfigType, value string) error {
+	if len(rules.Enum) > 0 && !funk.ContainsString(rules.Enum, value) {
+		return errors.New(fmt.Sprintf("value should be one of [%v]", strings.Join(rules.Enum, ", ")))
+	}
+
+	if len(rules.Regex) > 0 {
+		re, err := regexp.Compile(rules.Regex)
+		if err != nil {
+			return errors.Wrap(err, "invalid regex rule")
+		}
+
+		if !re.MatchString(value) {
+			return errors.New(fmt.Sprintf("value should match %v", rules.Regex))
+		}
+	}
+
+	if rules.Min != nil || rules.Max != nil {
+		if configType != application.ConfigTypeInteger && configType != application.ConfigTypeDecimal {
+			return errors.New("min and max rules are supported only for integer and decimal configs")
+		}
+
+		number, err := decimal.NewFromString(value)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		if rules.Min != nil && number.LessThan(*rules.Min) {
+			return errors.New(fmt.Sprintf("value should be greater or equal to %v", rules.Min.String()))
+		}
+
+		if rules.Max != nil && number.GreaterThan(*rules.Max) {
+			return errors.New(fmt.Sprintf("value should be less or equal to %v", rules.Max.String()))
+		}
+	}
+
+	if len(rules.JsonSchema) > 0 {
+		schema, err := compileJsonSchema(rules.JsonSchema)
+		if err != nil {
+			return err
+		}
+
+		if err := schema.Validate(strings.NewReader(value)); err != nil {
+			return errors.Wrap(err, "value does not match json schema")
+		}
+	}
+
+	return nil
+}
+
+func compileJsonSchema(data []byte) (*jsonschema.Schema, error) {
+	compiler := jsonschema.NewCompiler()
+
+	if err := compiler.AddResource(jsonSchemaResource, bytes.NewReader(data)); err != nil {
+		return nil, errors.Wrap(err, "invalid json schema rule")
+	}
+
+	schema, err := compiler.Compile(jsonSchemaResource)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid json schema rule")
+	}
+
+	return schema, nil
+}
+
+// validateRulesDefinition checks that rules can be used for the config type
+func validateRulesDefinition(rules database.ValidationRules, configType application.ConfigType) error {
+	if len(rules.Regex) > 0 {
+		if _, err := regexp.Compile(rules.Regex); err != nil {
+			return errors.Wrap(err, "invalid regex rule")
+		}
+	}
+
+	if rules.Min != nil || rules.Max != nil {
+		if configType != application.ConfigTypeInteger && configType != application.ConfigTypeDecimal {
+			return errors.New("min and max rules are supported only for integer and decimal configs")
+		}
+
+		if rules.Min != nil && rules.Max != nil && rules.Min.GreaterThan(*rules.Max) {
+			return errors.New("min should be less or equal to max")
+		}
+	}
+
+	if len(rules.JsonSchema) > 0 {
+		if configType != application.ConfigTypeObject {
+			return errors.New("json schema rule is supported only for object configs")
+		}
+
+		if _, err := compileJsonSchema(rules.JsonSchema); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// rulesLoosened reports whether next rules may accept a value which current rules reject. Regex and json schema can't be
+// compared, so any change of them counts as loosening
+func rulesLoosened(current *database.ValidationRules, next *database.ValidationRules) bool {
+	if current == nil {
+		return false
+	}
+
+	if next == nil {
+		next = &database.ValidationRules{}
+	}
+
+	if current.Min != nil && (next.Min == nil || next.Min.LessThan(*current.Min)) {
+		return true
+	}
+
+	if current.Max != nil && (next.Max == nil || next.Max.GreaterThan(*current.Max)) {
+		return true
+	}
+
+	if len(current.Enum) > 0 {
+		if len(next.Enum) == 0 {
+			return true
+		}
+
+		for _, value := range next.Enum {
+			if !funk.ContainsString(current.Enum, value) {
+				return true
+			}
+		}
+	}
+
+	if len(current.Regex) > 0 && next.Regex != current.Regex {
+		return true
+	}
+
+	if len(current.JsonSchema) > 0 {
+		var currentSchema, nextSchema bytes.Buffer
+
+		if err := json.Compact(&currentSchema, current.JsonSchema); err != nil {
+			return true
+		}
+
+		if err := json.Compact(&nextSchema, next.JsonSchema); err != nil {
+			return true
+		}
+
+		return !bytes.Equal(currentSchema.Bytes(), nextSchema.Bytes())
+	}
+
+	return false
+}
@@ -0,0 +1,83 @@
+package configs
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/digitalmonsters/configurator/pkg/database"
+	"github.com/digitalmonsters/go-common/application"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateRules(t *testing.T) {
+	min := decimal.NewFromInt(1)
+	max := decimal.NewFromInt(10)
+
+	numberRules := database.ValidationRules{Min: &min, Max: &max}
+
+	assert.Nil(t, validateRules(numberRules, application.ConfigTypeInteger, "5"))
+	assert.NotNil(t, validateRules(numberRules, application.ConfigTypeInteger, "0"))
+	assert.NotNil(t, validateRules(numberRules, application.ConfigTypeInteger, "11"))
+	assert.NotNil(t, validateRulesDefinition(database.ValidationRules{Min: &max, Max: &min}, application.ConfigTypeInteger))
+	assert.NotNil(t, validateRulesDefinition(numberRules, application.ConfigTypeString))
+
+	enumRules := database.ValidationRules{Enum: []string{"a", "b"}, Regex: "^[a-z]$"}
+
+	assert.Nil(t, validateRules(enumRules, application.ConfigTypeString, "a"))
+	assert.NotNil(t, validateRules(enumRules, application.ConfigTypeString, "c"))
+	assert.NotNil(t, validateRulesDefinition(database.ValidationRules{Regex: "("}, application.ConfigTypeString))
+
+	schemaRules := database.ValidationRules{JsonSchema: json.RawMessage(`{
+		"type": "object",
+		"required": ["price"],
+		"properties": {"price": {"type": "number", "minimum": 0}}
+	}`)}
+
+	assert.Nil(t, validateRulesDefinition(schemaRules, application.ConfigTypeObject))
+	assert.Nil(t, validateRules(schemaRules, application.ConfigTypeObject, `{"price": 1.5}`))
+	assert.NotNil(t, validateRules(schemaRules, application.ConfigTypeObject, `{"price": -1}`))
+	assert.NotNil(t, validateRules(schemaRules, application.ConfigTypeObject, `{}`))
+
+	cfg := database.Config{Type: application.ConfigTypeInteger, Validation: &numberRules}
+
+	assert.NotNil(t, validateConfigValue(cfg, "abc"))
+	assert.NotNil(t, validateConfigValue(cfg, "20"))
+	assert.Nil(t, validateConfigValue(cfg, "2"))
+}
+
+func TestRulesLoosened(t *testing.T) {
+	one := decimal.NewFromInt(1)
+	two := decimal.NewFromInt(2)
+	ten := decimal.NewFromInt(10)
+
+	current := &database.ValidationRules{Min: &two, Max: &ten, Enum: []string{"2", "5", "10"}}
+
+	assert.False(t, rulesLoosened(nil, current))
+	assert.False(t, rulesLoosened(current, current))
+	assert.False(t, rulesLoosened(current, &database.ValidationRules{Min: &two, Max: &two, Enum: []string{"2"}}))
+	assert.True(t, rulesLoosened(current, nil))
+	assert.True(t, rulesLoosened(current, &database.ValidationRules{Min: &one, Max: &ten, Enum: []string{"2"}}))
+	assert.True(t, rulesLoosened(current, &database.ValidationRules{Min: &two, Enum: []string{"2"}}))
+	assert.True(t, rulesLoosened(current, &database.ValidationRules{Min: &two, Max: &ten, Enum: []string{"2", "3"}}))
+
+	schema := &database.ValidationRules{JsonSchema: json.RawMessage(`{"type": "object"}`)}
+
+	assert.False(t, rulesLoosened(schema, &database.ValidationRules{JsonSchema: json.RawMessage(`{"type":"object"}`)}))
+	assert.True(t, rulesLoosened(schema, &database.ValidationRules{JsonSchema: json.RawMessage(`{"type":"array"}`)}))
+	assert.True(t, rulesLoosened(&database.ValidationRules{Regex: "^[a-z]$"}, &database.ValidationRules{Regex: "^[a-z]+$"}))
+}
+
+func TestValidateObjectValue(t *testing.T) {
+	assert.Nil(t, validateObjectValue(`{"a": 1}`))
+	assert.Nil(t, validateObjectValue(`[1, 2]`))
+
+	// services decode blank and null object values into zero value, so they are valid
+	assert.Nil(t, validateObjectValue(""))
+	assert.Nil(t, validateObjectValue("  "))
+	assert.Nil(t, validateObjectValue("null"))
+
+	assert.NotNil(t, validateObjectValue(`"text"`))
+	assert.NotNil(t, validateObjectValue(`12`))
+	assert.NotNil(t, validateObjectValue(`{"a":`))
+}
@@ -1,28 +1,95 @@
 package database
 
 import (
+	"database/sql/driver"
+	"encoding/json"
 	"github.com/digitalmonsters/go-common/application"
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
 	"gopkg.in/guregu/null.v4"
 	"time"
 )
 
 type Config struct {
-	Key             string
-	Value           string
-	Type            application.ConfigType
-	Description     string
-	AdminOnly       bool
-	CreatedAt       time.Time
-	UpdatedAt       time.Time
-	Category        application.ConfigCategory
-	ReleaseVersion  string
-	LastChangedById null.Int
+	Key              string
+	Value            string
+	Type             application.ConfigType
+	Description      string
+	AdminOnly        bool
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+	Category         application.ConfigCategory
+	ReleaseVersion   string
+	LastChangedById  null.Int
+	Validation       *ValidationRules
+	RequiresApproval bool
+}
+
+// ValidationRules are checked for every new value of config in addition to the type check
+type ValidationRules struct {
+	Min        *decimal.Decimal `json:"min,omitempty"`
+	Max        *decimal.Decimal `json:"max,omitempty"`
+	Enum       []string         `json:"enum,omitempty"`
+	Regex      string           `json:"regex,omitempty"`
+	JsonSchema json.RawMessage  `json:"json_schema,omitempty"`
+}
+
+func (v ValidationRules) Value() (driver.Value, error) {
+	return json.Marshal(v)
+}
+
+func (v *ValidationRules) Scan(src interface{}) error {
+	switch data := src.(type) {
+	case []byte:
+		return json.Unmarshal(data, v)
+	case string:
+		return json.Unmarshal([]byte(data), v)
+	case nil:
+		return nil
+	default:
+		return errors.New("unsupported type for validation rules")
+	}
+}
+
+// ConfigRules are validation and approval settings of config. Config logs keep them for rules changes waiting for approval
+type ConfigRules struct {
+	Validation       *ValidationRules `json:"validation,omitempty"`
+	RequiresApproval bool             `json:"requires_approval"`
+}
+
+func (r ConfigRules) Value() (driver.Value, error) {
+	return json.Marshal(r)
+}
+
+func (r *ConfigRules) Scan(src interface{}) error {
+	switch data := src.(type) {
+	case []byte:
+		return json.Unmarshal(data, r)
+	case string:
+		return json.Unmarshal([]byte(data), r)
+	case nil:
+		return nil
+	default:
+		return errors.New("unsupported type for config rules")
+	}
 }
 
 func (Config) TableName() string {
 	return "configs"
 }
 
+type ConfigLogStatus string
+
+const (
+	ConfigLogStatusApplied  = ConfigLogStatus("applied")
+	ConfigLogStatusPending  = ConfigLogStatus("pending")
+	ConfigLogStatusApproved = ConfigLogStatus("approved")
+	ConfigLogStatusRejected = ConfigLogStatus("rejected")
+)
+
+// AppliedConfigLogStatuses are statuses of logs which changed config value
+var AppliedConfigLogStatuses = []ConfigLogStatus{ConfigLogStatusApplied, ConfigLogStatusApproved}
+
 type ConfigLog struct {
 	Id            int64
 	Key           string
@@ -31,8 +98,46 @@ type ConfigLog struct {
 	CreatedAt     time.Time
 	UpdatedAt     time.Time
 	RelatedUserId null.Int
+	Status        ConfigLogStatus `gorm:"default:applied"`
+	ReviewedById  null.Int
+	ReviewedAt    null.Time
+	Comment       string
+	// Rules are set for rules changes, Value and OldValue of such logs are the value at the time of the request
+	Rules *ConfigRules
 }
 
 func (ConfigLog) TableName() string {
 	return "config_logs"
 }
+
+type ScheduledChangeStatus string
+
+const (
+	ScheduledChangeStatusPending       = ScheduledChangeStatus("pending")
+	ScheduledChangeStatusApplied       = ScheduledChangeStatus("applied")
+	ScheduledChangeStatusReverted      = ScheduledChangeStatus("reverted")
+	ScheduledChangeStatusRevertSkipped = ScheduledChangeStatus("revert_skipped")
+	ScheduledChangeStatusCancelled     = ScheduledChangeStatus("cancelled")
+	ScheduledChangeStatusFailed        = ScheduledChangeStatus("failed")
+)
+
+type ConfigScheduledChange struct {
+	Id            int64                 `json:"id"`
+	Key           string                `json:"key"`
+	Value         string                `json:"value"`
+	PreviousValue null.String           `json:"previous_value"`
+	ApplyAt       time.Time             `json:"apply_at"`
+	RevertAt      null.Time             `json:"revert_at"`
+	Status        ScheduledChangeStatus `json:"status"`
+	Error         null.String           `json:"error"`
+	CreatedById   int64                 `json:"created_by_id"`
+	CancelledById null.Int              `json:"cancelled_by_id"`
+	AppliedAt     null.Time             `json:"applied_at"`
+	RevertedAt    null.Time             `json:"reverted_at"`
+	CreatedAt     time.Time             `json:"created_at"`
+	UpdatedAt     time.Time             `json:"updated_at"`
+}
+
+func (ConfigScheduledChange) TableName() string {
+	return "config_scheduled_changes"
+}
@@ -404,5 +404,55 @@ INSERT INTO configs (key, value, type, description, admin_only, created_at, upda
 				INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('COUNTRY_RATE_CONVERSION_ENABLED', 'false', 'bool', 'country rate conversion', false, '2024-04-08 07:31:16.696989 +00:00', '2024-04-08 07:31:16.696989 +00:00', 'tokenomics', '04.11.24', null) ON CONFLICT (ke\y) DO NOTHING;`)
 			},
 		},
+		{
+			ID: "config_scheduled_changes_20261019",
+			Migrate: func(db *gorm.DB) error {
+				return boilerplate_testing.ExecutePostgresSql(db, `
+alter table configs alter column value type text;
+alter table config_logs alter column value type text;
+alter table config_logs alter column old_value type text;
+create index if not exists config_logs_key_created_at_idx on config_logs (key, created_at);
+
+create table if not exists config_scheduled_changes
+(
+    id              bigserial primary key,
+    key             varchar(255) not null,
+    value           text         not null,
+    previous_value  text,
+    apply_at        timestamp with time zone not null,
+    revert_at       timestamp with time zone,
+    status          varchar(32)  not null default 'pending',
+    error           text,
+    created_by_id   bigint       not null,
+    cancelled_by_id bigint,
+    applied_at      timestamp with time zone,
+    reverted_at     timestamp with time zone,
+    created_at      timestamp with time zone default CURRENT_TIMESTAMP not null,
+    updated_at      timestamp with time zone default CURRENT_TIMESTAMP not null
+);
+create index if not exists config_scheduled_changes_status_apply_at_idx on config_scheduled_changes (status, apply_at);
+create index if not exists config_scheduled_changes_status_revert_at_idx on config_scheduled_changes (status, revert_at);`)
+			},
+		},
+		{
+			ID: "config_validation_and_approval_20261019",
+			Migrate: func(db *gorm.DB) error {
+				return boilerplate_testing.ExecutePostgresSql(db, `
+alter table configs add column if not exists validation jsonb;
+alter table configs add column if not exists requires_approval boolean not null default false;
+alter table config_logs add column if not exists status varchar(32) not null default 'applied';
+alter table config_logs add column if not exists reviewed_by_id bigint;
+alter table config_logs add column if not exists reviewed_at timestamp with time zone;
+alter table config_logs add column if not exists comment text not null default '';
+create unique index if not exists config_logs_pending_key_uindex on config_logs (key) where status = 'pending';`)
+			},
+		},
+		{
+			ID: "config_logs_rules_20261019",
+			Migrate: func(db *gorm.DB) error {
+				return boilerplate_testing.ExecutePostgresSql(db, `
+alter table config_logs add column if not exists rules jsonb;`)
+			},
+		},
 	}
 }
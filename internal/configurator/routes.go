@@ -1,14 +1,71 @@
 package configurator
 
 import (
+	"context"
 	"net/http"
+
+	"github.com/digitalmonsters/configurator/configs"
+	configsPkg "github.com/digitalmonsters/configurator/pkg/configs"
+	"github.com/digitalmonsters/configurator/pkg/database"
+	"github.com/digitalmonsters/go-common/eventsourcing"
+	"github.com/digitalmonsters/go-common/router"
+	"github.com/digitalmonsters/go-common/wrappers/auth_go"
 	"github.com/go-chi/chi/v5"
 )
 
+// Migrate applies configurator migrations, it is called once on startup before RegisterRoutes
+func Migrate() {
+	database.Migrate()
+}
+
 func RegisterRoutes(r chi.Router) {
+	config := configs.GetConfig()
+
+	authGoWrapper := auth_go.NewAuthGoWrapper(config.Wrappers.AuthGo)
+	configService := configsPkg.NewConfigService(authGoWrapper)
+	auth := router.NewHttpAuth(authGoWrapper, writeError)
+	configPublisher := eventsourcing.NewKafkaBatchPublisher[eventsourcing.ConfigEvent]("config_upsert", config.ConfigNotifier, context.Background())
+
+	configsPkg.StartScheduledChangesWorker(configService, configPublisher, configsPkg.ScheduledChangesInterval, context.Background())
+
+	registerRoutes(r, configService, configPublisher, auth)
+}
+
+func registerRoutes(r chi.Router, configService configsPkg.IConfigService,
+	configPublisher eventsourcing.Publisher[eventsourcing.ConfigEvent], auth *router.HttpAuth) {
 	r.Route("/config", func(rr chi.Router) {
 		rr.Get("/ping", func(w http.ResponseWriter, r *http.Request) {
 			w.Write([]byte("config ok"))
 		})
+
+		rr.Post("/internal/json", internalJson(configService))
+		rr.Post("/internal/json/migrator", internalJsonMigrator(configService, configPublisher))
+
+		rr.Route("/admin", func(ar chi.Router) {
+			ar.Group(func(vr chi.Router) {
+				vr.Use(auth.RequireAdmin("configs:view"))
+
+				vr.Post("/configs", getConfigs(configService))
+				vr.Post("/configurations", getConfigs(configService))
+				vr.Post("/diff", getConfigDiff(configService))
+				vr.Post("/scheduled/list", listScheduledChanges(configService))
+				vr.Post("/export", exportConfigs(configService))
+			})
+
+			ar.With(auth.RequireAdmin("config:logs:view")).Post("/logs", getConfigLogs(configService))
+
+			ar.Group(func(ur chi.Router) {
+				ur.Use(auth.RequireAdmin("configs:upsert"))
+
+				ur.Post("/upsert", upsertConfig(configService, configPublisher))
+				ur.Post("/rules", setConfigRules(configService))
+				ur.Post("/changes/approve", approveConfigChange(configService, configPublisher))
+				ur.Post("/changes/reject", rejectConfigChange(configService))
+				ur.Post("/rollback", rollbackConfig(configService, configPublisher))
+				ur.Post("/scheduled/create", scheduleConfigChange(configService))
+				ur.Post("/scheduled/cancel", cancelScheduledChange(configService))
+				ur.Post("/import", importConfigs(configService, configPublisher))
+			})
+		})
 	})
 }
@@ -0,0 +1,89 @@
+package configurator
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/digitalmonsters/configurator/pkg/configs"
+	"github.com/digitalmonsters/configurator/pkg/database"
+	"github.com/digitalmonsters/go-common/eventsourcing"
+	"github.com/digitalmonsters/go-common/router"
+	"github.com/rs/zerolog/log"
+)
+
+// exportConfigs returns snapshot in api response, yaml snapshot is returned as a file
+func exportConfigs(service configs.IConfigService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req configs.ExportConfigsRequest
+
+		if err := decodeRequest(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		snapshot, err := service.AdminExportConfigs(database.GetDbWithContext(database.DbTypeReadonly, r.Context()), req)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		if req.Format != configs.SnapshotFormatYaml {
+			writeResponse(w, snapshot)
+			return
+		}
+
+		data, err := configs.MarshalSnapshot(*snapshot, req.Format)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/x-yaml")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=configs-%v-%v.yaml",
+			snapshot.Environment, snapshot.ExportedAt.Format("20060102150405")))
+		w.WriteHeader(http.StatusOK)
+
+		if _, err := w.Write(data); err != nil {
+			log.Err(err).Send()
+		}
+	}
+}
+
+func importConfigs(service configs.IConfigService, publisher eventsourcing.Publisher[eventsourcing.ConfigEvent]) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req configs.ImportConfigsRequest
+
+		if err := decodeRequest(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		tx := database.GetDbWithContext(database.DbTypeMaster, r.Context()).Begin()
+		defer tx.Rollback()
+
+		resp, callbacks, err := service.AdminImportConfigs(tx, req, router.AdminIdFromHttpRequest(r), publisher)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		if !resp.Applied {
+			writeResponse(w, resp)
+			return
+		}
+
+		if err := tx.Commit().Error; err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		for _, callbackFn := range callbacks {
+			if err := callbackFn(r.Context()); err != nil {
+				writeError(w, http.StatusInternalServerError, err)
+				return
+			}
+		}
+
+		writeResponse(w, resp)
+	}
+}
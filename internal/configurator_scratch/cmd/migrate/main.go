@@ -1,7 +0,0 @@
-package main
-
-import "github.com/digitalmonsters/configurator/pkg/database"
-
-func main() {
-	database.Migrate()
-}
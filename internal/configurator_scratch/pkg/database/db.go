@@ -1,84 +0,0 @@
-package database
-
-import (
-	"context"
-	"github.com/digitalmonsters/configurator/configs"
-	"github.com/digitalmonsters/go-common/boilerplate"
-	"github.com/digitalmonsters/go-common/boilerplate_testing"
-	"github.com/go-gormigrate/gormigrate/v2"
-	"github.com/rs/zerolog/log"
-	"gorm.io/gorm"
-)
-
-type DbType int
-
-const (
-	DbTypeMaster   = DbType(1)
-	DbTypeReadonly = DbType(2)
-)
-
-var masterGormDb *gorm.DB
-var readonlyGormDb *gorm.DB
-
-// TODO: remove the init function and use the db connection as a dependency
-func init() {
-	config := configs.GetConfig()
-
-	if boilerplate.GetCurrentEnvironment() == boilerplate.Ci {
-		if err := boilerplate_testing.EnsurePostgresDbExists(config.MasterDb); err != nil {
-			panic(err)
-		}
-	}
-
-	log.Info().Msg("setup postgres database")
-
-	mainDb, err := boilerplate.GetGormConnection(config.MasterDb)
-
-	if err != nil {
-		panic(err)
-	}
-
-	masterGormDb = mainDb
-
-	readOnlyConfig := config.ReadonlyDb
-
-	if len(readOnlyConfig.Host) == 0 || len(readOnlyConfig.Db) == 0 {
-		readOnlyConfig = config.MasterDb
-		log.Warn().Msgf("[DB Connection] no configuration for read-only db. Will use Master DB")
-	}
-
-	readDb, err := boilerplate.GetGormConnection(readOnlyConfig)
-
-	if err != nil {
-		log.Err(err).Msg("[Db Connection] can not setup connection to read-only db, will use master")
-
-		readonlyGormDb = masterGormDb
-	} else {
-		readonlyGormDb = readDb
-	}
-}
-
-func Migrate() {
-	m := gormigrate.New(masterGormDb, gormigrate.DefaultOptions, getMigrations())
-
-	log.Info().Msg("[Db] start migrations")
-
-	if err := m.Migrate(); err != nil {
-		panic(err)
-	}
-}
-
-func GetDb(t DbType) *gorm.DB {
-	switch t {
-	case DbTypeMaster:
-		return masterGormDb
-	case DbTypeReadonly:
-		return readonlyGormDb
-	default:
-		return masterGormDb
-	}
-}
-
-func GetDbWithContext(t DbType, ctx context.Context) *gorm.DB {
-	return GetDb(t).WithContext(ctx)
-}
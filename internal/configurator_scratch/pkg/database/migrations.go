@@ -1,408 +0,0 @@
-package database
-
-import (
-	"github.com/digitalmonsters/go-common/boilerplate"
-	"github.com/digitalmonsters/go-common/boilerplate_testing"
-	"github.com/go-gormigrate/gormigrate/v2"
-	"github.com/rs/zerolog/log"
-	"gorm.io/gorm"
-)
-
-func getMigrations() []*gormigrate.Migration {
-	return []*gormigrate.Migration{
-		{
-			ID: "init_20220425",
-			Migrate: func(db *gorm.DB) error {
-				var sqls = []string{
-					`create table if not exists feature_toggles (id bigserial primary key  not null, key text, value jsonb,
-created_at timestamp with time zone default current_timestamp, updated_at timestamp with time zone default current_timestamp,
- deleted_at timestamp with time zone default null);`,
-
-					`create unique index feature_toggles_uq on feature_toggles(key) where deleted_at is null;`,
-				}
-				for _, s := range sqls {
-					if err := db.Exec(s).Error; err != nil {
-						return err
-					}
-				}
-				return nil
-			},
-		},
-		{
-			ID: "feature_events_table_20220428",
-			Migrate: func(db *gorm.DB) error {
-				var sqls = []string{
-					`create table if not exists feature_toggle_events (id bigserial primary key  not null, feature_toggle_event jsonb,
-created_at timestamp with time zone default current_timestamp);`,
-				}
-				for _, s := range sqls {
-					if err := db.Exec(s).Error; err != nil {
-						return err
-					}
-				}
-				return nil
-			},
-		},
-		{
-			ID: "create_configs_table_20220503",
-			Migrate: func(db *gorm.DB) error {
-				return boilerplate_testing.ExecutePostgresSql(db,
-					"create table if not exists configs (     key         varchar(255)  not null         primary key,     value       varchar(255),     type  varchar(255),     description varchar(255),     admin_only  boolean,     created_at  timestamp with time zone default CURRENT_TIMESTAMP not null,     updated_at  timestamp with time zone default CURRENT_TIMESTAMP not null,     category    varchar(255) );")
-			},
-		},
-		{
-			ID: "create_config_logs_table_20220504",
-			Migrate: func(db *gorm.DB) error {
-				return boilerplate_testing.ExecutePostgresSql(db, `create table if not exists config_logs
-(
-    id         bigserial
-        primary key,
-    key        varchar(255),
-    value      varchar(255),
-    related_user_id      bigint,
-    created_at timestamp with time zone default CURRENT_TIMESTAMP not null,
-    updated_at timestamp with time zone default CURRENT_TIMESTAMP not null
-);`)
-			},
-		},
-		{
-			ID: "cleanup_20220504",
-			Migrate: func(db *gorm.DB) error {
-				return boilerplate_testing.ExecutePostgresSql(db, `
-drop table if exists feature_toggle_events;
-drop table if exists  feature_toggles;`)
-			},
-		},
-		{
-			ID: "add_realease_version_on_configs_20220504",
-			Migrate: func(db *gorm.DB) error {
-				return boilerplate_testing.ExecutePostgresSql(db, `
-alter table configs add column if not exists release_version varchar(255);
-`)
-			},
-		},
-		{
-			ID: "alter_config_logs_20220504",
-			Migrate: func(db *gorm.DB) error {
-				return boilerplate_testing.ExecutePostgresSql(db, `
-alter table config_logs add column if not exists old_value varchar(255);
-`)
-			},
-		},
-		{
-			ID: "add_last_changed_by_id_on_configs_20220725",
-			Migrate: func(db *gorm.DB) error {
-				return boilerplate_testing.ExecutePostgresSql(db, `
-alter table configs add column if not exists last_changed_by_id integer;`)
-			},
-		},
-		{
-			ID: "migrate_last_changed_by_id_values_20220725",
-			Migrate: func(db *gorm.DB) error {
-				return boilerplate_testing.ExecutePostgresSql(db, `
-update configs set last_changed_by_id = (select related_user_id from config_logs where key = configs.key order by created_at desc limit 1);`)
-			},
-		},
-		{
-			ID: "seed_the_config_20231222",
-			Migrate: func(db *gorm.DB) error {
-				if !boilerplate.InLocal() {
-					log.Warn().Msg("skipping seeding the config table in non-local environment")
-					return nil
-				}
-
-				return boilerplate_testing.ExecutePostgresSql(db, `
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('MUSIC_CALCULATION_LOVE_COUNT_WEIGHT', '10', 'integer', 'Music feed. c.loves * [X]', false, '2022-07-29 13:25:06.334724 +00:00', '2022-07-29 13:25:06.334724 +00:00', 'music', '29.07.2022', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('MUSIC_CALCULATION_SHORT_LISTEN_COUNT_WEIGHT', '1', 'integer', 'Music feed. c.short_listens * [X]', false, '2022-07-29 13:25:06.336488 +00:00', '2022-07-29 13:25:06.336488 +00:00', 'music', '29.07.2022', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('MUSIC_CALCULATION_TIMING_START_CONF', '5000', 'integer', 'Music calculation timing start value', false, '2022-07-29 13:25:06.338073 +00:00', '2022-07-29 13:25:06.338073 +00:00', 'music', '29.07.2022', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('MUSIC_FEED_LIMIT', '10000', 'integer', 'Max music feed limit', false, '2022-07-29 13:25:06.339823 +00:00', '2022-07-29 13:25:06.339823 +00:00', 'applications', '22.07.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('ADS_CAMPAIGN_VIDEOS_PER_CONTENT_VIDEOS', '9', 'integer', 'Ads campaign videos per content videos frequency, possible values are: 2, 4, 5, 10, 20', false, '2022-08-05 17:00:55.595789 +00:00', '2022-08-05 17:00:55.595789 +00:00', 'ad', '05.08.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('MEGA_BONUS_REFERRALS_POINTS_AMOUNT', '10000', 'decimal', 'mega bonus referrals points amount', false, '2022-05-20 14:04:16.703273 +00:00', '2022-08-08 09:56:12.327319 +00:00', 'tokenomics', '20.05.22', 1) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('ANDROID_APP_BUILD', '51', 'integer', 'android app build', false, '2022-06-18 10:17:36.578626 +00:00', '2022-08-08 16:46:15.944934 +00:00', 'applications', '17.06.22', 1) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('IOS_APP_NEW_VERSION', '1.21', 'string', 'ios app new version', false, '2022-06-18 10:17:36.580452 +00:00', '2022-08-08 18:19:14.366933 +00:00', 'applications', '17.06.22', 1) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('ADS_MODERATION_SLA', '48', 'integer', 'Ads moderation SLA', false, '2022-07-29 13:16:55.362581 +00:00', '2022-07-29 13:16:55.362581 +00:00', 'ad', '29.07.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('SURVEY_POINTS', '200', 'decimal', 'survey points', false, '2023-05-29 08:28:37.767756 +00:00', '2023-05-29 08:28:37.767756 +00:00', 'tokenomics', '29.05.23', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('REGISTRATIONS_WITH_SINGLE_IP_RESTRICTION_MINUTES', '5', 'integer', 'registrations with single ip time period (minutes) to cause block', false, '2022-08-12 13:03:27.900834 +00:00', '2022-08-12 13:03:27.900834 +00:00', 'auth', '11.08.2022', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('REGISTRATIONS_WITH_SINGLE_IP_RESTRICTION_COUNT', '10', 'integer', 'registrations with single ip count within configurable time period to cause block', false, '2022-08-12 13:03:27.921849 +00:00', '2022-08-12 13:03:27.921849 +00:00', 'auth', '11.08.2022', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('REGISTRATIONS_WITH_SINGLE_IP_BLOCK_DAYS', '3', 'integer', 'registrations with single ip block days', false, '2022-08-12 13:03:27.923429 +00:00', '2022-08-12 13:03:27.923429 +00:00', 'auth', '11.08.2022', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('GREETING_SPOT_FEED_AFTER_X_WATCHED_VIDEOS', '8', 'integer', 'Greeting for new users. after x videos show notify about spots feed', false, '2022-08-22 09:19:52.240603 +00:00', '2022-08-22 09:19:52.240603 +00:00', 'content', '22.08.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('ADS_AVAILABLE_FOR_USER_IDS', '0,1,1078,703113', 'string', 'List of user ids for which ads is active', false, '2022-08-09 17:24:16.277588 +00:00', '2023-02-07 00:05:50.418884 +00:00', 'ad', '08.08.22', 1) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('SURVEY_SECRET_HASH', 'AcaUHpZuZUag0b4axKoaZUqh6c1HC916', 'string', 'survey secret hash', false, '2023-05-29 11:56:44.085299 +00:00', '2023-05-29 11:56:44.085299 +00:00', 'tokenomics', '29.05.23', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('LOGIN_MSG_POPUP', '200', 'integer', 'show login msg popup for unauthenticated user every x videos', false, '2023-04-20 14:34:44.780546 +00:00', '2023-05-09 15:36:33.515789 +00:00', 'content', '20.04.23', 1) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('SOCIAL_MEDIA_POPUP_EVERY_X_VIDEOS', '200', 'integer', 'show social media popup every x videos', false, '2023-04-17 15:04:38.599975 +00:00', '2023-05-09 15:36:39.182790 +00:00', 'content', '14.04.23', 1) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('DOGS_CALCULATION_WATCH_COUNT_WEIGHT', '1', 'integer', 'Dogs feed. c.watch_count * [X]', false, '2023-05-23 07:31:04.458575 +00:00', '2023-05-23 07:31:04.458575 +00:00', 'content', '02.05.2023', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('ADS_PROVIDER', 'google_ads', 'string', 'ads provider: google_ads or internal', false, '2022-08-22 08:45:13.726935 +00:00', '2023-02-21 13:56:04.485085 +00:00', 'ad', '16.08.22', 1) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('CAPTCHA_EVERY_X_SECONDS', '10', 'integer', 'Captcha frequency per amount of videos', false, '2022-03-23 13:16:55.362581 +00:00', '2022-03-23 13:16:55.362581 +00:00', 'content', '24.03.23', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('CAPTCHA_EVERY_X_VIDEOS', '10', 'integer', 'Show captcha for every X videos', false, '2022-03-23 13:16:55.362581 +00:00', '2022-03-23 13:16:55.362581 +00:00', 'content', '24.03.23', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('DOGS_FEED_LIMIT', '10000', 'integer', 'Dogs feed limit', false, '2023-05-23 07:31:04.470611 +00:00', '2023-05-23 07:31:04.470611 +00:00', 'content', '02.05.2023', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('DOGS_CALCULATION_LIKE_COUNT_WEIGHT', '6', 'integer', 'Dogs feed. c.likes_count * [X]', false, '2023-05-23 07:31:04.471711 +00:00', '2023-05-23 07:31:04.471711 +00:00', 'content', '02.05.2023', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('DOGS_CALCULATION_TIMING_DELIMITER', '60', 'integer', 'Dogs calculation delimiter', false, '2023-05-23 07:31:04.472735 +00:00', '2023-05-23 07:31:04.472735 +00:00', 'content', '02.05.2023', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('ADS_CAMPAIGN_GLOBAL_PRICE', '0', 'decimal', 'Ads campaign global price', false, '2022-08-05 17:00:55.612549 +00:00', '2023-03-31 15:30:27.794825 +00:00', 'ad', '05.08.22', 1) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('DOGS_CALCULATION_TIMING_START_CONF', '5000', 'integer', 'Dogs calculation timing start value', false, '2023-05-23 07:31:04.473799 +00:00', '2023-05-23 07:31:04.473799 +00:00', 'content', '02.05.2023', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('POINTS_PASSWORD', 'changethispassword', 'string', 'used for authentication for adding and deducting points in the admin console', false, '2023-04-07 13:12:39.846030 +00:00', '2023-04-07 13:12:39.846030 +00:00', 'tokens', '06.04.23', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('DOGS_CALCULATION_DISLIKE_COUNT_WEIGHT', '1', 'integer', 'Dogs feed. (calculations...) - c.dislikes_count * [X]', false, '2023-05-23 07:31:04.475110 +00:00', '2023-05-23 07:31:04.475110 +00:00', 'content', '02.05.2023', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('DOGS_CALCULATION_LOVE_COUNT_WEIGHT', '10', 'integer', 'Dogs feed. c.love_count * [X]', false, '2023-05-23 07:31:04.476275 +00:00', '2023-05-23 07:31:04.476275 +00:00', 'content', '02.05.2023', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('UPLOAD_DOGS_THRESHOLD', '100', 'integer', 'Amount of max dogs upload for not approved creators', false, '2023-06-21 09:40:37.912338 +00:00', '2023-06-21 09:40:37.912338 +00:00', 'content', '21.06.2023', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('ADS_PER_VIDEOS', '10', 'integer', 'Ads per videos frequency, possible values are: 2, 4, 5, 10, 20', false, '2022-06-18 10:20:27.783542 +00:00', '2023-04-13 14:41:21.767939 +00:00', 'content', '17.06.2022', 1) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('REWARDED_ADS_EVERY_X_VIDEOS', '10', 'integer', 'rewarded ads every x videos', false, '2023-04-06 13:07:03.771849 +00:00', '2023-04-13 14:42:00.247217 +00:00', 'content', '04.04.23', 1) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('FULL_SCREEN_GOOGLE_ADS_EVERY_X_VIDEOS', '31', 'integer', 'show full screen google ads each X video', false, '2022-08-22 08:45:13.703906 +00:00', '2023-04-21 18:16:14.304708 +00:00', 'ad', '16.08.22', 1) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('MUSIC_FEATURE_FEED_IGNORE_IDS_ENABLED', 'true', 'bool', 'Ignore viewed content ids', false, '2022-08-05 17:02:29.633837 +00:00', '2022-08-05 17:02:29.633837 +00:00', 'content', '05.08.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('MUSIC_FULL_VERSION_MAX_DURATION', '600', 'integer', 'full version of creator song max duration', false, '2022-08-05 17:02:29.637136 +00:00', '2022-08-05 17:02:29.637136 +00:00', 'music', '05.05.2022', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('MUSIC_SHORT_VERSION_MAX_DURATION', '15', 'integer', 'short version of creator song max duration', false, '2022-08-05 17:02:29.638572 +00:00', '2022-08-05 17:02:29.638572 +00:00', 'music', '05.05.2022', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('MUSIC_SHORT_VERSION_MIN_DURATION', '10', 'integer', 'short version of creator song mmin duration', false, '2022-08-05 17:02:29.639946 +00:00', '2022-08-05 17:02:29.639946 +00:00', 'music', '05.05.2022', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('CURRENT_TOKENOMICS_VERSION', '2', 'integer', 'Current tokenomics version. for now available 1 or 2', false, '2022-07-29 13:18:00.111540 +00:00', '2022-08-08 16:10:02.322513 +00:00', 'tokenomics', '29.07.22', 1) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('WITHDRAWAL_USER_WEEKLY_LIMIT', '10', 'decimal', 'user withdrawal weekly limit', false, '2022-08-12 13:06:31.920034 +00:00', '2022-08-12 13:06:31.920034 +00:00', 'tokenomics', '12.08.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('TOKENOMICS_V2_PAID_SESSION_TTL_SECONDS', '600', 'integer', 'Tokenomics v2. Paid session ttl. should be bigger than TOKENOMICS_V2_PAID_VIEW_TIME_SECONDS', false, '2022-07-29 13:22:41.772474 +00:00', '2022-07-29 13:22:41.772474 +00:00', 'tokenomics', '29.07.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('FIRST_N_SOCIAL_MEDIA_BONUS_POINTS_AMOUNT', '30', 'decimal', 'Bonus for social medias added', false, '2022-07-29 13:22:41.775596 +00:00', '2022-07-29 13:22:41.775596 +00:00', 'tokenomics', '22.07.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('FIRST_SOCIAL_MEDIA_QUANTITY', '3', 'integer', 'Quantity of social medias added for receiving bonus', false, '2022-07-29 13:22:41.776874 +00:00', '2022-07-29 13:22:41.776874 +00:00', 'tokenomics', '22.07.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('TOKENOMICS_V2_PAID_VIEW_TIME_SECONDS', '30', 'integer', 'Tokenomics v2. Paid view time SECONDS', false, '2022-07-29 13:22:41.777981 +00:00', '2022-07-29 13:22:41.777981 +00:00', 'tokenomics', '29.07.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('TOKENOMICS_FEATURE_ONE_PAID_SHARE_PER_LINK_ENABLED', 'true', 'bool', 'One Paid Share Per Device', false, '2022-05-16 07:35:03.549296 +00:00', '2022-05-17 10:11:16.629866 +00:00', 'tokenomics', '12.05.22', 1) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('BANNED_FIRST_POPUP_TIME_MINUTES', '28800', 'integer', 'First warning banned popup appears', false, '2022-05-20 13:54:24.120863 +00:00', '2022-05-20 13:54:24.120863 +00:00', 'users', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('BANNED_SECOND_POPUP_TIME_MINUTES', '21600', 'integer', 'Second warning banned popup appears', false, '2022-05-20 13:54:24.127750 +00:00', '2022-05-20 13:54:24.127750 +00:00', 'users', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('BANNED_THIRD_POPUP_TIME_MINUTES', '7200', 'integer', 'Third warning banned popup appears', false, '2022-05-20 13:54:24.128738 +00:00', '2022-05-20 13:54:24.128738 +00:00', 'users', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('BANNED_TILL_MINUTES', '43200', 'integer', 'Banned till minutes', false, '2022-05-20 13:54:24.129848 +00:00', '2022-05-20 13:54:24.129848 +00:00', 'users', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('VIEWS_DAYS_COUNT_FOR_NEW_UPLOAD', '7', 'integer', 'Days count for inflating for views', false, '2022-05-20 14:02:32.955800 +00:00', '2022-05-20 14:02:32.955800 +00:00', 'bots', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('VIEWS_PER_DAY_MAX', '20', 'integer', 'max views per day', false, '2022-05-20 14:02:32.957784 +00:00', '2022-05-20 14:02:32.957784 +00:00', 'bots', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('VIEWS_PER_DAY_MIN', '8', 'integer', 'min views per day', false, '2022-05-20 14:02:32.958662 +00:00', '2022-05-20 14:02:32.958662 +00:00', 'bots', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('INFLUENCER_INFLATING_MULTIPLIER', '20', 'integer', 'multiplier view for influencer', false, '2022-05-20 14:02:32.959453 +00:00', '2022-05-20 14:02:32.959453 +00:00', 'bots', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('LIKES_DAYS_COUNT_FOR_NEW_UPLOAD', '3', 'integer', 'Days count for inflating for likes', false, '2022-05-20 14:02:32.960472 +00:00', '2022-05-20 14:02:32.960472 +00:00', 'bots', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('LIKES_PER_DAY_MAX', '15', 'integer', 'max views per day', false, '2022-05-20 14:02:32.961341 +00:00', '2022-05-20 14:02:32.961341 +00:00', 'bots', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('LIKES_PER_DAY_MIN', '5', 'integer', 'min views per day', false, '2022-05-20 14:02:32.962182 +00:00', '2022-05-20 14:02:32.962182 +00:00', 'bots', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('MAX_UPLOAD_CONTENT_COUNT_FOR_INFLATING', '10', 'integer', 'Count of uploaded videos for inflating', false, '2022-05-20 14:02:32.962914 +00:00', '2022-05-20 14:02:32.962914 +00:00', 'bots', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('POINT_SPOTS_VIEW_USER', '1', 'decimal', 'point spots view user', false, '2022-05-20 14:04:16.644650 +00:00', '2022-05-20 14:04:16.644650 +00:00', 'tokenomics', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('POINT_SHARE_CC_USER', '10', 'decimal', 'point share cc user', false, '2022-05-20 14:04:16.645502 +00:00', '2022-05-20 14:04:16.645502 +00:00', 'tokenomics', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('DAILY_MAX_AMOUNT_OF_PAID_VIEWS', '1000', 'integer', 'daily max amount of paid views', false, '2022-05-20 14:04:16.646317 +00:00', '2022-05-20 14:04:16.646317 +00:00', 'tokenomics', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('GUEST_AFTER_INSTALL_THIRD_PUSH_CONDITION_TIME_MINUTES', '250', 'integer', 'guest after install third push condition time minutes', false, '2022-05-20 14:04:16.647153 +00:00', '2022-05-20 14:04:16.647153 +00:00', 'tokenomics', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('POINT_VIEW_CC_USER', '0.1', 'decimal', 'point view cc user', false, '2022-05-20 14:04:16.648059 +00:00', '2022-05-20 14:04:16.648059 +00:00', 'tokenomics', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('MEGA_BONUS_REFERRALS_TARGET', '100', 'integer', 'mega bonus referrals target', false, '2022-05-20 14:04:16.648986 +00:00', '2022-05-20 14:04:16.648986 +00:00', 'tokenomics', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('POINT_BONUS_DAILY_FOLLOWERS_TARGET', '50', 'integer', 'point bonus daily followers target', false, '2022-05-20 14:04:16.649843 +00:00', '2022-05-20 14:04:16.649843 +00:00', 'tokenomics', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('POINT_BONUS_DAILY_TIME', '100', 'decimal', 'point bonus daily time', false, '2022-05-20 14:04:16.650692 +00:00', '2022-05-20 14:04:16.650692 +00:00', 'tokenomics', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('TOKENOMICS_FIRST_X_PAID_VIEWS_COUNT', '10', 'integer', 'tokenomics first x paid views count', false, '2022-05-20 14:04:16.651426 +00:00', '2022-05-20 14:04:16.651426 +00:00', 'tokenomics', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('TOKENOMICS_FIRST_X_PAID_VIEWS_COUNT_GENDER_PUSH', '50', 'integer', 'tokenomics first x paid views count gender push', false, '2022-05-20 14:04:16.652209 +00:00', '2022-05-20 14:04:16.652209 +00:00', 'tokenomics', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('PAID_SHARE_MAX_PER_USER', '2', 'integer', 'paid share max per user', false, '2022-05-20 14:04:16.652940 +00:00', '2022-05-20 14:04:16.652940 +00:00', 'tokenomics', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('POINT_BASE_RATE', '1', 'decimal', 'point base rate', false, '2022-05-20 14:04:16.653658 +00:00', '2022-05-20 14:04:16.653658 +00:00', 'tokenomics', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('POINTS_THRESHOLD_FOR_USER_WITHOUT_KYC', '100000', 'decimal', 'points threshold for user without kyc', false, '2022-05-20 14:04:16.654339 +00:00', '2022-05-20 14:04:16.654339 +00:00', 'tokenomics', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('ANDROID_APP_VERSION', '1.3', 'decimal', 'android app version', false, '2022-06-18 10:17:36.565020 +00:00', '2022-06-18 10:17:36.565020 +00:00', 'applications', '17.06.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('GUEST_MAX_EARNED_POINTS_FOR_VIEWS', '100', 'decimal', 'guest max earned points for views', false, '2022-05-20 14:04:16.655836 +00:00', '2022-05-20 14:04:16.655836 +00:00', 'tokenomics', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('MEGA_BONUS_REFERRALS_VIDEO_URL', 'https://video.cdn.lit.it/content/75c0da32-5c71-417b-baf0-04a41cbe38bc/v.mp4', 'string', 'mega bonus referrals video url', false, '2022-05-20 14:04:16.658180 +00:00', '2022-05-20 14:04:16.658180 +00:00', 'tokenomics', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('PAID_SHARE_TIMEOUT_PER_USER', '14', 'integer', 'paid share timeout per user', false, '2022-05-20 14:04:16.659034 +00:00', '2022-05-20 14:04:16.659034 +00:00', 'tokenomics', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('PAID_VIEW_THRESHOLD_PERCENT', '60', 'decimal', 'paid view threshold percent', false, '2022-05-20 14:04:16.659855 +00:00', '2022-05-20 14:04:16.659855 +00:00', 'tokenomics', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('POINT_BONUS_DAILY_TIME_TARGET', '30', 'integer', 'point bonus daily time target', false, '2022-05-20 14:04:16.660621 +00:00', '2022-05-20 14:04:16.660621 +00:00', 'tokenomics', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('POINT_BONUS_WEEKLY_TIME', '500', 'decimal', 'point bonus weekly time', false, '2022-05-20 14:04:16.661321 +00:00', '2022-05-20 14:04:16.661321 +00:00', 'tokenomics', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('MAX_DESCRIPTION_CHAR_COUNT', '150', 'integer', 'max description characters count', false, '2022-06-18 10:17:36.572641 +00:00', '2022-06-18 10:17:36.572641 +00:00', 'content', '17.06.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('GUEST_TOKENOMICS_FIRST_X_PAID_VIEWS_COUNT', '0', 'integer', 'guest tokenomics first x paid views count', false, '2022-05-20 14:04:16.662799 +00:00', '2022-05-20 14:04:16.662799 +00:00', 'tokenomics', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('WEEKLY_TIME_TARGET', '7', 'integer', 'weekly time target', false, '2022-05-20 14:04:16.663490 +00:00', '2022-05-20 14:04:16.663490 +00:00', 'tokenomics', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('USER_AFTER_SIGNUP_THIRD_PUSH_CONDITION_TIME_MINUTES', '60', 'integer', 'user after signup third push condition time minutes', false, '2022-05-20 14:04:16.664876 +00:00', '2022-05-20 14:04:16.664876 +00:00', 'tokenomics', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('PAID_SHARE_VIEW_THRESHOLD_PERCENT', '20', 'decimal', 'paid share view threshold percent', false, '2022-05-20 14:04:16.665589 +00:00', '2022-05-20 14:04:16.665589 +00:00', 'tokenomics', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('AVATAR_ADDED_BONUS_POINTS_AMOUNT', '30', 'decimal', 'avatar added bonus points amount', false, '2022-05-20 14:04:16.666397 +00:00', '2022-05-20 14:04:16.666397 +00:00', 'tokenomics', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('GUEST_AFTER_INSTALL_FIRST_PUSH_CONDITION_POINTS', '3', 'decimal', 'guest after install first push condition points', false, '2022-05-20 14:04:16.667115 +00:00', '2022-05-20 14:04:16.667115 +00:00', 'tokenomics', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('IOS_APP_FILE_URL', '', 'string', 'url on file ios application', false, '2022-06-24 15:43:33.295891 +00:00', '2022-06-24 15:43:33.295891 +00:00', 'applications', '24.06.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('TOKENOMICS_REFERRAL_EARNED_POINTS_THRESHOLD_STEP_1', '40', 'decimal', 'tokenomics referral earned points threshold step 1', false, '2022-05-20 14:04:16.668522 +00:00', '2022-05-20 14:04:16.668522 +00:00', 'tokenomics', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('USER_AFTER_SIGNUP_FIFTH_PUSH_CONDITION_TIME_HOURS', '48', 'integer', 'user after signup fifth push condition time hours', false, '2022-05-20 14:04:16.669188 +00:00', '2022-05-20 14:04:16.669188 +00:00', 'tokenomics', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('GUEST_AFTER_INSTALL_SECOND_PUSH_CONDITION_TIME_MINUTES', '130', 'integer', 'guest after install second push condition time minutes', false, '2022-05-20 14:04:16.669898 +00:00', '2022-05-20 14:04:16.669898 +00:00', 'tokenomics', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('MEGA_BONUS_REFERRALS_CONTENT_ID', '1053684', 'string', 'mega bonus referrals content id', false, '2022-05-20 14:04:16.670561 +00:00', '2022-05-20 14:04:16.670561 +00:00', 'tokenomics', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('PAID_VIEW_TIMEOUT_PER_USER', '14', 'integer', 'paid view timeout per user', false, '2022-05-20 14:04:16.671233 +00:00', '2022-05-20 14:04:16.671233 +00:00', 'tokenomics', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('POINT_BONUS_GRANDREFERRER_VERIFY', '30', 'decimal', 'point bonus grandreferrer verify', false, '2022-05-20 14:04:16.672086 +00:00', '2022-05-20 14:04:16.672086 +00:00', 'tokenomics', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('POINT_BONUS_VERIFY', '0', 'decimal', 'point bonus verify', false, '2022-05-20 14:04:16.672834 +00:00', '2022-05-20 14:04:16.672834 +00:00', 'tokenomics', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('POINT_TO_TOKEN', '1000', 'decimal', 'point to token', false, '2022-05-20 14:04:16.673686 +00:00', '2022-05-20 14:04:16.673686 +00:00', 'tokenomics', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('KYC_GRACE_PERIOD', '90', 'integer', 'kyc grace period', false, '2022-05-20 14:04:16.657325 +00:00', '2022-05-31 10:56:24.597185 +00:00', 'tokenomics', '20.05.22', 1) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('PAID_VIEW_THRESHOLD_TIME', '40', 'integer', 'paid view threshold time', false, '2022-05-20 14:04:16.642836 +00:00', '2022-05-31 15:27:50.534843 +00:00', 'tokenomics', '20.05.22', 1) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('THRESHOLD_FOR_PERFORMANCE_BONUS_FOR_CONTENT_PAID_SPOTS_VIEWS', '0', 'decimal', 'threshold for performance bonus for content paid spots views', false, '2022-05-20 14:04:16.664179 +00:00', '2022-06-01 08:39:11.956412 +00:00', 'tokenomics', '20.05.22', 1) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('AI_FEED_LOCATION_COEFFICIENT', '50', 'decimal', 'AI Feed location coefficient', false, '2022-05-30 09:12:51.167495 +00:00', '2022-06-24 10:31:24.039614 +00:00', 'content', '20.05.22', 1) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('SPOT_CALCULATION_TIMING_DELIMITER', '1', 'integer', 'Spot calculation delimiter', false, '2022-06-02 08:01:24.017707 +00:00', '2022-06-16 12:11:04.605416 +00:00', 'content', '31.05.2022', 1) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('ANDROID_APP_FILE_URL', '', 'string', 'url on file android application', false, '2022-06-24 15:43:33.508759 +00:00', '2022-06-24 15:43:33.508759 +00:00', 'applications', '24.06.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('TOKENOMICS_WITHDRAW_LIMIT_BEFORE_VESTING', '50000', 'decimal', 'tokenomics withdraw limit before vesting', false, '2022-05-20 14:04:16.675826 +00:00', '2022-05-20 14:04:16.675826 +00:00', 'tokenomics', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('TOKENS_FOR_PERFORMANCE_BONUS_OF_CONTENT_PAID_SPOTS_VIEWS', '10', 'decimal', 'tokens for performance bonus of content paid spots views', false, '2022-05-20 14:04:16.676561 +00:00', '2022-05-20 14:04:16.676561 +00:00', 'tokenomics', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('DAILY_MAX_AMOUNT_OF_PAID_SHARES', '10', 'integer', 'daily max amount of paid shares', false, '2022-05-20 14:04:16.677340 +00:00', '2022-05-20 14:04:16.677340 +00:00', 'tokenomics', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('SPOT_FEED_LIMIT', '10000', 'integer', 'Spot feed limit', false, '2022-06-02 08:01:24.024245 +00:00', '2022-06-02 08:01:24.024245 +00:00', 'content', '31.05.2022', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('PAID_VIEW_MAX_PER_USER', '2', 'integer', 'paid view max per user', false, '2022-05-20 14:04:16.678077 +00:00', '2022-05-20 14:04:16.678077 +00:00', 'tokenomics', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('USER_AFTER_SIGNUP_FOURTH_PUSH_CONDITION_TIME_HOURS', '24', 'integer', 'user after signup fourth push condition time hours', false, '2022-05-20 14:04:16.678847 +00:00', '2022-05-20 14:04:16.678847 +00:00', 'tokenomics', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('VISITORS_UNLOCKED_FEATURE_PRICE', '10', 'decimal', 'visitors unlocked feature price', false, '2022-05-20 14:04:16.679687 +00:00', '2022-05-20 14:04:16.679687 +00:00', 'tokenomics', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('TOKEN_RESERVE_SUPPLY', '10000000', 'decimal', 'token reserve supply', false, '2022-05-20 14:04:16.680509 +00:00', '2022-05-20 14:04:16.680509 +00:00', 'tokenomics', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('TOKEN_TOTAL_SUPPLY', '459000000', 'decimal', 'token total supply', false, '2022-05-20 14:04:16.681272 +00:00', '2022-05-20 14:04:16.681272 +00:00', 'tokenomics', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('PAID_SPOTS_VIEW_THRESHOLD_PERCENT', '100', 'decimal', 'paid spots view threshold percent', false, '2022-05-20 14:04:16.681991 +00:00', '2022-05-20 14:04:16.681991 +00:00', 'tokenomics', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('USER_AFTER_SIGNUP_SECOND_PUSH_CONDITION_TIME_MINUTES', '30', 'integer', 'user after signup second push condition time minutes', false, '2022-05-20 14:04:16.682712 +00:00', '2022-05-20 14:04:16.682712 +00:00', 'tokenomics', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('GUEST_AFTER_INSTALL_FIRST_PUSH_CONDITION_TIME_MINUTES', '10', 'integer', 'guest after install first push condition time minutes', false, '2022-05-20 14:04:16.684186 +00:00', '2022-05-20 14:04:16.684186 +00:00', 'tokenomics', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('GUEST_TOKENOMICS_FIRST_X_EARNED_POINTS', '10', 'decimal', 'guest tokenomics first x earned points', false, '2022-05-20 14:04:16.684927 +00:00', '2022-05-20 14:04:16.684927 +00:00', 'tokenomics', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('MEGA_BONUS_TIME_LIMIT_AFTER_START_MINUTES', '10080', 'integer', 'mega bonus time limit after start minutes', false, '2022-05-20 14:04:16.685705 +00:00', '2022-05-20 14:04:16.685705 +00:00', 'tokenomics', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('POINT_SHARE_CC_GUEST', '10', 'decimal', 'point share cc guest', false, '2022-05-20 14:04:16.686425 +00:00', '2022-05-20 14:04:16.686425 +00:00', 'tokenomics', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('POINT_VIEW_USER', '1', 'decimal', 'point view user', false, '2022-05-20 14:04:16.687216 +00:00', '2022-05-20 14:04:16.687216 +00:00', 'tokenomics', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('GUEST_AFTER_INSTALL_SECOND_PUSH_CONDITION_POINTS', '3', 'decimal', 'guest after install second push condition points', false, '2022-05-20 14:04:16.688064 +00:00', '2022-05-20 14:04:16.688064 +00:00', 'tokenomics', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('MAX_TIP_POINTS_PER_DAY_FOR_USER_WITHOUT_KYC', '150', 'decimal', 'max tip points per day for user without kyc', false, '2022-05-20 14:04:16.688766 +00:00', '2022-05-20 14:04:16.688766 +00:00', 'tokenomics', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('POINT_BONUS_FIRST_VIDEO_UPLOADED', '30', 'decimal', 'point bonus first video uploaded', false, '2022-05-20 14:04:16.689551 +00:00', '2022-05-20 14:04:16.689551 +00:00', 'tokenomics', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('POINT_SHARE_USER', '10', 'decimal', 'point share user', false, '2022-05-20 14:04:16.690295 +00:00', '2022-05-20 14:04:16.690295 +00:00', 'tokenomics', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('POINT_VIEW_SHARER', '10', 'decimal', 'point view sharer', false, '2022-05-20 14:04:16.691009 +00:00', '2022-05-20 14:04:16.691009 +00:00', 'tokenomics', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('TOKENOMICS_FIRST_X_PAID_VIEWS_AS_CONTENT_OWNER_COUNT', '1000', 'integer', 'tokenomics first x paid views as content owner count', false, '2022-05-20 14:04:16.692615 +00:00', '2022-05-20 14:04:16.692615 +00:00', 'tokenomics', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('POINT_BONUS_DESCRIPTION', '30', 'decimal', 'point bonus description', false, '2022-05-20 14:04:16.693329 +00:00', '2022-05-20 14:04:16.693329 +00:00', 'tokenomics', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('POINT_BONUS_FIRST_SPOT_UPLOADED', '30', 'decimal', 'point bonus first spot uploaded', false, '2022-05-20 14:04:16.694043 +00:00', '2022-05-20 14:04:16.694043 +00:00', 'tokenomics', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('POINT_BONUS_REFERRER_VERIFY', '100', 'decimal', 'point bonus referrer verify', false, '2022-05-20 14:04:16.694834 +00:00', '2022-05-20 14:04:16.694834 +00:00', 'tokenomics', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('POINT_SPOTS_VIEW_CC_USER', '0.1', 'decimal', 'point spots view creator user', false, '2022-05-20 14:04:16.695566 +00:00', '2022-05-20 14:04:16.695566 +00:00', 'tokenomics', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('TOKENS_FOR_PERFORMANCE_BONUS_OF_CONTENT_PAID_VIEWS', '10', 'decimal', 'tokens for performance bonus of content paid views', false, '2022-05-20 14:04:16.696473 +00:00', '2022-05-20 14:04:16.696473 +00:00', 'tokenomics', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('WEEKLY_FOLLOWERS_TARGET', '7', 'integer', 'weekly followers target', false, '2022-05-20 14:04:16.697271 +00:00', '2022-05-20 14:04:16.697271 +00:00', 'tokenomics', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('POINT_BONUS_DAILY_FOLLOWERS', '100', 'decimal', 'point bonus daily followers', false, '2022-05-20 14:04:16.698753 +00:00', '2022-05-20 14:04:16.698753 +00:00', 'tokenomics', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('POINT_VIEW_CC_GUEST', '0.1', 'decimal', 'point view cc guest', false, '2022-05-20 14:04:16.699427 +00:00', '2022-05-20 14:04:16.699427 +00:00', 'tokenomics', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('GUEST_AFTER_INSTALL_THIRD_PUSH_CONDITION_POINTS', '3', 'decimal', 'guest after install third push condition points', false, '2022-05-20 14:04:16.701037 +00:00', '2022-05-20 14:04:16.701037 +00:00', 'tokenomics', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('POINT_BONUS_WEEKLY_FOLLOWERS', '500', 'decimal', 'point bonus weekly followers', false, '2022-05-20 14:04:16.701783 +00:00', '2022-05-20 14:04:16.701783 +00:00', 'tokenomics', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('USER_AFTER_SIGNUP_FIRST_PUSH_CONDITION_TIME_MINUTES', '10', 'integer', 'user after signup first push condition time minutes', false, '2022-05-20 14:04:16.702486 +00:00', '2022-05-20 14:04:16.702486 +00:00', 'tokenomics', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('DAILY_MAX_AMOUNT_OF_PAID_SPOTS_VIEWS', '200', 'integer', 'daily max amount of paid spots views', false, '2022-05-20 14:04:16.683403 +00:00', '2022-09-09 21:06:00.379843 +00:00', 'tokenomics', '20.05.22', 1) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('WITHDRAWAL_FEATURE_ENABLED', 'true', 'bool', 'globally enable or disable withdrawal feature in system', false, '2022-05-24 08:40:49.732189 +00:00', '2022-05-24 08:40:49.732189 +00:00', 'tokenomics', '23.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('DISCLAIMER_URL', 'https://docs.google.com/document/d/1zNrR2hedMaj2xSzjIlwwpCOGuAKHh_Ljiayy9SdkaSo/edit?usp=sharing', 'string', 'link on disclaimer', false, '2022-06-18 10:17:36.573908 +00:00', '2022-06-18 10:17:36.573908 +00:00', 'applications', '17.06.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('SPOT_CALCULATION_DISLIKE_COUNT_WEIGHT', '1', 'integer', 'Spot feed. (calculations...) - c.dislikes_count * [X]', false, '2022-06-02 08:01:24.018766 +00:00', '2022-06-24 10:29:42.303874 +00:00', 'content', '31.05.2022', 1) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('AI_FEED_SHARE_COEFFICIENT', '5', 'decimal', 'AI Feed share coefficient', false, '2022-05-30 09:12:51.165934 +00:00', '2022-05-30 09:12:51.165934 +00:00', 'content', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('EMAIL_MARKETING_BONUS_POINTS_AMOUNT', '100', 'decimal', 'points for first confirmed marketing email', false, '2022-05-30 08:56:46.068265 +00:00', '2022-05-30 08:56:46.068265 +00:00', 'tokenomics', '27.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('TEMP_GUEST_INFO_EXPIRE_MINUTES', '1440', 'integer', 'Temp guest info expire minutes before anyone else could use them if guest will not register', false, '2022-05-30 09:09:42.215274 +00:00', '2022-05-30 09:09:42.215274 +00:00', 'users', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('AI_FEED_LIKE_COEFFICIENT', '2', 'decimal', 'AI Feed like coefficient', false, '2022-05-30 09:12:51.167036 +00:00', '2022-05-30 09:12:51.167036 +00:00', 'content', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('SPOT_CALCULATION_LIKE_COUNT_WEIGHT', '1', 'integer', 'Spot feed. c.likes_count * [X]', false, '2022-06-02 08:01:24.014635 +00:00', '2022-06-24 10:29:53.324725 +00:00', 'content', '31.05.2022', 1) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('IOS_APP_BUILD', '47', 'integer', 'ios app build', false, '2022-06-18 10:17:36.575435 +00:00', '2022-06-18 10:17:36.575435 +00:00', 'applications', '17.06.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('AI_FEED_MODERATOR_RATE_COEFFICIENT', '0.1', 'decimal', 'AI Feed moderator rate coefficient', false, '2022-05-30 09:12:51.168374 +00:00', '2022-05-30 09:12:51.168374 +00:00', 'content', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('TOKENIMICS_REFERRAL_INCREASE_MULTIPLIER_STEP_1', '1', 'decimal', 'tokenimics referral increase multiplier step 1', false, '2022-05-20 14:04:16.691851 +00:00', '2022-06-01 18:41:34.248526 +00:00', 'tokenomics', '20.05.22', 1) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('PAID_SHARE_VIEW_THRESHOLD_TIME', '2', 'integer', 'paid share view threshold time', false, '2022-05-20 14:04:16.698001 +00:00', '2022-05-31 14:45:34.507324 +00:00', 'tokenomics', '20.05.22', 1) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('TOKENIMICS_REFERRAL_INCREASE_MULTIPLIER_STEP_2', '1', 'decimal', 'tokenimics referral increase multiplier step 2', false, '2022-05-20 14:04:16.700131 +00:00', '2022-06-01 18:41:47.764830 +00:00', 'tokenomics', '20.05.22', 1) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('AD_POINTS_AMOUNT_REWARD', '5', 'decimal', 'ad points amount reward', false, '2022-05-20 14:04:16.662112 +00:00', '2022-06-14 10:55:49.389439 +00:00', 'tokenomics', '20.05.22', 1) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('WITHDRAWAL_CREATION_INTERVAL_MINUTES', '60', 'integer', 'interval in MINUTES which we use to check if there was no withdrawals in status [WithdrawalStatusPending, WithdrawalStatusApproved, WithdrawalStatusPaymentPending, WithdrawalStatusPaid, WithdrawalStatusNone]', false, '2022-05-24 08:40:49.735639 +00:00', '2022-06-16 15:53:27.384936 +00:00', 'tokenomics', '23.05.22', 1) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('MAX_HASHTAGS_COUNT', '3', 'integer', 'max hashtags count', false, '2022-06-18 10:17:36.576462 +00:00', '2022-06-18 10:17:36.576462 +00:00', 'content', '17.06.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('TERMS_LINK', 'https://lit.it/pages/terms_service', 'string', 'link on terms of service', false, '2022-06-18 10:17:36.577512 +00:00', '2022-06-18 10:17:36.577512 +00:00', 'applications', '17.06.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('PAID_SPOTS_VIEW_THRESHOLD_TIME', '2', 'integer', 'paid spots view threshold time', false, '2022-05-20 14:04:16.704054 +00:00', '2022-05-31 14:45:38.569442 +00:00', 'tokenomics', '20.05.22', 1) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('THRESHOLD_FOR_PERFORMANCE_BONUS_FOR_CONTENT_PAID_VIEWS', '0', 'integer', 'threshold for performance bonus for content paid views', false, '2022-05-20 14:04:16.667832 +00:00', '2022-06-01 08:38:58.969507 +00:00', 'tokenomics', '20.05.22', 1) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('DAYS_UNTIL_USER_HAS_BEEN_DELETED', '10', 'integer', 'days until user has been deleted after deletion request', false, '2022-06-10 14:24:37.197940 +00:00', '2022-06-10 14:24:37.197940 +00:00', 'users', '10.06.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('IRONSOURCE_REWARD', '0', 'decimal', 'ironsource reward', false, '2022-06-10 14:25:14.843414 +00:00', '2022-06-10 14:25:14.843414 +00:00', 'tokenomics', '27.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('USER_AFTER_SIGNUP_FIRST_PUSH_CONDITION_CONTENT_ID', '1053684', 'integer', 'user after signup first push condition content id', false, '2022-05-20 14:04:16.655123 +00:00', '2022-06-14 08:47:01.264383 +00:00', 'tokenomics', '20.05.22', 1) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('ANDROID_APP_NEW_VERSION', '', 'string', 'android app new version', false, '2022-06-18 10:17:36.579600 +00:00', '2022-06-18 10:17:36.579600 +00:00', 'applications', '17.06.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('UPLOAD_SPOT_THRESHOLD', '100', 'integer', 'Amount of max spots upload for not approved creators', false, '2022-06-24 15:49:55.404537 +00:00', '2022-06-24 15:49:55.404537 +00:00', 'content', '17.06.2022', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('DAILY_TOP_SPOT_BONUS', '1000', 'decimal', 'Author of the daily best spot reward', false, '2022-06-24 15:50:53.119211 +00:00', '2022-06-24 15:50:53.119211 +00:00', 'tokenomics', '24.06.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('WEEKLY_TOP_SPOT_BONUS', '1000', 'decimal', 'Author of the weekly best spot reward', false, '2022-06-24 15:50:53.120779 +00:00', '2022-06-24 15:50:53.120779 +00:00', 'tokenomics', '24.06.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('WITHDRAWAL_WHITELIST_USER_IDS', '0,1,1078,19594, 36653', 'string', 'skip withdrawals checks for specific user ids, should be comma separated, example 1,2,3,4', false, '2022-05-23 08:39:04.528941 +00:00', '2022-06-16 14:44:28.643168 +00:00', 'tokenomics', '23.05.22', 1) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('IOS_APP_VERSION', '1', 'decimal', 'ios app version', false, '2022-06-18 10:17:36.581328 +00:00', '2022-06-18 10:17:36.581328 +00:00', 'applications', '17.06.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('PRIVACY_LINK', 'https://lit.it/pages/privacy-notice', 'string', 'link on privacy notice', false, '2022-06-18 10:17:36.582301 +00:00', '2022-06-18 10:17:36.582301 +00:00', 'applications', '17.06.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('MAX_VIDEO_DURATION', '60', 'integer', 'max content duration in seconds', false, '2022-06-18 10:20:27.779972 +00:00', '2022-06-18 10:20:27.779972 +00:00', 'content', '17.06.2022', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('MIN_VIDEO_DURATION', '3', 'integer', 'min content duration in seconds', false, '2022-06-18 10:20:27.782432 +00:00', '2022-06-18 10:20:27.782432 +00:00', 'content', '17.06.2022', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('DEFAULT_OHW_REJECT_MESSAGE', 'Your One Hit Wonder application has been rejected.', 'string', 'The default message to send when rejecting a One Hit Wonder application.', false, '2022-06-18 10:20:27.785918 +00:00', '2022-06-18 10:20:27.785918 +00:00', 'content', '17.06.2022', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('DEFAULT_CC_REJECT_MESSAGE', 'Insufficient following. Try applying for a One Hit Wonder application.', 'string', 'The default message to send when rejecting a content creator application.', false, '2022-06-18 10:20:27.787096 +00:00', '2022-06-18 10:20:27.787096 +00:00', 'content', '17.06.2022', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('POINTS_EARNED_ADS_TRIGGER', '25', 'decimal', 'Show ad for user when he earned these points', false, '2022-06-18 10:20:27.788160 +00:00', '2022-06-18 10:20:27.788160 +00:00', 'content', '17.06.2022', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('MEGA_BONUS_MIN_TOTAL_WATCH_TIME_REQUIREMENT', '600', 'integer', 'mega bonus min total watch time requirement', false, '2022-05-20 14:04:16.656529 +00:00', '2022-06-21 07:50:22.460408 +00:00', 'tokenomics', '20.05.22', 1) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('UPLOAD_VIDEO_THRESHOLD', '300', 'integer', 'Amount of max videos upload for not approved creators', false, '2022-06-18 10:20:27.784807 +00:00', '2022-06-21 08:58:33.979176 +00:00', 'content', '17.06.2022', 1) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('SPOT_CALCULATION_WATCH_COUNT_WEIGHT', '1', 'integer', 'Spot feed. c.watch_count * [X]', false, '2022-06-02 08:01:24.022972 +00:00', '2022-06-24 10:29:28.141295 +00:00', 'content', '31.05.2022', 1) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('SPOT_CALCULATION_LOVE_COUNT_WEIGHT', '1', 'integer', 'Spot feed. c.love_count * [X]', false, '2022-06-02 08:01:24.020191 +00:00', '2022-06-24 10:29:36.481068 +00:00', 'content', '31.05.2022', 1) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('SPOT_CALCULATION_TIMING_START_CONF', '1500000000', 'integer', 'Spot calculation timing start value', false, '2022-06-02 08:01:24.021506 +00:00', '2022-06-24 10:30:30.876490 +00:00', 'content', '31.05.2022', 1) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('AI_FEED_LOCATION_CONFIRMED_COEFFICIENT', '50', 'decimal', 'AI Feed location confirmed coefficient', false, '2022-05-30 09:12:51.167929 +00:00', '2022-06-24 10:31:19.363063 +00:00', 'content', '20.05.22', 1) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('SOCIAL_SUBS_TARGET', '3', 'integer', 'Number of social subscriptions to reach in order to get points', false, '2022-07-01 15:45:55.954928 +00:00', '2022-07-01 15:45:55.954928 +00:00', 'users', '01.07.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('MAX_BORING_SPOTS_NUMBER', '3', 'integer', 'Max boring spots number. If more or equal, user is not allowed to download spots.', false, '2022-07-01 15:48:34.502832 +00:00', '2022-07-01 15:48:34.502832 +00:00', 'content', '24.06.2022', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('SHADOW_BAN_COEFFICIENT', '1000', 'decimal', 'Shadow ban coefficient', false, '2022-07-08 19:07:23.176489 +00:00', '2022-07-08 19:07:23.176489 +00:00', 'content', '01.07.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('POINT_BONUS_MONTHLY_TIME_REWARD', '10000', 'decimal', 'Reward for monthly time bonus', false, '2022-07-08 19:10:13.403219 +00:00', '2022-07-08 19:10:13.403219 +00:00', 'tokenomics', '08.07.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('WITHDRAWAL_BLOCKED_COUNTRIES', 'RU,BY', 'string', 'list of country codes, where withdrawal should be blocked, should be comma separated. example US,UA,TR', false, '2022-05-24 08:40:49.734706 +00:00', '2022-07-06 15:12:01.068101 +00:00', 'tokenomics', '23.05.22', 1) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('POINT_BONUS_MONTHLY_DAILY_TIME', '5', 'integer', 'Monthly time bonus threshold for a day', false, '2022-07-08 19:10:13.408081 +00:00', '2022-07-08 19:10:13.408081 +00:00', 'tokenomics', '08.07.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('POINT_BONUS_MONTHLY_TIME_SKIP', '1', 'integer', 'Reward for monthly time bonus', false, '2022-07-08 19:10:13.409434 +00:00', '2022-07-08 19:10:13.409434 +00:00', 'tokenomics', '08.07.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('POINT_BONUS_MONTHLY_TIME_TARGET', '30', 'integer', 'The number of days for monthly time bonus', false, '2022-07-08 19:10:13.410747 +00:00', '2022-07-08 19:10:13.410747 +00:00', 'tokenomics', '08.07.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('POINT_BONUS_SOCIAL_SUBS_TARGET_ACHIEVED', '50', 'decimal', 'point bonus social subs target achieved', false, '2022-07-08 19:10:13.412078 +00:00', '2022-07-08 19:10:13.412078 +00:00', 'tokenomics', '01.07.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('BONUS_MONTHLY_TIME_DO_NOT_MISS_TIME_MINUTES', '1140', 'integer', 'Do not miss bonus monthly push time minutes from day start (60 for 01:00, 1140 for 19:00)', false, '2022-07-15 15:49:45.037318 +00:00', '2022-07-15 15:49:45.037318 +00:00', 'tokenomics', '15.07.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('POINT_BONUS_FIRST_BIO_VIDEO_UPLOADED', '30', 'decimal', 'point bonus first bio video uploaded', false, '2022-07-15 15:49:45.046916 +00:00', '2022-07-15 15:49:45.046916 +00:00', 'tokenomics', '20.05.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('BONUS_MONTHLY_TIME_ENABLE', 'true', 'bool', 'Enable monthly time bonus', false, '2022-07-08 19:10:13.406748 +00:00', '2022-07-11 15:12:30.360753 +00:00', 'tokenomics', '08.07.22', 1) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('SPOTS_UPLOAD_BAN_EXPIRATION_PERIOD_MINUTES', '1440', 'integer', 'Spots upload ban expires in specified time', false, '2022-07-01 15:45:55.955641 +00:00', '2022-07-19 10:57:41.917573 +00:00', 'users', '01.07.22', 1) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('AUTH_FEATURE_ONE_ACCOUNT_PER_DEVICE_WHITELISTED_DEVICES', '1,2,3', 'string', 'whitelisted device ids for one account feature', false, '2022-07-01 15:45:12.720666 +00:00', '2022-07-19 12:41:23.695979 +00:00', 'applications', '28.06.2022', 1) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('MIN_BIO_DURATION', '30', 'integer', 'min bio duration in seconds', false, '2022-07-22 13:48:37.661618 +00:00', '2022-07-22 13:48:37.661618 +00:00', 'content', '22.07.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('MIN_SPOT_DURATION', '5', 'integer', 'min spot duration in seconds', false, '2022-07-22 13:48:37.671110 +00:00', '2022-07-22 13:48:37.671110 +00:00', 'content', '22.07.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('MAX_BIO_DURATION', '60', 'integer', 'max bio duration in seconds', false, '2022-07-22 13:48:37.672231 +00:00', '2022-07-22 13:48:37.672231 +00:00', 'content', '22.07.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('MAX_SPOT_DURATION', '15', 'integer', 'max spot duration in seconds', false, '2022-07-22 13:48:37.673314 +00:00', '2022-07-22 13:48:37.673314 +00:00', 'content', '22.07.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('MUSIC_CALCULATION_DISLIKE_COUNT_WEIGHT', '1', 'integer', 'Music feed. (calculations...) - c.dislikes * [X]', false, '2022-07-29 13:25:06.324352 +00:00', '2022-07-29 13:25:06.324352 +00:00', 'music', '29.07.2022', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('MUSIC_CALCULATION_LIKE_COUNT_WEIGHT', '6', 'integer', 'Music feed. c.likes * [X]', false, '2022-07-29 13:25:06.328004 +00:00', '2022-07-29 13:25:06.328004 +00:00', 'music', '29.07.2022', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('MUSIC_CALCULATION_TIMING_DELIMITER', '60', 'integer', 'Music calculation delimiter', false, '2022-07-29 13:25:06.329704 +00:00', '2022-07-29 13:25:06.329704 +00:00', 'music', '29.07.2022', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('MUSIC_FEED_UPDATE_SCORE_FREQUENCY_MINUTES', '60', 'integer', 'Period per music score update', false, '2022-07-29 13:25:06.331389 +00:00', '2022-07-29 13:25:06.331389 +00:00', 'music', '29.07.2022', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('MUSIC_MAX_HASHTAGS_COUNT', '10', 'integer', 'Max hashtags count in music', false, '2022-07-29 13:25:06.333039 +00:00', '2022-07-29 13:25:06.333039 +00:00', 'applications', '22.07.22', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('AUTH_FEATURE_ONE_ACCOUNT_PER_DEVICE_ENABLED', 'false', 'bool', 'One Account Per Device', false, '2022-05-16 07:31:16.696989 +00:00', '2022-05-16 07:31:16.696989 +00:00', 'auth', '06.05.22', null) ON CONFLICT (key) DO NOTHING;`)
-			},
-		},
-		{
-			ID: "seed_the_config_20240318",
-			Migrate: func(db *gorm.DB) error {
-				if !boilerplate.InLocal() {
-					log.Warn().Msg("skipping seeding the config table in non-local environment")
-					return nil
-				}
-
-				return boilerplate_testing.ExecutePostgresSql(db, `
-UPDATE configs SET value = 5 where key = 'POINT_BONUS_REFERRER_VERIFY';
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('TOTAL_HOURS_REFERRED_WATCH_VIDEO', '10', 'integer', 'total hours referred watch video', false, '2024-03-18 14:04:16.694834 +00:00', '2024-03-18 14:04:16.694834 +00:00', 'tokenomics', '18.03.24', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('POINTS_BONUS_REFERRED_WATCH_VIDEO', '1000', 'decimal', 'points bonus referred watch video', false, '2024-03-18 14:04:16.694834 +00:00', '2024-03-18 14:04:16.694834 +00:00', 'tokenomics', '18.03.24', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('MAX_POINTS_BONUS_REFERRED_WATCH_VIDEO', '5000', 'decimal', 'max points bonus referred watch video', false, '2024-03-18 14:04:16.694834 +00:00', '2024-03-18 14:04:16.694834 +00:00', 'tokenomics', '18.03.24', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('TOTAL_FRIENDS_REFERRED_IN_7_DAYS', '100', 'integer', 'total friends referred in 7 days', false, '2024-03-18 14:04:16.694834 +00:00', '2024-03-18 14:04:16.694834 +00:00', 'tokenomics', '18.03.24', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('POINTS_BONUS_FRIENDS_REFERRED_IN_7_DAYS', '20000', 'decimal', 'points bonus friends referred in 7 days', false, '2024-03-18 14:04:16.694834 +00:00', '2024-03-18 14:04:16.694834 +00:00', 'tokenomics', '18.03.24', null) ON CONFLICT (key) DO NOTHING;`)
-			},
-		},
-		{
-			ID: "seed_the_config_20240401",
-			Migrate: func(db *gorm.DB) error {
-				if !boilerplate.InLocal() {
-					log.Warn().Msg("skipping seeding the config table in non-local environment")
-					return nil
-				}
-
-				return boilerplate_testing.ExecutePostgresSql(db, `
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('MONTHLY_CAPPED_POINT_WELCOME_TIER', '10000', 'decimal', 'monthly capped point welcome tier', false, '2024-04-01 14:04:16.694834 +00:00', '2024-04-01 14:04:16.694834 +00:00', 'users', '04.01.24', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('MONTHLY_WITHDRAWAL_LIMIT_WELCOME_TIER', '1', 'integer', 'monthly withdrawal limit welcome tier', false, '2024-04-01 14:04:16.694834 +00:00', '2024-04-01 14:04:16.694834 +00:00', 'users', '04.01.24', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('MONTHLY_POINT_REQUIREMENT_WELCOME_TIER', '5000', 'decimal', 'monthly point requirement welcome tier', false, '2024-04-01 14:04:16.694834 +00:00', '2024-04-01 14:04:16.694834 +00:00', 'users', '04.01.24', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('MONTHLY_AD_WATCH_REQUIREMENT_WELCOME_TIER', '50', 'integer', 'monthly Ad watch requirement welcome tier', false, '2024-04-01 14:04:16.694834 +00:00', '2024-04-01 14:04:16.694834 +00:00', 'users', '04.01.24', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('WEEKLY_LOGIN_REQUIREMENT_WELCOME_TIER', '1', 'integer', 'weekly login requirement welcome tier', false, '2024-04-01 14:04:16.694834 +00:00', '2024-04-01 14:04:16.694834 +00:00', 'users', '04.01.24', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('WEEKLY_LOGIN_PERCENT_POINT_DEDUCTION_WELCOME_TIER', '0', 'integer', 'weekly login percent point deduction welcome tier', false, '2024-04-01 14:04:16.694834 +00:00', '2024-04-01 14:04:16.694834 +00:00', 'users', '04.01.24', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('WEEKLY_TIME_SPENT_REQUIREMENT_WELCOME_TIER', '3', 'integer', 'weekly time spent requirement welcome tier', false, '2024-04-01 14:04:16.694834 +00:00', '2024-04-01 14:04:16.694834 +00:00', 'users', '04.01.24', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('WEEKLY_TIME_SPENT_PERCENT_POINT_DEDUCTION_WELCOME_TIER', '0', 'integer', 'weekly time spent percent point deduction welcome tier', false, '2024-04-01 14:04:16.694834 +00:00', '2024-04-01 14:04:16.694834 +00:00', 'users', '04.01.24', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('MONTHLY_PERCENT_POINT_BEGINNERS_TIER', '10', 'integer', 'monthly percent point beginners tier', false, '2024-04-01 14:04:16.694834 +00:00', '2024-04-01 14:04:16.694834 +00:00', 'users', '04.01.24', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('MONTHLY_WITHDRAWAL_LIMIT_BEGINNERS_TIER', '1', 'integer', 'monthly withdrawal limit beginners tier', false, '2024-04-01 14:04:16.694834 +00:00', '2024-04-01 14:04:16.694834 +00:00', 'users', '04.01.24', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('MONTHLY_POINT_REQUIREMENT_BEGINNERS_TIER', '100000', 'decimal', 'monthly point requirement beginners tier', false, '2024-04-01 14:04:16.694834 +00:00', '2024-04-01 14:04:16.694834 +00:00', 'users', '04.01.24', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('MONTHLY_AD_WATCH_REQUIREMENT_BEGINNERS_TIER', '100', 'integer', 'monthly Ad watch requirement beginners tier', false, '2024-04-01 14:04:16.694834 +00:00', '2024-04-01 14:04:16.694834 +00:00', 'users', '04.01.24', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('WEEKLY_LOGIN_REQUIREMENT_BEGINNERS_TIER', '2', 'integer', 'weekly login requirement beginners tier', false, '2024-04-01 14:04:16.694834 +00:00', '2024-04-01 14:04:16.694834 +00:00', 'users', '04.01.24', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('WEEKLY_LOGIN_PERCENT_POINT_DEDUCTION_BEGINNERS_TIER', '5', 'integer', 'weekly login percent point deduction beginners tier', false, '2024-04-01 14:04:16.694834 +00:00', '2024-04-01 14:04:16.694834 +00:00', 'users', '04.01.24', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('WEEKLY_TIME_SPENT_REQUIREMENT_BEGINNERS_TIER', '5', 'integer', 'weekly time spent requirement beginners tier', false, '2024-04-01 14:04:16.694834 +00:00', '2024-04-01 14:04:16.694834 +00:00', 'users', '04.01.24', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('WEEKLY_TIME_SPENT_PERCENT_POINT_DEDUCTION_BEGINNERS_TIER', '5', 'integer', 'weekly time spent percent point deduction beginners tier', false, '2024-04-01 14:04:16.694834 +00:00', '2024-04-01 14:04:16.694834 +00:00', 'users', '04.01.24', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('MONTHLY_PERCENT_POINT_INTERMEDIARY_TIER', '12', 'integer', 'monthly percent point intermediary tier', false, '2024-04-01 14:04:16.694834 +00:00', '2024-04-01 14:04:16.694834 +00:00', 'users', '04.01.24', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('MONTHLY_WITHDRAWAL_LIMIT_INTERMEDIARY_TIER', '1', 'integer', 'monthly withdrawal limit intermediary tier', false, '2024-04-01 14:04:16.694834 +00:00', '2024-04-01 14:04:16.694834 +00:00', 'users', '04.01.24', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('MONTHLY_POINT_REQUIREMENT_INTERMEDIARY_TIER', '500000', 'decimal', 'monthly point requirement intermediary tier', false, '2024-04-01 14:04:16.694834 +00:00', '2024-04-01 14:04:16.694834 +00:00', 'users', '04.01.24', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('MONTHLY_AD_WATCH_REQUIREMENT_INTERMEDIARY_TIER', '200', 'integer', 'monthly Ad watch requirement intermediary tier', false, '2024-04-01 14:04:16.694834 +00:00', '2024-04-01 14:04:16.694834 +00:00', 'users', '04.01.24', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('WEEKLY_LOGIN_REQUIREMENT_INTERMEDIARY_TIER', '2', 'integer', 'weekly login requirement intermediary tier', false, '2024-04-01 14:04:16.694834 +00:00', '2024-04-01 14:04:16.694834 +00:00', 'users', '04.01.24', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('WEEKLY_LOGIN_PERCENT_POINT_DEDUCTION_INTERMEDIARY_TIER', '5', 'integer', 'weekly login percent point deduction intermediary tier', false, '2024-04-01 14:04:16.694834 +00:00', '2024-04-01 14:04:16.694834 +00:00', 'users', '04.01.24', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('WEEKLY_TIME_SPENT_REQUIREMENT_INTERMEDIARY_TIER', '6', 'integer', 'weekly time spent requirement intermediary tier', false, '2024-04-01 14:04:16.694834 +00:00', '2024-04-01 14:04:16.694834 +00:00', 'users', '04.01.24', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('WEEKLY_TIME_SPENT_PERCENT_POINT_DEDUCTION_INTERMEDIARY_TIER', '5', 'integer', 'weekly time spent percent point deduction intermediary tier', false, '2024-04-01 14:04:16.694834 +00:00', '2024-04-01 14:04:16.694834 +00:00', 'users', '04.01.24', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('MONTHLY_PERCENT_POINT_ADVANCED_TIER', '15', 'integer', 'monthly percent point advanced tier', false, '2024-04-01 14:04:16.694834 +00:00', '2024-04-01 14:04:16.694834 +00:00', 'users', '04.01.24', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('MONTHLY_WITHDRAWAL_LIMIT_ADVANCED_TIER', '2', 'integer', 'monthly withdrawal limit advanced tier', false, '2024-04-01 14:04:16.694834 +00:00', '2024-04-01 14:04:16.694834 +00:00', 'users', '04.01.24', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('MONTHLY_POINT_REQUIREMENT_ADVANCED_TIER', '2000000', 'decimal', 'monthly point requirement advanced tier', false, '2024-04-01 14:04:16.694834 +00:00', '2024-04-01 14:04:16.694834 +00:00', 'users', '04.01.24', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('MONTHLY_AD_WATCH_REQUIREMENT_ADVANCED_TIER', '500', 'integer', 'monthly Ad watch requirement advanced tier', false, '2024-04-01 14:04:16.694834 +00:00', '2024-04-01 14:04:16.694834 +00:00', 'users', '04.01.24', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('WEEKLY_LOGIN_REQUIREMENT_ADVANCED_TIER', '2', 'integer', 'weekly login requirement advanced tier', false, '2024-04-01 14:04:16.694834 +00:00', '2024-04-01 14:04:16.694834 +00:00', 'users', '04.01.24', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('WEEKLY_LOGIN_PERCENT_POINT_DEDUCTION_ADVANCED_TIER', '5', 'integer', 'weekly login percent point deduction advanced tier', false, '2024-04-01 14:04:16.694834 +00:00', '2024-04-01 14:04:16.694834 +00:00', 'users', '04.01.24', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('WEEKLY_TIME_SPENT_REQUIREMENT_ADVANCED_TIER', '7', 'integer', 'weekly time spent requirement advanced tier', false, '2024-04-01 14:04:16.694834 +00:00', '2024-04-01 14:04:16.694834 +00:00', 'users', '04.01.24', null) ON CONFLICT (key) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('WEEKLY_TIME_SPENT_PERCENT_POINT_DEDUCTION_ADVANCED_TIER', '5', 'integer', 'weekly time spent percent point deduction advanced tier', false, '2024-04-01 14:04:16.694834 +00:00', '2024-04-01 14:04:16.694834 +00:00', 'users', '04.01.24', null) ON CONFLICT (ke\y) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('MONTHLY_PERCENT_POINT_SUPERSTAR_TIER', '20', 'integer', 'monthly percent point superstar tier', false, '2024-04-01 14:04:16.694834 +00:00', '2024-04-01 14:04:16.694834 +00:00', 'users', '04.01.24', null) ON CONFLICT (ke\y) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('MONTHLY_WITHDRAWAL_LIMIT_SUPERSTAR_TIER', '2', 'integer', 'monthly withdrawal limit superstar tier', false, '2024-04-01 14:04:16.694834 +00:00', '2024-04-01 14:04:16.694834 +00:00', 'users', '04.01.24', null) ON CONFLICT (ke\y) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('MONTHLY_POINT_REQUIREMENT_SUPERSTAR_TIER', '5000000', 'decimal', 'monthly point requirement superstar tier', false, '2024-04-01 14:04:16.694834 +00:00', '2024-04-01 14:04:16.694834 +00:00', 'users', '04.01.24', null) ON CONFLICT (ke\y) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('MONTHLY_AD_WATCH_REQUIREMENT_SUPERSTAR_TIER', '1000', 'integer', 'monthly Ad watch requirement superstar tier', false, '2024-04-01 14:04:16.694834 +00:00', '2024-04-01 14:04:16.694834 +00:00', 'users', '04.01.24', null) ON CONFLICT (ke\y) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('WEEKLY_LOGIN_REQUIREMENT_SUPERSTAR_TIER', '2', 'integer', 'weekly login requirement superstar tier', false, '2024-04-01 14:04:16.694834 +00:00', '2024-04-01 14:04:16.694834 +00:00', 'users', '04.01.24', null) ON CONFLICT (ke\y) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('WEEKLY_LOGIN_PERCENT_POINT_DEDUCTION_SUPERSTAR_TIER', '5', 'integer', 'weekly login percent point deduction superstar tier', false, '2024-04-01 14:04:16.694834 +00:00', '2024-04-01 14:04:16.694834 +00:00', 'users', '04.01.24', null) ON CONFLICT (ke\y) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('WEEKLY_TIME_SPENT_REQUIREMENT_SUPERSTAR_TIER', '8', 'integer', 'weekly time spent requirement superstar tier', false, '2024-04-01 14:04:16.694834 +00:00', '2024-04-01 14:04:16.694834 +00:00', 'users', '04.01.24', null) ON CONFLICT (ke\y) DO NOTHING;
-INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('WEEKLY_TIME_SPENT_PERCENT_POINT_DEDUCTION_SUPERSTAR_TIER', '5', 'integer', 'weekly time spent percent point deduction superstar tier', false, '2024-04-01 14:04:16.694834 +00:00', '2024-04-01 14:04:16.694834 +00:00', 'users', '04.01.24', null) ON CONFLICT (ke\y) DO NOTHING;
-`)
-			},
-		},
-		{
-			ID: "seed_the_config_20240328",
-			Migrate: func(db *gorm.DB) error {
-				if !boilerplate.InLocal() {
-					log.Warn().Msg("skipping seeding the config table in non-local environment")
-					return nil
-				}
-
-				return boilerplate_testing.ExecutePostgresSql(db, `
-				INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('WITHDRAWAL_RATE', '1', 'decimal', 'withdrawal rate', false, '2024-03-20 07:31:16.696989 +00:00', '2024-03-20 07:31:16.696989 +00:00', 'tokenomics', '20.03.24', null) ON CONFLICT (key) DO NOTHING;`)
-			},
-		},
-		{
-			ID: "seed_the_config_20240408",
-			Migrate: func(db *gorm.DB) error {
-				if !boilerplate.InLocal() {
-					log.Warn().Msg("skipping seeding the config table in non-local environment")
-					return nil
-				}
-
-				return boilerplate_testing.ExecutePostgresSql(db, `
-				INSERT INTO configs (key, value, type, description, admin_only, created_at, updated_at, category, release_version, last_changed_by_id) VALUES ('COUNTRY_RATE_CONVERSION_ENABLED', 'false', 'bool', 'country rate conversion', false, '2024-04-08 07:31:16.696989 +00:00', '2024-04-08 07:31:16.696989 +00:00', 'tokenomics', '04.11.24', null) ON CONFLICT (ke\y) DO NOTHING;`)
-			},
-		},
-	}
-}
@@ -0,0 +1,194 @@
+package music
+
+import (
+	"net/http"
+
+	"github.com/digitalmonsters/music/pkg/creators/categories"
+	"github.com/digitalmonsters/music/pkg/creators/moods"
+	"github.com/digitalmonsters/music/pkg/creators/reject_reasons"
+	"github.com/digitalmonsters/music/pkg/database"
+)
+
+func listCategories(w http.ResponseWriter, r *http.Request) {
+	var req categories.PublicListRequest
+
+	if err := decodeRequest(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	resp, err := categories.PublicList(req, database.GetDbWithContext(database.DbTypeReadonly, r.Context()))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeResponse(w, resp)
+}
+
+func upsertCategories(w http.ResponseWriter, r *http.Request) {
+	var req categories.UpsertRequest
+
+	if err := decodeRequest(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	resp, err := categories.Upsert(req, database.GetDbWithContext(database.DbTypeMaster, r.Context()))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeResponse(w, resp)
+}
+
+func listCategoriesByAdmin(w http.ResponseWriter, r *http.Request) {
+	var req categories.ListRequest
+
+	if err := decodeRequest(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	resp, err := categories.AdminList(req, database.GetDbWithContext(database.DbTypeReadonly, r.Context()))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeResponse(w, resp)
+}
+
+func deleteCategories(w http.ResponseWriter, r *http.Request) {
+	var req categories.DeleteRequest
+
+	if err := decodeRequest(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := categories.Delete(req, database.GetDbWithContext(database.DbTypeMaster, r.Context())); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeResponse(w, nil)
+}
+
+func listMoods(w http.ResponseWriter, r *http.Request) {
+	var req moods.PublicListRequest
+
+	if err := decodeRequest(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	resp, err := moods.PublicList(req, database.GetDbWithContext(database.DbTypeReadonly, r.Context()))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeResponse(w, resp)
+}
+
+func upsertMoods(w http.ResponseWriter, r *http.Request) {
+	var req moods.UpsertRequest
+
+	if err := decodeRequest(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	resp, err := moods.Upsert(req, database.GetDbWithContext(database.DbTypeMaster, r.Context()))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeResponse(w, resp)
+}
+
+func listMoodsByAdmin(w http.ResponseWriter, r *http.Request) {
+	var req moods.ListRequest
+
+	if err := decodeRequest(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	resp, err := moods.AdminList(req, database.GetDbWithContext(database.DbTypeReadonly, r.Context()))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeResponse(w, resp)
+}
+
+func deleteMoods(w http.ResponseWriter, r *http.Request) {
+	var req moods.DeleteRequest
+
+	if err := decodeRequest(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := moods.Delete(req, database.GetDbWithContext(database.DbTypeMaster, r.Context())); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeResponse(w, nil)
+}
+
+func upsertRejectReasons(w http.ResponseWriter, r *http.Request) {
+	var req reject_reasons.UpsertRequest
+
+	if err := decodeRequest(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	resp, err := reject_reasons.Upsert(req, database.GetDbWithContext(database.DbTypeMaster, r.Context()))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeResponse(w, resp)
+}
+
+func listRejectReasons(w http.ResponseWriter, r *http.Request) {
+	var req reject_reasons.ListRequest
+
+	if err := decodeRequest(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	resp, err := reject_reasons.List(req, database.GetDbWithContext(database.DbTypeReadonly, r.Context()))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeResponse(w, resp)
+}
+
+func deleteRejectReasons(w http.ResponseWriter, r *http.Request) {
+	var req reject_reasons.DeleteRequest
+
+	if err := decodeRequest(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := reject_reasons.Delete(req, database.GetDbWithContext(database.DbTypeMaster, r.Context())); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeResponse(w, nil)
+}
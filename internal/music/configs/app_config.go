@@ -1,13 +1,20 @@
 package configs
 
 import (
+	"context"
 	"fmt"
 	"github.com/digitalmonsters/go-common/application"
 	"github.com/digitalmonsters/go-common/boilerplate"
 	"github.com/digitalmonsters/go-common/boilerplate_testing"
+	"github.com/digitalmonsters/go-common/ops"
+	"github.com/shopspring/decimal"
+	"sync"
 )
 
 var cfgService *application.Configurator[AppConfig]
+var cfgServiceOnce sync.Once
+var privateServer *ops.PrivateHttpServer
+var privateServerOnce sync.Once
 var mockAppConfigs = map[string]AppConfig{}
 
 func SetMockAppConfig(mock AppConfig) {
@@ -19,7 +26,40 @@ func GetAppConfig() AppConfig {
 		return boilerplate_testing.GetMockAppConfig(mockAppConfigs)
 	}
 
-	return cfgService.Values
+	return GetAppConfigurator().GetValues()
+}
+
+// GetAppConfigurator initializes app config service on first call. In ci and local environments mock values are used
+func GetAppConfigurator() *application.Configurator[AppConfig] {
+	cfgServiceOnce.Do(func() {
+		if boilerplate.GetCurrentEnvironment() == boilerplate.Ci || boilerplate.GetCurrentEnvironment() == boilerplate.Local {
+			cfgService = &application.Configurator[AppConfig]{Values: GetAppConfig()}
+			return
+		}
+
+		builder := application.NewConfigurator[AppConfig]().
+			WithRetriever(application.NewHttpRetriever(fmt.Sprintf("%s/internal/json", settings.Wrappers.Configurator.ApiUrl))).
+			WithMigrator(application.NewHttpMigrator(fmt.Sprintf("%s/internal/json/migrator", settings.Wrappers.Configurator.ApiUrl)), GetConfigsMigration()).
+			WithStatusProvider(GetPrivateHttpServer(), "configurator")
+
+		if len(settings.ConfigListener.Topic) > 0 {
+			builder = builder.WithKafkaListener(settings.ConfigListener, context.Background())
+		}
+
+		cfgService = builder.MustInit()
+		GetPrivateHttpServer().Ready()
+	})
+
+	return cfgService
+}
+
+// GetPrivateHttpServer starts private http server with health, metrics and status endpoints on PrivateHttpPort
+func GetPrivateHttpServer() *ops.PrivateHttpServer {
+	privateServerOnce.Do(func() {
+		privateServer = ops.NewPrivateHttpServer().StartAsync(settings.PrivateHttpPort)
+	})
+
+	return privateServer
 }
 
 type AppConfig struct {
@@ -36,6 +76,20 @@ type AppConfig struct {
 	MUSIC_FULL_VERSION_MAX_DURATION             int
 	MUSIC_FEATURE_FEED_IGNORE_IDS_ENABLED       bool
 	MUSIC_SHORT_VERSION_MIN_DURATION            int
+	MUSIC_FEED_CANDIDATES_LIMIT                 int
+	MUSIC_FEED_CATEGORY_AFFINITY_WEIGHT         int
+	MUSIC_FEED_MOOD_AFFINITY_WEIGHT             int
+	MUSIC_FEED_EXPLORATION_EVERY                int
+	MUSIC_FEED_NEW_CREATOR_DAYS                 int
+	MUSIC_USER_PLAYLISTS_MAX_COUNT              int
+	MUSIC_USER_PLAYLIST_MAX_SONGS               int
+	MUSIC_USER_PLAYLIST_FOLLOWS_MAX_COUNT       int
+	MUSIC_ROYALTY_CREATOR_SHARE_PERCENT         int
+	MUSIC_ROYALTY_POINTS_PER_VIDEO_USE          decimal.Decimal
+	MUSIC_ROYALTY_POINTS_PER_1000_SHORT_LISTENS decimal.Decimal
+	MUSIC_ROYALTY_POINTS_PER_1000_FULL_LISTENS  decimal.Decimal
+	MUSIC_ROYALTY_POINTS_PER_REACTION           decimal.Decimal
+	MUSIC_ROYALTY_MIN_PAYOUT_POINTS             decimal.Decimal
 }
 
 func GetConfigsMigration() map[string]application.MigrateConfigModel {
@@ -144,5 +198,117 @@ func GetConfigsMigration() map[string]application.MigrateConfigModel {
 			Category:       application.ConfigMusic,
 			ReleaseVersion: "05.05.2022",
 		},
+		"MUSIC_FEED_CANDIDATES_LIMIT": {
+			Key:            "MUSIC_FEED_CANDIDATES_LIMIT",
+			Value:          "300",
+			Type:           application.ConfigTypeInteger,
+			Description:    "Top scored songs taken for personal ranking of music feed",
+			Category:       application.ConfigMusic,
+			ReleaseVersion: "19.10.2026",
+		},
+		"MUSIC_FEED_CATEGORY_AFFINITY_WEIGHT": {
+			Key:            "MUSIC_FEED_CATEGORY_AFFINITY_WEIGHT",
+			Value:          "50",
+			Type:           application.ConfigTypeInteger,
+			Description:    "Music feed weight of user category affinity, global score weight is 100",
+			Category:       application.ConfigMusic,
+			ReleaseVersion: "19.10.2026",
+		},
+		"MUSIC_FEED_MOOD_AFFINITY_WEIGHT": {
+			Key:            "MUSIC_FEED_MOOD_AFFINITY_WEIGHT",
+			Value:          "30",
+			Type:           application.ConfigTypeInteger,
+			Description:    "Music feed weight of user mood affinity, global score weight is 100",
+			Category:       application.ConfigMusic,
+			ReleaseVersion: "19.10.2026",
+		},
+		"MUSIC_FEED_EXPLORATION_EVERY": {
+			Key:            "MUSIC_FEED_EXPLORATION_EVERY",
+			Value:          "5",
+			Type:           application.ConfigTypeInteger,
+			Description:    "Every n-th music feed slot is given to new creator, 0 disables exploration",
+			Category:       application.ConfigMusic,
+			ReleaseVersion: "19.10.2026",
+		},
+		"MUSIC_FEED_NEW_CREATOR_DAYS": {
+			Key:            "MUSIC_FEED_NEW_CREATOR_DAYS",
+			Value:          "30",
+			Type:           application.ConfigTypeInteger,
+			Description:    "Creator is new for music feed exploration during n days after first song",
+			Category:       application.ConfigMusic,
+			ReleaseVersion: "19.10.2026",
+		},
+		"MUSIC_USER_PLAYLISTS_MAX_COUNT": {
+			Key:            "MUSIC_USER_PLAYLISTS_MAX_COUNT",
+			Value:          "50",
+			Type:           application.ConfigTypeInteger,
+			Description:    "Max playlists created by user",
+			Category:       application.ConfigMusic,
+			ReleaseVersion: "19.10.2026",
+		},
+		"MUSIC_USER_PLAYLIST_MAX_SONGS": {
+			Key:            "MUSIC_USER_PLAYLIST_MAX_SONGS",
+			Value:          "500",
+			Type:           application.ConfigTypeInteger,
+			Description:    "Max songs in user playlist",
+			Category:       application.ConfigMusic,
+			ReleaseVersion: "19.10.2026",
+		},
+		"MUSIC_USER_PLAYLIST_FOLLOWS_MAX_COUNT": {
+			Key:            "MUSIC_USER_PLAYLIST_FOLLOWS_MAX_COUNT",
+			Value:          "500",
+			Type:           application.ConfigTypeInteger,
+			Description:    "Max playlists of other users followed by user",
+			Category:       application.ConfigMusic,
+			ReleaseVersion: "19.10.2026",
+		},
+		"MUSIC_ROYALTY_CREATOR_SHARE_PERCENT": {
+			Key:            "MUSIC_ROYALTY_CREATOR_SHARE_PERCENT",
+			Value:          "70",
+			Type:           application.ConfigTypeInteger,
+			Description:    "Royalty statement. Percent of points earned by creator song in tokenomics during the month",
+			Category:       application.ConfigMusic,
+			ReleaseVersion: "19.10.2026",
+		},
+		"MUSIC_ROYALTY_POINTS_PER_VIDEO_USE": {
+			Key:            "MUSIC_ROYALTY_POINTS_PER_VIDEO_USE",
+			Value:          "1",
+			Type:           application.ConfigTypeDecimal,
+			Description:    "Royalty statement. Points per new video using creator song",
+			Category:       application.ConfigMusic,
+			ReleaseVersion: "19.10.2026",
+		},
+		"MUSIC_ROYALTY_POINTS_PER_1000_SHORT_LISTENS": {
+			Key:            "MUSIC_ROYALTY_POINTS_PER_1000_SHORT_LISTENS",
+			Value:          "1",
+			Type:           application.ConfigTypeDecimal,
+			Description:    "Royalty statement. Points per 1000 counted short listens",
+			Category:       application.ConfigMusic,
+			ReleaseVersion: "19.10.2026",
+		},
+		"MUSIC_ROYALTY_POINTS_PER_1000_FULL_LISTENS": {
+			Key:            "MUSIC_ROYALTY_POINTS_PER_1000_FULL_LISTENS",
+			Value:          "5",
+			Type:           application.ConfigTypeDecimal,
+			Description:    "Royalty statement. Points per 1000 counted full listens",
+			Category:       application.ConfigMusic,
+			ReleaseVersion: "19.10.2026",
+		},
+		"MUSIC_ROYALTY_POINTS_PER_REACTION": {
+			Key:            "MUSIC_ROYALTY_POINTS_PER_REACTION",
+			Value:          "0.1",
+			Type:           application.ConfigTypeDecimal,
+			Description:    "Royalty statement. Points per new like, love, share or comment",
+			Category:       application.ConfigMusic,
+			ReleaseVersion: "19.10.2026",
+		},
+		"MUSIC_ROYALTY_MIN_PAYOUT_POINTS": {
+			Key:            "MUSIC_ROYALTY_MIN_PAYOUT_POINTS",
+			Value:          "10",
+			Type:           application.ConfigTypeDecimal,
+			Description:    "Royalty statement. Min statement total which can be paid out",
+			Category:       application.ConfigMusic,
+			ReleaseVersion: "19.10.2026",
+		},
 	}
 }
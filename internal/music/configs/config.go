@@ -8,6 +8,7 @@ import (
 	"github.com/RichardKnop/machinery/v1/config"
 	log2 "github.com/RichardKnop/machinery/v1/log"
 	"github.com/digitalmonsters/go-common/boilerplate"
+	"github.com/digitalmonsters/go-common/deeplink"
 	"github.com/rs/zerolog/log"
 	"os"
 )
@@ -26,6 +27,58 @@ type Settings struct {
 	Feed                   MusicFeedConfiguration               `json:"Feed"`
 	Redis                  RedisConfig                          `json:"Redis"`
 	Jobber                 JobberConfig                         `json:"Jobber"`
+	AudioProcessing        AudioProcessingConfig                `json:"AudioProcessing"`
+	Uploads                UploadsConfig                        `json:"Uploads"`
+	Listens                ListensConfig                        `json:"Listens"`
+	Search                 SearchConfig                         `json:"Search"`
+	Deeplink               deeplink.Config                      `json:"Deeplink"`
+	// ConfigListener receives config_upsert events of configurator, GroupId should be empty so every instance gets them
+	ConfigListener boilerplate.KafkaListenerConfiguration `json:"ConfigListener"`
+}
+
+type SearchConfig struct {
+	Backend        string            `json:"Backend"`        // postgres or meilisearch
+	ExternalWeight float64           `json:"ExternalWeight"` // weight of SoundStripe results in merged ranking, 0 disables them
+	MaxPageSize    int               `json:"MaxPageSize"`
+	Meilisearch    MeilisearchConfig `json:"Meilisearch"`
+}
+
+type MeilisearchConfig struct {
+	Host                   string `json:"Host"`
+	ApiKey                 string `json:"ApiKey"`
+	Index                  string `json:"Index"`
+	TimeoutSec             int    `json:"TimeoutSec"`
+	BatchSize              int    `json:"BatchSize"` // documents per request during reindex
+	ReindexIntervalMinutes int    `json:"ReindexIntervalMinutes"`
+}
+
+type ListensConfig struct {
+	ViewsTopic                 boilerplate.KafkaTopicConfig `json:"ViewsTopic"` // ViewEvent of counted creator song listens
+	DedupWindowMinutes         int                          `json:"DedupWindowMinutes"`
+	MinListenSeconds           int                          `json:"MinListenSeconds"`
+	MinListenPercent           int                          `json:"MinListenPercent"` // for versions shorter than MinListenSeconds
+	MaxListensPerHour          int                          `json:"MaxListensPerHour"`
+	BotUserAgents              []string                     `json:"BotUserAgents"` // case insensitive substrings
+	AggregationIntervalMinutes int                          `json:"AggregationIntervalMinutes"`
+}
+
+type UploadsConfig struct {
+	ChunkSizeMb            int `json:"ChunkSizeMb"` // size of every chunk except the last one, s3 requires at least 5MB
+	MaxFileSizeMb          int `json:"MaxFileSizeMb"`
+	SessionTtlHours        int `json:"SessionTtlHours"`
+	PresignedUrlTtlMinutes int `json:"PresignedUrlTtlMinutes"`
+}
+
+type AudioProcessingConfig struct {
+	FfmpegPath     string  `json:"FfmpegPath"`
+	FfprobePath    string  `json:"FfprobePath"`
+	Bitrate        string  `json:"Bitrate"`        // bitrate of transcoded mp3, e.g. 192k
+	SampleRate     int     `json:"SampleRate"`     // Hz
+	LoudnessTarget float64 `json:"LoudnessTarget"` // integrated loudness, LUFS
+	TruePeak       float64 `json:"TruePeak"`       // dBTP
+	WaveformPoints int     `json:"WaveformPoints"`
+	MaxFileSizeMb  int     `json:"MaxFileSizeMb"`
+	TimeoutSec     int     `json:"TimeoutSec"` // limit of a single ffmpeg run
 }
 
 type RedisConfig struct {
@@ -50,8 +103,9 @@ type NotifierConfig struct {
 }
 
 type CreatorsConfig struct {
-	MaxThresholdHours int              `json:"MaxThresholdHours"`
-	Listeners         CreatorListeners `json:"Listeners"`
+	MaxThresholdHours     int              `json:"MaxThresholdHours"`
+	SongMaxThresholdHours int              `json:"SongMaxThresholdHours"` // moderation sla of uploaded songs
+	Listeners             CreatorListeners `json:"Listeners"`
 }
 
 type CreatorListeners struct {
@@ -65,10 +119,19 @@ type CreatorListeners struct {
 }
 
 type SoundStripeConfig struct {
-	ApiUrl     string `json:"ApiUrl"`
-	ApiToken   string `json:"ApiToken"`
-	MaxWorkers int    `json:"MaxWorkers"`
-	MaxTimeout int    `json:"MaxTimeout"`
+	ApiUrl                  string `json:"ApiUrl"`
+	ApiToken                string `json:"ApiToken"`
+	MaxWorkers              int    `json:"MaxWorkers"`
+	MaxTimeout              int    `json:"MaxTimeout"`
+	SyncIntervalMinutes     int    `json:"SyncIntervalMinutes"` // catalog sync is disabled when 0
+	FullSyncIntervalHours   int    `json:"FullSyncIntervalHours"`
+	SyncPageSize            int    `json:"SyncPageSize"`
+	MaxRetries              int    `json:"MaxRetries"`  // retries of rate limited requests
+	RetryBaseMs             int    `json:"RetryBaseMs"` // first backoff when Retry-After is not set, doubled on every retry
+	MaxRetryWaitSec         int    `json:"MaxRetryWaitSec"`
+	FileUrlTtlMinutes       int    `json:"FileUrlTtlMinutes"`       // used when signed url has no expiration
+	FileUrlRefreshMinutes   int    `json:"FileUrlRefreshMinutes"`   // urls expiring sooner are refreshed
+	FileUrlRefreshBatchSize int    `json:"FileUrlRefreshBatchSize"` // library songs refreshed by one sync run
 }
 
 type CounterListener struct {
@@ -0,0 +1,188 @@
+package music
+
+import (
+	"net/http"
+
+	"github.com/digitalmonsters/go-common/router"
+	"github.com/digitalmonsters/music/pkg/creators/royalties"
+	"github.com/digitalmonsters/music/pkg/database"
+	"go.elastic.co/apm"
+)
+
+func listMyCreatorStatements(service *royalties.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req royalties.ListStatementsRequest
+
+		if err := decodeRequest(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		resp, err := service.ListMy(req, router.UserIdFromHttpRequest(r),
+			database.GetDbWithContext(database.DbTypeReadonly, r.Context()))
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		writeResponse(w, resp)
+	}
+}
+
+func getCreatorStatement(service *royalties.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req royalties.StatementRequest
+
+		if err := decodeRequest(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		resp, err := service.Get(req, router.UserIdFromHttpRequest(r),
+			database.GetDbWithContext(database.DbTypeReadonly, r.Context()))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		writeResponse(w, resp)
+	}
+}
+
+func requestCreatorStatementPayout(service *royalties.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req royalties.StatementRequest
+
+		if err := decodeRequest(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		resp, err := service.RequestPayout(req, router.UserIdFromHttpRequest(r),
+			database.GetDbWithContext(database.DbTypeMaster, r.Context()), r.Context())
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		writeResponse(w, resp)
+	}
+}
+
+func listCreatorStatementsByAdmin(service *royalties.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req royalties.AdminListStatementsRequest
+
+		if err := decodeRequest(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		resp, err := service.AdminList(req, database.GetDbWithContext(database.DbTypeReadonly, r.Context()))
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		writeResponse(w, resp)
+	}
+}
+
+func getCreatorStatementByAdmin(service *royalties.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req royalties.StatementRequest
+
+		if err := decodeRequest(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		resp, err := service.AdminGet(req, database.GetDbWithContext(database.DbTypeReadonly, r.Context()))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		writeResponse(w, resp)
+	}
+}
+
+func adjustCreatorStatement(service *royalties.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req royalties.AdjustStatementRequest
+
+		if err := decodeRequest(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		resp, err := service.Adjust(req, router.AdminIdFromHttpRequest(r),
+			database.GetDbWithContext(database.DbTypeMaster, r.Context()))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		writeResponse(w, resp)
+	}
+}
+
+func holdCreatorStatement(service *royalties.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req royalties.HoldStatementRequest
+
+		if err := decodeRequest(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		resp, err := service.Hold(req, router.AdminIdFromHttpRequest(r),
+			database.GetDbWithContext(database.DbTypeMaster, r.Context()))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		writeResponse(w, resp)
+	}
+}
+
+func releaseCreatorStatement(service *royalties.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req royalties.StatementRequest
+
+		if err := decodeRequest(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		resp, err := service.Release(req, router.AdminIdFromHttpRequest(r),
+			database.GetDbWithContext(database.DbTypeMaster, r.Context()))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		writeResponse(w, resp)
+	}
+}
+
+func generateCreatorStatements(service *royalties.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req royalties.GenerateStatementsRequest
+
+		if err := decodeRequest(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		generated, err := service.Generate(req.PeriodStart, database.GetDbWithContext(database.DbTypeMaster, r.Context()),
+			apm.TransactionFromContext(r.Context()))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		writeResponse(w, royalties.GenerateStatementsResponse{Generated: generated})
+	}
+}
@@ -0,0 +1,247 @@
+package music
+
+import (
+	"net/http"
+
+	"github.com/digitalmonsters/go-common/router"
+	"github.com/digitalmonsters/go-common/wrappers/content"
+	"github.com/digitalmonsters/go-common/wrappers/notification_handler"
+	"github.com/digitalmonsters/go-common/wrappers/user_go"
+	"github.com/digitalmonsters/music/pkg/creators"
+	"github.com/digitalmonsters/music/pkg/creators/moderation"
+	"github.com/digitalmonsters/music/pkg/database"
+)
+
+func becomeCreator(service *creators.Service, userGoWrapper user_go.IUserGoWrapper) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req creators.BecomeMusicCreatorRequest
+
+		if err := decodeRequest(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := service.BecomeMusicCreator(req, database.GetDbWithContext(database.DbTypeMaster, r.Context()),
+			executionDataFromRequest(r), userGoWrapper); err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		writeResponse(w, nil)
+	}
+}
+
+func checkCreatorRequestStatus(service *creators.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		resp, err := service.CheckRequestStatus(router.UserIdFromHttpRequest(r),
+			database.GetDbWithContext(database.DbTypeReadonly, r.Context()))
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		writeResponse(w, resp)
+	}
+}
+
+func uploadCreatorSong(service *creators.Service, contentWrapper content.IContentWrapper) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req creators.UploadNewSongRequest
+
+		if err := decodeRequest(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		resp, err := service.UploadNewSong(req, contentWrapper, database.GetDbWithContext(database.DbTypeMaster, r.Context()),
+			executionDataFromRequest(r))
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		writeResponse(w, resp)
+	}
+}
+
+func listMyCreatorSongs(service *creators.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req creators.MySongsListRequest
+
+		if err := decodeRequest(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		userId := router.UserIdFromHttpRequest(r)
+
+		resp, err := service.SongsList(creators.SongsListRequest{
+			UserId: userId,
+			Count:  req.Count,
+			Cursor: req.Cursor,
+		}, userId, database.GetDbWithContext(database.DbTypeReadonly, r.Context()), executionDataFromRequest(r))
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.GetError())
+			return
+		}
+
+		writeResponse(w, resp)
+	}
+}
+
+func listCreatorSongs(service *creators.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req creators.SongsListRequest
+
+		if err := decodeRequest(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		resp, err := service.SongsList(req, router.UserIdFromHttpRequest(r),
+			database.GetDbWithContext(database.DbTypeReadonly, r.Context()),
+			executionDataFromRequest(r))
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.GetError())
+			return
+		}
+
+		writeResponse(w, resp)
+	}
+}
+
+func listCreatorRequests(service *creators.Service, maxThresholdHours int, userGoWrapper user_go.IUserGoWrapper) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req creators.CreatorRequestsListRequest
+
+		if err := decodeRequest(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		resp, err := service.CreatorRequestsList(req, database.GetDbWithContext(database.DbTypeReadonly, r.Context()),
+			maxThresholdHours, r.Context(), userGoWrapper)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		writeResponse(w, resp)
+	}
+}
+
+func approveCreatorRequests(service *creators.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req creators.CreatorRequestApproveRequest
+
+		if err := decodeRequest(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		resp, err := service.CreatorRequestApprove(req, database.GetDbWithContext(database.DbTypeMaster, r.Context()))
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		writeResponse(w, resp)
+	}
+}
+
+func rejectCreatorRequests(service *creators.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req creators.CreatorRequestRejectRequest
+
+		if err := decodeRequest(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		resp, err := service.CreatorRequestReject(req, database.GetDbWithContext(database.DbTypeMaster, r.Context()))
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		writeResponse(w, resp)
+	}
+}
+
+func listModerationSongs(userGoWrapper user_go.IUserGoWrapper) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req moderation.ListRequest
+
+		if err := decodeRequest(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		resp, err := moderation.List(req, database.GetDbWithContext(database.DbTypeReadonly, r.Context()), userGoWrapper,
+			r.Context())
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.GetError())
+			return
+		}
+
+		writeResponse(w, resp)
+	}
+}
+
+func listModerationQueue(maxThresholdHours int, userGoWrapper user_go.IUserGoWrapper) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req moderation.QueueRequest
+
+		if err := decodeRequest(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		resp, err := moderation.Queue(req, database.GetDbWithContext(database.DbTypeReadonly, r.Context()),
+			maxThresholdHours, userGoWrapper, r.Context())
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.GetError())
+			return
+		}
+
+		writeResponse(w, resp)
+	}
+}
+
+func approveSong(notificationHandler notification_handler.INotificationHandlerWrapper) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req moderation.ApproveMusicRequest
+
+		if err := decodeRequest(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := moderation.ApproveMusic(req, database.GetDbWithContext(database.DbTypeMaster, r.Context()),
+			notificationHandler, r.Context()); err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		writeResponse(w, nil)
+	}
+}
+
+func rejectSong(notificationHandler notification_handler.INotificationHandlerWrapper) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req moderation.RejectMusicRequest
+
+		if err := decodeRequest(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := moderation.RejectMusic(req, database.GetDbWithContext(database.DbTypeMaster, r.Context()),
+			notificationHandler, r.Context()); err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		writeResponse(w, nil)
+	}
+}
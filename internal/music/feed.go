@@ -0,0 +1,34 @@
+package music
+
+import (
+	"net/http"
+
+	"github.com/digitalmonsters/go-common/router"
+	"github.com/digitalmonsters/music/pkg/database"
+	"github.com/digitalmonsters/music/pkg/feed"
+)
+
+type feedRequest struct {
+	Count      int     `json:"count"`
+	ContentIds []int64 `json:"content_ids"` // songs to show first, e.g. opened by a shared link
+}
+
+func getFeed(musicFeed *feed.Feed) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req feedRequest
+
+		if err := decodeRequest(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		resp, err := musicFeed.GetFeed(database.GetDbWithContext(database.DbTypeReadonly, r.Context()),
+			router.UserIdFromHttpRequest(r), req.ContentIds, req.Count, executionDataFromRequest(r))
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.GetError())
+			return
+		}
+
+		writeResponse(w, resp)
+	}
+}
@@ -0,0 +1,71 @@
+package music
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/digitalmonsters/go-common/router"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"go.elastic.co/apm"
+)
+
+type apiResponse struct {
+	Data    interface{} `json:"data"`
+	Success bool        `json:"success"`
+	Error   string      `json:"error,omitempty"`
+}
+
+func decodeRequest(r *http.Request, target interface{}) error {
+	if err := json.NewDecoder(r.Body).Decode(target); err != nil {
+		return errors.Wrap(err, "invalid request body")
+	}
+
+	return nil
+}
+
+func writeResponse(w http.ResponseWriter, data interface{}) {
+	writeJson(w, http.StatusOK, apiResponse{Data: data, Success: true})
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	if status >= http.StatusInternalServerError {
+		log.Err(err).Send()
+	}
+
+	writeJson(w, status, apiResponse{Success: false, Error: err.Error()})
+}
+
+func writeJson(w http.ResponseWriter, status int, resp apiResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.Err(err).Send()
+	}
+}
+
+// clientIpFromRequest returns ip of the client behind the proxy, first address of X-Forwarded-For
+func clientIpFromRequest(r *http.Request) string {
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		return strings.TrimSpace(strings.Split(forwarded, ",")[0])
+	}
+
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+
+	return r.RemoteAddr
+}
+
+// executionDataFromRequest builds execution data expected by service methods shared with rpc commands
+func executionDataFromRequest(r *http.Request) router.MethodExecutionData {
+	return router.MethodExecutionData{
+		ApmTransaction: apm.TransactionFromContext(r.Context()),
+		Context:        r.Context(),
+		UserId:         router.UserIdFromHttpRequest(r),
+		UserIp:         clientIpFromRequest(r),
+	}
+}
@@ -0,0 +1,75 @@
+package music
+
+import (
+	"net/http"
+
+	"github.com/digitalmonsters/go-common/router"
+	"github.com/digitalmonsters/music/pkg/database"
+	"github.com/digitalmonsters/music/pkg/listens"
+)
+
+func listenClientFromRequest(r *http.Request) listens.Client {
+	return listens.Client{
+		UserId:    router.UserIdFromHttpRequest(r),
+		Ip:        clientIpFromRequest(r),
+		UserAgent: r.UserAgent(),
+	}
+}
+
+func startListen(service *listens.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req listens.StartListenRequest
+
+		if err := decodeRequest(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		resp, err := service.Start(req, listenClientFromRequest(r), database.GetDbWithContext(database.DbTypeMaster, r.Context()))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		writeResponse(w, resp)
+	}
+}
+
+func progressListen(service *listens.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req listens.ProgressListenRequest
+
+		if err := decodeRequest(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		resp, err := service.Progress(req, listenClientFromRequest(r), database.GetDbWithContext(database.DbTypeMaster, r.Context()))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		writeResponse(w, resp)
+	}
+}
+
+func completeListen(service *listens.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req listens.ProgressListenRequest
+
+		if err := decodeRequest(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		resp, err := service.Complete(req, listenClientFromRequest(r),
+			database.GetDbWithContext(database.DbTypeMaster, r.Context()), r.Context())
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		writeResponse(w, resp)
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/digitalmonsters/music/pkg/feed/feed_converter"
 	"github.com/digitalmonsters/music/pkg/global"
 	"github.com/digitalmonsters/music/utils"
+	"github.com/lib/pq"
 	"github.com/pilagod/gorm-cursor-paginator/v2/paginator"
 	"github.com/pkg/errors"
 	"github.com/thoas/go-funk"
@@ -283,6 +284,8 @@ func (s *Service) CreatorRequestReject(req CreatorRequestRejectRequest, db *gorm
 	return creatorRequests, nil
 }
 
+// UploadNewSong creates a song in pending status or edits an existing one. Songs are shown to other users only after
+// moderation, so an edit of audio or cover sends the song back to review
 func (s *Service) UploadNewSong(req UploadNewSongRequest, contentWrapper content.IContentWrapper, db *gorm.DB, executionData router.MethodExecutionData) (*database.CreatorSong, error) {
 	tx := db.Begin()
 	defer tx.Rollback()
@@ -296,6 +299,81 @@ func (s *Service) UploadNewSong(req UploadNewSongRequest, contentWrapper content
 		return nil, errors.New("only approved creators can upload music")
 	}
 
+	var song *database.CreatorSong
+	var err error
+
+	job, err := s.lockProcessingJob(req, tx, executionData.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	if job != nil {
+		req.FullSongUrl = job.FullSongUrl
+		req.FullSongDuration = job.FullSongDuration
+		req.ShortSongUrl = job.ShortSongUrl
+		req.ShortSongDuration = job.ShortSongDuration
+	}
+
+	if req.Id.Valid {
+		song, err = s.editSong(req, tx, executionData)
+	} else {
+		song, err = s.createSong(req, tx, contentWrapper, executionData)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if job != nil {
+		if err := tx.Model(job).Update("song_id", song.Id).Error; err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		if job.WaveformUrl != song.WaveformUrl {
+			if err := tx.Model(song).Update("waveform_url", job.WaveformUrl).Error; err != nil {
+				return nil, errors.WithStack(err)
+			}
+
+			song.WaveformUrl = job.WaveformUrl
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return song, nil
+}
+
+// lockProcessingJob returns audio processing job referenced by request. Row is locked until song is linked, so
+// processing either finishes before and its urls are copied here, or finishes after and updates the linked song
+func (s *Service) lockProcessingJob(req UploadNewSongRequest, tx *gorm.DB, userId int64) (*database.SongProcessingJob, error) {
+	if !req.ProcessingJobId.Valid {
+		return nil, nil
+	}
+
+	var job database.SongProcessingJob
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? and user_id = ?", req.ProcessingJobId.Int64, userId).Find(&job).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if job.Id == 0 {
+		return nil, errors.New("processing job not found")
+	}
+
+	if job.Status == database.ProcessingStatusFailed {
+		return nil, errors.New("audio processing failed, please upload the file again")
+	}
+
+	if job.SongId.Valid && (!req.Id.Valid || job.SongId.Int64 != req.Id.Int64) {
+		return nil, errors.New("processing job is already used by another song")
+	}
+
+	return &job, nil
+}
+
+func (s *Service) createSong(req UploadNewSongRequest, tx *gorm.DB, contentWrapper content.IContentWrapper, executionData router.MethodExecutionData) (*database.CreatorSong, error) {
 	newContent := <-contentWrapper.InsertMusicContent(content.MusicContentRequest{
 		ContentType: eventsourcing.ContentTypeMusic,
 		Duration:    int(req.FullSongDuration),
@@ -307,11 +385,13 @@ func (s *Service) UploadNewSong(req UploadNewSongRequest, contentWrapper content
 		return nil, newContent.Error.ToError()
 	}
 
+	now := time.Now()
+
 	song := database.CreatorSong{
 		Id:                newContent.Response.Id,
 		UserId:            executionData.UserId,
 		Name:              req.Name,
-		Status:            music.CreatorSongStatusPublished,
+		Status:            music.CreatorSongStatusPending,
 		LyricAuthor:       req.LyricAuthor,
 		MusicAuthor:       req.MusicAuthor,
 		FullSongDuration:  req.FullSongDuration,
@@ -322,22 +402,64 @@ func (s *Service) UploadNewSong(req UploadNewSongRequest, contentWrapper content
 		ShortSongUrl:      req.ShortSongUrl,
 		ImageUrl:          req.ImageUrl,
 		Hashtags:          req.Hashtags,
-		CreatedAt:         time.Now(),
+		SubmittedAt:       null.TimeFrom(now),
+		CreatedAt:         now,
 	}
 
-	if req.Id.Valid {
-		song.Id = req.Id.Int64
-		song.UpdatedAt = null.TimeFrom(time.Now())
+	if err := tx.Create(&song).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return &song, nil
+}
+
+func (s *Service) editSong(req UploadNewSongRequest, tx *gorm.DB, executionData router.MethodExecutionData) (*database.CreatorSong, error) {
+	var song database.CreatorSong
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? and user_id = ?", req.Id.Int64, executionData.UserId).Find(&song).Error; err != nil {
+		return nil, errors.WithStack(err)
 	}
 
-	if err := tx.Clauses(clause.OnConflict{
-		Columns:   []clause.Column{{Name: "id"}},
-		DoUpdates: clause.AssignmentColumns([]string{"name", "lyric_author", "music_author", "category_id", "hashtags"}),
-	}).Create(&song).Error; err != nil {
+	if song.Id == 0 {
+		return nil, errors.New("song not found")
+	}
+
+	now := time.Now()
+
+	updateMap := map[string]interface{}{
+		"name":         req.Name,
+		"lyric_author": req.LyricAuthor,
+		"music_author": req.MusicAuthor,
+		"category_id":  req.CategoryId,
+		"mood_id":      req.MoodId,
+		"hashtags":     pq.StringArray(req.Hashtags),
+		"updated_at":   null.TimeFrom(now),
+	}
+
+	mediaChanged := song.FullSongUrl != req.FullSongUrl || song.ShortSongUrl != req.ShortSongUrl ||
+		song.ImageUrl != req.ImageUrl
+
+	if mediaChanged {
+		updateMap["full_song_url"] = req.FullSongUrl
+		updateMap["full_song_duration"] = req.FullSongDuration
+		updateMap["short_song_url"] = req.ShortSongUrl
+		updateMap["short_song_duration"] = req.ShortSongDuration
+		updateMap["image_url"] = req.ImageUrl
+		updateMap["waveform_url"] = ""
+
+		if song.Status != music.CreatorSongStatusPending {
+			updateMap["status"] = music.CreatorSongStatusPending
+			updateMap["reject_reason"] = null.Int{}
+			updateMap["submitted_at"] = null.TimeFrom(now)
+			updateMap["reviewed_at"] = null.Time{}
+		}
+	}
+
+	if err := tx.Model(&song).Updates(updateMap).Error; err != nil {
 		return nil, errors.WithStack(err)
 	}
 
-	if err := tx.Commit().Error; err != nil {
+	if err := tx.First(&song, song.Id).Error; err != nil {
 		return nil, errors.WithStack(err)
 	}
 
@@ -371,7 +493,7 @@ func (s *Service) SongsList(req SongsListRequest, currentUserId int64, db *gorm.
 	q := db.Where("user_id = ?", req.UserId)
 
 	if req.UserId != currentUserId {
-		q = q.Where("status != ?", music.CreatorSongStatusRejected)
+		q = q.Where("status in ?", database.CreatorSongVisibleStatuses)
 	}
 
 	paginatorRules := []paginator.Rule{
@@ -396,6 +396,110 @@ func TestUploadNewSong(t *testing.T) {
 	assert.Greater(t, song.Id, int64(0))
 	assert.Equal(t, song.UserId, userId)
 	assert.Equal(t, song.Name, "test_song")
+	assert.Equal(t, music.CreatorSongStatusPending, song.Status)
+	assert.True(t, song.SubmittedAt.Valid)
+
+	if err = gormDb.Model(&song).Update("status", music.CreatorSongStatusApproved).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	editReq := UploadNewSongRequest{
+		Id:           null.IntFrom(song.Id),
+		Name:         "renamed_song",
+		MusicAuthor:  "test music author",
+		CategoryId:   category.Id,
+		MoodId:       mood.Id,
+		FullSongUrl:  "https://full-url.com",
+		ShortSongUrl: "https://short-url.com",
+		ImageUrl:     "https://image-url.com",
+	}
+
+	edited, err := service.UploadNewSong(editReq, contentWrapper, gormDb, executionData)
+	assert.Nil(t, err)
+	assert.Equal(t, "renamed_song", edited.Name)
+	assert.Equal(t, music.CreatorSongStatusApproved, edited.Status)
+
+	editReq.ImageUrl = "https://new-image-url.com"
+
+	edited, err = service.UploadNewSong(editReq, contentWrapper, gormDb, executionData)
+	assert.Nil(t, err)
+	assert.Equal(t, "https://new-image-url.com", edited.ImageUrl)
+	assert.Equal(t, music.CreatorSongStatusPending, edited.Status)
+}
+
+func TestUploadNewSongWithProcessingJob(t *testing.T) {
+	if err := boilerplate_testing.FlushPostgresTables(config.MasterDb, []string{"" +
+		"public.creators", "public.categories", "public.creator_songs", "public.moods",
+		"public.song_processing_jobs"}, nil, t); err != nil {
+		t.Fatal(err)
+	}
+
+	contentWrapper := &content.ContentWrapperMock{}
+
+	contentWrapper.InsertMusicContentFn = func(content1 content.MusicContentRequest, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[content.SimpleContent] {
+		ch := make(chan wrappers.GenericResponseChan[content.SimpleContent], 2)
+		ch <- wrappers.GenericResponseChan[content.SimpleContent]{
+			Response: content.SimpleContent{
+				Id: 2,
+			},
+		}
+		close(ch)
+
+		return ch
+	}
+
+	userId := int64(112)
+	addCreator(t, userId, user_go.CreatorStatusApproved)
+	category := addCategory(t, "test_category")
+	mood := addMood(t, "test_mood")
+
+	job := database.SongProcessingJob{
+		UserId:            userId,
+		Status:            database.ProcessingStatusCompleted,
+		SourcePath:        "creator/source/112/test.wav",
+		FullSongUrl:       "https://full-processed.com",
+		FullSongDuration:  120,
+		ShortSongUrl:      "https://short-processed.com",
+		ShortSongDuration: 30,
+		WaveformUrl:       "https://waveform.com",
+	}
+
+	if err := gormDb.Create(&job).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	executionData := router.MethodExecutionData{
+		Context: context.TODO(),
+		UserId:  userId,
+	}
+
+	song, err := service.UploadNewSong(UploadNewSongRequest{
+		Name:            "processed_song",
+		MusicAuthor:     "test music author",
+		CategoryId:      category.Id,
+		MoodId:          mood.Id,
+		ImageUrl:        "https://image-url.com",
+		ProcessingJobId: null.IntFrom(job.Id),
+	}, contentWrapper, gormDb, executionData)
+	assert.Nil(t, err)
+	assert.Equal(t, "https://full-processed.com", song.FullSongUrl)
+	assert.Equal(t, 120.0, song.FullSongDuration)
+	assert.Equal(t, "https://short-processed.com", song.ShortSongUrl)
+	assert.Equal(t, "https://waveform.com", song.WaveformUrl)
+
+	if err = gormDb.First(&job, job.Id).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, song.Id, job.SongId.Int64)
+
+	_, err = service.UploadNewSong(UploadNewSongRequest{
+		Name:            "another_song",
+		CategoryId:      category.Id,
+		MoodId:          mood.Id,
+		ProcessingJobId: null.IntFrom(job.Id),
+	}, contentWrapper, gormDb, executionData)
+	assert.NotNil(t, err)
 }
 
 func TestService_SongsList(t *testing.T) {
@@ -3,17 +3,30 @@ package moderation
 import (
 	"context"
 	"fmt"
+	"github.com/digitalmonsters/go-common/apm_helper"
 	"github.com/digitalmonsters/go-common/error_codes"
 	"github.com/digitalmonsters/go-common/wrappers/music"
+	"github.com/digitalmonsters/go-common/wrappers/notification_handler"
 	"github.com/digitalmonsters/go-common/wrappers/user_go"
 	"github.com/digitalmonsters/music/pkg/database"
 	"github.com/pkg/errors"
 	"gopkg.in/guregu/null.v4"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
+	"time"
 )
 
-func RejectMusic(req RejectMusicRequest, db *gorm.DB) error {
+const (
+	TemplateSongApproved = "music_song_approved"
+	TemplateSongRejected = "music_song_rejected"
+)
+
+// songProcessingQuery matches songs with audio which is still being transcoded, they can not be reviewed yet
+const songProcessingQuery = "exists (select 1 from song_processing_jobs j where j.song_id = creator_songs.id and j.status in ?)"
+
+var unfinishedProcessingStatuses = []database.ProcessingStatus{database.ProcessingStatusPending, database.ProcessingStatusProcessing}
+
+func RejectMusic(req RejectMusicRequest, db *gorm.DB, notificationHandler notification_handler.INotificationHandlerWrapper, ctx context.Context) error {
 	if req.SongId == 0 {
 		return errors.New("wrong song_id")
 	}
@@ -30,9 +43,23 @@ func RejectMusic(req RejectMusicRequest, db *gorm.DB) error {
 		return errors.WithStack(err)
 	}
 
+	if song.Status == music.CreatorSongStatusRejected {
+		return errors.New("song is already rejected")
+	}
+
+	var reason database.CreatorRejectReasons
+	if err := tx.Where("id = ? and type = ?", req.RejectReason, database.ReasonTypeCreatorSong).Find(&reason).Error; err != nil {
+		return errors.WithStack(err)
+	}
+
+	if reason.Id == 0 {
+		return errors.New("reject reason not found")
+	}
+
 	updateMap := map[string]interface{}{
 		"status":        music.CreatorSongStatusRejected,
 		"reject_reason": null.IntFrom(req.RejectReason),
+		"reviewed_at":   null.TimeFrom(time.Now()),
 	}
 
 	if err := tx.Model(&song).Updates(updateMap).Error; err != nil {
@@ -43,16 +70,15 @@ func RejectMusic(req RejectMusicRequest, db *gorm.DB) error {
 		return errors.WithStack(err)
 	}
 
-	/*
-		if contentNotifier != nil {
-			contentNotifier.Enqueue(content, eventsourcing.ChangeEventTypeUpdated, "rejected")
-		}
-	*/
+	notifyCreator(notificationHandler, TemplateSongRejected, song, map[string]string{
+		"song_name":     song.Name,
+		"reject_reason": reason.Reason,
+	}, ctx)
 
 	return nil
 }
 
-func ApproveMusic(req ApproveMusicRequest, db *gorm.DB) error {
+func ApproveMusic(req ApproveMusicRequest, db *gorm.DB, notificationHandler notification_handler.INotificationHandlerWrapper, ctx context.Context) error {
 	tx := db.Begin()
 	defer tx.Rollback()
 
@@ -61,8 +87,23 @@ func ApproveMusic(req ApproveMusicRequest, db *gorm.DB) error {
 		return errors.WithStack(err)
 	}
 
+	if song.Status == music.CreatorSongStatusApproved {
+		return errors.New("song is already approved")
+	}
+
+	var processingCount int64
+	if err := tx.Model(&database.CreatorSong{}).Where("id = ?", song.Id).
+		Where(songProcessingQuery, unfinishedProcessingStatuses).Count(&processingCount).Error; err != nil {
+		return errors.WithStack(err)
+	}
+
+	if processingCount > 0 {
+		return errors.New("song audio is still processing")
+	}
+
 	song.Status = music.CreatorSongStatusApproved
 	song.RejectReason = null.Int{}
+	song.ReviewedAt = null.TimeFrom(time.Now())
 
 	if err := tx.Save(&song).Error; err != nil {
 		return errors.WithStack(err)
@@ -72,9 +113,26 @@ func ApproveMusic(req ApproveMusicRequest, db *gorm.DB) error {
 		return errors.WithStack(err)
 	}
 
+	notifyCreator(notificationHandler, TemplateSongApproved, song, map[string]string{
+		"song_name": song.Name,
+	}, ctx)
+
 	return nil
 }
 
+// notifyCreator sends moderation result to creator. Song status is already saved, so failed notification is only logged
+func notifyCreator(notificationHandler notification_handler.INotificationHandlerWrapper, templateName string,
+	song database.CreatorSong, renderingVars map[string]string, ctx context.Context) {
+	if notificationHandler == nil {
+		return
+	}
+
+	if err := (<-notificationHandler.EnqueueNotificationWithTemplate(templateName, song.UserId, renderingVars,
+		map[string]interface{}{"song_id": song.Id}, ctx)).Error; err != nil {
+		apm_helper.LogError(err, ctx)
+	}
+}
+
 func List(req ListRequest, db *gorm.DB, userGoWrapper user_go.IUserGoWrapper, ctx context.Context) (*ListResponse, *error_codes.ErrorWithCode) {
 	var records []database.CreatorSong
 	query := db.Model(records).Preload("Category").Preload("Mood")
@@ -94,7 +152,7 @@ func List(req ListRequest, db *gorm.DB, userGoWrapper user_go.IUserGoWrapper, ct
 	}
 
 	if req.MoodId.Valid {
-		query = query.Where("mood_id = ?", req.CategoryId.Int64)
+		query = query.Where("mood_id = ?", req.MoodId.Int64)
 	}
 
 	if len(req.Status) > 0 {
@@ -110,6 +168,54 @@ func List(req ListRequest, db *gorm.DB, userGoWrapper user_go.IUserGoWrapper, ct
 		return nil, error_codes.NewErrorWithCodeRef(err, error_codes.GenericServerError)
 	}
 
+	listItems, err := toListItems(records, 0, userGoWrapper, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ListResponse{
+		Items:      listItems,
+		TotalCount: totalCount,
+	}, nil
+}
+
+// Queue returns songs waiting for review, the longest waiting first
+func Queue(req QueueRequest, db *gorm.DB, maxThreshold int, userGoWrapper user_go.IUserGoWrapper, ctx context.Context) (*ListResponse, *error_codes.ErrorWithCode) {
+	var records []database.CreatorSong
+	query := db.Model(records).Preload("Category").Preload("Mood").
+		Where("status = ?", music.CreatorSongStatusPending).
+		Where("not "+songProcessingQuery, unfinishedProcessingStatuses)
+
+	if req.UserId.Valid {
+		query = query.Where("user_id = ?", req.UserId.Int64)
+	}
+
+	if req.MaxThresholdExceeded {
+		query = query.Where("submitted_at <= NOW() - INTERVAL ?", gorm.Expr(fmt.Sprintf("'%v HOURS'", maxThreshold)))
+	}
+
+	var totalCount int64
+	if err := query.Count(&totalCount).Error; err != nil {
+		return nil, error_codes.NewErrorWithCodeRef(err, error_codes.GenericServerError)
+	}
+
+	if err := query.Limit(req.Limit).Offset(req.Offset).Order("submitted_at asc, id asc").Find(&records).Error; err != nil {
+		return nil, error_codes.NewErrorWithCodeRef(err, error_codes.GenericServerError)
+	}
+
+	listItems, err := toListItems(records, maxThreshold, userGoWrapper, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ListResponse{
+		Items:      listItems,
+		TotalCount: totalCount,
+	}, nil
+}
+
+// toListItems converts songs to list items. Sla is not tracked when maxThreshold is zero
+func toListItems(records []database.CreatorSong, maxThreshold int, userGoWrapper user_go.IUserGoWrapper, ctx context.Context) ([]listItem, *error_codes.ErrorWithCode) {
 	var userIds []int64
 	for _, song := range records {
 		userIds = append(userIds, song.UserId)
@@ -136,6 +242,12 @@ func List(req ListRequest, db *gorm.DB, userGoWrapper user_go.IUserGoWrapper, ct
 			ShortSongDuration: song.ShortSongDuration,
 			ImageUrl:          song.ImageUrl,
 			UserId:            song.UserId,
+			SubmittedAt:       song.SubmittedAt,
+			ReviewedAt:        song.ReviewedAt,
+		}
+
+		if maxThreshold > 0 && song.Status == music.CreatorSongStatusPending && song.SubmittedAt.Valid {
+			item.SlaExpired = time.Now().After(song.SubmittedAt.Time.Add(time.Hour * time.Duration(maxThreshold)))
 		}
 
 		if song.Category != nil {
@@ -153,8 +265,5 @@ func List(req ListRequest, db *gorm.DB, userGoWrapper user_go.IUserGoWrapper, ct
 		listItems = append(listItems, item)
 	}
 
-	return &ListResponse{
-		Items:      listItems,
-		TotalCount: totalCount,
-	}, nil
+	return listItems, nil
 }
@@ -6,13 +6,16 @@ import (
 	"github.com/digitalmonsters/go-common/boilerplate_testing"
 	"github.com/digitalmonsters/go-common/wrappers"
 	"github.com/digitalmonsters/go-common/wrappers/music"
+	"github.com/digitalmonsters/go-common/wrappers/notification_handler"
 	"github.com/digitalmonsters/go-common/wrappers/user_go"
 	"github.com/digitalmonsters/music/configs"
 	"github.com/digitalmonsters/music/pkg/database"
 	"github.com/stretchr/testify/assert"
+	"gopkg.in/guregu/null.v4"
 	"gorm.io/gorm"
 	"os"
 	"testing"
+	"time"
 )
 
 var config configs.Settings
@@ -111,10 +114,24 @@ func TestRejectMusic(t *testing.T) {
 		t.Fatal(err)
 	}
 
+	var sentTemplates []string
+	notificationHandler := &notification_handler.NotificationHandlerWrapperMock{
+		EnqueueNotificationWithTemplateFn: func(templateName string, userId int64, renderingVars map[string]string,
+			customData map[string]interface{}, ctx context.Context) chan notification_handler.EnqueueMessageResult {
+			sentTemplates = append(sentTemplates, templateName)
+
+			ch := make(chan notification_handler.EnqueueMessageResult, 2)
+			ch <- notification_handler.EnqueueMessageResult{}
+			close(ch)
+
+			return ch
+		},
+	}
+
 	err := RejectMusic(RejectMusicRequest{
 		SongId:       song.Id,
 		RejectReason: rejectReasons[0].Id,
-	}, gormDb)
+	}, gormDb, notificationHandler, context.Background())
 
 	assert.Nil(t, err)
 
@@ -129,7 +146,7 @@ func TestRejectMusic(t *testing.T) {
 
 	err = ApproveMusic(ApproveMusicRequest{
 		SongId: songToCheck.Id,
-	}, gormDb)
+	}, gormDb, notificationHandler, context.Background())
 
 	assert.Nil(t, err)
 
@@ -139,6 +156,8 @@ func TestRejectMusic(t *testing.T) {
 
 	assert.Equal(t, songToCheck.Status, music.CreatorSongStatusApproved)
 	assert.False(t, songToCheck.RejectReason.Valid)
+	assert.True(t, songToCheck.ReviewedAt.Valid)
+	assert.Equal(t, []string{TemplateSongRejected, TemplateSongApproved}, sentTemplates)
 }
 
 func TestList(t *testing.T) {
@@ -176,3 +195,64 @@ func TestList(t *testing.T) {
 
 	assert.Equal(t, len(resp.Items), 10)
 }
+
+func TestQueue(t *testing.T) {
+	if err := boilerplate_testing.FlushPostgresTables(config.MasterDb, []string{
+		"public.creator_songs",
+		"public.categories",
+		"public.moods",
+	}, nil, t); err != nil {
+		t.Fatal(err)
+	}
+
+	mood := addMood(t, "test_mood")
+	category := addCategory(t, "test_category")
+
+	songs := []database.CreatorSong{
+		{
+			UserId:      1,
+			Name:        "recent",
+			Status:      music.CreatorSongStatusPending,
+			CategoryId:  category.Id,
+			MoodId:      mood.Id,
+			SubmittedAt: null.TimeFrom(time.Now().Add(-1 * time.Hour)),
+		},
+		{
+			UserId:      1,
+			Name:        "overdue",
+			Status:      music.CreatorSongStatusPending,
+			CategoryId:  category.Id,
+			MoodId:      mood.Id,
+			SubmittedAt: null.TimeFrom(time.Now().Add(-30 * time.Hour)),
+		},
+		{
+			UserId:     1,
+			Name:       "approved",
+			Status:     music.CreatorSongStatusApproved,
+			CategoryId: category.Id,
+			MoodId:     mood.Id,
+		},
+	}
+
+	if err := gormDb.Create(&songs).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := Queue(QueueRequest{Limit: 20}, gormDb, 24, userGoWrapper, context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, int64(2), resp.TotalCount)
+	assert.Equal(t, "overdue", resp.Items[0].SongName)
+	assert.True(t, resp.Items[0].SlaExpired)
+	assert.False(t, resp.Items[1].SlaExpired)
+
+	resp, err = Queue(QueueRequest{Limit: 20, MaxThresholdExceeded: true}, gormDb, 24, userGoWrapper, context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, 1, len(resp.Items))
+	assert.Equal(t, "overdue", resp.Items[0].SongName)
+}
@@ -24,6 +24,13 @@ type ListRequest struct {
 	Offset     int                       `json:"offset"`
 }
 
+type QueueRequest struct {
+	UserId               null.Int `json:"user_id"`
+	MaxThresholdExceeded bool     `json:"max_threshold_exceeded"`
+	Limit                int      `json:"limit"`
+	Offset               int      `json:"offset"`
+}
+
 type ListResponse struct {
 	Items      []listItem `json:"items"`
 	TotalCount int64      `json:"total_count"`
@@ -42,6 +49,9 @@ type listItem struct {
 	ShortSongUrl      string                  `json:"short_song_url"`
 	ShortSongDuration float64                 `json:"short_song_duration"`
 	ImageUrl          string                  `json:"image_url"`
+	SubmittedAt       null.Time               `json:"submitted_at"`
+	ReviewedAt        null.Time               `json:"reviewed_at"`
+	SlaExpired        bool                    `json:"sla_expired"`
 
 	UserId   int64  `json:"user_id"`
 	Username string `json:"username"`
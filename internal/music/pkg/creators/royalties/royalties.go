@@ -0,0 +1,665 @@
+package royalties
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/RichardKnop/machinery/v1"
+	"github.com/RichardKnop/machinery/v1/tasks"
+	"github.com/digitalmonsters/go-common/apm_helper"
+	"github.com/digitalmonsters/go-common/application"
+	"github.com/digitalmonsters/go-common/boilerplate"
+	"github.com/digitalmonsters/go-common/eventsourcing"
+	"github.com/digitalmonsters/go-common/wrappers/go_tokenomics"
+	"github.com/digitalmonsters/music/configs"
+	"github.com/digitalmonsters/music/pkg/database"
+	"github.com/digitalmonsters/music/pkg/feed/feed_converter"
+	"github.com/digitalmonsters/music/utils"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"github.com/samber/lo"
+	"github.com/shopspring/decimal"
+	"go.elastic.co/apm"
+	"gopkg.in/guregu/null.v4"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const generateTaskName = "music:royalties:generate"
+
+const defaultLimit = 20
+const maxLimit = 100
+
+var thousand = decimal.NewFromInt(1000)
+var hundred = decimal.NewFromInt(100)
+
+// Service builds monthly royalty statements of music creators and requests their payouts in tokenomics. Royalty
+// points of a song are calculated from its month activity by the revenue share formula of app config
+type Service struct {
+	feedConverter       *feed_converter.Service
+	goTokenomicsWrapper go_tokenomics.IGoTokenomicsWrapper
+	appConfig           *application.Configurator[configs.AppConfig]
+}
+
+func NewService(jobber *machinery.Server, feedConverter *feed_converter.Service,
+	goTokenomicsWrapper go_tokenomics.IGoTokenomicsWrapper, appConfig *application.Configurator[configs.AppConfig]) *Service {
+	s := &Service{
+		feedConverter:       feedConverter,
+		goTokenomicsWrapper: goTokenomicsWrapper,
+		appConfig:           appConfig,
+	}
+
+	if boilerplate.GetCurrentEnvironment() != boilerplate.Ci {
+		if err := s.registerGenerateTask(jobber); err != nil {
+			log.Fatal().Err(err).Msg("[Music] can not register royalty statements task")
+		}
+	}
+
+	return s
+}
+
+// Generate creates statements of the month containing periodStart for creators who do not have one yet. Listens are
+// counted by completion time, other counters have no event history, so they are taken at generation time as a difference
+// with the previous statement of the song. That is why only the previous month can be generated, an older month would
+// get activity of the months after it. Creators without activity get no statement
+func (s *Service) Generate(periodStart time.Time, db *gorm.DB, apmTransaction *apm.Transaction) (int, error) {
+	periodStart = monthStart(periodStart)
+	periodEnd := periodStart.AddDate(0, 1, 0)
+	now := time.Now()
+
+	if periodEnd.After(now) {
+		return 0, errors.New("period is not finished yet")
+	}
+
+	if periodEnd.AddDate(0, 1, 0).Before(now) {
+		return 0, errors.New("period is too old, counters at its end are not known")
+	}
+
+	var userIds []int64
+	if err := db.Unscoped().Model(&database.CreatorSong{}).
+		Where("created_at < ? and (deleted_at is null or deleted_at >= ?)", periodEnd, periodStart).
+		Distinct("user_id").Order("user_id").Pluck("user_id", &userIds).Error; err != nil {
+		return 0, errors.WithStack(err)
+	}
+
+	formula := s.formula()
+	generated := 0
+
+	var lastErr error
+
+	for _, userId := range userIds {
+		ok, err := s.generateForUser(userId, periodStart, periodEnd, formula, db, apmTransaction)
+		if err != nil {
+			lastErr = errors.Wrap(err, fmt.Sprintf("can not generate statement of user %v", userId))
+			log.Error().Err(lastErr).Send()
+
+			continue
+		}
+
+		if ok {
+			generated++
+		}
+	}
+
+	return generated, lastErr
+}
+
+func (s *Service) generateForUser(userId int64, periodStart time.Time, periodEnd time.Time,
+	formula database.RoyaltyFormula, db *gorm.DB, apmTransaction *apm.Transaction) (bool, error) {
+	// snapshots of a later statement are already taken, so an earlier month can not be generated after it
+	var existing int64
+	if err := db.Model(&database.CreatorStatement{}).
+		Where("user_id = ? and period_start >= ?", userId, periodStart).Count(&existing).Error; err != nil {
+		return false, errors.WithStack(err)
+	}
+
+	if existing > 0 {
+		return false, nil
+	}
+
+	var songs []database.CreatorSong
+	if err := db.Unscoped().
+		Where("user_id = ? and created_at < ? and (deleted_at is null or deleted_at >= ?)", userId, periodEnd, periodStart).
+		Order("id").Find(&songs).Error; err != nil {
+		return false, errors.WithStack(err)
+	}
+
+	songIds := lo.Map(songs, func(item database.CreatorSong, _ int) int64 { return item.Id })
+
+	listens, err := periodListens(songIds, periodStart, periodEnd, db)
+	if err != nil {
+		return false, err
+	}
+
+	previous, err := previousSnapshots(songIds, periodStart, db)
+	if err != nil {
+		return false, err
+	}
+
+	pointsEarned, err := s.feedConverter.GetPointsEarned(songIds, apmTransaction)
+	if err != nil {
+		return false, err
+	}
+
+	statement := database.CreatorStatement{
+		UserId:      userId,
+		PeriodStart: periodStart,
+		PeriodEnd:   periodEnd,
+		Status:      database.StatementStatusReady,
+		Formula:     formula,
+	}
+
+	var lines []database.CreatorStatementSong
+
+	for _, song := range songs {
+		line := statementSong(song, previous[song.Id], listens[song.Id], pointsEarned[song.Id], formula)
+		if !hasActivity(line) {
+			continue
+		}
+
+		lines = append(lines, line)
+
+		statement.SongsCount++
+		statement.UsedInVideo += line.UsedInVideo
+		statement.ShortListens += line.ShortListens
+		statement.FullListens += line.FullListens
+		statement.Likes += line.Likes
+		statement.Loves += line.Loves
+		statement.Shares += line.Shares
+		statement.Comments += line.Comments
+		statement.PointsEarned = statement.PointsEarned.Add(line.PointsEarned)
+		statement.RoyaltyPoints = statement.RoyaltyPoints.Add(line.RoyaltyPoints)
+	}
+
+	if len(lines) == 0 {
+		return false, nil
+	}
+
+	statement.TotalPoints = statement.RoyaltyPoints
+
+	tx := db.Begin()
+	defer tx.Rollback()
+
+	result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&statement)
+	if result.Error != nil {
+		return false, errors.WithStack(result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+
+	for i := range lines {
+		lines[i].StatementId = statement.Id
+	}
+
+	if err := tx.Create(&lines).Error; err != nil {
+		return false, errors.WithStack(err)
+	}
+
+	if err := tx.Create(&database.CreatorStatementEvent{
+		StatementId: statement.Id,
+		Type:        database.StatementEventTypeGenerated,
+		Amount:      statement.RoyaltyPoints,
+	}).Error; err != nil {
+		return false, errors.WithStack(err)
+	}
+
+	for songId, points := range pointsEarned {
+		if err := tx.Model(&database.CreatorSong{}).Where("id = ?", songId).
+			Update("points_earned", points).Error; err != nil {
+			return false, errors.WithStack(err)
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return false, errors.WithStack(err)
+	}
+
+	return true, nil
+}
+
+func (s *Service) ListMy(req ListStatementsRequest, userId int64, db *gorm.DB) (*StatementsListResponse, error) {
+	return s.list(db.Where("user_id = ?", userId), req.Limit, req.Offset)
+}
+
+func (s *Service) Get(req StatementRequest, userId int64, db *gorm.DB) (*StatementResponse, error) {
+	var statement database.CreatorStatement
+	if err := db.Where("id = ? and user_id = ?", req.StatementId, userId).Find(&statement).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if statement.Id == 0 {
+		return nil, errors.New("statement not found")
+	}
+
+	return s.toResponse(statement, false, db)
+}
+
+// RequestPayout sends statement total to tokenomics. Statement id is the idempotency key of the payout, failed
+// request is logged and can be repeated
+func (s *Service) RequestPayout(req StatementRequest, userId int64, db *gorm.DB, ctx context.Context) (*database.CreatorStatement, error) {
+	tx := db.Begin()
+	defer tx.Rollback()
+
+	var statement database.CreatorStatement
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? and user_id = ?", req.StatementId, userId).Find(&statement).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if statement.Id == 0 {
+		return nil, errors.New("statement not found")
+	}
+
+	switch statement.Status {
+	case database.StatementStatusPayoutRequested:
+		return &statement, nil
+	case database.StatementStatusOnHold:
+		return nil, errors.New("statement is on hold")
+	}
+
+	minPayout := s.appConfig.GetValues().MUSIC_ROYALTY_MIN_PAYOUT_POINTS
+	if !statement.TotalPoints.IsPositive() || statement.TotalPoints.LessThan(minPayout) {
+		return nil, errors.New(fmt.Sprintf("min payout is %v points", minPayout))
+	}
+
+	resp := <-s.goTokenomicsWrapper.RequestMusicRoyaltyPayout(go_tokenomics.MusicRoyaltyPayoutRequest{
+		UserId:      statement.UserId,
+		StatementId: statement.Id,
+		Amount:      statement.TotalPoints,
+		PeriodStart: statement.PeriodStart,
+		PeriodEnd:   statement.PeriodEnd,
+	}, ctx, true)
+
+	if resp.Error != nil {
+		payoutErr := resp.Error.ToError()
+
+		if err := tx.Create(&database.CreatorStatementEvent{
+			StatementId: statement.Id,
+			Type:        database.StatementEventTypePayoutFailed,
+			Amount:      statement.TotalPoints,
+			Comment:     payoutErr.Error(),
+		}).Error; err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		if err := tx.Commit().Error; err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		return nil, errors.Wrap(payoutErr, "payout request failed")
+	}
+
+	now := time.Now().UTC()
+
+	statement.Status = database.StatementStatusPayoutRequested
+	statement.PayoutId = null.StringFrom(resp.Response.PayoutId)
+	statement.PayoutRequestedAt = null.TimeFrom(now)
+	statement.UpdatedAt = now
+
+	if err := tx.Model(&statement).Updates(map[string]interface{}{
+		"status":              statement.Status,
+		"payout_id":           statement.PayoutId,
+		"payout_requested_at": statement.PayoutRequestedAt,
+		"updated_at":          statement.UpdatedAt,
+	}).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if err := tx.Create(&database.CreatorStatementEvent{
+		StatementId: statement.Id,
+		Type:        database.StatementEventTypePayoutRequested,
+		Amount:      statement.TotalPoints,
+		Comment:     resp.Response.PayoutId,
+	}).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return &statement, nil
+}
+
+func (s *Service) AdminList(req AdminListStatementsRequest, db *gorm.DB) (*StatementsListResponse, error) {
+	query := db
+
+	if req.UserId.Valid {
+		query = query.Where("user_id = ?", req.UserId.Int64)
+	}
+
+	if req.PeriodStart.Valid {
+		query = query.Where("period_start = ?", monthStart(req.PeriodStart.Time))
+	}
+
+	if len(req.Statuses) > 0 {
+		query = query.Where("status in ?", req.Statuses)
+	}
+
+	return s.list(query, req.Limit, req.Offset)
+}
+
+func (s *Service) AdminGet(req StatementRequest, db *gorm.DB) (*StatementResponse, error) {
+	var statement database.CreatorStatement
+	if err := db.Where("id = ?", req.StatementId).Find(&statement).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if statement.Id == 0 {
+		return nil, errors.New("statement not found")
+	}
+
+	return s.toResponse(statement, true, db)
+}
+
+// Adjust changes statement total by admin, statement with requested payout can not be adjusted
+func (s *Service) Adjust(req AdjustStatementRequest, adminId int64, db *gorm.DB) (*database.CreatorStatement, error) {
+	if req.Amount.IsZero() {
+		return nil, errors.New("amount is required")
+	}
+
+	reason, err := validateReason(req.Reason)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.update(req.StatementId, db, func(statement *database.CreatorStatement) (*database.CreatorStatementEvent, error) {
+		if statement.Status == database.StatementStatusPayoutRequested {
+			return nil, errors.New("payout of statement is already requested")
+		}
+
+		statement.Adjustment = statement.Adjustment.Add(req.Amount)
+		statement.TotalPoints = statement.RoyaltyPoints.Add(statement.Adjustment)
+
+		return &database.CreatorStatementEvent{
+			Type:    database.StatementEventTypeAdjusted,
+			AdminId: null.IntFrom(adminId),
+			Amount:  req.Amount,
+			Comment: reason,
+		}, nil
+	})
+}
+
+// Hold blocks payout of statement until it is released
+func (s *Service) Hold(req HoldStatementRequest, adminId int64, db *gorm.DB) (*database.CreatorStatement, error) {
+	reason, err := validateReason(req.Reason)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.update(req.StatementId, db, func(statement *database.CreatorStatement) (*database.CreatorStatementEvent, error) {
+		if statement.Status != database.StatementStatusReady {
+			return nil, errors.New("only ready statement can be held")
+		}
+
+		statement.Status = database.StatementStatusOnHold
+		statement.HoldReason = null.StringFrom(reason)
+
+		return &database.CreatorStatementEvent{
+			Type:    database.StatementEventTypeHeld,
+			AdminId: null.IntFrom(adminId),
+			Comment: reason,
+		}, nil
+	})
+}
+
+func (s *Service) Release(req StatementRequest, adminId int64, db *gorm.DB) (*database.CreatorStatement, error) {
+	return s.update(req.StatementId, db, func(statement *database.CreatorStatement) (*database.CreatorStatementEvent, error) {
+		if statement.Status != database.StatementStatusOnHold {
+			return nil, errors.New("statement is not on hold")
+		}
+
+		statement.Status = database.StatementStatusReady
+		statement.HoldReason = null.String{}
+
+		return &database.CreatorStatementEvent{
+			Type:    database.StatementEventTypeReleased,
+			AdminId: null.IntFrom(adminId),
+		}, nil
+	})
+}
+
+// update changes locked statement by fn and saves the event returned by fn to the audit log
+func (s *Service) update(statementId int64, db *gorm.DB,
+	fn func(statement *database.CreatorStatement) (*database.CreatorStatementEvent, error)) (*database.CreatorStatement, error) {
+	tx := db.Begin()
+	defer tx.Rollback()
+
+	var statement database.CreatorStatement
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", statementId).Find(&statement).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if statement.Id == 0 {
+		return nil, errors.New("statement not found")
+	}
+
+	event, err := fn(&statement)
+	if err != nil {
+		return nil, err
+	}
+
+	statement.UpdatedAt = time.Now().UTC()
+
+	if err := tx.Model(&statement).Updates(map[string]interface{}{
+		"status":       statement.Status,
+		"adjustment":   statement.Adjustment,
+		"total_points": statement.TotalPoints,
+		"hold_reason":  statement.HoldReason,
+		"updated_at":   statement.UpdatedAt,
+	}).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	event.StatementId = statement.Id
+
+	if err := tx.Create(event).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return &statement, nil
+}
+
+func (s *Service) list(query *gorm.DB, limit int, offset int) (*StatementsListResponse, error) {
+	if limit <= 0 || limit > maxLimit {
+		limit = defaultLimit
+	}
+
+	query = query.Model(&database.CreatorStatement{})
+
+	var totalCount int64
+	if err := query.Count(&totalCount).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	var statements []database.CreatorStatement
+	if err := query.Order("period_start desc, id desc").Limit(limit).Offset(offset).Find(&statements).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return &StatementsListResponse{
+		Items:      statements,
+		TotalCount: totalCount,
+	}, nil
+}
+
+func (s *Service) toResponse(statement database.CreatorStatement, withEvents bool, db *gorm.DB) (*StatementResponse, error) {
+	resp := StatementResponse{
+		CreatorStatement: statement,
+	}
+
+	if err := db.Where("statement_id = ?", statement.Id).Order("royalty_points desc, song_id").
+		Find(&resp.Songs).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if withEvents {
+		if err := db.Where("statement_id = ?", statement.Id).Order("id").Find(&resp.Events).Error; err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+
+	return &resp, nil
+}
+
+func (s *Service) formula() database.RoyaltyFormula {
+	cfg := s.appConfig.GetValues()
+
+	return database.RoyaltyFormula{
+		CreatorSharePercent:       cfg.MUSIC_ROYALTY_CREATOR_SHARE_PERCENT,
+		PointsPerVideoUse:         cfg.MUSIC_ROYALTY_POINTS_PER_VIDEO_USE,
+		PointsPer1000ShortListens: cfg.MUSIC_ROYALTY_POINTS_PER_1000_SHORT_LISTENS,
+		PointsPer1000FullListens:  cfg.MUSIC_ROYALTY_POINTS_PER_1000_FULL_LISTENS,
+		PointsPerReaction:         cfg.MUSIC_ROYALTY_POINTS_PER_REACTION,
+	}
+}
+
+func (s *Service) registerGenerateTask(jobber *machinery.Server) error {
+	if err := jobber.RegisterTask(generateTaskName, func() error {
+		var apmTransaction = apm_helper.StartNewApmTransaction(generateTaskName, "task", nil, nil)
+		defer apmTransaction.End()
+
+		_, err := s.Generate(monthStart(time.Now()).AddDate(0, -1, 0), database.GetDb(database.DbTypeMaster),
+			apmTransaction)
+
+		return err
+	}); err != nil {
+		return err
+	}
+
+	// previous month is generated daily, so a missed run is caught up, creators having the statement are skipped
+	return utils.RegisterPeriodicTask(jobber, "0 3 * * *", generateTaskName, []tasks.Arg{}, true)
+}
+
+type listenCounts struct {
+	Short int
+	Full  int
+}
+
+// periodListens counts listens of creator songs counted during the period
+func periodListens(songIds []int64, periodStart time.Time, periodEnd time.Time, db *gorm.DB) (map[int64]listenCounts, error) {
+	result := map[int64]listenCounts{}
+
+	if len(songIds) == 0 {
+		return result, nil
+	}
+
+	var rows []struct {
+		SongId     int64
+		ListenType eventsourcing.ListenType
+		Count      int
+	}
+
+	if err := db.Model(&database.SongListen{}).Select("song_id, listen_type, count(*) as count").
+		Where("song_kind = ? and counted and song_id in ?", database.ListenSongKindCreator, songIds).
+		Where("completed_at >= ? and completed_at < ?", periodStart, periodEnd).
+		Group("song_id, listen_type").Scan(&rows).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	for _, row := range rows {
+		counts := result[row.SongId]
+
+		if row.ListenType == eventsourcing.ListenTypeShort {
+			counts.Short += row.Count
+		} else {
+			counts.Full += row.Count
+		}
+
+		result[row.SongId] = counts
+	}
+
+	return result, nil
+}
+
+// previousSnapshots returns the last statement line of every song before the period
+func previousSnapshots(songIds []int64, periodStart time.Time, db *gorm.DB) (map[int64]database.CreatorStatementSong, error) {
+	if len(songIds) == 0 {
+		return map[int64]database.CreatorStatementSong{}, nil
+	}
+
+	var lines []database.CreatorStatementSong
+	if err := db.Raw(`select distinct on (ss.song_id) ss.*
+					  from creator_statement_songs ss
+							   join creator_statements s on s.id = ss.statement_id
+					  where ss.song_id in ? and s.period_start < ?
+					  order by ss.song_id, s.period_start desc`, songIds, periodStart).Scan(&lines).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return lo.KeyBy(lines, func(item database.CreatorStatementSong) int64 { return item.SongId }), nil
+}
+
+// statementSong calculates song line of statement. Snapshots never decrease, so a reaction removed and added again
+// is counted once
+func statementSong(song database.CreatorSong, previous database.CreatorStatementSong, listens listenCounts,
+	pointsEarned decimal.Decimal, formula database.RoyaltyFormula) database.CreatorStatementSong {
+	line := database.CreatorStatementSong{
+		SongId:            song.Id,
+		SongName:          song.Name,
+		ShortListens:      listens.Short,
+		FullListens:       listens.Full,
+		TotalUsedInVideo:  max(previous.TotalUsedInVideo, song.UsedInVideo),
+		TotalLikes:        max(previous.TotalLikes, song.Likes),
+		TotalLoves:        max(previous.TotalLoves, song.Loves),
+		TotalShares:       max(previous.TotalShares, song.Shares),
+		TotalComments:     max(previous.TotalComments, song.Comments),
+		TotalPointsEarned: decimal.Max(previous.TotalPointsEarned, pointsEarned),
+	}
+
+	line.UsedInVideo = line.TotalUsedInVideo - previous.TotalUsedInVideo
+	line.Likes = line.TotalLikes - previous.TotalLikes
+	line.Loves = line.TotalLoves - previous.TotalLoves
+	line.Shares = line.TotalShares - previous.TotalShares
+	line.Comments = line.TotalComments - previous.TotalComments
+	line.PointsEarned = line.TotalPointsEarned.Sub(previous.TotalPointsEarned)
+	line.RoyaltyPoints = royaltyPoints(line, formula)
+
+	return line
+}
+
+// royaltyPoints is the revenue share formula: creator share of earned points plus points per use in video, listens
+// and reactions
+func royaltyPoints(line database.CreatorStatementSong, formula database.RoyaltyFormula) decimal.Decimal {
+	reactions := line.Likes + line.Loves + line.Shares + line.Comments
+
+	return line.PointsEarned.Mul(decimal.NewFromInt(int64(formula.CreatorSharePercent))).Div(hundred).
+		Add(formula.PointsPerVideoUse.Mul(decimal.NewFromInt(int64(line.UsedInVideo)))).
+		Add(formula.PointsPer1000ShortListens.Mul(decimal.NewFromInt(int64(line.ShortListens))).Div(thousand)).
+		Add(formula.PointsPer1000FullListens.Mul(decimal.NewFromInt(int64(line.FullListens))).Div(thousand)).
+		Add(formula.PointsPerReaction.Mul(decimal.NewFromInt(int64(reactions)))).
+		Round(2)
+}
+
+func hasActivity(line database.CreatorStatementSong) bool {
+	return line.UsedInVideo > 0 || line.ShortListens > 0 || line.FullListens > 0 || line.Likes > 0 || line.Loves > 0 ||
+		line.Shares > 0 || line.Comments > 0 || line.PointsEarned.IsPositive()
+}
+
+func validateReason(reason string) (string, error) {
+	reason = strings.TrimSpace(reason)
+
+	if len(reason) == 0 {
+		return "", errors.New("reason is required")
+	}
+
+	if len([]rune(reason)) > maxReasonLength {
+		return "", errors.New(fmt.Sprintf("reason is longer than %v characters", maxReasonLength))
+	}
+
+	return reason, nil
+}
+
+func monthStart(t time.Time) time.Time {
+	t = t.UTC()
+
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
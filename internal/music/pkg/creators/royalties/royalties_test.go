@@ -0,0 +1,371 @@
+package royalties
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/digitalmonsters/go-common/application"
+	"github.com/digitalmonsters/go-common/boilerplate_testing"
+	"github.com/digitalmonsters/go-common/eventsourcing"
+	"github.com/digitalmonsters/go-common/wrappers"
+	"github.com/digitalmonsters/go-common/wrappers/go_tokenomics"
+	"github.com/digitalmonsters/go-common/wrappers/music"
+	"github.com/digitalmonsters/music/configs"
+	"github.com/digitalmonsters/music/pkg/database"
+	"github.com/digitalmonsters/music/pkg/feed/feed_converter"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"go.elastic.co/apm"
+	"gopkg.in/guregu/null.v4"
+	"gorm.io/gorm"
+)
+
+var config configs.Settings
+var gormDb *gorm.DB
+var service *Service
+var earnings map[int64]decimal.Decimal
+var payoutRequests []go_tokenomics.MusicRoyaltyPayoutRequest
+
+func TestMain(m *testing.M) {
+	config = configs.GetConfig()
+	gormDb = database.GetDb(database.DbTypeMaster)
+
+	goTokenomicsWrapper := &go_tokenomics.GoTokenomicsWrapperMock{}
+
+	goTokenomicsWrapper.GetContentEarningsTotalByContentIdsFn = func(contentIds []int64, apmTransaction *apm.Transaction, forceLog bool) chan go_tokenomics.GetContentEarningsTotalByContentIdsResponseChan {
+		ch := make(chan go_tokenomics.GetContentEarningsTotalByContentIdsResponseChan, 2)
+		items := map[int64]decimal.Decimal{}
+
+		for _, contentId := range contentIds {
+			if points, ok := earnings[contentId]; ok {
+				items[contentId] = points
+			}
+		}
+
+		ch <- go_tokenomics.GetContentEarningsTotalByContentIdsResponseChan{
+			Error: nil,
+			Items: items,
+		}
+		close(ch)
+
+		return ch
+	}
+
+	goTokenomicsWrapper.RequestMusicRoyaltyPayoutFn = func(req go_tokenomics.MusicRoyaltyPayoutRequest, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[go_tokenomics.MusicRoyaltyPayoutResponse] {
+		ch := make(chan wrappers.GenericResponseChan[go_tokenomics.MusicRoyaltyPayoutResponse], 2)
+		payoutRequests = append(payoutRequests, req)
+
+		ch <- wrappers.GenericResponseChan[go_tokenomics.MusicRoyaltyPayoutResponse]{
+			Response: go_tokenomics.MusicRoyaltyPayoutResponse{
+				PayoutId: "payout-1",
+				Amount:   req.Amount,
+			},
+		}
+		close(ch)
+
+		return ch
+	}
+
+	service = NewService(nil, feed_converter.NewFeedConverter(nil, nil, nil, goTokenomicsWrapper, context.TODO()),
+		goTokenomicsWrapper, &application.Configurator[configs.AppConfig]{
+			Values: configs.AppConfig{
+				MUSIC_ROYALTY_CREATOR_SHARE_PERCENT:         50,
+				MUSIC_ROYALTY_POINTS_PER_VIDEO_USE:          decimal.NewFromInt(2),
+				MUSIC_ROYALTY_POINTS_PER_1000_SHORT_LISTENS: decimal.NewFromInt(1000),
+				MUSIC_ROYALTY_POINTS_PER_1000_FULL_LISTENS:  decimal.NewFromInt(2000),
+				MUSIC_ROYALTY_POINTS_PER_REACTION:           decimal.RequireFromString("0.5"),
+				MUSIC_ROYALTY_MIN_PAYOUT_POINTS:             decimal.NewFromInt(10),
+			},
+		})
+
+	os.Exit(m.Run())
+}
+
+func flush(t *testing.T) {
+	if err := boilerplate_testing.FlushPostgresTables(config.MasterDb, []string{"public.creator_statements",
+		"public.creator_statement_songs", "public.creator_statement_events", "public.song_listens",
+		"public.creator_songs", "public.categories", "public.moods"}, nil, t); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func addSong(t *testing.T, song database.CreatorSong) database.CreatorSong {
+	category := database.Category{Name: "test_category"}
+	if err := gormDb.Create(&category).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	mood := database.Mood{Name: "test_mood"}
+	if err := gormDb.Create(&mood).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	song.CategoryId = category.Id
+	song.MoodId = mood.Id
+
+	if err := gormDb.Create(&song).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	return song
+}
+
+func addListen(t *testing.T, songId int64, listenType eventsourcing.ListenType, counted bool, completedAt time.Time) {
+	if err := gormDb.Create(&database.SongListen{
+		UserId:      2,
+		SongId:      songId,
+		SongKind:    database.ListenSongKindCreator,
+		ListenType:  listenType,
+		Status:      database.ListenStatusCompleted,
+		Counted:     counted,
+		StartedAt:   completedAt.Add(-time.Minute),
+		UpdatedAt:   completedAt,
+		CompletedAt: null.TimeFrom(completedAt),
+	}).Error; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestService_Generate(t *testing.T) {
+	flush(t)
+
+	// counters are taken at generation time, so only the previous month can be generated
+	period := monthStart(time.Now()).AddDate(0, -1, 0)
+	firstPeriod := period
+
+	song := addSong(t, database.CreatorSong{
+		UserId:      1,
+		Name:        "song",
+		Status:      music.CreatorSongStatusApproved,
+		UsedInVideo: 3,
+		Likes:       10,
+		Loves:       2,
+		CreatedAt:   firstPeriod.Add(-time.Hour),
+	})
+
+	// song without activity gets no statement line
+	addSong(t, database.CreatorSong{
+		UserId:    1,
+		Name:      "silent",
+		Status:    music.CreatorSongStatusApproved,
+		CreatedAt: firstPeriod.Add(-time.Hour),
+	})
+
+	addListen(t, song.Id, eventsourcing.ListenTypeShort, true, firstPeriod.Add(time.Hour))
+	addListen(t, song.Id, eventsourcing.ListenTypeFull, true, firstPeriod.Add(2*time.Hour))
+	addListen(t, song.Id, eventsourcing.ListenTypeFull, true, firstPeriod.Add(3*time.Hour))
+	addListen(t, song.Id, eventsourcing.ListenTypeFull, false, firstPeriod.Add(4*time.Hour))
+	addListen(t, song.Id, eventsourcing.ListenTypeFull, true, firstPeriod.Add(-time.Minute))
+
+	earnings = map[int64]decimal.Decimal{song.Id: decimal.NewFromInt(100)}
+
+	generated, err := service.Generate(firstPeriod.Add(10*24*time.Hour), gormDb, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, generated)
+
+	statements, err := service.ListMy(ListStatementsRequest{}, 1, gormDb)
+	assert.Nil(t, err)
+
+	if !assert.Len(t, statements.Items, 1) {
+		return
+	}
+
+	first := statements.Items[0]
+	assert.True(t, first.PeriodStart.Equal(firstPeriod))
+	assert.Equal(t, 1, first.SongsCount)
+	assert.Equal(t, 3, first.UsedInVideo)
+	assert.Equal(t, 1, first.ShortListens)
+	assert.Equal(t, 2, first.FullListens)
+	assert.Equal(t, 50, first.Formula.CreatorSharePercent)
+	// 100 * 50% + 3 uses * 2 + 1 short + 2 full * 2 + 12 reactions * 0.5
+	assert.Equal(t, "67", first.RoyaltyPoints.String())
+	assert.Equal(t, "67", first.TotalPoints.String())
+
+	var updatedSong database.CreatorSong
+	assert.Nil(t, gormDb.Where("id = ?", song.Id).Find(&updatedSong).Error)
+	assert.Equal(t, "100", updatedSong.PointsEarned.String())
+
+	// already generated month is skipped
+	generated, err = service.Generate(firstPeriod, gormDb, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, generated)
+
+	_, err = service.Generate(period.AddDate(0, -1, 0), gormDb, nil)
+	assert.NotNil(t, err)
+
+	_, err = service.Generate(period.AddDate(0, 1, 0), gormDb, nil)
+	assert.NotNil(t, err)
+
+	// the first statement and its listens are moved a month back, so the previous month is generated once more
+	firstPeriod = period.AddDate(0, -1, 0)
+	secondPeriod := period
+
+	assert.Nil(t, gormDb.Model(&database.CreatorStatement{}).Where("id = ?", first.Id).
+		Updates(map[string]interface{}{"period_start": firstPeriod, "period_end": secondPeriod}).Error)
+	assert.Nil(t, gormDb.Model(&database.SongListen{}).Where("song_id = ?", song.Id).
+		Update("completed_at", gorm.Expr("completed_at - interval '1 month'")).Error)
+
+	// the next month has differences only, decreased likes are not counted
+	assert.Nil(t, gormDb.Model(&database.CreatorSong{}).Where("id = ?", song.Id).
+		Updates(map[string]interface{}{"likes": 8, "used_in_video": 5}).Error)
+	earnings[song.Id] = decimal.NewFromInt(150)
+
+	generated, err = service.Generate(secondPeriod, gormDb, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, generated)
+
+	statements, err = service.ListMy(ListStatementsRequest{}, 1, gormDb)
+	assert.Nil(t, err)
+
+	if !assert.Len(t, statements.Items, 2) {
+		return
+	}
+
+	second, err := service.Get(StatementRequest{StatementId: statements.Items[0].Id}, 1, gormDb)
+	assert.Nil(t, err)
+	assert.True(t, second.PeriodStart.Equal(secondPeriod))
+	assert.Nil(t, second.Events)
+
+	if assert.Len(t, second.Songs, 1) {
+		line := second.Songs[0]
+		assert.Equal(t, 2, line.UsedInVideo)
+		assert.Equal(t, 0, line.Likes)
+		assert.Equal(t, 10, line.TotalLikes)
+		assert.Equal(t, "50", line.PointsEarned.String())
+		assert.Equal(t, "29", line.RoyaltyPoints.String())
+	}
+
+	_, err = service.Get(StatementRequest{StatementId: first.Id}, 2, gormDb)
+	assert.NotNil(t, err)
+}
+
+func TestService_RequestPayout(t *testing.T) {
+	flush(t)
+
+	period := monthStart(time.Now()).AddDate(0, -1, 0)
+
+	song := addSong(t, database.CreatorSong{
+		UserId:      1,
+		Name:        "song",
+		Status:      music.CreatorSongStatusApproved,
+		UsedInVideo: 5,
+		CreatedAt:   period.Add(-time.Hour),
+	})
+
+	earnings = map[int64]decimal.Decimal{song.Id: decimal.NewFromInt(100)}
+	payoutRequests = nil
+
+	generated, err := service.Generate(period, gormDb, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, generated)
+
+	statements, err := service.AdminList(AdminListStatementsRequest{UserId: null.IntFrom(1), PeriodStart: null.TimeFrom(period)},
+		gormDb)
+	assert.Nil(t, err)
+
+	if !assert.Len(t, statements.Items, 1) {
+		return
+	}
+
+	statementId := statements.Items[0].Id
+	assert.Equal(t, "60", statements.Items[0].TotalPoints.String())
+
+	_, err = service.Hold(HoldStatementRequest{StatementId: statementId, Reason: "audit"}, 10, gormDb)
+	assert.Nil(t, err)
+
+	_, err = service.RequestPayout(StatementRequest{StatementId: statementId}, 1, gormDb, context.TODO())
+	assert.NotNil(t, err)
+
+	_, err = service.Release(StatementRequest{StatementId: statementId}, 10, gormDb)
+	assert.Nil(t, err)
+
+	statement, err := service.Adjust(AdjustStatementRequest{StatementId: statementId, Amount: decimal.NewFromInt(-55),
+		Reason: "fraud listens"}, 10, gormDb)
+	assert.Nil(t, err)
+	assert.Equal(t, "5", statement.TotalPoints.String())
+
+	// total is below min payout
+	_, err = service.RequestPayout(StatementRequest{StatementId: statementId}, 1, gormDb, context.TODO())
+	assert.NotNil(t, err)
+
+	_, err = service.Adjust(AdjustStatementRequest{StatementId: statementId, Amount: decimal.NewFromInt(5)}, 10, gormDb)
+	assert.NotNil(t, err)
+
+	_, err = service.Adjust(AdjustStatementRequest{StatementId: statementId, Amount: decimal.NewFromInt(5),
+		Reason: "bonus"}, 10, gormDb)
+	assert.Nil(t, err)
+
+	statement, err = service.RequestPayout(StatementRequest{StatementId: statementId}, 1, gormDb, context.TODO())
+	assert.Nil(t, err)
+	assert.Equal(t, database.StatementStatusPayoutRequested, statement.Status)
+	assert.Equal(t, "payout-1", statement.PayoutId.String)
+
+	if assert.Len(t, payoutRequests, 1) {
+		assert.Equal(t, statementId, payoutRequests[0].StatementId)
+		assert.Equal(t, "10", payoutRequests[0].Amount.String())
+	}
+
+	// repeated request returns requested payout
+	_, err = service.RequestPayout(StatementRequest{StatementId: statementId}, 1, gormDb, context.TODO())
+	assert.Nil(t, err)
+	assert.Len(t, payoutRequests, 1)
+
+	_, err = service.Adjust(AdjustStatementRequest{StatementId: statementId, Amount: decimal.NewFromInt(1),
+		Reason: "late"}, 10, gormDb)
+	assert.NotNil(t, err)
+
+	resp, err := service.AdminGet(StatementRequest{StatementId: statementId}, gormDb)
+	assert.Nil(t, err)
+
+	types := make([]database.StatementEventType, 0)
+	for _, event := range resp.Events {
+		types = append(types, event.Type)
+	}
+
+	assert.Equal(t, []database.StatementEventType{database.StatementEventTypeGenerated, database.StatementEventTypeHeld,
+		database.StatementEventTypeReleased, database.StatementEventTypeAdjusted, database.StatementEventTypeAdjusted,
+		database.StatementEventTypePayoutRequested}, types)
+}
+
+func TestStatementSong(t *testing.T) {
+	formula := database.RoyaltyFormula{
+		CreatorSharePercent:       70,
+		PointsPerVideoUse:         decimal.NewFromInt(1),
+		PointsPer1000ShortListens: decimal.NewFromInt(1),
+		PointsPer1000FullListens:  decimal.NewFromInt(5),
+		PointsPerReaction:         decimal.RequireFromString("0.1"),
+	}
+
+	previous := database.CreatorStatementSong{
+		TotalUsedInVideo:  10,
+		TotalLikes:        20,
+		TotalComments:     5,
+		TotalPointsEarned: decimal.NewFromInt(40),
+	}
+
+	line := statementSong(database.CreatorSong{Id: 1, Name: "song", UsedInVideo: 12, Likes: 18, Comments: 8, Shares: 1},
+		previous, listenCounts{Short: 1500, Full: 300}, decimal.NewFromInt(50), formula)
+
+	assert.Equal(t, 2, line.UsedInVideo)
+	assert.Equal(t, 0, line.Likes)
+	assert.Equal(t, 20, line.TotalLikes)
+	assert.Equal(t, 3, line.Comments)
+	assert.Equal(t, 1, line.Shares)
+	assert.Equal(t, "10", line.PointsEarned.String())
+	// 10 * 70% + 2 uses + 1.5 short + 1.5 full + 4 reactions * 0.1
+	assert.Equal(t, "12.4", line.RoyaltyPoints.String())
+	assert.True(t, hasActivity(line))
+
+	line = statementSong(database.CreatorSong{Id: 1, Likes: 20}, database.CreatorStatementSong{TotalLikes: 20},
+		listenCounts{}, decimal.Zero, formula)
+	assert.False(t, hasActivity(line))
+}
+
+func TestMonthStart(t *testing.T) {
+	assert.Equal(t, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+		monthStart(time.Date(2026, 3, 31, 23, 30, 0, 0, time.FixedZone("test", 3600))))
+	assert.Equal(t, time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC),
+		monthStart(time.Date(2026, 3, 31, 23, 30, 0, 0, time.FixedZone("test", -3*3600))))
+}
@@ -0,0 +1,59 @@
+package royalties
+
+import (
+	"github.com/digitalmonsters/music/pkg/database"
+	"github.com/shopspring/decimal"
+	"gopkg.in/guregu/null.v4"
+	"time"
+)
+
+const maxReasonLength = 500
+
+type ListStatementsRequest struct {
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+}
+
+type StatementRequest struct {
+	StatementId int64 `json:"statement_id"`
+}
+
+type AdminListStatementsRequest struct {
+	UserId      null.Int                   `json:"user_id"`
+	PeriodStart null.Time                  `json:"period_start"` // any time of the month
+	Statuses    []database.StatementStatus `json:"statuses"`
+	Limit       int                        `json:"limit"`
+	Offset      int                        `json:"offset"`
+}
+
+// AdjustStatementRequest adds Amount to statement total, negative Amount decreases it
+type AdjustStatementRequest struct {
+	StatementId int64           `json:"statement_id"`
+	Amount      decimal.Decimal `json:"amount"`
+	Reason      string          `json:"reason"`
+}
+
+type HoldStatementRequest struct {
+	StatementId int64  `json:"statement_id"`
+	Reason      string `json:"reason"`
+}
+
+type GenerateStatementsRequest struct {
+	PeriodStart time.Time `json:"period_start"` // any time of the month
+}
+
+type GenerateStatementsResponse struct {
+	Generated int `json:"generated"`
+}
+
+type StatementsListResponse struct {
+	Items      []database.CreatorStatement `json:"items"`
+	TotalCount int64                       `json:"total_count"`
+}
+
+// StatementResponse is a statement with song lines. Events are the audit log, they are returned to admins only
+type StatementResponse struct {
+	database.CreatorStatement
+	Songs  []database.CreatorStatementSong  `json:"songs"`
+	Events []database.CreatorStatementEvent `json:"events,omitempty"`
+}
@@ -78,6 +78,7 @@ type UploadNewSongRequest struct {
 	ShortSongDuration float64     `json:"short_song_duration"`
 	ImageUrl          string      `json:"image_url"`
 	Hashtags          []string    `json:"hashtags"`
+	ProcessingJobId   null.Int    `json:"processing_job_id"` // audio uploaded via processing, replaces song urls
 }
 
 type CheckRequestStatusResponse struct {
@@ -6,6 +6,7 @@ import (
 	"github.com/digitalmonsters/go-common/boilerplate"
 	"github.com/digitalmonsters/go-common/boilerplate_testing"
 	"github.com/digitalmonsters/music/configs"
+	"github.com/go-gormigrate/gormigrate/v2"
 	"github.com/rs/zerolog/log"
 	"gorm.io/gorm"
 )
@@ -55,14 +56,16 @@ func init() {
 	} else {
 		readonlyGormDb = readDb
 	}
+}
 
-	// m := gormigrate.New(mainDb, gormigrate.DefaultOptions, getMigrations())
+func Migrate() {
+	m := gormigrate.New(masterGormDb, gormigrate.DefaultOptions, getMigrations())
 
 	log.Info().Msg("[Db] start migrations")
 
-	// if err = m.Migrate(); err != nil {
-	// 	panic(err)
-	// }
+	if err := m.Migrate(); err != nil {
+		panic(err)
+	}
 }
 
 func GetDb(t DbType) *gorm.DB {
@@ -511,5 +511,337 @@ func getMigrations() []*gormigrate.Migration {
 				return db.Exec(query).Error
 			},
 		},
+		{
+			ID: "creator_songs_moderation_191020261900",
+			Migrate: func(db *gorm.DB) error {
+				query := `alter table creator_songs add column if not exists submitted_at timestamptz;
+						  alter table creator_songs add column if not exists reviewed_at timestamptz;
+
+						  create index if not exists creator_songs_pending_idx
+							  on creator_songs (submitted_at) where status = 4;`
+				return db.Exec(query).Error
+			},
+			Rollback: func(db *gorm.DB) error {
+				return nil
+			},
+		},
+		{
+			ID: "song_processing_jobs_191020262000",
+			Migrate: func(db *gorm.DB) error {
+				query := `alter table creator_songs add column if not exists waveform_url text not null default '';
+
+						  create table if not exists song_processing_jobs
+						  (
+							  id                  bigserial primary key,
+							  user_id             bigint           not null,
+							  song_id             bigint           references creator_songs (id),
+							  status              integer          not null default 1,
+							  error               text,
+							  source_path         text             not null,
+							  source_filename     text             not null default '',
+							  preview_offset      double precision not null default 0,
+							  source_codec        text             not null default '',
+							  full_song_url       text             not null default '',
+							  full_song_duration  double precision not null default 0,
+							  short_song_url      text             not null default '',
+							  short_song_duration double precision not null default 0,
+							  waveform_url        text             not null default '',
+							  created_at          timestamptz      not null default now(),
+							  updated_at          timestamptz      not null default now(),
+							  completed_at        timestamptz
+						  );
+
+						  create index if not exists song_processing_jobs_user_id_idx on song_processing_jobs (user_id);
+						  create index if not exists song_processing_jobs_song_id_idx on song_processing_jobs (song_id);`
+				return db.Exec(query).Error
+			},
+			Rollback: func(db *gorm.DB) error {
+				return nil
+			},
+		},
+		{
+			ID: "upload_sessions_191020262100",
+			Migrate: func(db *gorm.DB) error {
+				query := `create table if not exists upload_sessions
+						  (
+							  id            bigserial primary key,
+							  user_id       bigint      not null,
+							  upload_type   integer     not null,
+							  status        integer     not null default 1,
+							  direct        boolean     not null default false,
+							  filename      text        not null default '',
+							  content_type  text        not null default '',
+							  path          text        not null,
+							  s3_upload_id  text        not null default '',
+							  total_size    bigint      not null,
+							  uploaded_size bigint      not null default 0,
+							  chunk_size    bigint      not null,
+							  checksum      text        not null default '',
+							  hash_state    bytea,
+							  expires_at    timestamptz not null,
+							  created_at    timestamptz not null default now(),
+							  updated_at    timestamptz not null default now(),
+							  completed_at  timestamptz
+						  );
+
+						  create index if not exists upload_sessions_user_id_idx on upload_sessions (user_id);
+						  create index if not exists upload_sessions_expires_at_idx on upload_sessions (expires_at) where status = 1;
+
+						  create table if not exists upload_session_parts
+						  (
+							  session_id  bigint      not null references upload_sessions (id) on delete cascade,
+							  part_number bigint      not null,
+							  size        bigint      not null,
+							  etag        text        not null,
+							  created_at  timestamptz not null default now(),
+							  primary key (session_id, part_number)
+						  );`
+				return db.Exec(query).Error
+			},
+			Rollback: func(db *gorm.DB) error {
+				return nil
+			},
+		},
+		{
+			ID: "song_listens_191020262200",
+			Migrate: func(db *gorm.DB) error {
+				query := `create table if not exists song_listens
+						  (
+							  id               bigserial primary key,
+							  user_id          bigint           not null,
+							  song_id          bigint           not null,
+							  song_kind        integer          not null,
+							  listen_type      integer          not null,
+							  source_view      integer          not null default 0,
+							  status           integer          not null default 1,
+							  duration         double precision not null default 0,
+							  listened_seconds double precision not null default 0,
+							  user_ip          text             not null default '',
+							  user_agent       text             not null default '',
+							  is_bot           boolean          not null default false,
+							  counted          boolean          not null default false,
+							  skip_reason      text,
+							  aggregated       boolean          not null default false,
+							  started_at       timestamptz      not null default now(),
+							  updated_at       timestamptz      not null default now(),
+							  completed_at     timestamptz
+						  );
+
+						  create index if not exists song_listens_dedup_idx
+							  on song_listens (user_id, song_id, song_kind, listen_type, completed_at) where counted;
+						  create index if not exists song_listens_user_completed_idx
+							  on song_listens (user_id, completed_at) where counted;
+						  create index if not exists song_listens_not_aggregated_idx
+							  on song_listens (id) where counted and not aggregated;`
+				return db.Exec(query).Error
+			},
+			Rollback: func(db *gorm.DB) error {
+				return nil
+			},
+		},
+		{
+			ID: "music_search_191020262300",
+			Migrate: func(db *gorm.DB) error {
+				query := `create or replace function music_search_tags(tags text[]) returns text
+							  language sql immutable as
+						  $$ select coalesce(array_to_string(tags, ' '), '') $$;
+
+						  alter table songs add column if not exists search_vector tsvector generated always as (
+							  setweight(to_tsvector('simple', coalesce(title, '')), 'A') ||
+							  setweight(to_tsvector('simple', coalesce(artist, '')), 'B') ||
+							  setweight(to_tsvector('simple', coalesce(genre, '')), 'C')) stored;
+
+						  alter table music_storage add column if not exists search_vector tsvector generated always as (
+							  setweight(to_tsvector('simple', coalesce(title, '')), 'A') ||
+							  setweight(to_tsvector('simple', coalesce(artist, '')), 'B') ||
+							  setweight(to_tsvector('simple', coalesce(genre, '')), 'C') ||
+							  setweight(to_tsvector('simple', coalesce(description, '')), 'D')) stored;
+
+						  alter table creator_songs add column if not exists search_vector tsvector generated always as (
+							  setweight(to_tsvector('simple', coalesce(name, '')), 'A') ||
+							  setweight(to_tsvector('simple', coalesce(music_author, '') || ' ' || coalesce(lyric_author, '')), 'B') ||
+							  setweight(to_tsvector('simple', music_search_tags(hashtags)), 'C')) stored;
+
+						  create index if not exists songs_search_vector_idx on songs using gin (search_vector);
+						  create index if not exists music_storage_search_vector_idx on music_storage using gin (search_vector);
+						  create index if not exists creator_songs_search_vector_idx on creator_songs using gin (search_vector);`
+				return db.Exec(query).Error
+			},
+			Rollback: func(db *gorm.DB) error {
+				return nil
+			},
+		},
+		{
+			ID: "user_playlists_191020262400",
+			Migrate: func(db *gorm.DB) error {
+				query := `create table if not exists user_playlists
+						  (
+							  id              bigserial primary key,
+							  user_id         bigint      not null,
+							  name            text        not null,
+							  description     text        not null default '',
+							  visibility      integer     not null default 1,
+							  songs_count     integer     not null default 0,
+							  followers_count integer     not null default 0,
+							  share_link      text,
+							  created_at      timestamptz not null default now(),
+							  updated_at      timestamptz not null default now(),
+							  deleted_at      timestamptz
+						  );
+
+						  create index if not exists user_playlists_user_id_idx on user_playlists (user_id)
+							  where deleted_at is null;
+
+						  create table if not exists user_playlist_songs
+						  (
+							  playlist_id bigint      not null references user_playlists (id) on delete cascade,
+							  song_id     bigint      not null,
+							  song_kind   integer     not null,
+							  sort_order  integer     not null default 0,
+							  created_at  timestamptz not null default now(),
+							  primary key (playlist_id, song_kind, song_id)
+						  );
+
+						  create index if not exists user_playlist_songs_order_idx
+							  on user_playlist_songs (playlist_id, sort_order);
+
+						  create table if not exists user_playlist_followers
+						  (
+							  playlist_id bigint      not null references user_playlists (id) on delete cascade,
+							  user_id     bigint      not null,
+							  created_at  timestamptz not null default now(),
+							  primary key (playlist_id, user_id)
+						  );
+
+						  create index if not exists user_playlist_followers_user_id_idx
+							  on user_playlist_followers (user_id, created_at);`
+				return db.Exec(query).Error
+			},
+			Rollback: func(db *gorm.DB) error {
+				return nil
+			},
+		},
+		{
+			ID: "soundstripe_catalog_191020262500",
+			Migrate: func(db *gorm.DB) error {
+				query := `create table if not exists soundstripe_songs
+						  (
+							  external_id         text primary key,
+							  title               text             not null default '',
+							  artist              text             not null default '',
+							  image_url           text             not null default '',
+							  genre               text             not null default '',
+							  duration            double precision not null default 0,
+							  files               jsonb,
+							  files_expire_at     timestamptz,
+							  license_status      integer          not null default 1,
+							  licensed_at         timestamptz,
+							  upstream_updated_at timestamptz,
+							  synced_at           timestamptz      not null default now(),
+							  removed_at          timestamptz,
+							  created_at          timestamptz      not null default now(),
+							  updated_at          timestamptz      not null default now()
+						  );
+
+						  create index if not exists soundstripe_songs_synced_at_idx on soundstripe_songs (synced_at)
+							  where removed_at is null;
+
+						  create table if not exists soundstripe_sync_state
+						  (
+							  id                   bigint primary key,
+							  updated_cursor       timestamptz,
+							  full_sync_started_at timestamptz,
+							  full_sync_page       integer     not null default 0,
+							  full_sync_seen       integer     not null default 0,
+							  last_full_sync_at    timestamptz,
+							  updated_at           timestamptz not null default now()
+						  );
+
+						  insert into soundstripe_sync_state (id) values (1) on conflict do nothing;`
+				return db.Exec(query).Error
+			},
+			Rollback: func(db *gorm.DB) error {
+				return nil
+			},
+		},
+		{
+			ID: "creator_statements_191020262600",
+			Migrate: func(db *gorm.DB) error {
+				query := `create table if not exists creator_statements
+						  (
+							  id                  bigserial primary key,
+							  user_id             bigint      not null,
+							  period_start        timestamptz not null,
+							  period_end          timestamptz not null,
+							  status              integer     not null default 1,
+							  songs_count         integer     not null default 0,
+							  used_in_video       integer     not null default 0,
+							  short_listens       integer     not null default 0,
+							  full_listens        integer     not null default 0,
+							  likes               integer     not null default 0,
+							  loves               integer     not null default 0,
+							  shares              integer     not null default 0,
+							  comments            integer     not null default 0,
+							  points_earned       numeric     not null default 0,
+							  royalty_points      numeric     not null default 0,
+							  adjustment          numeric     not null default 0,
+							  total_points        numeric     not null default 0,
+							  formula             jsonb,
+							  hold_reason         text,
+							  payout_id           text,
+							  payout_requested_at timestamptz,
+							  created_at          timestamptz not null default now(),
+							  updated_at          timestamptz not null default now(),
+							  unique (user_id, period_start)
+						  );
+
+						  create index if not exists creator_statements_period_idx on creator_statements (period_start, status);
+
+						  create table if not exists creator_statement_songs
+						  (
+							  statement_id        bigint      not null references creator_statements (id) on delete cascade,
+							  song_id             bigint      not null,
+							  song_name           text        not null default '',
+							  used_in_video       integer     not null default 0,
+							  short_listens       integer     not null default 0,
+							  full_listens        integer     not null default 0,
+							  likes               integer     not null default 0,
+							  loves               integer     not null default 0,
+							  shares              integer     not null default 0,
+							  comments            integer     not null default 0,
+							  points_earned       numeric     not null default 0,
+							  royalty_points      numeric     not null default 0,
+							  total_used_in_video integer     not null default 0,
+							  total_likes         integer     not null default 0,
+							  total_loves         integer     not null default 0,
+							  total_shares        integer     not null default 0,
+							  total_comments      integer     not null default 0,
+							  total_points_earned numeric     not null default 0,
+							  created_at          timestamptz not null default now(),
+							  primary key (statement_id, song_id)
+						  );
+
+						  create index if not exists creator_statement_songs_song_id_idx
+							  on creator_statement_songs (song_id, statement_id);
+
+						  create table if not exists creator_statement_events
+						  (
+							  id           bigserial primary key,
+							  statement_id bigint      not null references creator_statements (id) on delete cascade,
+							  type         integer     not null,
+							  admin_id     bigint,
+							  amount       numeric     not null default 0,
+							  comment      text        not null default '',
+							  created_at   timestamptz not null default now()
+						  );
+
+						  create index if not exists creator_statement_events_statement_id_idx
+							  on creator_statement_events (statement_id, id);`
+				return db.Exec(query).Error
+			},
+			Rollback: func(db *gorm.DB) error {
+				return nil
+			},
+		},
 	}
 }
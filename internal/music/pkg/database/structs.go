@@ -1,9 +1,14 @@
 package database
 
 import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"github.com/digitalmonsters/go-common/eventsourcing"
 	"github.com/digitalmonsters/go-common/wrappers/music"
 	"github.com/digitalmonsters/go-common/wrappers/user_go"
 	"github.com/lib/pq"
+	"github.com/pkg/errors"
 	"github.com/shopspring/decimal"
 	"gopkg.in/guregu/null.v4"
 	"gorm.io/gorm"
@@ -152,8 +157,11 @@ type CreatorSong struct {
 	ShortSongUrl      string                  `json:"short_song_url"`
 	ShortSongDuration float64                 `json:"short_song_duration"`
 	ImageUrl          string                  `json:"image_url"`
+	WaveformUrl       string                  `json:"waveform_url"`
 	Hashtags          pq.StringArray          `gorm:"type:text[]" json:"hashtags"`
 	RejectReason      null.Int                `json:"reject_reason"`
+	SubmittedAt       null.Time               `json:"submitted_at"` // when song entered review for the last time
+	ReviewedAt        null.Time               `json:"reviewed_at"`
 
 	ShortListens int             `json:"short_listens"`
 	FullListens  int             `json:"full_listens"`
@@ -180,6 +188,140 @@ func (CreatorSong) TableName() string {
 	return "creator_songs"
 }
 
+// CreatorSongVisibleStatuses are statuses of songs shown to users other than the creator. Published songs were
+// uploaded before moderation was introduced
+var CreatorSongVisibleStatuses = []music.CreatorSongStatus{music.CreatorSongStatusPublished, music.CreatorSongStatusApproved}
+
+type ProcessingStatus int
+
+const (
+	ProcessingStatusNone       = ProcessingStatus(0)
+	ProcessingStatusPending    = ProcessingStatus(1)
+	ProcessingStatusProcessing = ProcessingStatus(2)
+	ProcessingStatusCompleted  = ProcessingStatus(3)
+	ProcessingStatusFailed     = ProcessingStatus(4)
+)
+
+// SongProcessingJob is an uploaded source file of a creator song, which is transcoded into full and short versions
+type SongProcessingJob struct {
+	Id                int64            `json:"id"`
+	UserId            int64            `json:"user_id"`
+	SongId            null.Int         `json:"song_id"`
+	Status            ProcessingStatus `json:"status"`
+	Error             null.String      `json:"error"`
+	SourcePath        string           `json:"-"`
+	SourceFilename    string           `json:"source_filename"`
+	PreviewOffset     float64          `json:"preview_offset"` // start of short version, seconds
+	SourceCodec       string           `json:"source_codec"`
+	FullSongUrl       string           `json:"full_song_url"`
+	FullSongDuration  float64          `json:"full_song_duration"`
+	ShortSongUrl      string           `json:"short_song_url"`
+	ShortSongDuration float64          `json:"short_song_duration"`
+	WaveformUrl       string           `json:"waveform_url"`
+	CreatedAt         time.Time        `json:"created_at"`
+	UpdatedAt         time.Time        `json:"updated_at"`
+	CompletedAt       null.Time        `json:"completed_at"`
+}
+
+func (SongProcessingJob) TableName() string {
+	return "song_processing_jobs"
+}
+
+// IsFinished tells that job will not change song anymore
+func (j SongProcessingJob) IsFinished() bool {
+	return j.Status == ProcessingStatusCompleted || j.Status == ProcessingStatusFailed
+}
+
+type UploadSessionStatus int
+
+const (
+	UploadSessionStatusNone      = UploadSessionStatus(0)
+	UploadSessionStatusActive    = UploadSessionStatus(1)
+	UploadSessionStatusCompleted = UploadSessionStatus(2)
+	UploadSessionStatusAborted   = UploadSessionStatus(3)
+)
+
+// UploadSession is a resumable upload. Chunks are streamed to s3 multipart upload, or file is put directly to storage
+// by presigned url when Direct is set
+type UploadSession struct {
+	Id           int64               `json:"id"`
+	UserId       int64               `json:"user_id"`
+	UploadType   int                 `json:"upload_type"`
+	Status       UploadSessionStatus `json:"status"`
+	Direct       bool                `json:"direct"`
+	Filename     string              `json:"filename"`
+	ContentType  string              `json:"content_type"`
+	Path         string              `json:"-"`
+	S3UploadId   string              `json:"-"`
+	TotalSize    int64               `json:"total_size"`
+	UploadedSize int64               `json:"uploaded_size"` // offset of the next chunk
+	ChunkSize    int64               `json:"chunk_size"`
+	Checksum     string              `json:"checksum"` // sha256 hex of the whole file
+	HashState    []byte              `json:"-"`        // sha256 of uploaded chunks, to verify checksum without reading file back
+	ExpiresAt    time.Time           `json:"expires_at"`
+	CreatedAt    time.Time           `json:"created_at"`
+	UpdatedAt    time.Time           `json:"updated_at"`
+	CompletedAt  null.Time           `json:"completed_at"`
+}
+
+func (UploadSession) TableName() string {
+	return "upload_sessions"
+}
+
+type UploadSessionPart struct {
+	SessionId  int64     `json:"session_id" gorm:"primaryKey;autoIncrement:false"`
+	PartNumber int64     `json:"part_number" gorm:"primaryKey;autoIncrement:false"`
+	Size       int64     `json:"size"`
+	ETag       string    `json:"etag" gorm:"column:etag"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func (UploadSessionPart) TableName() string {
+	return "upload_session_parts"
+}
+
+type ListenSongKind int
+
+const (
+	ListenSongKindNone    = ListenSongKind(0)
+	ListenSongKindCreator = ListenSongKind(1) // creator_songs, counted into short and full listens
+	ListenSongKindLibrary = ListenSongKind(2) // songs of playlists, counted into listen_amount
+)
+
+type ListenStatus int
+
+const (
+	ListenStatusNone      = ListenStatus(0)
+	ListenStatusStarted   = ListenStatus(1)
+	ListenStatusCompleted = ListenStatus(2)
+)
+
+// SongListen is a single playback reported by client. Counted listens are added to song counters by aggregation task
+type SongListen struct {
+	Id              int64                    `json:"id"`
+	UserId          int64                    `json:"user_id"`
+	SongId          int64                    `json:"song_id"`
+	SongKind        ListenSongKind           `json:"song_kind"`
+	ListenType      eventsourcing.ListenType `json:"listen_type"`
+	SourceView      eventsourcing.SourceView `json:"source_view"`
+	Status          ListenStatus             `json:"status"`
+	Duration        float64                  `json:"duration"` // duration of listened version, seconds
+	ListenedSeconds float64                  `json:"listened_seconds"`
+	UserIp          string                   `json:"-"`
+	UserAgent       string                   `json:"-"`
+	IsBot           bool                     `json:"-"`
+	Counted         bool                     `json:"counted"`
+	SkipReason      null.String              `json:"-"` // why listen is not counted
+	Aggregated      bool                     `json:"-"`
+	StartedAt       time.Time                `json:"started_at"`
+	UpdatedAt       time.Time                `json:"updated_at"`
+	CompletedAt     null.Time                `json:"completed_at"`
+}
+
+func (SongListen) TableName() string {
+	return "song_listens"
+}
+
 type Mood struct {
 	Id         int64
 	Name       string
@@ -203,3 +345,259 @@ type ListenedMusic struct {
 func (ListenedMusic) TableName() string {
 	return "listened_music"
 }
+
+type PlaylistVisibility int
+
+const (
+	PlaylistVisibilityPrivate = PlaylistVisibility(1)
+	PlaylistVisibilityPublic  = PlaylistVisibility(2)
+)
+
+// UserPlaylist is a playlist created by user, admin-curated playlists are Playlist
+type UserPlaylist struct {
+	Id             int64              `json:"id"`
+	UserId         int64              `json:"user_id"`
+	Name           string             `json:"name"`
+	Description    string             `json:"description"`
+	Visibility     PlaylistVisibility `json:"visibility"`
+	SongsCount     int                `json:"songs_count"`
+	FollowersCount int                `json:"followers_count"`
+	ShareLink      null.String        `json:"share_link"`
+	CreatedAt      time.Time          `json:"created_at"`
+	UpdatedAt      time.Time          `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt     `json:"-"`
+}
+
+func (UserPlaylist) TableName() string {
+	return "user_playlists"
+}
+
+// UserPlaylistSong is a licensed Song or a CreatorSong added to user playlist
+type UserPlaylistSong struct {
+	PlaylistId int64          `json:"playlist_id"`
+	SongId     int64          `json:"song_id"`
+	SongKind   ListenSongKind `json:"song_kind"`
+	SortOrder  int            `json:"sort_order"`
+	CreatedAt  time.Time      `json:"created_at"`
+}
+
+func (UserPlaylistSong) TableName() string {
+	return "user_playlist_songs"
+}
+
+type UserPlaylistFollower struct {
+	PlaylistId int64     `json:"playlist_id"`
+	UserId     int64     `json:"user_id"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func (UserPlaylistFollower) TableName() string {
+	return "user_playlist_followers"
+}
+
+type LicenseStatus int
+
+const (
+	LicenseStatusNone    = LicenseStatus(0)
+	LicenseStatusActive  = LicenseStatus(1) // song is available upstream
+	LicenseStatusRemoved = LicenseStatus(2) // song was removed upstream, it can not be used anymore
+)
+
+// SongFiles are urls of song versions by version name, e.g. mp3
+type SongFiles map[string]string
+
+func (f SongFiles) Value() (driver.Value, error) {
+	if f == nil {
+		return nil, nil
+	}
+
+	return json.Marshal(f)
+}
+
+func (f *SongFiles) Scan(value interface{}) error {
+	if value == nil {
+		*f = nil
+		return nil
+	}
+
+	var data []byte
+
+	switch v := value.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return errors.New(fmt.Sprintf("can not scan %T into SongFiles", value))
+	}
+
+	return json.Unmarshal(data, f)
+}
+
+// SoundStripeSong is a song of SoundStripe catalog synced by the catalog sync task. Files are signed urls, which are
+// refreshed before FilesExpireAt
+type SoundStripeSong struct {
+	ExternalId        string        `json:"external_id" gorm:"primaryKey"`
+	Title             string        `json:"title"`
+	Artist            string        `json:"artist"`
+	ImageUrl          string        `json:"image_url"`
+	Genre             string        `json:"genre"`
+	Duration          float64       `json:"duration"`
+	Files             SongFiles     `json:"files" gorm:"type:jsonb"`
+	FilesExpireAt     null.Time     `json:"files_expire_at"`
+	LicenseStatus     LicenseStatus `json:"license_status"`
+	LicensedAt        null.Time     `json:"licensed_at"` // first time the song was added to the library
+	UpstreamUpdatedAt null.Time     `json:"upstream_updated_at"`
+	SyncedAt          time.Time     `json:"synced_at"` // last time the song was seen upstream
+	RemovedAt         null.Time     `json:"removed_at"`
+	CreatedAt         time.Time     `json:"created_at"`
+	UpdatedAt         time.Time     `json:"updated_at"`
+}
+
+func (SoundStripeSong) TableName() string {
+	return "soundstripe_songs"
+}
+
+// SoundStripeSyncState is a single row with progress of the catalog sync
+type SoundStripeSyncState struct {
+	Id                int64     `json:"id"`
+	UpdatedCursor     null.Time `json:"updated_cursor"` // max upstream updated_at of synced songs
+	FullSyncStartedAt null.Time `json:"full_sync_started_at"`
+	FullSyncPage      int       `json:"full_sync_page"` // next page of the full sync in progress
+	FullSyncSeen      int       `json:"full_sync_seen"`
+	LastFullSyncAt    null.Time `json:"last_full_sync_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+func (SoundStripeSyncState) TableName() string {
+	return "soundstripe_sync_state"
+}
+
+type StatementStatus int
+
+const (
+	StatementStatusNone            = StatementStatus(0)
+	StatementStatusReady           = StatementStatus(1) // generated, creator can request payout
+	StatementStatusOnHold          = StatementStatus(2) // payout is blocked by admin during audit
+	StatementStatusPayoutRequested = StatementStatus(3)
+)
+
+// RoyaltyFormula is the revenue share config used for a statement, stored with the statement to explain its points
+type RoyaltyFormula struct {
+	CreatorSharePercent       int             `json:"creator_share_percent"`
+	PointsPerVideoUse         decimal.Decimal `json:"points_per_video_use"`
+	PointsPer1000ShortListens decimal.Decimal `json:"points_per_1000_short_listens"`
+	PointsPer1000FullListens  decimal.Decimal `json:"points_per_1000_full_listens"`
+	PointsPerReaction         decimal.Decimal `json:"points_per_reaction"`
+}
+
+func (f RoyaltyFormula) Value() (driver.Value, error) {
+	return json.Marshal(f)
+}
+
+func (f *RoyaltyFormula) Scan(value interface{}) error {
+	if value == nil {
+		*f = RoyaltyFormula{}
+		return nil
+	}
+
+	var data []byte
+
+	switch v := value.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return errors.New(fmt.Sprintf("can not scan %T into RoyaltyFormula", value))
+	}
+
+	return json.Unmarshal(data, f)
+}
+
+// CreatorStatement is a monthly royalty statement of music creator. Counters are sums of CreatorStatementSong,
+// TotalPoints is RoyaltyPoints plus admin Adjustment
+type CreatorStatement struct {
+	Id                int64           `json:"id"`
+	UserId            int64           `json:"user_id"`
+	PeriodStart       time.Time       `json:"period_start"`
+	PeriodEnd         time.Time       `json:"period_end"` // exclusive
+	Status            StatementStatus `json:"status"`
+	SongsCount        int             `json:"songs_count"`
+	UsedInVideo       int             `json:"used_in_video"`
+	ShortListens      int             `json:"short_listens"`
+	FullListens       int             `json:"full_listens"`
+	Likes             int             `json:"likes"`
+	Loves             int             `json:"loves"`
+	Shares            int             `json:"shares"`
+	Comments          int             `json:"comments"`
+	PointsEarned      decimal.Decimal `json:"points_earned"`
+	RoyaltyPoints     decimal.Decimal `json:"royalty_points"`
+	Adjustment        decimal.Decimal `json:"adjustment"`
+	TotalPoints       decimal.Decimal `json:"total_points"`
+	Formula           RoyaltyFormula  `json:"formula" gorm:"type:jsonb"`
+	HoldReason        null.String     `json:"hold_reason"`
+	PayoutId          null.String     `json:"payout_id"`
+	PayoutRequestedAt null.Time       `json:"payout_requested_at"`
+	CreatedAt         time.Time       `json:"created_at"`
+	UpdatedAt         time.Time       `json:"updated_at"`
+}
+
+func (CreatorStatement) TableName() string {
+	return "creator_statements"
+}
+
+// CreatorStatementSong is a song line of statement. Listens are counted listens of the period, other counters are
+// differences of Total* snapshots with the previous statement of the song
+type CreatorStatementSong struct {
+	StatementId       int64           `json:"statement_id" gorm:"primaryKey"`
+	SongId            int64           `json:"song_id" gorm:"primaryKey"`
+	SongName          string          `json:"song_name"`
+	UsedInVideo       int             `json:"used_in_video"`
+	ShortListens      int             `json:"short_listens"`
+	FullListens       int             `json:"full_listens"`
+	Likes             int             `json:"likes"`
+	Loves             int             `json:"loves"`
+	Shares            int             `json:"shares"`
+	Comments          int             `json:"comments"`
+	PointsEarned      decimal.Decimal `json:"points_earned"`
+	RoyaltyPoints     decimal.Decimal `json:"royalty_points"`
+	TotalUsedInVideo  int             `json:"total_used_in_video"`
+	TotalLikes        int             `json:"total_likes"`
+	TotalLoves        int             `json:"total_loves"`
+	TotalShares       int             `json:"total_shares"`
+	TotalComments     int             `json:"total_comments"`
+	TotalPointsEarned decimal.Decimal `json:"total_points_earned"`
+	CreatedAt         time.Time       `json:"created_at"`
+}
+
+func (CreatorStatementSong) TableName() string {
+	return "creator_statement_songs"
+}
+
+type StatementEventType int
+
+const (
+	StatementEventTypeNone            = StatementEventType(0)
+	StatementEventTypeGenerated       = StatementEventType(1)
+	StatementEventTypeAdjusted        = StatementEventType(2)
+	StatementEventTypeHeld            = StatementEventType(3)
+	StatementEventTypeReleased        = StatementEventType(4)
+	StatementEventTypePayoutRequested = StatementEventType(5)
+	StatementEventTypePayoutFailed    = StatementEventType(6)
+)
+
+// CreatorStatementEvent is an audit log record of statement changes, AdminId is set for admin actions
+type CreatorStatementEvent struct {
+	Id          int64              `json:"id"`
+	StatementId int64              `json:"statement_id"`
+	Type        StatementEventType `json:"type"`
+	AdminId     null.Int           `json:"admin_id"`
+	Amount      decimal.Decimal    `json:"amount"`
+	Comment     string             `json:"comment"`
+	CreatedAt   time.Time          `json:"created_at"`
+}
+
+func (CreatorStatementEvent) TableName() string {
+	return "creator_statement_events"
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/samber/lo"
 	"gorm.io/gorm"
+	"time"
 )
 
 type Feed struct {
@@ -39,9 +40,11 @@ func NewFeed(
 }
 
 func (f *Feed) GetFeed(db *gorm.DB, userId int64, startContentsIds []int64, count int, executionData router.MethodExecutionData) (*ContentFeedResponse, *error_codes.ErrorWithCode) {
+	appConfig := f.appConfig.GetValues()
+
 	var expirationData []deduplicator.SongExpiration
 	var idsToIgnore []int64
-	if f.appConfig.Values.MUSIC_FEATURE_FEED_IGNORE_IDS_ENABLED {
+	if appConfig.MUSIC_FEATURE_FEED_IGNORE_IDS_ENABLED {
 		expirationData, idsToIgnore = f.deDuplicator.GetIdsToIgnore(userId, executionData.Context)
 	}
 
@@ -58,7 +61,8 @@ func (f *Feed) GetFeed(db *gorm.DB, userId int64, startContentsIds []int64, coun
 
 		idsToIgnore = append(idsToIgnore, startContentsIds...)
 
-		if err := db.Where("id in ? and reject_reason is null", startContentsIds).Limit(count).Find(&startContents).Error; err != nil {
+		if err := db.Where("id in ? and reject_reason is null", startContentsIds).
+			Where("status in ?", database.CreatorSongVisibleStatuses).Limit(count).Find(&startContents).Error; err != nil {
 			utils.CaptureApmErrorFromTransaction(errors.WithStack(err), executionData.Context)
 		}
 
@@ -66,25 +70,68 @@ func (f *Feed) GetFeed(db *gorm.DB, userId int64, startContentsIds []int64, coun
 	}
 
 	if count != 0 {
-		query := db.Model(songs).
-			Where("short_song_url is not null").
-			Where("full_song_url is not null").
-			Where("reject_reason is null")
+		baseQuery := func() *gorm.DB {
+			query := db.Model(&database.CreatorSong{}).
+				Where("short_song_url is not null").
+				Where("full_song_url is not null").
+				Where("reject_reason is null").
+				Where("status in ?", database.CreatorSongVisibleStatuses)
+
+			query = query.Where("creator_songs.id not in (select song_id from listened_music "+
+				" where listened_music.user_id = ?)", userId)
+
+			if len(idsToIgnore) > 0 {
+				query = query.Where("creator_songs.id not in ?", idsToIgnore)
+			}
 
-		query = query.Where("creator_songs.id not in (select song_id from listened_music "+
-			" where listened_music.user_id = ?)", userId)
+			return query
+		}
 
-		if len(idsToIgnore) > 0 {
-			query = query.Where("creator_songs.id not in ?", idsToIgnore)
+		candidatesLimit := appConfig.MUSIC_FEED_CANDIDATES_LIMIT
+		if candidatesLimit < count {
+			candidatesLimit = count
 		}
 
-		query = query.Order("score desc")
+		var candidates []*database.CreatorSong
 
-		if err := query.Limit(count).Find(&songs).Error; err != nil {
+		if err := baseQuery().Order("score desc").Limit(candidatesLimit).Find(&candidates).Error; err != nil {
 			return nil, error_codes.NewErrorWithCodeRef(err, error_codes.GenericServerError)
 		}
 
-		if f.appConfig.Values.MUSIC_FEATURE_FEED_IGNORE_IDS_ENABLED {
+		var exploration []*database.CreatorSong
+
+		if every := appConfig.MUSIC_FEED_EXPLORATION_EVERY; every > 0 && appConfig.MUSIC_FEED_NEW_CREATOR_DAYS > 0 {
+			newCreatorsSince := time.Now().UTC().AddDate(0, 0, -appConfig.MUSIC_FEED_NEW_CREATOR_DAYS)
+
+			if err := baseQuery().
+				Where("creator_songs.user_id in (select user_id from creator_songs where deleted_at is null "+
+					"group by user_id having min(created_at) > ?)", newCreatorsSince).
+				Order("created_at desc").Limit(count/every + 1).Find(&exploration).Error; err != nil {
+				utils.CaptureApmErrorFromTransaction(errors.WithStack(err), executionData.Context)
+			}
+		}
+
+		profile, err := buildProfile(db, userId)
+		if err != nil {
+			utils.CaptureApmErrorFromTransaction(err, executionData.Context)
+		}
+
+		creatorIds := lo.Uniq(lo.Map(candidates, func(s *database.CreatorSong, _ int) int64 {
+			return s.UserId
+		}))
+
+		if profile.following, err = f.feedConverter.FollowingCreators(userId, creatorIds, executionData.ApmTransaction); err != nil {
+			utils.CaptureApmErrorFromTransaction(err, executionData.Context)
+		}
+
+		ranked := diversify(rank(candidates, profile, rankingWeights{
+			category: float64(appConfig.MUSIC_FEED_CATEGORY_AFFINITY_WEIGHT),
+			mood:     float64(appConfig.MUSIC_FEED_MOOD_AFFINITY_WEIGHT),
+		}))
+
+		songs = diversify(mixExploration(ranked, exploration, appConfig.MUSIC_FEED_EXPLORATION_EVERY, count))
+
+		if appConfig.MUSIC_FEATURE_FEED_IGNORE_IDS_ENABLED {
 			go func() {
 				f.deDuplicator.SetIdsToIgnore(songs, userId, expirationData, executionData.Context)
 			}()
@@ -14,6 +14,8 @@ import (
 	"github.com/patrickmn/go-cache"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
+	"github.com/samber/lo"
+	"github.com/shopspring/decimal"
 	"github.com/thoas/go-funk"
 	"go.elastic.co/apm"
 	"gopkg.in/guregu/null.v4"
@@ -190,6 +192,7 @@ func (s *Service) ConvertToSongModel(songs []*database.CreatorSong, currentUserI
 			ShortSongUrl:      song.ShortSongUrl,
 			ShortSongDuration: song.ShortSongDuration,
 			ImageUrl:          song.ImageUrl,
+			WaveformUrl:       song.WaveformUrl,
 			Hashtags:          song.Hashtags,
 			Shares:            song.Shares,
 			ShortListens:      song.ShortListens,
@@ -281,6 +284,28 @@ func (s *Service) fillFollowingData(contentModels map[int64]*frontend.CreatorSon
 	return ch
 }
 
+// FollowingCreators returns creators from creatorIds which are followed by current user
+func (s *Service) FollowingCreators(currentUserId int64, creatorIds []int64, apmTransaction *apm.Transaction) (map[int64]bool, error) {
+	following := map[int64]bool{}
+
+	if currentUserId == 0 || len(creatorIds) == 0 {
+		return following, nil
+	}
+
+	data := <-s.followWrapper.GetUserFollowingRelationBulk(currentUserId, creatorIds, apmTransaction, false)
+	if data.Error != nil {
+		return following, data.Error.ToError()
+	}
+
+	for creatorId, relation := range data.Data {
+		if relation.IsFollowing {
+			following[creatorId] = true
+		}
+	}
+
+	return following, nil
+}
+
 func (s *Service) fillUsersAndApplyUserPrivacySettings(
 	contentModels map[int64]*frontend.CreatorSongModel,
 	ctx context.Context,
@@ -404,30 +429,16 @@ func (s *Service) fillPointsCount(contentModels map[int64]*frontend.CreatorSongM
 				continue
 			}
 
-			hasContentId := false
-
-			for _, contentId := range contentIds {
-				if contentId == content.Id {
-					hasContentId = true
-					break
-				}
-			}
-
-			if hasContentId {
-				continue
-			}
-
 			contentIds = append(contentIds, content.Id)
 		}
 
-		resp := <-s.goTokenomicsWrapper.GetContentEarningsTotalByContentIds(contentIds, apmTransaction, false)
-
-		if resp.Error != nil {
-			ch <- errors.Wrap(errors.New(resp.Error.Message), "fill points count failed")
+		pointsEarned, err := s.GetPointsEarned(contentIds, apmTransaction)
+		if err != nil {
+			ch <- errors.Wrap(err, "fill points count failed")
 		}
 
 		for _, content := range contentModels {
-			pointsCountResp, hasPointsCount := resp.Items[content.Id]
+			pointsCountResp, hasPointsCount := pointsEarned[content.Id]
 
 			if !hasPointsCount {
 				continue
@@ -439,3 +450,25 @@ func (s *Service) fillPointsCount(contentModels map[int64]*frontend.CreatorSongM
 
 	return ch
 }
+
+// GetPointsEarned returns total points earned by creator songs in tokenomics, songs without earnings are missing
+// in the result
+func (s *Service) GetPointsEarned(songIds []int64, apmTransaction *apm.Transaction) (map[int64]decimal.Decimal, error) {
+	songIds = lo.Uniq(songIds)
+
+	if len(songIds) == 0 {
+		return map[int64]decimal.Decimal{}, nil
+	}
+
+	resp := <-s.goTokenomicsWrapper.GetContentEarningsTotalByContentIds(songIds, apmTransaction, false)
+
+	if resp.Error != nil {
+		return nil, errors.New(resp.Error.Message)
+	}
+
+	if resp.Items == nil {
+		return map[int64]decimal.Decimal{}, nil
+	}
+
+	return resp.Items, nil
+}
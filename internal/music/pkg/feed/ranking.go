@@ -0,0 +1,199 @@
+package feed
+
+import (
+	"math"
+	"sort"
+
+	"github.com/digitalmonsters/music/pkg/database"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+// globalScoreWeight is a weight of normalized creator_songs.score, affinity weights from app config are relative to it
+const globalScoreWeight = 100.0
+
+type affinityRecord struct {
+	CategoryId int64
+	MoodId     int64
+	Amount     int
+}
+
+type userProfile struct {
+	categories map[int64]float64 // share of category in user history, 0..1
+	moods      map[int64]float64 // share of mood in user history, 0..1
+	following  map[int64]bool
+}
+
+type rankingWeights struct {
+	category float64
+	mood     float64
+}
+
+// buildProfile collects user taste from listened creator songs and favorite library songs.
+// Library songs have only genre, so favorites are matched to categories by name
+func buildProfile(db *gorm.DB, userId int64) (userProfile, error) {
+	var listened []affinityRecord
+
+	if err := db.Table("listened_music").
+		Select("creator_songs.category_id, creator_songs.mood_id, count(*) as amount").
+		Joins("join creator_songs on creator_songs.id = listened_music.song_id").
+		Where("listened_music.user_id = ?", userId).
+		Group("creator_songs.category_id, creator_songs.mood_id").
+		Scan(&listened).Error; err != nil {
+		return userProfile{}, errors.WithStack(err)
+	}
+
+	var favorites []affinityRecord
+
+	if err := db.Table("favorites").
+		Select("categories.id as category_id, count(*) as amount").
+		Joins("join songs on songs.id = favorites.song_id and songs.deleted_at is null").
+		Joins("join categories on lower(categories.name) = lower(songs.genre) and categories.deleted_at is null").
+		Where("favorites.user_id = ?", userId).
+		Group("categories.id").
+		Scan(&favorites).Error; err != nil {
+		return userProfile{}, errors.WithStack(err)
+	}
+
+	return profileFromSignals(append(listened, favorites...)), nil
+}
+
+func profileFromSignals(records []affinityRecord) userProfile {
+	profile := userProfile{
+		categories: map[int64]float64{},
+		moods:      map[int64]float64{},
+		following:  map[int64]bool{},
+	}
+
+	var categoriesTotal, moodsTotal int
+
+	for _, r := range records {
+		if r.CategoryId > 0 {
+			profile.categories[r.CategoryId] += float64(r.Amount)
+			categoriesTotal += r.Amount
+		}
+
+		if r.MoodId > 0 {
+			profile.moods[r.MoodId] += float64(r.Amount)
+			moodsTotal += r.Amount
+		}
+	}
+
+	for id := range profile.categories {
+		profile.categories[id] /= float64(categoriesTotal)
+	}
+
+	for id := range profile.moods {
+		profile.moods[id] /= float64(moodsTotal)
+	}
+
+	return profile
+}
+
+// rank orders candidates by global score mixed with user affinity. Songs of followed creators go first
+func rank(songs []*database.CreatorSong, profile userProfile, weights rankingWeights) []*database.CreatorSong {
+	if len(songs) == 0 {
+		return songs
+	}
+
+	minScore, maxScore := math.MaxInt, math.MinInt
+
+	for _, s := range songs {
+		if s.Score < minScore {
+			minScore = s.Score
+		}
+
+		if s.Score > maxScore {
+			maxScore = s.Score
+		}
+	}
+
+	values := make(map[int64]float64, len(songs))
+
+	for _, s := range songs {
+		var value float64
+
+		if maxScore > minScore {
+			value = float64(s.Score-minScore) / float64(maxScore-minScore) * globalScoreWeight
+		}
+
+		value += profile.categories[s.CategoryId] * weights.category
+		value += profile.moods[s.MoodId] * weights.mood
+
+		values[s.Id] = value
+	}
+
+	ranked := append([]*database.CreatorSong{}, songs...)
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		iFollowing, jFollowing := profile.following[ranked[i].UserId], profile.following[ranked[j].UserId]
+		if iFollowing != jFollowing {
+			return iFollowing
+		}
+
+		return values[ranked[i].Id] > values[ranked[j].Id]
+	})
+
+	return ranked
+}
+
+// mixExploration takes count songs from ranked, giving every n-th slot to songs of new creators
+func mixExploration(ranked []*database.CreatorSong, exploration []*database.CreatorSong, every int, count int) []*database.CreatorSong {
+	result := make([]*database.CreatorSong, 0, count)
+	seen := map[int64]bool{}
+
+	next := func(songs []*database.CreatorSong) []*database.CreatorSong {
+		for len(songs) > 0 {
+			s := songs[0]
+			songs = songs[1:]
+
+			if !seen[s.Id] {
+				seen[s.Id] = true
+				result = append(result, s)
+
+				return songs
+			}
+		}
+
+		return songs
+	}
+
+	for len(result) < count && (len(ranked) > 0 || len(exploration) > 0) {
+		position := len(result) + 1
+
+		if len(exploration) > 0 && (len(ranked) == 0 || (every > 0 && position%every == 0)) {
+			exploration = next(exploration)
+		} else {
+			ranked = next(ranked)
+		}
+	}
+
+	return result
+}
+
+// diversify reorders songs so the same creator is not shown twice in a row, keeping the order otherwise.
+// When only songs of the same creator are left, they are appended as is
+func diversify(songs []*database.CreatorSong) []*database.CreatorSong {
+	rest := append([]*database.CreatorSong{}, songs...)
+	result := make([]*database.CreatorSong, 0, len(songs))
+
+	for len(rest) > 0 {
+		picked := 0
+
+		if len(result) > 0 {
+			last := result[len(result)-1].UserId
+
+			for i, s := range rest {
+				if s.UserId != last {
+					picked = i
+					break
+				}
+			}
+		}
+
+		result = append(result, rest[picked])
+		rest = append(rest[:picked], rest[picked+1:]...)
+	}
+
+	return result
+}
@@ -0,0 +1,69 @@
+package feed
+
+import (
+	"testing"
+
+	"github.com/digitalmonsters/music/pkg/database"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+)
+
+func songIds(songs []*database.CreatorSong) []int64 {
+	return lo.Map(songs, func(s *database.CreatorSong, _ int) int64 {
+		return s.Id
+	})
+}
+
+func TestProfileFromSignals(t *testing.T) {
+	profile := profileFromSignals([]affinityRecord{
+		{CategoryId: 1, MoodId: 10, Amount: 3},
+		{CategoryId: 2, MoodId: 10, Amount: 1},
+		{CategoryId: 1, Amount: 4}, // favorites have no mood
+	})
+
+	assert.Equal(t, map[int64]float64{1: 0.875, 2: 0.125}, profile.categories)
+	assert.Equal(t, map[int64]float64{10: 1}, profile.moods)
+}
+
+func TestRank(t *testing.T) {
+	songs := []*database.CreatorSong{
+		{Id: 1, UserId: 1, CategoryId: 1, MoodId: 1, Score: 100},
+		{Id: 2, UserId: 2, CategoryId: 2, MoodId: 2, Score: 80},
+		{Id: 3, UserId: 3, CategoryId: 1, MoodId: 1, Score: 0},
+		{Id: 4, UserId: 4, CategoryId: 1, MoodId: 1, Score: 50},
+	}
+
+	weights := rankingWeights{category: 50, mood: 30}
+
+	assert.Equal(t, []int64{1, 2, 4, 3}, songIds(rank(songs, profileFromSignals(nil), weights)))
+
+	profile := profileFromSignals([]affinityRecord{{CategoryId: 2, MoodId: 2, Amount: 1}})
+	assert.Equal(t, []int64{2, 1, 4, 3}, songIds(rank(songs, profile, weights)))
+
+	profile.following = map[int64]bool{3: true}
+	assert.Equal(t, []int64{3, 2, 1, 4}, songIds(rank(songs, profile, weights)))
+}
+
+func TestMixExploration(t *testing.T) {
+	ranked := []*database.CreatorSong{{Id: 1}, {Id: 2}, {Id: 3}, {Id: 4}, {Id: 5}}
+	exploration := []*database.CreatorSong{{Id: 3}, {Id: 10}, {Id: 11}}
+
+	assert.Equal(t, []int64{1, 2, 3, 4, 5}, songIds(mixExploration(ranked, exploration, 3, 5)))
+	assert.Equal(t, []int64{1, 2, 3, 4}, songIds(mixExploration(ranked, exploration, 0, 4)))
+	assert.Equal(t, []int64{1, 3, 2, 10, 4, 11, 5}, songIds(mixExploration(ranked, exploration, 2, 10)))
+}
+
+func TestDiversify(t *testing.T) {
+	songs := []*database.CreatorSong{
+		{Id: 1, UserId: 1},
+		{Id: 2, UserId: 1},
+		{Id: 3, UserId: 1},
+		{Id: 4, UserId: 2},
+		{Id: 5, UserId: 3},
+	}
+
+	assert.Equal(t, []int64{1, 4, 2, 5, 3}, songIds(diversify(songs)))
+
+	sameCreator := []*database.CreatorSong{{Id: 1, UserId: 1}, {Id: 2, UserId: 1}}
+	assert.Equal(t, []int64{1, 2}, songIds(diversify(sameCreator)))
+}
@@ -9,7 +9,6 @@ import (
 	"github.com/digitalmonsters/go-common/apm_helper"
 	"github.com/digitalmonsters/go-common/application"
 	"github.com/digitalmonsters/go-common/boilerplate"
-	"github.com/digitalmonsters/go-common/wrappers/music"
 	"github.com/digitalmonsters/music/configs"
 	"github.com/digitalmonsters/music/pkg/database"
 	"github.com/digitalmonsters/music/utils"
@@ -77,9 +76,11 @@ func (b *feedBuilder) findCreatorSongs(tx *gorm.DB) ([]database.CreatorSong, err
 
 	var finalRecords []database.CreatorSong
 
-	if err := tx.Where("status != ?", music.CreatorSongStatusRejected).
+	appConfig := b.appConfig.GetValues()
+
+	if err := tx.Where("status in ?", database.CreatorSongVisibleStatuses).
 		Where("reject_reason is null").
-		Limit(b.appConfig.Values.MUSIC_FEED_LIMIT).
+		Limit(appConfig.MUSIC_FEED_LIMIT).
 		Order("id desc").
 		Find(&records).Error; err != nil {
 		return nil, errors.WithStack(err)
@@ -90,18 +91,18 @@ func (b *feedBuilder) findCreatorSongs(tx *gorm.DB) ([]database.CreatorSong, err
 	}
 
 	for _, r := range records {
-		score := (r.Loves * b.appConfig.Values.MUSIC_CALCULATION_LOVE_COUNT_WEIGHT) +
-			(r.Likes * b.appConfig.Values.MUSIC_CALCULATION_LIKE_COUNT_WEIGHT) +
-			(r.ShortListens * b.appConfig.Values.MUSIC_CALCULATION_SHORT_LISTEN_COUNT_WEIGHT) -
-			(r.Dislikes * b.appConfig.Values.MUSIC_CALCULATION_DISLIKE_COUNT_WEIGHT)
+		score := (r.Loves * appConfig.MUSIC_CALCULATION_LOVE_COUNT_WEIGHT) +
+			(r.Likes * appConfig.MUSIC_CALCULATION_LIKE_COUNT_WEIGHT) +
+			(r.ShortListens * appConfig.MUSIC_CALCULATION_SHORT_LISTEN_COUNT_WEIGHT) -
+			(r.Dislikes * appConfig.MUSIC_CALCULATION_DISLIKE_COUNT_WEIGHT)
 
 		timeNow := time2.Now().UTC()
 
-		if b.appConfig.Values.MUSIC_CALCULATION_TIMING_START_CONF > 0 && b.appConfig.Values.MUSIC_CALCULATION_TIMING_DELIMITER > 0 {
-			val := int(math.Round(float64(timeNow.Unix()-r.CreatedAt.Unix()) / float64(b.appConfig.Values.MUSIC_CALCULATION_TIMING_DELIMITER)))
+		if appConfig.MUSIC_CALCULATION_TIMING_START_CONF > 0 && appConfig.MUSIC_CALCULATION_TIMING_DELIMITER > 0 {
+			val := int(math.Round(float64(timeNow.Unix()-r.CreatedAt.Unix()) / float64(appConfig.MUSIC_CALCULATION_TIMING_DELIMITER)))
 
 			if val > 0 {
-				score += b.appConfig.Values.MUSIC_CALCULATION_TIMING_START_CONF / val
+				score += appConfig.MUSIC_CALCULATION_TIMING_START_CONF / val
 			}
 		}
 
@@ -131,7 +132,7 @@ func (b *feedBuilder) registerTask() error {
 		return err
 	}
 
-	every := fmt.Sprintf("@every %vm", b.appConfig.Values.MUSIC_FEED_UPDATE_SCORE_FREQUENCY_MINUTES)
+	every := fmt.Sprintf("@every %vm", b.appConfig.GetValues().MUSIC_FEED_UPDATE_SCORE_FREQUENCY_MINUTES)
 
 	return b.machineryServer.RegisterPeriodicTask(every, taskName, &tasks.Signature{
 		Name: taskName,
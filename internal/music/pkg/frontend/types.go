@@ -56,6 +56,7 @@ type CreatorSongModel struct {
 	ShortSongUrl      string                  `json:"short_song_url"`
 	ShortSongDuration float64                 `json:"short_song_duration"`
 	ImageUrl          string                  `json:"image_url"`
+	WaveformUrl       string                  `json:"waveform_url"`
 	Hashtags          pq.StringArray          `gorm:"type:text[]" json:"hashtags"`
 	ShortListens      int                     `json:"short_listens"`
 	FullListens       int                     `json:"full_listens"`
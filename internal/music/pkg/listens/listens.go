@@ -0,0 +1,438 @@
+package listens
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/RichardKnop/machinery/v1"
+	"github.com/RichardKnop/machinery/v1/tasks"
+	"github.com/digitalmonsters/go-common/apm_helper"
+	"github.com/digitalmonsters/go-common/boilerplate"
+	"github.com/digitalmonsters/go-common/eventsourcing"
+	"github.com/digitalmonsters/music/configs"
+	"github.com/digitalmonsters/music/pkg/database"
+	"github.com/digitalmonsters/music/utils"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"github.com/samber/lo"
+	"gopkg.in/guregu/null.v4"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const aggregateTaskName = "music:listens:aggregate"
+
+// progressTolerance allows client clock and network delays when listened seconds are compared to wall time
+const progressTolerance = 5 * time.Second
+
+// Service tracks listens reported by player: start, progress and complete. Completed listen is counted once per
+// user, song and listen type in a dedup window, bots and own songs are not counted
+type Service struct {
+	cfg           configs.ListensConfig
+	viewPublisher eventsourcing.IEventPublisher
+}
+
+func NewService(jobber *machinery.Server, cfg configs.ListensConfig, viewPublisher eventsourcing.IEventPublisher) *Service {
+	s := &Service{
+		cfg:           cfg,
+		viewPublisher: viewPublisher,
+	}
+
+	if boilerplate.GetCurrentEnvironment() != boilerplate.Ci {
+		if err := s.registerAggregateTask(jobber); err != nil {
+			log.Fatal().Err(err).Msg("[Music] can not register listens aggregation task")
+		}
+	}
+
+	return s
+}
+
+func (s *Service) Start(req StartListenRequest, client Client, db *gorm.DB) (*ListenResponse, error) {
+	if req.ListenType != eventsourcing.ListenTypeShort && req.ListenType != eventsourcing.ListenTypeFull {
+		return nil, errors.New("invalid listen_type")
+	}
+
+	duration, err := songDuration(req, client.UserId, db)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+
+	listen := database.SongListen{
+		UserId:     client.UserId,
+		SongId:     req.SongId,
+		SongKind:   req.SongKind,
+		ListenType: req.ListenType,
+		SourceView: req.SourceView,
+		Status:     database.ListenStatusStarted,
+		Duration:   duration,
+		UserIp:     client.Ip,
+		UserAgent:  client.UserAgent,
+		StartedAt:  now,
+		UpdatedAt:  now,
+	}
+
+	if err := db.Create(&listen).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return toResponse(listen), nil
+}
+
+func (s *Service) Progress(req ProgressListenRequest, client Client, db *gorm.DB) (*ListenResponse, error) {
+	var listen database.SongListen
+	if err := db.Where("id = ? and user_id = ?", req.ListenId, client.UserId).Find(&listen).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if listen.Id == 0 {
+		return nil, errors.New("listen not found")
+	}
+
+	if listen.Status != database.ListenStatusStarted {
+		return nil, errors.New("listen is already completed")
+	}
+
+	listened := listenedSeconds(listen, req.ListenedSeconds, time.Now())
+
+	if listened > listen.ListenedSeconds {
+		if err := db.Model(&listen).Where("status = ?", database.ListenStatusStarted).
+			Updates(map[string]interface{}{
+				"listened_seconds": listened,
+				"updated_at":       time.Now().UTC(),
+			}).Error; err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		listen.ListenedSeconds = listened
+	}
+
+	return toResponse(listen), nil
+}
+
+// Complete finishes listen and decides whether it is counted. Counted listens of creator songs are published as
+// ViewEvent for tokenomics, counters are updated by aggregation task
+func (s *Service) Complete(req ProgressListenRequest, client Client, db *gorm.DB, ctx context.Context) (*ListenResponse, error) {
+	tx := db.Begin()
+	defer tx.Rollback()
+
+	// listens of a user are completed one by one, so dedup and rate limit see each other
+	if err := tx.Exec("select pg_advisory_xact_lock(?)", client.UserId).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	var listen database.SongListen
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? and user_id = ?", req.ListenId, client.UserId).Find(&listen).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if listen.Id == 0 {
+		return nil, errors.New("listen not found")
+	}
+
+	if listen.Status != database.ListenStatusStarted {
+		return toResponse(listen), nil
+	}
+
+	now := time.Now().UTC()
+
+	listen.ListenedSeconds = math.Max(listen.ListenedSeconds, listenedSeconds(listen, req.ListenedSeconds, now))
+
+	var song database.CreatorSong
+	if listen.SongKind == database.ListenSongKindCreator {
+		if err := tx.Where("id = ?", listen.SongId).Find(&song).Error; err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+
+	skipReason, isBot, err := s.skipReason(listen, song, tx, now)
+	if err != nil {
+		return nil, err
+	}
+
+	listen.Status = database.ListenStatusCompleted
+	listen.Counted = skipReason == ""
+	listen.IsBot = isBot
+	listen.CompletedAt = null.TimeFrom(now)
+	listen.UpdatedAt = now
+
+	if !listen.Counted {
+		listen.SkipReason = null.StringFrom(skipReason)
+	}
+
+	if err := tx.Model(&listen).Updates(map[string]interface{}{
+		"status":           listen.Status,
+		"listened_seconds": listen.ListenedSeconds,
+		"counted":          listen.Counted,
+		"is_bot":           listen.IsBot,
+		"skip_reason":      listen.SkipReason,
+		"completed_at":     listen.CompletedAt,
+		"updated_at":       listen.UpdatedAt,
+	}).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if listen.Counted && listen.SongKind == database.ListenSongKindCreator {
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&database.ListenedMusic{
+			UserId: listen.UserId,
+			SongId: listen.SongId,
+		}).Error; err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if listen.Counted && listen.SongKind == database.ListenSongKindCreator {
+		s.publishView(listen, song, ctx)
+	}
+
+	return toResponse(listen), nil
+}
+
+// Aggregate adds counted listens to song counters. Listens are grouped, so a popular song row is updated once per run
+func (s *Service) Aggregate(db *gorm.DB) error {
+	tx := db.Begin()
+	defer tx.Rollback()
+
+	var listens []database.SongListen
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("counted and not aggregated").Order("id asc").Limit(10000).Find(&listens).Error; err != nil {
+		return errors.WithStack(err)
+	}
+
+	if len(listens) == 0 {
+		return nil
+	}
+
+	for _, c := range groupCounters(listens) {
+		var err error
+
+		switch {
+		case c.SongKind == database.ListenSongKindLibrary:
+			err = tx.Model(&database.Song{}).Where("id = ?", c.SongId).
+				Update("listen_amount", gorm.Expr("coalesce(listen_amount, 0) + ?", c.Count)).Error
+		case c.ListenType == eventsourcing.ListenTypeShort:
+			err = tx.Model(&database.CreatorSong{}).Where("id = ?", c.SongId).
+				Update("short_listens", gorm.Expr("coalesce(short_listens, 0) + ?", c.Count)).Error
+		default:
+			err = tx.Model(&database.CreatorSong{}).Where("id = ?", c.SongId).
+				Update("full_listens", gorm.Expr("coalesce(full_listens, 0) + ?", c.Count)).Error
+		}
+
+		if err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	if err := tx.Model(&database.SongListen{}).
+		Where("id in ?", lo.Map(listens, func(item database.SongListen, _ int) int64 { return item.Id })).
+		Update("aggregated", true).Error; err != nil {
+		return errors.WithStack(err)
+	}
+
+	return errors.WithStack(tx.Commit().Error)
+}
+
+func (s *Service) skipReason(listen database.SongListen, song database.CreatorSong, tx *gorm.DB, now time.Time) (string, bool, error) {
+	if s.isBotUserAgent(listen.UserAgent) {
+		return skipReasonBot, true, nil
+	}
+
+	if listen.SongKind == database.ListenSongKindCreator && song.UserId == listen.UserId {
+		return skipReasonOwnSong, false, nil
+	}
+
+	if listen.ListenedSeconds < requiredSeconds(listen.Duration, s.cfg.MinListenSeconds, s.cfg.MinListenPercent) {
+		return skipReasonTooShort, false, nil
+	}
+
+	var duplicates int64
+	if err := tx.Model(&database.SongListen{}).
+		Where("user_id = ? and song_id = ? and song_kind = ? and listen_type = ?", listen.UserId, listen.SongId,
+			listen.SongKind, listen.ListenType).
+		Where("counted and completed_at > ?", now.Add(-time.Duration(s.cfg.DedupWindowMinutes)*time.Minute)).
+		Count(&duplicates).Error; err != nil {
+		return "", false, errors.WithStack(err)
+	}
+
+	if duplicates > 0 {
+		return skipReasonDuplicate, false, nil
+	}
+
+	if s.cfg.MaxListensPerHour > 0 {
+		var lastHour int64
+		if err := tx.Model(&database.SongListen{}).
+			Where("user_id = ? and counted and completed_at > ?", listen.UserId, now.Add(-time.Hour)).
+			Count(&lastHour).Error; err != nil {
+			return "", false, errors.WithStack(err)
+		}
+
+		if lastHour >= int64(s.cfg.MaxListensPerHour) {
+			return skipReasonRateLimit, true, nil
+		}
+	}
+
+	return "", false, nil
+}
+
+func (s *Service) isBotUserAgent(userAgent string) bool {
+	if strings.TrimSpace(userAgent) == "" {
+		return true
+	}
+
+	userAgent = strings.ToLower(userAgent)
+
+	for _, pattern := range s.cfg.BotUserAgents {
+		if pattern != "" && strings.Contains(userAgent, strings.ToLower(pattern)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *Service) publishView(listen database.SongListen, song database.CreatorSong, ctx context.Context) {
+	if s.viewPublisher == nil {
+		return
+	}
+
+	watchPercent := 0
+	if listen.Duration > 0 {
+		watchPercent = int(math.Min(100, math.Round(listen.ListenedSeconds/listen.Duration*100)))
+	}
+
+	event := eventsourcing.ViewEvent{
+		UserId:            listen.UserId,
+		UserIp:            listen.UserIp,
+		ContentId:         listen.SongId,
+		ContentType:       eventsourcing.ContentTypeMusic,
+		ContentAuthorId:   song.UserId,
+		ContentTotalViews: int64(song.ShortListens + song.FullListens + 1),
+		WatchTime:         int(listen.ListenedSeconds),
+		WatchPercent:      int8(watchPercent),
+		CreatedAt:         listen.CompletedAt.ValueOrZero(),
+		ListenType:        listen.ListenType,
+		SourceView:        listen.SourceView,
+	}
+
+	for _, err := range s.viewPublisher.Publish(nil, event) {
+		utils.CaptureApmErrorFromTransaction(errors.Wrap(err, fmt.Sprintf("can not publish view of listen %v", listen.Id)), ctx)
+	}
+}
+
+func (s *Service) registerAggregateTask(jobber *machinery.Server) error {
+	if err := jobber.RegisterTask(aggregateTaskName, func() error {
+		var apmTransaction = apm_helper.StartNewApmTransaction(aggregateTaskName, "task", nil, nil)
+		defer apmTransaction.End()
+
+		return s.Aggregate(database.GetDb(database.DbTypeMaster))
+	}); err != nil {
+		return err
+	}
+
+	interval := s.cfg.AggregationIntervalMinutes
+	if interval <= 0 {
+		interval = 1
+	}
+
+	return utils.RegisterPeriodicTask(jobber, fmt.Sprintf("@every %vm", interval), aggregateTaskName, []tasks.Arg{}, true)
+}
+
+// songDuration checks that the song can be listened and returns duration of the listened version
+func songDuration(req StartListenRequest, userId int64, db *gorm.DB) (float64, error) {
+	switch req.SongKind {
+	case database.ListenSongKindCreator:
+		var song database.CreatorSong
+		if err := db.Where("id = ?", req.SongId).Find(&song).Error; err != nil {
+			return 0, errors.WithStack(err)
+		}
+
+		if song.Id == 0 || (song.UserId != userId && !lo.Contains(database.CreatorSongVisibleStatuses, song.Status)) {
+			return 0, errors.New("song not found")
+		}
+
+		if req.ListenType == eventsourcing.ListenTypeShort {
+			return song.ShortSongDuration, nil
+		}
+
+		return song.FullSongDuration, nil
+	case database.ListenSongKindLibrary:
+		var song database.Song
+		if err := db.Where("id = ?", req.SongId).Find(&song).Error; err != nil {
+			return 0, errors.WithStack(err)
+		}
+
+		if song.Id == 0 {
+			return 0, errors.New("song not found")
+		}
+
+		return song.Duration, nil
+	default:
+		return 0, errors.New("invalid song_kind")
+	}
+}
+
+// listenedSeconds limits reported progress by song duration and by time passed since listen start
+func listenedSeconds(listen database.SongListen, reported float64, now time.Time) float64 {
+	listened := math.Max(0, reported)
+
+	if listen.Duration > 0 {
+		listened = math.Min(listened, listen.Duration)
+	}
+
+	elapsed := now.Sub(listen.StartedAt) + progressTolerance
+
+	return math.Min(listened, elapsed.Seconds())
+}
+
+// requiredSeconds is minimal listened time of counted listen, short versions are counted by percent of duration
+func requiredSeconds(duration float64, minSeconds int, minPercent int) float64 {
+	required := float64(minSeconds)
+
+	if duration > 0 {
+		required = math.Min(required, duration*float64(minPercent)/100)
+	}
+
+	return required
+}
+
+func groupCounters(listens []database.SongListen) []songCounter {
+	var counters []songCounter
+	indexes := map[string]int{}
+
+	for _, l := range listens {
+		key := fmt.Sprintf("%v_%v_%v", l.SongKind, l.SongId, l.ListenType)
+
+		if i, ok := indexes[key]; ok {
+			counters[i].Count++
+			continue
+		}
+
+		indexes[key] = len(counters)
+		counters = append(counters, songCounter{
+			SongId:     l.SongId,
+			SongKind:   l.SongKind,
+			ListenType: l.ListenType,
+			Count:      1,
+		})
+	}
+
+	return counters
+}
+
+func toResponse(listen database.SongListen) *ListenResponse {
+	return &ListenResponse{
+		Id:              listen.Id,
+		Status:          listen.Status,
+		ListenedSeconds: listen.ListenedSeconds,
+		Counted:         listen.Counted,
+	}
+}
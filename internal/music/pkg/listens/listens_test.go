@@ -0,0 +1,257 @@
+package listens
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/digitalmonsters/go-common/boilerplate_testing"
+	"github.com/digitalmonsters/go-common/eventsourcing"
+	"github.com/digitalmonsters/go-common/wrappers/music"
+	"github.com/digitalmonsters/music/configs"
+	"github.com/digitalmonsters/music/pkg/database"
+	"github.com/stretchr/testify/assert"
+	"go.elastic.co/apm"
+	"gorm.io/gorm"
+)
+
+var config configs.Settings
+var gormDb *gorm.DB
+
+func TestMain(m *testing.M) {
+	config = configs.GetConfig()
+	gormDb = database.GetDb(database.DbTypeMaster)
+	os.Exit(m.Run())
+}
+
+type publisherMock struct {
+	events []eventsourcing.IEventData
+}
+
+func (p *publisherMock) Publish(apmTransaction *apm.Transaction, events ...eventsourcing.IEventData) []error {
+	p.events = append(p.events, events...)
+	return nil
+}
+
+func (p *publisherMock) GetPublisherType() eventsourcing.PublisherType {
+	return eventsourcing.PublisherTypeKafka
+}
+
+func (p *publisherMock) GetHosts() string {
+	return ""
+}
+
+func (p *publisherMock) GetTopic() string {
+	return ""
+}
+
+func newTestService(publisher *publisherMock) *Service {
+	return &Service{
+		cfg: configs.ListensConfig{
+			DedupWindowMinutes: 60,
+			MinListenSeconds:   30,
+			MinListenPercent:   50,
+			MaxListensPerHour:  100,
+			BotUserAgents:      []string{"bot", "curl"},
+		},
+		viewPublisher: publisher,
+	}
+}
+
+func addSong(t *testing.T, song database.CreatorSong) database.CreatorSong {
+	category := database.Category{Name: "test_category"}
+	if err := gormDb.Create(&category).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	mood := database.Mood{Name: "test_mood"}
+	if err := gormDb.Create(&mood).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	song.CategoryId = category.Id
+	song.MoodId = mood.Id
+
+	if err := gormDb.Create(&song).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	return song
+}
+
+// startedAgo moves listen start back, so listened seconds are not limited by wall time
+func startedAgo(t *testing.T, listenId int64, d time.Duration) {
+	if err := gormDb.Model(&database.SongListen{}).Where("id = ?", listenId).
+		Update("started_at", time.Now().UTC().Add(-d)).Error; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestService_CompleteAndAggregate(t *testing.T) {
+	if err := boilerplate_testing.FlushPostgresTables(config.MasterDb, []string{"public.song_listens",
+		"public.listened_music", "public.creator_songs", "public.categories", "public.moods"}, nil, t); err != nil {
+		t.Fatal(err)
+	}
+
+	song := addSong(t, database.CreatorSong{
+		Id:                100,
+		UserId:            1,
+		Name:              "test",
+		Status:            music.CreatorSongStatusApproved,
+		ShortSongDuration: 20,
+		FullSongDuration:  180,
+	})
+
+	publisher := &publisherMock{}
+	service := newTestService(publisher)
+	listener := Client{UserId: 2, Ip: "127.0.0.1", UserAgent: "LitIt/1.0 (iPhone)"}
+
+	listen, err := service.Start(StartListenRequest{
+		SongId:     song.Id,
+		SongKind:   database.ListenSongKindCreator,
+		ListenType: eventsourcing.ListenTypeFull,
+	}, listener, gormDb)
+	assert.Nil(t, err)
+
+	// progress can not be greater than time passed since start
+	progress, err := service.Progress(ProgressListenRequest{ListenId: listen.Id, ListenedSeconds: 120}, listener, gormDb)
+	assert.Nil(t, err)
+	assert.Less(t, progress.ListenedSeconds, 10.0)
+
+	startedAgo(t, listen.Id, 2*time.Minute)
+
+	completed, err := service.Complete(ProgressListenRequest{ListenId: listen.Id, ListenedSeconds: 120}, listener,
+		gormDb, context.TODO())
+	assert.Nil(t, err)
+	assert.True(t, completed.Counted)
+	assert.Equal(t, 120.0, completed.ListenedSeconds)
+	assert.Len(t, publisher.events, 1)
+
+	// second listen in dedup window is not counted
+	second, err := service.Start(StartListenRequest{
+		SongId:     song.Id,
+		SongKind:   database.ListenSongKindCreator,
+		ListenType: eventsourcing.ListenTypeFull,
+	}, listener, gormDb)
+	assert.Nil(t, err)
+
+	startedAgo(t, second.Id, 2*time.Minute)
+
+	completed, err = service.Complete(ProgressListenRequest{ListenId: second.Id, ListenedSeconds: 120}, listener,
+		gormDb, context.TODO())
+	assert.Nil(t, err)
+	assert.False(t, completed.Counted)
+	assert.Len(t, publisher.events, 1)
+
+	// short version is counted separately
+	short, err := service.Start(StartListenRequest{
+		SongId:     song.Id,
+		SongKind:   database.ListenSongKindCreator,
+		ListenType: eventsourcing.ListenTypeShort,
+	}, listener, gormDb)
+	assert.Nil(t, err)
+
+	startedAgo(t, short.Id, time.Minute)
+
+	completed, err = service.Complete(ProgressListenRequest{ListenId: short.Id, ListenedSeconds: 15}, listener,
+		gormDb, context.TODO())
+	assert.Nil(t, err)
+	assert.True(t, completed.Counted)
+
+	assert.Nil(t, service.Aggregate(gormDb))
+	assert.Nil(t, service.Aggregate(gormDb))
+
+	if err := gormDb.First(&song, song.Id).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, 1, song.FullListens)
+	assert.Equal(t, 1, song.ShortListens)
+
+	var listened int64
+	if err := gormDb.Model(&database.ListenedMusic{}).Where("user_id = ? and song_id = ?", listener.UserId, song.Id).
+		Count(&listened).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, int64(1), listened)
+}
+
+func TestService_CompleteNotCounted(t *testing.T) {
+	if err := boilerplate_testing.FlushPostgresTables(config.MasterDb, []string{"public.song_listens",
+		"public.listened_music", "public.creator_songs", "public.categories", "public.moods"}, nil, t); err != nil {
+		t.Fatal(err)
+	}
+
+	song := addSong(t, database.CreatorSong{
+		Id:               101,
+		UserId:           1,
+		Name:             "test",
+		Status:           music.CreatorSongStatusApproved,
+		FullSongDuration: 180,
+	})
+
+	publisher := &publisherMock{}
+	service := newTestService(publisher)
+
+	for _, client := range []Client{
+		{UserId: 2, UserAgent: "curl/8.0"},  // bot
+		{UserId: 1, UserAgent: "LitIt/1.0"}, // author
+		{UserId: 3, UserAgent: "LitIt/1.0"}, // too short
+	} {
+		listen, err := service.Start(StartListenRequest{
+			SongId:     song.Id,
+			SongKind:   database.ListenSongKindCreator,
+			ListenType: eventsourcing.ListenTypeFull,
+		}, client, gormDb)
+		assert.Nil(t, err)
+
+		startedAgo(t, listen.Id, time.Minute)
+
+		listenedSeconds := 60.0
+		if client.UserId == 3 {
+			listenedSeconds = 10
+		}
+
+		completed, err := service.Complete(ProgressListenRequest{ListenId: listen.Id, ListenedSeconds: listenedSeconds},
+			client, gormDb, context.TODO())
+		assert.Nil(t, err)
+		assert.False(t, completed.Counted)
+	}
+
+	assert.Len(t, publisher.events, 0)
+}
+
+func TestListenedSeconds(t *testing.T) {
+	now := time.Now()
+	listen := database.SongListen{Duration: 100, StartedAt: now.Add(-time.Minute)}
+
+	assert.Equal(t, 30.0, listenedSeconds(listen, 30, now))
+	assert.Equal(t, 65.0, listenedSeconds(listen, 90, now))
+	assert.Equal(t, 0.0, listenedSeconds(listen, -5, now))
+
+	listen.StartedAt = now.Add(-time.Hour)
+	assert.Equal(t, 100.0, listenedSeconds(listen, 500, now))
+}
+
+func TestRequiredSeconds(t *testing.T) {
+	assert.Equal(t, 30.0, requiredSeconds(180, 30, 50))
+	assert.Equal(t, 10.0, requiredSeconds(20, 30, 50))
+	assert.Equal(t, 30.0, requiredSeconds(0, 30, 50))
+}
+
+func TestGroupCounters(t *testing.T) {
+	counters := groupCounters([]database.SongListen{
+		{SongId: 1, SongKind: database.ListenSongKindCreator, ListenType: eventsourcing.ListenTypeFull},
+		{SongId: 1, SongKind: database.ListenSongKindCreator, ListenType: eventsourcing.ListenTypeShort},
+		{SongId: 1, SongKind: database.ListenSongKindCreator, ListenType: eventsourcing.ListenTypeFull},
+		{SongId: 1, SongKind: database.ListenSongKindLibrary, ListenType: eventsourcing.ListenTypeFull},
+	})
+
+	assert.Equal(t, []songCounter{
+		{SongId: 1, SongKind: database.ListenSongKindCreator, ListenType: eventsourcing.ListenTypeFull, Count: 2},
+		{SongId: 1, SongKind: database.ListenSongKindCreator, ListenType: eventsourcing.ListenTypeShort, Count: 1},
+		{SongId: 1, SongKind: database.ListenSongKindLibrary, ListenType: eventsourcing.ListenTypeFull, Count: 1},
+	}, counters)
+}
@@ -0,0 +1,47 @@
+package listens
+
+import (
+	"github.com/digitalmonsters/go-common/eventsourcing"
+	"github.com/digitalmonsters/music/pkg/database"
+)
+
+type StartListenRequest struct {
+	SongId     int64                    `json:"song_id"`
+	SongKind   database.ListenSongKind  `json:"song_kind"`
+	ListenType eventsourcing.ListenType `json:"listen_type"`
+	SourceView eventsourcing.SourceView `json:"source_view"`
+}
+
+type ProgressListenRequest struct {
+	ListenId        int64   `json:"listen_id"`
+	ListenedSeconds float64 `json:"listened_seconds"`
+}
+
+// Client describes who reports the listen, used for bot filtering
+type Client struct {
+	UserId    int64
+	Ip        string
+	UserAgent string
+}
+
+type ListenResponse struct {
+	Id              int64                 `json:"id"`
+	Status          database.ListenStatus `json:"status"`
+	ListenedSeconds float64               `json:"listened_seconds"`
+	Counted         bool                  `json:"counted"`
+}
+
+const (
+	skipReasonBot       = "bot"
+	skipReasonOwnSong   = "own_song"
+	skipReasonTooShort  = "too_short"
+	skipReasonDuplicate = "duplicate"
+	skipReasonRateLimit = "rate_limit"
+)
+
+type songCounter struct {
+	SongId     int64
+	SongKind   database.ListenSongKind
+	ListenType eventsourcing.ListenType
+	Count      int
+}
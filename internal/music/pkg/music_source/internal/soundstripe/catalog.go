@@ -0,0 +1,534 @@
+package soundstripe
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/digitalmonsters/go-common/apm_helper"
+	"github.com/digitalmonsters/music/pkg/database"
+	"github.com/digitalmonsters/music/pkg/music_source/internal"
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	"go.elastic.co/apm"
+	"gopkg.in/guregu/null.v4"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const syncStateId = 1
+
+const defaultSyncPageSize = 100
+const defaultFileUrlTtl = 60 * time.Minute
+
+// SyncCatalog copies SoundStripe catalog into soundstripe_songs. Songs updated since the last run are synced by
+// upstream updated_at, full sync pages through the whole catalog every FullSyncIntervalHours to detect removed songs.
+// Full sync is resumed from the saved page when it is interrupted by rate limits. Finally, expiring file urls of
+// library songs are refreshed
+func (s *Service) SyncCatalog(db *gorm.DB, apmTransaction *apm.Transaction, ctx context.Context) error {
+	state, err := s.syncState(db)
+	if err != nil {
+		return err
+	}
+
+	// db keeps microseconds, otherwise songs synced now look older than the full sync start
+	now := time.Now().UTC().Truncate(time.Microsecond)
+
+	// full sync in progress also syncs updated songs, incremental sync continues after it
+	if state.UpdatedCursor.Valid && !state.FullSyncStartedAt.Valid {
+		if err := s.syncUpdated(state, now, db, apmTransaction, ctx); err != nil {
+			if !errors.Is(err, ErrRateLimited) {
+				return err
+			}
+
+			apm_helper.LogError(err, ctx)
+		}
+	}
+
+	if s.fullSyncDue(*state, now) {
+		if err := s.syncFull(state, now, db, apmTransaction, ctx); err != nil {
+			if !errors.Is(err, ErrRateLimited) {
+				return err
+			}
+
+			apm_helper.LogError(err, ctx)
+		}
+	}
+
+	return s.refreshFiles(now, db, apmTransaction, ctx)
+}
+
+func (s *Service) syncUpdated(state *database.SoundStripeSyncState, now time.Time, db *gorm.DB, apmTransaction *apm.Transaction, ctx context.Context) error {
+	cursor := state.UpdatedCursor
+
+	for page := 1; ; page++ {
+		songs, count, err := s.fetchPage(page, "-updated_at", apmTransaction, ctx)
+		if err != nil {
+			return err
+		}
+
+		done := count < s.pageSize()
+
+		var updated []internal.SongModel
+		for _, song := range songs {
+			if !song.UpdatedAt.Valid || !song.UpdatedAt.Time.After(state.UpdatedCursor.Time) {
+				done = true
+				break
+			}
+
+			updated = append(updated, song)
+
+			if song.UpdatedAt.Time.After(cursor.Time) {
+				cursor = song.UpdatedAt
+			}
+		}
+
+		if err := s.upsertCatalog(updated, now, db); err != nil {
+			return err
+		}
+
+		if done {
+			break
+		}
+	}
+
+	state.UpdatedCursor = cursor
+
+	return s.saveState(state, db)
+}
+
+func (s *Service) syncFull(state *database.SoundStripeSyncState, now time.Time, db *gorm.DB, apmTransaction *apm.Transaction, ctx context.Context) error {
+	if !state.FullSyncStartedAt.Valid {
+		state.FullSyncStartedAt = null.TimeFrom(now)
+		state.FullSyncPage = 1
+		state.FullSyncSeen = 0
+
+		if err := s.saveState(state, db); err != nil {
+			return err
+		}
+	}
+
+	for {
+		songs, count, err := s.fetchPage(state.FullSyncPage, "", apmTransaction, ctx)
+		if err != nil {
+			return err
+		}
+
+		if err := s.upsertCatalog(songs, now, db); err != nil {
+			return err
+		}
+
+		for _, song := range songs {
+			if song.UpdatedAt.Valid && song.UpdatedAt.Time.After(state.UpdatedCursor.Time) {
+				state.UpdatedCursor = song.UpdatedAt
+			}
+		}
+
+		state.FullSyncSeen += len(songs)
+		state.FullSyncPage++
+
+		if count < s.pageSize() {
+			break
+		}
+
+		if err := s.saveState(state, db); err != nil {
+			return err
+		}
+	}
+
+	// empty catalog is more likely an api issue than removal of all songs
+	if state.FullSyncSeen > 0 {
+		if err := s.removeStale(state.FullSyncStartedAt.Time, db, apmTransaction, ctx); err != nil {
+			return err
+		}
+	}
+
+	state.LastFullSyncAt = null.TimeFrom(now)
+	state.FullSyncStartedAt = null.Time{}
+	state.FullSyncPage = 0
+	state.FullSyncSeen = 0
+
+	return s.saveState(state, db)
+}
+
+// removeStale removes songs which were not seen by full sync started at startedAt, and library songs missing in the
+// catalog. Paging may skip songs when catalog changes during the sync, so every song is checked before removal
+func (s *Service) removeStale(startedAt time.Time, db *gorm.DB, apmTransaction *apm.Transaction, ctx context.Context) error {
+	var candidates []string
+	if err := db.Raw(`select external_id from soundstripe_songs where removed_at is null and synced_at < ?
+			union
+			select s.external_id from songs s where s.source = ? and s.deleted_at is null and not exists
+				(select 1 from soundstripe_songs ss where ss.external_id = s.external_id and ss.removed_at is null)`,
+		startedAt, database.SongSourceSoundStripe).Scan(&candidates).Error; err != nil {
+		return errors.WithStack(err)
+	}
+
+	var removed []string
+
+	for _, externalId := range candidates {
+		resp := <-s.getSong(externalId, apmTransaction, ctx)
+		if resp.Error == nil {
+			if err := s.upsertCatalog([]internal.SongModel{resp.Song}, time.Now().UTC(), db); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if errors.Is(resp.Error, ErrSongNotFound) {
+			removed = append(removed, externalId)
+			continue
+		}
+
+		if errors.Is(resp.Error, ErrRateLimited) {
+			// the rest is checked by the next full sync
+			apm_helper.LogError(resp.Error, ctx)
+			break
+		}
+
+		apm_helper.LogError(resp.Error, ctx)
+	}
+
+	return s.removeSongs(removed, db)
+}
+
+// refreshFiles refreshes signed urls of library songs which are about to expire, so playback does not wait for api
+func (s *Service) refreshFiles(now time.Time, db *gorm.DB, apmTransaction *apm.Transaction, ctx context.Context) error {
+	if s.cfg.FileUrlRefreshBatchSize <= 0 {
+		return nil
+	}
+
+	var externalIds []string
+	if err := db.Model(&database.SoundStripeSong{}).
+		Where("removed_at is null and (files_expire_at is null or files_expire_at < ?)", now.Add(s.refreshMargin())).
+		Where("exists (select 1 from songs s where s.source = ? and s.external_id = soundstripe_songs.external_id "+
+			"and s.deleted_at is null)", database.SongSourceSoundStripe).
+		Order("files_expire_at nulls first").
+		Limit(s.cfg.FileUrlRefreshBatchSize).
+		Pluck("external_id", &externalIds).Error; err != nil {
+		return errors.WithStack(err)
+	}
+
+	var removed []string
+
+	for _, externalId := range externalIds {
+		resp := <-s.getSong(externalId, apmTransaction, ctx)
+		if resp.Error != nil {
+			if errors.Is(resp.Error, ErrSongNotFound) {
+				removed = append(removed, externalId)
+				continue
+			}
+
+			apm_helper.LogError(resp.Error, ctx)
+
+			if errors.Is(resp.Error, ErrRateLimited) {
+				break
+			}
+
+			continue
+		}
+
+		if err := s.upsertCatalog([]internal.SongModel{resp.Song}, time.Now().UTC(), db); err != nil {
+			return err
+		}
+	}
+
+	return s.removeSongs(removed, db)
+}
+
+func (s *Service) fetchPage(page int, sort string, apmTransaction *apm.Transaction, ctx context.Context) ([]internal.SongModel, int, error) {
+	link := fmt.Sprintf("songs?page[size]=%v&page[number]=%v", s.pageSize(), page)
+	if len(sort) > 0 {
+		link += fmt.Sprintf("&sort=%v", sort)
+	}
+
+	internalResp, err := s.makeApiRequestInternal(link, "GET", nil, apmTransaction)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var ssResp soundstripeSongsResp
+	if err = json.Unmarshal(internalResp, &ssResp); err != nil {
+		return nil, 0, errors.WithStack(err)
+	}
+
+	return s.mapToOurModel(ssResp, ctx), len(ssResp.MusicData), nil
+}
+
+// upsertCatalog saves songs seen upstream, songs removed earlier are restored in the catalog
+func (s *Service) upsertCatalog(songs []internal.SongModel, now time.Time, db *gorm.DB) error {
+	if len(songs) == 0 {
+		return nil
+	}
+
+	rows := lo.Map(songs, func(song internal.SongModel, _ int) database.SoundStripeSong {
+		return database.SoundStripeSong{
+			ExternalId:        song.ExternalId,
+			Title:             song.Title,
+			Artist:            song.Artist,
+			ImageUrl:          song.ImageUrl,
+			Genre:             song.Genre,
+			Duration:          song.Duration,
+			Files:             song.Files,
+			FilesExpireAt:     null.TimeFrom(s.filesExpireAt(song.Files, now)),
+			LicenseStatus:     database.LicenseStatusActive,
+			UpstreamUpdatedAt: song.UpdatedAt,
+			SyncedAt:          now,
+			CreatedAt:         now,
+			UpdatedAt:         now,
+		}
+	})
+
+	rows = lo.UniqBy(rows, func(row database.SoundStripeSong) string {
+		return row.ExternalId
+	})
+
+	if err := db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "external_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"title", "artist", "image_url", "genre", "duration", "files",
+			"files_expire_at", "license_status", "upstream_updated_at", "synced_at", "removed_at", "updated_at"}),
+	}).Create(&rows).Error; err != nil {
+		return errors.WithStack(err)
+	}
+
+	return nil
+}
+
+// trackLicenses saves when songs were added to the library for the first time
+func (s *Service) trackLicenses(songs []database.Song, tx *gorm.DB) error {
+	if err := tx.Model(&database.SoundStripeSong{}).
+		Where("external_id in ? and licensed_at is null", lo.Map(songs, func(song database.Song, _ int) string {
+			return song.ExternalId
+		})).
+		Update("licensed_at", time.Now().UTC()).Error; err != nil {
+		return errors.WithStack(err)
+	}
+
+	return nil
+}
+
+// removeSongs marks songs removed upstream in the catalog. Library songs are soft deleted and removed from admin and
+// user playlists, songs counters are updated by triggers and recounted for user playlists
+func (s *Service) removeSongs(externalIds []string, db *gorm.DB) error {
+	if len(externalIds) == 0 {
+		return nil
+	}
+
+	tx := db.Begin()
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+
+	var librarySongs []database.Song
+	if err := tx.Where("source = ? and external_id in ?", database.SongSourceSoundStripe, externalIds).
+		Find(&librarySongs).Error; err != nil {
+		return errors.WithStack(err)
+	}
+
+	mapped := lo.Associate(librarySongs, func(song database.Song) (string, database.Song) {
+		return song.ExternalId, song
+	})
+
+	rows := lo.Map(lo.Uniq(externalIds), func(externalId string, _ int) database.SoundStripeSong {
+		song := mapped[externalId]
+
+		return database.SoundStripeSong{
+			ExternalId:    externalId,
+			Title:         song.Title,
+			Artist:        song.Artist,
+			ImageUrl:      song.ImageUrl,
+			Genre:         song.Genre,
+			Duration:      song.Duration,
+			LicenseStatus: database.LicenseStatusRemoved,
+			SyncedAt:      now,
+			RemovedAt:     null.TimeFrom(now),
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		}
+	})
+
+	if err := tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "external_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"license_status", "removed_at", "updated_at"}),
+	}).Create(&rows).Error; err != nil {
+		return errors.WithStack(err)
+	}
+
+	if len(librarySongs) > 0 {
+		songIds := lo.Map(librarySongs, func(song database.Song, _ int) int64 {
+			return song.Id
+		})
+
+		if err := tx.Where("song_id in ?", songIds).Delete(&database.PlaylistSongRelations{}).Error; err != nil {
+			return errors.WithStack(err)
+		}
+
+		var userPlaylistIds []int64
+		if err := tx.Model(&database.UserPlaylistSong{}).
+			Where("song_kind = ? and song_id in ?", database.ListenSongKindLibrary, songIds).
+			Distinct().Pluck("playlist_id", &userPlaylistIds).Error; err != nil {
+			return errors.WithStack(err)
+		}
+
+		if err := tx.Where("song_kind = ? and song_id in ?", database.ListenSongKindLibrary, songIds).
+			Delete(&database.UserPlaylistSong{}).Error; err != nil {
+			return errors.WithStack(err)
+		}
+
+		if len(userPlaylistIds) > 0 {
+			if err := tx.Exec(`update user_playlists p set songs_count =
+					(select count(*) from user_playlist_songs ups where ups.playlist_id = p.id)
+					where p.id in ?`, userPlaylistIds).Error; err != nil {
+				return errors.WithStack(err)
+			}
+		}
+
+		if err := tx.Where("id in ?", songIds).Delete(&database.Song{}).Error; err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	return errors.WithStack(tx.Commit().Error)
+}
+
+func (s *Service) catalogReady(db *gorm.DB) (bool, error) {
+	var count int64
+	if err := db.Model(&database.SoundStripeSyncState{}).
+		Where("id = ? and last_full_sync_at is not null", syncStateId).Count(&count).Error; err != nil {
+		return false, errors.WithStack(err)
+	}
+
+	return count > 0, nil
+}
+
+func (s *Service) listCatalog(req internal.GetSongsListRequest, db *gorm.DB) (internal.GetSongsListResponse, error) {
+	resp := internal.GetSongsListResponse{}
+
+	query := db.Model(&database.SoundStripeSong{}).Where("removed_at is null")
+
+	if req.SearchKeyword.Valid {
+		keyword := fmt.Sprintf("%%%v%%", req.SearchKeyword.String)
+		query = query.Where("title ilike ? or artist ilike ?", keyword, keyword)
+	}
+
+	if err := query.Count(&resp.TotalCount).Error; err != nil {
+		return resp, errors.WithStack(err)
+	}
+
+	if req.Page < 1 {
+		req.Page = 1
+	}
+
+	if req.Size < 1 {
+		req.Size = 20
+	}
+
+	var songs []database.SoundStripeSong
+	if err := query.Order("upstream_updated_at desc nulls last, external_id").
+		Limit(req.Size).Offset((req.Page - 1) * req.Size).Find(&songs).Error; err != nil {
+		return resp, errors.WithStack(err)
+	}
+
+	validAfter := time.Now().Add(s.refreshMargin())
+
+	for _, song := range songs {
+		model := internal.SongModel{
+			Source:     database.SongSourceSoundStripe,
+			ExternalId: song.ExternalId,
+			Title:      song.Title,
+			Artist:     song.Artist,
+			ImageUrl:   song.ImageUrl,
+			Genre:      song.Genre,
+			Duration:   song.Duration,
+			UpdatedAt:  song.UpstreamUpdatedAt,
+		}
+
+		if song.FilesExpireAt.Valid && song.FilesExpireAt.Time.After(validAfter) {
+			model.Files = song.Files
+		}
+
+		resp.Songs = append(resp.Songs, model)
+	}
+
+	return resp, nil
+}
+
+func (s *Service) syncState(db *gorm.DB) (*database.SoundStripeSyncState, error) {
+	state := database.SoundStripeSyncState{Id: syncStateId}
+
+	if err := db.Where("id = ?", syncStateId).FirstOrCreate(&state).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return &state, nil
+}
+
+func (s *Service) saveState(state *database.SoundStripeSyncState, db *gorm.DB) error {
+	state.UpdatedAt = time.Now().UTC()
+
+	return errors.WithStack(db.Save(state).Error)
+}
+
+func (s *Service) fullSyncDue(state database.SoundStripeSyncState, now time.Time) bool {
+	if state.FullSyncStartedAt.Valid || !state.LastFullSyncAt.Valid {
+		return true
+	}
+
+	return now.Sub(state.LastFullSyncAt.Time) >= time.Duration(s.cfg.FullSyncIntervalHours)*time.Hour
+}
+
+func (s *Service) pageSize() int {
+	if s.cfg.SyncPageSize > 0 {
+		return s.cfg.SyncPageSize
+	}
+
+	return defaultSyncPageSize
+}
+
+func (s *Service) refreshMargin() time.Duration {
+	return time.Duration(s.cfg.FileUrlRefreshMinutes) * time.Minute
+}
+
+// filesExpireAt returns the earliest expiration of signed urls, from Expires param of CloudFront urls or
+// X-Amz-Date and X-Amz-Expires of S3 presigned urls. FileUrlTtlMinutes is used for urls without expiration
+func (s *Service) filesExpireAt(files map[string]string, now time.Time) time.Time {
+	ttl := time.Duration(s.cfg.FileUrlTtlMinutes) * time.Minute
+	if ttl <= 0 {
+		ttl = defaultFileUrlTtl
+	}
+
+	expireAt := now.Add(ttl)
+
+	for _, file := range files {
+		u, err := url.Parse(file)
+		if err != nil {
+			continue
+		}
+
+		query := u.Query()
+
+		if expires, err := strconv.ParseInt(query.Get("Expires"), 10, 64); err == nil {
+			if t := time.Unix(expires, 0).UTC(); t.Before(expireAt) {
+				expireAt = t
+			}
+
+			continue
+		}
+
+		signedAt, err := time.Parse("20060102T150405Z", query.Get("X-Amz-Date"))
+		if err != nil {
+			continue
+		}
+
+		if seconds, err := strconv.Atoi(query.Get("X-Amz-Expires")); err == nil {
+			if t := signedAt.Add(time.Duration(seconds) * time.Second); t.Before(expireAt) {
+				expireAt = t
+			}
+		}
+	}
+
+	return expireAt
+}
@@ -0,0 +1,244 @@
+package soundstripe
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/digitalmonsters/go-common/boilerplate_testing"
+	"github.com/digitalmonsters/music/configs"
+	"github.com/digitalmonsters/music/pkg/database"
+	"github.com/digitalmonsters/music/pkg/music_source/internal"
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/guregu/null.v4"
+	"gorm.io/gorm"
+)
+
+var config configs.Settings
+var gormDb *gorm.DB
+
+func TestMain(m *testing.M) {
+	config = configs.GetConfig()
+	gormDb = database.GetDb(database.DbTypeMaster)
+	os.Exit(m.Run())
+}
+
+func newTestService(fake *FakeServer, sleeps *[]time.Duration) *Service {
+	s := NewService(configs.SoundStripeConfig{
+		ApiUrl:                  fake.URL,
+		MaxWorkers:              2,
+		MaxTimeout:              5,
+		FullSyncIntervalHours:   24,
+		SyncPageSize:            2,
+		MaxRetries:              2,
+		RetryBaseMs:             100,
+		MaxRetryWaitSec:         10,
+		FileUrlRefreshMinutes:   10,
+		FileUrlRefreshBatchSize: 10,
+	})
+
+	s.sleep = func(d time.Duration) {
+		*sleeps = append(*sleeps, d)
+	}
+
+	return s
+}
+
+func flush(t *testing.T) {
+	if err := boilerplate_testing.FlushPostgresTables(config.MasterDb, []string{"public.soundstripe_songs",
+		"public.soundstripe_sync_state", "public.songs", "public.playlists", "public.playlist_song_relations",
+		"public.user_playlists", "public.user_playlist_songs"}, nil, t); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestService_SyncCatalog(t *testing.T) {
+	flush(t)
+
+	fake := NewFakeServer()
+	defer fake.Close()
+
+	var sleeps []time.Duration
+	service := newTestService(fake, &sleeps)
+
+	updatedAt := time.Now().UTC().Add(-time.Hour).Truncate(time.Second)
+	for i := 1; i <= 3; i++ {
+		fake.PutSong(FakeSong{Id: fmt.Sprint(i), Title: fmt.Sprintf("song %v", i), Artist: "artist", Duration: 100,
+			UpdatedAt: updatedAt.Add(time.Duration(i) * time.Minute)})
+	}
+
+	librarySong := database.Song{Source: database.SongSourceSoundStripe, ExternalId: "2", Title: "song 2"}
+	assert.Nil(t, gormDb.Create(&librarySong).Error)
+
+	playlist := database.Playlist{Name: "test", SortOrder: 1}
+	assert.Nil(t, gormDb.Create(&playlist).Error)
+	assert.Nil(t, gormDb.Create(&database.PlaylistSongRelations{PlaylistId: playlist.Id, SongId: librarySong.Id}).Error)
+
+	assert.Nil(t, service.SyncCatalog(gormDb, nil, context.TODO()))
+
+	var songs []database.SoundStripeSong
+	assert.Nil(t, gormDb.Order("external_id").Find(&songs).Error)
+
+	if assert.Len(t, songs, 3) {
+		assert.Equal(t, "song 1", songs[0].Title)
+		assert.Equal(t, database.LicenseStatusActive, songs[0].LicenseStatus)
+		assert.True(t, songs[0].FilesExpireAt.Valid)
+	}
+
+	state, err := service.syncState(gormDb)
+	assert.Nil(t, err)
+	assert.True(t, state.LastFullSyncAt.Valid)
+	assert.False(t, state.FullSyncStartedAt.Valid)
+	assert.True(t, state.UpdatedCursor.Time.Equal(updatedAt.Add(3*time.Minute)))
+
+	// catalog is used for listing after the full sync
+	requests := len(fake.Requests())
+	list := <-service.GetSongsList(internal.GetSongsListRequest{SearchKeyword: null.StringFrom("song 3"), Page: 1,
+		Size: 10}, gormDb, nil, context.TODO())
+	assert.Nil(t, list.Error)
+	assert.Equal(t, int64(1), list.Response.TotalCount)
+	assert.Len(t, fake.Requests(), requests)
+
+	// incremental sync picks updated songs only
+	fake.PutSong(FakeSong{Id: "1", Title: "song 1 remastered", Artist: "artist", Duration: 100,
+		UpdatedAt: updatedAt.Add(10 * time.Minute)})
+	fake.RemoveSong("2")
+
+	assert.Nil(t, service.SyncCatalog(gormDb, nil, context.TODO()))
+
+	var song1 database.SoundStripeSong
+	assert.Nil(t, gormDb.Where("external_id = ?", "1").Find(&song1).Error)
+	assert.Equal(t, "song 1 remastered", song1.Title)
+
+	var song2 database.SoundStripeSong
+	assert.Nil(t, gormDb.Where("external_id = ?", "2").Find(&song2).Error)
+	assert.False(t, song2.RemovedAt.Valid)
+
+	// full sync detects removed song
+	assert.Nil(t, gormDb.Model(&database.SoundStripeSyncState{}).Where("id = ?", syncStateId).
+		Update("last_full_sync_at", time.Now().Add(-48*time.Hour)).Error)
+
+	assert.Nil(t, service.SyncCatalog(gormDb, nil, context.TODO()))
+
+	assert.Nil(t, gormDb.Where("external_id = ?", "2").Find(&song2).Error)
+	assert.True(t, song2.RemovedAt.Valid)
+	assert.Equal(t, database.LicenseStatusRemoved, song2.LicenseStatus)
+
+	var count int64
+	assert.Nil(t, gormDb.Model(&database.Song{}).Where("id = ?", librarySong.Id).Count(&count).Error)
+	assert.Equal(t, int64(0), count)
+
+	assert.Nil(t, gormDb.Where("id = ?", playlist.Id).Find(&playlist).Error)
+	assert.Equal(t, 0, playlist.SongsCount)
+}
+
+func TestService_SyncCatalogRateLimited(t *testing.T) {
+	flush(t)
+
+	fake := NewFakeServer()
+	defer fake.Close()
+
+	var sleeps []time.Duration
+	service := newTestService(fake, &sleeps)
+
+	fake.PutSong(FakeSong{Id: "1", Title: "song", Artist: "artist", UpdatedAt: time.Now()})
+
+	// more 429 responses than retries, full sync is resumed by the next run
+	fake.RateLimit(3, "")
+
+	assert.Nil(t, service.SyncCatalog(gormDb, nil, context.TODO()))
+
+	state, err := service.syncState(gormDb)
+	assert.Nil(t, err)
+	assert.True(t, state.FullSyncStartedAt.Valid)
+	assert.False(t, state.LastFullSyncAt.Valid)
+	assert.Len(t, fake.Requests(), 3)
+	assert.NotEmpty(t, sleeps)
+
+	assert.Nil(t, service.SyncCatalog(gormDb, nil, context.TODO()))
+
+	state, err = service.syncState(gormDb)
+	assert.Nil(t, err)
+	assert.True(t, state.LastFullSyncAt.Valid)
+}
+
+func TestService_GetSongUrl(t *testing.T) {
+	flush(t)
+
+	fake := NewFakeServer()
+	defer fake.Close()
+
+	var sleeps []time.Duration
+	service := newTestService(fake, &sleeps)
+
+	fake.PutSong(FakeSong{Id: "1", Title: "song", Artist: "artist", UpdatedAt: time.Now()})
+
+	files, err := service.GetSongUrl("1", gormDb, nil, context.TODO())
+	assert.Nil(t, err)
+	assert.Contains(t, files["mp3"], "Expires=")
+
+	// cached url is returned without api request
+	requests := len(fake.Requests())
+	cached, err := service.GetSongUrl("1", gormDb, nil, context.TODO())
+	assert.Nil(t, err)
+	assert.Equal(t, files, cached)
+	assert.Len(t, fake.Requests(), requests)
+
+	// url expiring sooner than refresh margin is refreshed
+	assert.Nil(t, gormDb.Model(&database.SoundStripeSong{}).Where("external_id = ?", "1").
+		Update("files_expire_at", time.Now().Add(time.Minute)).Error)
+
+	_, err = service.GetSongUrl("1", gormDb, nil, context.TODO())
+	assert.Nil(t, err)
+	assert.Len(t, fake.Requests(), requests+1)
+
+	// rate limited request is retried after Retry-After
+	assert.Nil(t, gormDb.Model(&database.SoundStripeSong{}).Where("external_id = ?", "1").
+		Update("files_expire_at", nil).Error)
+	fake.RateLimit(1, "3")
+
+	_, err = service.GetSongUrl("1", gormDb, nil, context.TODO())
+	assert.Nil(t, err)
+	assert.Greater(t, lo.Max(sleeps), 2*time.Second)
+
+	librarySong := database.Song{Source: database.SongSourceSoundStripe, ExternalId: "2"}
+	assert.Nil(t, gormDb.Create(&librarySong).Error)
+
+	_, err = service.GetSongUrl("2", gormDb, nil, context.TODO())
+	assert.True(t, errors.Is(err, ErrSongRemoved))
+
+	var song database.SoundStripeSong
+	assert.Nil(t, gormDb.Where("external_id = ?", "2").Find(&song).Error)
+	assert.True(t, song.RemovedAt.Valid)
+
+	var count int64
+	assert.Nil(t, gormDb.Model(&database.Song{}).Where("id = ?", librarySong.Id).Count(&count).Error)
+	assert.Equal(t, int64(0), count)
+}
+
+func TestService_FilesExpireAt(t *testing.T) {
+	service := &Service{cfg: configs.SoundStripeConfig{FileUrlTtlMinutes: 30}}
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, now.Add(30*time.Minute), service.filesExpireAt(map[string]string{"mp3": "https://cdn/song.mp3"}, now))
+
+	assert.Equal(t, now.Add(5*time.Minute), service.filesExpireAt(map[string]string{
+		"mp3": fmt.Sprintf("https://cdn/song.mp3?Expires=%v&Signature=abc", now.Add(10*time.Minute).Unix()),
+		"wav": "https://s3/song.wav?X-Amz-Date=20261019T115500Z&X-Amz-Expires=600",
+	}, now))
+}
+
+func TestService_RetryDelay(t *testing.T) {
+	service := &Service{cfg: configs.SoundStripeConfig{RetryBaseMs: 500, MaxRetryWaitSec: 3}}
+
+	assert.Equal(t, 2*time.Second, service.retryDelay("2", 0))
+	assert.Equal(t, 500*time.Millisecond, service.retryDelay("", 0))
+	assert.Equal(t, 2*time.Second, service.retryDelay("", 2))
+	assert.Equal(t, 3*time.Second, service.retryDelay("", 5))
+	assert.Equal(t, time.Duration(0), service.retryDelay(time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat), 0))
+}
@@ -0,0 +1,225 @@
+package soundstripe
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FakeSong is a song served by FakeServer
+type FakeSong struct {
+	Id        string
+	Title     string
+	Artist    string
+	Genres    []string
+	Duration  float64
+	UpdatedAt time.Time
+}
+
+// FakeServer is SoundStripe api for tests. It serves songs list and single songs in json:api format with signed file
+// urls expiring after UrlTtl, and responds 429 to the next rate limited requests
+type FakeServer struct {
+	*httptest.Server
+
+	UrlTtl time.Duration
+
+	mutex       sync.Mutex
+	songs       map[string]FakeSong
+	rateLimited int
+	retryAfter  string
+	requests    []string
+}
+
+func NewFakeServer() *FakeServer {
+	f := &FakeServer{
+		UrlTtl: time.Hour,
+		songs:  map[string]FakeSong{},
+	}
+
+	f.Server = httptest.NewServer(http.HandlerFunc(f.handle))
+
+	return f
+}
+
+func (f *FakeServer) PutSong(song FakeSong) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.songs[song.Id] = song
+}
+
+func (f *FakeServer) RemoveSong(id string) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	delete(f.songs, id)
+}
+
+// RateLimit responds 429 with Retry-After header to the next count requests
+func (f *FakeServer) RateLimit(count int, retryAfter string) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.rateLimited = count
+	f.retryAfter = retryAfter
+}
+
+// Requests returns paths with query of received requests
+func (f *FakeServer) Requests() []string {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	return append([]string{}, f.requests...)
+}
+
+func (f *FakeServer) handle(w http.ResponseWriter, r *http.Request) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.requests = append(f.requests, r.URL.RequestURI())
+
+	if f.rateLimited > 0 {
+		f.rateLimited--
+
+		if len(f.retryAfter) > 0 {
+			w.Header().Set("Retry-After", f.retryAfter)
+		}
+
+		w.WriteHeader(http.StatusTooManyRequests)
+		return
+	}
+
+	path := strings.Trim(r.URL.Path, "/")
+
+	if path == "songs" {
+		f.writeSongs(w, r)
+		return
+	}
+
+	if id := strings.TrimPrefix(path, "songs/"); id != path {
+		song, ok := f.songs[id]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		data, included := f.toJson(song)
+		writeFakeJson(w, map[string]interface{}{
+			"data":     data,
+			"included": included,
+		})
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNotFound)
+}
+
+func (f *FakeServer) writeSongs(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	var songs []FakeSong
+	for _, song := range f.songs {
+		if q := query.Get("filter[q]"); len(q) > 0 && !strings.Contains(strings.ToLower(song.Title), strings.ToLower(q)) {
+			continue
+		}
+
+		songs = append(songs, song)
+	}
+
+	sort.Slice(songs, func(i, j int) bool {
+		if query.Get("sort") == "-updated_at" && !songs[i].UpdatedAt.Equal(songs[j].UpdatedAt) {
+			return songs[i].UpdatedAt.After(songs[j].UpdatedAt)
+		}
+
+		return songs[i].Id < songs[j].Id
+	})
+
+	size, _ := strconv.Atoi(query.Get("page[size]"))
+	if size <= 0 {
+		size = 20
+	}
+
+	page, _ := strconv.Atoi(query.Get("page[number]"))
+	if page <= 0 {
+		page = 1
+	}
+
+	data := make([]interface{}, 0)
+	included := make([]interface{}, 0)
+
+	for i := (page - 1) * size; i < len(songs) && i < page*size; i++ {
+		songData, songIncluded := f.toJson(songs[i])
+		data = append(data, songData)
+		included = append(included, songIncluded...)
+	}
+
+	writeFakeJson(w, map[string]interface{}{
+		"data":     data,
+		"included": included,
+		"links": map[string]interface{}{
+			"meta": map[string]interface{}{
+				"total_count": len(songs),
+			},
+		},
+	})
+}
+
+func (f *FakeServer) toJson(song FakeSong) (interface{}, []interface{}) {
+	artistId := fmt.Sprintf("artist_%v", song.Id)
+	fileId := fmt.Sprintf("file_%v", song.Id)
+	expires := time.Now().Add(f.UrlTtl).Unix()
+
+	data := map[string]interface{}{
+		"id":   song.Id,
+		"type": "songs",
+		"attributes": map[string]interface{}{
+			"title":      song.Title,
+			"tags":       map[string]interface{}{"genre": song.Genres},
+			"updated_at": song.UpdatedAt.UTC().Format(time.RFC3339),
+		},
+		"relationships": map[string]interface{}{
+			"artists": map[string]interface{}{
+				"data": []interface{}{map[string]interface{}{"id": artistId, "type": IncludedTypeArtists}},
+			},
+			"audio_files": map[string]interface{}{
+				"data": []interface{}{map[string]interface{}{"id": fileId, "type": IncludedTypeAudioFiles}},
+			},
+		},
+	}
+
+	included := []interface{}{
+		map[string]interface{}{
+			"id":   artistId,
+			"type": IncludedTypeArtists,
+			"attributes": map[string]interface{}{
+				"name":  song.Artist,
+				"image": fmt.Sprintf("%v/images/%v.jpg", f.URL, song.Id),
+			},
+		},
+		map[string]interface{}{
+			"id":   fileId,
+			"type": IncludedTypeAudioFiles,
+			"attributes": map[string]interface{}{
+				"duration": song.Duration,
+				"versions": map[string]string{
+					"mp3": fmt.Sprintf("%v/files/%v.mp3?Expires=%v", f.URL, song.Id, expires),
+				},
+			},
+		},
+	}
+
+	return data, included
+}
+
+func writeFakeJson(w http.ResponseWriter, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,61 @@
+package soundstripe
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var ErrRateLimited = errors.New("soundstripe rate limit exceeded")
+var ErrSongNotFound = errors.New("song not found in soundstripe")
+var ErrSongRemoved = errors.New("song was removed from soundstripe")
+
+// retryDelay returns wait time of rate limited request from Retry-After header, which is either seconds or http date.
+// Without the header backoff is doubled on every attempt
+func (s *Service) retryDelay(retryAfter string, attempt int) time.Duration {
+	var delay time.Duration
+
+	retryAfter = strings.TrimSpace(retryAfter)
+
+	if seconds, err := strconv.Atoi(retryAfter); err == nil && seconds >= 0 {
+		delay = time.Duration(seconds) * time.Second
+	} else if date, err := http.ParseTime(retryAfter); err == nil {
+		delay = time.Until(date)
+	} else {
+		delay = time.Duration(float64(s.cfg.RetryBaseMs)*math.Pow(2, float64(attempt))) * time.Millisecond
+	}
+
+	if delay < 0 {
+		delay = 0
+	}
+
+	if maxWait := time.Duration(s.cfg.MaxRetryWaitSec) * time.Second; maxWait > 0 && delay > maxWait {
+		delay = maxWait
+	}
+
+	return delay
+}
+
+// setRateLimited blocks all requests of the service for delay, requests are not sent while the limit is exceeded
+func (s *Service) setRateLimited(delay time.Duration) {
+	s.rateLimitMutex.Lock()
+	defer s.rateLimitMutex.Unlock()
+
+	if until := time.Now().Add(delay); until.After(s.blockedUntil) {
+		s.blockedUntil = until
+	}
+}
+
+func (s *Service) waitRateLimit() {
+	s.rateLimitMutex.Lock()
+	wait := time.Until(s.blockedUntil)
+	s.rateLimitMutex.Unlock()
+
+	if wait > 0 {
+		s.sleep(wait)
+	}
+}
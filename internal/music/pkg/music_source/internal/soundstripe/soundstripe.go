@@ -19,24 +19,34 @@ import (
 	"gorm.io/gorm"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 )
 
 type Service struct {
+	cfg        configs.SoundStripeConfig
 	apiUrl     string
 	apiToken   string
 	timeout    time.Duration
 	workerPool *workerpool.WorkerPool
 	songsCache *cache.Cache
+	client     *fasthttp.Client
+	sleep      func(d time.Duration)
+
+	rateLimitMutex sync.Mutex
+	blockedUntil   time.Time // requests wait until this time after 429 response
 }
 
-func NewService(cfg configs.SoundStripeConfig) internal.IMusicStorageAdapter {
+func NewService(cfg configs.SoundStripeConfig) *Service {
 	return &Service{
+		cfg:        cfg,
 		apiUrl:     cfg.ApiUrl,
 		apiToken:   cfg.ApiToken,
 		timeout:    time.Second * time.Duration(cfg.MaxTimeout),
 		workerPool: workerpool.New(cfg.MaxWorkers),
 		songsCache: cache.New(60*time.Minute, 61*time.Minute),
+		client:     &fasthttp.Client{},
+		sleep:      time.Sleep,
 	}
 }
 
@@ -62,7 +72,30 @@ func (s *Service) SyncSongsList(externalSongsIds []string, tx *gorm.DB, apmTrans
 	var songsToAdd []database.Song
 	chans := make([]chan internal.GetSongResponseChan, 0)
 
+	var catalogSongs []database.SoundStripeSong
+	if err := tx.Where("external_id in ? and removed_at is null", missing).Find(&catalogSongs).Error; err != nil {
+		return errors.WithStack(err)
+	}
+
+	catalogMapped := map[string]database.SoundStripeSong{}
+	for _, song := range catalogSongs {
+		catalogMapped[song.ExternalId] = song
+	}
+
 	for _, songId := range missing {
+		if song, ok := catalogMapped[songId]; ok {
+			songsToAdd = append(songsToAdd, database.Song{
+				Source:     database.SongSourceSoundStripe,
+				ExternalId: songId,
+				Title:      song.Title,
+				Artist:     song.Artist,
+				ImageUrl:   song.ImageUrl,
+				Genre:      song.Genre,
+				Duration:   song.Duration,
+			})
+			continue
+		}
+
 		cachedItem, hasCachedItem := s.songsCache.Get(songId)
 		if hasCachedItem {
 			song := cachedItem.(internal.SongModel)
@@ -81,6 +114,7 @@ func (s *Service) SyncSongsList(externalSongsIds []string, tx *gorm.DB, apmTrans
 		chans = append(chans, s.getSong(songId, apmTransaction, ctx))
 	}
 
+	var fetched []internal.SongModel
 	var internalErrors []error
 	if len(chans) > 0 {
 		for _, ch := range chans {
@@ -100,6 +134,7 @@ func (s *Service) SyncSongsList(externalSongsIds []string, tx *gorm.DB, apmTrans
 				Duration:   result.Song.Duration,
 			})
 
+			fetched = append(fetched, result.Song)
 			s.songsCache.Set(result.Song.ExternalId, result.Song, cache.DefaultExpiration)
 		}
 	}
@@ -108,6 +143,14 @@ func (s *Service) SyncSongsList(externalSongsIds []string, tx *gorm.DB, apmTrans
 		if err := tx.Create(&songsToAdd).Error; err != nil {
 			return errors.WithStack(err)
 		}
+
+		if err := s.upsertCatalog(fetched, time.Now().UTC(), tx); err != nil {
+			return err
+		}
+
+		if err := s.trackLicenses(songsToAdd, tx); err != nil {
+			return err
+		}
 	}
 
 	if len(internalErrors) > 0 {
@@ -121,8 +164,19 @@ func (s *Service) SyncSongsList(externalSongsIds []string, tx *gorm.DB, apmTrans
 	return nil
 }
 
+// GetSongsList lists songs from the local catalog after it was fully synced, otherwise SoundStripe api is called
 func (s *Service) GetSongsList(req internal.GetSongsListRequest, db *gorm.DB, apmTx *apm.Transaction, ctx context.Context) chan internal.GetSongsListResponseChan {
 	resChan := make(chan internal.GetSongsListResponseChan, 2)
+
+	if ready, err := s.catalogReady(db); err != nil {
+		apm_helper.LogError(err, ctx)
+	} else if ready {
+		resp, err := s.listCatalog(req, db)
+		resChan <- internal.GetSongsListResponseChan{Error: err, Response: resp}
+
+		return resChan
+	}
+
 	s.workerPool.Submit(func() {
 		finalResponse := internal.GetSongsListResponseChan{}
 
@@ -204,6 +258,7 @@ func (s *Service) mapToOurModel(ssResp soundstripeSongsResp, ctx context.Context
 			Genre:      strings.Join(song.Attributes.Tags.Genre, ","),
 			Duration:   audioFiles.Attributes.Duration,
 			Files:      audioFiles.Attributes.Versions,
+			UpdatedAt:  song.Attributes.UpdatedAt,
 		}
 
 		songs = append(songs, ss)
@@ -213,12 +268,42 @@ func (s *Service) mapToOurModel(ssResp soundstripeSongsResp, ctx context.Context
 	return songs
 }
 
+// GetSongUrl returns signed urls of song versions. Urls are cached in the catalog until they are about to expire,
+// songs removed upstream are removed from the library
 func (s *Service) GetSongUrl(externalSongId string, db *gorm.DB, apmTransaction *apm.Transaction, ctx context.Context) (map[string]string, error) {
+	var catalogSong database.SoundStripeSong
+	if err := db.Where("external_id = ?", externalSongId).Find(&catalogSong).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if catalogSong.RemovedAt.Valid {
+		return nil, ErrSongRemoved
+	}
+
+	now := time.Now().UTC()
+
+	if len(catalogSong.Files) > 0 && catalogSong.FilesExpireAt.Valid &&
+		catalogSong.FilesExpireAt.Time.After(now.Add(s.refreshMargin())) {
+		return catalogSong.Files, nil
+	}
+
 	songResp := <-s.getSong(externalSongId, apmTransaction, ctx)
 	if songResp.Error != nil {
+		if errors.Is(songResp.Error, ErrSongNotFound) {
+			if err := s.removeSongs([]string{externalSongId}, db); err != nil {
+				apm_helper.LogError(err, ctx)
+			}
+
+			return nil, ErrSongRemoved
+		}
+
 		return nil, songResp.Error
 	}
 
+	if err := s.upsertCatalog([]internal.SongModel{songResp.Song}, now, db); err != nil {
+		apm_helper.LogError(err, ctx)
+	}
+
 	return songResp.Song.Files, nil
 }
 
@@ -263,9 +348,10 @@ func (s *Service) getSong(externalId string, apmTransaction *apm.Transaction, ct
 	return resChan
 }
 
+// makeApiRequestInternal calls SoundStripe api. Rate limited requests are retried after Retry-After or exponential
+// backoff, other requests of the service wait until the limit is over
 func (s *Service) makeApiRequestInternal(apiMethod string, httpMethod string, body []byte, apmTransaction *apm.Transaction) ([]byte, error) {
 	url := fmt.Sprintf("%v/%v", s.apiUrl, apiMethod)
-	cl := &fasthttp.Client{}
 
 	httpReq := fasthttp.AcquireRequest()
 	defer fasthttp.ReleaseRequest(httpReq)
@@ -282,16 +368,31 @@ func (s *Service) makeApiRequestInternal(apiMethod string, httpMethod string, bo
 
 	utils.AppendBrowserHeaders(httpReq)
 
-	err := sendHttpRequestWithClient(cl, httpReq, httpRes, apmTransaction, s.timeout, true)
-	if err != nil {
-		return nil, errors.WithStack(err)
-	}
+	for attempt := 0; ; attempt++ {
+		s.waitRateLimit()
 
-	if httpRes.StatusCode() != fasthttp.StatusOK {
-		return nil, errors.New(fmt.Sprintf("Soundstipe HTTP CODE %v. URL %v", httpRes.StatusCode(), url))
-	}
+		httpRes.Reset()
+
+		err := sendHttpRequestWithClient(s.client, httpReq, httpRes, apmTransaction, s.timeout, true)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		switch httpRes.StatusCode() {
+		case fasthttp.StatusOK:
+			return utils.UnpackFastHttpBody(httpRes)
+		case fasthttp.StatusNotFound:
+			return nil, errors.Wrap(ErrSongNotFound, url)
+		case fasthttp.StatusTooManyRequests:
+			s.setRateLimited(s.retryDelay(string(httpRes.Header.Peek("Retry-After")), attempt))
 
-	return utils.UnpackFastHttpBody(httpRes)
+			if attempt >= s.cfg.MaxRetries {
+				return nil, errors.Wrap(ErrRateLimited, url)
+			}
+		default:
+			return nil, errors.New(fmt.Sprintf("Soundstipe HTTP CODE %v. URL %v", httpRes.StatusCode(), url))
+		}
+	}
 }
 
 func sendHttpRequestWithClient(client *fasthttp.Client, request *fasthttp.Request, response *fasthttp.Response, parentTx *apm.Transaction,
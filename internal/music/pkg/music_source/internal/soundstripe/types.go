@@ -1,5 +1,7 @@
 package soundstripe
 
+import "gopkg.in/guregu/null.v4"
+
 type soundstripeSongsResp struct {
 	MusicData  []musicData    `json:"data"`
 	Pagination pagination     `json:"links"`
@@ -50,8 +52,9 @@ type T struct {
 }
 
 type attributes struct {
-	Title string `json:"title"`
-	Tags  tags   `json:"tags"`
+	Title     string    `json:"title"`
+	Tags      tags      `json:"tags"`
+	UpdatedAt null.Time `json:"updated_at"`
 }
 
 type tags struct {
@@ -30,6 +30,7 @@ type SongModel struct {
 	Files        map[string]string   `json:"files"`
 	DateUploaded null.Time           `json:"date_uploaded"`
 	Playlists    []PlaylistModel     `json:"playlists"`
+	UpdatedAt    null.Time           `json:"-"` // upstream update time, used by catalog sync
 }
 
 type PlaylistModel struct {
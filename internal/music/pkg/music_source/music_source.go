@@ -3,32 +3,51 @@ package music_source
 import (
 	"context"
 	"fmt"
+	"github.com/RichardKnop/machinery/v1"
+	"github.com/RichardKnop/machinery/v1/tasks"
 	"github.com/digitalmonsters/go-common/apm_helper"
+	"github.com/digitalmonsters/go-common/boilerplate"
 	"github.com/digitalmonsters/music/configs"
 	"github.com/digitalmonsters/music/pkg/database"
 	"github.com/digitalmonsters/music/pkg/music_source/internal"
 	"github.com/digitalmonsters/music/pkg/music_source/internal/lit"
 	"github.com/digitalmonsters/music/pkg/music_source/internal/soundstripe"
+	"github.com/digitalmonsters/music/utils"
 	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
 	"go.elastic.co/apm"
 	"gopkg.in/guregu/null.v4"
 	"gorm.io/gorm"
 	"time"
 )
 
+const catalogSyncTaskName = "music:soundstripe:sync"
+
 type MusicStorageService struct {
 	cfg             *configs.Settings
 	implementations map[database.SongSource]internal.IMusicStorageAdapter
+	soundStripe     *soundstripe.Service
 }
 
-func NewMusicStorageService(configuration *configs.Settings) *MusicStorageService {
-	return &MusicStorageService{
+func NewMusicStorageService(jobber *machinery.Server, configuration *configs.Settings) *MusicStorageService {
+	soundStripe := soundstripe.NewService(*configuration.SoundStripe)
+
+	s := &MusicStorageService{
 		cfg: configuration,
 		implementations: map[database.SongSource]internal.IMusicStorageAdapter{
 			database.SongSourceOwnStorage:  lit.NewService(),
-			database.SongSourceSoundStripe: soundstripe.NewService(*configuration.SoundStripe),
+			database.SongSourceSoundStripe: soundStripe,
 		},
+		soundStripe: soundStripe,
+	}
+
+	if boilerplate.GetCurrentEnvironment() != boilerplate.Ci && configuration.SoundStripe.SyncIntervalMinutes > 0 {
+		if err := s.registerCatalogSyncTask(jobber); err != nil {
+			log.Fatal().Err(err).Msg("[Music] can not register soundstripe catalog sync task")
+		}
 	}
+
+	return s
 }
 
 func (s *MusicStorageService) findMusicInPlaylists(playlistIds []int64, source database.SongSource, page int, size int, db *gorm.DB, ctx context.Context) (*ListMusicResponse, error) {
@@ -193,3 +212,24 @@ func (s *MusicStorageService) getImplementation(implType database.SongSource) (i
 
 	return nil, errors.New(fmt.Sprintf("muscic adapter [%v] not implemented", implType))
 }
+
+func (s *MusicStorageService) registerCatalogSyncTask(jobber *machinery.Server) error {
+	if err := jobber.RegisterTask(catalogSyncTaskName, func() error {
+		var apmTransaction = apm_helper.StartNewApmTransaction(catalogSyncTaskName, "task", nil, nil)
+		defer apmTransaction.End()
+
+		ctx := apm.ContextWithTransaction(context.Background(), apmTransaction)
+
+		if err := s.soundStripe.SyncCatalog(database.GetDb(database.DbTypeMaster), apmTransaction, ctx); err != nil {
+			apm_helper.LogError(err, ctx)
+			return err
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	return utils.RegisterPeriodicTask(jobber, fmt.Sprintf("@every %vm", s.cfg.SoundStripe.SyncIntervalMinutes),
+		catalogSyncTaskName, []tasks.Arg{}, true)
+}
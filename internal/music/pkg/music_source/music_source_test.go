@@ -22,7 +22,7 @@ func TestMain(m *testing.M) {
 }
 
 func TestNewMusicStorageService(t *testing.T) {
-	service := NewMusicStorageService(&config)
+	service := NewMusicStorageService(nil, &config)
 
 	if err := boilerplate_testing.FlushPostgresTables(config.MasterDb, []string{"public.playlists", "public.songs", "public.playlist_song_relations"}, nil, t); err != nil {
 		t.Fatal(err)
@@ -0,0 +1,157 @@
+package processing
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os/exec"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// waveformSampleRate is enough to draw the player waveform and keeps decoding cheap
+const waveformSampleRate = 8000
+
+// transcode converts source into mp3 with standard bitrate and normalized loudness. When duration > 0 only
+// [offset, offset+duration] part of the source is encoded
+func (s *Service) transcode(source string, target string, offset float64, duration float64, ctx context.Context) error {
+	args := []string{"-y", "-v", "error"}
+
+	if offset > 0 {
+		args = append(args, "-ss", formatSeconds(offset))
+	}
+
+	args = append(args, "-i", source)
+
+	if duration > 0 {
+		args = append(args, "-t", formatSeconds(duration))
+	}
+
+	args = append(args,
+		"-vn", "-map_metadata", "-1",
+		"-af", fmt.Sprintf("loudnorm=I=%v:TP=%v:LRA=11", s.cfg.LoudnessTarget, s.cfg.TruePeak),
+		"-ar", strconv.Itoa(s.cfg.SampleRate),
+		"-ac", "2",
+		"-c:a", "libmp3lame",
+		"-b:a", s.cfg.Bitrate,
+		target,
+	)
+
+	if out, err := exec.CommandContext(ctx, s.cfg.FfmpegPath, args...).CombinedOutput(); err != nil {
+		return errors.Wrap(err, string(out))
+	}
+
+	return nil
+}
+
+// waveform decodes source into mono pcm and returns normalized peaks of it
+func (s *Service) waveform(source string, ctx context.Context) ([]float64, error) {
+	cmd := exec.CommandContext(ctx, s.cfg.FfmpegPath, "-v", "error", "-i", source, "-vn",
+		"-ac", "1", "-ar", strconv.Itoa(waveformSampleRate), "-f", "s16le", "-")
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	samples, readErr := readSamples(bufio.NewReader(stdout))
+
+	if err := cmd.Wait(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if readErr != nil {
+		return nil, readErr
+	}
+
+	return computePeaks(samples, s.cfg.WaveformPoints), nil
+}
+
+func readSamples(r io.Reader) ([]int16, error) {
+	var samples []int16
+	buf := make([]byte, 2)
+
+	for {
+		if _, err := io.ReadFull(r, buf); err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				return samples, nil
+			}
+
+			return nil, errors.WithStack(err)
+		}
+
+		samples = append(samples, int16(binary.LittleEndian.Uint16(buf)))
+	}
+}
+
+// computePeaks splits samples into points buckets and returns max amplitude of every bucket scaled to [0, 1]
+func computePeaks(samples []int16, points int) []float64 {
+	if points <= 0 || len(samples) == 0 {
+		return []float64{}
+	}
+
+	if points > len(samples) {
+		points = len(samples)
+	}
+
+	peaks := make([]float64, points)
+	bucketSize := float64(len(samples)) / float64(points)
+	maxPeak := 0.0
+
+	for i := 0; i < points; i++ {
+		from := int(float64(i) * bucketSize)
+		to := int(float64(i+1) * bucketSize)
+
+		if i == points-1 {
+			to = len(samples)
+		}
+
+		for _, sample := range samples[from:to] {
+			peaks[i] = math.Max(peaks[i], math.Abs(float64(sample)))
+		}
+
+		maxPeak = math.Max(maxPeak, peaks[i])
+	}
+
+	if maxPeak == 0 {
+		return peaks
+	}
+
+	for i := range peaks {
+		peaks[i] = math.Round(peaks[i]/maxPeak*1000) / 1000
+	}
+
+	return peaks
+}
+
+// previewBounds returns offset and duration of the short version. Offset is moved back when preview does not fit
+// into the song
+func previewBounds(offset float64, songDuration float64, minDuration float64, maxDuration float64) (float64, float64, error) {
+	if songDuration < minDuration {
+		return 0, 0, errors.New("song duration is less than min song duration")
+	}
+
+	duration := math.Min(maxDuration, songDuration)
+
+	if offset < 0 {
+		offset = 0
+	}
+
+	if offset+duration > songDuration {
+		offset = songDuration - duration
+	}
+
+	return offset, duration, nil
+}
+
+func formatSeconds(v float64) string {
+	return strconv.FormatFloat(v, 'f', 3, 64)
+}
@@ -0,0 +1,78 @@
+package processing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+)
+
+var allowedCodecs = []string{"mp3", "aac", "flac", "alac", "vorbis", "opus", "pcm_s16le", "pcm_s24le", "pcm_f32le"}
+
+type probeResult struct {
+	Streams []probeStream `json:"streams"`
+	Format  probeFormat   `json:"format"`
+}
+
+type probeStream struct {
+	CodecType string `json:"codec_type"`
+	CodecName string `json:"codec_name"`
+}
+
+type probeFormat struct {
+	FormatName string `json:"format_name"`
+	Duration   string `json:"duration"`
+}
+
+type sourceInfo struct {
+	Codec    string
+	Duration float64
+}
+
+// probe reads container info of the source file with ffprobe and validates that it contains a supported audio stream
+func (s *Service) probe(path string, ctx context.Context) (*sourceInfo, error) {
+	out, err := exec.CommandContext(ctx, s.cfg.FfprobePath, "-v", "error", "-print_format", "json",
+		"-show_format", "-show_streams", path).Output()
+	if err != nil {
+		return nil, errors.Wrap(err, "can not read audio file")
+	}
+
+	var result probeResult
+	if err := json.Unmarshal(out, &result); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return parseProbeResult(result, float64(s.appConfig.GetValues().MUSIC_FULL_VERSION_MAX_DURATION))
+}
+
+func parseProbeResult(result probeResult, maxDuration float64) (*sourceInfo, error) {
+	audio, ok := lo.Find(result.Streams, func(item probeStream) bool {
+		return item.CodecType == "audio"
+	})
+
+	if !ok {
+		return nil, errors.New("file does not contain audio stream")
+	}
+
+	if !lo.Contains(allowedCodecs, audio.CodecName) {
+		return nil, errors.New(fmt.Sprintf("audio codec %v is not supported", audio.CodecName))
+	}
+
+	duration, err := strconv.ParseFloat(result.Format.Duration, 64)
+	if err != nil || duration <= 0 {
+		return nil, errors.New("can not detect song duration")
+	}
+
+	if maxDuration > 0 && duration > maxDuration {
+		return nil, errors.New("song duration is greater than max song duration")
+	}
+
+	return &sourceInfo{
+		Codec:    audio.CodecName,
+		Duration: duration,
+	}, nil
+}
@@ -0,0 +1,356 @@
+package processing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/RichardKnop/machinery/v1"
+	"github.com/RichardKnop/machinery/v1/tasks"
+	"github.com/digitalmonsters/go-common/apm_helper"
+	"github.com/digitalmonsters/go-common/application"
+	"github.com/digitalmonsters/go-common/boilerplate"
+	"github.com/digitalmonsters/go-common/s3"
+	"github.com/digitalmonsters/music/configs"
+	"github.com/digitalmonsters/music/pkg/database"
+	"github.com/digitalmonsters/music/utils"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/guregu/null.v4"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const taskName = "music:audio:process"
+
+// Service transcodes creator uploads in background. Source file is stored to s3 on submit, machinery task converts
+// it into full and short mp3 versions and waveform json, then updates linked creator song
+type Service struct {
+	jobber    *machinery.Server
+	cfg       configs.AudioProcessingConfig
+	s3Cfg     boilerplate.S3Config
+	uploader  s3.IUploader
+	appConfig *application.Configurator[configs.AppConfig]
+}
+
+func NewService(jobber *machinery.Server, cfg configs.Settings, appConfig *application.Configurator[configs.AppConfig]) *Service {
+	s := &Service{
+		jobber:    jobber,
+		cfg:       cfg.AudioProcessing,
+		s3Cfg:     cfg.S3,
+		uploader:  s3.NewUploader(&cfg.S3),
+		appConfig: appConfig,
+	}
+
+	if boilerplate.GetCurrentEnvironment() != boilerplate.Ci {
+		if err := s.registerTask(); err != nil {
+			log.Fatal().Err(err).Msg("[Music] can not register audio processing task")
+		}
+	}
+
+	return s
+}
+
+// Submit stores source file and schedules its processing. PreviewOffset is a creator chosen start of short version
+func (s *Service) Submit(userId int64, file multipart.File, header *multipart.FileHeader, previewOffset float64,
+	db *gorm.DB, ctx context.Context) (*database.SongProcessingJob, error) {
+	if s.cfg.MaxFileSizeMb > 0 && header.Size > int64(s.cfg.MaxFileSizeMb)<<20 {
+		return nil, errors.New("file is too large")
+	}
+
+	if previewOffset < 0 {
+		return nil, errors.New("preview offset should be positive")
+	}
+
+	sourcePath := fmt.Sprintf("creator/source/%v/%v%v", userId, uuid.New().String(),
+		strings.ToLower(filepath.Ext(header.Filename)))
+
+	if err := s.uploader.UploadObjectFromReader(sourcePath, file, "application/octet-stream"); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return s.createJob(userId, sourcePath, header.Filename, previewOffset, db)
+}
+
+// SubmitStored schedules processing of a source file which is already uploaded to s3, e.g. by upload session
+func (s *Service) SubmitStored(userId int64, sourcePath string, filename string, previewOffset float64,
+	db *gorm.DB) (*database.SongProcessingJob, error) {
+	if previewOffset < 0 {
+		return nil, errors.New("preview offset should be positive")
+	}
+
+	return s.createJob(userId, sourcePath, filename, previewOffset, db)
+}
+
+func (s *Service) createJob(userId int64, sourcePath string, filename string, previewOffset float64,
+	db *gorm.DB) (*database.SongProcessingJob, error) {
+	job := database.SongProcessingJob{
+		UserId:         userId,
+		Status:         database.ProcessingStatusPending,
+		SourcePath:     sourcePath,
+		SourceFilename: filename,
+		PreviewOffset:  previewOffset,
+		CreatedAt:      time.Now().UTC(),
+		UpdatedAt:      time.Now().UTC(),
+	}
+
+	if err := db.Create(&job).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if boilerplate.GetCurrentEnvironment() != boilerplate.Ci {
+		if _, err := utils.SendTask(s.jobber, taskName, []tasks.Arg{{Type: "int64", Value: job.Id}}, false); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+
+	return &job, nil
+}
+
+// GetJob returns processing job of user, client polls it until status is completed or failed
+func (s *Service) GetJob(req GetJobRequest, userId int64, db *gorm.DB) (*database.SongProcessingJob, error) {
+	var job database.SongProcessingJob
+	if err := db.Where("id = ? and user_id = ?", req.Id, userId).Find(&job).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if job.Id == 0 {
+		return nil, errors.New("processing job not found")
+	}
+
+	return &job, nil
+}
+
+func (s *Service) registerTask() error {
+	return s.jobber.RegisterTask(taskName, func(jobId int64) error {
+		var apmTransaction = apm_helper.StartNewApmTransaction(taskName, "task", nil, nil)
+		defer apmTransaction.End()
+		ctx := boilerplate.CreateCustomContext(context.Background(), apmTransaction, log.Logger)
+
+		db := database.GetDb(database.DbTypeMaster).WithContext(ctx)
+
+		if err := s.process(jobId, db, ctx); err != nil {
+			utils.CaptureApmErrorFromTransaction(err, ctx)
+
+			if err := s.markFailed(jobId, err, db); err != nil {
+				return err
+			}
+		}
+
+		// failed jobs are final, creator uploads a new file instead of retry
+		return nil
+	})
+}
+
+func (s *Service) process(jobId int64, db *gorm.DB, ctx context.Context) error {
+	var job database.SongProcessingJob
+	if err := db.Where("id = ?", jobId).Find(&job).Error; err != nil {
+		return errors.WithStack(err)
+	}
+
+	if job.Id == 0 || job.IsFinished() {
+		return nil
+	}
+
+	if err := db.Model(&job).Updates(map[string]interface{}{
+		"status":     database.ProcessingStatusProcessing,
+		"updated_at": time.Now().UTC(),
+	}).Error; err != nil {
+		return errors.WithStack(err)
+	}
+
+	timeout := time.Duration(s.cfg.TimeoutSec) * time.Second
+	if timeout <= 0 {
+		timeout = 5 * time.Minute
+	}
+
+	workDir, err := os.MkdirTemp("", "music-processing-")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	defer os.RemoveAll(workDir)
+
+	sourceFile := filepath.Join(workDir, "source"+filepath.Ext(job.SourcePath))
+	if err := s.download(job.SourcePath, sourceFile); err != nil {
+		return err
+	}
+
+	probeCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	info, err := s.probe(sourceFile, probeCtx)
+	if err != nil {
+		return err
+	}
+
+	appConfig := s.appConfig.GetValues()
+
+	previewOffset, previewDuration, err := previewBounds(job.PreviewOffset, info.Duration,
+		float64(appConfig.MUSIC_SHORT_VERSION_MIN_DURATION), float64(appConfig.MUSIC_SHORT_VERSION_MAX_DURATION))
+	if err != nil {
+		return err
+	}
+
+	fullFile := filepath.Join(workDir, "full.mp3")
+	shortFile := filepath.Join(workDir, "short.mp3")
+
+	fullCtx, cancelFull := context.WithTimeout(ctx, timeout)
+	defer cancelFull()
+
+	if err := s.transcode(sourceFile, fullFile, 0, 0, fullCtx); err != nil {
+		return err
+	}
+
+	shortCtx, cancelShort := context.WithTimeout(ctx, timeout)
+	defer cancelShort()
+
+	if err := s.transcode(sourceFile, shortFile, previewOffset, previewDuration, shortCtx); err != nil {
+		return err
+	}
+
+	waveformCtx, cancelWaveform := context.WithTimeout(ctx, timeout)
+	defer cancelWaveform()
+
+	peaks, err := s.waveform(fullFile, waveformCtx)
+	if err != nil {
+		return err
+	}
+
+	waveformBody, err := json.Marshal(waveformFile{
+		Duration: info.Duration,
+		Peaks:    peaks,
+	})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	name := uuid.New().String()
+
+	fullUrl, err := s.uploadFile(fullFile, fmt.Sprintf("creator/full/%v.mp3", name), "audio/mpeg")
+	if err != nil {
+		return err
+	}
+
+	shortUrl, err := s.uploadFile(shortFile, fmt.Sprintf("creator/short/%v.mp3", name), "audio/mpeg")
+	if err != nil {
+		return err
+	}
+
+	waveformPath := fmt.Sprintf("creator/waveform/%v.json", name)
+	if err := s.uploader.UploadObjectFromReader(waveformPath, bytes.NewReader(waveformBody), "application/json"); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return s.complete(job.Id, database.SongProcessingJob{
+		SourceCodec:       info.Codec,
+		FullSongUrl:       fullUrl,
+		FullSongDuration:  info.Duration,
+		ShortSongUrl:      shortUrl,
+		ShortSongDuration: previewDuration,
+		WaveformUrl:       s.cdnUrl(waveformPath),
+	}, db)
+}
+
+// complete stores results and copies them into linked song. Job row is locked, so song linking from creators
+// service waits for it and sees final urls
+func (s *Service) complete(jobId int64, result database.SongProcessingJob, db *gorm.DB) error {
+	tx := db.Begin()
+	defer tx.Rollback()
+
+	var job database.SongProcessingJob
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", jobId).Find(&job).Error; err != nil {
+		return errors.WithStack(err)
+	}
+
+	now := time.Now().UTC()
+
+	if err := tx.Model(&job).Updates(map[string]interface{}{
+		"status":              database.ProcessingStatusCompleted,
+		"error":               null.String{},
+		"source_codec":        result.SourceCodec,
+		"full_song_url":       result.FullSongUrl,
+		"full_song_duration":  result.FullSongDuration,
+		"short_song_url":      result.ShortSongUrl,
+		"short_song_duration": result.ShortSongDuration,
+		"waveform_url":        result.WaveformUrl,
+		"updated_at":          now,
+		"completed_at":        null.TimeFrom(now),
+	}).Error; err != nil {
+		return errors.WithStack(err)
+	}
+
+	if job.SongId.Valid {
+		if err := tx.Model(&database.CreatorSong{}).Where("id = ?", job.SongId.Int64).
+			Updates(map[string]interface{}{
+				"full_song_url":       result.FullSongUrl,
+				"full_song_duration":  result.FullSongDuration,
+				"short_song_url":      result.ShortSongUrl,
+				"short_song_duration": result.ShortSongDuration,
+				"waveform_url":        result.WaveformUrl,
+				"updated_at":          null.TimeFrom(now),
+			}).Error; err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	return errors.WithStack(tx.Commit().Error)
+}
+
+func (s *Service) markFailed(jobId int64, processingErr error, db *gorm.DB) error {
+	return errors.WithStack(db.Model(&database.SongProcessingJob{}).Where("id = ?", jobId).
+		Updates(map[string]interface{}{
+			"status":     database.ProcessingStatusFailed,
+			"error":      null.StringFrom(processingErr.Error()),
+			"updated_at": time.Now().UTC(),
+		}).Error)
+}
+
+func (s *Service) download(path string, target string) error {
+	body, err := s.uploader.GetObject(path)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	defer body.Close()
+
+	f, err := os.Create(target)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	defer f.Close()
+
+	if _, err := io.Copy(f, body); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return nil
+}
+
+func (s *Service) uploadFile(localPath string, path string, contentType string) (string, error) {
+	f, err := os.Open(localPath)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	defer f.Close()
+
+	if err := s.uploader.UploadObjectFromReader(path, f, contentType); err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	return s.cdnUrl(path), nil
+}
+
+func (s *Service) cdnUrl(path string) string {
+	return fmt.Sprintf("%v/%v", s.s3Cfg.CdnUrl, path)
+}
@@ -0,0 +1,64 @@
+package processing
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComputePeaks(t *testing.T) {
+	peaks := computePeaks([]int16{0, 100, -200, 50, 400, -100, 0, 10}, 4)
+	assert.Equal(t, []float64{0.25, 0.5, 1, 0.025}, peaks)
+
+	assert.Equal(t, []float64{1, 0.5}, computePeaks([]int16{-100, 50}, 10))
+	assert.Equal(t, []float64{}, computePeaks(nil, 10))
+	assert.Equal(t, []float64{0, 0}, computePeaks([]int16{0, 0, 0, 0}, 2))
+}
+
+func TestPreviewBounds(t *testing.T) {
+	offset, duration, err := previewBounds(30, 180, 10, 60)
+	assert.Nil(t, err)
+	assert.Equal(t, 30.0, offset)
+	assert.Equal(t, 60.0, duration)
+
+	offset, duration, err = previewBounds(150, 180, 10, 60)
+	assert.Nil(t, err)
+	assert.Equal(t, 120.0, offset)
+	assert.Equal(t, 60.0, duration)
+
+	offset, duration, err = previewBounds(5, 40, 10, 60)
+	assert.Nil(t, err)
+	assert.Equal(t, 0.0, offset)
+	assert.Equal(t, 40.0, duration)
+
+	_, _, err = previewBounds(0, 5, 10, 60)
+	assert.NotNil(t, err)
+}
+
+func TestParseProbeResult(t *testing.T) {
+	info, err := parseProbeResult(probeResult{
+		Streams: []probeStream{{CodecType: "video", CodecName: "mjpeg"}, {CodecType: "audio", CodecName: "flac"}},
+		Format:  probeFormat{FormatName: "flac", Duration: "183.250000"},
+	}, 300)
+	assert.Nil(t, err)
+	assert.Equal(t, "flac", info.Codec)
+	assert.Equal(t, 183.25, info.Duration)
+
+	_, err = parseProbeResult(probeResult{
+		Streams: []probeStream{{CodecType: "video", CodecName: "h264"}},
+		Format:  probeFormat{Duration: "10"},
+	}, 300)
+	assert.NotNil(t, err)
+
+	_, err = parseProbeResult(probeResult{
+		Streams: []probeStream{{CodecType: "audio", CodecName: "wmav2"}},
+		Format:  probeFormat{Duration: "10"},
+	}, 300)
+	assert.NotNil(t, err)
+
+	_, err = parseProbeResult(probeResult{
+		Streams: []probeStream{{CodecType: "audio", CodecName: "mp3"}},
+		Format:  probeFormat{Duration: "301"},
+	}, 300)
+	assert.NotNil(t, err)
+}
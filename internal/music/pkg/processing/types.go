@@ -0,0 +1,10 @@
+package processing
+
+type GetJobRequest struct {
+	Id int64 `json:"id"`
+}
+
+type waveformFile struct {
+	Duration float64   `json:"duration"`
+	Peaks    []float64 `json:"peaks"`
+}
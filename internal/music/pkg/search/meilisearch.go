@@ -0,0 +1,385 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/digitalmonsters/music/configs"
+	"github.com/digitalmonsters/music/pkg/database"
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	"github.com/valyala/fasthttp"
+	"gorm.io/gorm"
+)
+
+// meilisearchBackend searches in a Meilisearch index filled from db by the reindex task
+type meilisearchBackend struct {
+	cfg     configs.MeilisearchConfig
+	client  *fasthttp.Client
+	timeout time.Duration
+}
+
+func newMeilisearchBackend(cfg configs.MeilisearchConfig) *meilisearchBackend {
+	timeout := cfg.TimeoutSec
+	if timeout <= 0 {
+		timeout = 5
+	}
+
+	return &meilisearchBackend{
+		cfg:     cfg,
+		client:  &fasthttp.Client{},
+		timeout: time.Duration(timeout) * time.Second,
+	}
+}
+
+type meiliDocument struct {
+	Uid            string              `json:"uid"`
+	Source         Source              `json:"source"`
+	Id             int64               `json:"id"`
+	ExternalId     string              `json:"external_id"`
+	SongSource     database.SongSource `json:"song_source"`
+	UserId         int64               `json:"user_id"`
+	Title          string              `json:"title"`
+	Artist         string              `json:"artist"`
+	ImageUrl       string              `json:"image_url"`
+	Genre          string              `json:"genre"`
+	Genres         []string            `json:"genres"` // lower case genres for filters and facets
+	CategoryId     int64               `json:"category_id"`
+	Category       string              `json:"category"`
+	MoodId         int64               `json:"mood_id"`
+	Mood           string              `json:"mood"`
+	Duration       float64             `json:"duration"`
+	DurationBucket string              `json:"duration_bucket"`
+	Hashtags       []string            `json:"hashtags"`
+	Popularity     int64               `json:"popularity"`
+	InLibrary      bool                `json:"in_library"`
+	RankingScore   float64             `json:"_rankingScore,omitempty"`
+}
+
+func newMeiliDocument(item SearchItem) meiliDocument {
+	return meiliDocument{
+		Uid:            fmt.Sprintf("%v_%v", item.Source, item.Id),
+		Source:         item.Source,
+		Id:             item.Id,
+		ExternalId:     item.ExternalId,
+		SongSource:     item.SongSource,
+		UserId:         item.UserId,
+		Title:          item.Title,
+		Artist:         item.Artist,
+		ImageUrl:       item.ImageUrl,
+		Genre:          item.Genre,
+		Genres:         splitGenres(item.Genre),
+		CategoryId:     item.CategoryId,
+		Category:       item.Category,
+		MoodId:         item.MoodId,
+		Mood:           item.Mood,
+		Duration:       item.Duration,
+		DurationBucket: durationBucket(item.Duration),
+		Hashtags:       item.Hashtags,
+		Popularity:     item.Popularity,
+		InLibrary:      item.InLibrary,
+	}
+}
+
+func (d meiliDocument) toItem() SearchItem {
+	return SearchItem{
+		Source:     d.Source,
+		Id:         d.Id,
+		ExternalId: d.ExternalId,
+		SongSource: d.SongSource,
+		UserId:     d.UserId,
+		Title:      d.Title,
+		Artist:     d.Artist,
+		ImageUrl:   d.ImageUrl,
+		Genre:      d.Genre,
+		CategoryId: d.CategoryId,
+		Category:   d.Category,
+		MoodId:     d.MoodId,
+		Mood:       d.Mood,
+		Duration:   d.Duration,
+		Hashtags:   d.Hashtags,
+		Popularity: d.Popularity,
+		InLibrary:  d.InLibrary,
+		Rank:       d.RankingScore,
+	}
+}
+
+func splitGenres(genre string) []string {
+	var genres []string
+
+	for _, g := range strings.Split(strings.ToLower(genre), ",") {
+		if g = strings.TrimSpace(g); len(g) > 0 {
+			genres = append(genres, g)
+		}
+	}
+
+	return genres
+}
+
+type meiliSearchRequest struct {
+	Q                string        `json:"q"`
+	Filter           []interface{} `json:"filter,omitempty"` // items are joined with AND, items of nested arrays with OR
+	Facets           []string      `json:"facets"`
+	Offset           int           `json:"offset"`
+	Limit            int           `json:"limit"`
+	ShowRankingScore bool          `json:"showRankingScore"`
+}
+
+type meiliSearchResponse struct {
+	Hits               []meiliDocument             `json:"hits"`
+	EstimatedTotalHits int64                       `json:"estimatedTotalHits"`
+	FacetDistribution  map[string]map[string]int64 `json:"facetDistribution"`
+}
+
+var meiliSettings = map[string]interface{}{
+	"searchableAttributes": []string{"title", "artist", "hashtags", "genre", "category", "mood"},
+	"filterableAttributes": []string{"source", "genres", "mood_id", "category_id", "duration", "duration_bucket",
+		"in_library"},
+	"sortableAttributes": []string{"popularity"},
+	"rankingRules":       []string{"words", "typo", "proximity", "attribute", "sort", "exactness", "popularity:desc"},
+}
+
+func quoteFilterValues[T any](values []T) string {
+	quoted := lo.Map(values, func(v T, _ int) string {
+		b, _ := json.Marshal(v)
+		return string(b)
+	})
+
+	return fmt.Sprintf("[%v]", strings.Join(quoted, ", "))
+}
+
+func meiliFilters(req SearchRequest, sources []Source) []interface{} {
+	filters := []interface{}{fmt.Sprintf("source IN %v", quoteFilterValues(sources))}
+
+	if skipInLibrary(sources) {
+		filters = append(filters, "in_library = false")
+	}
+
+	if len(req.Genres) > 0 {
+		genres := lo.Map(req.Genres, func(g string, _ int) string {
+			return strings.ToLower(strings.TrimSpace(g))
+		})
+
+		filters = append(filters, fmt.Sprintf("genres IN %v", quoteFilterValues(genres)))
+	}
+
+	if len(req.MoodIds) > 0 {
+		filters = append(filters, fmt.Sprintf("mood_id IN %v", quoteFilterValues(req.MoodIds)))
+	}
+
+	if len(req.CategoryIds) > 0 {
+		filters = append(filters, fmt.Sprintf("category_id IN %v", quoteFilterValues(req.CategoryIds)))
+	}
+
+	if req.MinDuration.Valid {
+		filters = append(filters, fmt.Sprintf("duration >= %v", req.MinDuration.Float64))
+	}
+
+	if req.MaxDuration.Valid {
+		filters = append(filters, fmt.Sprintf("duration <= %v", req.MaxDuration.Float64))
+	}
+
+	return filters
+}
+
+func (m *meilisearchBackend) Search(req SearchRequest, sources []Source, db *gorm.DB, ctx context.Context) (*backendResult, error) {
+	var resp meiliSearchResponse
+
+	if err := m.request("POST", fmt.Sprintf("/indexes/%v/search", m.cfg.Index), meiliSearchRequest{
+		Q:                req.Query,
+		Filter:           meiliFilters(req, sources),
+		Facets:           []string{"genres", "mood_id", "category_id", "duration_bucket"},
+		Offset:           (req.Page - 1) * req.Size,
+		Limit:            req.Size,
+		ShowRankingScore: true,
+	}, &resp); err != nil {
+		return nil, err
+	}
+
+	result := &backendResult{
+		Items:      make([]SearchItem, 0, len(resp.Hits)),
+		TotalCount: resp.EstimatedTotalHits,
+	}
+
+	for _, hit := range resp.Hits {
+		result.Items = append(result.Items, hit.toItem())
+	}
+
+	result.Facets.Genres = facetValues(resp.FacetDistribution["genres"], nil)
+	if len(result.Facets.Genres) > maxGenreFacets {
+		result.Facets.Genres = result.Facets.Genres[:maxGenreFacets]
+	}
+
+	result.Facets.Durations = facetValues(resp.FacetDistribution["duration_bucket"], nil)
+	sortDurationFacets(result.Facets.Durations)
+
+	var moods []database.Mood
+	if moodIds := facetIds(resp.FacetDistribution["mood_id"]); len(moodIds) > 0 {
+		if err := db.WithContext(ctx).Where("id in ?", moodIds).Find(&moods).Error; err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+
+	result.Facets.Moods = facetValues(resp.FacetDistribution["mood_id"], lo.Associate(moods,
+		func(m database.Mood) (int64, string) {
+			return m.Id, m.Name
+		}))
+
+	var categories []database.Category
+	if categoryIds := facetIds(resp.FacetDistribution["category_id"]); len(categoryIds) > 0 {
+		if err := db.WithContext(ctx).Where("id in ?", categoryIds).Find(&categories).Error; err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+
+	result.Facets.Categories = facetValues(resp.FacetDistribution["category_id"], lo.Associate(categories,
+		func(c database.Category) (int64, string) {
+			return c.Id, c.Name
+		}))
+
+	return result, nil
+}
+
+func facetIds(distribution map[string]int64) []int64 {
+	var ids []int64
+
+	for k := range distribution {
+		if id, err := strconv.ParseInt(k, 10, 64); err == nil && id > 0 {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids
+}
+
+// facetValues converts meilisearch facet distribution, when names are set facet keys are ids
+func facetValues(distribution map[string]int64, names map[int64]string) []FacetValue {
+	values := make([]FacetValue, 0, len(distribution))
+
+	for k, count := range distribution {
+		if names == nil {
+			values = append(values, FacetValue{Value: k, Count: count})
+			continue
+		}
+
+		id, err := strconv.ParseInt(k, 10, 64)
+		if err != nil || id == 0 {
+			continue
+		}
+
+		values = append(values, FacetValue{Id: id, Value: names[id], Count: count})
+	}
+
+	sort.Slice(values, func(i, j int) bool {
+		if values[i].Count != values[j].Count {
+			return values[i].Count > values[j].Count
+		}
+
+		return values[i].Value < values[j].Value
+	})
+
+	return values
+}
+
+// Reindex fills a temporary index from db and swaps it with the search index, so removed songs disappear.
+// Meilisearch processes enqueued tasks in order, so the swap happens after all documents are added
+func (m *meilisearchBackend) Reindex(db *gorm.DB) error {
+	tmpIndex := fmt.Sprintf("%v_reindex", m.cfg.Index)
+
+	batchSize := m.cfg.BatchSize
+	if batchSize <= 0 {
+		batchSize = 1000
+	}
+
+	if err := m.request("DELETE", fmt.Sprintf("/indexes/%v", tmpIndex), nil, nil); err != nil {
+		return err
+	}
+
+	for _, index := range []string{m.cfg.Index, tmpIndex} {
+		// creation of existing index fails asynchronously and does not affect next tasks
+		if err := m.request("POST", "/indexes", map[string]string{"uid": index, "primaryKey": "uid"}, nil); err != nil {
+			return err
+		}
+	}
+
+	if err := m.request("PATCH", fmt.Sprintf("/indexes/%v/settings", tmpIndex), meiliSettings, nil); err != nil {
+		return err
+	}
+
+	for _, source := range []Source{SourceLibrary, SourceOwnStorage, SourceCreator} {
+		for offset := 0; ; offset += batchSize {
+			items, err := allDocuments(source, batchSize, offset, db)
+			if err != nil {
+				return err
+			}
+
+			if len(items) == 0 {
+				break
+			}
+
+			documents := lo.Map(items, func(item SearchItem, _ int) meiliDocument {
+				return newMeiliDocument(item)
+			})
+
+			if err = m.request("POST", fmt.Sprintf("/indexes/%v/documents", tmpIndex), documents, nil); err != nil {
+				return err
+			}
+
+			if len(items) < batchSize {
+				break
+			}
+		}
+	}
+
+	if err := m.request("POST", "/swap-indexes", []map[string][]string{
+		{"indexes": {m.cfg.Index, tmpIndex}},
+	}, nil); err != nil {
+		return err
+	}
+
+	return m.request("DELETE", fmt.Sprintf("/indexes/%v", tmpIndex), nil, nil)
+}
+
+func (m *meilisearchBackend) request(method string, path string, body interface{}, out interface{}) error {
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseResponse(resp)
+
+	req.SetRequestURI(strings.TrimSuffix(m.cfg.Host, "/") + path)
+	req.Header.SetMethod(method)
+
+	if len(m.cfg.ApiKey) > 0 {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", m.cfg.ApiKey))
+	}
+
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		req.Header.SetContentType("application/json")
+		req.SetBody(b)
+	}
+
+	if err := m.client.DoTimeout(req, resp, m.timeout); err != nil {
+		return errors.WithStack(err)
+	}
+
+	if resp.StatusCode() >= 300 {
+		return errors.New(fmt.Sprintf("meilisearch HTTP CODE %v. %v %v: %v", resp.StatusCode(), method, path,
+			string(resp.Body())))
+	}
+
+	if out != nil {
+		return errors.WithStack(json.Unmarshal(resp.Body(), out))
+	}
+
+	return nil
+}
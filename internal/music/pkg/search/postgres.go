@@ -0,0 +1,335 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/digitalmonsters/go-common/common"
+	"github.com/digitalmonsters/music/pkg/database"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	"gorm.io/gorm"
+)
+
+// maxGenreFacets limits genre facet, soundstripe songs have a lot of genres
+const maxGenreFacets = 20
+
+// creatorTaxonomyRankWeight lowers rank of creator songs matched only by category or mood name
+const creatorTaxonomyRankWeight = 0.5
+
+// postgresBackend searches songs, music_storage and creator_songs by search_vector columns
+type postgresBackend struct{}
+
+type document struct {
+	Source     Source
+	Id         int64
+	ExternalId string
+	SongSource database.SongSource
+	UserId     int64
+	Title      string
+	Artist     string
+	ImageUrl   string
+	Genre      string
+	CategoryId int64
+	Category   string
+	MoodId     int64
+	Mood       string
+	Duration   float64
+	Hashtags   pq.StringArray
+	Popularity int64
+	InLibrary  bool
+	Rank       float64
+}
+
+func (d document) toItem() SearchItem {
+	return SearchItem{
+		Source:     d.Source,
+		Id:         d.Id,
+		ExternalId: d.ExternalId,
+		SongSource: d.SongSource,
+		UserId:     d.UserId,
+		Title:      d.Title,
+		Artist:     d.Artist,
+		ImageUrl:   d.ImageUrl,
+		Genre:      d.Genre,
+		CategoryId: d.CategoryId,
+		Category:   d.Category,
+		MoodId:     d.MoodId,
+		Mood:       d.Mood,
+		Duration:   d.Duration,
+		Hashtags:   d.Hashtags,
+		Popularity: d.Popularity,
+		InLibrary:  d.InLibrary,
+		Rank:       d.Rank,
+	}
+}
+
+// visibleLibrarySong is a condition on songs s, library songs are shown to users only from active playlists
+const visibleLibrarySong = `exists(select 1 from playlist_song_relations psr
+		join playlists p on p.id = psr.playlist_id and p.deleted_at is null
+		where psr.song_id = s.id)`
+
+const librarySql = `select 'library' as source, s.id, coalesce(s.external_id, '') as external_id, s.source as song_source,
+		0::bigint as user_id, coalesce(s.title, '') as title, coalesce(s.artist, '') as artist,
+		coalesce(s.image_url, '') as image_url, coalesce(s.genre, '') as genre, 0::bigint as category_id, '' as category,
+		0::bigint as mood_id, '' as mood, coalesce(s.duration, 0)::double precision as duration, null::text[] as hashtags,
+		coalesce(s.listen_amount, 0)::bigint as popularity, false as in_library, {rank} as rank
+	from songs s {join_query}
+	where s.deleted_at is null and ` + visibleLibrarySong + ` {match}`
+
+const ownStorageSql = `select 'own_storage' as source, m.id, m.id::text as external_id,
+		(@own_storage_source)::int as song_source, 0::bigint as user_id, coalesce(m.title, '') as title,
+		coalesce(m.artist, '') as artist, coalesce(m.image_url, '') as image_url, coalesce(m.genre, '') as genre,
+		0::bigint as category_id, '' as category, 0::bigint as mood_id, '' as mood,
+		coalesce(m.duration, 0)::double precision as duration, null::text[] as hashtags, 0::bigint as popularity,
+		exists(select 1 from songs s
+			where s.source = @own_storage_source and s.external_id = m.id::text and s.deleted_at is null
+			and ` + visibleLibrarySong + `) as in_library, {rank} as rank
+	from music_storage m {join_query}
+	where m.deleted_at is null {match}`
+
+const creatorSql = `select 'creator' as source, cs.id, '' as external_id, 0 as song_source, cs.user_id,
+		coalesce(cs.name, '') as title, coalesce(cs.music_author, '') as artist, coalesce(cs.image_url, '') as image_url,
+		coalesce(c.name, '') as genre, coalesce(cs.category_id, 0) as category_id, coalesce(c.name, '') as category,
+		coalesce(cs.mood_id, 0) as mood_id, coalesce(m.name, '') as mood,
+		coalesce(cs.full_song_duration, 0)::double precision as duration, cs.hashtags,
+		(coalesce(cs.short_listens, 0) + coalesce(cs.full_listens, 0))::bigint as popularity, false as in_library,
+		{rank} as rank
+	from creator_songs cs {join_query}
+		left join categories c on c.id = cs.category_id
+		left join moods m on m.id = cs.mood_id
+	where cs.deleted_at is null and cs.reject_reason is null and cs.full_song_url is not null
+		and cs.status in @visible_statuses {match}`
+
+const creatorTaxonomyVector = `to_tsvector('simple', coalesce(c.name, '') || ' ' || coalesce(m.name, ''))`
+
+// tsQuery converts user query to tsquery expression with prefix match of every word. Words removed by
+// common.ToTsQuery (e.g. not latin) are searched as plain text
+func tsQuery(query string) (string, string) {
+	if terms := common.ToTsQuery(query); len(terms) > 0 {
+		words := strings.Split(terms, "|")
+		for i := range words {
+			words[i] += ":*"
+		}
+
+		return "to_tsquery('simple', @query)", strings.Join(words, " | ")
+	}
+
+	if query = strings.TrimSpace(query); len(query) > 0 {
+		return "plainto_tsquery('simple', @query)", query
+	}
+
+	return "", ""
+}
+
+type sqlBuilder struct {
+	documents string
+	filters   []string
+	args      map[string]interface{}
+	withQuery string
+}
+
+func newSqlBuilder(req SearchRequest, sources []Source) *sqlBuilder {
+	b := &sqlBuilder{
+		args: map[string]interface{}{
+			"own_storage_source": database.SongSourceOwnStorage,
+			"visible_statuses":   database.CreatorSongVisibleStatuses,
+		},
+	}
+
+	queryExpr, queryArg := tsQuery(req.Query)
+	textSearch := len(queryExpr) > 0
+
+	if textSearch {
+		b.withQuery = fmt.Sprintf("with q as (select %v as query) ", queryExpr)
+		b.args["query"] = queryArg
+	}
+
+	part := func(sql string, vector string, rank string, match string) string {
+		r := strings.NewReplacer("{rank}", "0::real", "{join_query}", "", "{match}", "")
+		if textSearch {
+			if rank == "" {
+				rank = fmt.Sprintf("ts_rank(%v, q.query)", vector)
+			}
+
+			if match == "" {
+				match = fmt.Sprintf("and %v @@ q.query", vector)
+			}
+
+			r = strings.NewReplacer("{rank}", rank, "{join_query}", "cross join q", "{match}", match)
+		}
+
+		return r.Replace(sql)
+	}
+
+	var parts []string
+
+	for _, source := range sources {
+		switch source {
+		case SourceLibrary:
+			parts = append(parts, part(librarySql, "s.search_vector", "", ""))
+		case SourceOwnStorage:
+			parts = append(parts, part(ownStorageSql, "m.search_vector", "", ""))
+		case SourceCreator:
+			parts = append(parts, part(creatorSql, "cs.search_vector",
+				fmt.Sprintf("ts_rank(cs.search_vector, q.query) + %v * ts_rank(%v, q.query)",
+					creatorTaxonomyRankWeight, creatorTaxonomyVector),
+				fmt.Sprintf("and (cs.search_vector @@ q.query or %v @@ q.query)", creatorTaxonomyVector)))
+		}
+	}
+
+	b.documents = fmt.Sprintf("(%v) d", strings.Join(parts, " union all "))
+
+	if skipInLibrary(sources) {
+		b.filters = append(b.filters, "not d.in_library")
+	}
+
+	if len(req.Genres) > 0 {
+		genres := make([]string, 0, len(req.Genres))
+		for _, g := range req.Genres {
+			genres = append(genres, strings.ToLower(strings.TrimSpace(g)))
+		}
+
+		b.filters = append(b.filters, "exists(select 1 from unnest(string_to_array(lower(d.genre), ',')) g "+
+			"where trim(g) in @genres)")
+		b.args["genres"] = genres
+	}
+
+	if len(req.MoodIds) > 0 {
+		b.filters = append(b.filters, "d.mood_id in @mood_ids")
+		b.args["mood_ids"] = req.MoodIds
+	}
+
+	if len(req.CategoryIds) > 0 {
+		b.filters = append(b.filters, "d.category_id in @category_ids")
+		b.args["category_ids"] = req.CategoryIds
+	}
+
+	if req.MinDuration.Valid {
+		b.filters = append(b.filters, "d.duration >= @min_duration")
+		b.args["min_duration"] = req.MinDuration.Float64
+	}
+
+	if req.MaxDuration.Valid {
+		b.filters = append(b.filters, "d.duration <= @max_duration")
+		b.args["max_duration"] = req.MaxDuration.Float64
+	}
+
+	return b
+}
+
+// skipInLibrary tells if own storage songs added to playlists are skipped, they are found as library songs
+func skipInLibrary(sources []Source) bool {
+	return lo.Contains(sources, SourceLibrary) && lo.Contains(sources, SourceOwnStorage)
+}
+
+func (b *sqlBuilder) sql(selectSql string, extraJoin string, extraFilters []string, tail string) string {
+	where := append([]string{"true"}, b.filters...)
+	where = append(where, extraFilters...)
+
+	return fmt.Sprintf("%vselect %v from %v %v where %v %v", b.withQuery, selectSql, b.documents, extraJoin,
+		strings.Join(where, " and "), tail)
+}
+
+// durationBucketSql must match durationBucket
+const durationBucketSql = `case when d.duration < 60 then '` + durationUnder1m + `'
+	when d.duration < 180 then '` + duration1mTo3m + `'
+	when d.duration < 300 then '` + duration3mTo5m + `'
+	else '` + durationOver5m + `' end`
+
+func (p *postgresBackend) Search(req SearchRequest, sources []Source, db *gorm.DB, ctx context.Context) (*backendResult, error) {
+	b := newSqlBuilder(req, sources)
+	db = db.WithContext(ctx)
+
+	var documents []document
+
+	args := map[string]interface{}{"limit": req.Size, "offset": (req.Page - 1) * req.Size}
+	for k, v := range b.args {
+		args[k] = v
+	}
+
+	if err := db.Raw(b.sql("d.*", "", nil,
+		"order by d.rank desc, d.popularity desc, d.source, d.id limit @limit offset @offset"), args).
+		Scan(&documents).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	result := &backendResult{
+		Items: make([]SearchItem, 0, len(documents)),
+	}
+
+	for _, d := range documents {
+		result.Items = append(result.Items, d.toItem())
+	}
+
+	if err := db.Raw(b.sql("count(*)", "", nil, ""), b.args).Scan(&result.TotalCount).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if result.TotalCount == 0 {
+		return result, nil
+	}
+
+	if err := db.Raw(b.sql("trim(g) as value, count(*) as count",
+		"cross join unnest(string_to_array(lower(d.genre), ',')) g", []string{"trim(g) <> ''"},
+		fmt.Sprintf("group by trim(g) order by count desc, value limit %v", maxGenreFacets)), b.args).
+		Scan(&result.Facets.Genres).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if err := db.Raw(b.sql("d.mood_id as id, d.mood as value, count(*) as count", "", []string{"d.mood_id > 0"},
+		"group by d.mood_id, d.mood order by count desc, value"), b.args).
+		Scan(&result.Facets.Moods).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if err := db.Raw(b.sql("d.category_id as id, d.category as value, count(*) as count", "",
+		[]string{"d.category_id > 0"}, "group by d.category_id, d.category order by count desc, value"), b.args).
+		Scan(&result.Facets.Categories).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if err := db.Raw(b.sql(durationBucketSql+" as value, count(*) as count", "", nil, "group by 1"), b.args).
+		Scan(&result.Facets.Durations).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	sortDurationFacets(result.Facets.Durations)
+
+	return result, nil
+}
+
+var durationBucketsOrder = map[string]int{durationUnder1m: 0, duration1mTo3m: 1, duration3mTo5m: 2, durationOver5m: 3}
+
+func sortDurationFacets(values []FacetValue) {
+	sort.Slice(values, func(i, j int) bool {
+		return durationBucketsOrder[values[i].Value] < durationBucketsOrder[values[j].Value]
+	})
+}
+
+// allDocuments returns a page of all db documents of the source, used to fill external search index
+func allDocuments(source Source, limit int, offset int, db *gorm.DB) ([]SearchItem, error) {
+	b := newSqlBuilder(SearchRequest{}, []Source{source})
+
+	args := map[string]interface{}{"limit": limit, "offset": offset}
+	for k, v := range b.args {
+		args[k] = v
+	}
+
+	var documents []document
+
+	if err := db.Raw(b.sql("d.*", "", nil, "order by d.id limit @limit offset @offset"), args).
+		Scan(&documents).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	items := make([]SearchItem, 0, len(documents))
+	for _, d := range documents {
+		items = append(items, d.toItem())
+	}
+
+	return items, nil
+}
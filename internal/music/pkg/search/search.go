@@ -0,0 +1,269 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/RichardKnop/machinery/v1"
+	"github.com/RichardKnop/machinery/v1/tasks"
+	"github.com/digitalmonsters/go-common/apm_helper"
+	"github.com/digitalmonsters/go-common/boilerplate"
+	"github.com/digitalmonsters/music/configs"
+	"github.com/digitalmonsters/music/pkg/database"
+	"github.com/digitalmonsters/music/pkg/music_source"
+	"github.com/digitalmonsters/music/utils"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"github.com/samber/lo"
+	"go.elastic.co/apm"
+	"gopkg.in/guregu/null.v4"
+	"gorm.io/gorm"
+)
+
+const reindexTaskName = "music:search:reindex"
+
+const BackendMeilisearch = "meilisearch"
+
+const defaultPageSize = 20
+
+// rrfK is a constant of reciprocal rank fusion, used to merge db and external results
+const rrfK = 60.0
+
+type IBackend interface {
+	Search(req SearchRequest, sources []Source, db *gorm.DB, ctx context.Context) (*backendResult, error)
+}
+
+// IExternalSource searches songs which are not stored in db
+type IExternalSource interface {
+	Search(query string, page int, size int, db *gorm.DB, apmTransaction *apm.Transaction, ctx context.Context) ([]SearchItem, int64, error)
+}
+
+// Service searches songs, own storage and creator songs in db or meilisearch and merges them with SoundStripe results
+type Service struct {
+	cfg      configs.SearchConfig
+	backend  IBackend
+	fallback IBackend // postgres when meilisearch is used
+	external IExternalSource
+}
+
+func NewService(jobber *machinery.Server, cfg configs.SearchConfig, external IExternalSource) *Service {
+	s := &Service{
+		cfg:      cfg,
+		backend:  &postgresBackend{},
+		external: external,
+	}
+
+	if cfg.Backend == BackendMeilisearch {
+		meili := newMeilisearchBackend(cfg.Meilisearch)
+		s.fallback = s.backend
+		s.backend = meili
+
+		if boilerplate.GetCurrentEnvironment() != boilerplate.Ci {
+			if err := s.registerReindexTask(jobber, meili); err != nil {
+				log.Fatal().Err(err).Msg("[Music] can not register search reindex task")
+			}
+		}
+	}
+
+	return s
+}
+
+func (s *Service) Search(req SearchRequest, allowedSources []Source, db *gorm.DB, apmTransaction *apm.Transaction, ctx context.Context) (*SearchResponse, error) {
+	sources := allowedSources
+	if len(req.Sources) > 0 {
+		sources = lo.Intersect(allowedSources, lo.Uniq(req.Sources))
+	}
+
+	if len(sources) == 0 {
+		return nil, errors.New("invalid sources")
+	}
+
+	if req.Page < 1 {
+		req.Page = 1
+	}
+
+	if req.Size < 1 {
+		req.Size = defaultPageSize
+	}
+
+	if s.cfg.MaxPageSize > 0 && req.Size > s.cfg.MaxPageSize {
+		req.Size = s.cfg.MaxPageSize
+	}
+
+	resp := &SearchResponse{
+		Items: []SearchItem{},
+	}
+
+	var local []SearchItem
+
+	if dbSources := lo.Without(sources, SourceSoundStripe); len(dbSources) > 0 {
+		result, err := s.backend.Search(req, dbSources, db, ctx)
+		if err != nil && s.fallback != nil {
+			apm_helper.LogError(err, ctx)
+			result, err = s.fallback.Search(req, dbSources, db, ctx)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		local = result.Items
+		resp.Facets = result.Facets
+		resp.TotalCount = result.TotalCount
+	}
+
+	var external []SearchItem
+
+	if lo.Contains(sources, SourceSoundStripe) && s.useExternal(req) {
+		items, totalCount, err := s.external.Search(req.Query, req.Page, req.Size, db, apmTransaction, ctx)
+		if err != nil {
+			apm_helper.LogError(err, ctx)
+		} else {
+			external, err = s.skipLibrarySongs(filterExternal(items, req), sources, db)
+			if err != nil {
+				return nil, err
+			}
+
+			resp.TotalCount += totalCount
+		}
+	}
+
+	resp.Items = mergeRanked(local, external, s.cfg.ExternalWeight)
+
+	return resp, nil
+}
+
+// useExternal tells if SoundStripe is searched, it has no moods and categories and is not browsed without query
+func (s *Service) useExternal(req SearchRequest) bool {
+	return s.external != nil && s.cfg.ExternalWeight > 0 && len(strings.TrimSpace(req.Query)) > 0 &&
+		!req.hasFacetFilters()
+}
+
+// filterExternal applies filters, which SoundStripe api does not support
+func filterExternal(items []SearchItem, req SearchRequest) []SearchItem {
+	genres := lo.Map(req.Genres, func(g string, _ int) string {
+		return strings.ToLower(strings.TrimSpace(g))
+	})
+
+	return lo.Filter(items, func(item SearchItem, _ int) bool {
+		if req.MinDuration.Valid && item.Duration < req.MinDuration.Float64 {
+			return false
+		}
+
+		if req.MaxDuration.Valid && item.Duration > req.MaxDuration.Float64 {
+			return false
+		}
+
+		if len(genres) > 0 && len(lo.Intersect(genres, splitGenres(item.Genre))) == 0 {
+			return false
+		}
+
+		return true
+	})
+}
+
+// skipLibrarySongs removes SoundStripe songs which are already in the library, they are found as library songs
+func (s *Service) skipLibrarySongs(items []SearchItem, sources []Source, db *gorm.DB) ([]SearchItem, error) {
+	if len(items) == 0 || !lo.Contains(sources, SourceLibrary) {
+		return items, nil
+	}
+
+	var libraryIds []string
+
+	if err := db.Table("songs s").
+		Where("s.source = ? and s.external_id in ? and s.deleted_at is null", database.SongSourceSoundStripe,
+			lo.Map(items, func(item SearchItem, _ int) string {
+				return item.ExternalId
+			})).
+		Where(visibleLibrarySong).
+		Pluck("s.external_id", &libraryIds).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return lo.Filter(items, func(item SearchItem, _ int) bool {
+		return !lo.Contains(libraryIds, item.ExternalId)
+	}), nil
+}
+
+// mergeRanked merges db and external results with reciprocal rank fusion, ranks of different backends are not
+// comparable, so only positions are used. Rank of merged items is replaced with the fused one
+func mergeRanked(local []SearchItem, external []SearchItem, externalWeight float64) []SearchItem {
+	merged := make([]SearchItem, 0, len(local)+len(external))
+
+	for i, item := range local {
+		item.Rank = 1 / (rrfK + float64(i+1))
+		merged = append(merged, item)
+	}
+
+	for i, item := range external {
+		item.Rank = externalWeight / (rrfK + float64(i+1))
+		merged = append(merged, item)
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].Rank > merged[j].Rank
+	})
+
+	return merged
+}
+
+func (s *Service) registerReindexTask(jobber *machinery.Server, meili *meilisearchBackend) error {
+	if err := jobber.RegisterTask(reindexTaskName, func() error {
+		var apmTransaction = apm_helper.StartNewApmTransaction(reindexTaskName, "task", nil, nil)
+		defer apmTransaction.End()
+
+		if err := meili.Reindex(database.GetDb(database.DbTypeReadonly)); err != nil {
+			apm_helper.LogError(err, apm.ContextWithTransaction(context.Background(), apmTransaction))
+			return err
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	interval := s.cfg.Meilisearch.ReindexIntervalMinutes
+	if interval <= 0 {
+		interval = 15
+	}
+
+	return utils.RegisterPeriodicTask(jobber, fmt.Sprintf("@every %vm", interval), reindexTaskName, []tasks.Arg{}, true)
+}
+
+type soundStripeSource struct {
+	musicStorageService *music_source.MusicStorageService
+}
+
+func NewSoundStripeSource(musicStorageService *music_source.MusicStorageService) IExternalSource {
+	return &soundStripeSource{musicStorageService: musicStorageService}
+}
+
+func (s *soundStripeSource) Search(query string, page int, size int, db *gorm.DB, apmTransaction *apm.Transaction, ctx context.Context) ([]SearchItem, int64, error) {
+	resp, err := s.musicStorageService.ListMusic(music_source.ListMusicRequest{
+		SearchKeyword: null.StringFrom(query),
+		Source:        database.SongSourceSoundStripe,
+		Page:          page,
+		Size:          size,
+	}, db, apmTransaction, ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	items := make([]SearchItem, 0, len(resp.Songs))
+	for _, song := range resp.Songs {
+		items = append(items, SearchItem{
+			Source:     SourceSoundStripe,
+			ExternalId: song.ExternalId,
+			SongSource: database.SongSourceSoundStripe,
+			Title:      song.Title,
+			Artist:     song.Artist,
+			ImageUrl:   song.ImageUrl,
+			Genre:      song.Genre,
+			Duration:   song.Duration,
+		})
+	}
+
+	return items, resp.TotalCount, nil
+}
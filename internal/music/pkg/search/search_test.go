@@ -0,0 +1,225 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/digitalmonsters/go-common/boilerplate_testing"
+	"github.com/digitalmonsters/go-common/wrappers/music"
+	"github.com/digitalmonsters/music/configs"
+	"github.com/digitalmonsters/music/pkg/database"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"go.elastic.co/apm"
+	"gopkg.in/guregu/null.v4"
+	"gorm.io/gorm"
+)
+
+var config configs.Settings
+var gormDb *gorm.DB
+
+func TestMain(m *testing.M) {
+	config = configs.GetConfig()
+	gormDb = database.GetDb(database.DbTypeMaster)
+	os.Exit(m.Run())
+}
+
+type externalMock struct {
+	items []SearchItem
+}
+
+func (e *externalMock) Search(query string, page int, size int, db *gorm.DB, apmTransaction *apm.Transaction, ctx context.Context) ([]SearchItem, int64, error) {
+	return e.items, int64(len(e.items)), nil
+}
+
+func seed(t *testing.T) (database.Category, database.Mood) {
+	if err := boilerplate_testing.FlushPostgresTables(config.MasterDb, []string{"public.songs", "public.playlists",
+		"public.playlist_song_relations", "public.music_storage", "public.creator_songs", "public.categories",
+		"public.moods"}, nil, t); err != nil {
+		t.Fatal(err)
+	}
+
+	category := database.Category{Name: "Rock"}
+	if err := gormDb.Create(&category).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	mood := database.Mood{Name: "Happy"}
+	if err := gormDb.Create(&mood).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	storage := []database.MusicStorage{
+		{Title: "Summer night", Artist: "Storage band", Genre: "Pop", Duration: 200},
+		{Title: "Summer rain", Artist: "Storage band", Genre: "Jazz", Duration: 100},
+	}
+	if err := gormDb.Create(&storage).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	songs := []database.Song{
+		{Source: database.SongSourceSoundStripe, ExternalId: "ss_1", Title: "Summer vibes", Artist: "DJ",
+			Genre: "Pop,Dance", Duration: 150, ListenAmount: 10},
+		{Source: database.SongSourceOwnStorage, ExternalId: fmt.Sprint(storage[0].Id), Title: "Summer night",
+			Artist: "Storage band", Genre: "Pop", Duration: 200},
+		{Source: database.SongSourceSoundStripe, ExternalId: "ss_hidden", Title: "Summer hidden", Artist: "DJ",
+			Genre: "Pop", Duration: 150},
+	}
+	if err := gormDb.Create(&songs).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	playlist := database.Playlist{Name: "test", SortOrder: 1}
+	if err := gormDb.Create(&playlist).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	// the last song is not in a playlist, so it is not visible
+	if err := gormDb.Create(&[]database.PlaylistSongRelations{
+		{PlaylistId: playlist.Id, SongId: songs[0].Id},
+		{PlaylistId: playlist.Id, SongId: songs[1].Id},
+	}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	creatorSongs := []database.CreatorSong{
+		{UserId: 1, Name: "Summer drive", Status: music.CreatorSongStatusApproved, CategoryId: category.Id,
+			MoodId: mood.Id, FullSongUrl: "full", ShortSongUrl: "short", FullSongDuration: 250,
+			Hashtags: pq.StringArray{"road"}},
+		{UserId: 2, Name: "Morning", Status: music.CreatorSongStatusApproved, CategoryId: category.Id,
+			MoodId: mood.Id, FullSongUrl: "full", ShortSongUrl: "short", FullSongDuration: 50,
+			Hashtags: pq.StringArray{"summer"}},
+		{UserId: 3, Name: "Summer draft", Status: music.CreatorSongStatusPending, CategoryId: category.Id,
+			MoodId: mood.Id, FullSongUrl: "full", ShortSongUrl: "short", FullSongDuration: 50},
+	}
+	if err := gormDb.Create(&creatorSongs).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	return category, mood
+}
+
+func TestService_Search(t *testing.T) {
+	category, mood := seed(t)
+
+	service := &Service{
+		cfg:     configs.SearchConfig{ExternalWeight: 1, MaxPageSize: 50},
+		backend: &postgresBackend{},
+		external: &externalMock{items: []SearchItem{
+			{Source: SourceSoundStripe, ExternalId: "ss_1", Title: "Summer vibes", Genre: "Pop", Duration: 150},
+			{Source: SourceSoundStripe, ExternalId: "ss_2", Title: "Summer beach", Genre: "Pop", Duration: 120},
+		}},
+	}
+
+	resp, err := service.Search(SearchRequest{Query: "summ"}, AdminSources, gormDb, nil, context.TODO())
+	assert.Nil(t, err)
+
+	titles := map[Source][]string{}
+	for _, item := range resp.Items {
+		titles[item.Source] = append(titles[item.Source], item.Title)
+	}
+
+	assert.ElementsMatch(t, []string{"Summer vibes", "Summer night"}, titles[SourceLibrary])
+	assert.ElementsMatch(t, []string{"Summer rain"}, titles[SourceOwnStorage])
+	assert.ElementsMatch(t, []string{"Summer drive", "Morning"}, titles[SourceCreator])
+	assert.Equal(t, []string{"Summer beach"}, titles[SourceSoundStripe])
+	assert.Equal(t, int64(7), resp.TotalCount)
+	assert.Equal(t, []FacetValue{{Id: mood.Id, Value: "Happy", Count: 2}}, resp.Facets.Moods)
+	assert.Equal(t, []FacetValue{{Id: category.Id, Value: "Rock", Count: 2}}, resp.Facets.Categories)
+	assert.Equal(t, FacetValue{Value: "pop", Count: 2}, resp.Facets.Genres[0])
+
+	// own storage is not available for users
+	resp, err = service.Search(SearchRequest{Query: "summer", Sources: []Source{SourceOwnStorage}}, UserSources,
+		gormDb, nil, context.TODO())
+	assert.NotNil(t, err)
+
+	resp, err = service.Search(SearchRequest{Query: "summer", MoodIds: []int64{mood.Id},
+		MinDuration: null.FloatFrom(100)}, UserSources, gormDb, nil, context.TODO())
+	assert.Nil(t, err)
+
+	if assert.Len(t, resp.Items, 1) {
+		assert.Equal(t, "Summer drive", resp.Items[0].Title)
+	}
+
+	// category names are searchable for creator songs
+	resp, err = service.Search(SearchRequest{Query: "rock", Sources: []Source{SourceCreator}}, UserSources,
+		gormDb, nil, context.TODO())
+	assert.Nil(t, err)
+	assert.Len(t, resp.Items, 2)
+
+	resp, err = service.Search(SearchRequest{Genres: []string{"Dance"}}, UserSources, gormDb, nil, context.TODO())
+	assert.Nil(t, err)
+
+	if assert.Len(t, resp.Items, 1) {
+		assert.Equal(t, "Summer vibes", resp.Items[0].Title)
+	}
+}
+
+func TestTsQuery(t *testing.T) {
+	expr, arg := tsQuery("Summer  Night!")
+	assert.Equal(t, "to_tsquery('simple', @query)", expr)
+	assert.Equal(t, "summer:* | night:*", arg)
+
+	expr, arg = tsQuery(" лето ")
+	assert.Equal(t, "plainto_tsquery('simple', @query)", expr)
+	assert.Equal(t, "лето", arg)
+
+	expr, _ = tsQuery("  ")
+	assert.Equal(t, "", expr)
+}
+
+func TestMergeRanked(t *testing.T) {
+	local := []SearchItem{{Title: "l1"}, {Title: "l2"}, {Title: "l3"}}
+	external := []SearchItem{{Title: "e1"}, {Title: "e2"}}
+
+	titles := func(items []SearchItem) []string {
+		var result []string
+		for _, item := range items {
+			result = append(result, item.Title)
+		}
+
+		return result
+	}
+
+	assert.Equal(t, []string{"l1", "e1", "l2", "e2", "l3"}, titles(mergeRanked(local, external, 1)))
+	assert.Equal(t, []string{"l1", "l2", "l3", "e1", "e2"}, titles(mergeRanked(local, external, 0.9)))
+	assert.Equal(t, []string{"e1", "e2", "l1", "l2", "l3"}, titles(mergeRanked(local, external, 2)))
+}
+
+func TestFilterExternal(t *testing.T) {
+	items := []SearchItem{
+		{ExternalId: "1", Genre: "Pop,Dance", Duration: 100},
+		{ExternalId: "2", Genre: "Jazz", Duration: 100},
+		{ExternalId: "3", Genre: "Pop", Duration: 400},
+	}
+
+	filtered := filterExternal(items, SearchRequest{Genres: []string{"dance", "Pop"}, MaxDuration: null.FloatFrom(300)})
+
+	if assert.Len(t, filtered, 1) {
+		assert.Equal(t, "1", filtered[0].ExternalId)
+	}
+}
+
+func TestMeiliFilters(t *testing.T) {
+	assert.Equal(t, []interface{}{
+		`source IN ["library", "own_storage"]`,
+		"in_library = false",
+		`genres IN ["pop", "hip \"hop\""]`,
+		"mood_id IN [1, 2]",
+		"duration >= 60",
+	}, meiliFilters(SearchRequest{
+		Genres:      []string{"Pop", `Hip "Hop"`},
+		MoodIds:     []int64{1, 2},
+		MinDuration: null.FloatFrom(60),
+	}, []Source{SourceLibrary, SourceOwnStorage}))
+}
+
+func TestFacetValues(t *testing.T) {
+	assert.Equal(t, []FacetValue{{Value: "rock", Count: 3}, {Value: "jazz", Count: 1}, {Value: "pop", Count: 1}},
+		facetValues(map[string]int64{"pop": 1, "rock": 3, "jazz": 1}, nil))
+
+	assert.Equal(t, []FacetValue{{Id: 2, Value: "Sad", Count: 5}, {Id: 1, Value: "Happy", Count: 1}},
+		facetValues(map[string]int64{"1": 1, "2": 5, "0": 7}, map[int64]string{1: "Happy", 2: "Sad"}))
+}
@@ -0,0 +1,103 @@
+package search
+
+import (
+	"github.com/digitalmonsters/music/pkg/database"
+	"gopkg.in/guregu/null.v4"
+)
+
+type Source string
+
+const (
+	SourceLibrary     = Source("library")     // songs from playlists
+	SourceOwnStorage  = Source("own_storage") // music_storage, not published to users until added to a playlist
+	SourceCreator     = Source("creator")
+	SourceSoundStripe = Source("soundstripe") // external api, not stored in db
+)
+
+// UserSources are sources available in the app, AdminSources are available in the admin panel
+var UserSources = []Source{SourceLibrary, SourceCreator, SourceSoundStripe}
+var AdminSources = []Source{SourceLibrary, SourceOwnStorage, SourceCreator, SourceSoundStripe}
+
+type SearchRequest struct {
+	Query       string     `json:"query"`
+	Sources     []Source   `json:"sources"` // all allowed sources when empty
+	Genres      []string   `json:"genres"`
+	MoodIds     []int64    `json:"mood_ids"`
+	CategoryIds []int64    `json:"category_ids"`
+	MinDuration null.Float `json:"min_duration"` // seconds
+	MaxDuration null.Float `json:"max_duration"`
+	Page        int        `json:"page"`
+	Size        int        `json:"size"`
+}
+
+func (r SearchRequest) hasFacetFilters() bool {
+	return len(r.MoodIds) > 0 || len(r.CategoryIds) > 0
+}
+
+type SearchItem struct {
+	Source     Source              `json:"source"`
+	Id         int64               `json:"id"` // 0 for soundstripe songs
+	ExternalId string              `json:"external_id"`
+	SongSource database.SongSource `json:"song_source"` // source of library songs
+	UserId     int64               `json:"user_id"`     // author of creator songs
+	Title      string              `json:"title"`
+	Artist     string              `json:"artist"`
+	ImageUrl   string              `json:"image_url"`
+	Genre      string              `json:"genre"` // category name for creator songs
+	CategoryId int64               `json:"category_id"`
+	Category   string              `json:"category"`
+	MoodId     int64               `json:"mood_id"`
+	Mood       string              `json:"mood"`
+	Duration   float64             `json:"duration"`
+	Hashtags   []string            `json:"hashtags"`
+	Popularity int64               `json:"-"`
+	InLibrary  bool                `json:"-"` // own storage song added to a playlist
+	Rank       float64             `json:"rank"`
+}
+
+type FacetValue struct {
+	Id    int64  `json:"id,omitempty"`
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+type Facets struct {
+	Genres     []FacetValue `json:"genres"`
+	Moods      []FacetValue `json:"moods"`
+	Categories []FacetValue `json:"categories"`
+	Durations  []FacetValue `json:"durations"`
+}
+
+type SearchResponse struct {
+	Items      []SearchItem `json:"items"`
+	Facets     Facets       `json:"facets"` // computed over db sources only
+	TotalCount int64        `json:"total_count"`
+}
+
+// backendResult is a page of db sources found by a backend
+type backendResult struct {
+	Items      []SearchItem
+	Facets     Facets
+	TotalCount int64
+}
+
+// duration buckets of the duration facet, seconds
+const (
+	durationUnder1m = "under_1m"
+	duration1mTo3m  = "1m_3m"
+	duration3mTo5m  = "3m_5m"
+	durationOver5m  = "over_5m"
+)
+
+func durationBucket(duration float64) string {
+	switch {
+	case duration < 60:
+		return durationUnder1m
+	case duration < 180:
+		return duration1mTo3m
+	case duration < 300:
+		return duration3mTo5m
+	default:
+		return durationOver5m
+	}
+}
@@ -150,12 +150,10 @@ func PlaylistSongListAdmin(req PlaylistSongListRequest, db *gorm.DB) (*PlaylistS
 	}, nil
 }
 
+// GetSongUrl returns playable url of the song. Listens are counted by listen events, not by url requests
 func GetSongUrl(req GetSongUrlRequest, db *gorm.DB, apmTransaction *apm.Transaction, service *music_source.MusicStorageService, ctx context.Context) (map[string]string, error) {
-	tx := db.Begin()
-	defer tx.Rollback()
-
 	var song database.Song
-	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&song, req.SongId).Error; err != nil {
+	if err := db.First(&song, req.SongId).Error; err != nil {
 		return nil, errors.WithStack(err)
 	}
 
@@ -164,14 +162,5 @@ func GetSongUrl(req GetSongUrlRequest, db *gorm.DB, apmTransaction *apm.Transact
 		return nil, errors.WithStack(err)
 	}
 
-	if err = tx.Model(&song).Where("id = ?", song.Id).
-		Update("listen_amount", gorm.Expr("listen_amount + 1")).Error; err != nil {
-		return nil, errors.WithStack(err)
-	}
-
-	if err = tx.Commit().Error; err != nil {
-		return nil, errors.WithStack(err)
-	}
-
 	return data, nil
 }
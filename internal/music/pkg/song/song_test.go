@@ -18,7 +18,7 @@ var musicStorageService *music_source.MusicStorageService
 func TestMain(m *testing.M) {
 	config = configs.GetConfig()
 	gormDb = database.GetDb(database.DbTypeMaster)
-	musicStorageService = music_source.NewMusicStorageService(&config)
+	musicStorageService = music_source.NewMusicStorageService(nil, &config)
 
 	os.Exit(m.Run())
 }
@@ -0,0 +1,569 @@
+package uploader
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/RichardKnop/machinery/v1"
+	"github.com/RichardKnop/machinery/v1/tasks"
+	"github.com/digitalmonsters/go-common/apm_helper"
+	"github.com/digitalmonsters/go-common/application"
+	"github.com/digitalmonsters/go-common/boilerplate"
+	"github.com/digitalmonsters/go-common/s3"
+	"github.com/digitalmonsters/music/configs"
+	"github.com/digitalmonsters/music/pkg/database"
+	"github.com/digitalmonsters/music/utils"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"github.com/thoas/go-funk"
+	"gopkg.in/guregu/null.v4"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const cleanupTaskName = "music:uploads:cleanup"
+
+// minChunkSize is the minimal size of s3 multipart upload part
+const minChunkSize = 5 << 20
+
+var ErrOffsetMismatch = errors.New("chunk offset does not match uploaded size")
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+var ErrFileRejected = errors.New("file rejected")
+
+// SessionService handles resumable uploads: client creates a session, sends chunks with offsets and finalizes it.
+// Chunks are streamed to s3 multipart upload, only one chunk is kept in memory
+type SessionService struct {
+	cfg       configs.UploadsConfig
+	s3Cfg     boilerplate.S3Config
+	uploader  s3.IUploader
+	appConfig *application.Configurator[configs.AppConfig]
+}
+
+func NewSessionService(jobber *machinery.Server, cfg configs.Settings, appConfig *application.Configurator[configs.AppConfig]) *SessionService {
+	s := &SessionService{
+		cfg:       cfg.Uploads,
+		s3Cfg:     cfg.S3,
+		uploader:  s3.NewUploader(&cfg.S3),
+		appConfig: appConfig,
+	}
+
+	if boilerplate.GetCurrentEnvironment() != boilerplate.Ci {
+		if err := s.registerCleanupTask(jobber); err != nil {
+			log.Fatal().Err(err).Msg("[Music] can not register upload sessions cleanup task")
+		}
+	}
+
+	return s
+}
+
+func (s *SessionService) CreateSession(req CreateSessionRequest, userId int64, allowedTypes []UploadType, db *gorm.DB) (*SessionResponse, error) {
+	if !funk.Contains(allowedTypes, req.UploadType) {
+		return nil, errors.New("upload type is not allowed")
+	}
+
+	if req.Size <= 0 {
+		return nil, errors.New("invalid file size")
+	}
+
+	if s.cfg.MaxFileSizeMb > 0 && req.Size > int64(s.cfg.MaxFileSizeMb)<<20 {
+		return nil, errors.New("file is too large")
+	}
+
+	ext, err := getFileExtension(req.Filename)
+	if err != nil {
+		return nil, err
+	}
+
+	if !checkFileExtension(req.UploadType, ext) {
+		return nil, errors.New("wrong file extension")
+	}
+
+	req.Checksum = strings.ToLower(req.Checksum)
+	if req.Checksum != "" {
+		if b, err := hex.DecodeString(req.Checksum); err != nil || len(b) != sha256.Size {
+			return nil, errors.New("checksum should be sha256 hex")
+		}
+	}
+
+	if req.ContentType == "" {
+		req.ContentType = "application/octet-stream"
+	}
+
+	now := time.Now().UTC()
+
+	session := database.UploadSession{
+		UserId:      userId,
+		UploadType:  int(req.UploadType),
+		Status:      database.UploadSessionStatusActive,
+		Direct:      req.Direct,
+		Filename:    req.Filename,
+		ContentType: req.ContentType,
+		Path: filepath.Join(req.UploadType.pathPrefix(), req.UploadType.ToString(),
+			fmt.Sprintf("%v.%v", uuid.New().String(), ext)),
+		TotalSize: req.Size,
+		ChunkSize: s.ChunkSize(),
+		Checksum:  req.Checksum,
+		ExpiresAt: now.Add(time.Duration(s.cfg.SessionTtlHours) * time.Hour),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	var uploadUrl string
+
+	if session.Direct {
+		uploadUrl, err = s.uploader.PutObjectSignedUrl(session.Path,
+			time.Duration(s.cfg.PresignedUrlTtlMinutes)*time.Minute, "")
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+	} else {
+		if session.S3UploadId, err = s.uploader.CreateMultipartUpload(session.Path, session.ContentType); err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		if session.HashState, err = marshalHash(sha256.New()); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := db.Create(&session).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return &SessionResponse{
+		UploadSession: session,
+		UploadUrl:     uploadUrl,
+	}, nil
+}
+
+// GetSession returns session state, client resumes upload from UploadedSize
+func (s *SessionService) GetSession(req SessionRequest, userId int64, allowedTypes []UploadType, db *gorm.DB) (*database.UploadSession, error) {
+	return s.findSession(req.Id, userId, allowedTypes, db)
+}
+
+// UploadChunk appends chunk at offset. Offset should be equal to uploaded size, otherwise ErrOffsetMismatch is
+// returned and client should request session to resume. Chunk md5 is verified when contentMd5 (base64) is passed
+func (s *SessionService) UploadChunk(sessionId int64, offset int64, body io.Reader, contentMd5 string, userId int64,
+	allowedTypes []UploadType, db *gorm.DB) (*database.UploadSession, error) {
+	session, err := s.findSession(sessionId, userId, allowedTypes, db)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkActive(session); err != nil {
+		return nil, err
+	}
+
+	if session.Direct {
+		return nil, errors.New("direct session does not accept chunks")
+	}
+
+	if offset != session.UploadedSize {
+		return nil, ErrOffsetMismatch
+	}
+
+	expectedSize := session.TotalSize - offset
+	if expectedSize > session.ChunkSize {
+		expectedSize = session.ChunkSize
+	}
+
+	if expectedSize <= 0 {
+		return nil, errors.New("all chunks are already uploaded")
+	}
+
+	chunk, err := readChunk(body, expectedSize)
+	if err != nil {
+		return nil, err
+	}
+
+	if contentMd5 != "" {
+		sum := md5.Sum(chunk)
+		if base64.StdEncoding.EncodeToString(sum[:]) != contentMd5 {
+			return nil, ErrChecksumMismatch
+		}
+	}
+
+	fileHash, err := unmarshalHash(session.HashState)
+	if err != nil {
+		return nil, err
+	}
+
+	fileHash.Write(chunk)
+
+	hashState, err := marshalHash(fileHash)
+	if err != nil {
+		return nil, err
+	}
+
+	partNumber := offset/session.ChunkSize + 1
+
+	// same part number overwrites previous upload in s3, so retry of the chunk is safe before offset is saved
+	etag, err := s.uploader.UploadPart(session.Path, session.S3UploadId, partNumber, bytes.NewReader(chunk))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	tx := db.Begin()
+	defer tx.Rollback()
+
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(session, session.Id).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if err := checkActive(session); err != nil {
+		return nil, err
+	}
+
+	if session.UploadedSize != offset {
+		return nil, ErrOffsetMismatch
+	}
+
+	if err := tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "session_id"}, {Name: "part_number"}},
+		DoUpdates: clause.AssignmentColumns([]string{"size", "etag"}),
+	}).Create(&database.UploadSessionPart{
+		SessionId:  session.Id,
+		PartNumber: partNumber,
+		Size:       int64(len(chunk)),
+		ETag:       etag,
+		CreatedAt:  time.Now().UTC(),
+	}).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if err := tx.Model(session).Updates(map[string]interface{}{
+		"uploaded_size": offset + int64(len(chunk)),
+		"hash_state":    hashState,
+		"updated_at":    time.Now().UTC(),
+	}).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	session.UploadedSize = offset + int64(len(chunk))
+	session.HashState = hashState
+
+	return session, nil
+}
+
+// FinalizeSession completes upload and verifies its checksum. File is available by returned url after that
+func (s *SessionService) FinalizeSession(req SessionRequest, userId int64, allowedTypes []UploadType, db *gorm.DB) (*FinalizeSessionResponse, error) {
+	tx := db.Begin()
+	defer tx.Rollback()
+
+	session, err := s.findSession(req.Id, userId, allowedTypes, tx.Clauses(clause.Locking{Strength: "UPDATE"}))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkActive(session); err != nil {
+		return nil, err
+	}
+
+	var checksum string
+
+	if session.Direct {
+		checksum, err = s.verifyDirectUpload(session)
+	} else {
+		checksum, err = s.completeMultipartUpload(session, tx)
+	}
+
+	var duration null.Float
+	uploadType := UploadType(session.UploadType)
+
+	if err == nil && uploadType.isMusic() && strings.HasSuffix(session.Path, ".mp3") {
+		if duration, err = s.readDuration(session.Path); err == nil {
+			if validationErr := validateDuration(uploadType, duration.ValueOrZero(), s.appConfig); validationErr != nil {
+				err = errors.Wrap(ErrFileRejected, validationErr.Error())
+			}
+		}
+	}
+
+	if errors.Is(err, ErrChecksumMismatch) || errors.Is(err, ErrFileRejected) {
+		// file can not be used, client starts a new session
+		if abortErr := s.abort(session, tx); abortErr != nil {
+			return nil, abortErr
+		}
+
+		if commitErr := tx.Commit().Error; commitErr != nil {
+			return nil, errors.WithStack(commitErr)
+		}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+
+	if err := tx.Model(session).Updates(map[string]interface{}{
+		"status":       database.UploadSessionStatusCompleted,
+		"checksum":     checksum,
+		"updated_at":   now,
+		"completed_at": null.TimeFrom(now),
+	}).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return &FinalizeSessionResponse{
+		uploadResponse: uploadResponse{
+			FileUrl:  s.cdnUrl(session.Path),
+			Size:     session.TotalSize,
+			Duration: duration,
+		},
+		SessionId: session.Id,
+		Checksum:  checksum,
+	}, nil
+}
+
+func (s *SessionService) AbortSession(req SessionRequest, userId int64, allowedTypes []UploadType, db *gorm.DB) error {
+	tx := db.Begin()
+	defer tx.Rollback()
+
+	session, err := s.findSession(req.Id, userId, allowedTypes, tx.Clauses(clause.Locking{Strength: "UPDATE"}))
+	if err != nil {
+		return err
+	}
+
+	if session.Status != database.UploadSessionStatusActive {
+		return errors.New("upload session is not active")
+	}
+
+	if err := s.abort(session, tx); err != nil {
+		return err
+	}
+
+	return errors.WithStack(tx.Commit().Error)
+}
+
+// GetCompletedSession returns finished upload of the user, e.g. to pass uploaded source file to processing
+func (s *SessionService) GetCompletedSession(id int64, userId int64, uploadType UploadType, db *gorm.DB) (*database.UploadSession, error) {
+	session, err := s.findSession(id, userId, []UploadType{uploadType}, db)
+	if err != nil {
+		return nil, err
+	}
+
+	if session.Status != database.UploadSessionStatusCompleted {
+		return nil, errors.New("upload session is not completed")
+	}
+
+	return session, nil
+}
+
+// CleanupExpired aborts expired sessions, so s3 does not keep their uploaded parts
+func (s *SessionService) CleanupExpired(db *gorm.DB, ctx context.Context) error {
+	var sessions []database.UploadSession
+	if err := db.Where("status = ? and expires_at < ?", database.UploadSessionStatusActive, time.Now().UTC()).
+		Limit(1000).Find(&sessions).Error; err != nil {
+		return errors.WithStack(err)
+	}
+
+	for i := range sessions {
+		if err := s.abort(&sessions[i], db); err != nil {
+			utils.CaptureApmErrorFromTransaction(err, ctx)
+		}
+	}
+
+	return nil
+}
+
+func (s *SessionService) findSession(id int64, userId int64, allowedTypes []UploadType, db *gorm.DB) (*database.UploadSession, error) {
+	var session database.UploadSession
+	if err := db.Where("id = ? and user_id = ? and upload_type in ?", id, userId, allowedTypes).
+		Find(&session).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if session.Id == 0 {
+		return nil, errors.New("upload session not found")
+	}
+
+	return &session, nil
+}
+
+func (s *SessionService) completeMultipartUpload(session *database.UploadSession, tx *gorm.DB) (string, error) {
+	if session.UploadedSize != session.TotalSize {
+		return "", errors.New(fmt.Sprintf("upload is not finished, uploaded %v of %v bytes",
+			session.UploadedSize, session.TotalSize))
+	}
+
+	fileHash, err := unmarshalHash(session.HashState)
+	if err != nil {
+		return "", err
+	}
+
+	checksum := hex.EncodeToString(fileHash.Sum(nil))
+	if session.Checksum != "" && session.Checksum != checksum {
+		return "", ErrChecksumMismatch
+	}
+
+	var parts []database.UploadSessionPart
+	if err := tx.Where("session_id = ?", session.Id).Order("part_number asc").Find(&parts).Error; err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	var completedParts []s3.CompletedPart
+	for _, p := range parts {
+		completedParts = append(completedParts, s3.CompletedPart{
+			PartNumber: p.PartNumber,
+			ETag:       p.ETag,
+		})
+	}
+
+	if err := s.uploader.CompleteMultipartUpload(session.Path, session.S3UploadId, completedParts); err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	return checksum, nil
+}
+
+// verifyDirectUpload checks file put by presigned url. Checksum is calculated by streaming file back from storage
+func (s *SessionService) verifyDirectUpload(session *database.UploadSession) (string, error) {
+	size, err := s.uploader.GetObjectSize(session.Path)
+	if err != nil {
+		return "", errors.Wrap(err, "file is not uploaded")
+	}
+
+	if size != session.TotalSize {
+		return "", errors.New(fmt.Sprintf("uploaded file size %v does not match %v", size, session.TotalSize))
+	}
+
+	if session.Checksum == "" {
+		return "", nil
+	}
+
+	body, err := s.uploader.GetObject(session.Path)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	defer body.Close()
+
+	fileHash := sha256.New()
+	if _, err := io.Copy(fileHash, body); err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	if checksum := hex.EncodeToString(fileHash.Sum(nil)); checksum != session.Checksum {
+		return "", ErrChecksumMismatch
+	}
+
+	return session.Checksum, nil
+}
+
+func (s *SessionService) readDuration(path string) (null.Float, error) {
+	body, err := s.uploader.GetObject(path)
+	if err != nil {
+		return null.Float{}, errors.WithStack(err)
+	}
+
+	defer body.Close()
+
+	return null.FloatFrom(getSongDuration(body)), nil
+}
+
+func (s *SessionService) abort(session *database.UploadSession, db *gorm.DB) error {
+	if !session.Direct && session.S3UploadId != "" {
+		if err := s.uploader.AbortMultipartUpload(session.Path, session.S3UploadId); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	return errors.WithStack(db.Model(session).Updates(map[string]interface{}{
+		"status":     database.UploadSessionStatusAborted,
+		"updated_at": time.Now().UTC(),
+	}).Error)
+}
+
+func (s *SessionService) registerCleanupTask(jobber *machinery.Server) error {
+	if err := jobber.RegisterTask(cleanupTaskName, func() error {
+		var apmTransaction = apm_helper.StartNewApmTransaction(cleanupTaskName, "task", nil, nil)
+		defer apmTransaction.End()
+		ctx := boilerplate.CreateCustomContext(context.Background(), apmTransaction, log.Logger)
+
+		return s.CleanupExpired(database.GetDb(database.DbTypeMaster).WithContext(ctx), ctx)
+	}); err != nil {
+		return err
+	}
+
+	return utils.RegisterPeriodicTask(jobber, "@every 1h", cleanupTaskName, []tasks.Arg{}, true)
+}
+
+// ChunkSize is the size of every chunk except the last one
+func (s *SessionService) ChunkSize() int64 {
+	size := int64(s.cfg.ChunkSizeMb) << 20
+	if size < minChunkSize {
+		return minChunkSize
+	}
+
+	return size
+}
+
+func (s *SessionService) cdnUrl(path string) string {
+	return fmt.Sprintf("%v/%v", s.s3Cfg.CdnUrl, path)
+}
+
+func checkActive(session *database.UploadSession) error {
+	if session.Status != database.UploadSessionStatusActive {
+		return errors.New("upload session is not active")
+	}
+
+	if session.ExpiresAt.Before(time.Now()) {
+		return errors.New("upload session is expired")
+	}
+
+	return nil
+}
+
+// readChunk reads exactly size bytes and fails when body is shorter or longer
+func readChunk(body io.Reader, size int64) ([]byte, error) {
+	chunk := make([]byte, size)
+
+	if _, err := io.ReadFull(body, chunk); err != nil {
+		return nil, errors.New(fmt.Sprintf("chunk should be %v bytes", size))
+	}
+
+	if n, _ := body.Read(make([]byte, 1)); n > 0 {
+		return nil, errors.New(fmt.Sprintf("chunk should be %v bytes", size))
+	}
+
+	return chunk, nil
+}
+
+func marshalHash(h hash.Hash) ([]byte, error) {
+	state, err := h.(encoding.BinaryMarshaler).MarshalBinary()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return state, nil
+}
+
+func unmarshalHash(state []byte) (hash.Hash, error) {
+	h := sha256.New()
+
+	if err := h.(encoding.BinaryUnmarshaler).UnmarshalBinary(state); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return h, nil
+}
@@ -0,0 +1,212 @@
+package uploader
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/digitalmonsters/go-common/boilerplate_testing"
+	"github.com/digitalmonsters/go-common/s3"
+	"github.com/digitalmonsters/music/configs"
+	"github.com/digitalmonsters/music/pkg/database"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+var config configs.Settings
+var gormDb *gorm.DB
+
+func TestMain(m *testing.M) {
+	config = configs.GetConfig()
+	gormDb = database.GetDb(database.DbTypeMaster)
+	os.Exit(m.Run())
+}
+
+type uploadedPart struct {
+	number int64
+	body   []byte
+}
+
+func newTestSessionService(parts *[]uploadedPart, completed *[]s3.CompletedPart, aborted *bool) *SessionService {
+	return &SessionService{
+		cfg: configs.UploadsConfig{
+			ChunkSizeMb:     5,
+			MaxFileSizeMb:   100,
+			SessionTtlHours: 1,
+		},
+		uploader: &s3.UploaderMock{
+			CreateMultipartUploadFn: func(path string, contentType string) (string, error) {
+				return "upload-id", nil
+			},
+			UploadPartFn: func(path string, uploadId string, partNumber int64, body io.ReadSeeker) (string, error) {
+				b, err := io.ReadAll(body)
+				*parts = append(*parts, uploadedPart{number: partNumber, body: b})
+
+				return "etag", err
+			},
+			CompleteMultipartUploadFn: func(path string, uploadId string, p []s3.CompletedPart) error {
+				*completed = p
+				return nil
+			},
+			AbortMultipartUploadFn: func(path string, uploadId string) error {
+				*aborted = true
+				return nil
+			},
+		},
+		appConfig: configs.GetAppConfigurator(),
+	}
+}
+
+func TestSessionService_ChunkedUpload(t *testing.T) {
+	if err := boilerplate_testing.FlushPostgresTables(config.MasterDb, []string{"public.upload_sessions",
+		"public.upload_session_parts"}, nil, t); err != nil {
+		t.Fatal(err)
+	}
+
+	var parts []uploadedPart
+	var completed []s3.CompletedPart
+	var aborted bool
+	service := newTestSessionService(&parts, &completed, &aborted)
+
+	file := bytes.Repeat([]byte{1, 2, 3, 4}, (minChunkSize+100)/4)
+	sum := sha256.Sum256(file)
+	userId := int64(10)
+
+	session, err := service.CreateSession(CreateSessionRequest{
+		UploadType: UploadTypeCreatorsSongSource,
+		Filename:   "song.wav",
+		Size:       int64(len(file)),
+		Checksum:   hex.EncodeToString(sum[:]),
+	}, userId, CreatorUploadTypes, gormDb)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(minChunkSize), session.ChunkSize)
+
+	_, err = service.CreateSession(CreateSessionRequest{
+		UploadType: UploadTypeAdminMusic,
+		Filename:   "song.mp3",
+		Size:       100,
+	}, userId, CreatorUploadTypes, gormDb)
+	assert.NotNil(t, err)
+
+	first := file[:minChunkSize]
+	firstMd5 := md5.Sum(first)
+
+	_, err = service.UploadChunk(session.Id, 0, bytes.NewReader(first), "wrong", userId, CreatorUploadTypes, gormDb)
+	assert.ErrorIs(t, err, ErrChecksumMismatch)
+
+	updated, err := service.UploadChunk(session.Id, 0, bytes.NewReader(first),
+		base64.StdEncoding.EncodeToString(firstMd5[:]), userId, CreatorUploadTypes, gormDb)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(minChunkSize), updated.UploadedSize)
+
+	// retry of already saved chunk
+	_, err = service.UploadChunk(session.Id, 0, bytes.NewReader(first), "", userId, CreatorUploadTypes, gormDb)
+	assert.ErrorIs(t, err, ErrOffsetMismatch)
+
+	_, err = service.FinalizeSession(SessionRequest{Id: session.Id}, userId, CreatorUploadTypes, gormDb)
+	assert.NotNil(t, err)
+
+	_, err = service.UploadChunk(session.Id, minChunkSize, bytes.NewReader(file[minChunkSize:]), "", userId,
+		CreatorUploadTypes, gormDb)
+	assert.Nil(t, err)
+
+	resp, err := service.FinalizeSession(SessionRequest{Id: session.Id}, userId, CreatorUploadTypes, gormDb)
+	assert.Nil(t, err)
+	assert.Equal(t, hex.EncodeToString(sum[:]), resp.Checksum)
+	assert.Equal(t, int64(len(file)), resp.Size)
+	assert.Equal(t, []s3.CompletedPart{{PartNumber: 1, ETag: "etag"}, {PartNumber: 2, ETag: "etag"}}, completed)
+	assert.False(t, aborted)
+
+	if assert.Len(t, parts, 2) {
+		assert.Equal(t, int64(2), parts[1].number)
+		assert.Equal(t, file, append(parts[0].body, parts[1].body...))
+	}
+
+	stored, err := service.GetCompletedSession(session.Id, userId, UploadTypeCreatorsSongSource, gormDb)
+	assert.Nil(t, err)
+	assert.Equal(t, database.UploadSessionStatusCompleted, stored.Status)
+}
+
+func TestSessionService_ChecksumMismatch(t *testing.T) {
+	if err := boilerplate_testing.FlushPostgresTables(config.MasterDb, []string{"public.upload_sessions",
+		"public.upload_session_parts"}, nil, t); err != nil {
+		t.Fatal(err)
+	}
+
+	var parts []uploadedPart
+	var completed []s3.CompletedPart
+	var aborted bool
+	service := newTestSessionService(&parts, &completed, &aborted)
+
+	userId := int64(11)
+	sum := sha256.Sum256([]byte("another file"))
+
+	session, err := service.CreateSession(CreateSessionRequest{
+		UploadType: UploadTypeCreatorsSongImage,
+		Filename:   "cover.png",
+		Size:       4,
+		Checksum:   hex.EncodeToString(sum[:]),
+	}, userId, CreatorUploadTypes, gormDb)
+	assert.Nil(t, err)
+
+	_, err = service.UploadChunk(session.Id, 0, bytes.NewReader([]byte{1, 2, 3, 4, 5}), "", userId,
+		CreatorUploadTypes, gormDb)
+	assert.NotNil(t, err)
+
+	_, err = service.UploadChunk(session.Id, 0, bytes.NewReader([]byte{1, 2, 3, 4}), "", userId,
+		CreatorUploadTypes, gormDb)
+	assert.Nil(t, err)
+
+	_, err = service.FinalizeSession(SessionRequest{Id: session.Id}, userId, CreatorUploadTypes, gormDb)
+	assert.ErrorIs(t, err, ErrChecksumMismatch)
+	assert.True(t, aborted)
+	assert.Nil(t, completed)
+
+	var stored database.UploadSession
+	if err := gormDb.First(&stored, session.Id).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, database.UploadSessionStatusAborted, stored.Status)
+}
+
+func TestSessionService_CleanupExpired(t *testing.T) {
+	if err := boilerplate_testing.FlushPostgresTables(config.MasterDb, []string{"public.upload_sessions",
+		"public.upload_session_parts"}, nil, t); err != nil {
+		t.Fatal(err)
+	}
+
+	var parts []uploadedPart
+	var completed []s3.CompletedPart
+	var aborted bool
+	service := newTestSessionService(&parts, &completed, &aborted)
+
+	session, err := service.CreateSession(CreateSessionRequest{
+		UploadType: UploadTypeCreatorsSongSource,
+		Filename:   "song.flac",
+		Size:       100,
+	}, 12, CreatorUploadTypes, gormDb)
+	assert.Nil(t, err)
+
+	if err := gormDb.Model(&database.UploadSession{}).Where("id = ?", session.Id).
+		Update("expires_at", time.Now().Add(-time.Minute)).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Nil(t, service.CleanupExpired(gormDb, context.TODO()))
+	assert.True(t, aborted)
+
+	var stored database.UploadSession
+	if err := gormDb.First(&stored, session.Id).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, database.UploadSessionStatusAborted, stored.Status)
+}
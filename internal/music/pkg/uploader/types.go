@@ -1,6 +1,9 @@
 package uploader
 
-import "gopkg.in/guregu/null.v4"
+import (
+	"github.com/digitalmonsters/music/pkg/database"
+	"gopkg.in/guregu/null.v4"
+)
 
 type uploadResponse struct {
 	FileUrl  string     `json:"file_url"`
@@ -11,13 +14,19 @@ type uploadResponse struct {
 type UploadType int
 
 const (
-	UploadTypeNone              = UploadType(0)
-	UploadTypeAdminMusic        = UploadType(1)
-	UploadTypeCreatorsSongFull  = UploadType(2)
-	UploadTypeCreatorsSongShort = UploadType(3)
-	UploadTypeCreatorsSongImage = UploadType(4)
+	UploadTypeNone               = UploadType(0)
+	UploadTypeAdminMusic         = UploadType(1)
+	UploadTypeCreatorsSongFull   = UploadType(2)
+	UploadTypeCreatorsSongShort  = UploadType(3)
+	UploadTypeCreatorsSongImage  = UploadType(4)
+	UploadTypeCreatorsSongSource = UploadType(5) // original file for audio processing
 )
 
+var CreatorUploadTypes = []UploadType{UploadTypeCreatorsSongFull, UploadTypeCreatorsSongShort,
+	UploadTypeCreatorsSongImage, UploadTypeCreatorsSongSource}
+
+var AdminUploadTypes = []UploadType{UploadTypeAdminMusic}
+
 func (t UploadType) ToString() string {
 	switch t {
 	case UploadTypeAdminMusic:
@@ -28,7 +37,45 @@ func (t UploadType) ToString() string {
 		return "short"
 	case UploadTypeCreatorsSongImage:
 		return "image"
+	case UploadTypeCreatorsSongSource:
+		return "source"
 	default:
 		return "unk"
 	}
 }
+
+func (t UploadType) pathPrefix() string {
+	if t >= UploadTypeCreatorsSongFull && t <= UploadTypeCreatorsSongSource {
+		return "creator"
+	}
+
+	return ""
+}
+
+func (t UploadType) isMusic() bool {
+	return t == UploadTypeAdminMusic || t == UploadTypeCreatorsSongFull || t == UploadTypeCreatorsSongShort
+}
+
+type CreateSessionRequest struct {
+	UploadType  UploadType `json:"upload_type"`
+	Filename    string     `json:"filename"`
+	Size        int64      `json:"size"`
+	ContentType string     `json:"content_type"`
+	Checksum    string     `json:"checksum"` // optional sha256 hex of the whole file
+	Direct      bool       `json:"direct"`   // upload file directly to storage with presigned url
+}
+
+type SessionRequest struct {
+	Id int64 `json:"id"`
+}
+
+type SessionResponse struct {
+	database.UploadSession
+	UploadUrl string `json:"upload_url,omitempty"` // presigned PUT url for direct sessions
+}
+
+type FinalizeSessionResponse struct {
+	uploadResponse
+	SessionId int64  `json:"session_id"`
+	Checksum  string `json:"checksum"`
+}
@@ -1,7 +1,6 @@
 package uploader
 
 import (
-	"bytes"
 	"crypto/md5"
 	"fmt"
 	"github.com/digitalmonsters/go-common/application"
@@ -10,23 +9,27 @@ import (
 	"github.com/pkg/errors"
 	"github.com/tcolgate/mp3"
 	"github.com/thoas/go-funk"
-	"github.com/valyala/fasthttp"
 	"gopkg.in/guregu/null.v4"
 	"io"
+	"net/http"
 	"path/filepath"
 	"strings"
 )
 
 var extensionsForMusic = []string{"mp3"}
 var extensionsForImage = []string{"jpg", "jpeg", "png"}
+var extensionsForSource = []string{"mp3", "wav", "flac", "m4a", "aac", "ogg", "opus", "aif", "aiff"}
 
-func FileUpload(cfg *configs.Settings, appConfig *application.Configurator[configs.AppConfig], uploadType UploadType, ctx *fasthttp.RequestCtx) (*uploadResponse, error) {
-	m, err := ctx.Request.MultipartForm()
-	if err != nil {
-		return nil, err
+const maxMultipartMemory = 32 << 20
+
+func FileUpload(cfg *configs.Settings, appConfig *application.Configurator[configs.AppConfig], uploadType UploadType, r *http.Request) (*uploadResponse, error) {
+	if err := r.ParseMultipartForm(maxMultipartMemory); err != nil {
+		return nil, errors.WithStack(err)
 	}
 
-	files := m.File["File"]
+	defer r.MultipartForm.RemoveAll()
+
+	files := r.MultipartForm.File["File"]
 	if len(files) == 0 {
 		return nil, errors.New("no file found")
 	}
@@ -40,18 +43,16 @@ func FileUpload(cfg *configs.Settings, appConfig *application.Configurator[confi
 	header := files[0]
 	size := header.Size
 
-	f := strings.Split(header.Filename, ".")
-
-	if len(f) < 2 {
-		return nil, errors.New("invalid file format")
+	fileExtension, err := getFileExtension(header.Filename)
+	if err != nil {
+		return nil, err
 	}
 
-	fileExtension := f[len(f)-1]
-
 	if !checkFileExtension(uploadType, fileExtension) {
 		return nil, errors.New("wrong file extension")
 	}
 
+	// large files are stored on disk by multipart reader, so file is read in a stream and rewound for every pass
 	openedFile, err := header.Open()
 	if err != nil {
 		return nil, err
@@ -59,49 +60,38 @@ func FileUpload(cfg *configs.Settings, appConfig *application.Configurator[confi
 
 	defer openedFile.Close()
 
-	body := make([]byte, size)
-
-	_, err = openedFile.Read(body)
-	if err != nil {
-		return nil, err
+	hash := md5.New()
+	if _, err = io.Copy(hash, openedFile); err != nil {
+		return nil, errors.WithStack(err)
 	}
 
-	fileId := fmt.Sprintf("%x", md5.Sum(body))
-
-	filename := fmt.Sprintf("%s.%s", fileId, f[len(f)-1])
+	fileId := fmt.Sprintf("%x", hash.Sum(nil))
 
-	var filePath string
-	if uploadType >= UploadTypeCreatorsSongFull && uploadType <= UploadTypeCreatorsSongImage { //creator upload
-		filePath = "creator"
-	}
+	filename := fmt.Sprintf("%s.%s", fileId, fileExtension)
 
 	var duration null.Float
 
 	if fileExtension == "mp3" {
-		duration = null.FloatFrom(getSongDuration(body))
-		if uploadType == UploadTypeCreatorsSongFull {
-			if int(duration.ValueOrZero()) > appConfig.Values.MUSIC_FULL_VERSION_MAX_DURATION {
-				return nil, errors.New("song duration is greater than max song duration")
-			}
+		if _, err = openedFile.Seek(0, io.SeekStart); err != nil {
+			return nil, errors.WithStack(err)
 		}
 
-		if uploadType == UploadTypeCreatorsSongShort {
-			if int(duration.ValueOrZero()) > appConfig.Values.MUSIC_SHORT_VERSION_MAX_DURATION {
-				return nil, errors.New("song duration is greater than max song duration")
-			}
+		duration = null.FloatFrom(getSongDuration(openedFile))
 
-			if int(duration.ValueOrZero()) < appConfig.Values.MUSIC_SHORT_VERSION_MIN_DURATION {
-				return nil, errors.New("song duration is less than min song duration")
-			}
+		if err = validateDuration(uploadType, duration.ValueOrZero(), appConfig); err != nil {
+			return nil, err
 		}
-
 	}
 
-	filePath = filepath.Join(filePath, uploadType.ToString(), filename)
+	filePath := filepath.Join(uploadType.pathPrefix(), uploadType.ToString(), filename)
 	fileUrl = fmt.Sprintf("%v/%v", cfg.S3.CdnUrl, filePath)
 
+	if _, err = openedFile.Seek(0, io.SeekStart); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
 	uploader := s3.NewUploader(&cfg.S3)
-	if err := uploader.UploadObject(filePath, body, "application/octet-stream"); err != nil {
+	if err := uploader.UploadObjectFromReader(filePath, openedFile, "application/octet-stream"); err != nil {
 		return nil, err
 	}
 
@@ -112,29 +102,59 @@ func FileUpload(cfg *configs.Settings, appConfig *application.Configurator[confi
 	}, nil
 }
 
+func getFileExtension(filename string) (string, error) {
+	f := strings.Split(filename, ".")
+
+	if len(f) < 2 {
+		return "", errors.New("invalid file format")
+	}
+
+	return strings.ToLower(f[len(f)-1]), nil
+}
+
 func checkFileExtension(t UploadType, ext string) bool {
 	switch t {
 	case UploadTypeAdminMusic, UploadTypeCreatorsSongFull, UploadTypeCreatorsSongShort:
 		return funk.ContainsString(extensionsForMusic, ext)
 	case UploadTypeCreatorsSongImage:
 		return funk.ContainsString(extensionsForImage, ext)
+	case UploadTypeCreatorsSongSource:
+		return funk.ContainsString(extensionsForSource, ext)
 	default:
 		return false
 	}
 }
 
-func getSongDuration(body []byte) float64 {
+func validateDuration(uploadType UploadType, duration float64, appConfig *application.Configurator[configs.AppConfig]) error {
+	values := appConfig.GetValues()
+
+	if uploadType == UploadTypeCreatorsSongFull {
+		if int(duration) > values.MUSIC_FULL_VERSION_MAX_DURATION {
+			return errors.New("song duration is greater than max song duration")
+		}
+	}
+
+	if uploadType == UploadTypeCreatorsSongShort {
+		if int(duration) > values.MUSIC_SHORT_VERSION_MAX_DURATION {
+			return errors.New("song duration is greater than max song duration")
+		}
+
+		if int(duration) < values.MUSIC_SHORT_VERSION_MIN_DURATION {
+			return errors.New("song duration is less than min song duration")
+		}
+	}
+
+	return nil
+}
+
+func getSongDuration(r io.Reader) float64 {
 	duration := 0.0
-	r := bytes.NewReader(body)
 	d := mp3.NewDecoder(r)
 	var fr mp3.Frame
 	skipped := 0
 
 	for {
 		if err := d.Decode(&fr, &skipped); err != nil {
-			if err == io.EOF {
-				break
-			}
 			break
 		}
 		duration += fr.Duration().Seconds()
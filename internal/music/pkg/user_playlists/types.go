@@ -0,0 +1,71 @@
+package user_playlists
+
+import (
+	"github.com/digitalmonsters/music/pkg/database"
+	"github.com/digitalmonsters/music/pkg/frontend"
+	"gopkg.in/guregu/null.v4"
+)
+
+const maxNameLength = 100
+const maxDescriptionLength = 500
+
+type CreatePlaylistRequest struct {
+	Name        string                      `json:"name"`
+	Description string                      `json:"description"`
+	Visibility  database.PlaylistVisibility `json:"visibility"` // private when empty
+}
+
+// UpdatePlaylistRequest changes only fields which are set
+type UpdatePlaylistRequest struct {
+	PlaylistId  int64       `json:"playlist_id"`
+	Name        null.String `json:"name"`
+	Description null.String `json:"description"`
+	Visibility  null.Int    `json:"visibility"`
+}
+
+type PlaylistRequest struct {
+	PlaylistId int64 `json:"playlist_id"`
+}
+
+type SongRef struct {
+	SongId   int64                   `json:"song_id"`
+	SongKind database.ListenSongKind `json:"song_kind"`
+}
+
+type PlaylistSongsRequest struct {
+	PlaylistId int64     `json:"playlist_id"`
+	Songs      []SongRef `json:"songs"`
+}
+
+type ListPlaylistsRequest struct {
+	UserId int64  `json:"user_id"` // used by public listing of user playlists
+	Count  int    `json:"count"`
+	Cursor string `json:"cursor"`
+}
+
+type PlaylistModel struct {
+	database.UserPlaylist
+	IsOwner    bool `json:"is_owner"`
+	IsFollowed bool `json:"is_followed"`
+}
+
+type ListPlaylistsResponse struct {
+	Items  []PlaylistModel `json:"items"`
+	Cursor string          `json:"cursor"`
+}
+
+// PlaylistSongItem holds Song for library songs or CreatorSong for creator songs depending on SongKind
+type PlaylistSongItem struct {
+	SongKind    database.ListenSongKind    `json:"song_kind"`
+	Song        *frontend.Song             `json:"song,omitempty"`
+	CreatorSong *frontend.CreatorSongModel `json:"creator_song,omitempty"`
+}
+
+type PlaylistResponse struct {
+	Playlist PlaylistModel      `json:"playlist"`
+	Songs    []PlaylistSongItem `json:"songs"`
+}
+
+type ShareResponse struct {
+	Link string `json:"link"`
+}
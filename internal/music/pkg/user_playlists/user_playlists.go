@@ -0,0 +1,751 @@
+package user_playlists
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/digitalmonsters/go-common/application"
+	"github.com/digitalmonsters/go-common/deeplink"
+	"github.com/digitalmonsters/music/configs"
+	"github.com/digitalmonsters/music/pkg/database"
+	"github.com/digitalmonsters/music/pkg/feed/feed_converter"
+	"github.com/digitalmonsters/music/pkg/frontend"
+	"github.com/pilagod/gorm-cursor-paginator/v2/paginator"
+	"github.com/pkg/errors"
+	"github.com/samber/lo"
+	"go.elastic.co/apm"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Service manages playlists created by users. Admin-curated playlists are managed by playlist package
+type Service struct {
+	feedConverter   *feed_converter.Service
+	deeplinkService deeplink.IService
+	deeplinkConfig  deeplink.Config
+	appConfig       *application.Configurator[configs.AppConfig]
+}
+
+func NewService(feedConverter *feed_converter.Service, deeplinkService deeplink.IService, deeplinkConfig deeplink.Config,
+	appConfig *application.Configurator[configs.AppConfig]) *Service {
+	return &Service{
+		feedConverter:   feedConverter,
+		deeplinkService: deeplinkService,
+		deeplinkConfig:  deeplinkConfig,
+		appConfig:       appConfig,
+	}
+}
+
+func (s *Service) Create(req CreatePlaylistRequest, userId int64, db *gorm.DB) (*PlaylistModel, error) {
+	name, err := validateName(req.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(req.Description) > maxDescriptionLength {
+		return nil, errors.New("description is too long")
+	}
+
+	if req.Visibility == 0 {
+		req.Visibility = database.PlaylistVisibilityPrivate
+	}
+
+	if err := validateVisibility(req.Visibility); err != nil {
+		return nil, err
+	}
+
+	tx := db.Begin()
+	defer tx.Rollback()
+
+	// serializes concurrent creations of the same user, otherwise both pass the limit check
+	if err := tx.Exec("select pg_advisory_xact_lock(?)", userId).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	var count int64
+	if err := tx.Model(&database.UserPlaylist{}).Where("user_id = ?", userId).Count(&count).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if maxCount := s.appConfig.GetValues().MUSIC_USER_PLAYLISTS_MAX_COUNT; maxCount > 0 && count >= int64(maxCount) {
+		return nil, errors.New(fmt.Sprintf("playlists limit of %v is reached", maxCount))
+	}
+
+	playlist := database.UserPlaylist{
+		UserId:      userId,
+		Name:        name,
+		Description: req.Description,
+		Visibility:  req.Visibility,
+	}
+
+	if err := tx.Create(&playlist).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return &PlaylistModel{UserPlaylist: playlist, IsOwner: true}, nil
+}
+
+func (s *Service) Update(req UpdatePlaylistRequest, userId int64, db *gorm.DB) (*PlaylistModel, error) {
+	updates := map[string]interface{}{}
+
+	if req.Name.Valid {
+		name, err := validateName(req.Name.String)
+		if err != nil {
+			return nil, err
+		}
+
+		updates["name"] = name
+	}
+
+	if req.Description.Valid {
+		if len(req.Description.String) > maxDescriptionLength {
+			return nil, errors.New("description is too long")
+		}
+
+		updates["description"] = req.Description.String
+	}
+
+	if req.Visibility.Valid {
+		visibility := database.PlaylistVisibility(req.Visibility.Int64)
+		if err := validateVisibility(visibility); err != nil {
+			return nil, err
+		}
+
+		updates["visibility"] = visibility
+	}
+
+	tx := db.Begin()
+	defer tx.Rollback()
+
+	playlist, err := ownPlaylistForUpdate(req.PlaylistId, userId, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(updates) > 0 {
+		updates["updated_at"] = time.Now().UTC()
+
+		if err := tx.Model(playlist).Updates(updates).Error; err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		if err := tx.Where("id = ?", playlist.Id).Find(playlist).Error; err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return &PlaylistModel{UserPlaylist: *playlist, IsOwner: true}, nil
+}
+
+// Delete soft deletes playlist, it disappears from followed playlists of other users
+func (s *Service) Delete(req PlaylistRequest, userId int64, db *gorm.DB) error {
+	result := db.Where("id = ? and user_id = ?", req.PlaylistId, userId).Delete(&database.UserPlaylist{})
+	if result.Error != nil {
+		return errors.WithStack(result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.New("playlist not found")
+	}
+
+	return nil
+}
+
+// AddSongs appends songs to the end of playlist, songs which are already in playlist are skipped
+func (s *Service) AddSongs(req PlaylistSongsRequest, userId int64, db *gorm.DB) (*PlaylistModel, error) {
+	if len(req.Songs) == 0 {
+		return nil, errors.New("songs are required")
+	}
+
+	tx := db.Begin()
+	defer tx.Rollback()
+
+	playlist, err := ownPlaylistForUpdate(req.PlaylistId, userId, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateSongs(req.Songs, userId, tx); err != nil {
+		return nil, err
+	}
+
+	var existing []database.UserPlaylistSong
+	if err := tx.Where("playlist_id = ?", playlist.Id).Find(&existing).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	existingRefs := lo.Map(existing, func(song database.UserPlaylistSong, _ int) SongRef {
+		return SongRef{SongId: song.SongId, SongKind: song.SongKind}
+	})
+
+	newRefs := lo.Filter(lo.Uniq(req.Songs), func(ref SongRef, _ int) bool {
+		return !lo.Contains(existingRefs, ref)
+	})
+
+	if maxSongs := s.appConfig.GetValues().MUSIC_USER_PLAYLIST_MAX_SONGS; maxSongs > 0 && len(existing)+len(newRefs) > maxSongs {
+		return nil, errors.New(fmt.Sprintf("playlist songs limit of %v is reached", maxSongs))
+	}
+
+	if len(newRefs) > 0 {
+		sortOrder := 0
+		for _, song := range existing {
+			if song.SortOrder > sortOrder {
+				sortOrder = song.SortOrder
+			}
+		}
+
+		now := time.Now().UTC()
+		songs := make([]database.UserPlaylistSong, 0, len(newRefs))
+
+		for _, ref := range newRefs {
+			sortOrder++
+
+			songs = append(songs, database.UserPlaylistSong{
+				PlaylistId: playlist.Id,
+				SongId:     ref.SongId,
+				SongKind:   ref.SongKind,
+				SortOrder:  sortOrder,
+				CreatedAt:  now,
+			})
+		}
+
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&songs).Error; err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+
+	if err := updateSongsCount(playlist, tx); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return &PlaylistModel{UserPlaylist: *playlist, IsOwner: true}, nil
+}
+
+func (s *Service) RemoveSongs(req PlaylistSongsRequest, userId int64, db *gorm.DB) (*PlaylistModel, error) {
+	if len(req.Songs) == 0 {
+		return nil, errors.New("songs are required")
+	}
+
+	tx := db.Begin()
+	defer tx.Rollback()
+
+	playlist, err := ownPlaylistForUpdate(req.PlaylistId, userId, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	for kind, refs := range lo.GroupBy(req.Songs, func(ref SongRef) database.ListenSongKind {
+		return ref.SongKind
+	}) {
+		if err := tx.Where("playlist_id = ? and song_kind = ? and song_id in ?", playlist.Id, kind,
+			lo.Map(refs, func(ref SongRef, _ int) int64 {
+				return ref.SongId
+			})).Delete(&database.UserPlaylistSong{}).Error; err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+
+	if err := updateSongsCount(playlist, tx); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return &PlaylistModel{UserPlaylist: *playlist, IsOwner: true}, nil
+}
+
+// ReorderSongs sets order of playlist songs, request should contain all songs of playlist in the new order
+func (s *Service) ReorderSongs(req PlaylistSongsRequest, userId int64, db *gorm.DB) error {
+	tx := db.Begin()
+	defer tx.Rollback()
+
+	playlist, err := ownPlaylistForUpdate(req.PlaylistId, userId, tx)
+	if err != nil {
+		return err
+	}
+
+	var existing []database.UserPlaylistSong
+	if err := tx.Where("playlist_id = ?", playlist.Id).Find(&existing).Error; err != nil {
+		return errors.WithStack(err)
+	}
+
+	existingRefs := lo.Map(existing, func(song database.UserPlaylistSong, _ int) SongRef {
+		return SongRef{SongId: song.SongId, SongKind: song.SongKind}
+	})
+
+	if len(lo.Uniq(req.Songs)) != len(req.Songs) || len(req.Songs) != len(existingRefs) ||
+		len(lo.Intersect(existingRefs, req.Songs)) != len(existingRefs) {
+		return errors.New("songs should match playlist songs")
+	}
+
+	for i, ref := range req.Songs {
+		if err := tx.Model(&database.UserPlaylistSong{}).
+			Where("playlist_id = ? and song_kind = ? and song_id = ?", playlist.Id, ref.SongKind, ref.SongId).
+			Update("sort_order", i+1).Error; err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	if err := tx.Model(playlist).Update("updated_at", time.Now().UTC()).Error; err != nil {
+		return errors.WithStack(err)
+	}
+
+	return errors.WithStack(tx.Commit().Error)
+}
+
+// Get returns playlist with songs. Private playlists are available only for the owner, hidden songs are skipped for
+// other users
+func (s *Service) Get(req PlaylistRequest, userId int64, db *gorm.DB, apmTransaction *apm.Transaction, ctx context.Context) (*PlaylistResponse, error) {
+	var playlist database.UserPlaylist
+	if err := db.Where("id = ?", req.PlaylistId).Find(&playlist).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if playlist.Id == 0 || (playlist.UserId != userId && playlist.Visibility != database.PlaylistVisibilityPublic) {
+		return nil, errors.New("playlist not found")
+	}
+
+	models, err := s.toModels([]database.UserPlaylist{playlist}, userId, db)
+	if err != nil {
+		return nil, err
+	}
+
+	var playlistSongs []database.UserPlaylistSong
+	if err := db.Where("playlist_id = ?", playlist.Id).Order("sort_order, created_at").
+		Find(&playlistSongs).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	songIds := map[database.ListenSongKind][]int64{}
+	for _, song := range playlistSongs {
+		songIds[song.SongKind] = append(songIds[song.SongKind], song.SongId)
+	}
+
+	librarySongs := map[int64]frontend.Song{}
+
+	if ids := songIds[database.ListenSongKindLibrary]; len(ids) > 0 {
+		var dbSongs []database.Song
+		if err := db.Where("id in ?", ids).Find(&dbSongs).Error; err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		librarySongs = lo.Associate(frontend.ConvertSongsToFrontendModel(dbSongs, userId, db, ctx),
+			func(song frontend.Song) (int64, frontend.Song) {
+				return song.Id, song
+			})
+	}
+
+	creatorSongs := map[int64]frontend.CreatorSongModel{}
+
+	if ids := songIds[database.ListenSongKindCreator]; len(ids) > 0 {
+		var dbSongs []*database.CreatorSong
+		if err := db.Where("id in ?", ids).
+			Where("status in ? or user_id = ?", database.CreatorSongVisibleStatuses, userId).
+			Find(&dbSongs).Error; err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		creatorSongs = lo.Associate(s.feedConverter.ConvertToSongModel(dbSongs, userId, false, apmTransaction, ctx),
+			func(song frontend.CreatorSongModel) (int64, frontend.CreatorSongModel) {
+				return song.Id, song
+			})
+	}
+
+	resp := &PlaylistResponse{
+		Playlist: models[0],
+		Songs:    make([]PlaylistSongItem, 0, len(playlistSongs)),
+	}
+
+	for _, song := range playlistSongs {
+		item := PlaylistSongItem{SongKind: song.SongKind}
+
+		if librarySong, ok := librarySongs[song.SongId]; ok && song.SongKind == database.ListenSongKindLibrary {
+			item.Song = &librarySong
+		} else if creatorSong, ok := creatorSongs[song.SongId]; ok && song.SongKind == database.ListenSongKindCreator {
+			item.CreatorSong = &creatorSong
+		} else {
+			continue
+		}
+
+		resp.Songs = append(resp.Songs, item)
+	}
+
+	return resp, nil
+}
+
+// ListMy returns playlists created by user, recently updated first
+func (s *Service) ListMy(req ListPlaylistsRequest, userId int64, db *gorm.DB) (*ListPlaylistsResponse, error) {
+	return s.list(db.Model(&database.UserPlaylist{}).Where("user_id = ?", userId), req, userId, db)
+}
+
+// ListUserPublic returns public playlists of another user
+func (s *Service) ListUserPublic(req ListPlaylistsRequest, userId int64, db *gorm.DB) (*ListPlaylistsResponse, error) {
+	return s.list(db.Model(&database.UserPlaylist{}).
+		Where("user_id = ? and visibility = ?", req.UserId, database.PlaylistVisibilityPublic), req, userId, db)
+}
+
+func (s *Service) list(query *gorm.DB, req ListPlaylistsRequest, userId int64, db *gorm.DB) (*ListPlaylistsResponse, error) {
+	var playlists []database.UserPlaylist
+
+	p := paginator.New(
+		&paginator.Config{
+			Rules: []paginator.Rule{
+				{
+					Key:   "UpdatedAt",
+					Order: paginator.DESC,
+				},
+				{
+					Key:   "Id",
+					Order: paginator.DESC,
+				},
+			},
+			Limit: req.Count,
+		},
+	)
+
+	if len(req.Cursor) > 0 {
+		p.SetAfterCursor(req.Cursor)
+	}
+
+	result, cursor, err := p.Paginate(query, &playlists)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if result.Error != nil {
+		return nil, errors.WithStack(result.Error)
+	}
+
+	models, err := s.toModels(playlists, userId, db)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &ListPlaylistsResponse{
+		Items: models,
+	}
+
+	if cursor.After != nil {
+		resp.Cursor = *cursor.After
+	}
+
+	return resp, nil
+}
+
+// ListFollowed returns playlists followed by user, recently followed first. Playlists which were deleted or made
+// private by the owner are skipped, follows are kept in case the playlist becomes public again
+func (s *Service) ListFollowed(req ListPlaylistsRequest, userId int64, db *gorm.DB) (*ListPlaylistsResponse, error) {
+	var follows []database.UserPlaylistFollower
+
+	query := db.Model(&database.UserPlaylistFollower{}).
+		Joins("join user_playlists p on p.id = user_playlist_followers.playlist_id").
+		Where("user_playlist_followers.user_id = ? and p.deleted_at is null and p.visibility = ?", userId,
+			database.PlaylistVisibilityPublic)
+
+	p := paginator.New(
+		&paginator.Config{
+			Rules: []paginator.Rule{
+				{
+					Key:     "CreatedAt",
+					Order:   paginator.DESC,
+					SQLRepr: "user_playlist_followers.created_at",
+				},
+				{
+					Key:     "PlaylistId",
+					Order:   paginator.DESC,
+					SQLRepr: "user_playlist_followers.playlist_id",
+				},
+			},
+			Limit: req.Count,
+		},
+	)
+
+	if len(req.Cursor) > 0 {
+		p.SetAfterCursor(req.Cursor)
+	}
+
+	result, cursor, err := p.Paginate(query, &follows)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if result.Error != nil {
+		return nil, errors.WithStack(result.Error)
+	}
+
+	resp := &ListPlaylistsResponse{
+		Items: []PlaylistModel{},
+	}
+
+	if cursor.After != nil {
+		resp.Cursor = *cursor.After
+	}
+
+	if len(follows) == 0 {
+		return resp, nil
+	}
+
+	var playlists []database.UserPlaylist
+	if err := db.Where("id in ?", lo.Map(follows, func(f database.UserPlaylistFollower, _ int) int64 {
+		return f.PlaylistId
+	})).Find(&playlists).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	models, err := s.toModels(playlists, userId, db)
+	if err != nil {
+		return nil, err
+	}
+
+	mapped := lo.Associate(models, func(model PlaylistModel) (int64, PlaylistModel) {
+		return model.Id, model
+	})
+
+	for _, f := range follows {
+		if model, ok := mapped[f.PlaylistId]; ok {
+			resp.Items = append(resp.Items, model)
+		}
+	}
+
+	return resp, nil
+}
+
+func (s *Service) Follow(req PlaylistRequest, userId int64, db *gorm.DB) error {
+	tx := db.Begin()
+	defer tx.Rollback()
+
+	// serializes concurrent follows of the same user, otherwise both pass the limit check
+	if err := tx.Exec("select pg_advisory_xact_lock(?)", userId).Error; err != nil {
+		return errors.WithStack(err)
+	}
+
+	var playlist database.UserPlaylist
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", req.PlaylistId).
+		Find(&playlist).Error; err != nil {
+		return errors.WithStack(err)
+	}
+
+	if playlist.Id == 0 || playlist.Visibility != database.PlaylistVisibilityPublic {
+		return errors.New("playlist not found")
+	}
+
+	if playlist.UserId == userId {
+		return errors.New("own playlist can not be followed")
+	}
+
+	var count int64
+	if err := tx.Model(&database.UserPlaylistFollower{}).Where("user_id = ?", userId).Count(&count).Error; err != nil {
+		return errors.WithStack(err)
+	}
+
+	if maxCount := s.appConfig.GetValues().MUSIC_USER_PLAYLIST_FOLLOWS_MAX_COUNT; maxCount > 0 && count >= int64(maxCount) {
+		return errors.New(fmt.Sprintf("followed playlists limit of %v is reached", maxCount))
+	}
+
+	result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&database.UserPlaylistFollower{
+		PlaylistId: playlist.Id,
+		UserId:     userId,
+		CreatedAt:  time.Now().UTC(),
+	})
+	if result.Error != nil {
+		return errors.WithStack(result.Error)
+	}
+
+	if result.RowsAffected > 0 {
+		if err := tx.Model(&playlist).UpdateColumn("followers_count", gorm.Expr("followers_count + 1")).
+			Error; err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	return errors.WithStack(tx.Commit().Error)
+}
+
+func (s *Service) Unfollow(req PlaylistRequest, userId int64, db *gorm.DB) error {
+	tx := db.Begin()
+	defer tx.Rollback()
+
+	result := tx.Where("playlist_id = ? and user_id = ?", req.PlaylistId, userId).Delete(&database.UserPlaylistFollower{})
+	if result.Error != nil {
+		return errors.WithStack(result.Error)
+	}
+
+	if result.RowsAffected > 0 {
+		if err := tx.Model(&database.UserPlaylist{}).Unscoped().Where("id = ?", req.PlaylistId).
+			UpdateColumn("followers_count", gorm.Expr("greatest(followers_count - 1, 0)")).Error; err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	return errors.WithStack(tx.Commit().Error)
+}
+
+// Share returns share link of public playlist. Link does not depend on the sharer, so it is generated once and
+// stored in playlist
+func (s *Service) Share(req PlaylistRequest, userId int64, db *gorm.DB) (*ShareResponse, error) {
+	var playlist database.UserPlaylist
+	if err := db.Where("id = ?", req.PlaylistId).Find(&playlist).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if playlist.Id == 0 || (playlist.UserId != userId && playlist.Visibility != database.PlaylistVisibilityPublic) {
+		return nil, errors.New("playlist not found")
+	}
+
+	if playlist.Visibility != database.PlaylistVisibilityPublic {
+		return nil, errors.New("private playlist can not be shared")
+	}
+
+	if playlist.ShareLink.Valid {
+		return &ShareResponse{Link: playlist.ShareLink.String}, nil
+	}
+
+	link := fmt.Sprintf("%v/music/playlist/%v?sharerId=%v&referredByType=shared_playlist", s.deeplinkConfig.URI,
+		playlist.Id, playlist.UserId)
+
+	var shareLink string
+	var err error
+
+	if deeplink.Provider(s.deeplinkConfig.Provider) == deeplink.ProviderFirebase {
+		shareLink, err = s.deeplinkService.GenerateFirebaseDeeplinkWithMeta(link, playlist.Name, playlist.Description, "")
+	} else {
+		shareLink, err = s.deeplinkService.GenerateBranchDeeplinkWithMeta(link, playlist.Name, playlist.Description, "")
+	}
+
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if err := db.Model(&playlist).UpdateColumn("share_link", shareLink).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return &ShareResponse{Link: shareLink}, nil
+}
+
+func (s *Service) toModels(playlists []database.UserPlaylist, userId int64, db *gorm.DB) ([]PlaylistModel, error) {
+	var followedIds []int64
+
+	if userId > 0 && len(playlists) > 0 {
+		if err := db.Model(&database.UserPlaylistFollower{}).
+			Where("user_id = ? and playlist_id in ?", userId, lo.Map(playlists, func(p database.UserPlaylist, _ int) int64 {
+				return p.Id
+			})).Pluck("playlist_id", &followedIds).Error; err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+
+	return lo.Map(playlists, func(p database.UserPlaylist, _ int) PlaylistModel {
+		return PlaylistModel{
+			UserPlaylist: p,
+			IsOwner:      userId > 0 && p.UserId == userId,
+			IsFollowed:   lo.Contains(followedIds, p.Id),
+		}
+	}), nil
+}
+
+func ownPlaylistForUpdate(playlistId int64, userId int64, tx *gorm.DB) (*database.UserPlaylist, error) {
+	var playlist database.UserPlaylist
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? and user_id = ?", playlistId, userId).Find(&playlist).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if playlist.Id == 0 {
+		return nil, errors.New("playlist not found")
+	}
+
+	return &playlist, nil
+}
+
+// validateSongs checks that library songs exist and creator songs are visible to the user
+func validateSongs(songs []SongRef, userId int64, tx *gorm.DB) error {
+	for kind, refs := range lo.GroupBy(songs, func(ref SongRef) database.ListenSongKind {
+		return ref.SongKind
+	}) {
+		ids := lo.Uniq(lo.Map(refs, func(ref SongRef, _ int) int64 {
+			return ref.SongId
+		}))
+
+		query := tx.Where("id in ?", ids)
+
+		switch kind {
+		case database.ListenSongKindLibrary:
+			query = query.Model(&database.Song{})
+		case database.ListenSongKindCreator:
+			query = query.Model(&database.CreatorSong{}).
+				Where("status in ? or user_id = ?", database.CreatorSongVisibleStatuses, userId)
+		default:
+			return errors.New("invalid song_kind")
+		}
+
+		var count int64
+		if err := query.Count(&count).Error; err != nil {
+			return errors.WithStack(err)
+		}
+
+		if count != int64(len(ids)) {
+			return errors.New("song not found")
+		}
+	}
+
+	return nil
+}
+
+func updateSongsCount(playlist *database.UserPlaylist, tx *gorm.DB) error {
+	var count int64
+	if err := tx.Model(&database.UserPlaylistSong{}).Where("playlist_id = ?", playlist.Id).
+		Count(&count).Error; err != nil {
+		return errors.WithStack(err)
+	}
+
+	playlist.SongsCount = int(count)
+	playlist.UpdatedAt = time.Now().UTC()
+
+	if err := tx.Model(playlist).Updates(map[string]interface{}{
+		"songs_count": playlist.SongsCount,
+		"updated_at":  playlist.UpdatedAt,
+	}).Error; err != nil {
+		return errors.WithStack(err)
+	}
+
+	return nil
+}
+
+func validateName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+
+	if len(name) == 0 {
+		return "", errors.New("name is required")
+	}
+
+	if len([]rune(name)) > maxNameLength {
+		return "", errors.New("name is too long")
+	}
+
+	return name, nil
+}
+
+func validateVisibility(visibility database.PlaylistVisibility) error {
+	if visibility != database.PlaylistVisibilityPrivate && visibility != database.PlaylistVisibilityPublic {
+		return errors.New("invalid visibility")
+	}
+
+	return nil
+}
@@ -0,0 +1,305 @@
+package user_playlists
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/digitalmonsters/go-common/application"
+	"github.com/digitalmonsters/go-common/boilerplate_testing"
+	"github.com/digitalmonsters/go-common/deeplink"
+	"github.com/digitalmonsters/go-common/wrappers"
+	"github.com/digitalmonsters/go-common/wrappers/follow"
+	"github.com/digitalmonsters/go-common/wrappers/go_tokenomics"
+	"github.com/digitalmonsters/go-common/wrappers/like"
+	"github.com/digitalmonsters/go-common/wrappers/music"
+	"github.com/digitalmonsters/go-common/wrappers/user_go"
+	"github.com/digitalmonsters/music/configs"
+	"github.com/digitalmonsters/music/pkg/database"
+	"github.com/digitalmonsters/music/pkg/feed/feed_converter"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"go.elastic.co/apm"
+	"gopkg.in/guregu/null.v4"
+	"gorm.io/gorm"
+)
+
+var config configs.Settings
+var gormDb *gorm.DB
+var service *Service
+var generatedLinks int
+
+func TestMain(m *testing.M) {
+	config = configs.GetConfig()
+	gormDb = database.GetDb(database.DbTypeMaster)
+	userWrapper := &user_go.UserGoWrapperMock{}
+
+	userWrapper.GetUsersFn = func(userIds []int64, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[map[int64]user_go.UserRecord] {
+		ch := make(chan wrappers.GenericResponseChan[map[int64]user_go.UserRecord], 2)
+		resp := map[int64]user_go.UserRecord{}
+
+		for _, userId := range userIds {
+			resp[userId] = user_go.UserRecord{
+				UserId:    userId,
+				Username:  fmt.Sprintf("username%v", userId),
+				Firstname: fmt.Sprintf("firstname%v", userId),
+				Lastname:  fmt.Sprintf("lastname%v", userId),
+				Email:     fmt.Sprintf("email%v", userId),
+			}
+		}
+
+		ch <- wrappers.GenericResponseChan[map[int64]user_go.UserRecord]{
+			Error:    nil,
+			Response: resp,
+		}
+		close(ch)
+
+		return ch
+	}
+
+	followWrapper := &follow.FollowWrapperMock{}
+	followWrapper.GetUserFollowingRelationBulkFn = func(userId int64, requestUserIds []int64, apmTransaction *apm.Transaction,
+		forceLog bool) chan follow.GetUserFollowingRelationBulkResponseChan {
+		ch := make(chan follow.GetUserFollowingRelationBulkResponseChan, 2)
+
+		ch <- follow.GetUserFollowingRelationBulkResponseChan{
+			Error: nil,
+			Data:  map[int64]follow.RelationData{},
+		}
+		close(ch)
+
+		return ch
+	}
+
+	likeWrapper := &like.LikeWrapperMock{}
+
+	likeWrapper.GetInternalSpotReactionsByUserFn = func(contentIds []int64, userId int64, apmTransaction *apm.Transaction, forceLog bool) chan like.GetInternalSpotReactionsByUserResponseChan {
+		ch := make(chan like.GetInternalSpotReactionsByUserResponseChan, 2)
+
+		ch <- like.GetInternalSpotReactionsByUserResponseChan{
+			Error: nil,
+			Data:  map[int64]like.SpotReaction{},
+		}
+		close(ch)
+
+		return ch
+	}
+
+	goTokenomicsWrapper := &go_tokenomics.GoTokenomicsWrapperMock{}
+
+	goTokenomicsWrapper.GetContentEarningsTotalByContentIdsFn = func(contentIds []int64, apmTransaction *apm.Transaction, forceLog bool) chan go_tokenomics.GetContentEarningsTotalByContentIdsResponseChan {
+		ch := make(chan go_tokenomics.GetContentEarningsTotalByContentIdsResponseChan, 2)
+
+		ch <- go_tokenomics.GetContentEarningsTotalByContentIdsResponseChan{
+			Error: nil,
+			Items: map[int64]decimal.Decimal{},
+		}
+		close(ch)
+
+		return ch
+	}
+
+	deeplinkService := &deeplink.ServiceMock{
+		GenerateBranchDeeplinkWithMetaFn: func(link string, title string, description string, previewThumbnail string) (string, error) {
+			generatedLinks++
+			return fmt.Sprintf("https://share/%v", generatedLinks), nil
+		},
+	}
+
+	service = NewService(feed_converter.NewFeedConverter(userWrapper, followWrapper, likeWrapper, goTokenomicsWrapper,
+		context.Background()), deeplinkService, deeplink.Config{Provider: string(deeplink.ProviderBranch)},
+		&application.Configurator[configs.AppConfig]{Values: configs.AppConfig{
+			MUSIC_USER_PLAYLISTS_MAX_COUNT:        2,
+			MUSIC_USER_PLAYLIST_MAX_SONGS:         3,
+			MUSIC_USER_PLAYLIST_FOLLOWS_MAX_COUNT: 1,
+		}})
+
+	os.Exit(m.Run())
+}
+
+func flush(t *testing.T) {
+	if err := boilerplate_testing.FlushPostgresTables(config.MasterDb, []string{"public.user_playlists",
+		"public.user_playlist_songs", "public.user_playlist_followers", "public.songs", "public.creator_songs"}, nil,
+		t); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestService_CreateAndUpdate(t *testing.T) {
+	flush(t)
+
+	_, err := service.Create(CreatePlaylistRequest{Name: "  "}, 1, gormDb)
+	assert.NotNil(t, err)
+
+	playlist, err := service.Create(CreatePlaylistRequest{Name: " Road trip "}, 1, gormDb)
+	assert.Nil(t, err)
+	assert.Equal(t, "Road trip", playlist.Name)
+	assert.Equal(t, database.PlaylistVisibilityPrivate, playlist.Visibility)
+
+	_, err = service.Create(CreatePlaylistRequest{Name: "Second", Visibility: database.PlaylistVisibilityPublic}, 1, gormDb)
+	assert.Nil(t, err)
+
+	_, err = service.Create(CreatePlaylistRequest{Name: "Third"}, 1, gormDb)
+	assert.NotNil(t, err)
+
+	// other user is not limited by playlists of the first one
+	_, err = service.Create(CreatePlaylistRequest{Name: "Other"}, 2, gormDb)
+	assert.Nil(t, err)
+
+	_, err = service.Update(UpdatePlaylistRequest{PlaylistId: playlist.Id, Name: null.StringFrom("Stolen")}, 2, gormDb)
+	assert.NotNil(t, err)
+
+	updated, err := service.Update(UpdatePlaylistRequest{PlaylistId: playlist.Id, Name: null.StringFrom("Summer trip"),
+		Visibility: null.IntFrom(int64(database.PlaylistVisibilityPublic))}, 1, gormDb)
+	assert.Nil(t, err)
+	assert.Equal(t, "Summer trip", updated.Name)
+	assert.Equal(t, database.PlaylistVisibilityPublic, updated.Visibility)
+
+	assert.Nil(t, service.Delete(PlaylistRequest{PlaylistId: playlist.Id}, 1, gormDb))
+
+	// deleted playlist frees the limit
+	_, err = service.Create(CreatePlaylistRequest{Name: "Third"}, 1, gormDb)
+	assert.Nil(t, err)
+
+	resp, err := service.ListMy(ListPlaylistsRequest{Count: 10}, 1, gormDb)
+	assert.Nil(t, err)
+	assert.Len(t, resp.Items, 2)
+}
+
+func TestService_Songs(t *testing.T) {
+	flush(t)
+
+	songs := []database.Song{
+		{Source: database.SongSourceSoundStripe, ExternalId: "1", Title: "first"},
+		{Source: database.SongSourceSoundStripe, ExternalId: "2", Title: "second"},
+		{Source: database.SongSourceSoundStripe, ExternalId: "3", Title: "third"},
+	}
+	if err := gormDb.Create(&songs).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	creatorSongs := []database.CreatorSong{
+		{UserId: 5, Name: "approved", Status: music.CreatorSongStatusApproved},
+		{UserId: 5, Name: "pending", Status: music.CreatorSongStatusPending},
+	}
+	if err := gormDb.Create(&creatorSongs).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	playlist, err := service.Create(CreatePlaylistRequest{Name: "Mix"}, 1, gormDb)
+	assert.Nil(t, err)
+
+	// pending song of other creator is not visible
+	_, err = service.AddSongs(PlaylistSongsRequest{PlaylistId: playlist.Id, Songs: []SongRef{
+		{SongId: creatorSongs[1].Id, SongKind: database.ListenSongKindCreator},
+	}}, 1, gormDb)
+	assert.NotNil(t, err)
+
+	resp, err := service.AddSongs(PlaylistSongsRequest{PlaylistId: playlist.Id, Songs: []SongRef{
+		{SongId: songs[0].Id, SongKind: database.ListenSongKindLibrary},
+		{SongId: creatorSongs[0].Id, SongKind: database.ListenSongKindCreator},
+		{SongId: songs[0].Id, SongKind: database.ListenSongKindLibrary},
+	}}, 1, gormDb)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, resp.SongsCount)
+
+	resp, err = service.AddSongs(PlaylistSongsRequest{PlaylistId: playlist.Id, Songs: []SongRef{
+		{SongId: songs[1].Id, SongKind: database.ListenSongKindLibrary},
+	}}, 1, gormDb)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, resp.SongsCount)
+
+	// the limit is 3 songs
+	_, err = service.AddSongs(PlaylistSongsRequest{PlaylistId: playlist.Id, Songs: []SongRef{
+		{SongId: songs[2].Id, SongKind: database.ListenSongKindLibrary},
+	}}, 1, gormDb)
+	assert.NotNil(t, err)
+
+	// reorder requires all songs
+	assert.NotNil(t, service.ReorderSongs(PlaylistSongsRequest{PlaylistId: playlist.Id, Songs: []SongRef{
+		{SongId: songs[1].Id, SongKind: database.ListenSongKindLibrary},
+	}}, 1, gormDb))
+
+	assert.Nil(t, service.ReorderSongs(PlaylistSongsRequest{PlaylistId: playlist.Id, Songs: []SongRef{
+		{SongId: creatorSongs[0].Id, SongKind: database.ListenSongKindCreator},
+		{SongId: songs[1].Id, SongKind: database.ListenSongKindLibrary},
+		{SongId: songs[0].Id, SongKind: database.ListenSongKindLibrary},
+	}}, 1, gormDb))
+
+	get, err := service.Get(PlaylistRequest{PlaylistId: playlist.Id}, 1, gormDb, nil, context.TODO())
+	assert.Nil(t, err)
+
+	if assert.Len(t, get.Songs, 3) {
+		assert.Equal(t, creatorSongs[0].Id, get.Songs[0].CreatorSong.Id)
+		assert.Equal(t, songs[1].Id, get.Songs[1].Song.Id)
+		assert.Equal(t, songs[0].Id, get.Songs[2].Song.Id)
+	}
+
+	// private playlist is not available for other users
+	_, err = service.Get(PlaylistRequest{PlaylistId: playlist.Id}, 2, gormDb, nil, context.TODO())
+	assert.NotNil(t, err)
+
+	resp, err = service.RemoveSongs(PlaylistSongsRequest{PlaylistId: playlist.Id, Songs: []SongRef{
+		{SongId: songs[1].Id, SongKind: database.ListenSongKindLibrary},
+	}}, 1, gormDb)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, resp.SongsCount)
+}
+
+func TestService_FollowAndShare(t *testing.T) {
+	flush(t)
+
+	private, err := service.Create(CreatePlaylistRequest{Name: "Private"}, 1, gormDb)
+	assert.Nil(t, err)
+
+	public, err := service.Create(CreatePlaylistRequest{Name: "Public", Visibility: database.PlaylistVisibilityPublic},
+		1, gormDb)
+	assert.Nil(t, err)
+
+	assert.NotNil(t, service.Follow(PlaylistRequest{PlaylistId: private.Id}, 2, gormDb))
+	assert.NotNil(t, service.Follow(PlaylistRequest{PlaylistId: public.Id}, 1, gormDb))
+	assert.Nil(t, service.Follow(PlaylistRequest{PlaylistId: public.Id}, 2, gormDb))
+
+	// the limit is 1 followed playlist
+	other, err := service.Create(CreatePlaylistRequest{Name: "Other", Visibility: database.PlaylistVisibilityPublic},
+		3, gormDb)
+	assert.Nil(t, err)
+	assert.NotNil(t, service.Follow(PlaylistRequest{PlaylistId: other.Id}, 2, gormDb))
+
+	followed, err := service.ListFollowed(ListPlaylistsRequest{Count: 10}, 2, gormDb)
+	assert.Nil(t, err)
+
+	if assert.Len(t, followed.Items, 1) {
+		assert.Equal(t, public.Id, followed.Items[0].Id)
+		assert.Equal(t, 1, followed.Items[0].FollowersCount)
+		assert.True(t, followed.Items[0].IsFollowed)
+	}
+
+	publicList, err := service.ListUserPublic(ListPlaylistsRequest{UserId: 1, Count: 10}, 2, gormDb)
+	assert.Nil(t, err)
+	assert.Len(t, publicList.Items, 1)
+
+	_, err = service.Share(PlaylistRequest{PlaylistId: private.Id}, 1, gormDb)
+	assert.NotNil(t, err)
+
+	share, err := service.Share(PlaylistRequest{PlaylistId: public.Id}, 2, gormDb)
+	assert.Nil(t, err)
+
+	// link is generated once
+	shareAgain, err := service.Share(PlaylistRequest{PlaylistId: public.Id}, 1, gormDb)
+	assert.Nil(t, err)
+	assert.Equal(t, share.Link, shareAgain.Link)
+
+	// made private playlist disappears from followed
+	_, err = service.Update(UpdatePlaylistRequest{PlaylistId: public.Id,
+		Visibility: null.IntFrom(int64(database.PlaylistVisibilityPrivate))}, 1, gormDb)
+	assert.Nil(t, err)
+
+	followed, err = service.ListFollowed(ListPlaylistsRequest{Count: 10}, 2, gormDb)
+	assert.Nil(t, err)
+	assert.Len(t, followed.Items, 0)
+
+	assert.Nil(t, service.Unfollow(PlaylistRequest{PlaylistId: public.Id}, 2, gormDb))
+	assert.Nil(t, service.Follow(PlaylistRequest{PlaylistId: other.Id}, 2, gormDb))
+}
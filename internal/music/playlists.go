@@ -0,0 +1,189 @@
+package music
+
+import (
+	"net/http"
+
+	"github.com/digitalmonsters/music/pkg/database"
+	"github.com/digitalmonsters/music/pkg/music_source"
+	"github.com/digitalmonsters/music/pkg/playlist"
+	"github.com/digitalmonsters/music/pkg/song"
+	"go.elastic.co/apm"
+)
+
+func listPlaylists(w http.ResponseWriter, r *http.Request) {
+	var req playlist.PlayListListingPublicRequest
+
+	if err := decodeRequest(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	resp, err := playlist.PlaylistListingPublic(req, database.GetDbWithContext(database.DbTypeReadonly, r.Context()))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeResponse(w, resp)
+}
+
+func listPlaylistSongs(w http.ResponseWriter, r *http.Request) {
+	var req playlist.PlaylistSongsListPublicRequest
+
+	if err := decodeRequest(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	resp, err := playlist.PlaylistSongsListPublic(req, database.GetDbWithContext(database.DbTypeReadonly, r.Context()),
+		executionDataFromRequest(r))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeResponse(w, resp)
+}
+
+func getSongUrl(musicStorageService *music_source.MusicStorageService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req song.GetSongUrlRequest
+
+		if err := decodeRequest(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		// master db, expired SoundStripe urls are refreshed and cached
+		resp, err := song.GetSongUrl(req, database.GetDbWithContext(database.DbTypeMaster, r.Context()),
+			apm.TransactionFromContext(r.Context()), musicStorageService, r.Context())
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		writeResponse(w, resp)
+	}
+}
+
+func upsertPlaylist(w http.ResponseWriter, r *http.Request) {
+	var req playlist.UpsertPlaylistRequest
+
+	if err := decodeRequest(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	resp, err := playlist.UpsertPlaylist(req, database.GetDbWithContext(database.DbTypeMaster, r.Context()))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeResponse(w, resp)
+}
+
+func deletePlaylists(w http.ResponseWriter, r *http.Request) {
+	var req playlist.DeletePlaylistsBulkRequest
+
+	if err := decodeRequest(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := playlist.DeletePlaylistsBulk(req, database.GetDbWithContext(database.DbTypeMaster, r.Context())); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeResponse(w, nil)
+}
+
+func listPlaylistsByAdmin(w http.ResponseWriter, r *http.Request) {
+	var req playlist.PlaylistListingAdminRequest
+
+	if err := decodeRequest(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	resp, err := playlist.PlaylistListingAdmin(req, database.GetDbWithContext(database.DbTypeReadonly, r.Context()))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeResponse(w, resp)
+}
+
+func listPlaylistSongsByAdmin(w http.ResponseWriter, r *http.Request) {
+	var req song.PlaylistSongListRequest
+
+	if err := decodeRequest(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	resp, err := song.PlaylistSongListAdmin(req, database.GetDbWithContext(database.DbTypeReadonly, r.Context()))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeResponse(w, resp)
+}
+
+func addPlaylistSongs(musicStorageService *music_source.MusicStorageService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req song.AddSongToPlaylistRequest
+
+		if err := decodeRequest(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := song.AddSongToPlaylistBulk(req, database.GetDbWithContext(database.DbTypeMaster, r.Context()),
+			apm.TransactionFromContext(r.Context()), musicStorageService, r.Context()); err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		writeResponse(w, nil)
+	}
+}
+
+func deletePlaylistSongs(w http.ResponseWriter, r *http.Request) {
+	var req song.DeleteSongsFromPlaylistBulkRequest
+
+	if err := decodeRequest(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := song.DeleteSongFromPlaylistsBulk(req, database.GetDbWithContext(database.DbTypeMaster, r.Context())); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeResponse(w, nil)
+}
+
+func listSourceMusic(musicStorageService *music_source.MusicStorageService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req music_source.ListMusicRequest
+
+		if err := decodeRequest(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		resp, err := musicStorageService.ListMusic(req, database.GetDbWithContext(database.DbTypeReadonly, r.Context()),
+			apm.TransactionFromContext(r.Context()), r.Context())
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		writeResponse(w, resp)
+	}
+}
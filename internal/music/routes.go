@@ -0,0 +1,222 @@
+package music
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/digitalmonsters/go-common/deeplink"
+	"github.com/digitalmonsters/go-common/eventsourcing"
+	"github.com/digitalmonsters/go-common/http_client"
+	"github.com/digitalmonsters/go-common/router"
+	"github.com/digitalmonsters/go-common/wrappers/auth_go"
+	"github.com/digitalmonsters/go-common/wrappers/content"
+	"github.com/digitalmonsters/go-common/wrappers/follow"
+	"github.com/digitalmonsters/go-common/wrappers/go_tokenomics"
+	"github.com/digitalmonsters/go-common/wrappers/like"
+	"github.com/digitalmonsters/go-common/wrappers/notification_handler"
+	"github.com/digitalmonsters/go-common/wrappers/user_go"
+	"github.com/digitalmonsters/music/configs"
+	"github.com/digitalmonsters/music/pkg/creators"
+	"github.com/digitalmonsters/music/pkg/creators/royalties"
+	"github.com/digitalmonsters/music/pkg/database"
+	"github.com/digitalmonsters/music/pkg/feed/feed_converter"
+	"github.com/digitalmonsters/music/pkg/listens"
+	"github.com/digitalmonsters/music/pkg/music_source"
+	"github.com/digitalmonsters/music/pkg/processing"
+	"github.com/digitalmonsters/music/pkg/search"
+	"github.com/digitalmonsters/music/pkg/uploader"
+	"github.com/digitalmonsters/music/pkg/user_playlists"
+	"github.com/go-chi/chi/v5"
+)
+
+// Migrate applies music database migrations, it should be called before RegisterRoutes
+func Migrate() {
+	database.Migrate()
+}
+
+func RegisterRoutes(r chi.Router) {
+	cfg := configs.GetConfig()
+	appConfig := configs.GetAppConfigurator()
+	ctx := context.Background()
+
+	userGoWrapper := user_go.NewUserGoWrapper(cfg.Wrappers.UserGo)
+	contentWrapper := content.NewContentWrapper(cfg.Wrappers.Content)
+	notificationHandler := notification_handler.NewNotificationHandlerWrapper(cfg.Wrappers.NotificationHandler)
+
+	goTokenomicsWrapper := go_tokenomics.NewGoTokenomicsWrapper(cfg.Wrappers.GoTokenomics)
+	auth := router.NewHttpAuth(auth_go.NewAuthGoWrapper(cfg.Wrappers.AuthGo), writeError)
+
+	feedConverter := feed_converter.NewFeedConverter(userGoWrapper, follow.NewFollowWrapper(cfg.Wrappers.Follows),
+		like.NewLikeWrapper(cfg.Wrappers.Likes), goTokenomicsWrapper, ctx)
+	jobber := newJobber(cfg)
+	musicFeed := newFeed(jobber, cfg, appConfig, feedConverter)
+	processingService := processing.NewService(jobber, cfg, appConfig)
+	sessionService := uploader.NewSessionService(jobber, cfg, appConfig)
+	maxChunkSize := sessionService.ChunkSize()
+	listensService := listens.NewService(jobber, cfg.Listens,
+		eventsourcing.NewKafkaEventPublisher(cfg.KafkaWriter, cfg.Listens.ViewsTopic))
+	musicStorageService := music_source.NewMusicStorageService(jobber, &cfg)
+	searchService := search.NewService(jobber, cfg.Search, search.NewSoundStripeSource(musicStorageService))
+	royaltiesService := royalties.NewService(jobber, feedConverter, goTokenomicsWrapper, appConfig)
+	startWorker(jobber, cfg)
+
+	creatorsService := creators.NewService(feedConverter, creatorNotifiers(cfg, ctx))
+	userPlaylistsService := user_playlists.NewService(feedConverter,
+		deeplink.NewService(cfg.Deeplink, http_client.NewHttpClient(), ctx), cfg.Deeplink, appConfig)
+
+	r.Route("/music", func(rr chi.Router) {
+		rr.Get("/ping", func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("music ok"))
+		})
+
+		rr.Post("/playlists/list", listPlaylists)
+		rr.Post("/playlists/songs", listPlaylistSongs)
+		rr.Post("/popular", listPopularSongs)
+		rr.Post("/feed", getFeed(musicFeed))
+		rr.Post("/categories/list", listCategories)
+		rr.Post("/moods/list", listMoods)
+		rr.Post("/creators/songs/list", listCreatorSongs(creatorsService))
+		rr.Post("/search", searchMusic(searchService, search.UserSources))
+		rr.Post("/user_playlists/get", getUserPlaylist(userPlaylistsService))
+		rr.Post("/user_playlists/user", listUserPublicPlaylists(userPlaylistsService))
+
+		rr.Group(func(ur chi.Router) {
+			ur.Use(auth.RequireUser)
+
+			ur.Post("/songs/url", getSongUrl(musicStorageService))
+			ur.Post("/favorites/add", addToFavorites)
+			ur.Post("/favorites/remove", removeFromFavorites)
+			ur.Post("/favorites/list", listFavoriteSongs)
+
+			ur.Post("/listens/start", startListen(listensService))
+			ur.Post("/listens/progress", progressListen(listensService))
+			ur.Post("/listens/complete", completeListen(listensService))
+
+			ur.Post("/user_playlists/create", createUserPlaylist(userPlaylistsService))
+			ur.Post("/user_playlists/update", updateUserPlaylist(userPlaylistsService))
+			ur.Post("/user_playlists/delete", deleteUserPlaylist(userPlaylistsService))
+			ur.Post("/user_playlists/my", listMyUserPlaylists(userPlaylistsService))
+			ur.Post("/user_playlists/songs/add", addUserPlaylistSongs(userPlaylistsService))
+			ur.Post("/user_playlists/songs/remove", removeUserPlaylistSongs(userPlaylistsService))
+			ur.Post("/user_playlists/songs/reorder", reorderUserPlaylistSongs(userPlaylistsService))
+			ur.Post("/user_playlists/follow", followUserPlaylist(userPlaylistsService))
+			ur.Post("/user_playlists/unfollow", unfollowUserPlaylist(userPlaylistsService))
+			ur.Post("/user_playlists/followed", listFollowedUserPlaylists(userPlaylistsService))
+			ur.Post("/user_playlists/share", shareUserPlaylist(userPlaylistsService))
+		})
+
+		rr.Route("/creators", func(cr chi.Router) {
+			cr.Use(auth.RequireUser)
+
+			cr.Post("/become", becomeCreator(creatorsService, userGoWrapper))
+			cr.Post("/status", checkCreatorRequestStatus(creatorsService))
+			cr.Post("/songs/upload", uploadCreatorSong(creatorsService, contentWrapper))
+			cr.Post("/songs/my", listMyCreatorSongs(creatorsService))
+			cr.Post("/statements/list", listMyCreatorStatements(royaltiesService))
+			cr.Post("/statements/get", getCreatorStatement(royaltiesService))
+			cr.Post("/statements/payout", requestCreatorStatementPayout(royaltiesService))
+			cr.Post("/files/full", upload(&cfg, appConfig, uploader.UploadTypeCreatorsSongFull))
+			cr.Post("/files/short", upload(&cfg, appConfig, uploader.UploadTypeCreatorsSongShort))
+			cr.Post("/files/image", upload(&cfg, appConfig, uploader.UploadTypeCreatorsSongImage))
+			cr.Post("/files/process", processAudio(processingService, cfg.AudioProcessing.MaxFileSizeMb))
+			cr.Post("/files/process/status", processingStatus(processingService))
+			cr.Post("/files/process/uploaded", processUploadedAudio(sessionService, processingService))
+
+			cr.Post("/uploads/create", createUploadSession(sessionService, router.UserIdFromHttpRequest,
+				uploader.CreatorUploadTypes))
+			cr.Post("/uploads/status", getUploadSession(sessionService, router.UserIdFromHttpRequest,
+				uploader.CreatorUploadTypes))
+			cr.Put("/uploads/chunk", uploadChunk(sessionService, router.UserIdFromHttpRequest,
+				uploader.CreatorUploadTypes, maxChunkSize))
+			cr.Post("/uploads/finalize", finalizeUploadSession(sessionService, router.UserIdFromHttpRequest,
+				uploader.CreatorUploadTypes))
+			cr.Post("/uploads/abort", abortUploadSession(sessionService, router.UserIdFromHttpRequest,
+				uploader.CreatorUploadTypes))
+		})
+
+		rr.Route("/admin", func(ar chi.Router) {
+			ar.With(auth.RequireAdmin("music:playlists:edit")).Post("/playlists/upsert", upsertPlaylist)
+			ar.With(auth.RequireAdmin("music:playlists:edit")).Post("/playlists/delete", deletePlaylists)
+			ar.With(auth.RequireAdmin("music:playlists:view")).Post("/playlists/list", listPlaylistsByAdmin)
+			ar.With(auth.RequireAdmin("music:playlists:view")).Post("/playlists/songs/list", listPlaylistSongsByAdmin)
+			ar.With(auth.RequireAdmin("music:playlists:edit")).Post("/playlists/songs/add",
+				addPlaylistSongs(musicStorageService))
+			ar.With(auth.RequireAdmin("music:playlists:edit")).Post("/playlists/songs/delete", deletePlaylistSongs)
+
+			ar.Group(func(sr chi.Router) {
+				sr.Use(auth.RequireAdmin("music:storage:view"))
+
+				sr.Post("/source/list", listSourceMusic(musicStorageService))
+				sr.Post("/search", searchMusic(searchService, search.AdminSources))
+				sr.Post("/storage/list", listOwnStorageSongs)
+				sr.Post("/uploads/status", getUploadSession(sessionService, router.AdminIdFromHttpRequest,
+					uploader.AdminUploadTypes))
+			})
+
+			ar.Group(func(sr chi.Router) {
+				sr.Use(auth.RequireAdmin("music:storage:edit"))
+
+				sr.Post("/storage/upsert", upsertOwnStorageSongs)
+				sr.Post("/storage/delete", deleteOwnStorageSongs)
+				sr.Post("/storage/files", upload(&cfg, appConfig, uploader.UploadTypeAdminMusic))
+
+				sr.Post("/uploads/create", createUploadSession(sessionService, router.AdminIdFromHttpRequest,
+					uploader.AdminUploadTypes))
+				sr.Put("/uploads/chunk", uploadChunk(sessionService, router.AdminIdFromHttpRequest,
+					uploader.AdminUploadTypes, maxChunkSize))
+				sr.Post("/uploads/finalize", finalizeUploadSession(sessionService, router.AdminIdFromHttpRequest,
+					uploader.AdminUploadTypes))
+				sr.Post("/uploads/abort", abortUploadSession(sessionService, router.AdminIdFromHttpRequest,
+					uploader.AdminUploadTypes))
+			})
+
+			ar.With(auth.RequireAdmin("music:creators:view")).Post("/creators/requests/list",
+				listCreatorRequests(creatorsService, cfg.Creators.MaxThresholdHours, userGoWrapper))
+			ar.With(auth.RequireAdmin("music:creators:edit")).Post("/creators/requests/approve",
+				approveCreatorRequests(creatorsService))
+			ar.With(auth.RequireAdmin("music:creators:edit")).Post("/creators/requests/reject",
+				rejectCreatorRequests(creatorsService))
+
+			ar.With(auth.RequireAdmin("music:royalties:view")).Post("/creators/statements/list",
+				listCreatorStatementsByAdmin(royaltiesService))
+			ar.With(auth.RequireAdmin("music:royalties:view")).Post("/creators/statements/get",
+				getCreatorStatementByAdmin(royaltiesService))
+			ar.With(auth.RequireAdmin("music:royalties:adjust")).Post("/creators/statements/adjust",
+				adjustCreatorStatement(royaltiesService))
+			ar.With(auth.RequireAdmin("music:royalties:adjust")).Post("/creators/statements/hold",
+				holdCreatorStatement(royaltiesService))
+			ar.With(auth.RequireAdmin("music:royalties:adjust")).Post("/creators/statements/release",
+				releaseCreatorStatement(royaltiesService))
+			ar.With(auth.RequireAdmin("music:royalties:generate")).Post("/creators/statements/generate",
+				generateCreatorStatements(royaltiesService))
+
+			ar.With(auth.RequireAdmin("music:moderation:view")).Post("/moderation/list",
+				listModerationSongs(userGoWrapper))
+			ar.With(auth.RequireAdmin("music:moderation:view")).Post("/moderation/queue",
+				listModerationQueue(cfg.Creators.SongMaxThresholdHours, userGoWrapper))
+			ar.With(auth.RequireAdmin("music:moderation:edit")).Post("/moderation/approve",
+				approveSong(notificationHandler))
+			ar.With(auth.RequireAdmin("music:moderation:edit")).Post("/moderation/reject",
+				rejectSong(notificationHandler))
+
+			ar.Group(func(dr chi.Router) {
+				dr.Use(auth.RequireAdmin("music:dictionaries:view"))
+
+				dr.Post("/categories/list", listCategoriesByAdmin)
+				dr.Post("/moods/list", listMoodsByAdmin)
+				dr.Post("/reject_reasons/list", listRejectReasons)
+			})
+
+			ar.Group(func(dr chi.Router) {
+				dr.Use(auth.RequireAdmin("music:dictionaries:edit"))
+
+				dr.Post("/categories/upsert", upsertCategories)
+				dr.Post("/categories/delete", deleteCategories)
+				dr.Post("/moods/upsert", upsertMoods)
+				dr.Post("/moods/delete", deleteMoods)
+				dr.Post("/reject_reasons/upsert", upsertRejectReasons)
+				dr.Post("/reject_reasons/delete", deleteRejectReasons)
+			})
+		})
+	})
+}
@@ -0,0 +1,76 @@
+package music
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/digitalmonsters/go-common/boilerplate_testing"
+	"github.com/digitalmonsters/music/configs"
+	"github.com/digitalmonsters/music/pkg/creators/categories"
+	"github.com/digitalmonsters/music/pkg/database"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+var config configs.Settings
+var gormDb *gorm.DB
+
+func TestMain(m *testing.M) {
+	config = configs.GetConfig()
+	gormDb = database.GetDb(database.DbTypeMaster)
+
+	Migrate()
+
+	os.Exit(m.Run())
+}
+
+func TestMigrate(t *testing.T) {
+	for _, table := range []string{"songs", "playlists", "categories", "creator_songs", "user_playlists",
+		"creator_statements"} {
+		assert.True(t, gormDb.Migrator().HasTable(table), table)
+	}
+}
+
+func TestListCategoriesRoute(t *testing.T) {
+	if err := boilerplate_testing.FlushPostgresTables(config.MasterDb, []string{"public.categories"}, nil, t); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := gormDb.Create(&[]database.Category{
+		{Name: "active category", SortOrder: 1, IsActive: true},
+		{Name: "hidden category", SortOrder: 2},
+	}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	r := chi.NewRouter()
+	r.Post("/music/categories/list", listCategories)
+
+	body, _ := json.Marshal(categories.PublicListRequest{Count: 10})
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/music/categories/list", bytes.NewReader(body)))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var resp struct {
+		Data    categories.PublicListResponse `json:"data"`
+		Success bool                          `json:"success"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.True(t, resp.Success)
+	if assert.Len(t, resp.Data.Items, 1) {
+		assert.Equal(t, "active category", resp.Data.Items[0].Name)
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/music/categories/list", bytes.NewReader([]byte("{"))))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
@@ -0,0 +1,29 @@
+package music
+
+import (
+	"net/http"
+
+	"github.com/digitalmonsters/music/pkg/database"
+	"github.com/digitalmonsters/music/pkg/search"
+	"go.elastic.co/apm"
+)
+
+func searchMusic(service *search.Service, sources []search.Source) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req search.SearchRequest
+
+		if err := decodeRequest(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		resp, err := service.Search(req, sources, database.GetDbWithContext(database.DbTypeReadonly, r.Context()),
+			apm.TransactionFromContext(r.Context()), r.Context())
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		writeResponse(w, resp)
+	}
+}
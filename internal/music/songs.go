@@ -0,0 +1,133 @@
+package music
+
+import (
+	"net/http"
+
+	"github.com/digitalmonsters/go-common/router"
+	"github.com/digitalmonsters/music/pkg/database"
+	"github.com/digitalmonsters/music/pkg/favorites"
+	"github.com/digitalmonsters/music/pkg/own_storage"
+	"github.com/digitalmonsters/music/pkg/popular"
+)
+
+func listPopularSongs(w http.ResponseWriter, r *http.Request) {
+	var req popular.GetPopularSongsRequest
+
+	if err := decodeRequest(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	resp, err := popular.GetPopularSongs(req, database.GetDbWithContext(database.DbTypeReadonly, r.Context()),
+		executionDataFromRequest(r))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeResponse(w, resp)
+}
+
+func addToFavorites(w http.ResponseWriter, r *http.Request) {
+	var req favorites.AddToFavoritesRequest
+
+	if err := decodeRequest(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	req.UserId = router.UserIdFromHttpRequest(r)
+
+	if err := favorites.AddToFavorites(req, database.GetDbWithContext(database.DbTypeMaster, r.Context())); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeResponse(w, nil)
+}
+
+func removeFromFavorites(w http.ResponseWriter, r *http.Request) {
+	var req favorites.RemoveFromFavoritesRequest
+
+	if err := decodeRequest(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	req.UserId = router.UserIdFromHttpRequest(r)
+
+	if err := favorites.RemoveFromFavorites(req, database.GetDbWithContext(database.DbTypeMaster, r.Context())); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeResponse(w, nil)
+}
+
+func listFavoriteSongs(w http.ResponseWriter, r *http.Request) {
+	var req favorites.FavoriteSongsListRequest
+
+	if err := decodeRequest(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	resp, err := favorites.FavoriteSongsList(req, database.GetDbWithContext(database.DbTypeReadonly, r.Context()),
+		executionDataFromRequest(r))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeResponse(w, resp)
+}
+
+func upsertOwnStorageSongs(w http.ResponseWriter, r *http.Request) {
+	var req own_storage.AddSongsToOwnStorageRequest
+
+	if err := decodeRequest(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	resp, err := own_storage.UpsertSongsToOwnStorageBulk(req, database.GetDbWithContext(database.DbTypeMaster, r.Context()))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeResponse(w, resp)
+}
+
+func deleteOwnStorageSongs(w http.ResponseWriter, r *http.Request) {
+	var req own_storage.DeleteSongsFromOwnStorageRequest
+
+	if err := decodeRequest(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := own_storage.DeleteSongsFromOwnStorageBulk(req, database.GetDbWithContext(database.DbTypeMaster, r.Context())); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeResponse(w, nil)
+}
+
+func listOwnStorageSongs(w http.ResponseWriter, r *http.Request) {
+	var req own_storage.OwnStorageMusicListRequest
+
+	if err := decodeRequest(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	resp, err := own_storage.OwnStorageMusicList(req, database.GetDbWithContext(database.DbTypeReadonly, r.Context()))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeResponse(w, resp)
+}
@@ -0,0 +1,168 @@
+package music
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/digitalmonsters/go-common/router"
+	"github.com/digitalmonsters/music/pkg/database"
+	"github.com/digitalmonsters/music/pkg/processing"
+	"github.com/digitalmonsters/music/pkg/uploader"
+	"github.com/pkg/errors"
+)
+
+// uploadOwner returns id of the session owner, creators and admins use the same session handlers with their own ids
+type uploadOwner func(r *http.Request) int64
+
+func createUploadSession(service *uploader.SessionService, owner uploadOwner, allowedTypes []uploader.UploadType) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req uploader.CreateSessionRequest
+
+		if err := decodeRequest(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		if len(allowedTypes) == 1 && req.UploadType == uploader.UploadTypeNone {
+			req.UploadType = allowedTypes[0]
+		}
+
+		resp, err := service.CreateSession(req, owner(r), allowedTypes,
+			database.GetDbWithContext(database.DbTypeMaster, r.Context()))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		writeResponse(w, resp)
+	}
+}
+
+func getUploadSession(service *uploader.SessionService, owner uploadOwner, allowedTypes []uploader.UploadType) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req uploader.SessionRequest
+
+		if err := decodeRequest(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		resp, err := service.GetSession(req, owner(r), allowedTypes,
+			database.GetDbWithContext(database.DbTypeMaster, r.Context()))
+		if err != nil {
+			writeError(w, http.StatusNotFound, err)
+			return
+		}
+
+		writeResponse(w, resp)
+	}
+}
+
+// uploadChunk accepts raw chunk body, session and offset are passed in query: PUT .../uploads/chunk?id=1&offset=0.
+// Optional Content-MD5 header is verified. On 409 client requests the session and resumes from uploaded_size
+func uploadChunk(service *uploader.SessionService, owner uploadOwner, allowedTypes []uploader.UploadType,
+	maxChunkSize int64) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(r.URL.Query().Get("id"), 10, 64)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, errors.New("invalid id"))
+			return
+		}
+
+		offset, err := strconv.ParseInt(r.URL.Query().Get("offset"), 10, 64)
+		if err != nil || offset < 0 {
+			writeError(w, http.StatusBadRequest, errors.New("invalid offset"))
+			return
+		}
+
+		body := http.MaxBytesReader(w, r.Body, maxChunkSize+1)
+		defer body.Close()
+
+		resp, err := service.UploadChunk(id, offset, body, r.Header.Get("Content-MD5"), owner(r), allowedTypes,
+			database.GetDbWithContext(database.DbTypeMaster, r.Context()))
+		if err != nil {
+			status := http.StatusBadRequest
+			if errors.Is(err, uploader.ErrOffsetMismatch) {
+				status = http.StatusConflict
+			}
+
+			writeError(w, status, err)
+			return
+		}
+
+		writeResponse(w, resp)
+	}
+}
+
+func finalizeUploadSession(service *uploader.SessionService, owner uploadOwner, allowedTypes []uploader.UploadType) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req uploader.SessionRequest
+
+		if err := decodeRequest(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		resp, err := service.FinalizeSession(req, owner(r), allowedTypes,
+			database.GetDbWithContext(database.DbTypeMaster, r.Context()))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		writeResponse(w, resp)
+	}
+}
+
+func abortUploadSession(service *uploader.SessionService, owner uploadOwner, allowedTypes []uploader.UploadType) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req uploader.SessionRequest
+
+		if err := decodeRequest(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := service.AbortSession(req, owner(r), allowedTypes,
+			database.GetDbWithContext(database.DbTypeMaster, r.Context())); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		writeResponse(w, nil)
+	}
+}
+
+type processUploadedRequest struct {
+	SessionId     int64   `json:"session_id"`
+	PreviewOffset float64 `json:"preview_offset"`
+}
+
+// processUploadedAudio schedules processing of a source file uploaded by session
+func processUploadedAudio(sessions *uploader.SessionService, service *processing.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req processUploadedRequest
+
+		if err := decodeRequest(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		db := database.GetDbWithContext(database.DbTypeMaster, r.Context())
+		userId := router.UserIdFromHttpRequest(r)
+
+		session, err := sessions.GetCompletedSession(req.SessionId, userId, uploader.UploadTypeCreatorsSongSource, db)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		resp, err := service.SubmitStored(userId, session.Path, session.Filename, req.PreviewOffset, db)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		writeResponse(w, resp)
+	}
+}
@@ -0,0 +1,87 @@
+package music
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/digitalmonsters/go-common/application"
+	"github.com/digitalmonsters/go-common/router"
+	"github.com/digitalmonsters/music/configs"
+	"github.com/digitalmonsters/music/pkg/database"
+	"github.com/digitalmonsters/music/pkg/processing"
+	"github.com/digitalmonsters/music/pkg/uploader"
+	"github.com/pkg/errors"
+)
+
+// upload accepts multipart form with a single "File" field and returns url of uploaded file
+func upload(cfg *configs.Settings, appConfig *application.Configurator[configs.AppConfig],
+	uploadType uploader.UploadType) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		resp, err := uploader.FileUpload(cfg, appConfig, uploadType, r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		writeResponse(w, resp)
+	}
+}
+
+// processAudio accepts multipart form with "File" field and optional "preview_offset" (seconds) and schedules
+// transcoding of the file. Returned job is polled via processingStatus
+func processAudio(service *processing.Service, maxFileSizeMb int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if maxFileSizeMb > 0 {
+			r.Body = http.MaxBytesReader(w, r.Body, int64(maxFileSizeMb+1)<<20)
+		}
+
+		file, header, err := r.FormFile("File")
+		if err != nil {
+			writeError(w, http.StatusBadRequest, errors.WithStack(err))
+			return
+		}
+
+		defer file.Close()
+
+		if r.MultipartForm != nil {
+			defer r.MultipartForm.RemoveAll()
+		}
+
+		var previewOffset float64
+		if v := r.FormValue("preview_offset"); v != "" {
+			if previewOffset, err = strconv.ParseFloat(v, 64); err != nil {
+				writeError(w, http.StatusBadRequest, errors.New("invalid preview_offset"))
+				return
+			}
+		}
+
+		resp, err := service.Submit(router.UserIdFromHttpRequest(r), file, header, previewOffset,
+			database.GetDbWithContext(database.DbTypeMaster, r.Context()), r.Context())
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		writeResponse(w, resp)
+	}
+}
+
+func processingStatus(service *processing.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req processing.GetJobRequest
+
+		if err := decodeRequest(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		resp, err := service.GetJob(req, router.UserIdFromHttpRequest(r),
+			database.GetDbWithContext(database.DbTypeMaster, r.Context()))
+		if err != nil {
+			writeError(w, http.StatusNotFound, err)
+			return
+		}
+
+		writeResponse(w, resp)
+	}
+}
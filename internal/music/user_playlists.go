@@ -0,0 +1,267 @@
+package music
+
+import (
+	"net/http"
+
+	"github.com/digitalmonsters/go-common/router"
+	"github.com/digitalmonsters/music/pkg/database"
+	"github.com/digitalmonsters/music/pkg/user_playlists"
+	"go.elastic.co/apm"
+)
+
+func createUserPlaylist(service *user_playlists.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req user_playlists.CreatePlaylistRequest
+
+		if err := decodeRequest(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		resp, err := service.Create(req, router.UserIdFromHttpRequest(r),
+			database.GetDbWithContext(database.DbTypeMaster, r.Context()))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		writeResponse(w, resp)
+	}
+}
+
+func updateUserPlaylist(service *user_playlists.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req user_playlists.UpdatePlaylistRequest
+
+		if err := decodeRequest(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		resp, err := service.Update(req, router.UserIdFromHttpRequest(r),
+			database.GetDbWithContext(database.DbTypeMaster, r.Context()))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		writeResponse(w, resp)
+	}
+}
+
+func deleteUserPlaylist(service *user_playlists.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req user_playlists.PlaylistRequest
+
+		if err := decodeRequest(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := service.Delete(req, router.UserIdFromHttpRequest(r),
+			database.GetDbWithContext(database.DbTypeMaster, r.Context())); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		writeResponse(w, nil)
+	}
+}
+
+func addUserPlaylistSongs(service *user_playlists.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req user_playlists.PlaylistSongsRequest
+
+		if err := decodeRequest(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		resp, err := service.AddSongs(req, router.UserIdFromHttpRequest(r),
+			database.GetDbWithContext(database.DbTypeMaster, r.Context()))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		writeResponse(w, resp)
+	}
+}
+
+func removeUserPlaylistSongs(service *user_playlists.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req user_playlists.PlaylistSongsRequest
+
+		if err := decodeRequest(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		resp, err := service.RemoveSongs(req, router.UserIdFromHttpRequest(r),
+			database.GetDbWithContext(database.DbTypeMaster, r.Context()))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		writeResponse(w, resp)
+	}
+}
+
+func reorderUserPlaylistSongs(service *user_playlists.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req user_playlists.PlaylistSongsRequest
+
+		if err := decodeRequest(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := service.ReorderSongs(req, router.UserIdFromHttpRequest(r),
+			database.GetDbWithContext(database.DbTypeMaster, r.Context())); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		writeResponse(w, nil)
+	}
+}
+
+func getUserPlaylist(service *user_playlists.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req user_playlists.PlaylistRequest
+
+		if err := decodeRequest(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		resp, err := service.Get(req, router.UserIdFromHttpRequest(r),
+			database.GetDbWithContext(database.DbTypeReadonly, r.Context()),
+			apm.TransactionFromContext(r.Context()), r.Context())
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		writeResponse(w, resp)
+	}
+}
+
+func listMyUserPlaylists(service *user_playlists.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req user_playlists.ListPlaylistsRequest
+
+		if err := decodeRequest(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		resp, err := service.ListMy(req, router.UserIdFromHttpRequest(r),
+			database.GetDbWithContext(database.DbTypeReadonly, r.Context()))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		writeResponse(w, resp)
+	}
+}
+
+func listUserPublicPlaylists(service *user_playlists.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req user_playlists.ListPlaylistsRequest
+
+		if err := decodeRequest(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		resp, err := service.ListUserPublic(req, router.UserIdFromHttpRequest(r),
+			database.GetDbWithContext(database.DbTypeReadonly, r.Context()))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		writeResponse(w, resp)
+	}
+}
+
+func listFollowedUserPlaylists(service *user_playlists.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req user_playlists.ListPlaylistsRequest
+
+		if err := decodeRequest(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		resp, err := service.ListFollowed(req, router.UserIdFromHttpRequest(r),
+			database.GetDbWithContext(database.DbTypeReadonly, r.Context()))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		writeResponse(w, resp)
+	}
+}
+
+func followUserPlaylist(service *user_playlists.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req user_playlists.PlaylistRequest
+
+		if err := decodeRequest(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := service.Follow(req, router.UserIdFromHttpRequest(r),
+			database.GetDbWithContext(database.DbTypeMaster, r.Context())); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		writeResponse(w, nil)
+	}
+}
+
+func unfollowUserPlaylist(service *user_playlists.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req user_playlists.PlaylistRequest
+
+		if err := decodeRequest(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := service.Unfollow(req, router.UserIdFromHttpRequest(r),
+			database.GetDbWithContext(database.DbTypeMaster, r.Context())); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		writeResponse(w, nil)
+	}
+}
+
+func shareUserPlaylist(service *user_playlists.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req user_playlists.PlaylistRequest
+
+		if err := decodeRequest(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		resp, err := service.Share(req, router.UserIdFromHttpRequest(r),
+			database.GetDbWithContext(database.DbTypeMaster, r.Context()))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		writeResponse(w, resp)
+	}
+}
@@ -0,0 +1,71 @@
+package music
+
+import (
+	"context"
+	"crypto/tls"
+	"time"
+
+	"github.com/RichardKnop/machinery/v1"
+	"github.com/digitalmonsters/go-common/application"
+	"github.com/digitalmonsters/go-common/boilerplate"
+	"github.com/digitalmonsters/go-common/eventsourcing"
+	"github.com/digitalmonsters/music/configs"
+	"github.com/digitalmonsters/music/pkg/creators/notifier"
+	"github.com/digitalmonsters/music/pkg/feed"
+	"github.com/digitalmonsters/music/pkg/feed/deduplicator"
+	"github.com/digitalmonsters/music/pkg/feed/feed_converter"
+	"github.com/digitalmonsters/music/pkg/global"
+	"github.com/go-redis/redis/v8"
+	"github.com/rs/zerolog/log"
+)
+
+const workerName = "music"
+
+// newJobber creates machinery server shared by all music background tasks. Tasks should be registered before
+// startWorker is called
+func newJobber(cfg configs.Settings) *machinery.Server {
+	jobber, err := configs.GetJobber(cfg.Jobber)
+	if err != nil {
+		log.Fatal().Err(err).Msg("[Music] can not create jobber")
+	}
+
+	return jobber
+}
+
+// newFeed creates music feed, its periodic score update task is registered on jobber
+func newFeed(jobber *machinery.Server, cfg configs.Settings, appConfig *application.Configurator[configs.AppConfig],
+	feedConverter *feed_converter.Service) *feed.Feed {
+	redisOptions := &redis.Options{
+		Addr: cfg.Redis.Host,
+		DB:   cfg.Redis.Db,
+	}
+
+	if cfg.Redis.Tls {
+		redisOptions.TLSConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+
+	return feed.NewFeed(deduplicator.NewDeDuplicator(redis.NewClient(redisOptions)), feedConverter, jobber, appConfig)
+}
+
+// startWorker launches machinery worker which runs feed score update and audio processing tasks
+func startWorker(jobber *machinery.Server, cfg configs.Settings) {
+	if boilerplate.GetCurrentEnvironment() == boilerplate.Ci {
+		return
+	}
+
+	worker := jobber.NewWorker(workerName, cfg.Jobber.Concurrency)
+
+	go func() {
+		if err := worker.Launch(); err != nil {
+			log.Error().Err(err).Msg("[Music] worker stopped")
+		}
+	}()
+}
+
+// creatorNotifiers publish creator request changes to kafka
+func creatorNotifiers(cfg configs.Settings, ctx context.Context) []global.INotifier {
+	return []global.INotifier{
+		notifier.NewService(time.Duration(cfg.NotifierCreatorsConfig.PollTimeMs)*time.Millisecond,
+			eventsourcing.NewKafkaEventPublisher(cfg.KafkaWriter, cfg.NotifierCreatorsConfig.KafkaTopic), ctx),
+	}
+}
@@ -0,0 +1,64 @@
+package notifications
+
+import (
+	"net/http"
+
+	"github.com/digitalmonsters/go-common/router"
+	"github.com/digitalmonsters/notification-handler/pkg/admin_query"
+	"github.com/digitalmonsters/notification-handler/pkg/database"
+	"github.com/pkg/errors"
+)
+
+func listAdminQueries(service admin_query.IService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeResponse(w, service.ListQueries())
+	}
+}
+
+func runAdminQuery(service admin_query.IService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req admin_query.RunQueryRequest
+
+		if err := decodeRequest(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		resp, err := service.RunQuery(req, router.AdminIdFromHttpRequest(r),
+			database.GetDbWithContext(database.DbTypeReadonly, r.Context()),
+			database.GetDbWithContext(database.DbTypeMaster, r.Context()), r.Context())
+		if err != nil {
+			switch {
+			case errors.Is(err, admin_query.ErrAccessDenied):
+				writeError(w, http.StatusForbidden, err)
+			case errors.Is(err, admin_query.ErrQueryNotFound), errors.Is(err, admin_query.ErrInvalidParams):
+				writeError(w, http.StatusBadRequest, err)
+			default:
+				writeError(w, http.StatusInternalServerError, err)
+			}
+
+			return
+		}
+
+		writeResponse(w, resp)
+	}
+}
+
+func listAdminQueryLogs(service admin_query.IService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req admin_query.ListLogsRequest
+
+		if err := decodeRequest(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		resp, err := service.ListLogs(req, database.GetDbWithContext(database.DbTypeReadonly, r.Context()))
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		writeResponse(w, resp)
+	}
+}
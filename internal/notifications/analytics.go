@@ -0,0 +1,27 @@
+package notifications
+
+import (
+	"net/http"
+
+	"github.com/digitalmonsters/notification-handler/pkg/analytics"
+	"github.com/digitalmonsters/notification-handler/pkg/database"
+)
+
+func templateStats(service analytics.IService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req analytics.TemplateStatsRequest
+
+		if err := decodeRequest(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		resp, err := service.GetTemplateStats(req, database.GetDbWithContext(database.DbTypeReadonly, r.Context()))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		writeResponse(w, resp)
+	}
+}
@@ -0,0 +1,85 @@
+package notifications
+
+import (
+	"net/http"
+
+	"github.com/digitalmonsters/go-common/router"
+	"github.com/digitalmonsters/notification-handler/pkg/campaign"
+	"github.com/digitalmonsters/notification-handler/pkg/database"
+)
+
+func createCampaign(service campaign.IService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req campaign.CreateCampaignRequest
+
+		if err := decodeRequest(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		resp, err := service.CreateCampaign(req, router.AdminIdFromHttpRequest(r),
+			database.GetDbWithContext(database.DbTypeMaster, r.Context()))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		writeResponse(w, resp)
+	}
+}
+
+func cancelCampaign(service campaign.IService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req campaign.CancelCampaignRequest
+
+		if err := decodeRequest(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := service.CancelCampaign(req, database.GetDbWithContext(database.DbTypeMaster, r.Context())); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		writeResponse(w, nil)
+	}
+}
+
+func getCampaign(service campaign.IService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req campaign.GetCampaignRequest
+
+		if err := decodeRequest(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		resp, err := service.GetCampaign(req, database.GetDbWithContext(database.DbTypeReadonly, r.Context()))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		writeResponse(w, resp)
+	}
+}
+
+func listCampaigns(service campaign.IService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req campaign.ListCampaignsRequest
+
+		if err := decodeRequest(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		resp, err := service.ListCampaigns(req, database.GetDbWithContext(database.DbTypeReadonly, r.Context()))
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		writeResponse(w, resp)
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"strings"
+
+	"github.com/digitalmonsters/notification-handler/pkg/database"
+	"github.com/digitalmonsters/notification-handler/pkg/storage"
+	"github.com/rs/zerolog/log"
+)
+
+// Copies scylla notification storage to postgres. Can be stopped at any time and started again,
+// it continues from the last copied page of every table
+func main() {
+	tables := flag.String("tables", "", "comma separated tables to copy, all by default: "+
+		strings.Join(storage.BackfillTableNames(), ","))
+	batchSize := flag.Int("batch", 0, "rows per page")
+	reset := flag.Bool("reset", false, "copy tables from the beginning, even already completed ones")
+	flag.Parse()
+
+	req := storage.BackfillRequest{
+		BatchSize: *batchSize,
+		Reset:     *reset,
+	}
+
+	if len(*tables) > 0 {
+		req.Tables = strings.Split(*tables, ",")
+	}
+
+	if database.GetScyllaSession() == nil {
+		log.Fatal().Msg("[Backfill] scylla session is not created, backfill should be run before postgres mode")
+	}
+
+	results, err := storage.Backfill(database.GetScyllaSession(), database.GetDb(database.DbTypeMaster), req,
+		context.Background())
+
+	for _, result := range results {
+		log.Info().Str("table", result.Table).Int64("copied_count", result.CopiedCount).
+			Bool("completed", result.CompletedAt.Valid).Msg("[Backfill] table done")
+	}
+
+	if err != nil {
+		log.Fatal().Err(err).Msg("[Backfill] failed, run again to continue")
+	}
+}
@@ -17,6 +17,15 @@ const (
 	PushNotificationDeadlineKeyMinutes = 720
 	PushNotificationDeadlineMinutes    = 720
 	PushNotificationJobCron            = "1 */12 * * *" // should be equal to PushNotificationDeadlineMinutes
+	PushCampaignJobCron                = "* * * * *"
+	PushCampaignBatchSize              = 500
+	PushCampaignDefaultRatePerSecond   = 100
+	TokenCleanupJobCron                = "30 3 * * *"
+	TokenCleanupBatchSize              = 1000
+	TokenDefaultInactiveDays           = 90
+	StorageCleanupJobCron              = "15 * * * *"
+	StorageCleanupBatchSize            = 5000
+	StorageBackfillDefaultBatchSize    = 500
 )
 
 const (
@@ -52,11 +61,32 @@ type Settings struct {
 	Jobber                         JobberConfig                           `json:"Jobber"`
 	MusicCreatorListener           boilerplate.KafkaListenerConfiguration `json:"MusicCreatorListener"`
 	EmailConfig                    mail.EmailService                      `json:"AwsSMTPConfig"`
+	Tokens                         TokensConfig                           `json:"Tokens"`
+	Realtime                       RealtimeConfig                         `json:"Realtime"`
+	Storage                        StorageConfig                          `json:"Storage"`
 
 	// Firebase Configuration
 	Firebase FirebaseConfig `json:"Firebase"`
 }
 
+type TokensConfig struct {
+	InactiveDays int `json:"InactiveDays"` // tokens of devices not seen for this period are removed
+}
+
+// StorageConfig controls where notifications, grouping queue, settings and cached users are stored during
+// scylla to postgres migration
+type StorageConfig struct {
+	Mode     string `json:"Mode"`     // scylla (default), dual or postgres
+	ReadFrom string `json:"ReadFrom"` // scylla (default) or postgres, used only in dual mode
+}
+
+type RealtimeConfig struct {
+	Broker         string                  `json:"Broker"` // memory (default) or redis
+	Redis          boilerplate.RedisConfig `json:"Redis"`
+	Channel        string                  `json:"Channel"`
+	AllowedOrigins []string                `json:"AllowedOrigins"` // browser origins allowed to open websocket stream
+}
+
 type FirebaseConfig struct {
 	ServiceAccountJSON map[string]interface{} `json:"ServiceAccountJSON"`
 }
@@ -145,4 +175,11 @@ const (
 	GeneralPushNotificationTask  MachineryTask = "general:push_notification"
 	PeriodicPushNotificationTask MachineryTask = "periodic:push_notification"
 	UserPushNotificationTask     MachineryTask = "user:push_notification"
+	GeneralPushCampaignTask      MachineryTask = "general:push_campaign"
+	PeriodicPushCampaignTask     MachineryTask = "periodic:push_campaign"
+	PushCampaignBatchTask        MachineryTask = "batch:push_campaign"
+	GeneralTokenCleanupTask      MachineryTask = "general:token_cleanup"
+	PeriodicTokenCleanupTask     MachineryTask = "periodic:token_cleanup"
+	GeneralStorageCleanupTask    MachineryTask = "general:storage_cleanup"
+	PeriodicStorageCleanupTask   MachineryTask = "periodic:storage_cleanup"
 )
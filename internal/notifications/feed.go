@@ -0,0 +1,30 @@
+package notifications
+
+import (
+	"net/http"
+
+	"github.com/digitalmonsters/go-common/router"
+	"github.com/digitalmonsters/notification-handler/pkg/database"
+	"github.com/digitalmonsters/notification-handler/pkg/notification"
+)
+
+// readAllNotifications resets unread counter of the user, streams of the user get the new counter after commit
+func readAllNotifications(w http.ResponseWriter, r *http.Request) {
+	tx := database.GetDbWithContext(database.DbTypeMaster, r.Context()).Begin()
+	defer tx.Rollback()
+
+	callbacks, err := notification.ReadAllNotifications(tx, router.UserIdFromHttpRequest(r))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if err = tx.Commit().Error; err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	runCallbacks(callbacks, r.Context())
+
+	writeResponse(w, nil)
+}
@@ -0,0 +1,55 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/digitalmonsters/go-common/callback"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+type apiResponse struct {
+	Data    interface{} `json:"data"`
+	Success bool        `json:"success"`
+	Error   string      `json:"error,omitempty"`
+}
+
+func decodeRequest(r *http.Request, target interface{}) error {
+	if err := json.NewDecoder(r.Body).Decode(target); err != nil {
+		return errors.Wrap(err, "invalid request body")
+	}
+
+	return nil
+}
+
+func writeResponse(w http.ResponseWriter, data interface{}) {
+	writeJson(w, http.StatusOK, apiResponse{Data: data, Success: true})
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	if status >= http.StatusInternalServerError {
+		log.Err(err).Send()
+	}
+
+	writeJson(w, status, apiResponse{Success: false, Error: err.Error()})
+}
+
+func writeJson(w http.ResponseWriter, status int, resp apiResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.Err(err).Send()
+	}
+}
+
+// runCallbacks executes callbacks after commit, the change is already saved, so errors are only logged
+func runCallbacks(callbacks []callback.Callback, ctx context.Context) {
+	for _, callbackFn := range callbacks {
+		if err := callbackFn(ctx); err != nil {
+			log.Ctx(ctx).Err(err).Send()
+		}
+	}
+}
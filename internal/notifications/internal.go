@@ -0,0 +1,66 @@
+package notifications
+
+import (
+	"net/http"
+
+	"github.com/digitalmonsters/go-common/wrappers/notification_handler"
+	"github.com/digitalmonsters/notification-handler/pkg/database"
+	"github.com/digitalmonsters/notification-handler/pkg/notification"
+)
+
+// internalCreateNotification saves notification sent by other services, it reaches realtime stream after commit
+func internalCreateNotification(w http.ResponseWriter, r *http.Request) {
+	var req notification_handler.CreateNotificationRequest
+
+	if err := decodeRequest(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	tx := database.GetDbWithContext(database.DbTypeMaster, r.Context()).Begin()
+	defer tx.Rollback()
+
+	resp, callbacks, err := notification.CreateNotification(r.Context(), req, tx)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if err = tx.Commit().Error; err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	runCallbacks(callbacks, r.Context())
+
+	writeResponse(w, resp)
+}
+
+// internalCreateInAppNotification saves in-app notification sent by other services, it reaches realtime stream
+// after commit
+func internalCreateInAppNotification(w http.ResponseWriter, r *http.Request) {
+	var req notification_handler.CreateNotificationRequest
+
+	if err := decodeRequest(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	tx := database.GetDbWithContext(database.DbTypeMaster, r.Context()).Begin()
+	defer tx.Rollback()
+
+	callbacks, err := notification.CreateInAppNotification(req, tx)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if err = tx.Commit().Error; err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	runCallbacks(callbacks, r.Context())
+
+	writeResponse(w, nil)
+}
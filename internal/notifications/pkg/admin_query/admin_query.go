@@ -0,0 +1,312 @@
+package admin_query
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/digitalmonsters/go-common/common"
+	"github.com/digitalmonsters/go-common/wrappers/auth_go"
+	"github.com/digitalmonsters/notification-handler/pkg/database"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"go.elastic.co/apm"
+	"gopkg.in/guregu/null.v4"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultRowLimit  = 100
+	maxRowLimit      = 1000
+	statementTimeout = 5 * time.Second
+	maxStringParam   = 255
+)
+
+var (
+	ErrQueryNotFound = errors.New("query not found")
+	ErrAccessDenied  = errors.New("admin user does not have access to this query")
+	ErrInvalidParams = errors.New("invalid params")
+)
+
+type IService interface {
+	ListQueries() ListQueriesResponse
+	RunQuery(req RunQueryRequest, adminId int64, readonlyDb *gorm.DB, masterDb *gorm.DB,
+		ctx context.Context) (*RunQueryResponse, error)
+	ListLogs(req ListLogsRequest, db *gorm.DB) (*ListLogsResponse, error)
+}
+
+type service struct {
+	authWrapper auth_go.IAuthGoWrapper
+}
+
+func NewService(authWrapper auth_go.IAuthGoWrapper) IService {
+	return &service{
+		authWrapper: authWrapper,
+	}
+}
+
+func (s service) ListQueries() ListQueriesResponse {
+	items := make([]QueryInfo, 0, len(queries))
+
+	for _, q := range queries {
+		items = append(items, q.info())
+	}
+
+	return ListQueriesResponse{Items: items}
+}
+
+// RunQuery runs a named query in a read only transaction with statement timeout. Every run is written to audit log,
+// including denied and failed ones
+func (s service) RunQuery(req RunQueryRequest, adminId int64, readonlyDb *gorm.DB, masterDb *gorm.DB,
+	ctx context.Context) (*RunQueryResponse, error) {
+	startedAt := time.Now()
+
+	resp, err := s.runQuery(req, adminId, readonlyDb, ctx)
+
+	auditLog := database.AdminQueryLog{
+		AdminId:    adminId,
+		QueryName:  req.Name,
+		Params:     marshalParams(req.Params),
+		DurationMs: time.Since(startedAt).Milliseconds(),
+		CreatedAt:  time.Now().UTC(),
+	}
+
+	if resp != nil {
+		auditLog.RowCount = len(resp.Rows)
+	}
+
+	if err != nil {
+		auditLog.Error = null.StringFrom(err.Error())
+	}
+
+	if auditErr := masterDb.Create(&auditLog).Error; auditErr != nil {
+		log.Ctx(ctx).Error().Err(auditErr).Str("query_name", req.Name).Msg("[AdminQuery] can not write audit log")
+
+		if err == nil {
+			return nil, errors.WithStack(auditErr) // results are not returned without audit record
+		}
+	}
+
+	return resp, err
+}
+
+func (s service) runQuery(req RunQueryRequest, adminId int64, db *gorm.DB, ctx context.Context) (*RunQueryResponse, error) {
+	q, ok := findQuery(req.Name)
+	if !ok {
+		return nil, errors.Wrapf(ErrQueryNotFound, "query [%v]", req.Name)
+	}
+
+	if err := s.checkAccess(q, adminId, ctx); err != nil {
+		return nil, err
+	}
+
+	params, err := parseParams(q, req.Params)
+	if err != nil {
+		return nil, err
+	}
+
+	if q.validate != nil {
+		if err = q.validate(params); err != nil {
+			return nil, errors.Wrap(ErrInvalidParams, err.Error())
+		}
+	}
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultRowLimit
+	}
+
+	if limit > maxRowLimit {
+		limit = maxRowLimit
+	}
+
+	params["row_limit"] = limit + 1 // one extra row tells that result was truncated
+
+	var rows []map[string]interface{}
+
+	if err = db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(fmt.Sprintf("set local statement_timeout = %d", statementTimeout.Milliseconds())).Error; err != nil {
+			return errors.WithStack(err)
+		}
+
+		return errors.WithStack(tx.Raw(q.sql, params).Find(&rows).Error)
+	}, &sql.TxOptions{ReadOnly: true}); err != nil {
+		return nil, err
+	}
+
+	resp := &RunQueryResponse{
+		Rows: rows,
+	}
+
+	if len(resp.Rows) > limit {
+		resp.Rows = resp.Rows[:limit]
+		resp.Truncated = true
+	}
+
+	if resp.Rows == nil {
+		resp.Rows = []map[string]interface{}{}
+	}
+
+	return resp, nil
+}
+
+// checkAccess follows admin command rules: public queries only require admin, others are checked by rbac object
+func (s service) checkAccess(q query, adminId int64, ctx context.Context) error {
+	if q.accessLevel == common.AccessLevelPublic {
+		return nil
+	}
+
+	resp := <-s.authWrapper.CheckAdminPermissions(adminId, q.rbacObject, apm.TransactionFromContext(ctx), false)
+	if resp.Error != nil {
+		return errors.WithStack(resp.Error.ToError())
+	}
+
+	if !resp.Resp.HasAccess {
+		return errors.Wrapf(ErrAccessDenied, "query [%v]", q.name)
+	}
+
+	return nil
+}
+
+func (s service) ListLogs(req ListLogsRequest, db *gorm.DB) (*ListLogsResponse, error) {
+	query := db.Model(&database.AdminQueryLog{})
+
+	if req.AdminId.Valid {
+		query = query.Where("admin_id = ?", req.AdminId.Int64)
+	}
+
+	if req.QueryName.Valid {
+		query = query.Where("query_name = ?", req.QueryName.String)
+	}
+
+	var totalCount null.Int
+
+	if req.Offset == 0 {
+		var count int64
+		if err := query.Count(&count).Error; err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		totalCount = null.IntFrom(count)
+	}
+
+	if req.Limit <= 0 || req.Limit > maxRowLimit {
+		req.Limit = defaultRowLimit
+	}
+
+	var items []database.AdminQueryLog
+
+	if err := query.Order("created_at desc, id desc").Limit(req.Limit).Offset(req.Offset).Find(&items).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return &ListLogsResponse{
+		Items:      items,
+		TotalCount: totalCount,
+	}, nil
+}
+
+func parseParams(q query, raw map[string]interface{}) (map[string]interface{}, error) {
+	params := make(map[string]interface{}, len(q.params)+1)
+
+	for name := range raw {
+		found := false
+
+		for _, param := range q.params {
+			if param.Name == name {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return nil, errors.Wrapf(ErrInvalidParams, "unknown param [%v]", name)
+		}
+	}
+
+	for _, param := range q.params {
+		value, ok := raw[param.Name]
+		if !ok || value == nil {
+			if param.Required {
+				return nil, errors.Wrapf(ErrInvalidParams, "param [%v] is required", param.Name)
+			}
+
+			params[param.Name] = nil
+			continue
+		}
+
+		parsed, err := parseParam(param, value)
+		if err != nil {
+			return nil, errors.Wrapf(ErrInvalidParams, "param [%v]: %v", param.Name, err.Error())
+		}
+
+		params[param.Name] = parsed
+	}
+
+	return params, nil
+}
+
+func parseParam(param Param, value interface{}) (interface{}, error) {
+	switch param.Type {
+	case ParamTypeInt:
+		switch v := value.(type) {
+		case float64:
+			if v != math.Trunc(v) {
+				return nil, errors.New("should be an integer")
+			}
+
+			return int64(v), nil
+		case string:
+			parsed, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return nil, errors.New("should be an integer")
+			}
+
+			return parsed, nil
+		}
+
+		return nil, errors.New("should be an integer")
+	case ParamTypeString:
+		v, ok := value.(string)
+		if !ok {
+			return nil, errors.New("should be a string")
+		}
+
+		if len(v) > maxStringParam {
+			return nil, errors.New("is too long")
+		}
+
+		return v, nil
+	case ParamTypeTime:
+		v, ok := value.(string)
+		if !ok {
+			return nil, errors.New("should be a RFC3339 time")
+		}
+
+		parsed, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return nil, errors.New("should be a RFC3339 time")
+		}
+
+		return parsed.UTC(), nil
+	}
+
+	return nil, errors.Errorf("unsupported type %v", param.Type)
+}
+
+func marshalParams(params map[string]interface{}) string {
+	if len(params) == 0 {
+		return "{}"
+	}
+
+	marshalled, err := json.Marshal(params)
+	if err != nil {
+		return "{}"
+	}
+
+	return string(marshalled)
+}
@@ -0,0 +1,195 @@
+package admin_query
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/digitalmonsters/go-common/boilerplate_testing"
+	"github.com/digitalmonsters/go-common/common"
+	"github.com/digitalmonsters/go-common/wrappers/auth_go"
+	"github.com/digitalmonsters/notification-handler/configs"
+	"github.com/digitalmonsters/notification-handler/pkg/database"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"go.elastic.co/apm"
+	"gorm.io/gorm"
+)
+
+var config configs.Settings
+var gormDb *gorm.DB
+
+func TestMain(m *testing.M) {
+	config = configs.GetConfig()
+	gormDb = database.GetDb(database.DbTypeMaster)
+	os.Exit(m.Run())
+}
+
+var testQuery = query{
+	name: "test_query",
+	params: []Param{
+		{Name: "user_id", Type: ParamTypeInt, Required: true},
+		{Name: "type", Type: ParamTypeString},
+		{Name: "date_from", Type: ParamTypeTime},
+	},
+}
+
+func TestParseParams(t *testing.T) {
+	params, err := parseParams(testQuery, map[string]interface{}{
+		"user_id":   float64(10), // json numbers are decoded as float64
+		"date_from": "2022-05-01T10:00:00+02:00",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, map[string]interface{}{
+		"user_id":   int64(10),
+		"type":      nil,
+		"date_from": time.Date(2022, 5, 1, 8, 0, 0, 0, time.UTC),
+	}, params)
+
+	params, err = parseParams(testQuery, map[string]interface{}{"user_id": "11", "type": "push.content.like"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, int64(11), params["user_id"])
+	assert.Equal(t, "push.content.like", params["type"])
+}
+
+func TestParseParams_Invalid(t *testing.T) {
+	for name, raw := range map[string]map[string]interface{}{
+		"unknown param":          {"user_id": float64(10), "row_limit": float64(100000)},
+		"missing required param": {"type": "push.content.like"},
+		"null required param":    {"user_id": nil},
+		"fractional int":         {"user_id": 10.5},
+		"not a number":           {"user_id": "10 or 1=1"},
+		"bool instead of int":    {"user_id": true},
+		"int instead of string":  {"user_id": float64(10), "type": float64(1)},
+		"too long string":        {"user_id": float64(10), "type": string(make([]byte, maxStringParam+1))},
+		"not a RFC3339 time":     {"user_id": float64(10), "date_from": "2022-05-01"},
+	} {
+		_, err := parseParams(testQuery, raw)
+		assert.True(t, errors.Is(err, ErrInvalidParams), name)
+	}
+}
+
+// withQueries replaces vetted queries for the test
+func withQueries(t *testing.T, testQueries ...query) {
+	original := queries
+	queries = testQueries
+
+	t.Cleanup(func() {
+		queries = original
+	})
+}
+
+func flushLogs(t *testing.T) {
+	if err := boilerplate_testing.FlushPostgresTables(config.MasterDb, []string{"public.admin_query_logs"}, nil,
+		t); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func getLogs(t *testing.T) []database.AdminQueryLog {
+	var logs []database.AdminQueryLog
+	if err := gormDb.Order("id").Find(&logs).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	return logs
+}
+
+func TestService_RunQuery_Truncated(t *testing.T) {
+	flushLogs(t)
+
+	withQueries(t, query{
+		name:        "numbers",
+		accessLevel: common.AccessLevelPublic,
+		sql:         "select n from generate_series(1, 5) as n order by n limit @row_limit",
+	})
+
+	service := NewService(nil)
+
+	resp, err := service.RunQuery(RunQueryRequest{Name: "numbers", Limit: 3}, 1, gormDb, gormDb, context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Len(t, resp.Rows, 3)
+	assert.True(t, resp.Truncated)
+
+	resp, err = service.RunQuery(RunQueryRequest{Name: "numbers", Limit: 5}, 1, gormDb, gormDb, context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Len(t, resp.Rows, 5)
+	assert.False(t, resp.Truncated)
+
+	logs := getLogs(t)
+	if assert.Len(t, logs, 2) {
+		assert.Equal(t, 3, logs[0].RowCount)
+		assert.Equal(t, 5, logs[1].RowCount)
+		assert.False(t, logs[0].Error.Valid)
+	}
+}
+
+func TestService_RunQuery_AccessDenied(t *testing.T) {
+	flushLogs(t)
+
+	withQueries(t, query{
+		name:        "numbers",
+		accessLevel: common.AccessLevelRead,
+		rbacObject:  "numbers_object",
+		sql:         "select n from generate_series(1, 5) as n order by n limit @row_limit",
+	})
+
+	var checkedObject string
+
+	service := NewService(&auth_go.AuthGoWrapperMock{
+		CheckAdminPermissionsFn: func(userId int64, obj string, transaction *apm.Transaction,
+			forceLog bool) chan auth_go.CheckAdminPermissionsResponseChan {
+			checkedObject = obj
+
+			ch := make(chan auth_go.CheckAdminPermissionsResponseChan, 1)
+			ch <- auth_go.CheckAdminPermissionsResponseChan{Resp: auth_go.CheckAdminPermissionsResponse{UserId: userId}}
+
+			return ch
+		},
+	})
+
+	resp, err := service.RunQuery(RunQueryRequest{Name: "numbers"}, 1, gormDb, gormDb, context.Background())
+	assert.Nil(t, resp)
+	assert.True(t, errors.Is(err, ErrAccessDenied))
+	assert.Equal(t, "numbers_object", checkedObject)
+
+	logs := getLogs(t)
+	if assert.Len(t, logs, 1) {
+		assert.True(t, logs[0].Error.Valid)
+	}
+}
+
+func TestService_RunQuery_AuditFailure(t *testing.T) {
+	withQueries(t, query{
+		name:        "numbers",
+		accessLevel: common.AccessLevelPublic,
+		sql:         "select n from generate_series(1, 5) as n order by n limit @row_limit",
+	})
+
+	// audit log can not be written to a finished transaction
+	masterDb := gormDb.Begin()
+	masterDb.Rollback()
+
+	service := NewService(nil)
+
+	resp, err := service.RunQuery(RunQueryRequest{Name: "numbers"}, 1, gormDb, masterDb, context.Background())
+	assert.Nil(t, resp) // results are withheld
+	assert.NotNil(t, err)
+
+	// original error is kept for failed queries
+	_, err = service.RunQuery(RunQueryRequest{Name: "unknown"}, 1, gormDb, masterDb, context.Background())
+	assert.True(t, errors.Is(err, ErrQueryNotFound))
+}
@@ -0,0 +1,133 @@
+package admin_query
+
+import (
+	"time"
+
+	"github.com/digitalmonsters/go-common/common"
+	"github.com/pkg/errors"
+)
+
+const maxDeliveryStatsPeriod = 31 * 24 * time.Hour
+
+// query is a vetted read only statement. Sql uses named params, @row_limit is always bound by the service
+type query struct {
+	name        string
+	description string
+	accessLevel common.AccessLevel
+	rbacObject  string
+	params      []Param
+	sql         string
+	validate    func(params map[string]interface{}) error
+}
+
+func (q query) info() QueryInfo {
+	return QueryInfo{
+		Name:        q.name,
+		Description: q.description,
+		AccessLevel: q.accessLevel.ToString(),
+		RbacObject:  q.rbacObject,
+		Params:      q.params,
+	}
+}
+
+var queries = []query{
+	{
+		name:        "notification_history",
+		description: "Notifications feed of a user, newest first",
+		accessLevel: common.AccessLevelRead,
+		rbacObject:  "notifications_history",
+		params: []Param{
+			{Name: "user_id", Type: ParamTypeInt, Required: true},
+			{Name: "type", Type: ParamTypeString, Description: "notification type, e.g. push.content.like"},
+			{Name: "date_from", Type: ParamTypeTime},
+			{Name: "date_to", Type: ParamTypeTime},
+		},
+		sql: `select id, type, title, message, related_user_id, content_id, comment_id, created_at
+			from notifications
+			where user_id = @user_id
+				and (cast(@type as text) is null or type = @type)
+				and (cast(@date_from as timestamptz) is null or created_at >= @date_from)
+				and (cast(@date_to as timestamptz) is null or created_at < @date_to)
+			order by created_at desc
+			limit @row_limit`,
+	},
+	{
+		name:        "user_push_sends",
+		description: "Pushes accepted for devices of a user and whether they were opened, newest first",
+		accessLevel: common.AccessLevelRead,
+		rbacObject:  "notifications_history",
+		params: []Param{
+			{Name: "user_id", Type: ParamTypeInt, Required: true},
+			{Name: "template_id", Type: ParamTypeString},
+			{Name: "date_from", Type: ParamTypeTime},
+		},
+		sql: `select s.id, s.device_id, s.template_id, s.kind, s.platform, s.language, s.campaign_id, s.created_at,
+				exists(select 1 from track_fcm_notifications o
+					where o.notification_id = s.id and o.device_id = s.device_id) as opened
+			from push_sends s
+			where s.user_id = @user_id
+				and (cast(@template_id as text) is null or s.template_id = @template_id)
+				and (cast(@date_from as timestamptz) is null or s.created_at >= @date_from)
+			order by s.created_at desc
+			limit @row_limit`,
+	},
+	{
+		name:        "device_tokens",
+		description: "Devices of a user. Push tokens are masked",
+		accessLevel: common.AccessLevelRead,
+		rbacObject:  "notifications_devices",
+		params: []Param{
+			{Name: "user_id", Type: ParamTypeInt, Required: true},
+		},
+		sql: `select "deviceId" as device_id, platform, left("pushToken", 12) || '...' as push_token_prefix,
+				"updatedAt" as updated_at, last_seen_at
+			from devices
+			where "userId" = @user_id
+			order by last_seen_at desc
+			limit @row_limit`,
+	},
+	{
+		name:        "delivery_stats",
+		description: "Pushes sent and opened per template and platform for a period up to 31 days",
+		accessLevel: common.AccessLevelRead,
+		rbacObject:  "notifications_stats",
+		params: []Param{
+			{Name: "date_from", Type: ParamTypeTime, Required: true},
+			{Name: "date_to", Type: ParamTypeTime, Required: true},
+			{Name: "template_id", Type: ParamTypeString},
+		},
+		sql: `select s.template_id, s.platform, count(*) as sent, count(o.notification_id) as opened
+			from push_sends s
+				left join (select distinct notification_id, device_id from track_fcm_notifications
+					where created_at >= @date_from) o on o.notification_id = s.id and o.device_id = s.device_id
+			where s.created_at >= @date_from and s.created_at < @date_to
+				and (cast(@template_id as text) is null or s.template_id = @template_id)
+			group by 1, 2
+			order by sent desc
+			limit @row_limit`,
+		validate: func(params map[string]interface{}) error {
+			dateFrom := params["date_from"].(time.Time)
+			dateTo := params["date_to"].(time.Time)
+
+			if !dateFrom.Before(dateTo) {
+				return errors.New("date_from should be before date_to")
+			}
+
+			if dateTo.Sub(dateFrom) > maxDeliveryStatsPeriod {
+				return errors.New("period is too long")
+			}
+
+			return nil
+		},
+	},
+}
+
+func findQuery(name string) (query, bool) {
+	for _, q := range queries {
+		if q.name == name {
+			return q, true
+		}
+	}
+
+	return query{}, false
+}
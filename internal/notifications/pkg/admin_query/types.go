@@ -0,0 +1,56 @@
+package admin_query
+
+import (
+	"github.com/digitalmonsters/notification-handler/pkg/database"
+	"gopkg.in/guregu/null.v4"
+)
+
+type ParamType string
+
+const (
+	ParamTypeInt    ParamType = "int"
+	ParamTypeString ParamType = "string"
+	ParamTypeTime   ParamType = "time" // RFC3339
+)
+
+type Param struct {
+	Name        string    `json:"name"`
+	Type        ParamType `json:"type"`
+	Required    bool      `json:"required"`
+	Description string    `json:"description"`
+}
+
+type QueryInfo struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	AccessLevel string  `json:"access_level"`
+	RbacObject  string  `json:"rbac_object"`
+	Params      []Param `json:"params"`
+}
+
+type ListQueriesResponse struct {
+	Items []QueryInfo `json:"items"`
+}
+
+type RunQueryRequest struct {
+	Name   string                 `json:"name"`
+	Params map[string]interface{} `json:"params"`
+	Limit  int                    `json:"limit"`
+}
+
+type RunQueryResponse struct {
+	Rows      []map[string]interface{} `json:"rows"`
+	Truncated bool                     `json:"truncated"`
+}
+
+type ListLogsRequest struct {
+	AdminId   null.Int    `json:"admin_id"`
+	QueryName null.String `json:"query_name"`
+	Limit     int         `json:"limit"`
+	Offset    int         `json:"offset"`
+}
+
+type ListLogsResponse struct {
+	Items      []database.AdminQueryLog `json:"items"`
+	TotalCount null.Int                 `json:"total_count"`
+}
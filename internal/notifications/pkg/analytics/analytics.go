@@ -0,0 +1,106 @@
+package analytics
+
+import (
+	"time"
+
+	"github.com/digitalmonsters/go-common/common"
+	"github.com/digitalmonsters/go-common/translation"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+const maxStatsPeriod = 92 * 24 * time.Hour
+
+type IService interface {
+	GetTemplateStats(req TemplateStatsRequest, db *gorm.DB) (*TemplateStatsResponse, error)
+}
+
+type service struct {
+}
+
+func NewService() IService {
+	return &service{}
+}
+
+// GetTemplateStats returns push sends and opens per template per day (UTC). A send is a push accepted for a device,
+// it is counted as opened when the same device reported an open for it
+func (s service) GetTemplateStats(req TemplateStatsRequest, db *gorm.DB) (*TemplateStatsResponse, error) {
+	if req.DateFrom.IsZero() || req.DateTo.IsZero() || !req.DateFrom.Before(req.DateTo) {
+		return nil, errors.New("date_from should be before date_to")
+	}
+
+	if req.DateTo.Sub(req.DateFrom) > maxStatsPeriod {
+		return nil, errors.New("period is too long")
+	}
+
+	query := db.Table("push_sends s").
+		Select("s.template_id, date_trunc('day', s.created_at at time zone 'UTC') as day, s.platform, s.language, "+
+			"count(*) as sent, count(o.notification_id) as opened").
+		Joins("left join (select distinct notification_id, device_id from track_fcm_notifications where created_at >= ?) o "+
+			"on o.notification_id = s.id and o.device_id = s.device_id", req.DateFrom).
+		Where("s.created_at >= ? and s.created_at < ?", req.DateFrom, req.DateTo)
+
+	if len(req.TemplateIds) > 0 {
+		query = query.Where("s.template_id in ?", req.TemplateIds)
+	}
+
+	if len(req.Platforms) > 0 {
+		query = query.Where("s.platform in ?", req.Platforms)
+	}
+
+	if len(req.Languages) > 0 {
+		query = query.Where("s.language in ?", req.Languages)
+	}
+
+	var records []statsRecord
+
+	if err := query.Group("1, 2, 3, 4").Order("2, 1").Scan(&records).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return &TemplateStatsResponse{
+		Items: aggregate(records),
+	}, nil
+}
+
+func aggregate(records []statsRecord) []TemplateDayStats {
+	items := make([]TemplateDayStats, 0)
+	indexes := map[string]int{}
+
+	for _, r := range records {
+		day := r.Day.Format("2006-01-02")
+		key := day + "_" + r.TemplateId
+
+		i, ok := indexes[key]
+		if !ok {
+			items = append(items, TemplateDayStats{
+				TemplateId: r.TemplateId,
+				Day:        day,
+				Platforms:  map[common.DeviceType]Stats{},
+				Languages:  map[translation.Language]Stats{},
+			})
+
+			i = len(items) - 1
+			indexes[key] = i
+		}
+
+		item := &items[i]
+
+		item.Stats = item.Stats.add(r.Sent, r.Opened)
+		item.Platforms[r.Platform] = item.Platforms[r.Platform].add(r.Sent, r.Opened)
+		item.Languages[r.Language] = item.Languages[r.Language].add(r.Sent, r.Opened)
+	}
+
+	return items
+}
+
+func (s Stats) add(sent int64, opened int64) Stats {
+	s.Sent += sent
+	s.Opened += opened
+
+	if s.Sent > 0 {
+		s.Ctr = float64(s.Opened) / float64(s.Sent)
+	}
+
+	return s
+}
@@ -0,0 +1,34 @@
+package analytics
+
+import (
+	"github.com/digitalmonsters/go-common/common"
+	"github.com/digitalmonsters/go-common/translation"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestAggregate(t *testing.T) {
+	day := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+
+	items := aggregate([]statsRecord{
+		{TemplateId: "content_like", Day: day, Platform: common.DeviceTypeIos, Language: translation.DefaultUserLanguage, Sent: 6, Opened: 3},
+		{TemplateId: "content_like", Day: day, Platform: common.DeviceTypeAndroid, Language: translation.DefaultUserLanguage, Sent: 4, Opened: 0},
+		{TemplateId: "tip", Day: day, Platform: common.DeviceTypeIos, Language: translation.DefaultUserLanguage, Sent: 0, Opened: 0},
+		{TemplateId: "content_like", Day: day.Add(24 * time.Hour), Platform: common.DeviceTypeIos, Language: translation.DefaultUserLanguage, Sent: 1, Opened: 1},
+	})
+
+	assert.Len(t, items, 3)
+
+	assert.Equal(t, "content_like", items[0].TemplateId)
+	assert.Equal(t, "2026-10-19", items[0].Day)
+	assert.Equal(t, int64(10), items[0].Sent)
+	assert.Equal(t, int64(3), items[0].Opened)
+	assert.InDelta(t, 0.3, items[0].Ctr, 0.0001)
+	assert.Equal(t, Stats{Sent: 6, Opened: 3, Ctr: 0.5}, items[0].Platforms[common.DeviceTypeIos])
+	assert.Equal(t, Stats{Sent: 4}, items[0].Platforms[common.DeviceTypeAndroid])
+	assert.Equal(t, int64(10), items[0].Languages[translation.DefaultUserLanguage].Sent)
+
+	assert.Equal(t, float64(0), items[1].Ctr)
+	assert.Equal(t, "2026-10-20", items[2].Day)
+}
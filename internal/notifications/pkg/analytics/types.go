@@ -0,0 +1,43 @@
+package analytics
+
+import (
+	"time"
+
+	"github.com/digitalmonsters/go-common/common"
+	"github.com/digitalmonsters/go-common/translation"
+)
+
+type TemplateStatsRequest struct {
+	DateFrom    time.Time              `json:"date_from"`
+	DateTo      time.Time              `json:"date_to"`
+	TemplateIds []string               `json:"template_ids"`
+	Platforms   []common.DeviceType    `json:"platforms"`
+	Languages   []translation.Language `json:"languages"`
+}
+
+type TemplateStatsResponse struct {
+	Items []TemplateDayStats `json:"items"`
+}
+
+type Stats struct {
+	Sent   int64   `json:"sent"`
+	Opened int64   `json:"opened"`
+	Ctr    float64 `json:"ctr"`
+}
+
+type TemplateDayStats struct {
+	TemplateId string `json:"template_id"`
+	Day        string `json:"day"`
+	Stats
+	Platforms map[common.DeviceType]Stats    `json:"platforms"`
+	Languages map[translation.Language]Stats `json:"languages"`
+}
+
+type statsRecord struct {
+	TemplateId string
+	Day        time.Time
+	Platform   common.DeviceType
+	Language   translation.Language
+	Sent       int64
+	Opened     int64
+}
@@ -0,0 +1,216 @@
+package campaign
+
+import (
+	"strings"
+	"time"
+
+	"github.com/digitalmonsters/notification-handler/configs"
+	"github.com/digitalmonsters/notification-handler/pkg/database"
+	"github.com/pkg/errors"
+	"gopkg.in/guregu/null.v4"
+	"gorm.io/gorm"
+)
+
+type IService interface {
+	CreateCampaign(req CreateCampaignRequest, adminId int64, tx *gorm.DB) (*database.PushCampaign, error)
+	CancelCampaign(req CancelCampaignRequest, tx *gorm.DB) error
+	GetCampaign(req GetCampaignRequest, db *gorm.DB) (*CampaignProgress, error)
+	ListCampaigns(req ListCampaignsRequest, db *gorm.DB) (*ListCampaignsResponse, error)
+}
+
+type service struct {
+}
+
+func NewService() IService {
+	return &service{}
+}
+
+func (s service) CreateCampaign(req CreateCampaignRequest, adminId int64, tx *gorm.DB) (*database.PushCampaign, error) {
+	if len(strings.TrimSpace(req.Title)) == 0 || len(strings.TrimSpace(req.Body)) == 0 {
+		return nil, errors.New("title and body are required")
+	}
+
+	if req.RatePerSecond < 0 {
+		return nil, errors.New("rate_per_second should be positive")
+	}
+
+	if req.Segment.LastActiveFrom.Valid && req.Segment.LastActiveTo.Valid &&
+		req.Segment.LastActiveFrom.Time.After(req.Segment.LastActiveTo.Time) {
+		return nil, errors.New("last_active_from should be before last_active_to")
+	}
+
+	now := time.Now().UTC()
+
+	scheduledAt := now
+	if req.ScheduledAt.Valid && req.ScheduledAt.Time.After(now) {
+		scheduledAt = req.ScheduledAt.Time.UTC()
+	}
+
+	ratePerSecond := req.RatePerSecond
+	if ratePerSecond == 0 {
+		ratePerSecond = configs.PushCampaignDefaultRatePerSecond
+	}
+
+	campaign := database.PushCampaign{
+		Title:         req.Title,
+		Body:          req.Body,
+		ImageUrl:      req.ImageUrl,
+		CustomData:    req.CustomData,
+		Segment:       req.Segment,
+		ScheduledAt:   scheduledAt,
+		RatePerSecond: ratePerSecond,
+		Status:        database.PushCampaignStatusScheduled,
+		CreatedBy:     adminId,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+
+	if err := tx.Create(&campaign).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return &campaign, nil
+}
+
+func (s service) CancelCampaign(req CancelCampaignRequest, tx *gorm.DB) error {
+	now := time.Now().UTC()
+
+	res := tx.Model(&database.PushCampaign{}).
+		Where("id = ? and status in ?", req.Id, []database.PushCampaignStatus{database.PushCampaignStatusScheduled,
+			database.PushCampaignStatusRunning}).
+		Updates(map[string]interface{}{
+			"status":       database.PushCampaignStatusCancelled,
+			"cancelled_at": now,
+			"updated_at":   now,
+		})
+
+	if res.Error != nil {
+		return errors.WithStack(res.Error)
+	}
+
+	if res.RowsAffected == 0 {
+		return errors.New("campaign not found or already finished")
+	}
+
+	return nil
+}
+
+func (s service) GetCampaign(req GetCampaignRequest, db *gorm.DB) (*CampaignProgress, error) {
+	var campaigns []database.PushCampaign
+
+	if err := db.Where("id = ?", req.Id).Limit(1).Find(&campaigns).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if len(campaigns) == 0 {
+		return nil, errors.New("campaign not found")
+	}
+
+	items, err := s.fillProgress(campaigns, db)
+	if err != nil {
+		return nil, err
+	}
+
+	return &items[0], nil
+}
+
+func (s service) ListCampaigns(req ListCampaignsRequest, db *gorm.DB) (*ListCampaignsResponse, error) {
+	query := db.Model(&database.PushCampaign{})
+
+	if len(req.Status) > 0 {
+		query = query.Where("status in ?", req.Status)
+	}
+
+	if req.ScheduledAtFrom.Valid {
+		query = query.Where("scheduled_at >= ?", req.ScheduledAtFrom.Time)
+	}
+
+	if req.ScheduledAtTo.Valid {
+		query = query.Where("scheduled_at <= ?", req.ScheduledAtTo.Time)
+	}
+
+	var totalCount null.Int
+
+	if req.Offset == 0 {
+		var count int64
+
+		if err := query.Count(&count).Error; err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		totalCount = null.IntFrom(count)
+	}
+
+	var campaigns []database.PushCampaign
+
+	if req.Limit > 0 {
+		query = query.Limit(req.Limit)
+	}
+
+	if err := query.Order("id desc").Offset(req.Offset).Find(&campaigns).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	items, err := s.fillProgress(campaigns, db)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ListCampaignsResponse{
+		Items:      items,
+		TotalCount: totalCount,
+	}, nil
+}
+
+// fillProgress joins campaigns with opens tracked by the clients, campaign pushes are recorded in push_sends with campaign id
+func (s service) fillProgress(campaigns []database.PushCampaign, db *gorm.DB) ([]CampaignProgress, error) {
+	items := make([]CampaignProgress, 0, len(campaigns))
+
+	if len(campaigns) == 0 {
+		return items, nil
+	}
+
+	var ids []int64
+
+	for _, c := range campaigns {
+		if c.StartedAt.Valid {
+			ids = append(ids, c.Id)
+		}
+	}
+
+	opens := map[int64]int64{}
+
+	if len(ids) > 0 {
+		var records []struct {
+			CampaignId int64
+			Opened     int64
+		}
+
+		if err := db.Raw(`select s.campaign_id, count(distinct t.user_id) as opened
+			from track_fcm_notifications t
+			join push_sends s on s.id = t.notification_id and s.device_id = t.device_id
+			where s.campaign_id in ?
+			group by s.campaign_id`, ids).Scan(&records).Error; err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		for _, r := range records {
+			opens[r.CampaignId] = r.Opened
+		}
+	}
+
+	for _, c := range campaigns {
+		item := CampaignProgress{
+			PushCampaign: c,
+			OpenedCount:  opens[c.Id],
+		}
+
+		if c.SentCount > 0 {
+			item.OpenRate = float64(item.OpenedCount) / float64(c.SentCount)
+		}
+
+		items = append(items, item)
+	}
+
+	return items, nil
+}
@@ -0,0 +1,43 @@
+package campaign
+
+import (
+	"github.com/digitalmonsters/notification-handler/pkg/database"
+	"gopkg.in/guregu/null.v4"
+)
+
+type CreateCampaignRequest struct {
+	Title         string               `json:"title"`
+	Body          string               `json:"body"`
+	ImageUrl      string               `json:"image_url"`
+	CustomData    database.CustomData  `json:"custom_data"`
+	Segment       database.PushSegment `json:"segment"`
+	ScheduledAt   null.Time            `json:"scheduled_at"` // empty value means send now
+	RatePerSecond int                  `json:"rate_per_second"`
+}
+
+type CancelCampaignRequest struct {
+	Id int64 `json:"id"`
+}
+
+type GetCampaignRequest struct {
+	Id int64 `json:"id"`
+}
+
+type ListCampaignsRequest struct {
+	Status          []database.PushCampaignStatus `json:"status"`
+	ScheduledAtFrom null.Time                     `json:"scheduled_at_from"`
+	ScheduledAtTo   null.Time                     `json:"scheduled_at_to"`
+	Limit           int                           `json:"limit"`
+	Offset          int                           `json:"offset"`
+}
+
+type ListCampaignsResponse struct {
+	Items      []CampaignProgress `json:"items"`
+	TotalCount null.Int           `json:"total_count"`
+}
+
+type CampaignProgress struct {
+	database.PushCampaign
+	OpenedCount int64   `json:"opened_count"`
+	OpenRate    float64 `json:"open_rate"`
+}
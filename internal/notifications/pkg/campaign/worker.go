@@ -0,0 +1,418 @@
+package campaign
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/RichardKnop/machinery/v1"
+	"github.com/RichardKnop/machinery/v1/tasks"
+	"github.com/digitalmonsters/go-common/apm_helper"
+	"github.com/digitalmonsters/go-common/boilerplate"
+	"github.com/digitalmonsters/go-common/translation"
+	"github.com/digitalmonsters/go-common/wrappers/user_go"
+	"github.com/digitalmonsters/notification-handler/configs"
+	"github.com/digitalmonsters/notification-handler/pkg/database"
+	"github.com/digitalmonsters/notification-handler/pkg/sender"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"github.com/thoas/go-funk"
+	"gopkg.in/guregu/null.v4"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const pushAdminTemplate = "push_admin"
+
+// Worker delivers scheduled campaigns. Every minute due campaigns are switched to running and their audience is
+// processed by chained batch tasks ordered by user id, so a restarted task continues from the last processed user
+type Worker struct {
+	sender      sender.ISender
+	jobber      *machinery.Server
+	userWrapper user_go.IUserGoWrapper
+}
+
+func NewWorker(sender sender.ISender, jobber *machinery.Server, userWrapper user_go.IUserGoWrapper) *Worker {
+	return &Worker{
+		sender:      sender,
+		jobber:      jobber,
+		userWrapper: userWrapper,
+	}
+}
+
+func (w *Worker) RegisterTasks() error {
+	if err := w.jobber.RegisterTask(string(configs.GeneralPushCampaignTask), func() error {
+		apmTransaction := apm_helper.StartNewApmTransaction(string(configs.GeneralPushCampaignTask),
+			"push_campaign", nil, nil)
+
+		defer func() {
+			apmTransaction.End()
+		}()
+
+		ctx := boilerplate.CreateCustomContext(context.Background(), apmTransaction, log.Logger)
+
+		if err := w.StartDueCampaigns(time.Now().UTC(), ctx); err != nil {
+			apm_helper.LogError(errors.WithStack(err), ctx)
+			return errors.WithStack(err)
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	if err := w.jobber.RegisterTask(string(configs.PushCampaignBatchTask), func(campaignId int64, afterUserId int64) error {
+		apmTransaction := apm_helper.StartNewApmTransaction(string(configs.PushCampaignBatchTask),
+			"push_campaign", nil, nil)
+
+		defer func() {
+			apmTransaction.End()
+		}()
+
+		ctx := boilerplate.CreateCustomContext(context.Background(), apmTransaction, log.Logger)
+
+		apm_helper.AddApmLabel(apmTransaction, "campaign_id", campaignId)
+		apm_helper.AddApmLabel(apmTransaction, "after_user_id", afterUserId)
+
+		if err := w.ProcessBatch(campaignId, afterUserId, ctx); err != nil {
+			apm_helper.LogError(errors.WithStack(err), ctx)
+			return errors.WithStack(err)
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	if err := w.jobber.RegisterPeriodicTask(configs.PushCampaignJobCron,
+		string(configs.PeriodicPushCampaignTask), &tasks.Signature{
+			Name: string(configs.GeneralPushCampaignTask),
+		}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// StartDueCampaigns moves scheduled campaigns to running and enqueues their first batch
+func (w *Worker) StartDueCampaigns(currentDate time.Time, ctx context.Context) error {
+	db := database.GetDbWithContext(database.DbTypeMaster, ctx)
+
+	var ids []int64
+
+	if err := db.Model(&database.PushCampaign{}).Where("status = ? and scheduled_at <= ?",
+		database.PushCampaignStatusScheduled, currentDate).Order("scheduled_at").Pluck("id", &ids).Error; err != nil {
+		return errors.WithStack(err)
+	}
+
+	for _, id := range ids {
+		res := db.Model(&database.PushCampaign{}).Where("id = ? and status = ?", id, database.PushCampaignStatusScheduled).
+			Updates(map[string]interface{}{
+				"status":     database.PushCampaignStatusRunning,
+				"started_at": currentDate,
+				"updated_at": currentDate,
+			})
+
+		if res.Error != nil {
+			return errors.WithStack(res.Error)
+		}
+
+		if res.RowsAffected == 0 { // cancelled or already taken by another worker
+			continue
+		}
+
+		if err := w.enqueueBatch(id, 0); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ProcessBatch sends campaign push to the next batch of users and enqueues the following batch
+func (w *Worker) ProcessBatch(campaignId int64, afterUserId int64, ctx context.Context) error {
+	db := database.GetDbWithContext(database.DbTypeMaster, ctx)
+
+	campaign, err := getCampaign(campaignId, db)
+	if err != nil {
+		return err
+	}
+
+	if campaign.Status != database.PushCampaignStatusRunning {
+		log.Ctx(ctx).Info().Int64("campaign_id", campaignId).Str("status", string(campaign.Status)).
+			Msg("[PushCampaign] campaign is not running, stopping")
+		return nil
+	}
+
+	userIds, err := getCandidateUserIds(campaign.Segment, afterUserId, db)
+	if err != nil {
+		return err
+	}
+
+	if len(userIds) == 0 {
+		return completeCampaign(campaignId, db)
+	}
+
+	users, err := w.filterUsers(campaign.Segment, userIds, ctx)
+	if err != nil {
+		return err
+	}
+
+	var delay time.Duration
+	if campaign.RatePerSecond > 0 {
+		delay = time.Second / time.Duration(campaign.RatePerSecond)
+	}
+
+	for i, userId := range userIds {
+		user, ok := users[userId]
+		if !ok {
+			continue
+		}
+
+		if i > 0 && i%50 == 0 {
+			if campaign, err = getCampaign(campaignId, db); err != nil {
+				return err
+			}
+
+			if campaign.Status != database.PushCampaignStatusRunning {
+				break
+			}
+		}
+
+		shouldSend, err := createRecipient(campaignId, userId, db)
+		if err != nil {
+			return err
+		}
+
+		if !shouldSend { // already processed by a previous attempt of this batch
+			continue
+		}
+
+		status := database.PushCampaignRecipientStatusSent
+		var sendErr null.String
+
+		if _, err := w.sender.PushNotification(buildNotification(*campaign, userId), campaign.ImageUrl, campaign.Id, 0,
+			pushAdminTemplate, user.Language, "", campaign.Segment.Platforms, ctx); err != nil {
+			log.Ctx(ctx).Err(err).Int64("campaign_id", campaignId).Int64("user_id", userId).Send()
+
+			status = database.PushCampaignRecipientStatusFailed
+			sendErr = null.StringFrom(err.Error())
+		}
+
+		if err := finishRecipient(campaignId, userId, status, sendErr, db); err != nil {
+			return err
+		}
+
+		if delay > 0 {
+			time.Sleep(delay)
+		}
+	}
+
+	if campaign.Status != database.PushCampaignStatusRunning {
+		return nil
+	}
+
+	if len(userIds) < configs.PushCampaignBatchSize {
+		return completeCampaign(campaignId, db)
+	}
+
+	return w.enqueueBatch(campaignId, userIds[len(userIds)-1])
+}
+
+func (w *Worker) filterUsers(segment database.PushSegment, userIds []int64,
+	ctx context.Context) (map[int64]user_go.UserDetailRecord, error) {
+	resp := <-w.userWrapper.GetUsersDetails(userIds, ctx, false)
+
+	if resp.Error != nil {
+		return nil, errors.Wrap(resp.Error.ToError(), "can not get users details")
+	}
+
+	result := make(map[int64]user_go.UserDetailRecord, len(resp.Response))
+
+	for userId, user := range resp.Response {
+		if user.Deleted || user.Guest {
+			continue
+		}
+
+		language := user.Language
+		if len(language) == 0 {
+			language = translation.DefaultUserLanguage
+		}
+
+		if len(segment.CountryCodes) > 0 && !funk.ContainsString(segment.CountryCodes, user.CountryCode) {
+			continue
+		}
+
+		if len(segment.Languages) > 0 && !funk.Contains(segment.Languages, language) {
+			continue
+		}
+
+		if len(segment.KycStatuses) > 0 && !funk.ContainsString(segment.KycStatuses, user.KycStatus) {
+			continue
+		}
+
+		user.Language = language
+		result[userId] = user
+	}
+
+	return result, nil
+}
+
+func (w *Worker) enqueueBatch(campaignId int64, afterUserId int64) error {
+	if _, err := w.jobber.SendTask(&tasks.Signature{
+		Name: string(configs.PushCampaignBatchTask),
+		Args: []tasks.Arg{
+			{
+				Name:  "campaignId",
+				Type:  "int64",
+				Value: campaignId,
+			},
+			{
+				Name:  "afterUserId",
+				Type:  "int64",
+				Value: afterUserId,
+			},
+		},
+	}); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return nil
+}
+
+func buildNotification(campaign database.PushCampaign, userId int64) database.Notification {
+	customData := make(database.CustomData, len(campaign.CustomData)+1)
+
+	for k, v := range campaign.CustomData {
+		customData[k] = v
+	}
+
+	customData["campaign_id"] = fmt.Sprint(campaign.Id)
+
+	return database.Notification{
+		UserId:     userId,
+		Type:       database.GetNotificationTypeForAll(pushAdminTemplate),
+		Title:      campaign.Title,
+		Message:    campaign.Body,
+		CustomData: customData,
+	}
+}
+
+func getCampaign(campaignId int64, db *gorm.DB) (*database.PushCampaign, error) {
+	var campaign database.PushCampaign
+
+	if err := db.Where("id = ?", campaignId).Take(&campaign).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return &campaign, nil
+}
+
+// getCandidateUserIds returns next users with a registered device, platform and activity filters are applied here,
+// the rest of the segment requires user details and is applied by filterUsers
+func getCandidateUserIds(segment database.PushSegment, afterUserId int64, db *gorm.DB) ([]int64, error) {
+	query := db.Model(&database.Device{}).Where("\"userId\" > ? and \"deletedAt\" is null", afterUserId)
+
+	if len(segment.Platforms) > 0 {
+		query = query.Where("platform in ?", segment.Platforms)
+	}
+
+	if segment.LastActiveFrom.Valid {
+		query = query.Where("last_seen_at >= ?", segment.LastActiveFrom.Time)
+	}
+
+	if segment.LastActiveTo.Valid {
+		query = query.Where("last_seen_at <= ?", segment.LastActiveTo.Time)
+	}
+
+	var userIds []int64
+
+	if err := query.Distinct("\"userId\"").Order("\"userId\"").Limit(configs.PushCampaignBatchSize).
+		Pluck("userId", &userIds).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return userIds, nil
+}
+
+// createRecipient saves pending recipient and counts it as targeted in one transaction. It returns false when a
+// previous attempt of the batch has already sent the push, recipients left pending by a crashed attempt are sent again
+func createRecipient(campaignId int64, userId int64, db *gorm.DB) (bool, error) {
+	var recipient database.PushCampaignRecipient
+
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&database.PushCampaignRecipient{
+			CampaignId: campaignId,
+			UserId:     userId,
+			Status:     database.PushCampaignRecipientStatusPending,
+			CreatedAt:  time.Now().UTC(),
+		})
+
+		if res.Error != nil {
+			return errors.WithStack(res.Error)
+		}
+
+		if res.RowsAffected == 0 {
+			return errors.WithStack(tx.Where("campaign_id = ? and user_id = ?", campaignId, userId).Take(&recipient).Error)
+		}
+
+		recipient.Status = database.PushCampaignRecipientStatusPending
+
+		return incrementCampaignCounter(campaignId, "targeted_count", tx)
+	}); err != nil {
+		return false, err
+	}
+
+	return recipient.Status == database.PushCampaignRecipientStatusPending, nil
+}
+
+// finishRecipient saves result of the push and counts it in one transaction, recipient is counted only once
+func finishRecipient(campaignId int64, userId int64, status database.PushCampaignRecipientStatus, sendErr null.String,
+	db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&database.PushCampaignRecipient{}).Where("campaign_id = ? and user_id = ? and status = ?",
+			campaignId, userId, database.PushCampaignRecipientStatusPending).
+			Updates(map[string]interface{}{"status": status, "error": sendErr})
+
+		if res.Error != nil {
+			return errors.WithStack(res.Error)
+		}
+
+		if res.RowsAffected == 0 { // finished by a concurrent attempt
+			return nil
+		}
+
+		counter := "sent_count"
+		if status == database.PushCampaignRecipientStatusFailed {
+			counter = "failed_count"
+		}
+
+		return incrementCampaignCounter(campaignId, counter, tx)
+	})
+}
+
+func incrementCampaignCounter(campaignId int64, column string, tx *gorm.DB) error {
+	if err := tx.Model(&database.PushCampaign{}).Where("id = ?", campaignId).Updates(map[string]interface{}{
+		column:       gorm.Expr(fmt.Sprintf("%v + 1", column)),
+		"updated_at": time.Now().UTC(),
+	}).Error; err != nil {
+		return errors.WithStack(err)
+	}
+
+	return nil
+}
+
+func completeCampaign(campaignId int64, db *gorm.DB) error {
+	now := time.Now().UTC()
+
+	if err := db.Model(&database.PushCampaign{}).Where("id = ? and status = ?", campaignId, database.PushCampaignStatusRunning).
+		Updates(map[string]interface{}{
+			"status":      database.PushCampaignStatusCompleted,
+			"finished_at": now,
+			"updated_at":  now,
+		}).Error; err != nil {
+		return errors.WithStack(err)
+	}
+
+	return nil
+}
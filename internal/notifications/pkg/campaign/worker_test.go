@@ -0,0 +1,227 @@
+package campaign
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/digitalmonsters/go-common/boilerplate_testing"
+	"github.com/digitalmonsters/go-common/common"
+	"github.com/digitalmonsters/go-common/translation"
+	"github.com/digitalmonsters/go-common/wrappers"
+	"github.com/digitalmonsters/go-common/wrappers/notification_gateway"
+	"github.com/digitalmonsters/go-common/wrappers/user_go"
+	"github.com/digitalmonsters/notification-handler/configs"
+	"github.com/digitalmonsters/notification-handler/pkg/database"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/guregu/null.v4"
+	"gorm.io/gorm"
+)
+
+var config configs.Settings
+var gormDb *gorm.DB
+
+func TestMain(m *testing.M) {
+	config = configs.GetConfig()
+	gormDb = database.GetDb(database.DbTypeMaster)
+	os.Exit(m.Run())
+}
+
+// senderMock records users who got the campaign push, push to failUserId returns error
+type senderMock struct {
+	sentTo     []int64
+	failUserId int64
+}
+
+func (s *senderMock) SendEmail(msg []notification_gateway.SendEmailMessageRequest, ctx context.Context) error {
+	return nil
+}
+
+func (s *senderMock) PushNotification(notification database.Notification, imageUrl string, entityId int64,
+	relatedEntityId int64, templateName string, language translation.Language, customKind string,
+	platforms []common.DeviceType, ctx context.Context) (bool, error) {
+	s.sentTo = append(s.sentTo, notification.UserId)
+
+	if notification.UserId == s.failUserId {
+		return false, errors.New("push failed")
+	}
+
+	return false, nil
+}
+
+func (s *senderMock) UnapplyEvent(userId int64, eventType string, entityId int64, relatedEntityId int64,
+	ctx context.Context) error {
+	return nil
+}
+
+func newUserWrapperMock(users map[int64]user_go.UserDetailRecord) *user_go.UserGoWrapperMock {
+	return &user_go.UserGoWrapperMock{
+		GetUsersDetailFn: func(userIds []int64, ctx context.Context, forceLog bool) chan wrappers.GenericResponseChan[map[int64]user_go.UserDetailRecord] {
+			ch := make(chan wrappers.GenericResponseChan[map[int64]user_go.UserDetailRecord], 1)
+
+			resp := map[int64]user_go.UserDetailRecord{}
+			for _, userId := range userIds {
+				if user, ok := users[userId]; ok {
+					resp[userId] = user
+				}
+			}
+
+			ch <- wrappers.GenericResponseChan[map[int64]user_go.UserDetailRecord]{Response: resp}
+
+			return ch
+		},
+	}
+}
+
+func flushCampaigns(t *testing.T) {
+	if err := boilerplate_testing.FlushPostgresTables(config.MasterDb, []string{"public.push_campaigns",
+		"public.push_campaign_recipients", "public.devices"}, nil, t); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func createDevice(t *testing.T, userId int64, platform common.DeviceType, lastSeenAt time.Time) {
+	if err := gormDb.Create(&database.Device{
+		UserId:     userId,
+		DeviceId:   "device",
+		PushToken:  fmt.Sprintf("token_%v", userId),
+		Platform:   platform,
+		UpdatedAt:  lastSeenAt,
+		LastSeenAt: lastSeenAt,
+	}).Error; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func createRunningCampaign(t *testing.T, campaign database.PushCampaign) database.PushCampaign {
+	campaign.Title = "title"
+	campaign.Body = "body"
+	campaign.Status = database.PushCampaignStatusRunning
+	campaign.ScheduledAt = time.Now().UTC()
+
+	if err := gormDb.Create(&campaign).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	return campaign
+}
+
+func getRecipients(t *testing.T, campaignId int64) map[int64]database.PushCampaignRecipientStatus {
+	var recipients []database.PushCampaignRecipient
+	if err := gormDb.Where("campaign_id = ?", campaignId).Find(&recipients).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	result := map[int64]database.PushCampaignRecipientStatus{}
+	for _, recipient := range recipients {
+		result[recipient.UserId] = recipient.Status
+	}
+
+	return result
+}
+
+func TestWorker_ProcessBatch_Segment(t *testing.T) {
+	flushCampaigns(t)
+
+	now := time.Now().UTC()
+
+	createDevice(t, 1, common.DeviceTypeIos, now)
+	createDevice(t, 2, common.DeviceTypeAndroid, now)                // other platform
+	createDevice(t, 3, common.DeviceTypeIos, now)                    // other country
+	createDevice(t, 4, common.DeviceTypeIos, now)                    // deleted user
+	createDevice(t, 5, common.DeviceTypeIos, now.AddDate(0, 0, -10)) // not active
+	createDevice(t, 6, common.DeviceTypeIos, now)                    // push fails
+
+	users := map[int64]user_go.UserDetailRecord{
+		1: {Id: 1, CountryCode: "US"},
+		2: {Id: 2, CountryCode: "US"},
+		3: {Id: 3, CountryCode: "DE"},
+		4: {Id: 4, CountryCode: "US", Deleted: true},
+		5: {Id: 5, CountryCode: "US"},
+		6: {Id: 6, CountryCode: "US"},
+	}
+
+	campaign := createRunningCampaign(t, database.PushCampaign{
+		Segment: database.PushSegment{
+			CountryCodes:   []string{"US"},
+			Platforms:      []common.DeviceType{common.DeviceTypeIos},
+			LastActiveFrom: null.TimeFrom(now.Add(-24 * time.Hour)),
+		},
+	})
+
+	sender := &senderMock{failUserId: 6}
+	worker := NewWorker(sender, nil, newUserWrapperMock(users))
+
+	if err := worker.ProcessBatch(campaign.Id, 0, context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.ElementsMatch(t, []int64{1, 6}, sender.sentTo)
+	assert.Equal(t, map[int64]database.PushCampaignRecipientStatus{
+		1: database.PushCampaignRecipientStatusSent,
+		6: database.PushCampaignRecipientStatusFailed,
+	}, getRecipients(t, campaign.Id))
+
+	updated, err := getCampaign(campaign.Id, gormDb)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, database.PushCampaignStatusCompleted, updated.Status)
+	assert.Equal(t, int64(2), updated.TargetedCount)
+	assert.Equal(t, int64(1), updated.SentCount)
+	assert.Equal(t, int64(1), updated.FailedCount)
+}
+
+func TestWorker_ProcessBatch_RetryPartialBatch(t *testing.T) {
+	flushCampaigns(t)
+
+	now := time.Now().UTC()
+
+	for userId := int64(1); userId <= 3; userId++ {
+		createDevice(t, userId, common.DeviceTypeAndroid, now)
+	}
+
+	// previous attempt sent push to user 1 and crashed after user 2 was saved as pending
+	campaign := createRunningCampaign(t, database.PushCampaign{
+		TargetedCount: 2,
+		SentCount:     1,
+	})
+
+	if err := gormDb.Create(&[]database.PushCampaignRecipient{
+		{CampaignId: campaign.Id, UserId: 1, Status: database.PushCampaignRecipientStatusSent, CreatedAt: now},
+		{CampaignId: campaign.Id, UserId: 2, Status: database.PushCampaignRecipientStatusPending, CreatedAt: now},
+	}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	sender := &senderMock{}
+	worker := NewWorker(sender, nil, newUserWrapperMock(map[int64]user_go.UserDetailRecord{
+		1: {Id: 1},
+		2: {Id: 2},
+		3: {Id: 3},
+	}))
+
+	if err := worker.ProcessBatch(campaign.Id, 0, context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, []int64{2, 3}, sender.sentTo)
+	assert.Equal(t, map[int64]database.PushCampaignRecipientStatus{
+		1: database.PushCampaignRecipientStatusSent,
+		2: database.PushCampaignRecipientStatusSent,
+		3: database.PushCampaignRecipientStatusSent,
+	}, getRecipients(t, campaign.Id))
+
+	updated, err := getCampaign(campaign.Id, gormDb)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, int64(3), updated.TargetedCount)
+	assert.Equal(t, int64(3), updated.SentCount)
+	assert.Equal(t, int64(0), updated.FailedCount)
+}
@@ -0,0 +1,23 @@
+package database
+
+import (
+	"time"
+
+	"gopkg.in/guregu/null.v4"
+)
+
+// AdminQueryLog is an audit record of a named admin query run, including denied and failed runs
+type AdminQueryLog struct {
+	Id         int64       `json:"id" gorm:"primaryKey;autoIncrement"`
+	AdminId    int64       `json:"admin_id"`
+	QueryName  string      `json:"query_name"`
+	Params     string      `json:"params" gorm:"type:jsonb"`
+	RowCount   int         `json:"row_count"`
+	DurationMs int64       `json:"duration_ms"`
+	Error      null.String `json:"error"`
+	CreatedAt  time.Time   `json:"created_at"`
+}
+
+func (AdminQueryLog) TableName() string {
+	return "admin_query_logs"
+}
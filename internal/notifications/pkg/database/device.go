@@ -1,16 +1,20 @@
 package database
 
 import (
+	"time"
+
 	"github.com/digitalmonsters/go-common/common"
 	"github.com/google/uuid"
 )
 
 type Device struct {
-	Id        uuid.UUID         `gorm:"primaryKey;autoIncrement"`
-	UserId    int64             `gorm:"column:userId"`
-	DeviceId  string            `gorm:"column:deviceId"`
-	PushToken string            `gorm:"column:pushToken"`
-	Platform  common.DeviceType `json:"platform"`
+	Id         uuid.UUID         `gorm:"primaryKey;autoIncrement"`
+	UserId     int64             `gorm:"column:userId"`
+	DeviceId   string            `gorm:"column:deviceId"`
+	PushToken  string            `gorm:"column:pushToken"`
+	Platform   common.DeviceType `json:"platform"`
+	UpdatedAt  time.Time         `gorm:"column:updatedAt"`
+	LastSeenAt time.Time         `gorm:"column:last_seen_at"`
 }
 
 func (Device) TableName() string {
@@ -635,5 +635,286 @@ func getMigrations() []*gormigrate.Migration {
 				`)
 			},
 		},
+		{
+			ID: "render_template_texts_191020261200",
+			Migrate: func(db *gorm.DB) error {
+				return boilerplate_testing.ExecutePostgresSql(db, `
+					CREATE TABLE IF NOT EXISTS public.render_template_texts (
+						id bigserial NOT NULL,
+						template_id text NOT NULL,
+						language varchar(16) NOT NULL,
+						version int4 NOT NULL,
+						title text NULL,
+						body text NULL,
+						headline text NULL,
+						title_multiple text NULL,
+						body_multiple text NULL,
+						headline_multiple text NULL,
+						created_by int8 NOT NULL DEFAULT 0,
+						created_at timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+						CONSTRAINT render_template_texts_pkey PRIMARY KEY (id),
+						CONSTRAINT render_template_texts_template_fk FOREIGN KEY (template_id) REFERENCES public.render_templates (id) ON DELETE CASCADE
+					);
+
+					CREATE UNIQUE INDEX IF NOT EXISTS render_template_texts_version_uindex ON public.render_template_texts USING btree (template_id, language, version);
+				`)
+			},
+		},
+		{
+			ID: "push_campaigns_191020261300",
+			Migrate: func(db *gorm.DB) error {
+				return boilerplate_testing.ExecutePostgresSql(db, `
+					CREATE TABLE IF NOT EXISTS public.push_campaigns (
+						id bigserial NOT NULL,
+						title text NOT NULL,
+						body text NOT NULL,
+						image_url text NOT NULL DEFAULT '',
+						custom_data jsonb NULL,
+						segment jsonb NULL,
+						scheduled_at timestamptz NOT NULL,
+						rate_per_second int4 NOT NULL DEFAULT 0,
+						status varchar(32) NOT NULL,
+						targeted_count int8 NOT NULL DEFAULT 0,
+						sent_count int8 NOT NULL DEFAULT 0,
+						failed_count int8 NOT NULL DEFAULT 0,
+						created_by int8 NOT NULL DEFAULT 0,
+						created_at timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+						updated_at timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+						started_at timestamptz NULL,
+						finished_at timestamptz NULL,
+						cancelled_at timestamptz NULL,
+						CONSTRAINT push_campaigns_pkey PRIMARY KEY (id)
+					);
+
+					CREATE INDEX IF NOT EXISTS push_campaigns_status_scheduled_at_idx ON public.push_campaigns USING btree (status, scheduled_at);
+
+					CREATE TABLE IF NOT EXISTS public.push_campaign_recipients (
+						campaign_id int8 NOT NULL,
+						user_id int8 NOT NULL,
+						status varchar(32) NOT NULL,
+						error text NULL,
+						created_at timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+						CONSTRAINT push_campaign_recipients_pkey PRIMARY KEY (campaign_id, user_id),
+						CONSTRAINT push_campaign_recipients_campaign_fk FOREIGN KEY (campaign_id) REFERENCES public.push_campaigns (id) ON DELETE CASCADE
+					);
+
+					CREATE INDEX IF NOT EXISTS devices_user_last_active_idx ON public.devices USING btree ("userId", coalesce("updatedAt", "createdAt"));
+				`)
+			},
+		},
+		{
+			ID: "push_sends_191020261400",
+			Migrate: func(db *gorm.DB) error {
+				return boilerplate_testing.ExecutePostgresSql(db, `
+					CREATE SEQUENCE IF NOT EXISTS public.push_sends_id_seq;
+
+					CREATE TABLE IF NOT EXISTS public.push_sends (
+						id int8 NOT NULL,
+						device_id varchar(255) NOT NULL,
+						user_id int8 NOT NULL,
+						template_id varchar(255) NOT NULL,
+						kind varchar(255) NOT NULL DEFAULT '',
+						platform varchar(32) NOT NULL DEFAULT '',
+						language varchar(32) NOT NULL DEFAULT '',
+						campaign_id int8 NULL,
+						created_at timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+						CONSTRAINT push_sends_pkey PRIMARY KEY (id, device_id)
+					);
+
+					CREATE INDEX IF NOT EXISTS push_sends_template_created_at_idx ON public.push_sends USING btree (created_at, template_id);
+					CREATE INDEX IF NOT EXISTS push_sends_campaign_idx ON public.push_sends USING btree (campaign_id) WHERE campaign_id IS NOT NULL;
+				`)
+			},
+		},
+		{
+			ID: "devices_last_seen_at_191020261500",
+			Migrate: func(db *gorm.DB) error {
+				return boilerplate_testing.ExecutePostgresSql(db, `
+					ALTER TABLE public.devices ADD COLUMN IF NOT EXISTS last_seen_at timestamptz NULL;
+					UPDATE public.devices SET last_seen_at = coalesce("updatedAt", "createdAt") WHERE last_seen_at IS NULL;
+					ALTER TABLE public.devices ALTER COLUMN last_seen_at SET DEFAULT CURRENT_TIMESTAMP;
+					ALTER TABLE public.devices ALTER COLUMN last_seen_at SET NOT NULL;
+
+					DELETE FROM public.devices T1
+						USING public.devices T2
+					WHERE T1."pushToken" = T2."pushToken"
+						AND T1."pushToken" <> ''
+						AND (coalesce(T1."updatedAt", T1."createdAt"), T1.ctid) < (coalesce(T2."updatedAt", T2."createdAt"), T2.ctid);
+
+					CREATE UNIQUE INDEX IF NOT EXISTS devices_pushtoken_uindex ON public.devices USING btree ("pushToken")
+						WHERE "pushToken" <> '';
+					CREATE INDEX IF NOT EXISTS devices_last_seen_at_idx ON public.devices USING btree (last_seen_at);
+				`)
+			},
+		},
+		{
+			ID: "notification_storage_191020261600",
+			Migrate: func(db *gorm.DB) error {
+				return boilerplate_testing.ExecutePostgresSql(db, `
+					CREATE TABLE IF NOT EXISTS public.notification_feed (
+						user_id int8 NOT NULL,
+						event_type varchar(255) NOT NULL,
+						created_at timestamptz NOT NULL,
+						entity_id int8 NOT NULL,
+						related_entity_id int8 NOT NULL,
+						notifications_count int8 NOT NULL DEFAULT 0,
+						title text NOT NULL DEFAULT '',
+						body text NOT NULL DEFAULT '',
+						headline text NOT NULL DEFAULT '',
+						kind varchar(255) NOT NULL DEFAULT '',
+						rendering_variables text NOT NULL DEFAULT '',
+						custom_data text NOT NULL DEFAULT '',
+						notification_info text NOT NULL DEFAULT '',
+						expires_at timestamptz NOT NULL,
+						CONSTRAINT notification_feed_pkey PRIMARY KEY (user_id, event_type, created_at, entity_id, related_entity_id)
+					);
+
+					CREATE INDEX IF NOT EXISTS notification_feed_user_created_at_idx ON public.notification_feed USING btree (user_id, created_at DESC, event_type, entity_id, related_entity_id);
+					CREATE INDEX IF NOT EXISTS notification_feed_expires_at_idx ON public.notification_feed USING btree (expires_at);
+
+					CREATE TABLE IF NOT EXISTS public.notification_relations (
+						user_id int8 NOT NULL,
+						event_type varchar(255) NOT NULL,
+						entity_id int8 NOT NULL,
+						related_entity_id int8 NOT NULL,
+						event_applied bool NOT NULL DEFAULT false,
+						expires_at timestamptz NOT NULL,
+						CONSTRAINT notification_relations_pkey PRIMARY KEY (user_id, event_type, entity_id, related_entity_id)
+					);
+
+					CREATE INDEX IF NOT EXISTS notification_relations_expires_at_idx ON public.notification_relations USING btree (expires_at);
+
+					CREATE TABLE IF NOT EXISTS public.push_notification_group_queue (
+						deadline_key timestamptz NOT NULL,
+						deadline timestamptz NOT NULL,
+						user_id int8 NOT NULL,
+						event_type varchar(255) NOT NULL,
+						entity_id int8 NOT NULL,
+						created_at timestamptz NOT NULL,
+						notification_count int8 NOT NULL DEFAULT 0,
+						expires_at timestamptz NOT NULL,
+						CONSTRAINT push_notification_group_queue_pkey PRIMARY KEY (deadline_key, deadline, user_id, event_type, entity_id)
+					);
+
+					CREATE INDEX IF NOT EXISTS push_notification_group_queue_expires_at_idx ON public.push_notification_group_queue USING btree (expires_at);
+
+					CREATE TABLE IF NOT EXISTS public.user_notifications_settings (
+						user_id int8 NOT NULL,
+						template_id varchar(255) NOT NULL,
+						muted bool NOT NULL DEFAULT false,
+						CONSTRAINT user_notifications_settings_pkey PRIMARY KEY (user_id, template_id)
+					);
+
+					CREATE TABLE IF NOT EXISTS public.user_notifications_read (
+						user_id int8 NOT NULL,
+						notification_id int8 NOT NULL,
+						CONSTRAINT user_notifications_read_pkey PRIMARY KEY (user_id, notification_id)
+					);
+
+					CREATE TABLE IF NOT EXISTS public.user_notifications_read_counter (
+						notification_id int8 NOT NULL,
+						read_count int8 NOT NULL DEFAULT 0,
+						CONSTRAINT user_notifications_read_counter_pkey PRIMARY KEY (notification_id)
+					);
+
+					CREATE TABLE IF NOT EXISTS public.notification_users (
+						user_id int8 NOT NULL,
+						username varchar(255) NOT NULL DEFAULT '',
+						firstname varchar(255) NOT NULL DEFAULT '',
+						lastname varchar(255) NOT NULL DEFAULT '',
+						name_privacy_status int4 NOT NULL DEFAULT 0,
+						language varchar(32) NOT NULL DEFAULT '',
+						email varchar(255) NOT NULL DEFAULT '',
+						expires_at timestamptz NOT NULL,
+						CONSTRAINT notification_users_pkey PRIMARY KEY (user_id)
+					);
+
+					CREATE INDEX IF NOT EXISTS notification_users_expires_at_idx ON public.notification_users USING btree (expires_at);
+
+					CREATE TABLE IF NOT EXISTS public.storage_backfill_progress (
+						table_name varchar(255) NOT NULL,
+						page_state bytea NULL,
+						copied_count int8 NOT NULL DEFAULT 0,
+						completed_at timestamptz NULL,
+						updated_at timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+						CONSTRAINT storage_backfill_progress_pkey PRIMARY KEY (table_name)
+					);
+				`)
+			},
+		},
+		{
+			ID: "notification_preferences_191020261700",
+			Migrate: func(db *gorm.DB) error {
+				return boilerplate_testing.ExecutePostgresSql(db, `
+					ALTER TABLE public.render_templates ADD COLUMN IF NOT EXISTS category varchar(64) NOT NULL DEFAULT 'system';
+					ALTER TABLE public.render_templates ADD COLUMN IF NOT EXISTS default_push bool NOT NULL DEFAULT true;
+					ALTER TABLE public.render_templates ADD COLUMN IF NOT EXISTS default_in_app bool NOT NULL DEFAULT true;
+
+					UPDATE public.render_templates SET category = 'comments' WHERE id LIKE 'comment#_%' ESCAPE '#';
+					UPDATE public.render_templates SET category = 'social' WHERE id IN ('follow', 'tip', 'referral_greeting');
+					UPDATE public.render_templates SET category = 'content'
+					WHERE id IN ('content_like', 'content_posted', 'content_reject', 'content_upload', 'spot_upload',
+						'spot_upload_cat', 'spot_upload_dog', 'first_boring_spots', 'last_boring_spots', 'max_boring_spots',
+						'warning_boring_spots');
+					UPDATE public.render_templates SET category = 'creator'
+					WHERE id LIKE '%creator#_status#_%' ESCAPE '#' OR id LIKE 'kyc#_status#_%' ESCAPE '#'
+						OR id LIKE 'ads#_campaign#_%' ESCAPE '#';
+					UPDATE public.render_templates SET category = 'rewards'
+					WHERE category = 'system' AND (id LIKE '%bonus%' OR id LIKE 'first#_%' ESCAPE '#'
+						OR id LIKE 'increase#_reward#_%' ESCAPE '#' OR id LIKE '%referrals#_joined' ESCAPE '#'
+						OR id LIKE 'daily#_max#_%' ESCAPE '#' OR id LIKE 'guest#_max#_%' ESCAPE '#');
+					UPDATE public.render_templates SET category = 'marketing'
+					WHERE id LIKE 'guest#_after#_install#_%' ESCAPE '#' OR id LIKE 'user#_after#_signup#_%' ESCAPE '#'
+						OR id LIKE 'user#_need#_to#_%' ESCAPE '#';
+					UPDATE public.render_templates SET category = 'account' WHERE id LIKE 'banned#_%' ESCAPE '#' OR id = 'user_banned';
+
+					CREATE TABLE IF NOT EXISTS public.user_notification_preferences (
+						user_id int8 NOT NULL,
+						channel varchar(32) NOT NULL,
+						scope varchar(32) NOT NULL,
+						scope_key varchar(255) NOT NULL,
+						muted bool NOT NULL DEFAULT false,
+						updated_at timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+						CONSTRAINT user_notification_preferences_pkey PRIMARY KEY (user_id, channel, scope, scope_key)
+					);
+				`)
+			},
+		},
+		{
+			ID: "admin_query_logs_191020261800",
+			Migrate: func(db *gorm.DB) error {
+				return boilerplate_testing.ExecutePostgresSql(db, `
+					CREATE TABLE IF NOT EXISTS public.admin_query_logs (
+						id bigserial NOT NULL,
+						admin_id int8 NOT NULL,
+						query_name varchar(255) NOT NULL,
+						params jsonb NOT NULL DEFAULT '{}'::jsonb,
+						row_count int4 NOT NULL DEFAULT 0,
+						duration_ms int8 NOT NULL DEFAULT 0,
+						error text NULL,
+						created_at timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+						CONSTRAINT admin_query_logs_pkey PRIMARY KEY (id)
+					);
+
+					CREATE INDEX IF NOT EXISTS admin_query_logs_admin_id_created_at_idx ON public.admin_query_logs USING btree (admin_id, created_at);
+					CREATE INDEX IF NOT EXISTS admin_query_logs_created_at_idx ON public.admin_query_logs USING btree (created_at);
+				`)
+			},
+		},
+		{
+			ID: "music_song_moderation_templates_191020261900",
+			Migrate: func(db *gorm.DB) error {
+				return boilerplate_testing.ExecutePostgresSql(db, `
+					INSERT INTO public.render_templates (id, title, body, kind, category, created_at, updated_at)
+					VALUES ('music_song_approved', 'Your song is live',
+						'Your song {{.song_name}} has been approved and is now available to listeners',
+						'music_creator', 'creator', now(), now()),
+						('music_song_rejected', 'Your song was not approved',
+						'Your song {{.song_name}} was rejected: {{.reject_reason}}',
+						'music_creator', 'creator', now(), now())
+					ON CONFLICT DO NOTHING;
+				`)
+			},
+		},
 	}
 }
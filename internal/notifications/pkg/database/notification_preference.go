@@ -0,0 +1,23 @@
+package database
+
+import "time"
+
+const (
+	PreferenceScopeCategory = "category"
+	PreferenceScopeTemplate = "template"
+)
+
+// UserNotificationPreference is a user override of a channel for a whole category or a single template.
+// Push overrides of templates are kept in user_notifications_settings
+type UserNotificationPreference struct {
+	UserId    int64  `gorm:"primaryKey"`
+	Channel   string `gorm:"primaryKey"`
+	Scope     string `gorm:"primaryKey"`
+	ScopeKey  string `gorm:"primaryKey"`
+	Muted     bool
+	UpdatedAt time.Time
+}
+
+func (UserNotificationPreference) TableName() string {
+	return "user_notification_preferences"
+}
@@ -0,0 +1,118 @@
+package database
+
+import (
+	"time"
+
+	"github.com/digitalmonsters/go-common/translation"
+	"github.com/digitalmonsters/go-common/wrappers/user_go"
+	"gopkg.in/guregu/null.v4"
+)
+
+// Postgres counterparts of scylla tables. ExpiresAt replaces scylla default_time_to_live, expired rows are ignored
+// on read and removed by storage cleanup task
+
+type NotificationFeedItem struct {
+	UserId             int64     `gorm:"primaryKey"`
+	EventType          string    `gorm:"primaryKey"`
+	CreatedAt          time.Time `gorm:"primaryKey;autoCreateTime:false"`
+	EntityId           int64     `gorm:"primaryKey"`
+	RelatedEntityId    int64     `gorm:"primaryKey"`
+	NotificationsCount int64
+	Title              string
+	Body               string
+	Headline           string
+	Kind               string
+	RenderingVariables string
+	CustomData         string
+	NotificationInfo   string
+	ExpiresAt          time.Time
+}
+
+func (NotificationFeedItem) TableName() string {
+	return "notification_feed"
+}
+
+type NotificationRelation struct {
+	UserId          int64  `gorm:"primaryKey"`
+	EventType       string `gorm:"primaryKey"`
+	EntityId        int64  `gorm:"primaryKey"`
+	RelatedEntityId int64  `gorm:"primaryKey"`
+	EventApplied    bool
+	ExpiresAt       time.Time
+}
+
+func (NotificationRelation) TableName() string {
+	return "notification_relations"
+}
+
+type PushNotificationGroupQueueItem struct {
+	DeadlineKey       time.Time `gorm:"primaryKey"`
+	Deadline          time.Time `gorm:"primaryKey"`
+	UserId            int64     `gorm:"primaryKey"`
+	EventType         string    `gorm:"primaryKey"`
+	EntityId          int64     `gorm:"primaryKey"`
+	CreatedAt         time.Time `gorm:"autoCreateTime:false"`
+	NotificationCount int64
+	ExpiresAt         time.Time
+}
+
+func (PushNotificationGroupQueueItem) TableName() string {
+	return "push_notification_group_queue"
+}
+
+type UserNotificationSetting struct {
+	UserId     int64  `gorm:"primaryKey"`
+	TemplateId string `gorm:"primaryKey"`
+	Muted      bool
+}
+
+func (UserNotificationSetting) TableName() string {
+	return "user_notifications_settings"
+}
+
+type UserNotificationRead struct {
+	UserId         int64 `gorm:"primaryKey"`
+	NotificationId int64 `gorm:"primaryKey"`
+}
+
+func (UserNotificationRead) TableName() string {
+	return "user_notifications_read"
+}
+
+type UserNotificationReadCounter struct {
+	NotificationId int64 `gorm:"primaryKey"`
+	ReadCount      int64
+}
+
+func (UserNotificationReadCounter) TableName() string {
+	return "user_notifications_read_counter"
+}
+
+// NotificationUser is a local copy of user data used for rendering
+type NotificationUser struct {
+	UserId            int64 `gorm:"primaryKey"`
+	Username          string
+	Firstname         string
+	Lastname          string
+	NamePrivacyStatus user_go.NamePrivacyStatus
+	Language          translation.Language
+	Email             string
+	ExpiresAt         time.Time
+}
+
+func (NotificationUser) TableName() string {
+	return "notification_users"
+}
+
+// StorageBackfillProgress keeps scylla paging state of the last copied page, so backfill can be resumed
+type StorageBackfillProgress struct {
+	Name        string `gorm:"column:table_name;primaryKey"`
+	PageState   []byte
+	CopiedCount int64
+	CompletedAt null.Time
+	UpdatedAt   time.Time
+}
+
+func (StorageBackfillProgress) TableName() string {
+	return "storage_backfill_progress"
+}
@@ -0,0 +1,85 @@
+package database
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+
+	"github.com/digitalmonsters/go-common/common"
+	"github.com/digitalmonsters/go-common/translation"
+	"gopkg.in/guregu/null.v4"
+)
+
+type PushCampaignStatus string
+
+const (
+	PushCampaignStatusScheduled PushCampaignStatus = "scheduled"
+	PushCampaignStatusRunning   PushCampaignStatus = "running"
+	PushCampaignStatusCompleted PushCampaignStatus = "completed"
+	PushCampaignStatusCancelled PushCampaignStatus = "cancelled"
+)
+
+type PushCampaignRecipientStatus string
+
+const (
+	PushCampaignRecipientStatusPending PushCampaignRecipientStatus = "pending"
+	PushCampaignRecipientStatusSent    PushCampaignRecipientStatus = "sent"
+	PushCampaignRecipientStatusFailed  PushCampaignRecipientStatus = "failed"
+	PushCampaignRecipientStatusSkipped PushCampaignRecipientStatus = "skipped"
+)
+
+// PushCampaign is an admin broadcast push, sent in batches by the campaign worker once ScheduledAt is reached
+type PushCampaign struct {
+	Id            int64              `json:"id" gorm:"primaryKey;autoIncrement"`
+	Title         string             `json:"title"`
+	Body          string             `json:"body"`
+	ImageUrl      string             `json:"image_url"`
+	CustomData    CustomData         `json:"custom_data" gorm:"type:jsonb"`
+	Segment       PushSegment        `json:"segment" gorm:"type:jsonb"`
+	ScheduledAt   time.Time          `json:"scheduled_at"`
+	RatePerSecond int                `json:"rate_per_second"`
+	Status        PushCampaignStatus `json:"status"`
+	TargetedCount int64              `json:"targeted_count"`
+	SentCount     int64              `json:"sent_count"`
+	FailedCount   int64              `json:"failed_count"`
+	CreatedBy     int64              `json:"created_by"`
+	CreatedAt     time.Time          `json:"created_at"`
+	UpdatedAt     time.Time          `json:"updated_at"`
+	StartedAt     null.Time          `json:"started_at"`
+	FinishedAt    null.Time          `json:"finished_at"`
+	CancelledAt   null.Time          `json:"cancelled_at"`
+}
+
+func (PushCampaign) TableName() string {
+	return "push_campaigns"
+}
+
+// PushSegment describes campaign audience. Empty fields are not applied
+type PushSegment struct {
+	CountryCodes   []string               `json:"country_codes"`
+	Languages      []translation.Language `json:"languages"`
+	Platforms      []common.DeviceType    `json:"platforms"`
+	KycStatuses    []string               `json:"kyc_statuses"`
+	LastActiveFrom null.Time              `json:"last_active_from"`
+	LastActiveTo   null.Time              `json:"last_active_to"`
+}
+
+func (n *PushSegment) Scan(input interface{}) error {
+	return json.Unmarshal(input.([]byte), n)
+}
+
+func (n PushSegment) Value() (driver.Value, error) {
+	return json.Marshal(n)
+}
+
+type PushCampaignRecipient struct {
+	CampaignId int64                       `json:"campaign_id" gorm:"primaryKey"`
+	UserId     int64                       `json:"user_id" gorm:"primaryKey"`
+	Status     PushCampaignRecipientStatus `json:"status"`
+	Error      null.String                 `json:"error"`
+	CreatedAt  time.Time                   `json:"created_at"`
+}
+
+func (PushCampaignRecipient) TableName() string {
+	return "push_campaign_recipients"
+}
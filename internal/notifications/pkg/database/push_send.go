@@ -0,0 +1,28 @@
+package database
+
+import (
+	"time"
+
+	"github.com/digitalmonsters/go-common/common"
+	"github.com/digitalmonsters/go-common/translation"
+	"gopkg.in/guregu/null.v4"
+)
+
+// PushSend is a push accepted for delivery to a device. Id is shared by all devices of a single push and is sent to
+// clients in custom data as push_send_id. Clients report it back as notification_id of track_fcm_notifications,
+// so opens can be joined with sends
+type PushSend struct {
+	Id         int64                `json:"id" gorm:"primaryKey"`
+	DeviceId   string               `json:"device_id" gorm:"primaryKey"`
+	UserId     int64                `json:"user_id"`
+	TemplateId string               `json:"template_id"`
+	Kind       string               `json:"kind"`
+	Platform   common.DeviceType    `json:"platform"`
+	Language   translation.Language `json:"language"`
+	CampaignId null.Int             `json:"campaign_id"`
+	CreatedAt  time.Time            `json:"created_at"`
+}
+
+func (PushSend) TableName() string {
+	return "push_sends"
+}
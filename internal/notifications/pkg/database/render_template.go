@@ -1,6 +1,11 @@
 package database
 
-import "time"
+import (
+	"time"
+
+	"github.com/digitalmonsters/go-common/translation"
+	"gopkg.in/guregu/null.v4"
+)
 
 type RenderTemplate struct {
 	Id        string `json:"id"`
@@ -10,6 +15,10 @@ type RenderTemplate struct {
 	ImageUrl  string `json:"image_url"`
 	Muted     bool   `json:"muted"`
 
+	Category     string `json:"category"`
+	DefaultPush  bool   `json:"default_push"`
+	DefaultInApp bool   `json:"default_in_app"`
+
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
@@ -17,3 +26,80 @@ type RenderTemplate struct {
 func (RenderTemplate) TableName() string {
 	return "render_templates"
 }
+
+// Notification categories group templates, so users can mute all templates of a category at once
+const (
+	CategorySocial    = "social"
+	CategoryComments  = "comments"
+	CategoryContent   = "content"
+	CategoryCreator   = "creator"
+	CategoryRewards   = "rewards"
+	CategoryMarketing = "marketing"
+	CategorySystem    = "system"
+	CategoryAccount   = "account" // transactional notifications, can not be muted
+)
+
+var Categories = []string{CategorySocial, CategoryComments, CategoryContent, CategoryCreator, CategoryRewards,
+	CategoryMarketing, CategorySystem, CategoryAccount}
+
+func IsKnownCategory(category string) bool {
+	for _, c := range Categories {
+		if c == category {
+			return true
+		}
+	}
+
+	return false
+}
+
+const (
+	TemplateFieldTitle            = "title"
+	TemplateFieldBody             = "body"
+	TemplateFieldHeadline         = "headline"
+	TemplateFieldTitleMultiple    = "title_multiple"
+	TemplateFieldBodyMultiple     = "body_multiple"
+	TemplateFieldHeadlineMultiple = "headline_multiple"
+)
+
+var TemplateFields = []string{TemplateFieldTitle, TemplateFieldBody, TemplateFieldHeadline,
+	TemplateFieldTitleMultiple, TemplateFieldBodyMultiple, TemplateFieldHeadlineMultiple}
+
+// RenderTemplateText is a versioned per-language copy of a template. Rows are append-only,
+// the highest version for (template_id, language) is the active one. Null fields fall back to embedded translations.
+type RenderTemplateText struct {
+	Id               int64                `json:"id" gorm:"primaryKey;autoIncrement"`
+	TemplateId       string               `json:"template_id"`
+	Language         translation.Language `json:"language"`
+	Version          int                  `json:"version"`
+	Title            null.String          `json:"title"`
+	Body             null.String          `json:"body"`
+	Headline         null.String          `json:"headline"`
+	TitleMultiple    null.String          `json:"title_multiple"`
+	BodyMultiple     null.String          `json:"body_multiple"`
+	HeadlineMultiple null.String          `json:"headline_multiple"`
+	CreatedBy        int64                `json:"created_by"`
+	CreatedAt        time.Time            `json:"created_at"`
+}
+
+func (RenderTemplateText) TableName() string {
+	return "render_template_texts"
+}
+
+func (t RenderTemplateText) GetField(field string) null.String {
+	switch field {
+	case TemplateFieldTitle:
+		return t.Title
+	case TemplateFieldBody:
+		return t.Body
+	case TemplateFieldHeadline:
+		return t.Headline
+	case TemplateFieldTitleMultiple:
+		return t.TitleMultiple
+	case TemplateFieldBodyMultiple:
+		return t.BodyMultiple
+	case TemplateFieldHeadlineMultiple:
+		return t.HeadlineMultiple
+	}
+
+	return null.String{}
+}
@@ -23,6 +23,11 @@ func initNonCi() {
 	config := configs.GetConfig()
 	var err error
 
+	if config.Storage.Mode == "postgres" { // scylla is not used anymore
+		log.Info().Msg("[Scylla] storage mode is postgres, session is not created")
+		return
+	}
+
 	cluster := boilerplate.GetScyllaCluster(config.Scylla)
 	session, err = cluster.CreateSession()
 	if err != nil {
@@ -207,6 +207,10 @@ func GetNotificationType(templateId string) string {
 		return "push.ads_moderation.status"
 	case "ads_campaign_rejected":
 		return "push.ads_moderation.status"
+	case "music_song_approved":
+		return "push.music-song.status"
+	case "music_song_rejected":
+		return "push.music-song.status"
 	}
 	return ""
 }
@@ -391,6 +395,8 @@ func GetNotificationTemplates(notificationType string) []string {
 		return []string{"add_social_subs_target_achieved_bonus"}
 	case "push.ads_moderation.status":
 		return []string{"ads_campaign_rejected", "ads_campaign_approved"}
+	case "push.music-song.status":
+		return []string{"music_song_rejected", "music_song_approved"}
 	}
 	return []string{}
 }
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"sync"
 
+	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
 
 	firebase "firebase.google.com/go"
@@ -50,6 +51,10 @@ func (f *FirebaseClient) SendNotification(
 	deviceToken, platform, collapseKey, title, imageUrl, body, notificationType string,
 	data map[string]string,
 ) (string, error) {
+	if f == nil {
+		return "", errors.New("firebase is not configured")
+	}
+
 	if data == nil {
 		data = make(map[string]string)
 	}
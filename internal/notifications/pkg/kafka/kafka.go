@@ -12,6 +12,7 @@ import (
 	"github.com/digitalmonsters/go-common/eventsourcing"
 	"github.com/digitalmonsters/go-common/wrappers/user_go"
 	"github.com/digitalmonsters/notification-handler/pkg/mail"
+	"github.com/digitalmonsters/notification-handler/pkg/settings"
 	"github.com/rs/zerolog/log"
 	"github.com/segmentio/kafka-go"
 )
@@ -23,6 +24,7 @@ func StartEmailConsumer(
 	brokerString string,
 	emailSvc mail.IEmailService,
 	userGoWrapper user_go.IUserGoWrapper,
+	settingsService settings.IService,
 ) {
 	reader := kafka.NewReader(kafka.ReaderConfig{
 		Brokers:  []string{brokerString},
@@ -60,12 +62,16 @@ func StartEmailConsumer(
 				}
 
 				switch object.EventType {
-				case "email.password.forgot":
+				case eventsourcing.EmailNotificationPasswordForgot:
 					var payload eventsourcing.EmailNotificationPasswordForgotPayload
 					if !unmarshalPayload(object, &payload) {
 						continue
 					}
 
+					if isEmailMuted(ctx, settingsService, payload.UserId, object.EventType) {
+						continue
+					}
+
 					log.Info().
 						Interface("payload", payload).
 						Int64("user_id", payload.UserId).
@@ -104,29 +110,37 @@ func StartEmailConsumer(
 						log.Info().Str("email", user.Email).Msg("Email sent successfully")
 					}
 
-				case "email.confirm.address":
+				case eventsourcing.EmailNotificationConfirmAddress:
 					var payload eventsourcing.EmailNotificationConfirmAddressPayload
 					if !unmarshalPayload(object, &payload) {
 						continue
 					}
 
+					if isEmailMuted(ctx, settingsService, payload.UserId, object.EventType) {
+						continue
+					}
+
 					log.Info().
 						Interface("payload", payload).
 						Int64("user_id", payload.UserId).
 						Msg("Processing confirm address event")
 
-				case "email.referral":
+				case eventsourcing.EmailNotificationReferral:
 					var payload eventsourcing.EmailNotificationReferralPayload
 					if !unmarshalPayload(object, &payload) {
 						continue
 					}
 
+					if isEmailMuted(ctx, settingsService, payload.UserId, object.EventType) {
+						continue
+					}
+
 					log.Info().
 						Interface("payload", payload).
 						Int64("user_id", payload.UserId).
 						Msg("Processing referral event")
 
-				case "email.guest.temp_info":
+				case eventsourcing.EmailGuestTempInfo:
 					var payload eventsourcing.EmailNotificationTempGuestInfoPayload
 					if !unmarshalPayload(object, &payload) {
 						continue
@@ -148,6 +162,30 @@ func StartEmailConsumer(
 	}()
 }
 
+func isEmailMuted(ctx context.Context, settingsService settings.IService, userID int64,
+	emailType eventsourcing.EmailNotificationType) bool {
+	templateID := string(emailType)
+
+	muted, err := settingsService.IsMuted(userID, settings.ChannelEmail, templateID, ctx)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Int64("user_id", userID).
+			Str("template_id", templateID).
+			Msg("Failed to check email preferences")
+		return false
+	}
+
+	if muted {
+		log.Info().
+			Int64("user_id", userID).
+			Str("template_id", templateID).
+			Msg("Email is muted by user preferences, skipping")
+	}
+
+	return muted
+}
+
 func getUser(ctx context.Context, userID int64, wrapper user_go.IUserGoWrapper) *user_go.UserRecord {
 	userReq := <-wrapper.GetUsers([]int64{userID}, ctx, false)
 	if userReq.Error != nil {
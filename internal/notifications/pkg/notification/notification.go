@@ -2,24 +2,24 @@ package notification
 
 import (
 	"context"
-	"encoding/base32"
 	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
 
+	"github.com/digitalmonsters/go-common/callback"
+	"github.com/digitalmonsters/go-common/common"
 	"github.com/digitalmonsters/go-common/wrappers/follow"
 	"github.com/digitalmonsters/go-common/wrappers/notification_handler"
 	"github.com/digitalmonsters/go-common/wrappers/user_go"
 	"github.com/digitalmonsters/notification-handler/pkg/database"
-	"github.com/digitalmonsters/notification-handler/pkg/database/scylla"
-	"github.com/digitalmonsters/notification-handler/pkg/utils"
+	"github.com/digitalmonsters/notification-handler/pkg/realtime"
+	"github.com/digitalmonsters/notification-handler/pkg/storage"
 	"github.com/google/uuid"
 	"github.com/pilagod/gorm-cursor-paginator/v2/paginator"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
 	"github.com/samber/lo"
-	snappy "github.com/segmentio/kafka-go/compress/snappy/go-xerial-snappy"
 	"gopkg.in/guregu/null.v4"
 	"gorm.io/gorm"
 )
@@ -35,24 +35,6 @@ func GetNotifications(db *gorm.DB, userId int64, page string, typeGroup TypeGrou
 		}, nil
 	}
 
-	var pageState []byte
-
-	if len(page) > 0 {
-		var err error
-
-		pageState, err = base32.StdEncoding.DecodeString(page)
-		if err != nil {
-			return nil, errors.WithStack(err)
-		}
-
-		pageState, err = snappy.Decode(pageState)
-		if err != nil {
-			return nil, errors.WithStack(err)
-		}
-	}
-
-	session := database.GetScyllaSession()
-
 	notificationByTypeGroupView := TypeGroupToScyllaViewName(typeGroup)
 	if len(notificationByTypeGroupView) == 0 {
 		return nil, errors.WithStack(errors.New("unknown group"))
@@ -62,65 +44,27 @@ func GetNotifications(db *gorm.DB, userId int64, page string, typeGroup TypeGrou
 		notificationByTypeGroupView = fmt.Sprintf("%v_with_push_admin", notificationByTypeGroupView)
 	}
 
-	query := fmt.Sprintf("select created_at, event_type, entity_id, related_entity_id from %v where user_id = ?", notificationByTypeGroupView)
-
-	iter := session.Query(query, userId).WithContext(ctx).PageSize(limit).PageState(pageState).Iter()
-
-	nextPageState := iter.PageState()
-	scanner := iter.Scanner()
+	feed, nextPage, err := storage.GetRepository().GetFeed(userId, notificationByTypeGroupView, page, limit, ctx)
+	if err != nil {
+		return nil, err
+	}
 
-	notifications := make([]database.Notification, 0)
+	notifications := make([]database.Notification, 0, len(feed))
 	notificationsCounts := make(map[uuid.UUID]int64)
 
-	for scanner.Next() {
-		notificationByTypeGroup := scylla.NotificationByTypeGroup{UserId: userId}
-
-		if err := scanner.Scan(&notificationByTypeGroup.CreatedAt, &notificationByTypeGroup.EventType,
-			&notificationByTypeGroup.EntityId, &notificationByTypeGroup.RelatedEntityId); err != nil {
-			return nil, errors.WithStack(err)
-		}
-
-		notificationIter := session.Query("select title, body, notifications_count, notification_info from notification "+
-			"where user_id = ? and event_type = ? and created_at = ? and entity_id = ? and related_entity_id = ?",
-			userId, notificationByTypeGroup.EventType, notificationByTypeGroup.CreatedAt,
-			notificationByTypeGroup.EntityId, notificationByTypeGroup.RelatedEntityId).Iter()
-
-		var title string
-		var body string
-		var notificationsCount int64
-		var notificationInfo string
-
-		notificationIter.Scan(&title, &body, &notificationsCount, &notificationInfo)
-
-		if err := notificationIter.Close(); err != nil {
-			return nil, errors.WithStack(err)
-		}
-
+	for _, item := range feed {
 		var notification database.Notification
-		if err := json.Unmarshal([]byte(notificationInfo), &notification); err != nil {
+		if err = json.Unmarshal([]byte(item.NotificationInfo), &notification); err != nil {
 			return nil, errors.WithStack(err)
 		}
 
-		notification.Title = title
-		notification.Message = body
-		notificationsCounts[notification.Id] = notificationsCount
+		notification.Title = item.Title
+		notification.Message = item.Body
+		notificationsCounts[notification.Id] = item.NotificationsCount
 
 		notifications = append(notifications, notification)
 	}
 
-	if err := scanner.Err(); err != nil {
-		return nil, errors.WithStack(err)
-	}
-	if err := iter.Close(); err != nil {
-		return nil, errors.WithStack(err)
-	}
-
-	nextPage := ""
-
-	if len(nextPageState) > 0 {
-		nextPage = base32.StdEncoding.EncodeToString(snappy.Encode(nextPageState))
-	}
-
 	notificationsResp := mapNotificationsToResponseItems(notifications, notificationsCounts, userGoWrapper, followWrapper, ctx)
 
 	var userNotification database.UserNotification
@@ -240,6 +184,7 @@ func getNotificationsTemplatesByTypeGroup(typeGroup TypeGroup) []string {
 			"monthly_mega_bonus_progress_almost_finished", "monthly_mega_bonus_one_day_missing", "monthly_mega_bonus_do_not_miss",
 			"push_admin", "first_x_social_media_added", "add_social_subs_target_achieved_bonus", "ads_campaign_rejected", "ads_campaign_approved",
 			"music_creator_status_rejected", "music_creator_status_approved", "music_creator_status_pending", "intro",
+			"music_song_rejected", "music_song_approved",
 		}
 	case TypeGroupComment:
 		return []string{"comment_reply", "comment_vote_like", "comment_vote_dislike", "comment_profile_resource_create",
@@ -271,13 +216,20 @@ func DeleteNotification(db *gorm.DB, userId int64, id uuid.UUID) error {
 	return nil
 }
 
-func ReadAllNotifications(db *gorm.DB, userId int64) error {
+// ReadAllNotifications resets unread counter, returned callbacks publish it to realtime stream after commit
+func ReadAllNotifications(db *gorm.DB, userId int64) ([]callback.Callback, error) {
 	if err := db.Model(&database.UserNotification{}).
 		Where("user_id = ?", userId).Update("unread_count", 0).Error; err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	return []callback.Callback{
+		func(ctx context.Context) error {
+			realtime.PublishUnreadCount(userId, 0, ctx)
+
+			return nil
+		},
+	}, nil
 }
 
 func IncrementUnreadNotificationsCounter(db *gorm.DB, userId int64) error {
@@ -288,6 +240,31 @@ func IncrementUnreadNotificationsCounter(db *gorm.DB, userId int64) error {
 	return nil
 }
 
+func GetUnreadNotificationsCount(db *gorm.DB, userId int64) (int64, error) {
+	var records []database.UserNotification
+
+	if err := db.Where("user_id = ?", userId).Limit(1).Find(&records).Error; err != nil {
+		return 0, errors.WithStack(err)
+	}
+
+	if len(records) == 0 {
+		return 0, nil
+	}
+
+	return records[0].UnreadCount, nil
+}
+
+// PublishUnreadNotificationsCount sends current unread counter to realtime subscribers, should be called after commit
+func PublishUnreadNotificationsCount(db *gorm.DB, userId int64, ctx context.Context) {
+	count, err := GetUnreadNotificationsCount(db, userId)
+	if err != nil {
+		log.Ctx(ctx).Err(err).Int64("user_id", userId).Msg("[Realtime] can not get unread notifications count")
+		return
+	}
+
+	realtime.PublishUnreadCount(userId, count, ctx)
+}
+
 func ListNotificationsByAdmin(db *gorm.DB, req ListNotificationsByAdminRequest, userGoWrapper user_go.IUserGoWrapper,
 	followWrapper follow.IFollowWrapper, ctx context.Context) (*ListNotificationsByAdminResponse, error) {
 	notifications := make([]database.Notification, 0)
@@ -325,61 +302,13 @@ func ListNotificationsByAdmin(db *gorm.DB, req ListNotificationsByAdminRequest,
 }
 
 func ReadNotification(req ReadNotificationRequest, userId int64, ctx context.Context) error {
-	session := database.GetScyllaSession()
-
-	iter := session.Query("select notification_id from user_notifications_read where cluster_key = ? and notification_id = ? and user_id = ? limit 1;",
-		GetUserNotificationsReadClusterKey(userId), req.NotificationId, userId).
-		WithContext(ctx).Iter()
-
-	isNotificationAlreadyRead := iter.NumRows() > 0
-
-	if err := iter.Close(); err != nil {
-		return err
-	}
-
-	if !isNotificationAlreadyRead {
-		if err := session.Query(
-			"insert into user_notifications_read (cluster_key, notification_id, user_id) values (?, ?, ?)",
-			GetUserNotificationsReadClusterKey(userId), req.NotificationId, userId,
-		).Exec(); err != nil {
-			return err
-		}
-
-		if err := session.Query(
-			"update user_notifications_read_counter set read_count = read_count + ? where notification_id = ?",
-			1, req.NotificationId,
-		).Exec(); err != nil {
-			return err
-		}
-	}
+	_, err := storage.GetRepository().MarkRead(userId, req.NotificationId, ctx)
 
-	return nil
+	return err
 }
 
 func GetNotificationsReadCount(req GetNotificationsReadCountRequest, ctx context.Context) (map[int64]int64, error) {
-	notificationsReadCountMap := make(map[int64]int64)
-
-	if len(req.NotificationIds) == 0 {
-		return notificationsReadCountMap, nil
-	}
-
-	session := database.GetScyllaSession()
-
-	iter := session.Query(fmt.Sprintf("select notification_id, read_count from user_notifications_read_counter where notification_id in (%v);",
-		utils.JoinInt64ForInStatement(req.NotificationIds))).WithContext(ctx).Iter()
-
-	var notificationId int64
-	var readCount int64
-
-	for iter.Scan(&notificationId, &readCount) {
-		notificationsReadCountMap[notificationId] = readCount
-	}
-
-	if err := iter.Close(); err != nil {
-		return nil, err
-	}
-
-	return notificationsReadCountMap, nil
+	return storage.GetRepository().GetReadCounts(req.NotificationIds, ctx)
 }
 
 func DisableUnregisteredTokens(req notification_handler.DisableUnregisteredTokensRequest, db *gorm.DB) ([]string, error) {
@@ -390,16 +319,26 @@ func DisableUnregisteredTokens(req notification_handler.DisableUnregisteredToken
 	return req.Tokens, nil
 }
 
-func GetLatestDeviceForUser(userID int, db *gorm.DB) (*database.Device, error) {
+// GetLatestDeviceForUser returns the last registered device of the user, of the given platforms when platforms are set
+func GetLatestDeviceForUser(userID int, db *gorm.DB, platforms ...common.DeviceType) (*database.Device, error) {
 	var device database.Device
-	if err := db.Raw(`SELECT * FROM devices WHERE "userId" = ? ORDER BY "createdAt" DESC LIMIT 1
-	`, userID).Scan(&device).Error; err != nil {
+
+	query := db.Model(&database.Device{}).Where("\"userId\" = ?", userID)
+
+	if len(platforms) > 0 {
+		query = query.Where("platform in ?", platforms)
+	}
+
+	if err := query.Order("\"createdAt\" desc").Limit(1).Scan(&device).Error; err != nil {
 		return nil, errors.WithStack(err)
 	}
 	return &device, nil
 }
 
-func CreateNotification(ctx context.Context, req notification_handler.CreateNotificationRequest, db *gorm.DB) (notification_handler.CreateNotificationResponse, error) {
+// CreateNotification saves notification in the caller transaction, returned callbacks publish it to realtime stream
+// and should be executed after commit
+func CreateNotification(ctx context.Context, req notification_handler.CreateNotificationRequest,
+	db *gorm.DB) (notification_handler.CreateNotificationResponse, []callback.Callback, error) {
 
 	result := MapInternalToDatabaseNotification(req)
 
@@ -421,7 +360,7 @@ func CreateNotification(ctx context.Context, req notification_handler.CreateNoti
 
 		if result.Error != nil {
 			log.Ctx(ctx).Error().Err(result.Error).Msg("[PushNotification] Failed to delete existing notifications")
-			return notification_handler.CreateNotificationResponse{Status: false}, result.Error
+			return notification_handler.CreateNotificationResponse{Status: false}, nil, result.Error
 		}
 
 		deletedCount = result.RowsAffected
@@ -434,14 +373,26 @@ func CreateNotification(ctx context.Context, req notification_handler.CreateNoti
 	}
 
 	if err := db.Create(&result).Error; err != nil {
-		return notification_handler.CreateNotificationResponse{Status: false}, err
+		return notification_handler.CreateNotificationResponse{Status: false}, nil, err
 	}
 
 	if err := IncrementUnreadNotificationsCounter(db, result.UserId); err != nil {
-		return notification_handler.CreateNotificationResponse{Status: true}, err
+		return notification_handler.CreateNotificationResponse{Status: true}, nil, err
 	}
 
-	return notification_handler.CreateNotificationResponse{Status: true}, nil
+	return notification_handler.CreateNotificationResponse{Status: true}, notificationCreatedCallbacks(result), nil
+}
+
+// notificationCreatedCallbacks reads unread counter from master, since the caller transaction is already committed
+func notificationCreatedCallbacks(notification database.Notification) []callback.Callback {
+	return []callback.Callback{
+		func(ctx context.Context) error {
+			realtime.PublishNotification(notification.UserId, notification, ctx)
+			PublishUnreadNotificationsCount(database.GetDbWithContext(database.DbTypeMaster, ctx), notification.UserId, ctx)
+
+			return nil
+		},
+	}
 }
 
 func DeleteUnFollowNotification(ctx context.Context, notification notification_handler.CreateNotificationRequest, db *gorm.DB) error {
@@ -544,11 +495,35 @@ func GetInAppNotifications(userID int64, db *gorm.DB) ([]database.InAppNotificat
 	return notifications, nil
 }
 
-func CreateInAppNotification(req notification_handler.CreateNotificationRequest, db *gorm.DB) error {
+// CreateInAppNotification saves in-app notification in the caller transaction, returned callbacks publish it to
+// realtime stream and should be executed after commit
+func CreateInAppNotification(req notification_handler.CreateNotificationRequest, db *gorm.DB) ([]callback.Callback, error) {
 	inAppNotification := MapInternalToDatabaseInAppNotification(req)
 	if err := db.Create(&inAppNotification).Error; err != nil {
-		return err
+		return nil, err
+	}
+
+	return []callback.Callback{
+		func(ctx context.Context) error {
+			realtime.PublishInAppNotification(inAppNotification.UserId, inAppNotification, ctx)
+
+			return nil
+		},
+	}, nil
+}
+
+// AckInAppNotifications marks in-app notifications delivered over realtime stream as shown
+func AckInAppNotifications(db *gorm.DB, userId int64, ids []uuid.UUID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	if err := db.Model(&database.InAppNotification{}).
+		Where("user_id = ? and id in ?", userId, ids).
+		Update("is_shown", true).Error; err != nil {
+		return errors.WithStack(err)
 	}
+
 	return nil
 }
 
@@ -611,24 +586,6 @@ func GetPet2Notifications(db *gorm.DB, userId int64, page string, typeGroup Type
 		}, nil
 	}
 
-	var pageState []byte
-
-	if len(page) > 0 {
-		var err error
-
-		pageState, err = base32.StdEncoding.DecodeString(page)
-		if err != nil {
-			return nil, errors.WithStack(err)
-		}
-
-		pageState, err = snappy.Decode(pageState)
-		if err != nil {
-			return nil, errors.WithStack(err)
-		}
-	}
-
-	session := database.GetScyllaSession()
-
 	notificationByTypeGroupView := TypeGroupToScyllaViewName(typeGroup)
 	if len(notificationByTypeGroupView) == 0 {
 		return nil, errors.WithStack(errors.New("unknown group"))
@@ -638,65 +595,27 @@ func GetPet2Notifications(db *gorm.DB, userId int64, page string, typeGroup Type
 		notificationByTypeGroupView = fmt.Sprintf("%v_with_push_admin", notificationByTypeGroupView)
 	}
 
-	query := fmt.Sprintf("select created_at, event_type, entity_id, related_entity_id from %v where user_id = ?", notificationByTypeGroupView)
-
-	iter := session.Query(query, userId).WithContext(ctx).PageSize(limit).PageState(pageState).Iter()
-
-	nextPageState := iter.PageState()
-	scanner := iter.Scanner()
+	feed, nextPage, err := storage.GetRepository().GetFeed(userId, notificationByTypeGroupView, page, limit, ctx)
+	if err != nil {
+		return nil, err
+	}
 
-	notifications := make([]database.Notification, 0)
+	notifications := make([]database.Notification, 0, len(feed))
 	notificationsCounts := make(map[uuid.UUID]int64)
 
-	for scanner.Next() {
-		notificationByTypeGroup := scylla.NotificationByTypeGroup{UserId: userId}
-
-		if err := scanner.Scan(&notificationByTypeGroup.CreatedAt, &notificationByTypeGroup.EventType,
-			&notificationByTypeGroup.EntityId, &notificationByTypeGroup.RelatedEntityId); err != nil {
-			return nil, errors.WithStack(err)
-		}
-
-		notificationIter := session.Query("select title, body, notifications_count, notification_info from notification "+
-			"where user_id = ? and event_type = ? and created_at = ? and entity_id = ? and related_entity_id = ?",
-			userId, notificationByTypeGroup.EventType, notificationByTypeGroup.CreatedAt,
-			notificationByTypeGroup.EntityId, notificationByTypeGroup.RelatedEntityId).Iter()
-
-		var title string
-		var body string
-		var notificationsCount int64
-		var notificationInfo string
-
-		notificationIter.Scan(&title, &body, &notificationsCount, &notificationInfo)
-
-		if err := notificationIter.Close(); err != nil {
-			return nil, errors.WithStack(err)
-		}
-
+	for _, item := range feed {
 		var notification database.Notification
-		if err := json.Unmarshal([]byte(notificationInfo), &notification); err != nil {
+		if err = json.Unmarshal([]byte(item.NotificationInfo), &notification); err != nil {
 			return nil, errors.WithStack(err)
 		}
 
-		notification.Title = title
-		notification.Message = body
-		notificationsCounts[notification.Id] = notificationsCount
+		notification.Title = item.Title
+		notification.Message = item.Body
+		notificationsCounts[notification.Id] = item.NotificationsCount
 
 		notifications = append(notifications, notification)
 	}
 
-	if err := scanner.Err(); err != nil {
-		return nil, errors.WithStack(err)
-	}
-	if err := iter.Close(); err != nil {
-		return nil, errors.WithStack(err)
-	}
-
-	nextPage := ""
-
-	if len(nextPageState) > 0 {
-		nextPage = base32.StdEncoding.EncodeToString(snappy.Encode(nextPageState))
-	}
-
 	notificationsResp := mapNotificationsToResponseItems(notifications, notificationsCounts, userGoWrapper, followWrapper, ctx)
 
 	var userNotification database.UserNotification
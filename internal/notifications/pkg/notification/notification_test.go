@@ -0,0 +1,120 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/digitalmonsters/go-common/boilerplate_testing"
+	"github.com/digitalmonsters/go-common/wrappers/notification_handler"
+	"github.com/digitalmonsters/notification-handler/configs"
+	"github.com/digitalmonsters/notification-handler/pkg/database"
+	"github.com/digitalmonsters/notification-handler/pkg/realtime"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+var config configs.Settings
+var gormDb *gorm.DB
+
+func TestMain(m *testing.M) {
+	config = configs.GetConfig()
+	gormDb = database.GetDb(database.DbTypeMaster)
+	os.Exit(m.Run())
+}
+
+func TestCreateInAppNotification_Realtime(t *testing.T) {
+	if err := boilerplate_testing.FlushPostgresTables(config.MasterDb, []string{"public.in_app_notifications"}, nil,
+		t); err != nil {
+		t.Fatal(err)
+	}
+
+	events, unsubscribe := realtime.GetBroker().Subscribe(101)
+	defer unsubscribe()
+
+	tx := gormDb.Begin()
+	defer tx.Rollback()
+
+	callbacks, err := CreateInAppNotification(notification_handler.CreateNotificationRequest{
+		Notifications: notification_handler.Notification{
+			UserID:  101,
+			Type:    "popup.test",
+			Title:   "title",
+			Message: "message",
+		},
+	}, tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Len(t, events, 0) // nothing is published before commit
+
+	if err := tx.Commit().Error; err != nil {
+		t.Fatal(err)
+	}
+
+	for _, callbackFn := range callbacks {
+		assert.Nil(t, callbackFn(context.Background()))
+	}
+
+	if !assert.Len(t, events, 1) {
+		return
+	}
+
+	event := <-events
+	assert.Equal(t, realtime.EventTypeInAppNotification, event.Type)
+	assert.Equal(t, int64(101), event.UserId)
+
+	var payload database.InAppNotification
+	if err := json.Unmarshal(event.Payload, &payload); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "message", payload.Message)
+
+	var saved []database.InAppNotification
+	if err := gormDb.Where("user_id = ?", 101).Find(&saved).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if assert.Len(t, saved, 1) {
+		assert.Equal(t, saved[0].Id, payload.Id)
+	}
+}
+
+func TestReadAllNotifications_Realtime(t *testing.T) {
+	if err := boilerplate_testing.FlushPostgresTables(config.MasterDb, []string{"public.user_notifications"}, nil,
+		t); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := IncrementUnreadNotificationsCounter(gormDb, 102); err != nil {
+		t.Fatal(err)
+	}
+
+	events, unsubscribe := realtime.GetBroker().Subscribe(102)
+	defer unsubscribe()
+
+	callbacks, err := ReadAllNotifications(gormDb, 102)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, callbackFn := range callbacks {
+		assert.Nil(t, callbackFn(context.Background()))
+	}
+
+	count, err := GetUnreadNotificationsCount(gormDb, 102)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, int64(0), count)
+
+	if assert.Len(t, events, 1) {
+		event := <-events
+		assert.Equal(t, realtime.EventTypeUnreadCount, event.Type)
+		assert.JSONEq(t, `{"unread_count":0}`, string(event.Payload))
+	}
+}
@@ -0,0 +1,137 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/digitalmonsters/notification-handler/configs"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+const subscriberBufferSize = 32
+
+// IBroker fans out events to connections of a user. Memory broker works within a single node, redis broker
+// delivers events published on any node
+type IBroker interface {
+	Publish(event Event, ctx context.Context) error
+	Subscribe(userId int64) (events <-chan Event, unsubscribe func())
+	Close() error
+}
+
+var broker IBroker
+var brokerOnce sync.Once
+
+// GetBroker returns broker configured by Realtime settings, memory broker is used by default
+func GetBroker() IBroker {
+	brokerOnce.Do(func() {
+		cfg := configs.GetConfig().Realtime
+
+		if BrokerType(cfg.Broker) == BrokerTypeRedis {
+			broker = NewRedisBroker(cfg.Redis, cfg.Channel)
+			return
+		}
+
+		broker = NewMemoryBroker()
+	})
+
+	return broker
+}
+
+type memoryBroker struct {
+	mutex       sync.RWMutex
+	subscribers map[int64]map[chan Event]struct{}
+}
+
+func NewMemoryBroker() IBroker {
+	return newMemoryBroker()
+}
+
+func newMemoryBroker() *memoryBroker {
+	return &memoryBroker{
+		subscribers: map[int64]map[chan Event]struct{}{},
+	}
+}
+
+func (b *memoryBroker) Publish(event Event, ctx context.Context) error {
+	b.dispatch(event, ctx)
+
+	return nil
+}
+
+// dispatch never blocks publisher, events for a slow connection are dropped
+func (b *memoryBroker) dispatch(event Event, ctx context.Context) {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	for ch := range b.subscribers[event.UserId] {
+		select {
+		case ch <- event:
+		default:
+			log.Ctx(ctx).Warn().Int64("user_id", event.UserId).Str("type", string(event.Type)).
+				Msg("[Realtime] subscriber buffer is full, event dropped")
+		}
+	}
+}
+
+func (b *memoryBroker) Subscribe(userId int64) (<-chan Event, func()) {
+	ch := make(chan Event, subscriberBufferSize)
+
+	b.mutex.Lock()
+	if _, ok := b.subscribers[userId]; !ok {
+		b.subscribers[userId] = map[chan Event]struct{}{}
+	}
+	b.subscribers[userId][ch] = struct{}{}
+	b.mutex.Unlock()
+
+	var once sync.Once
+
+	return ch, func() {
+		once.Do(func() {
+			b.mutex.Lock()
+			defer b.mutex.Unlock()
+
+			delete(b.subscribers[userId], ch)
+			if len(b.subscribers[userId]) == 0 {
+				delete(b.subscribers, userId)
+			}
+
+			close(ch)
+		})
+	}
+}
+
+func (b *memoryBroker) Close() error {
+	return nil
+}
+
+func publish(eventType EventType, userId int64, payload interface{}, ctx context.Context) {
+	marshalled, err := json.Marshal(payload)
+	if err != nil {
+		log.Ctx(ctx).Err(errors.WithStack(err)).Send()
+		return
+	}
+
+	if err = GetBroker().Publish(Event{
+		Type:    eventType,
+		UserId:  userId,
+		Payload: marshalled,
+	}, ctx); err != nil {
+		log.Ctx(ctx).Err(err).Int64("user_id", userId).Msg("[Realtime] can not publish event")
+	}
+}
+
+// PublishNotification should be called after the notification is committed
+func PublishNotification(userId int64, notification interface{}, ctx context.Context) {
+	publish(EventTypeNotification, userId, notification, ctx)
+}
+
+// PublishInAppNotification should be called after the in-app notification is committed
+func PublishInAppNotification(userId int64, notification interface{}, ctx context.Context) {
+	publish(EventTypeInAppNotification, userId, notification, ctx)
+}
+
+func PublishUnreadCount(userId int64, unreadCount int64, ctx context.Context) {
+	publish(EventTypeUnreadCount, userId, UnreadCountPayload{UnreadCount: unreadCount}, ctx)
+}
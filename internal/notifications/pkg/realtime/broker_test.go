@@ -0,0 +1,50 @@
+package realtime
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestMemoryBroker(t *testing.T) {
+	b := NewMemoryBroker()
+	ctx := context.Background()
+
+	first, unsubscribeFirst := b.Subscribe(1)
+	second, unsubscribeSecond := b.Subscribe(1)
+	other, unsubscribeOther := b.Subscribe(2)
+	defer unsubscribeOther()
+
+	assert.Nil(t, b.Publish(Event{Type: EventTypeUnreadCount, UserId: 1, Payload: []byte(`{"unread_count":1}`)}, ctx))
+
+	assert.Equal(t, EventTypeUnreadCount, (<-first).Type)
+	assert.Equal(t, EventTypeUnreadCount, (<-second).Type)
+	assert.Len(t, other, 0)
+
+	unsubscribeFirst()
+	unsubscribeFirst()
+
+	_, ok := <-first
+	assert.False(t, ok)
+
+	for i := 0; i < subscriberBufferSize+1; i++ {
+		assert.Nil(t, b.Publish(Event{Type: EventTypeNotification, UserId: 1}, ctx))
+	}
+
+	assert.Len(t, second, subscriberBufferSize)
+
+	unsubscribeSecond()
+	assert.Len(t, b.(*memoryBroker).subscribers, 1)
+}
+
+func TestIsOriginAllowed(t *testing.T) {
+	allowed := []string{"https://app.litit.com", "HTTPS://admin.litit.com/"}
+
+	assert.True(t, IsOriginAllowed("", allowed))
+	assert.True(t, IsOriginAllowed("https://app.litit.com", allowed))
+	assert.True(t, IsOriginAllowed("https://Admin.litit.com", allowed))
+	assert.False(t, IsOriginAllowed("http://app.litit.com", allowed))
+	assert.False(t, IsOriginAllowed("https://app.litit.com.evil.com", allowed))
+	assert.False(t, IsOriginAllowed("null", allowed))
+	assert.False(t, IsOriginAllowed("https://app.litit.com", nil))
+}
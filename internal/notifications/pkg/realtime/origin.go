@@ -0,0 +1,29 @@
+package realtime
+
+import (
+	"net/url"
+	"strings"
+)
+
+// IsOriginAllowed checks browser origin of the stream connection against AllowedOrigins setting. Native clients do not
+// send origin and are allowed, a browser page from unknown origin must not open a stream with cookies of the user
+func IsOriginAllowed(origin string, allowedOrigins []string) bool {
+	if len(origin) == 0 {
+		return true
+	}
+
+	parsed, err := url.Parse(origin)
+	if err != nil || len(parsed.Scheme) == 0 || len(parsed.Host) == 0 {
+		return false
+	}
+
+	normalized := strings.ToLower(parsed.Scheme + "://" + parsed.Host)
+
+	for _, allowed := range allowedOrigins {
+		if strings.ToLower(strings.TrimSuffix(strings.TrimSpace(allowed), "/")) == normalized {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,84 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/digitalmonsters/go-common/boilerplate"
+	"github.com/go-redis/redis/v8"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+const defaultRedisChannel = "notifications:realtime"
+
+// redisBroker publishes events to a redis channel, every node listens to the channel and dispatches events to
+// its local connections
+type redisBroker struct {
+	*memoryBroker
+	client  *redis.Client
+	channel string
+	cancel  context.CancelFunc
+}
+
+func NewRedisBroker(cfg boilerplate.RedisConfig, channel string) IBroker {
+	if len(channel) == 0 {
+		channel = defaultRedisChannel
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	b := &redisBroker{
+		memoryBroker: newMemoryBroker(),
+		client: redis.NewClient(&redis.Options{
+			Addr:     fmt.Sprintf("%v:%v", cfg.Host, cfg.Port),
+			Password: cfg.Password,
+			DB:       cfg.Db,
+		}),
+		channel: channel,
+		cancel:  cancel,
+	}
+
+	b.listenAsync(ctx)
+
+	return b
+}
+
+func (b *redisBroker) Publish(event Event, ctx context.Context) error {
+	marshalled, err := json.Marshal(event)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	if err = b.client.Publish(ctx, b.channel, marshalled).Err(); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return nil
+}
+
+func (b *redisBroker) listenAsync(ctx context.Context) {
+	subscriber := b.client.Subscribe(ctx, b.channel)
+
+	go func() {
+		defer subscriber.Close()
+
+		for msg := range subscriber.Channel() {
+			var event Event
+
+			if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil {
+				log.Err(errors.WithStack(err)).Msg("[Realtime] invalid event received from redis")
+				continue
+			}
+
+			b.dispatch(event, ctx)
+		}
+	}()
+}
+
+func (b *redisBroker) Close() error {
+	b.cancel()
+
+	return errors.WithStack(b.client.Close())
+}
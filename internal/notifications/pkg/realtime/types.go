@@ -0,0 +1,45 @@
+package realtime
+
+import (
+	"encoding/json"
+
+	"github.com/google/uuid"
+)
+
+type EventType string
+
+const (
+	EventTypeNotification      EventType = "notification"
+	EventTypeInAppNotification EventType = "in_app_notification"
+	EventTypeUnreadCount       EventType = "unread_count"
+)
+
+// Event is delivered to all connections of the user
+type Event struct {
+	Type    EventType       `json:"type"`
+	UserId  int64           `json:"user_id"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+type UnreadCountPayload struct {
+	UnreadCount int64 `json:"unread_count"`
+}
+
+type ClientMessageType string
+
+const (
+	ClientMessageTypeAck ClientMessageType = "ack"
+)
+
+// ClientMessage is sent by clients over WebSocket, ack marks in-app notifications as shown
+type ClientMessage struct {
+	Type ClientMessageType `json:"type"`
+	Ids  []uuid.UUID       `json:"ids"`
+}
+
+type BrokerType string
+
+const (
+	BrokerTypeMemory BrokerType = "memory"
+	BrokerTypeRedis  BrokerType = "redis"
+)
@@ -12,55 +12,120 @@ import (
 )
 
 var templateCache = cache.New(30*time.Minute, 11*time.Minute)
+var textsCache = cache.New(1*time.Minute, 2*time.Minute)
 var TemplateRenderingError = errors.New("template rendering error")
 
+// Texts holds raw (not rendered) template text by field name, see database.TemplateFields
+type Texts map[string]string
+
 func Render(renderTemplate database.RenderTemplate, renderingData database.RenderingVariables, language translation.Language) (title string,
 	body string, headline string, titleMultiple string, bodyMultiple string, headlineMultiple string, err error) {
-	prefix := fmt.Sprintf("%v_%v", renderTemplate.Id, renderTemplate.UpdatedAt)
-
-	translatedTitle, _ := translation.GetTranslation(translation.DefaultUserLanguage, language, translation.PlaceNotifications, fmt.Sprintf("%v_title", renderTemplate.Id))
-	title, err = RenderText(fmt.Sprintf("%v_title_%v", prefix, language), translatedTitle.ValueOrZero(), renderingData)
-
+	texts, revision, err := ResolveTexts(renderTemplate.Id, language)
 	if err != nil {
 		return "", "", "", "", "", "", err
 	}
 
-	translatedBody, _ := translation.GetTranslation(translation.DefaultUserLanguage, language, translation.PlaceNotifications, fmt.Sprintf("%v_body", renderTemplate.Id))
-	body, err = RenderText(fmt.Sprintf("%v_body_%v", prefix, language), translatedBody.ValueOrZero(), renderingData)
+	prefix := fmt.Sprintf("%v_%v_%v", renderTemplate.Id, renderTemplate.UpdatedAt, revision)
 
-	if err != nil {
-		return "", "", "", "", "", "", err
+	rendered := make(map[string]string, len(database.TemplateFields))
+
+	for _, field := range database.TemplateFields {
+		if rendered[field], err = RenderText(fmt.Sprintf("%v_%v_%v", prefix, field, language), texts[field], renderingData); err != nil {
+			return "", "", "", "", "", "", err
+		}
 	}
 
-	translatedHeadline, _ := translation.GetTranslation(translation.DefaultUserLanguage, language, translation.PlaceNotifications, fmt.Sprintf("%v_headline", renderTemplate.Id))
-	headline, err = RenderText(fmt.Sprintf("%v_headline_%v", prefix, language), translatedHeadline.ValueOrZero(), renderingData)
+	return rendered[database.TemplateFieldTitle], rendered[database.TemplateFieldBody], rendered[database.TemplateFieldHeadline],
+		rendered[database.TemplateFieldTitleMultiple], rendered[database.TemplateFieldBodyMultiple],
+		rendered[database.TemplateFieldHeadlineMultiple], nil
+}
 
+// ResolveTexts returns template texts for the language. Texts edited in the admin panel win over the embedded translations,
+// revision changes every time a new text version is saved, so it can be used as a part of the template cache key.
+func ResolveTexts(templateId string, language translation.Language) (Texts, string, error) {
+	languageText, err := getTemplateText(templateId, language)
 	if err != nil {
-		return "", "", "", "", "", "", err
+		return nil, "", err
 	}
 
-	translatedTitleMultiple, _ := translation.GetTranslation(translation.DefaultUserLanguage, language, translation.PlaceNotifications, fmt.Sprintf("%v_title_multiple", renderTemplate.Id))
-	titleMultiple, err = RenderText(fmt.Sprintf("%v_title_multiple_%v", prefix, language), translatedTitleMultiple.ValueOrZero(), renderingData)
+	var defaultLanguageText *database.RenderTemplateText
 
-	if err != nil {
-		return "", "", "", "", "", "", err
+	if language != translation.DefaultUserLanguage {
+		if defaultLanguageText, err = getTemplateText(templateId, translation.DefaultUserLanguage); err != nil {
+			return nil, "", err
+		}
 	}
 
-	translatedBodyMultiple, _ := translation.GetTranslation(translation.DefaultUserLanguage, language, translation.PlaceNotifications, fmt.Sprintf("%v_body_multiple", renderTemplate.Id))
-	bodyMultiple, err = RenderText(fmt.Sprintf("%v_body_multiple_%v", prefix, language), translatedBodyMultiple.ValueOrZero(), renderingData)
+	texts := make(Texts, len(database.TemplateFields))
 
-	if err != nil {
-		return "", "", "", "", "", "", err
+	for _, field := range database.TemplateFields {
+		if languageText != nil {
+			if v := languageText.GetField(field); v.Valid {
+				texts[field] = v.String
+				continue
+			}
+		}
+
+		translated, fromRequestedLanguage := translation.GetTranslation(translation.DefaultUserLanguage, language,
+			translation.PlaceNotifications, fmt.Sprintf("%v_%v", templateId, field))
+
+		if !fromRequestedLanguage && defaultLanguageText != nil {
+			if v := defaultLanguageText.GetField(field); v.Valid {
+				texts[field] = v.String
+				continue
+			}
+		}
+
+		texts[field] = translated.ValueOrZero()
+	}
+
+	return texts, fmt.Sprintf("v%v_%v", getTextVersion(languageText), getTextVersion(defaultLanguageText)), nil
+}
+
+func InvalidateTexts(templateId string, language translation.Language) {
+	textsCache.Delete(fmt.Sprintf("%v_%v", templateId, language))
+}
+
+func getTemplateText(templateId string, language translation.Language) (*database.RenderTemplateText, error) {
+	cacheKey := fmt.Sprintf("%v_%v", templateId, language)
+
+	if cached, ok := textsCache.Get(cacheKey); ok {
+		return cached.(*database.RenderTemplateText), nil
 	}
 
-	translatedHeadlineMultiple, _ := translation.GetTranslation(translation.DefaultUserLanguage, language, translation.PlaceNotifications, fmt.Sprintf("%v_headline_multiple", renderTemplate.Id))
-	headlineMultiple, err = RenderText(fmt.Sprintf("%v_headline_multiple_%v", prefix, language), translatedHeadlineMultiple.ValueOrZero(), renderingData)
+	var texts []database.RenderTemplateText
 
-	if err != nil {
-		return "", "", "", "", "", "", err
+	if err := database.GetDb(database.DbTypeReadonly).Where("template_id = ? and language = ?", templateId, language).
+		Order("version desc").Limit(1).Find(&texts).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	var text *database.RenderTemplateText
+
+	if len(texts) > 0 {
+		text = &texts[0]
+	}
+
+	textsCache.SetDefault(cacheKey, text)
+
+	return text, nil
+}
+
+func getTextVersion(text *database.RenderTemplateText) int {
+	if text == nil {
+		return 0
+	}
+
+	return text.Version
+}
+
+// ValidateText checks template syntax without executing it
+func ValidateText(templateBody string) error {
+	if _, err := template.New("validation").Parse(templateBody); err != nil {
+		return errors.WithStack(err)
 	}
 
-	return title, body, headline, titleMultiple, bodyMultiple, headlineMultiple, err
+	return nil
 }
 
 func RenderText(templateName string, templateBody string, renderingData database.RenderingVariables) (string, error) {
@@ -77,3 +77,12 @@ func TestRenderText(t *testing.T) {
 	}
 
 }
+
+func TestValidateText(t *testing.T) {
+	a := assert.New(t)
+
+	a.Nil(ValidateText("{{.firstname}} {{.lastname}} liked your video"))
+	a.Nil(ValidateText(""))
+	a.NotNil(ValidateText("{{.firstname} liked your video"))
+	a.NotNil(ValidateText("{{if .firstname}}missing end"))
+}
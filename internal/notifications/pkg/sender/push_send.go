@@ -0,0 +1,104 @@
+package sender
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/digitalmonsters/go-common/translation"
+	"github.com/digitalmonsters/notification-handler/pkg/database"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/guregu/null.v4"
+)
+
+const (
+	pushSendIdKey      = "push_send_id" // custom data of callers may already have own notification_id
+	aggregatedPushType = "push.aggregate"
+
+	followTemplateId      = "follow"
+	contentLikeTemplateId = "content_like"
+)
+
+// reservePushSendId returns id for the next push, zero means the push will be sent without tracking
+func reservePushSendId(ctx context.Context) int64 {
+	var id int64
+
+	if err := database.GetDbWithContext(database.DbTypeMaster, ctx).Raw("select nextval('push_sends_id_seq')").
+		Scan(&id).Error; err != nil {
+		log.Ctx(ctx).Err(err).Msg("[PushSend] can not reserve push send id")
+		return 0
+	}
+
+	return id
+}
+
+// withPushSendId returns a copy of custom data with the push send id, which is reported back by clients on open
+func withPushSendId(customData database.CustomData, sendId int64) database.CustomData {
+	if sendId == 0 {
+		return customData
+	}
+
+	result := make(database.CustomData, len(customData)+1)
+
+	for k, v := range customData {
+		result[k] = v
+	}
+
+	result[pushSendIdKey] = strconv.FormatInt(sendId, 10)
+
+	return result
+}
+
+// recordPushSends stores accepted push for every device. Errors are only logged, analytics should not affect delivery
+func recordPushSends(sendId int64, templateId string, kind string, language translation.Language, userId int64,
+	customData database.CustomData, devices []database.Device, ctx context.Context) {
+	if sendId == 0 || len(devices) == 0 {
+		return
+	}
+
+	var campaignId null.Int
+
+	if v, ok := customData["campaign_id"]; ok {
+		if parsed, err := strconv.ParseInt(fmt.Sprint(v), 10, 64); err == nil {
+			campaignId = null.IntFrom(parsed)
+		}
+	}
+
+	now := time.Now().UTC()
+	records := make([]database.PushSend, 0, len(devices))
+
+	for _, device := range devices {
+		records = append(records, database.PushSend{
+			Id:         sendId,
+			DeviceId:   device.DeviceId,
+			UserId:     userId,
+			TemplateId: templateId,
+			Kind:       kind,
+			Platform:   device.Platform,
+			Language:   language,
+			CampaignId: campaignId,
+			CreatedAt:  now,
+		})
+	}
+
+	if err := database.GetDbWithContext(database.DbTypeMaster, ctx).Create(&records).Error; err != nil {
+		log.Ctx(ctx).Err(err).Int64("push_send_id", sendId).Msg("[PushSend] can not record push sends")
+	}
+}
+
+// enqueuePush sends push to all user devices through the gateway and records it for analytics
+func (s *Sender) enqueuePush(userTokens []database.Device, pushType, kind, title, body, headline string, userId int64,
+	customData database.CustomData, language translation.Language, ctx context.Context) error {
+	sendId := reservePushSendId(ctx)
+	customData = withPushSendId(customData, sendId)
+
+	if err := <-s.gateway.EnqueuePushForUser(s.prepareCustomPushEvents(userTokens, pushType, kind, title, body, headline,
+		fmt.Sprint(userId), customData, userId), ctx); err != nil {
+		return err
+	}
+
+	recordPushSends(sendId, pushType, kind, language, userId, customData, userTokens, ctx)
+
+	return nil
+}
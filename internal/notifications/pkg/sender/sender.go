@@ -22,17 +22,18 @@ import (
 	"github.com/digitalmonsters/notification-handler/pkg/database/scylla"
 	"github.com/digitalmonsters/notification-handler/pkg/firebase"
 	notificationPkg "github.com/digitalmonsters/notification-handler/pkg/notification"
+	"github.com/digitalmonsters/notification-handler/pkg/realtime"
 	"github.com/digitalmonsters/notification-handler/pkg/renderer"
 	"github.com/digitalmonsters/notification-handler/pkg/settings"
+	"github.com/digitalmonsters/notification-handler/pkg/storage"
 	"github.com/digitalmonsters/notification-handler/pkg/token"
-	"github.com/digitalmonsters/notification-handler/pkg/utils"
-	"github.com/gocql/gocql"
 	"github.com/google/uuid"
 	"github.com/pkg/errors"
 	"github.com/robfig/cron/v3"
 	"github.com/rs/zerolog/log"
 	"go.elastic.co/apm"
 	"go.elastic.co/apm/module/apmhttp"
+	"gopkg.in/guregu/null.v4"
 )
 
 type Sender struct {
@@ -41,6 +42,7 @@ type Sender struct {
 	jobber          *machinery.Server
 	userWrapper     user_go.IUserGoWrapper
 	firebaseClient  *firebase.FirebaseClient
+	repository      storage.INotificationRepository
 }
 
 func NewSender(gateway notification_gateway.INotificationGatewayWrapper, settingsService settings.IService,
@@ -51,6 +53,7 @@ func NewSender(gateway notification_gateway.INotificationGatewayWrapper, setting
 		jobber:          jobber,
 		userWrapper:     userWrapper,
 		firebaseClient:  firebaseClient,
+		repository:      storage.GetRepository(),
 	}
 	setupNotificationCronJobs(sender)
 	sender.SendDailyAggregatedNotifications(context.Background())
@@ -172,6 +175,25 @@ func (s *Sender) processUserNotifications(userId int64, ctx context.Context) err
 		return err
 	}
 
+	// aggregated push summarizes follow and like templates, so activity of templates with muted push is left out
+	followPushMuted, err := s.settingsService.IsMuted(userId, settings.ChannelPush, followTemplateId, ctx)
+	if err != nil {
+		return err
+	}
+
+	if followPushMuted {
+		friendRequestCount, followCount = 0, 0
+	}
+
+	likePushMuted, err := s.settingsService.IsMuted(userId, settings.ChannelPush, contentLikeTemplateId, ctx)
+	if err != nil {
+		return err
+	}
+
+	if likePushMuted {
+		likesCount = 0
+	}
+
 	totalCount := friendRequestCount + followCount + likesCount
 	if totalCount == 0 {
 		tx.Rollback()
@@ -244,6 +266,11 @@ func (s *Sender) processUserNotifications(userId int64, ctx context.Context) err
 		"is_aggregated":         "true",
 	}
 
+	sendId := reservePushSendId(ctx)
+	if sendId != 0 {
+		data[pushSendIdKey] = fmt.Sprint(sendId)
+	}
+
 	// Send notification
 	fResp, err := s.firebaseClient.SendNotification(
 		ctx,
@@ -253,7 +280,7 @@ func (s *Sender) processUserNotifications(userId int64, ctx context.Context) err
 		notificationTitle,
 		"",
 		messageBuilder.String(),
-		"push.aggregate",
+		aggregatedPushType,
 		data,
 	)
 
@@ -262,6 +289,10 @@ func (s *Sender) processUserNotifications(userId int64, ctx context.Context) err
 		return err
 	}
 
+	// aggregated copy is not translated, so it is recorded with the default language
+	recordPushSends(sendId, aggregatedPushType, aggregatedPushType, translation.DefaultUserLanguage, userId, nil,
+		[]database.Device{*deviceInfo}, ctx)
+
 	log.Ctx(ctx).Info().
 		Str("response", fResp).
 		Int64("user_id", userId).
@@ -385,20 +416,17 @@ func (s *Sender) sendGroupedPush(eventType, kind string, userId int64, entityId
 		return nil
 	}
 
-	session := database.GetScyllaSession()
-
-	notificationRelationIter := session.Query("select user_id, event_applied from notification_relation where user_id = ? "+
-		"and event_type = ? and entity_id = ? and related_entity_id = ?", userId, eventType, entityId, 0).Iter()
-
-	var userIdFromSelect int64
-	var eventApplied bool
-	notificationRelationIter.Scan(&userIdFromSelect, &eventApplied)
-
-	if err = notificationRelationIter.Close(); err != nil {
+	relations, err := s.repository.GetRelations(storage.RelationFilter{
+		UserId:          userId,
+		EventType:       eventType,
+		EntityId:        null.IntFrom(entityId),
+		RelatedEntityId: null.IntFrom(0),
+	}, ctx)
+	if err != nil {
 		return errors.WithStack(err)
 	}
 
-	if userIdFromSelect > 0 && !eventApplied {
+	if len(relations) > 0 && !relations[0].EventApplied {
 		return nil
 	}
 
@@ -441,8 +469,8 @@ func (s *Sender) sendGroupedPush(eventType, kind string, userId int64, entityId
 		headline = headlineMultiple
 	}
 
-	if err = <-s.gateway.EnqueuePushForUser(s.prepareCustomPushEvents(userTokens, eventType, kind, title, body, headline, fmt.Sprint(userId), customData,
-		userId), ctx); err != nil {
+	if err = s.enqueuePush(userTokens, eventType, kind, title, body, headline, userId, customData, userData.Language,
+		ctx); err != nil {
 		return errors.WithStack(err)
 	}
 
@@ -451,8 +479,8 @@ func (s *Sender) sendGroupedPush(eventType, kind string, userId int64, entityId
 
 func (s *Sender) sendCustomPushTemplateMessageToUser(pushType, kind, title, body, headline string, userId int64,
 	customData database.CustomData, isGrouped bool, entityId int64, relatedEntityId int64, createdAt time.Time,
-	ctx context.Context) (interface{}, error) {
-	userTokens, err := token.GetUserTokens(database.GetDbWithContext(database.DbTypeReadonly, ctx), userId)
+	language translation.Language, platforms []common.DeviceType, ctx context.Context) (interface{}, error) {
+	userTokens, err := token.GetUserTokens(database.GetDbWithContext(database.DbTypeReadonly, ctx), userId, platforms...)
 
 	if err != nil {
 		return nil, errors.WithStack(err)
@@ -472,24 +500,19 @@ func (s *Sender) sendCustomPushTemplateMessageToUser(pushType, kind, title, body
 	}
 
 	if !isGrouped {
-		sendResult := <-s.gateway.EnqueuePushForUser(s.prepareCustomPushEvents(userTokens, pushType, kind, title, body, headline, fmt.Sprint(userId), customData,
-			userId), ctx)
-		return nil, sendResult
+		return nil, s.enqueuePush(userTokens, pushType, kind, title, body, headline, userId, customData, language, ctx)
 	}
 
-	session := database.GetScyllaSession()
-
-	notificationRelationIter := session.Query("select event_applied from notification_relation where user_id = ? "+
-		"and event_type = ? and entity_id = ?", userId, pushType, entityId).Iter()
-
-	var eventApplied bool
-	notificationRelationIter.Scan(&eventApplied)
-
-	if err = notificationRelationIter.Close(); err != nil {
+	relations, err := s.repository.GetRelations(storage.RelationFilter{
+		UserId:    userId,
+		EventType: pushType,
+		EntityId:  null.IntFrom(entityId),
+	}, ctx)
+	if err != nil {
 		return nil, errors.WithStack(err)
 	}
 
-	if !eventApplied {
+	if len(relations) == 0 || !relations[0].EventApplied {
 		return nil, nil
 	}
 
@@ -499,27 +522,17 @@ func (s *Sender) sendCustomPushTemplateMessageToUser(pushType, kind, title, body
 	apm_helper.AddApmLabel(apm.TransactionFromContext(ctx), "deadline_key", deadlineKeys)
 	apm_helper.AddApmLabel(apm.TransactionFromContext(ctx), "deadline", deadlines)
 
-	query := fmt.Sprintf("select deadline_key, deadline, user_id, "+
-		"event_type, entity_id, created_at, notification_count from push_notification_group_queue "+
-		"where deadline_key in (%v) and deadline in (%v) and user_id = ? and event_type = ?",
-		utils.JoinDatesForInStatement(deadlineKeys), utils.JoinDatesForInStatement(deadlines))
-
-	if relatedEntityId != 0 {
-		query = fmt.Sprintf("%v and entity_id = %v", query, entityId)
+	queueFilter := storage.GroupQueueFilter{
+		UserId:    null.IntFrom(userId),
+		EventType: null.StringFrom(pushType),
 	}
 
-	pushNotificationGroupQueueIter := session.Query(query, userId, pushType).WithContext(ctx).Iter()
-
-	pushNotificationsGroupQueue := make([]scylla.PushNotificationGroupQueue, 0)
-	var pushNotificationGroupQueue scylla.PushNotificationGroupQueue
-	for pushNotificationGroupQueueIter.Scan(&pushNotificationGroupQueue.DeadlineKey, &pushNotificationGroupQueue.Deadline,
-		&pushNotificationGroupQueue.UserId, &pushNotificationGroupQueue.EventType, &pushNotificationGroupQueue.EntityId,
-		&pushNotificationGroupQueue.CreatedAt, &pushNotificationGroupQueue.NotificationCount) {
-		pushNotificationsGroupQueue = append(pushNotificationsGroupQueue, pushNotificationGroupQueue)
-		pushNotificationGroupQueue = scylla.PushNotificationGroupQueue{}
+	if relatedEntityId != 0 {
+		queueFilter.EntityId = null.IntFrom(entityId)
 	}
 
-	if err = pushNotificationGroupQueueIter.Close(); err != nil {
+	pushNotificationsGroupQueue, err := s.repository.GetGroupQueue(deadlineKeys, deadlines, queueFilter, ctx)
+	if err != nil {
 		return nil, errors.WithStack(err)
 	}
 
@@ -528,6 +541,7 @@ func (s *Sender) sendCustomPushTemplateMessageToUser(pushType, kind, title, body
 	apm_helper.AddApmLabel(apm.TransactionFromContext(ctx), "grouped_queued_notifications_count", len(pushNotificationsGroupQueue))
 	apm_helper.AddApmLabel(apm.TransactionFromContext(ctx), "push_notifications_group_queue", pushNotificationsGroupQueue)
 
+	var pushNotificationGroupQueue scylla.PushNotificationGroupQueue
 	for _, item := range pushNotificationsGroupQueue {
 		if !flooredCreatedAt.Before(item.DeadlineKey) /* >= */ || item.Deadline.Equal(deadline) {
 			continue
@@ -537,8 +551,6 @@ func (s *Sender) sendCustomPushTemplateMessageToUser(pushType, kind, title, body
 		break
 	}
 
-	batch := session.NewBatch(gocql.UnloggedBatch).WithContext(ctx)
-
 	hasPreviousPushNotificationGroupQueueItem := pushNotificationGroupQueue.UserId != 0
 	apm_helper.AddApmLabel(apm.TransactionFromContext(ctx), "has_previous_push_notification_group_queue_item", hasPreviousPushNotificationGroupQueueItem)
 
@@ -554,11 +566,15 @@ func (s *Sender) sendCustomPushTemplateMessageToUser(pushType, kind, title, body
 			deadlineKey = deadline
 		}
 
-		batch.Query("update push_notification_group_queue set created_at = ?, notification_count = ? "+
-			"where deadline_key = ? and deadline = ? and user_id = ? and event_type = ? and entity_id = ?",
-			createdAt, 1, deadlineKey, deadline, userId, pushType, entityId)
-
-		if err = session.ExecuteBatch(batch); err != nil {
+		if err = s.repository.ExecuteBatch(storage.NewBatch().UpsertGroupQueueItem(scylla.PushNotificationGroupQueue{
+			DeadlineKey:       deadlineKey,
+			Deadline:          deadline,
+			UserId:            userId,
+			EventType:         pushType,
+			EntityId:          entityId,
+			CreatedAt:         createdAt,
+			NotificationCount: 1,
+		}), ctx); err != nil {
 			return nil, errors.WithStack(err)
 		}
 
@@ -575,19 +591,24 @@ func (s *Sender) sendCustomPushTemplateMessageToUser(pushType, kind, title, body
 
 	if relatedEntityId == 0 {
 		pushNotificationGroupQueue.EntityId = entityId
-		if err = session.Query("delete from push_notification_group_queue where deadline_key = ? and deadline = ? and "+
-			"user_id = ? and event_type = ?", pushNotificationGroupQueue.DeadlineKey, pushNotificationGroupQueue.Deadline,
-			pushNotificationGroupQueue.UserId, pushNotificationGroupQueue.EventType).Exec(); err != nil {
+
+		// separate batch, scylla ignores upsert of a row deleted in the same batch
+		if err = s.repository.ExecuteBatch(storage.NewBatch().DeleteGroupQueueItems(pushNotificationGroupQueue.DeadlineKey,
+			pushNotificationGroupQueue.Deadline, pushNotificationGroupQueue.UserId, pushNotificationGroupQueue.EventType,
+			null.Int{}), ctx); err != nil {
 			return nil, errors.WithStack(err)
 		}
 	}
 
-	batch.Query("update push_notification_group_queue set created_at = ?, notification_count = ? "+
-		"where deadline_key = ? and deadline = ? and user_id = ? and event_type = ? and entity_id = ?",
-		pushNotificationGroupQueue.CreatedAt, notificationCount, pushNotificationGroupQueue.DeadlineKey, newDeadline,
-		pushNotificationGroupQueue.UserId, pushNotificationGroupQueue.EventType, pushNotificationGroupQueue.EntityId)
-
-	if err = session.ExecuteBatch(batch); err != nil {
+	if err = s.repository.ExecuteBatch(storage.NewBatch().UpsertGroupQueueItem(scylla.PushNotificationGroupQueue{
+		DeadlineKey:       pushNotificationGroupQueue.DeadlineKey,
+		Deadline:          newDeadline,
+		UserId:            pushNotificationGroupQueue.UserId,
+		EventType:         pushNotificationGroupQueue.EventType,
+		EntityId:          pushNotificationGroupQueue.EntityId,
+		CreatedAt:         pushNotificationGroupQueue.CreatedAt,
+		NotificationCount: notificationCount,
+	}), ctx); err != nil {
 		return nil, errors.WithStack(err)
 	}
 
@@ -640,7 +661,8 @@ func (s *Sender) prepareCustomPushEvents(tokens []database.Device, pushType, kin
 
 // -
 func (s *Sender) PushNotification(notification database.Notification, imageUrl string, entityId int64, relatedEntityId int64,
-	templateName string, language translation.Language, customKind string, ctx context.Context) (shouldRetry bool, innerErr error) {
+	templateName string, language translation.Language, customKind string, platforms []common.DeviceType,
+	ctx context.Context) (shouldRetry bool, innerErr error) {
 
 	log.Ctx(ctx).Info().
 		Str("template_name", templateName).
@@ -681,6 +703,12 @@ func (s *Sender) PushNotification(notification database.Notification, imageUrl s
 
 	template.Id = templateName
 
+	inAppMuted, err := s.settingsService.IsMuted(notification.UserId, settings.ChannelInApp, templateName, ctx)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("[PushNotification] Failed to check in-app preferences")
+		return true, errors.WithStack(err)
+	}
+
 	notification.Id = uuid.New()
 	notification.CreatedAt = time.Now().UTC()
 
@@ -728,8 +756,7 @@ func (s *Sender) PushNotification(notification database.Notification, imageUrl s
 	}
 	log.Ctx(ctx).Info().Str("kind", kind).Msg("[PushNotification] Notification kind determined")
 
-	session := database.GetScyllaSession()
-	batch := session.NewBatch(gocql.UnloggedBatch).WithContext(ctx)
+	batch := storage.NewBatch()
 
 	notificationsCount := int64(1)
 	alreadySend := false
@@ -737,65 +764,58 @@ func (s *Sender) PushNotification(notification database.Notification, imageUrl s
 	if template.IsGrouped {
 		log.Ctx(ctx).Info().Msg("[PushNotification] Processing grouped notifications")
 
-		query := "select user_id, entity_id, related_entity_id from notification_relation where user_id = ? and event_type = ?"
+		relationFilter := storage.RelationFilter{
+			UserId:    notification.UserId,
+			EventType: template.Id,
+		}
+
 		if relatedEntityId != 0 {
-			query = fmt.Sprintf("%v and entity_id = %v", query, entityId)
+			relationFilter.EntityId = null.IntFrom(entityId)
 		}
 
-		notificationRelationIter := session.Query(query, notification.UserId, template.Id).WithContext(ctx).Iter()
+		var relations []scylla.NotificationRelation
+		relations, err = s.repository.GetRelations(relationFilter, ctx)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("[PushNotification] Failed to get notification relations")
+			return true, errors.WithStack(err)
+		}
 
-		var userIdSelected int64
-		var entityIdSelected int64
-		var relatedEntityIdSelected int64
-		for notificationRelationIter.Scan(&userIdSelected, &entityIdSelected, &relatedEntityIdSelected) {
-			if entityIdSelected == entityId && relatedEntityIdSelected == relatedEntityId {
+		for _, relation := range relations {
+			if relation.EntityId == entityId && relation.RelatedEntityId == relatedEntityId {
 				alreadySend = true
 			} else {
 				notificationsCount++
 			}
 		}
 
-		if err = notificationRelationIter.Close(); err != nil {
-			log.Ctx(ctx).Error().Err(err).Msg("[PushNotification] Failed to close notification relation iterator")
+		batch.SetEventApplied(notification.UserId, template.Id, entityId, relatedEntityId, true)
+
+		var previousNotifications []scylla.Notification
+		previousNotifications, err = s.repository.GetNotificationsSince(notification.UserId, template.Id,
+			notification.CreatedAt.Add(-3*24*30*time.Hour), ctx)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("[PushNotification] Failed to get previous notifications")
 			return true, errors.WithStack(err)
 		}
 
-		batch.Query("update notification_relation set event_applied = true where user_id = ? and event_type = ? "+
-			"and entity_id = ? and related_entity_id = ?", notification.UserId, template.Id, entityId, relatedEntityId)
-
-		notificationIter := session.Query("select entity_id, related_entity_id, created_at "+
-			"from notification where user_id = ? and event_type = ? and created_at >= ?",
-			notification.UserId, template.Id, notification.CreatedAt.Add(-3*24*30*time.Hour)).WithContext(ctx).Iter()
-
-		found := false
-		entityIdSelected = 0
-		relatedEntityIdSelected = 0
-		var createdAt time.Time
-
-		for notificationIter.Scan(&entityIdSelected, &relatedEntityIdSelected, &createdAt) {
-			if (relatedEntityId != 0 && entityIdSelected == entityId) || relatedEntityId == 0 {
-				found = true
-				break
+		for _, previous := range previousNotifications {
+			if relatedEntityId != 0 && previous.EntityId != entityId {
+				continue
 			}
-		}
 
-		if err = notificationIter.Close(); err != nil {
-			log.Ctx(ctx).Error().Err(err).Msg("[PushNotification] Failed to close notification iterator")
-			return true, errors.WithStack(err)
-		}
-
-		if found {
 			log.Ctx(ctx).Info().
-				Int64("entity_id", entityIdSelected).
-				Int64("related_entity_id", relatedEntityIdSelected).
+				Int64("entity_id", previous.EntityId).
+				Int64("related_entity_id", previous.RelatedEntityId).
 				Msg("[PushNotification] Found duplicate notification, deleting")
-			batch.Query("delete from notification where user_id = ? and event_type = ? and created_at = ? and entity_id = ? and related_entity_id = ?",
-				notification.UserId, template.Id, createdAt, entityIdSelected, relatedEntityIdSelected)
-			if err = s.UpdateCreatedAtInGroupQueue(notification.UserId, template.Id, entityIdSelected,
-				relatedEntityIdSelected, notification.CreatedAt, ctx); err != nil {
+			batch.DeleteNotification(notification.UserId, template.Id, previous.CreatedAt, previous.EntityId,
+				previous.RelatedEntityId)
+			if err = s.UpdateCreatedAtInGroupQueue(notification.UserId, template.Id, previous.EntityId,
+				previous.RelatedEntityId, notification.CreatedAt, ctx); err != nil {
 				log.Ctx(ctx).Error().Err(err).Msg("[PushNotification] Failed to update createdAt in group queue")
 				return true, errors.WithStack(err)
 			}
+
+			break
 		}
 	}
 
@@ -838,71 +858,88 @@ func (s *Sender) PushNotification(notification database.Notification, imageUrl s
 		headline = headlineMultiple
 	}
 
-	batch.Query("update notification set notifications_count = ?, title = ?, body = ?, headline = ?, kind = ?, rendering_variables = ?, "+
-		"custom_data = ?, notification_info = ? where user_id = ? and event_type = ? "+
-		"and created_at = ? and entity_id = ? and related_entity_id = ?", notificationsCount, title, body, headline,
-		kind, string(renderingVariablesMarshalled), string(customDataMarshalled), string(notificationInfoMarshalled),
-		notification.UserId, template.Id, notification.CreatedAt, entityId, relatedEntityId)
-
-	if err = session.ExecuteBatch(batch); err != nil {
+	if !inAppMuted {
+		batch.UpsertNotification(scylla.Notification{
+			UserId:             notification.UserId,
+			EventType:          template.Id,
+			EntityId:           entityId,
+			RelatedEntityId:    relatedEntityId,
+			CreatedAt:          notification.CreatedAt,
+			NotificationsCount: notificationsCount,
+			Title:              title,
+			Body:               body,
+			Headline:           headline,
+			Kind:               kind,
+			RenderingVariables: string(renderingVariablesMarshalled),
+			CustomData:         string(customDataMarshalled),
+			NotificationInfo:   string(notificationInfoMarshalled),
+		})
+	}
+
+	if err = s.repository.ExecuteBatch(batch, ctx); err != nil {
 		return true, errors.WithStack(err)
 	}
 
-	tx := database.GetDb(database.DbTypeMaster).WithContext(ctx).Begin()
-	defer tx.Rollback()
+	if !inAppMuted {
+		tx := database.GetDb(database.DbTypeMaster).WithContext(ctx).Begin()
+		defer tx.Rollback()
 
-	if notification.Type == "push.profile.following" {
-		if notification.Message == "Someone  started following you" {
-			notification.Message = "Someone started following you"
+		if notification.Type == "push.profile.following" {
+			if notification.Message == "Someone  started following you" {
+				notification.Message = "Someone started following you"
+			}
 		}
-	}
 
-	if notification.Type == "push.profile.following" {
-		var deletedCount int64
-		result := tx.Exec(`
-			DELETE FROM notifications 
-			WHERE user_id = ? 
-			AND related_user_id = ? 
-			AND type = ? 
-			AND created_at >= ?`,
-			notification.UserId,
-			notification.RelatedUserId,
-			notification.Type,
-			time.Now().Add(-24*time.Hour),
-		)
+		if notification.Type == "push.profile.following" {
+			var deletedCount int64
+			result := tx.Exec(`
+				DELETE FROM notifications 
+				WHERE user_id = ? 
+				AND related_user_id = ? 
+				AND type = ? 
+				AND created_at >= ?`,
+				notification.UserId,
+				notification.RelatedUserId,
+				notification.Type,
+				time.Now().Add(-24*time.Hour),
+			)
+
+			if result.Error != nil {
+				log.Ctx(ctx).Error().Err(result.Error).Msg("[PushNotification] Failed to delete existing notifications")
+				return true, result.Error
+			}
 
-		if result.Error != nil {
-			log.Ctx(ctx).Error().Err(result.Error).Msg("[PushNotification] Failed to delete existing notifications")
-			return true, result.Error
+			deletedCount = result.RowsAffected
+			if deletedCount > 0 {
+				log.Ctx(ctx).Info().
+					Int64("deleted_count", deletedCount).
+					Int64("user_id", notification.UserId).
+					Msg("[PushNotification] Deleted existing notifications")
+			}
 		}
 
-		deletedCount = result.RowsAffected
-		if deletedCount > 0 {
-			log.Ctx(ctx).Info().
-				Int64("deleted_count", deletedCount).
-				Int64("user_id", notification.UserId).
-				Msg("[PushNotification] Deleted existing notifications")
+		if notification.Type == "push.profile.following" || notification.Type == "push.content.like" {
+			notification.AggregatedSent = false
+		} else {
+			notification.AggregatedSent = true
+		}
+		if err = tx.Create(&notification).Error; err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("[PushNotification] Failed to create notification")
+			return true, err
 		}
-	}
 
-	if notification.Type == "push.profile.following" || notification.Type == "push.content.like" {
-		notification.AggregatedSent = false
-	} else {
-		notification.AggregatedSent = true
-	}
-	if err = tx.Create(&notification).Error; err != nil {
-		log.Ctx(ctx).Error().Err(err).Msg("[PushNotification] Failed to create notification")
-		return true, err
-	}
+		if err = notificationPkg.IncrementUnreadNotificationsCounter(tx, notification.UserId); err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("[PushNotification] Failed to increment unread notifications counter")
+			return true, err
+		}
 
-	if err = notificationPkg.IncrementUnreadNotificationsCounter(tx, notification.UserId); err != nil {
-		log.Ctx(ctx).Error().Err(err).Msg("[PushNotification] Failed to increment unread notifications counter")
-		return true, err
-	}
+		if err = tx.Commit().Error; err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("[PushNotification] Failed to commit transaction")
+			return true, errors.WithStack(err)
+		}
 
-	if err = tx.Commit().Error; err != nil {
-		log.Ctx(ctx).Error().Err(err).Msg("[PushNotification] Failed to commit transaction")
-		return true, errors.WithStack(err)
+		realtime.PublishNotification(notification.UserId, notification, ctx)
+		notificationPkg.PublishUnreadNotificationsCount(database.GetDbWithContext(database.DbTypeMaster, ctx), notification.UserId, ctx)
 	}
 
 	if !alreadySend {
@@ -911,7 +948,7 @@ func (s *Sender) PushNotification(notification database.Notification, imageUrl s
 			Str("template_id", template.Id).
 			Msg("[PushNotification] Sending custom push notification")
 		if _, err = s.sendCustomPushTemplateMessageToUser(template.Id, kind, title, body, "", notification.UserId, notification.CustomData, template.IsGrouped,
-			entityId, relatedEntityId, notification.CreatedAt, ctx); err != nil {
+			entityId, relatedEntityId, notification.CreatedAt, language, platforms, ctx); err != nil {
 			log.Ctx(ctx).Error().Err(err).Msg("[PushNotification] Failed to send custom push notification")
 			return true, errors.WithStack(err)
 		}
@@ -924,10 +961,11 @@ func (s *Sender) PushNotification(notification database.Notification, imageUrl s
 		notification.Type == "push.user.need.upload" || notification.Type == "push.user.need.avatar"
 
 	if isAggregationEligible || isSendDirectly {
-		deviceInfo, err := notificationPkg.GetLatestDeviceForUser(int(notification.UserId), database.GetDbWithContext(database.DbTypeMaster, ctx))
+		deviceInfo, err := notificationPkg.GetLatestDeviceForUser(int(notification.UserId),
+			database.GetDbWithContext(database.DbTypeMaster, ctx), platforms...)
 		if err != nil {
 			log.Ctx(ctx).Error().Err(err).Msg("[PushNotification] Failed to get token for firebase")
-			return false, nil
+			return true, errors.WithStack(err)
 		}
 
 		if deviceInfo.PushToken != "" {
@@ -936,9 +974,23 @@ func (s *Sender) PushNotification(notification database.Notification, imageUrl s
 					Msg("[PushNotification] Skipping aggregation, recently sent")
 				return false, nil
 			}
+
+			isMuted, err := s.settingsService.IsMuted(notification.UserId, settings.ChannelPush, templateName, ctx)
+			if err != nil {
+				log.Ctx(ctx).Error().Err(err).Msg("[PushNotification] Failed to check push preferences")
+				return true, errors.WithStack(err)
+			}
+
+			if isMuted {
+				return false, nil
+			}
+
+			sendId := reservePushSendId(ctx)
+			customData := withPushSendId(notification.CustomData, sendId)
+
 			// Now send the notification (either aggregated or individual)
 			data := make(map[string]string)
-			for k, v := range notification.CustomData {
+			for k, v := range customData {
 				switch value := v.(type) {
 				case string:
 					data[k] = value
@@ -961,11 +1013,14 @@ func (s *Sender) PushNotification(notification database.Notification, imageUrl s
 			if err != nil {
 				log.Info().Msgf("firebase-reponse fail %v for user-id %v for token %v", fResp, notification.UserId, deviceInfo.PushToken)
 				log.Ctx(ctx).Error().Err(err).Msg("[PushNotification] Failed to sent notification on firebase")
-				return false, nil
+				return false, errors.Wrap(err, "can not send push with firebase")
 			}
 			log.Info().Msgf("firebase-reponse success %v for user-id %v for token %v", fResp, notification.UserId, deviceInfo.PushToken)
 			log.Info().Msgf("firebase-reponse %v", fResp)
 			log.Info().Msg("Push notification firebase successfully")
+
+			recordPushSends(sendId, templateName, kind, language, notification.UserId, customData,
+				[]database.Device{*deviceInfo}, ctx)
 		}
 	}
 
@@ -978,41 +1033,31 @@ func (s *Sender) PushNotification(notification database.Notification, imageUrl s
 
 func (s *Sender) UpdateCreatedAtInGroupQueue(userId int64, eventType string, entityId int64, relatedEntityId int64,
 	newCreatedAt time.Time, ctx context.Context) error {
-	session := database.GetScyllaSession()
-
 	deadlineKeys, deadlines := GetDeadlinesForSelect(newCreatedAt)
 
-	query := fmt.Sprintf("select deadline_key, deadline, user_id, event_type, entity_id, created_at, "+
-		"notification_count from push_notification_group_queue where deadline_key in (%v) and deadline in (%v) and user_id = ? "+
-		"and event_type = ?", utils.JoinDatesForInStatement(deadlineKeys), utils.JoinDatesForInStatement(deadlines))
+	queueFilter := storage.GroupQueueFilter{
+		UserId:    null.IntFrom(userId),
+		EventType: null.StringFrom(eventType),
+	}
 
 	if relatedEntityId != 0 {
-		query = fmt.Sprintf("%v and entity_id = %v", query, entityId)
+		queueFilter.EntityId = null.IntFrom(entityId)
 	}
 
-	iter := session.Query(query, userId, eventType).WithContext(ctx).Iter()
-
-	pushNotificationGroupQueue := scylla.PushNotificationGroupQueue{}
+	items, err := s.repository.GetGroupQueue(deadlineKeys, deadlines, queueFilter, ctx)
+	if err != nil {
+		return errors.WithStack(err)
+	}
 
-	batch := session.NewBatch(gocql.UnloggedBatch).WithContext(ctx)
+	batch := storage.NewBatch()
 
-	for iter.Scan(&pushNotificationGroupQueue.DeadlineKey, &pushNotificationGroupQueue.Deadline,
-		&pushNotificationGroupQueue.UserId, &pushNotificationGroupQueue.EventType,
-		&pushNotificationGroupQueue.EntityId, &pushNotificationGroupQueue.CreatedAt,
-		&pushNotificationGroupQueue.NotificationCount) {
+	for _, item := range items {
 		// need to correct select by updated created_at before send push
-		batch.Query("update push_notification_group_queue set created_at = ?, notification_count = ? "+
-			"where deadline_key = ? and deadline = ? and user_id = ? and event_type = ? and entity_id = ?",
-			newCreatedAt, pushNotificationGroupQueue.NotificationCount,
-			pushNotificationGroupQueue.DeadlineKey, pushNotificationGroupQueue.Deadline, pushNotificationGroupQueue.UserId,
-			pushNotificationGroupQueue.EventType, pushNotificationGroupQueue.EntityId)
+		item.CreatedAt = newCreatedAt
+		batch.UpsertGroupQueueItem(item)
 	}
 
-	if err := iter.Close(); err != nil {
-		return errors.WithStack(err)
-	}
-
-	if err := session.ExecuteBatch(batch); err != nil {
+	if err = s.repository.ExecuteBatch(batch, ctx); err != nil {
 		return errors.WithStack(err)
 	}
 
@@ -1043,28 +1088,13 @@ func GetDeadlinesForSelect(fromDate time.Time) (deadlineKeys []time.Time, deadli
 }
 
 func (s *Sender) CheckPushNotificationDeadlineMinutes(currentDate time.Time, ctx context.Context) error {
-	session := database.GetScyllaSession()
-
 	deadlineKeys, deadlines := GetDeadlinesForSelect(currentDate)
 
 	apm_helper.AddApmLabel(apm.TransactionFromContext(ctx), "deadline_key", deadlineKeys)
 	apm_helper.AddApmLabel(apm.TransactionFromContext(ctx), "deadline", deadlines)
 
-	pushNotificationGroupQueueIter := session.Query(fmt.Sprintf("select deadline_key, deadline, user_id, "+
-		"event_type, entity_id, created_at, notification_count from push_notification_group_queue "+
-		"where deadline_key in (%v) and deadline in (%v)",
-		utils.JoinDatesForInStatement(deadlineKeys), utils.JoinDatesForInStatement(deadlines))).WithContext(ctx).Iter()
-
-	pushNotificationsGroupQueue := make([]scylla.PushNotificationGroupQueue, 0)
-	var pushNotificationGroupQueue scylla.PushNotificationGroupQueue
-	for pushNotificationGroupQueueIter.Scan(&pushNotificationGroupQueue.DeadlineKey, &pushNotificationGroupQueue.Deadline,
-		&pushNotificationGroupQueue.UserId, &pushNotificationGroupQueue.EventType, &pushNotificationGroupQueue.EntityId,
-		&pushNotificationGroupQueue.CreatedAt, &pushNotificationGroupQueue.NotificationCount) {
-		pushNotificationsGroupQueue = append(pushNotificationsGroupQueue, pushNotificationGroupQueue)
-		pushNotificationGroupQueue = scylla.PushNotificationGroupQueue{}
-	}
-
-	if err := pushNotificationGroupQueueIter.Close(); err != nil {
+	pushNotificationsGroupQueue, err := s.repository.GetGroupQueue(deadlineKeys, deadlines, storage.GroupQueueFilter{}, ctx)
+	if err != nil {
 		return errors.WithStack(err)
 	}
 
@@ -1102,44 +1132,18 @@ func (s *Sender) CheckPushNotificationDeadlineMinutes(currentDate time.Time, ctx
 
 func (s *Sender) getNotificationForGroupSend(userId int64, eventType string, createdAt time.Time, entityId int64,
 	ctx context.Context) (*scylla.Notification, error) {
-	session := database.GetScyllaSession()
-
-	notificationIter := session.Query("select user_id, related_entity_id, title, body, headline, kind, rendering_variables, custom_data "+
-		"from notification where user_id = ? and event_type = ? and created_at = ? and entity_id = ? limit 1",
-		userId, eventType, createdAt, entityId).WithContext(ctx).Iter()
-
-	notification := scylla.Notification{
-		UserId:    userId,
-		EventType: eventType,
-		EntityId:  entityId,
-		CreatedAt: createdAt,
-	}
-
-	var userIdFromSelect int64
-	notificationIter.Scan(&userIdFromSelect, &notification.RelatedEntityId, &notification.Title, &notification.Body,
-		&notification.Headline, &notification.Kind, &notification.RenderingVariables, &notification.CustomData)
-
-	if err := notificationIter.Close(); err != nil {
+	notification, err := s.repository.GetNotification(userId, eventType, createdAt, entityId, ctx)
+	if err != nil {
 		return nil, errors.WithStack(err)
 	}
 
-	if userIdFromSelect == 0 { // should do nothing
-		return nil, nil
-	}
-
-	return &notification, nil
+	return notification, nil
 }
 
 func (s *Sender) deleteNotificationFromQueue(deadlineKey time.Time, deadline time.Time, userId int64, eventType string,
 	entityId int64, ctx context.Context) error {
-	session := database.GetScyllaSession()
-	batch := session.NewBatch(gocql.UnloggedBatch).WithContext(ctx)
-
-	batch.Query("delete from push_notification_group_queue where deadline_key = ? and deadline = ? "+
-		"and user_id = ? and event_type = ? and entity_id = ?", deadlineKey, deadline, userId,
-		eventType, entityId)
-
-	if err := session.ExecuteBatch(batch); err != nil {
+	if err := s.repository.ExecuteBatch(storage.NewBatch().DeleteGroupQueueItems(deadlineKey, deadline, userId,
+		eventType, null.IntFrom(entityId)), ctx); err != nil {
 		return errors.WithStack(err)
 	}
 
@@ -1319,10 +1323,8 @@ func (s *Sender) RegisterUserPushNotificationTasks() error {
 }
 
 func (s *Sender) UnapplyEvent(userId int64, eventType string, entityId int64, relatedEntityId int64, ctx context.Context) error {
-	session := database.GetScyllaSession()
-
-	if err := session.Query("update notification_relation set event_applied = false where user_id = ? and "+
-		"event_type = ? and entity_id = ? and related_entity_id = ?", userId, eventType, entityId, relatedEntityId).WithContext(ctx).Exec(); err != nil {
+	if err := s.repository.ExecuteBatch(storage.NewBatch().SetEventApplied(userId, eventType, entityId, relatedEntityId,
+		false), ctx); err != nil {
 		return errors.WithStack(err)
 	}
 
@@ -1371,6 +1373,12 @@ func (s *Sender) PushPet2Notification(notification database.Notification, entity
 
 	template.Id = templateName
 
+	inAppMuted, err := s.settingsService.IsMuted(notification.UserId, settings.ChannelInApp, templateName, ctx)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("[PushNotification] Failed to check in-app preferences")
+		return true, errors.WithStack(err)
+	}
+
 	notification.Id = uuid.New()
 	notification.CreatedAt = time.Now().UTC()
 
@@ -1418,8 +1426,7 @@ func (s *Sender) PushPet2Notification(notification database.Notification, entity
 	}
 	log.Ctx(ctx).Info().Str("kind", kind).Msg("[PushNotification] Notification kind determined")
 
-	session := database.GetScyllaSession()
-	batch := session.NewBatch(gocql.UnloggedBatch).WithContext(ctx)
+	batch := storage.NewBatch()
 
 	notificationsCount := int64(1)
 	alreadySend := false
@@ -1427,65 +1434,58 @@ func (s *Sender) PushPet2Notification(notification database.Notification, entity
 	if template.IsGrouped {
 		log.Ctx(ctx).Info().Msg("[PushNotification] Processing grouped notifications")
 
-		query := "select user_id, entity_id, related_entity_id from notification_relation where user_id = ? and event_type = ?"
+		relationFilter := storage.RelationFilter{
+			UserId:    notification.UserId,
+			EventType: template.Id,
+		}
+
 		if relatedEntityId != 0 {
-			query = fmt.Sprintf("%v and entity_id = %v", query, entityId)
+			relationFilter.EntityId = null.IntFrom(entityId)
 		}
 
-		notificationRelationIter := session.Query(query, notification.UserId, template.Id).WithContext(ctx).Iter()
+		var relations []scylla.NotificationRelation
+		relations, err = s.repository.GetRelations(relationFilter, ctx)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("[PushNotification] Failed to get notification relations")
+			return true, errors.WithStack(err)
+		}
 
-		var userIdSelected int64
-		var entityIdSelected int64
-		var relatedEntityIdSelected int64
-		for notificationRelationIter.Scan(&userIdSelected, &entityIdSelected, &relatedEntityIdSelected) {
-			if entityIdSelected == entityId && relatedEntityIdSelected == relatedEntityId {
+		for _, relation := range relations {
+			if relation.EntityId == entityId && relation.RelatedEntityId == relatedEntityId {
 				alreadySend = true
 			} else {
 				notificationsCount++
 			}
 		}
 
-		if err = notificationRelationIter.Close(); err != nil {
-			log.Ctx(ctx).Error().Err(err).Msg("[PushNotification] Failed to close notification relation iterator")
+		batch.SetEventApplied(notification.UserId, template.Id, entityId, relatedEntityId, true)
+
+		var previousNotifications []scylla.Notification
+		previousNotifications, err = s.repository.GetNotificationsSince(notification.UserId, template.Id,
+			notification.CreatedAt.Add(-3*24*30*time.Hour), ctx)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("[PushNotification] Failed to get previous notifications")
 			return true, errors.WithStack(err)
 		}
 
-		batch.Query("update notification_relation set event_applied = true where user_id = ? and event_type = ? "+
-			"and entity_id = ? and related_entity_id = ?", notification.UserId, template.Id, entityId, relatedEntityId)
-
-		notificationIter := session.Query("select entity_id, related_entity_id, created_at "+
-			"from notification where user_id = ? and event_type = ? and created_at >= ?",
-			notification.UserId, template.Id, notification.CreatedAt.Add(-3*24*30*time.Hour)).WithContext(ctx).Iter()
-
-		found := false
-		entityIdSelected = 0
-		relatedEntityIdSelected = 0
-		var createdAt time.Time
-
-		for notificationIter.Scan(&entityIdSelected, &relatedEntityIdSelected, &createdAt) {
-			if (relatedEntityId != 0 && entityIdSelected == entityId) || relatedEntityId == 0 {
-				found = true
-				break
+		for _, previous := range previousNotifications {
+			if relatedEntityId != 0 && previous.EntityId != entityId {
+				continue
 			}
-		}
 
-		if err = notificationIter.Close(); err != nil {
-			log.Ctx(ctx).Error().Err(err).Msg("[PushNotification] Failed to close notification iterator")
-			return true, errors.WithStack(err)
-		}
-
-		if found {
 			log.Ctx(ctx).Info().
-				Int64("entity_id", entityIdSelected).
-				Int64("related_entity_id", relatedEntityIdSelected).
+				Int64("entity_id", previous.EntityId).
+				Int64("related_entity_id", previous.RelatedEntityId).
 				Msg("[PushNotification] Found duplicate notification, deleting")
-			batch.Query("delete from notification where user_id = ? and event_type = ? and created_at = ? and entity_id = ? and related_entity_id = ?",
-				notification.UserId, template.Id, createdAt, entityIdSelected, relatedEntityIdSelected)
-			if err = s.UpdateCreatedAtInGroupQueue(notification.UserId, template.Id, entityIdSelected,
-				relatedEntityIdSelected, notification.CreatedAt, ctx); err != nil {
+			batch.DeleteNotification(notification.UserId, template.Id, previous.CreatedAt, previous.EntityId,
+				previous.RelatedEntityId)
+			if err = s.UpdateCreatedAtInGroupQueue(notification.UserId, template.Id, previous.EntityId,
+				previous.RelatedEntityId, notification.CreatedAt, ctx); err != nil {
 				log.Ctx(ctx).Error().Err(err).Msg("[PushNotification] Failed to update createdAt in group queue")
 				return true, errors.WithStack(err)
 			}
+
+			break
 		}
 	}
 
@@ -1528,32 +1528,49 @@ func (s *Sender) PushPet2Notification(notification database.Notification, entity
 		headline = headlineMultiple
 	}
 
-	batch.Query("update notification set notifications_count = ?, title = ?, body = ?, headline = ?, kind = ?, rendering_variables = ?, "+
-		"custom_data = ?, notification_info = ? where user_id = ? and event_type = ? "+
-		"and created_at = ? and entity_id = ? and related_entity_id = ?", notificationsCount, title, body, headline,
-		kind, string(renderingVariablesMarshalled), string(customDataMarshalled), string(notificationInfoMarshalled),
-		notification.UserId, template.Id, notification.CreatedAt, entityId, relatedEntityId)
-
-	if err = session.ExecuteBatch(batch); err != nil {
+	if !inAppMuted {
+		batch.UpsertNotification(scylla.Notification{
+			UserId:             notification.UserId,
+			EventType:          template.Id,
+			EntityId:           entityId,
+			RelatedEntityId:    relatedEntityId,
+			CreatedAt:          notification.CreatedAt,
+			NotificationsCount: notificationsCount,
+			Title:              title,
+			Body:               body,
+			Headline:           headline,
+			Kind:               kind,
+			RenderingVariables: string(renderingVariablesMarshalled),
+			CustomData:         string(customDataMarshalled),
+			NotificationInfo:   string(notificationInfoMarshalled),
+		})
+	}
+
+	if err = s.repository.ExecuteBatch(batch, ctx); err != nil {
 		return true, errors.WithStack(err)
 	}
 
-	tx := database.GetDb(database.DbTypeMaster).WithContext(ctx).Begin()
-	defer tx.Rollback()
+	if !inAppMuted {
+		tx := database.GetDb(database.DbTypeMaster).WithContext(ctx).Begin()
+		defer tx.Rollback()
 
-	if err = tx.Create(&notification).Error; err != nil {
-		log.Ctx(ctx).Error().Err(err).Msg("[PushNotification] Failed to create notification")
-		return true, err
-	}
+		if err = tx.Create(&notification).Error; err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("[PushNotification] Failed to create notification")
+			return true, err
+		}
 
-	if err = notificationPkg.IncrementUnreadNotificationsCounter(tx, notification.UserId); err != nil {
-		log.Ctx(ctx).Error().Err(err).Msg("[PushNotification] Failed to increment unread notifications counter")
-		return true, err
-	}
+		if err = notificationPkg.IncrementUnreadNotificationsCounter(tx, notification.UserId); err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("[PushNotification] Failed to increment unread notifications counter")
+			return true, err
+		}
 
-	if err = tx.Commit().Error; err != nil {
-		log.Ctx(ctx).Error().Err(err).Msg("[PushNotification] Failed to commit transaction")
-		return true, errors.WithStack(err)
+		if err = tx.Commit().Error; err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("[PushNotification] Failed to commit transaction")
+			return true, errors.WithStack(err)
+		}
+
+		realtime.PublishNotification(notification.UserId, notification, ctx)
+		notificationPkg.PublishUnreadNotificationsCount(database.GetDbWithContext(database.DbTypeMaster, ctx), notification.UserId, ctx)
 	}
 
 	if !alreadySend {
@@ -1562,7 +1579,7 @@ func (s *Sender) PushPet2Notification(notification database.Notification, entity
 			Str("template_id", template.Id).
 			Msg("[PushNotification] Sending custom push notification")
 		if _, err = s.sendCustomPushTemplateMessageToUser(template.Id, kind, title, body, "", notification.UserId, notification.CustomData, template.IsGrouped,
-			entityId, relatedEntityId, notification.CreatedAt, ctx); err != nil {
+			entityId, relatedEntityId, notification.CreatedAt, language, nil, ctx); err != nil {
 			log.Ctx(ctx).Error().Err(err).Msg("[PushNotification] Failed to send custom push notification")
 			return true, errors.WithStack(err)
 		}
@@ -1612,20 +1629,17 @@ func (s *Sender) sendPet2GroupedPush(eventType, kind string, userId int64, entit
 		return nil
 	}
 
-	session := database.GetScyllaSession()
-
-	notificationRelationIter := session.Query("select user_id, event_applied from notification_relation where user_id = ? "+
-		"and event_type = ? and entity_id = ? and related_entity_id = ?", userId, eventType, entityId, 0).Iter()
-
-	var userIdFromSelect int64
-	var eventApplied bool
-	notificationRelationIter.Scan(&userIdFromSelect, &eventApplied)
-
-	if err = notificationRelationIter.Close(); err != nil {
+	relations, err := s.repository.GetRelations(storage.RelationFilter{
+		UserId:          userId,
+		EventType:       eventType,
+		EntityId:        null.IntFrom(entityId),
+		RelatedEntityId: null.IntFrom(0),
+	}, ctx)
+	if err != nil {
 		return errors.WithStack(err)
 	}
 
-	if userIdFromSelect > 0 && !eventApplied {
+	if len(relations) > 0 && !relations[0].EventApplied {
 		return nil
 	}
 
@@ -1668,8 +1682,8 @@ func (s *Sender) sendPet2GroupedPush(eventType, kind string, userId int64, entit
 		headline = headlineMultiple
 	}
 
-	if err = <-s.gateway.EnqueuePushForUser(s.prepareCustomPushEvents(userTokens, eventType, kind, title, body, headline, fmt.Sprint(userId), customData,
-		userId), ctx); err != nil {
+	if err = s.enqueuePush(userTokens, eventType, kind, title, body, headline, userId, customData, userData.Language,
+		ctx); err != nil {
 		return errors.WithStack(err)
 	}
 
@@ -1678,7 +1692,7 @@ func (s *Sender) sendPet2GroupedPush(eventType, kind string, userId int64, entit
 
 func (s *Sender) sendCustomPushPet2TemplateMessageToUser(pushType, kind, title, body, headline string, userId int64,
 	customData database.CustomData, isGrouped bool, entityId int64, relatedEntityId int64, createdAt time.Time,
-	ctx context.Context) (interface{}, error) {
+	language translation.Language, ctx context.Context) (interface{}, error) {
 	userTokens, err := token.GetUserTokens(database.GetDbWithContext(database.DbTypeReadonly, ctx), userId)
 
 	if err != nil {
@@ -1699,24 +1713,19 @@ func (s *Sender) sendCustomPushPet2TemplateMessageToUser(pushType, kind, title,
 	}
 
 	if !isGrouped {
-		sendResult := <-s.gateway.EnqueuePushForUser(s.prepareCustomPushEvents(userTokens, pushType, kind, title, body, headline, fmt.Sprint(userId), customData,
-			userId), ctx)
-		return nil, sendResult
+		return nil, s.enqueuePush(userTokens, pushType, kind, title, body, headline, userId, customData, language, ctx)
 	}
 
-	session := database.GetScyllaSession()
-
-	notificationRelationIter := session.Query("select event_applied from notification_relation where user_id = ? "+
-		"and event_type = ? and entity_id = ?", userId, pushType, entityId).Iter()
-
-	var eventApplied bool
-	notificationRelationIter.Scan(&eventApplied)
-
-	if err = notificationRelationIter.Close(); err != nil {
+	relations, err := s.repository.GetRelations(storage.RelationFilter{
+		UserId:    userId,
+		EventType: pushType,
+		EntityId:  null.IntFrom(entityId),
+	}, ctx)
+	if err != nil {
 		return nil, errors.WithStack(err)
 	}
 
-	if !eventApplied {
+	if len(relations) == 0 || !relations[0].EventApplied {
 		return nil, nil
 	}
 
@@ -1726,27 +1735,17 @@ func (s *Sender) sendCustomPushPet2TemplateMessageToUser(pushType, kind, title,
 	apm_helper.AddApmLabel(apm.TransactionFromContext(ctx), "deadline_key", deadlineKeys)
 	apm_helper.AddApmLabel(apm.TransactionFromContext(ctx), "deadline", deadlines)
 
-	query := fmt.Sprintf("select deadline_key, deadline, user_id, "+
-		"event_type, entity_id, created_at, notification_count from push_notification_group_queue "+
-		"where deadline_key in (%v) and deadline in (%v) and user_id = ? and event_type = ?",
-		utils.JoinDatesForInStatement(deadlineKeys), utils.JoinDatesForInStatement(deadlines))
-
-	if relatedEntityId != 0 {
-		query = fmt.Sprintf("%v and entity_id = %v", query, entityId)
+	queueFilter := storage.GroupQueueFilter{
+		UserId:    null.IntFrom(userId),
+		EventType: null.StringFrom(pushType),
 	}
 
-	pushNotificationGroupQueueIter := session.Query(query, userId, pushType).WithContext(ctx).Iter()
-
-	pushNotificationsGroupQueue := make([]scylla.PushNotificationGroupQueue, 0)
-	var pushNotificationGroupQueue scylla.PushNotificationGroupQueue
-	for pushNotificationGroupQueueIter.Scan(&pushNotificationGroupQueue.DeadlineKey, &pushNotificationGroupQueue.Deadline,
-		&pushNotificationGroupQueue.UserId, &pushNotificationGroupQueue.EventType, &pushNotificationGroupQueue.EntityId,
-		&pushNotificationGroupQueue.CreatedAt, &pushNotificationGroupQueue.NotificationCount) {
-		pushNotificationsGroupQueue = append(pushNotificationsGroupQueue, pushNotificationGroupQueue)
-		pushNotificationGroupQueue = scylla.PushNotificationGroupQueue{}
+	if relatedEntityId != 0 {
+		queueFilter.EntityId = null.IntFrom(entityId)
 	}
 
-	if err = pushNotificationGroupQueueIter.Close(); err != nil {
+	pushNotificationsGroupQueue, err := s.repository.GetGroupQueue(deadlineKeys, deadlines, queueFilter, ctx)
+	if err != nil {
 		return nil, errors.WithStack(err)
 	}
 
@@ -1755,6 +1754,7 @@ func (s *Sender) sendCustomPushPet2TemplateMessageToUser(pushType, kind, title,
 	apm_helper.AddApmLabel(apm.TransactionFromContext(ctx), "grouped_queued_notifications_count", len(pushNotificationsGroupQueue))
 	apm_helper.AddApmLabel(apm.TransactionFromContext(ctx), "push_notifications_group_queue", pushNotificationsGroupQueue)
 
+	var pushNotificationGroupQueue scylla.PushNotificationGroupQueue
 	for _, item := range pushNotificationsGroupQueue {
 		if !flooredCreatedAt.Before(item.DeadlineKey) /* >= */ || item.Deadline.Equal(deadline) {
 			continue
@@ -1764,8 +1764,6 @@ func (s *Sender) sendCustomPushPet2TemplateMessageToUser(pushType, kind, title,
 		break
 	}
 
-	batch := session.NewBatch(gocql.UnloggedBatch).WithContext(ctx)
-
 	hasPreviousPushNotificationGroupQueueItem := pushNotificationGroupQueue.UserId != 0
 	apm_helper.AddApmLabel(apm.TransactionFromContext(ctx), "has_previous_push_notification_group_queue_item", hasPreviousPushNotificationGroupQueueItem)
 
@@ -1781,11 +1779,15 @@ func (s *Sender) sendCustomPushPet2TemplateMessageToUser(pushType, kind, title,
 			deadlineKey = deadline
 		}
 
-		batch.Query("update push_notification_group_queue set created_at = ?, notification_count = ? "+
-			"where deadline_key = ? and deadline = ? and user_id = ? and event_type = ? and entity_id = ?",
-			createdAt, 1, deadlineKey, deadline, userId, pushType, entityId)
-
-		if err = session.ExecuteBatch(batch); err != nil {
+		if err = s.repository.ExecuteBatch(storage.NewBatch().UpsertGroupQueueItem(scylla.PushNotificationGroupQueue{
+			DeadlineKey:       deadlineKey,
+			Deadline:          deadline,
+			UserId:            userId,
+			EventType:         pushType,
+			EntityId:          entityId,
+			CreatedAt:         createdAt,
+			NotificationCount: 1,
+		}), ctx); err != nil {
 			return nil, errors.WithStack(err)
 		}
 
@@ -1802,19 +1804,24 @@ func (s *Sender) sendCustomPushPet2TemplateMessageToUser(pushType, kind, title,
 
 	if relatedEntityId == 0 {
 		pushNotificationGroupQueue.EntityId = entityId
-		if err = session.Query("delete from push_notification_group_queue where deadline_key = ? and deadline = ? and "+
-			"user_id = ? and event_type = ?", pushNotificationGroupQueue.DeadlineKey, pushNotificationGroupQueue.Deadline,
-			pushNotificationGroupQueue.UserId, pushNotificationGroupQueue.EventType).Exec(); err != nil {
+
+		// separate batch, scylla ignores upsert of a row deleted in the same batch
+		if err = s.repository.ExecuteBatch(storage.NewBatch().DeleteGroupQueueItems(pushNotificationGroupQueue.DeadlineKey,
+			pushNotificationGroupQueue.Deadline, pushNotificationGroupQueue.UserId, pushNotificationGroupQueue.EventType,
+			null.Int{}), ctx); err != nil {
 			return nil, errors.WithStack(err)
 		}
 	}
 
-	batch.Query("update push_notification_group_queue set created_at = ?, notification_count = ? "+
-		"where deadline_key = ? and deadline = ? and user_id = ? and event_type = ? and entity_id = ?",
-		pushNotificationGroupQueue.CreatedAt, notificationCount, pushNotificationGroupQueue.DeadlineKey, newDeadline,
-		pushNotificationGroupQueue.UserId, pushNotificationGroupQueue.EventType, pushNotificationGroupQueue.EntityId)
-
-	if err = session.ExecuteBatch(batch); err != nil {
+	if err = s.repository.ExecuteBatch(storage.NewBatch().UpsertGroupQueueItem(scylla.PushNotificationGroupQueue{
+		DeadlineKey:       pushNotificationGroupQueue.DeadlineKey,
+		Deadline:          newDeadline,
+		UserId:            pushNotificationGroupQueue.UserId,
+		EventType:         pushNotificationGroupQueue.EventType,
+		EntityId:          pushNotificationGroupQueue.EntityId,
+		CreatedAt:         pushNotificationGroupQueue.CreatedAt,
+		NotificationCount: notificationCount,
+	}), ctx); err != nil {
 		return nil, errors.WithStack(err)
 	}
 
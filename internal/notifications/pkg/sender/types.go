@@ -3,6 +3,7 @@ package sender
 import (
 	"context"
 
+	"github.com/digitalmonsters/go-common/common"
 	"github.com/digitalmonsters/go-common/translation"
 	"github.com/digitalmonsters/go-common/wrappers/notification_gateway"
 	"github.com/digitalmonsters/notification-handler/pkg/database"
@@ -10,7 +11,9 @@ import (
 
 type ISender interface {
 	SendEmail(msg []notification_gateway.SendEmailMessageRequest, ctx context.Context) error
+	// PushNotification sends templated or custom push. When platforms are set, push goes only to devices of these platforms
 	PushNotification(notification database.Notification, imageUrl string, entityId int64, relatedEntityId int64,
-		templateName string, language translation.Language, customKind string, ctx context.Context) (shouldRetry bool, innerErr error)
+		templateName string, language translation.Language, customKind string, platforms []common.DeviceType,
+		ctx context.Context) (shouldRetry bool, innerErr error)
 	UnapplyEvent(userId int64, eventType string, entityId int64, relatedEntityId int64, ctx context.Context) error
 }
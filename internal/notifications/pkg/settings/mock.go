@@ -2,6 +2,7 @@ package settings
 
 import (
 	"context"
+	"github.com/digitalmonsters/go-common/callback"
 	"gorm.io/gorm"
 )
 
@@ -11,6 +12,9 @@ type ServiceMock struct {
 		db *gorm.DB) (map[string]GetPushSettingsByAdminItem, error)
 	ChangePushSettingsFn      func(settings map[string]bool, userId int64, ctx context.Context) error
 	IsPushNotificationMutedFn func(userId int64, templateId string, ctx context.Context) (bool, error)
+	GetPreferencesFn          func(userId int64, ctx context.Context, db *gorm.DB) (*GetPreferencesResponse, error)
+	ChangePreferencesFn       func(req ChangePreferencesRequest, userId int64, ctx context.Context, tx *gorm.DB) ([]callback.Callback, error)
+	IsMutedFn                 func(userId int64, channel Channel, templateId string, ctx context.Context) (bool, error)
 }
 
 func (s *ServiceMock) GetPushSettings(userId int64, ctx context.Context, db *gorm.DB) (map[string]bool, error) {
@@ -29,6 +33,19 @@ func (s *ServiceMock) IsPushNotificationMuted(userId int64, templateId string, c
 	return s.IsPushNotificationMutedFn(userId, templateId, ctx)
 }
 
+func (s *ServiceMock) GetPreferences(userId int64, ctx context.Context, db *gorm.DB) (*GetPreferencesResponse, error) {
+	return s.GetPreferencesFn(userId, ctx, db)
+}
+
+func (s *ServiceMock) ChangePreferences(req ChangePreferencesRequest, userId int64, ctx context.Context,
+	tx *gorm.DB) ([]callback.Callback, error) {
+	return s.ChangePreferencesFn(req, userId, ctx, tx)
+}
+
+func (s *ServiceMock) IsMuted(userId int64, channel Channel, templateId string, ctx context.Context) (bool, error) {
+	return s.IsMutedFn(userId, channel, templateId, ctx)
+}
+
 func GetMock() IService {
 	return &ServiceMock{}
 }
@@ -0,0 +1,374 @@
+package settings
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/digitalmonsters/go-common/callback"
+	"github.com/digitalmonsters/go-common/eventsourcing"
+	"github.com/digitalmonsters/notification-handler/pkg/database"
+	"github.com/patrickmn/go-cache"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// templateInfo describes channels a template is delivered to and whether each channel is enabled by default
+type templateInfo struct {
+	id       string
+	category string
+	defaults map[Channel]bool
+}
+
+// emailTemplates are sent by email consumer and have no render template, template id is the email event type
+var emailTemplates = []templateInfo{
+	newEmailTemplateInfo(eventsourcing.EmailNotificationPasswordForgot, database.CategoryAccount),
+	newEmailTemplateInfo(eventsourcing.EmailNotificationConfirmAddress, database.CategoryAccount),
+	newEmailTemplateInfo(eventsourcing.EmailNotificationReferral, database.CategoryRewards),
+	newEmailTemplateInfo(eventsourcing.EmailGuestTempInfo, database.CategoryAccount),
+}
+
+func newEmailTemplateInfo(emailType eventsourcing.EmailNotificationType, category string) templateInfo {
+	return templateInfo{
+		id:       string(emailType),
+		category: category,
+		defaults: map[Channel]bool{ChannelEmail: true},
+	}
+}
+
+func newTemplateInfo(template database.RenderTemplate) templateInfo {
+	return templateInfo{
+		id:       template.Id,
+		category: template.Category,
+		defaults: map[Channel]bool{
+			ChannelPush:  template.DefaultPush,
+			ChannelInApp: template.DefaultInApp,
+		},
+	}
+}
+
+func isCategoryMutable(category string) bool {
+	return category != database.CategoryAccount
+}
+
+func isKnownChannel(channel Channel) bool {
+	for _, c := range Channels {
+		if c == channel {
+			return true
+		}
+	}
+
+	return false
+}
+
+// userPreferences holds user overrides. Category mute wins over template overrides, template override wins over
+// template default
+type userPreferences struct {
+	categories map[Channel]map[string]bool
+	templates  map[Channel]map[string]bool
+}
+
+func (p userPreferences) resolve(template templateInfo, channel Channel) (ChannelPreference, bool) {
+	defaultEnabled, supported := template.defaults[channel]
+	if !supported {
+		return ChannelPreference{}, false
+	}
+
+	pref := ChannelPreference{
+		Enabled: defaultEnabled,
+		Default: defaultEnabled,
+		Source:  PreferenceSourceDefault,
+	}
+
+	if !isCategoryMutable(template.category) {
+		pref.Enabled = true
+		pref.Source = PreferenceSourceLocked
+
+		return pref, true
+	}
+
+	if p.categories[channel][template.category] {
+		pref.Enabled = false
+		pref.Source = PreferenceSourceCategory
+
+		return pref, true
+	}
+
+	if muted, ok := p.templates[channel][template.id]; ok {
+		pref.Enabled = !muted
+		pref.Source = PreferenceSourceTemplate
+	}
+
+	return pref, true
+}
+
+func (s service) getTemplateInfos(db *gorm.DB) (map[string]templateInfo, error) {
+	templates, err := s.getTemplates(db)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make(map[string]templateInfo, len(templates)+len(emailTemplates))
+
+	for id, template := range templates {
+		infos[id] = newTemplateInfo(template)
+	}
+
+	for _, template := range emailTemplates {
+		infos[template.id] = template
+	}
+
+	return infos, nil
+}
+
+func (s service) getUserPreferences(userId int64, ctx context.Context, db *gorm.DB) (*userPreferences, error) {
+	var items []database.UserNotificationPreference
+
+	if err := db.WithContext(ctx).Where("user_id = ?", userId).Find(&items).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	prefs := &userPreferences{
+		categories: make(map[Channel]map[string]bool),
+		templates:  make(map[Channel]map[string]bool),
+	}
+
+	for _, channel := range Channels {
+		prefs.categories[channel] = make(map[string]bool)
+		prefs.templates[channel] = make(map[string]bool)
+	}
+
+	for _, item := range items {
+		channel := Channel(item.Channel)
+		if !isKnownChannel(channel) {
+			continue
+		}
+
+		switch item.Scope {
+		case database.PreferenceScopeCategory:
+			prefs.categories[channel][item.ScopeKey] = item.Muted
+		case database.PreferenceScopeTemplate:
+			prefs.templates[channel][item.ScopeKey] = item.Muted
+		}
+	}
+
+	// push overrides of templates were stored before preferences were introduced and are still kept there
+	pushSettings, err := s.repository.GetSettings(userId, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for templateId, muted := range pushSettings {
+		prefs.templates[ChannelPush][templateId] = muted
+	}
+
+	return prefs, nil
+}
+
+// getCachedUserPreferences is used on delivery, where preferences of the same user are checked for every message.
+// Changes made on other instances are seen after cache expiration
+func (s service) getCachedUserPreferences(userId int64, ctx context.Context, db *gorm.DB) (*userPreferences, error) {
+	key := strconv.FormatInt(userId, 10)
+
+	if cached, ok := s.preferencesCache.Get(key); ok {
+		return cached.(*userPreferences), nil
+	}
+
+	prefs, err := s.getUserPreferences(userId, ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	s.preferencesCache.Set(key, prefs, cache.DefaultExpiration)
+
+	return prefs, nil
+}
+
+// GetPreferences returns resolved state of every channel of every template grouped by category
+func (s service) GetPreferences(userId int64, ctx context.Context, db *gorm.DB) (*GetPreferencesResponse, error) {
+	templates, err := s.getTemplateInfos(db)
+	if err != nil {
+		return nil, err
+	}
+
+	prefs, err := s.getUserPreferences(userId, ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	templatesByCategory := make(map[string][]templateInfo)
+	for _, template := range templates {
+		templatesByCategory[template.category] = append(templatesByCategory[template.category], template)
+	}
+
+	resp := &GetPreferencesResponse{
+		Channels:   Channels,
+		Categories: make([]CategoryPreferences, 0, len(database.Categories)),
+	}
+
+	for _, category := range database.Categories {
+		categoryTemplates := templatesByCategory[category]
+		if len(categoryTemplates) == 0 {
+			continue
+		}
+
+		sort.Slice(categoryTemplates, func(i, j int) bool {
+			return categoryTemplates[i].id < categoryTemplates[j].id
+		})
+
+		item := CategoryPreferences{
+			Category:  category,
+			Mutable:   isCategoryMutable(category),
+			Muted:     make(map[Channel]bool, len(Channels)),
+			Templates: make([]TemplatePreferences, 0, len(categoryTemplates)),
+		}
+
+		for _, channel := range Channels {
+			item.Muted[channel] = item.Mutable && prefs.categories[channel][category]
+		}
+
+		for _, template := range categoryTemplates {
+			templatePrefs := TemplatePreferences{
+				TemplateId: template.id,
+				Channels:   make(map[Channel]ChannelPreference, len(template.defaults)),
+			}
+
+			for _, channel := range Channels {
+				if pref, ok := prefs.resolve(template, channel); ok {
+					templatePrefs.Channels[channel] = pref
+				}
+			}
+
+			item.Templates = append(item.Templates, templatePrefs)
+		}
+
+		resp.Categories = append(resp.Categories, item)
+	}
+
+	return resp, nil
+}
+
+// ChangePreferences saves overrides in tx. Push overrides of templates live in settings repository, which may be
+// outside of postgres, so they are written by callbacks after commit
+func (s service) ChangePreferences(req ChangePreferencesRequest, userId int64, ctx context.Context,
+	tx *gorm.DB) ([]callback.Callback, error) {
+	templates, err := s.getTemplateInfos(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	items := make([]database.UserNotificationPreference, 0, len(req.Categories)+len(req.Templates))
+	pushSettings := make(map[string]bool)
+
+	for _, change := range req.Categories {
+		if !isKnownChannel(change.Channel) {
+			return nil, errors.WithStack(errors.Errorf("unknown channel %v", change.Channel))
+		}
+
+		if !database.IsKnownCategory(change.Category) {
+			return nil, errors.WithStack(errors.Errorf("unknown category %v", change.Category))
+		}
+
+		if !isCategoryMutable(change.Category) {
+			return nil, errors.WithStack(errors.Errorf("category %v can not be muted", change.Category))
+		}
+
+		items = append(items, database.UserNotificationPreference{
+			UserId:    userId,
+			Channel:   string(change.Channel),
+			Scope:     database.PreferenceScopeCategory,
+			ScopeKey:  change.Category,
+			Muted:     change.Muted,
+			UpdatedAt: now,
+		})
+	}
+
+	for _, change := range req.Templates {
+		if !isKnownChannel(change.Channel) {
+			return nil, errors.WithStack(errors.Errorf("unknown channel %v", change.Channel))
+		}
+
+		template, ok := templates[change.TemplateId]
+		if !ok {
+			return nil, errors.WithStack(errors.Errorf("template %v not found", change.TemplateId))
+		}
+
+		if _, supported := template.defaults[change.Channel]; !supported {
+			return nil, errors.WithStack(errors.Errorf("template %v is not sent to %v", change.TemplateId, change.Channel))
+		}
+
+		if !isCategoryMutable(template.category) {
+			return nil, errors.WithStack(errors.Errorf("template %v can not be muted", change.TemplateId))
+		}
+
+		if change.Channel == ChannelPush {
+			pushSettings[change.TemplateId] = change.Muted
+			continue
+		}
+
+		items = append(items, database.UserNotificationPreference{
+			UserId:    userId,
+			Channel:   string(change.Channel),
+			Scope:     database.PreferenceScopeTemplate,
+			ScopeKey:  change.TemplateId,
+			Muted:     change.Muted,
+			UpdatedAt: now,
+		})
+	}
+
+	if len(items) > 0 {
+		if err = tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}, {Name: "channel"}, {Name: "scope"}, {Name: "scope_key"}},
+			DoUpdates: clause.AssignmentColumns([]string{"muted", "updated_at"}),
+		}).Create(&items).Error; err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+
+	return []callback.Callback{
+		func(ctx context.Context) error {
+			defer s.preferencesCache.Delete(strconv.FormatInt(userId, 10))
+
+			return s.repository.SetSettings(userId, pushSettings, ctx)
+		},
+	}, nil
+}
+
+// IsMuted tells whether template should not be delivered to user by the channel. Templates without render template,
+// like custom admin pushes, are treated as system ones enabled by default
+func (s service) IsMuted(userId int64, channel Channel, templateId string, ctx context.Context) (bool, error) {
+	db := database.GetDbWithContext(database.DbTypeReadonly, ctx)
+
+	templates, err := s.getTemplateInfos(db)
+	if err != nil {
+		return false, err
+	}
+
+	template, ok := templates[templateId]
+	if !ok {
+		template = templateInfo{
+			id:       templateId,
+			category: database.CategorySystem,
+			defaults: map[Channel]bool{ChannelPush: true, ChannelInApp: true, ChannelEmail: true},
+		}
+	}
+
+	if _, supported := template.defaults[channel]; supported && !isCategoryMutable(template.category) {
+		return false, nil
+	}
+
+	prefs, err := s.getCachedUserPreferences(userId, ctx, db)
+	if err != nil {
+		return false, err
+	}
+
+	pref, supported := prefs.resolve(template, channel)
+	if !supported {
+		return true, nil
+	}
+
+	return !pref.Enabled, nil
+}
@@ -0,0 +1,247 @@
+package settings
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/digitalmonsters/go-common/boilerplate_testing"
+	"github.com/digitalmonsters/notification-handler/configs"
+	"github.com/digitalmonsters/notification-handler/pkg/database"
+	"github.com/digitalmonsters/notification-handler/pkg/storage"
+	"github.com/patrickmn/go-cache"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+var config configs.Settings
+var gormDb *gorm.DB
+
+func TestMain(m *testing.M) {
+	config = configs.GetConfig()
+	gormDb = database.GetDb(database.DbTypeMaster)
+	os.Exit(m.Run())
+}
+
+var socialTemplate = templateInfo{
+	id:       "preferences_social",
+	category: database.CategorySocial,
+	defaults: map[Channel]bool{ChannelPush: true, ChannelInApp: false},
+}
+
+var accountTemplate = templateInfo{
+	id:       "preferences_account",
+	category: database.CategoryAccount,
+	defaults: map[Channel]bool{ChannelPush: true, ChannelInApp: true},
+}
+
+func newUserPreferences() userPreferences {
+	prefs := userPreferences{
+		categories: make(map[Channel]map[string]bool),
+		templates:  make(map[Channel]map[string]bool),
+	}
+
+	for _, channel := range Channels {
+		prefs.categories[channel] = make(map[string]bool)
+		prefs.templates[channel] = make(map[string]bool)
+	}
+
+	return prefs
+}
+
+func TestUserPreferences_Resolve(t *testing.T) {
+	prefs := newUserPreferences()
+
+	pref, supported := prefs.resolve(socialTemplate, ChannelPush)
+	assert.True(t, supported)
+	assert.Equal(t, ChannelPreference{Enabled: true, Default: true, Source: PreferenceSourceDefault}, pref)
+
+	pref, supported = prefs.resolve(socialTemplate, ChannelInApp)
+	assert.True(t, supported)
+	assert.Equal(t, ChannelPreference{Enabled: false, Default: false, Source: PreferenceSourceDefault}, pref)
+
+	_, supported = prefs.resolve(socialTemplate, ChannelEmail)
+	assert.False(t, supported)
+
+	prefs.templates[ChannelPush][socialTemplate.id] = true
+	prefs.templates[ChannelInApp][socialTemplate.id] = false
+
+	pref, _ = prefs.resolve(socialTemplate, ChannelPush)
+	assert.Equal(t, ChannelPreference{Enabled: false, Default: true, Source: PreferenceSourceTemplate}, pref)
+
+	pref, _ = prefs.resolve(socialTemplate, ChannelInApp)
+	assert.Equal(t, ChannelPreference{Enabled: true, Default: false, Source: PreferenceSourceTemplate}, pref)
+}
+
+func TestUserPreferences_Resolve_CategoryOverTemplate(t *testing.T) {
+	prefs := newUserPreferences()
+
+	// template was explicitly enabled, but the whole category is muted later
+	prefs.templates[ChannelInApp][socialTemplate.id] = false
+	prefs.categories[ChannelInApp][database.CategorySocial] = true
+
+	pref, _ := prefs.resolve(socialTemplate, ChannelInApp)
+	assert.Equal(t, ChannelPreference{Enabled: false, Default: false, Source: PreferenceSourceCategory}, pref)
+
+	// category mute is per channel
+	pref, _ = prefs.resolve(socialTemplate, ChannelPush)
+	assert.Equal(t, PreferenceSourceDefault, pref.Source)
+	assert.True(t, pref.Enabled)
+
+	prefs.categories[ChannelInApp][database.CategorySocial] = false
+
+	pref, _ = prefs.resolve(socialTemplate, ChannelInApp)
+	assert.Equal(t, ChannelPreference{Enabled: true, Default: false, Source: PreferenceSourceTemplate}, pref)
+}
+
+func TestUserPreferences_Resolve_AccountLocked(t *testing.T) {
+	prefs := newUserPreferences()
+
+	prefs.categories[ChannelPush][database.CategoryAccount] = true
+	prefs.templates[ChannelInApp][accountTemplate.id] = true
+
+	for _, channel := range []Channel{ChannelPush, ChannelInApp} {
+		pref, supported := prefs.resolve(accountTemplate, channel)
+		assert.True(t, supported)
+		assert.Equal(t, ChannelPreference{Enabled: true, Default: true, Source: PreferenceSourceLocked}, pref)
+	}
+}
+
+func newTestService() *service {
+	return &service{
+		templatesCache:   cache.New(10*time.Minute, 10*time.Minute),
+		preferencesCache: cache.New(time.Minute, 10*time.Minute),
+		repository:       storage.NewPostgresRepository(gormDb),
+	}
+}
+
+func createTemplates(t *testing.T) {
+	if err := boilerplate_testing.FlushPostgresTables(config.MasterDb, []string{"public.user_notification_preferences",
+		"public.user_notifications_settings"}, nil, t); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, template := range []templateInfo{socialTemplate, accountTemplate} {
+		if err := gormDb.Save(&database.RenderTemplate{
+			Id:           template.id,
+			Category:     template.category,
+			DefaultPush:  template.defaults[ChannelPush],
+			DefaultInApp: template.defaults[ChannelInApp],
+			CreatedAt:    time.Now().UTC(),
+			UpdatedAt:    time.Now().UTC(),
+		}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestService_ChangePreferences(t *testing.T) {
+	createTemplates(t)
+
+	s := newTestService()
+	ctx := context.Background()
+
+	muted, err := s.IsMuted(1, ChannelPush, socialTemplate.id, ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.False(t, muted)
+
+	tx := gormDb.Begin()
+	defer tx.Rollback()
+
+	callbacks, err := s.ChangePreferences(ChangePreferencesRequest{
+		Categories: []CategoryPreferenceChange{{Category: database.CategorySocial, Channel: ChannelInApp, Muted: true}},
+		Templates:  []TemplatePreferenceChange{{TemplateId: socialTemplate.id, Channel: ChannelPush, Muted: true}},
+	}, 1, ctx, tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// push overrides are not written before commit
+	pushSettings, err := s.repository.GetSettings(1, ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, pushSettings, 0)
+
+	if err = tx.Commit().Error; err != nil {
+		t.Fatal(err)
+	}
+
+	for _, callbackFn := range callbacks {
+		assert.Nil(t, callbackFn(ctx))
+	}
+
+	pushSettings, err = s.repository.GetSettings(1, ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, map[string]bool{socialTemplate.id: true}, pushSettings)
+
+	// cached preferences are dropped by callbacks
+	muted, err = s.IsMuted(1, ChannelPush, socialTemplate.id, ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, muted)
+
+	muted, err = s.IsMuted(1, ChannelInApp, socialTemplate.id, ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, muted)
+}
+
+func TestService_ChangePreferences_AccountLocked(t *testing.T) {
+	createTemplates(t)
+
+	s := newTestService()
+	ctx := context.Background()
+
+	tx := gormDb.Begin()
+	defer tx.Rollback()
+
+	_, err := s.ChangePreferences(ChangePreferencesRequest{
+		Categories: []CategoryPreferenceChange{{Category: database.CategoryAccount, Channel: ChannelPush, Muted: true}},
+	}, 1, ctx, tx)
+	assert.NotNil(t, err)
+
+	_, err = s.ChangePreferences(ChangePreferencesRequest{
+		Templates: []TemplatePreferenceChange{{TemplateId: accountTemplate.id, Channel: ChannelInApp, Muted: true}},
+	}, 1, ctx, tx)
+	assert.NotNil(t, err)
+
+	// overrides stored before the category was locked are ignored
+	if err = gormDb.Create(&database.UserNotificationPreference{
+		UserId:    1,
+		Channel:   string(ChannelPush),
+		Scope:     database.PreferenceScopeCategory,
+		ScopeKey:  database.CategoryAccount,
+		Muted:     true,
+		UpdatedAt: time.Now().UTC(),
+	}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	muted, err := s.IsMuted(1, ChannelPush, accountTemplate.id, ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.False(t, muted)
+
+	resp, err := s.GetPreferences(1, ctx, gormDb)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, category := range resp.Categories {
+		if category.Category != database.CategoryAccount {
+			continue
+		}
+
+		assert.False(t, category.Mutable)
+		assert.False(t, category.Muted[ChannelPush])
+	}
+}
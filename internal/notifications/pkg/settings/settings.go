@@ -2,12 +2,15 @@ package settings
 
 import (
 	"context"
+	"strconv"
+	"time"
+
+	"github.com/digitalmonsters/go-common/callback"
 	"github.com/digitalmonsters/notification-handler/pkg/database"
-	"github.com/gocql/gocql"
+	"github.com/digitalmonsters/notification-handler/pkg/storage"
 	"github.com/patrickmn/go-cache"
 	"github.com/pkg/errors"
 	"gorm.io/gorm"
-	"time"
 )
 
 type IService interface {
@@ -16,54 +19,35 @@ type IService interface {
 		db *gorm.DB) (map[string]GetPushSettingsByAdminItem, error)
 	ChangePushSettings(settings map[string]bool, userId int64, ctx context.Context) error
 	IsPushNotificationMuted(userId int64, templateId string, ctx context.Context) (bool, error)
+	GetPreferences(userId int64, ctx context.Context, db *gorm.DB) (*GetPreferencesResponse, error)
+	ChangePreferences(req ChangePreferencesRequest, userId int64, ctx context.Context,
+		tx *gorm.DB) ([]callback.Callback, error)
+	IsMuted(userId int64, channel Channel, templateId string, ctx context.Context) (bool, error)
 }
 
 type service struct {
-	templatesCache *cache.Cache
+	templatesCache   *cache.Cache
+	preferencesCache *cache.Cache // user preferences used by IsMuted, dropped on change
+	repository       storage.ISettingsRepository
 }
 
 func NewService() IService {
 	return &service{
-		templatesCache: cache.New(10*time.Minute, 10*time.Minute),
+		templatesCache:   cache.New(10*time.Minute, 10*time.Minute),
+		preferencesCache: cache.New(time.Minute, 10*time.Minute),
+		repository:       storage.GetRepository(),
 	}
 }
 
 func (s service) GetPushSettings(userId int64, ctx context.Context, db *gorm.DB) (map[string]bool, error) {
-	session := database.GetScyllaSession()
-
-	iter := session.Query("select template_id, muted from user_notifications_settings where "+
-		"cluster_key = ? and user_id = ?", database.GetUserNotificationsSettingsClusterKey(userId), userId).
-		WithContext(ctx).Iter()
-
-	settingsMap := make(map[string]bool)
-
-	var templateId string
-	var muted bool
-
-	for iter.Scan(&templateId, &muted) {
-		settingsMap[templateId] = muted
-	}
-
-	if err := iter.Close(); err != nil {
-		return nil, errors.WithStack(err)
+	settingsMap, err := s.repository.GetSettings(userId, ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	templatesMap := make(map[string]database.RenderTemplate)
-
-	for id, template := range s.templatesCache.Items() {
-		templatesMap[id] = template.Object.(database.RenderTemplate)
-	}
-
-	if len(templatesMap) == 0 {
-		var templates []database.RenderTemplate
-		if err := db.Find(&templates).Error; err != nil {
-			return nil, errors.WithStack(err)
-		}
-
-		for _, template := range templates {
-			templatesMap[template.Id] = template
-			s.templatesCache.Set(template.Id, template, 0)
-		}
+	templatesMap, err := s.getTemplates(db)
+	if err != nil {
+		return nil, err
 	}
 
 	for id := range templatesMap {
@@ -77,48 +61,26 @@ func (s service) GetPushSettings(userId int64, ctx context.Context, db *gorm.DB)
 
 func (s service) GetPushSettingsByAdmin(req GetPushSettingsByAdminRequest, ctx context.Context,
 	db *gorm.DB) (map[string]GetPushSettingsByAdminItem, error) {
-	session := database.GetScyllaSession()
-
-	iter := session.Query("select template_id, muted from user_notifications_settings where "+
-		"cluster_key = ? and user_id = ?", database.GetUserNotificationsSettingsClusterKey(req.UserId), req.UserId).
-		WithContext(ctx).Iter()
+	userSettings, err := s.repository.GetSettings(req.UserId, ctx)
+	if err != nil {
+		return nil, err
+	}
 
 	settingsMap := make(map[string]GetPushSettingsByAdminItem)
 
-	var templateId string
-	var muted bool
-
-	for iter.Scan(&templateId, &muted) {
+	for templateId, muted := range userSettings {
 		settingsMap[templateId] = GetPushSettingsByAdminItem{Muted: muted}
 	}
 
-	if err := iter.Close(); err != nil {
-		return nil, errors.WithStack(err)
-	}
-
-	templatesMap := make(map[string]database.RenderTemplate)
-
-	for id, template := range s.templatesCache.Items() {
-		templatesMap[id] = template.Object.(database.RenderTemplate)
-	}
-
-	if len(templatesMap) == 0 {
-		var templates []database.RenderTemplate
-		if err := db.Find(&templates).Error; err != nil {
-			return nil, errors.WithStack(err)
-		}
-
-		for _, template := range templates {
-			templatesMap[template.Id] = template
-			s.templatesCache.Set(template.Id, template, 0)
-		}
+	templatesMap, err := s.getTemplates(db)
+	if err != nil {
+		return nil, err
 	}
 
 	for id, template := range templatesMap {
 		if v, ok := settingsMap[id]; !ok {
 			settingsMap[id] = GetPushSettingsByAdminItem{
 				RenderTemplate: template,
-				Muted:          muted,
 			}
 		} else {
 			v.RenderTemplate = template
@@ -130,51 +92,37 @@ func (s service) GetPushSettingsByAdmin(req GetPushSettingsByAdminRequest, ctx c
 }
 
 func (s service) ChangePushSettings(settings map[string]bool, userId int64, ctx context.Context) error {
-	session := database.GetScyllaSession()
-
-	var currentBatch = session.NewBatch(gocql.UnloggedBatch).WithContext(ctx)
-	countStatements := 0
-
-	for templateId, muted := range settings {
-		currentBatch.Query("update user_notifications_settings set  muted = ? where cluster_key = ? and user_id = ? and template_id = ?",
-			muted, database.GetUserNotificationsSettingsClusterKey(userId), userId, templateId)
-		countStatements++
-
-		if countStatements == 130 {
-			if err := session.ExecuteBatch(currentBatch); err != nil {
-				return errors.WithStack(err)
-			}
-
-			countStatements = 0
-			currentBatch = session.NewBatch(gocql.UnloggedBatch).WithContext(ctx)
-		}
+	if err := s.repository.SetSettings(userId, settings, ctx); err != nil {
+		return err
 	}
 
-	if countStatements != 0 {
-		if err := session.ExecuteBatch(currentBatch); err != nil {
-			return errors.WithStack(err)
-		}
-	}
+	s.preferencesCache.Delete(strconv.FormatInt(userId, 10))
 
 	return nil
 }
 
 func (s service) IsPushNotificationMuted(userId int64, templateId string, ctx context.Context) (bool, error) {
-	session := database.GetScyllaSession()
-
-	iter := session.Query("select muted from user_notifications_settings where "+
-		"cluster_key = ? and user_id = ? and template_id = ?", database.GetUserNotificationsSettingsClusterKey(userId), userId, templateId).
-		WithContext(ctx).Iter()
+	return s.IsMuted(userId, ChannelPush, templateId, ctx)
+}
 
-	var muted bool
+func (s service) getTemplates(db *gorm.DB) (map[string]database.RenderTemplate, error) {
+	templatesMap := make(map[string]database.RenderTemplate)
 
-	for iter.Scan(&muted) {
-		break
+	for id, template := range s.templatesCache.Items() {
+		templatesMap[id] = template.Object.(database.RenderTemplate)
 	}
 
-	if err := iter.Close(); err != nil {
-		return false, errors.WithStack(err)
+	if len(templatesMap) == 0 {
+		var templates []database.RenderTemplate
+		if err := db.Find(&templates).Error; err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		for _, template := range templates {
+			templatesMap[template.Id] = template
+			s.templatesCache.Set(template.Id, template, 0)
+		}
 	}
 
-	return muted, nil
+	return templatesMap, nil
 }
@@ -23,3 +23,71 @@ type ChangePushSettingsByAdminRequest struct {
 	ChangeSettingsRequest
 	UserId int64 `json:"user_id"`
 }
+
+type Channel string
+
+const (
+	ChannelPush  Channel = "push"
+	ChannelInApp Channel = "in_app"
+	ChannelEmail Channel = "email"
+)
+
+var Channels = []Channel{ChannelPush, ChannelInApp, ChannelEmail}
+
+type PreferenceSource string
+
+const (
+	PreferenceSourceDefault  PreferenceSource = "default"
+	PreferenceSourceTemplate PreferenceSource = "template"
+	PreferenceSourceCategory PreferenceSource = "category"
+	PreferenceSourceLocked   PreferenceSource = "locked"
+)
+
+type ChannelPreference struct {
+	Enabled bool             `json:"enabled"`
+	Default bool             `json:"default"`
+	Source  PreferenceSource `json:"source"`
+}
+
+type TemplatePreferences struct {
+	TemplateId string                        `json:"template_id"`
+	Channels   map[Channel]ChannelPreference `json:"channels"`
+}
+
+type CategoryPreferences struct {
+	Category  string                `json:"category"`
+	Mutable   bool                  `json:"mutable"`
+	Muted     map[Channel]bool      `json:"muted"`
+	Templates []TemplatePreferences `json:"templates"`
+}
+
+type GetPreferencesResponse struct {
+	Channels   []Channel             `json:"channels"`
+	Categories []CategoryPreferences `json:"categories"`
+}
+
+type CategoryPreferenceChange struct {
+	Category string  `json:"category"`
+	Channel  Channel `json:"channel"`
+	Muted    bool    `json:"muted"`
+}
+
+type TemplatePreferenceChange struct {
+	TemplateId string  `json:"template_id"`
+	Channel    Channel `json:"channel"`
+	Muted      bool    `json:"muted"`
+}
+
+type ChangePreferencesRequest struct {
+	Categories []CategoryPreferenceChange `json:"categories"`
+	Templates  []TemplatePreferenceChange `json:"templates"`
+}
+
+type GetPreferencesByAdminRequest struct {
+	UserId int64 `json:"user_id"`
+}
+
+type ChangePreferencesByAdminRequest struct {
+	ChangePreferencesRequest
+	UserId int64 `json:"user_id"`
+}
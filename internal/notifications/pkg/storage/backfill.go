@@ -0,0 +1,305 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/digitalmonsters/notification-handler/configs"
+	"github.com/digitalmonsters/notification-handler/pkg/database"
+	"github.com/gocql/gocql"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/guregu/null.v4"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type BackfillRequest struct {
+	Tables    []string // scylla tables to copy, all when empty
+	BatchSize int
+	Reset     bool // start from the beginning even if table was already copied
+}
+
+type BackfillTableResult struct {
+	Table       string    `json:"table"`
+	CopiedCount int64     `json:"copied_count"`
+	CompletedAt null.Time `json:"completed_at"`
+}
+
+// backfillTable copies a scylla table page by page. Rows already written by dual write are kept untouched
+type backfillTable struct {
+	name       string
+	query      string
+	onConflict clause.OnConflict
+	readPage   func(iter *gocql.Iter, now time.Time) (rows interface{}, count int)
+}
+
+var backfillTables = []backfillTable{
+	{
+		name: "notification",
+		query: "select user_id, event_type, created_at, entity_id, related_entity_id, notifications_count, title, body, " +
+			"headline, kind, rendering_variables, custom_data, notification_info, ttl(notification_info) from notification",
+		onConflict: clause.OnConflict{DoNothing: true},
+		readPage: func(iter *gocql.Iter, now time.Time) (interface{}, int) {
+			items := make([]database.NotificationFeedItem, 0)
+
+			var n database.NotificationFeedItem
+			var ttl *int
+
+			for iter.Scan(&n.UserId, &n.EventType, &n.CreatedAt, &n.EntityId, &n.RelatedEntityId, &n.NotificationsCount,
+				&n.Title, &n.Body, &n.Headline, &n.Kind, &n.RenderingVariables, &n.CustomData, &n.NotificationInfo, &ttl) {
+				n.ExpiresAt = ttlToExpiresAt(ttl, now, NotificationTtl)
+				items = append(items, n)
+
+				n = database.NotificationFeedItem{}
+				ttl = nil
+			}
+
+			return &items, len(items)
+		},
+	},
+	{
+		name: "notification_relation",
+		query: "select user_id, event_type, entity_id, related_entity_id, event_applied, ttl(event_applied) " +
+			"from notification_relation",
+		onConflict: clause.OnConflict{DoNothing: true},
+		readPage: func(iter *gocql.Iter, now time.Time) (interface{}, int) {
+			items := make([]database.NotificationRelation, 0)
+
+			var rel database.NotificationRelation
+			var ttl *int
+
+			for iter.Scan(&rel.UserId, &rel.EventType, &rel.EntityId, &rel.RelatedEntityId, &rel.EventApplied, &ttl) {
+				rel.ExpiresAt = ttlToExpiresAt(ttl, now, RelationTtl)
+				items = append(items, rel)
+
+				rel = database.NotificationRelation{}
+				ttl = nil
+			}
+
+			return &items, len(items)
+		},
+	},
+	{
+		name: "push_notification_group_queue",
+		query: "select deadline_key, deadline, user_id, event_type, entity_id, created_at, notification_count, " +
+			"ttl(notification_count) from push_notification_group_queue",
+		onConflict: clause.OnConflict{DoNothing: true},
+		readPage: func(iter *gocql.Iter, now time.Time) (interface{}, int) {
+			items := make([]database.PushNotificationGroupQueueItem, 0)
+
+			var item database.PushNotificationGroupQueueItem
+			var ttl *int
+
+			for iter.Scan(&item.DeadlineKey, &item.Deadline, &item.UserId, &item.EventType, &item.EntityId,
+				&item.CreatedAt, &item.NotificationCount, &ttl) {
+				item.ExpiresAt = ttlToExpiresAt(ttl, now, GroupQueueTtl)
+				items = append(items, item)
+
+				item = database.PushNotificationGroupQueueItem{}
+				ttl = nil
+			}
+
+			return &items, len(items)
+		},
+	},
+	{
+		name:       "user_notifications_settings",
+		query:      "select user_id, template_id, muted from user_notifications_settings",
+		onConflict: clause.OnConflict{DoNothing: true},
+		readPage: func(iter *gocql.Iter, now time.Time) (interface{}, int) {
+			items := make([]database.UserNotificationSetting, 0)
+
+			var setting database.UserNotificationSetting
+
+			for iter.Scan(&setting.UserId, &setting.TemplateId, &setting.Muted) {
+				items = append(items, setting)
+				setting = database.UserNotificationSetting{}
+			}
+
+			return &items, len(items)
+		},
+	},
+	{
+		name:       "user_notifications_read",
+		query:      "select user_id, notification_id from user_notifications_read",
+		onConflict: clause.OnConflict{DoNothing: true},
+		readPage: func(iter *gocql.Iter, now time.Time) (interface{}, int) {
+			items := make([]database.UserNotificationRead, 0)
+
+			var read database.UserNotificationRead
+
+			for iter.Scan(&read.UserId, &read.NotificationId) {
+				items = append(items, read)
+				read = database.UserNotificationRead{}
+			}
+
+			return &items, len(items)
+		},
+	},
+	{
+		name:  "user_notifications_read_counter",
+		query: "select notification_id, read_count from user_notifications_read_counter",
+		// scylla counter includes reads made after dual write was enabled, so it wins unless postgres is ahead
+		onConflict: clause.OnConflict{
+			Columns: []clause.Column{{Name: "notification_id"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"read_count": gorm.Expr("greatest(user_notifications_read_counter.read_count, excluded.read_count)"),
+			}),
+		},
+		readPage: func(iter *gocql.Iter, now time.Time) (interface{}, int) {
+			items := make([]database.UserNotificationReadCounter, 0)
+
+			var counter database.UserNotificationReadCounter
+
+			for iter.Scan(&counter.NotificationId, &counter.ReadCount) {
+				items = append(items, counter)
+				counter = database.UserNotificationReadCounter{}
+			}
+
+			return &items, len(items)
+		},
+	},
+	{
+		name: "user",
+		query: "select user_id, username, firstname, lastname, language, name_privacy_status, email, ttl(username) " +
+			"from user",
+		onConflict: clause.OnConflict{DoNothing: true},
+		readPage: func(iter *gocql.Iter, now time.Time) (interface{}, int) {
+			items := make([]database.NotificationUser, 0)
+
+			var user database.NotificationUser
+			var ttl *int
+
+			for iter.Scan(&user.UserId, &user.Username, &user.Firstname, &user.Lastname, &user.Language,
+				&user.NamePrivacyStatus, &user.Email, &ttl) {
+				user.ExpiresAt = ttlToExpiresAt(ttl, now, UserTtl)
+				items = append(items, user)
+
+				user = database.NotificationUser{}
+				ttl = nil
+			}
+
+			return &items, len(items)
+		},
+	},
+}
+
+func BackfillTableNames() []string {
+	names := make([]string, 0, len(backfillTables))
+	for _, table := range backfillTables {
+		names = append(names, table.name)
+	}
+
+	return names
+}
+
+// Backfill copies scylla tables to postgres. Paging state is saved together with every copied page,
+// so interrupted backfill continues from the last copied page
+func Backfill(session *gocql.Session, db *gorm.DB, req BackfillRequest, ctx context.Context) ([]BackfillTableResult, error) {
+	if req.BatchSize <= 0 {
+		req.BatchSize = configs.StorageBackfillDefaultBatchSize
+	}
+
+	tables := backfillTables
+
+	if len(req.Tables) > 0 {
+		tables = make([]backfillTable, 0, len(req.Tables))
+
+		for _, name := range req.Tables {
+			found := false
+
+			for _, table := range backfillTables {
+				if table.name == name {
+					tables = append(tables, table)
+					found = true
+					break
+				}
+			}
+
+			if !found {
+				return nil, errors.Errorf("unknown table %v", name)
+			}
+		}
+	}
+
+	results := make([]BackfillTableResult, 0, len(tables))
+
+	for _, table := range tables {
+		result, err := backfill(session, db, table, req, ctx)
+		if err != nil {
+			return results, errors.Wrapf(err, "table %v", table.name)
+		}
+
+		results = append(results, *result)
+	}
+
+	return results, nil
+}
+
+func backfill(session *gocql.Session, db *gorm.DB, table backfillTable, req BackfillRequest,
+	ctx context.Context) (*BackfillTableResult, error) {
+	var progress database.StorageBackfillProgress
+
+	if err := db.WithContext(ctx).Where("table_name = ?", table.name).Find(&progress).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if req.Reset || len(progress.Name) == 0 {
+		progress = database.StorageBackfillProgress{Name: table.name}
+	}
+
+	if progress.CompletedAt.Valid {
+		log.Ctx(ctx).Info().Str("table", table.name).Msg("[Backfill] table is already copied")
+
+		return &BackfillTableResult{Table: table.name, CopiedCount: progress.CopiedCount, CompletedAt: progress.CompletedAt}, nil
+	}
+
+	for {
+		iter := session.Query(table.query).WithContext(ctx).PageSize(req.BatchSize).PageState(progress.PageState).Iter()
+
+		nextPageState := iter.PageState()
+		rows, count := table.readPage(iter, time.Now().UTC())
+
+		if err := iter.Close(); err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		progress.PageState = nextPageState
+		progress.CopiedCount += int64(count)
+		progress.UpdatedAt = time.Now().UTC()
+
+		if len(nextPageState) == 0 {
+			progress.CompletedAt = null.TimeFrom(progress.UpdatedAt)
+		}
+
+		if err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if count > 0 {
+				if err := tx.Clauses(table.onConflict).Create(rows).Error; err != nil {
+					return errors.WithStack(err)
+				}
+			}
+
+			return errors.WithStack(tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(&progress).Error)
+		}); err != nil {
+			return nil, err
+		}
+
+		log.Ctx(ctx).Info().Str("table", table.name).Int("page_count", count).Int64("copied_count", progress.CopiedCount).
+			Msg("[Backfill] page copied")
+
+		if progress.CompletedAt.Valid {
+			break
+		}
+	}
+
+	return &BackfillTableResult{Table: table.name, CopiedCount: progress.CopiedCount, CompletedAt: progress.CompletedAt}, nil
+}
+
+func ttlToExpiresAt(ttl *int, now time.Time, defaultTtl time.Duration) time.Time {
+	if ttl == nil || *ttl <= 0 {
+		return now.Add(defaultTtl)
+	}
+
+	return now.Add(time.Duration(*ttl) * time.Second)
+}
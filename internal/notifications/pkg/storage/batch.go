@@ -0,0 +1,104 @@
+package storage
+
+import (
+	"time"
+
+	"github.com/digitalmonsters/notification-handler/pkg/database/scylla"
+	"gopkg.in/guregu/null.v4"
+)
+
+// Batch collects writes which are executed together by ExecuteBatch. Scylla executes it as unlogged batch,
+// postgres in a single transaction in the order of calls
+type Batch struct {
+	mutations []interface{}
+}
+
+type upsertNotification struct {
+	notification scylla.Notification
+}
+
+type deleteNotification struct {
+	userId          int64
+	eventType       string
+	createdAt       time.Time
+	entityId        int64
+	relatedEntityId int64
+}
+
+type setEventApplied struct {
+	relation scylla.NotificationRelation
+}
+
+type upsertGroupQueueItem struct {
+	item scylla.PushNotificationGroupQueue
+}
+
+type deleteGroupQueueItems struct {
+	deadlineKey time.Time
+	deadline    time.Time
+	userId      int64
+	eventType   string
+	entityId    null.Int
+}
+
+func NewBatch() *Batch {
+	return &Batch{}
+}
+
+func (b *Batch) Len() int {
+	return len(b.mutations)
+}
+
+// UpsertNotification writes counter, texts and info of a notification, key columns identify the row
+func (b *Batch) UpsertNotification(notification scylla.Notification) *Batch {
+	b.mutations = append(b.mutations, upsertNotification{notification: notification})
+
+	return b
+}
+
+func (b *Batch) DeleteNotification(userId int64, eventType string, createdAt time.Time, entityId int64,
+	relatedEntityId int64) *Batch {
+	b.mutations = append(b.mutations, deleteNotification{
+		userId:          userId,
+		eventType:       eventType,
+		createdAt:       createdAt,
+		entityId:        entityId,
+		relatedEntityId: relatedEntityId,
+	})
+
+	return b
+}
+
+func (b *Batch) SetEventApplied(userId int64, eventType string, entityId int64, relatedEntityId int64, applied bool) *Batch {
+	b.mutations = append(b.mutations, setEventApplied{relation: scylla.NotificationRelation{
+		UserId:          userId,
+		EventType:       eventType,
+		EntityId:        entityId,
+		RelatedEntityId: relatedEntityId,
+		EventApplied:    applied,
+	}})
+
+	return b
+}
+
+// UpsertGroupQueueItem writes created_at and notification_count of a queue item
+func (b *Batch) UpsertGroupQueueItem(item scylla.PushNotificationGroupQueue) *Batch {
+	b.mutations = append(b.mutations, upsertGroupQueueItem{item: item})
+
+	return b
+}
+
+// DeleteGroupQueueItems deletes queue items of user and event type for deadline, when entityId is not set
+// items of all entities are deleted
+func (b *Batch) DeleteGroupQueueItems(deadlineKey time.Time, deadline time.Time, userId int64, eventType string,
+	entityId null.Int) *Batch {
+	b.mutations = append(b.mutations, deleteGroupQueueItems{
+		deadlineKey: deadlineKey,
+		deadline:    deadline,
+		userId:      userId,
+		eventType:   eventType,
+		entityId:    entityId,
+	})
+
+	return b
+}
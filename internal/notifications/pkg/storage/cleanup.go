@@ -0,0 +1,67 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/RichardKnop/machinery/v1"
+	"github.com/RichardKnop/machinery/v1/tasks"
+	"github.com/digitalmonsters/go-common/apm_helper"
+	"github.com/digitalmonsters/go-common/boilerplate"
+	"github.com/digitalmonsters/notification-handler/configs"
+	"github.com/digitalmonsters/notification-handler/pkg/database"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+func RegisterCleanupTasks(jobber *machinery.Server) error {
+	if err := jobber.RegisterTask(string(configs.GeneralStorageCleanupTask), func() error {
+		apmTransaction := apm_helper.StartNewApmTransaction(string(configs.GeneralStorageCleanupTask),
+			"storage_cleanup", nil, nil)
+
+		defer func() {
+			apmTransaction.End()
+		}()
+
+		ctx := boilerplate.CreateCustomContext(context.Background(), apmTransaction, log.Logger)
+
+		deleted, err := CleanupExpired(time.Now().UTC(), ctx)
+		apm_helper.AddApmLabel(apmTransaction, "deleted_count", deleted)
+
+		if err != nil {
+			apm_helper.LogError(errors.WithStack(err), ctx)
+			return errors.WithStack(err)
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	if err := jobber.RegisterPeriodicTask(configs.StorageCleanupJobCron,
+		string(configs.PeriodicStorageCleanupTask), &tasks.Signature{
+			Name: string(configs.GeneralStorageCleanupTask),
+		}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// CleanupExpired removes postgres rows with passed ttl. Does nothing while scylla is the only storage
+func CleanupExpired(currentDate time.Time, ctx context.Context) (int64, error) {
+	if Mode(configs.GetConfig().Storage.Mode) != ModeDual && Mode(configs.GetConfig().Storage.Mode) != ModePostgres {
+		return 0, nil
+	}
+
+	repo := &postgresRepository{db: database.GetDb(database.DbTypeMaster)}
+
+	deleted, err := repo.DeleteExpired(currentDate, configs.StorageCleanupBatchSize, ctx)
+	if err != nil {
+		return deleted, err
+	}
+
+	log.Ctx(ctx).Info().Int64("deleted_count", deleted).Msg("[StorageCleanup] expired rows removed")
+
+	return deleted, nil
+}
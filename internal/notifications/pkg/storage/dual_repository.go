@@ -0,0 +1,147 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/digitalmonsters/go-common/apm_helper"
+	"github.com/digitalmonsters/notification-handler/pkg/database/scylla"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// dualRepository reads from primary storage and writes to both. Errors of secondary storage are only logged,
+// secondary is brought in sync by backfill
+type dualRepository struct {
+	primary   IRepository
+	secondary IRepository
+}
+
+func NewDualRepository(primary IRepository, secondary IRepository) IRepository {
+	return &dualRepository{
+		primary:   primary,
+		secondary: secondary,
+	}
+}
+
+func (r *dualRepository) logSecondaryError(operation string, err error, ctx context.Context) {
+	log.Ctx(ctx).Warn().Str("operation", operation).Err(err).Msg("[Storage] secondary storage write failed")
+	apm_helper.LogError(errors.Wrap(err, "secondary storage"), ctx)
+}
+
+// GetFeed continues pages from the storage which issued them, so switching of primary does not break paging
+func (r *dualRepository) GetFeed(userId int64, view string, page string, limit int, ctx context.Context) ([]scylla.Notification, string, error) {
+	if len(page) > 0 {
+		if _, isPostgres := r.primary.(*postgresRepository); isPostgres != isPostgresPage(page) {
+			return r.secondary.GetFeed(userId, view, page, limit, ctx)
+		}
+	}
+
+	return r.primary.GetFeed(userId, view, page, limit, ctx)
+}
+
+func (r *dualRepository) GetNotification(userId int64, eventType string, createdAt time.Time, entityId int64,
+	ctx context.Context) (*scylla.Notification, error) {
+	return r.primary.GetNotification(userId, eventType, createdAt, entityId, ctx)
+}
+
+func (r *dualRepository) GetNotificationsSince(userId int64, eventType string, since time.Time,
+	ctx context.Context) ([]scylla.Notification, error) {
+	return r.primary.GetNotificationsSince(userId, eventType, since, ctx)
+}
+
+func (r *dualRepository) GetRelations(filter RelationFilter, ctx context.Context) ([]scylla.NotificationRelation, error) {
+	return r.primary.GetRelations(filter, ctx)
+}
+
+func (r *dualRepository) GetGroupQueue(deadlineKeys []time.Time, deadlines []time.Time, filter GroupQueueFilter,
+	ctx context.Context) ([]scylla.PushNotificationGroupQueue, error) {
+	return r.primary.GetGroupQueue(deadlineKeys, deadlines, filter, ctx)
+}
+
+func (r *dualRepository) ExecuteBatch(batch *Batch, ctx context.Context) error {
+	if err := r.primary.ExecuteBatch(batch, ctx); err != nil {
+		return err
+	}
+
+	if err := r.secondary.ExecuteBatch(batch, ctx); err != nil {
+		r.logSecondaryError("execute_batch", err, ctx)
+	}
+
+	return nil
+}
+
+func (r *dualRepository) MarkRead(userId int64, notificationId int64, ctx context.Context) (bool, error) {
+	marked, err := r.primary.MarkRead(userId, notificationId, ctx)
+	if err != nil || !marked {
+		return marked, err
+	}
+
+	if _, err = r.secondary.MarkRead(userId, notificationId, ctx); err != nil {
+		r.logSecondaryError("mark_read", err, ctx)
+	}
+
+	return marked, nil
+}
+
+func (r *dualRepository) GetReadCounts(notificationIds []int64, ctx context.Context) (map[int64]int64, error) {
+	return r.primary.GetReadCounts(notificationIds, ctx)
+}
+
+func (r *dualRepository) GetSettings(userId int64, ctx context.Context) (map[string]bool, error) {
+	return r.primary.GetSettings(userId, ctx)
+}
+
+func (r *dualRepository) SetSettings(userId int64, settings map[string]bool, ctx context.Context) error {
+	if err := r.primary.SetSettings(userId, settings, ctx); err != nil {
+		return err
+	}
+
+	if err := r.secondary.SetSettings(userId, settings, ctx); err != nil {
+		r.logSecondaryError("set_settings", err, ctx)
+	}
+
+	return nil
+}
+
+func (r *dualRepository) IsMuted(userId int64, templateId string, ctx context.Context) (bool, error) {
+	return r.primary.IsMuted(userId, templateId, ctx)
+}
+
+// GetUser falls back to secondary storage, since user table is filled by other services, and copies found user
+// to primary one
+func (r *dualRepository) GetUser(userId int64, ctx context.Context) (*scylla.User, error) {
+	user, err := r.primary.GetUser(userId, ctx)
+	if err != nil || user.UserId != 0 {
+		return user, err
+	}
+
+	secondaryUser, err := r.secondary.GetUser(userId, ctx)
+	if err != nil {
+		r.logSecondaryError("get_user", err, ctx)
+
+		return user, nil
+	}
+
+	if secondaryUser.UserId == 0 {
+		return user, nil
+	}
+
+	if err = r.primary.UpsertUser(*secondaryUser, ctx); err != nil {
+		apm_helper.LogError(err, ctx)
+	}
+
+	return secondaryUser, nil
+}
+
+func (r *dualRepository) UpsertUser(user scylla.User, ctx context.Context) error {
+	if err := r.primary.UpsertUser(user, ctx); err != nil {
+		return err
+	}
+
+	if err := r.secondary.UpsertUser(user, ctx); err != nil {
+		r.logSecondaryError("upsert_user", err, ctx)
+	}
+
+	return nil
+}
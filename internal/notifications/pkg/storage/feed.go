@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"encoding/base32"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// postgresPagePrefix is not a part of base32 alphabet, so postgres pages can not be confused with scylla ones
+const postgresPagePrefix = "pg_"
+
+var allFeedEventTypes = []string{"comment_reply", "comment_vote_like", "comment_vote_dislike",
+	"comment_profile_resource_create", "comment_content_resource_create", "follow", "content_posted", "tip",
+	"content_like", "bonus_followers", "bonus_time", "content_upload", "spot_upload", "content_reject",
+	"kyc_status_verified", "kyc_status_rejected", "creator_status_rejected", "creator_status_approved",
+	"creator_status_pending", "first_daily_followers_bonus", "first_daily_time_bonus", "first_guest_x_earned_points",
+	"first_guest_x_paid_views", "first_x_paid_views", "first_referral_joined", "first_video_shared",
+	"first_weekly_followers_bonus", "first_weekly_time_bonus", "first_x_paid_views_as_content_owner",
+	"guest_max_earned_points_for_views", "increase_reward_stage_1", "increase_reward_stage_2",
+	"registration_verify_bonus", "other_referrals_joined", "custom_reward_increase", "megabonus",
+	"first_time_avatar_added", "first_video_uploaded", "first_bio_video_uploaded", "first_spot_uploaded",
+	"add_description_bonus", "first_x_paid_views_gender_push", "first_email_marketing_added", "top_daily_spot_bonus",
+	"top_weekly_spot_bonus", "last_boring_spots", "first_boring_spots", "warning_boring_spots",
+	"monthly_mega_bonus_completed", "monthly_mega_bonus_progress", "monthly_mega_bonus_progress_almost_finished",
+	"monthly_mega_bonus_one_day_missing", "monthly_mega_bonus_do_not_miss", "first_x_social_media_added",
+	"add_social_subs_target_achieved_bonus", "ads_campaign_rejected", "ads_campaign_approved",
+	"music_creator_status_rejected", "music_creator_status_approved", "music_creator_status_pending",
+	"music_song_rejected", "music_song_approved"}
+
+// feedViewEventTypes mirrors filters of scylla views (see scylla/migration_change.txt)
+var feedViewEventTypes = map[string][]string{
+	"notification_comment": {"comment_reply", "comment_vote_like", "comment_vote_dislike",
+		"comment_profile_resource_create", "comment_content_resource_create"},
+	"notification_following":              {"follow"},
+	"notification_system":                 {""},
+	"notification_system_with_push_admin": {"push_admin"},
+	"notification_all":                    allFeedEventTypes,
+	"notification_all_with_push_admin":    append(append([]string{}, allFeedEventTypes...), "push_admin"),
+}
+
+func getFeedViewEventTypes(view string) ([]string, error) {
+	eventTypes, ok := feedViewEventTypes[view]
+	if !ok {
+		return nil, errors.WithStack(errors.New("unknown group"))
+	}
+
+	return eventTypes, nil
+}
+
+// feedCursor is a key of the last returned row, rows are ordered by created_at desc and the rest of the key asc
+type feedCursor struct {
+	CreatedAt       time.Time `json:"c"`
+	EventType       string    `json:"t"`
+	EntityId        int64     `json:"e"`
+	RelatedEntityId int64     `json:"r"`
+}
+
+func isPostgresPage(page string) bool {
+	return strings.HasPrefix(page, postgresPagePrefix)
+}
+
+func encodeFeedCursor(cursor feedCursor) (string, error) {
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	return postgresPagePrefix + base32.StdEncoding.EncodeToString(data), nil
+}
+
+func decodeFeedCursor(page string) (*feedCursor, error) {
+	if len(page) == 0 {
+		return nil, nil
+	}
+
+	if !isPostgresPage(page) {
+		return nil, errors.WithStack(errors.New("invalid page"))
+	}
+
+	data, err := base32.StdEncoding.DecodeString(strings.TrimPrefix(page, postgresPagePrefix))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	var cursor feedCursor
+	if err = json.Unmarshal(data, &cursor); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return &cursor, nil
+}
+
+// toStorageTime truncates time to milliseconds like scylla does, so keys read from one storage match another one
+func toStorageTime(value time.Time) time.Time {
+	return value.UTC().Truncate(time.Millisecond)
+}
@@ -0,0 +1,468 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/digitalmonsters/notification-handler/pkg/database"
+	"github.com/digitalmonsters/notification-handler/pkg/database/scylla"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type postgresRepository struct {
+	db *gorm.DB
+}
+
+func NewPostgresRepository(db *gorm.DB) IRepository {
+	return &postgresRepository{
+		db: db,
+	}
+}
+
+func (r *postgresRepository) GetFeed(userId int64, view string, page string, limit int, ctx context.Context) ([]scylla.Notification, string, error) {
+	eventTypes, err := getFeedViewEventTypes(view)
+	if err != nil {
+		return nil, "", err
+	}
+
+	cursor, err := decodeFeedCursor(page)
+	if err != nil {
+		return nil, "", err
+	}
+
+	query := r.db.WithContext(ctx).Where("user_id = ? and event_type in ? and expires_at > ?", userId, eventTypes,
+		time.Now().UTC())
+
+	if cursor != nil {
+		query = query.Where("created_at < ? or (created_at = ? and (event_type, entity_id, related_entity_id) > (?, ?, ?))",
+			cursor.CreatedAt, cursor.CreatedAt, cursor.EventType, cursor.EntityId, cursor.RelatedEntityId)
+	}
+
+	var items []database.NotificationFeedItem
+
+	// one extra row tells if there is a next page
+	if err = query.Order("created_at desc, event_type, entity_id, related_entity_id").Limit(limit + 1).
+		Find(&items).Error; err != nil {
+		return nil, "", errors.WithStack(err)
+	}
+
+	nextPage := ""
+
+	if len(items) > limit {
+		items = items[:limit]
+		last := items[len(items)-1]
+
+		if nextPage, err = encodeFeedCursor(feedCursor{
+			CreatedAt:       last.CreatedAt,
+			EventType:       last.EventType,
+			EntityId:        last.EntityId,
+			RelatedEntityId: last.RelatedEntityId,
+		}); err != nil {
+			return nil, "", err
+		}
+	}
+
+	notifications := make([]scylla.Notification, 0, len(items))
+	for _, item := range items {
+		notifications = append(notifications, feedItemToNotification(item))
+	}
+
+	return notifications, nextPage, nil
+}
+
+func (r *postgresRepository) GetNotification(userId int64, eventType string, createdAt time.Time, entityId int64,
+	ctx context.Context) (*scylla.Notification, error) {
+	var items []database.NotificationFeedItem
+
+	if err := r.db.WithContext(ctx).
+		Where("user_id = ? and event_type = ? and created_at = ? and entity_id = ? and expires_at > ?", userId,
+			eventType, toStorageTime(createdAt), entityId, time.Now().UTC()).
+		Order("related_entity_id").Limit(1).Find(&items).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if len(items) == 0 {
+		return nil, nil
+	}
+
+	notification := feedItemToNotification(items[0])
+
+	return &notification, nil
+}
+
+func (r *postgresRepository) GetNotificationsSince(userId int64, eventType string, since time.Time,
+	ctx context.Context) ([]scylla.Notification, error) {
+	var items []database.NotificationFeedItem
+
+	if err := r.db.WithContext(ctx).Select("user_id", "event_type", "entity_id", "related_entity_id", "created_at").
+		Where("user_id = ? and event_type = ? and created_at >= ? and expires_at > ?", userId, eventType,
+			toStorageTime(since), time.Now().UTC()).
+		Order("created_at desc, entity_id, related_entity_id").Find(&items).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	notifications := make([]scylla.Notification, 0, len(items))
+	for _, item := range items {
+		notifications = append(notifications, feedItemToNotification(item))
+	}
+
+	return notifications, nil
+}
+
+func (r *postgresRepository) GetRelations(filter RelationFilter, ctx context.Context) ([]scylla.NotificationRelation, error) {
+	query := r.db.WithContext(ctx).Where("user_id = ? and event_type = ? and expires_at > ?", filter.UserId,
+		filter.EventType, time.Now().UTC())
+
+	if filter.EntityId.Valid {
+		query = query.Where("entity_id = ?", filter.EntityId.Int64)
+
+		if filter.RelatedEntityId.Valid {
+			query = query.Where("related_entity_id = ?", filter.RelatedEntityId.Int64)
+		}
+	}
+
+	var items []database.NotificationRelation
+
+	if err := query.Order("entity_id, related_entity_id").Find(&items).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	relations := make([]scylla.NotificationRelation, 0, len(items))
+	for _, item := range items {
+		relations = append(relations, scylla.NotificationRelation{
+			UserId:          item.UserId,
+			EventType:       item.EventType,
+			EntityId:        item.EntityId,
+			RelatedEntityId: item.RelatedEntityId,
+			EventApplied:    item.EventApplied,
+		})
+	}
+
+	return relations, nil
+}
+
+func (r *postgresRepository) GetGroupQueue(deadlineKeys []time.Time, deadlines []time.Time, filter GroupQueueFilter,
+	ctx context.Context) ([]scylla.PushNotificationGroupQueue, error) {
+	query := r.db.WithContext(ctx).Where("deadline_key in ? and deadline in ? and expires_at > ?",
+		toStorageTimes(deadlineKeys), toStorageTimes(deadlines), time.Now().UTC())
+
+	if filter.UserId.Valid {
+		query = query.Where("user_id = ?", filter.UserId.Int64)
+	}
+	if filter.EventType.Valid {
+		query = query.Where("event_type = ?", filter.EventType.String)
+	}
+	if filter.EntityId.Valid {
+		query = query.Where("entity_id = ?", filter.EntityId.Int64)
+	}
+
+	var items []database.PushNotificationGroupQueueItem
+
+	if err := query.Order("deadline_key, deadline, user_id, event_type, entity_id").Find(&items).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	result := make([]scylla.PushNotificationGroupQueue, 0, len(items))
+	for _, item := range items {
+		result = append(result, scylla.PushNotificationGroupQueue{
+			DeadlineKey:       item.DeadlineKey.UTC(),
+			Deadline:          item.Deadline.UTC(),
+			UserId:            item.UserId,
+			EventType:         item.EventType,
+			EntityId:          item.EntityId,
+			CreatedAt:         item.CreatedAt.UTC(),
+			NotificationCount: item.NotificationCount,
+		})
+	}
+
+	return result, nil
+}
+
+func (r *postgresRepository) ExecuteBatch(batch *Batch, ctx context.Context) error {
+	if batch.Len() == 0 {
+		return nil
+	}
+
+	now := time.Now().UTC()
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, m := range batch.mutations {
+			if err := r.applyMutation(tx, m, now); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (r *postgresRepository) applyMutation(tx *gorm.DB, m interface{}, now time.Time) error {
+	switch mutation := m.(type) {
+	case upsertNotification:
+		item := notificationToFeedItem(mutation.notification, now.Add(NotificationTtl))
+
+		if err := tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(&item).Error; err != nil {
+			return errors.WithStack(err)
+		}
+	case deleteNotification:
+		if err := tx.Where("user_id = ? and event_type = ? and created_at = ? and entity_id = ? and related_entity_id = ?",
+			mutation.userId, mutation.eventType, toStorageTime(mutation.createdAt), mutation.entityId,
+			mutation.relatedEntityId).Delete(&database.NotificationFeedItem{}).Error; err != nil {
+			return errors.WithStack(err)
+		}
+	case setEventApplied:
+		item := database.NotificationRelation{
+			UserId:          mutation.relation.UserId,
+			EventType:       mutation.relation.EventType,
+			EntityId:        mutation.relation.EntityId,
+			RelatedEntityId: mutation.relation.RelatedEntityId,
+			EventApplied:    mutation.relation.EventApplied,
+			ExpiresAt:       now.Add(RelationTtl),
+		}
+
+		if err := tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(&item).Error; err != nil {
+			return errors.WithStack(err)
+		}
+	case upsertGroupQueueItem:
+		item := database.PushNotificationGroupQueueItem{
+			DeadlineKey:       toStorageTime(mutation.item.DeadlineKey),
+			Deadline:          toStorageTime(mutation.item.Deadline),
+			UserId:            mutation.item.UserId,
+			EventType:         mutation.item.EventType,
+			EntityId:          mutation.item.EntityId,
+			CreatedAt:         toStorageTime(mutation.item.CreatedAt),
+			NotificationCount: mutation.item.NotificationCount,
+			ExpiresAt:         now.Add(GroupQueueTtl),
+		}
+
+		if err := tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(&item).Error; err != nil {
+			return errors.WithStack(err)
+		}
+	case deleteGroupQueueItems:
+		query := tx.Where("deadline_key = ? and deadline = ? and user_id = ? and event_type = ?",
+			toStorageTime(mutation.deadlineKey), toStorageTime(mutation.deadline), mutation.userId, mutation.eventType)
+
+		if mutation.entityId.Valid {
+			query = query.Where("entity_id = ?", mutation.entityId.Int64)
+		}
+
+		if err := query.Delete(&database.PushNotificationGroupQueueItem{}).Error; err != nil {
+			return errors.WithStack(err)
+		}
+	default:
+		return errors.WithStack(fmt.Errorf("unsupported mutation %T", m))
+	}
+
+	return nil
+}
+
+func (r *postgresRepository) MarkRead(userId int64, notificationId int64, ctx context.Context) (bool, error) {
+	marked := false
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&database.UserNotificationRead{
+			UserId:         userId,
+			NotificationId: notificationId,
+		})
+		if res.Error != nil {
+			return errors.WithStack(res.Error)
+		}
+
+		if res.RowsAffected == 0 { // already read
+			return nil
+		}
+
+		if err := tx.Exec("insert into user_notifications_read_counter (notification_id, read_count) values (?, 1) "+
+			"on conflict (notification_id) do update set read_count = user_notifications_read_counter.read_count + 1",
+			notificationId).Error; err != nil {
+			return errors.WithStack(err)
+		}
+
+		marked = true
+
+		return nil
+	})
+
+	return marked, err
+}
+
+func (r *postgresRepository) GetReadCounts(notificationIds []int64, ctx context.Context) (map[int64]int64, error) {
+	readCounts := make(map[int64]int64)
+
+	if len(notificationIds) == 0 {
+		return readCounts, nil
+	}
+
+	var counters []database.UserNotificationReadCounter
+
+	if err := r.db.WithContext(ctx).Where("notification_id in ?", notificationIds).Find(&counters).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	for _, counter := range counters {
+		readCounts[counter.NotificationId] = counter.ReadCount
+	}
+
+	return readCounts, nil
+}
+
+func (r *postgresRepository) GetSettings(userId int64, ctx context.Context) (map[string]bool, error) {
+	var items []database.UserNotificationSetting
+
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userId).Find(&items).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	settings := make(map[string]bool, len(items))
+	for _, item := range items {
+		settings[item.TemplateId] = item.Muted
+	}
+
+	return settings, nil
+}
+
+func (r *postgresRepository) SetSettings(userId int64, settings map[string]bool, ctx context.Context) error {
+	if len(settings) == 0 {
+		return nil
+	}
+
+	items := make([]database.UserNotificationSetting, 0, len(settings))
+	for templateId, muted := range settings {
+		items = append(items, database.UserNotificationSetting{
+			UserId:     userId,
+			TemplateId: templateId,
+			Muted:      muted,
+		})
+	}
+
+	if err := r.db.WithContext(ctx).Clauses(clause.OnConflict{UpdateAll: true}).Create(&items).Error; err != nil {
+		return errors.WithStack(err)
+	}
+
+	return nil
+}
+
+func (r *postgresRepository) IsMuted(userId int64, templateId string, ctx context.Context) (bool, error) {
+	var muted []bool
+
+	if err := r.db.WithContext(ctx).Model(database.UserNotificationSetting{}).
+		Where("user_id = ? and template_id = ?", userId, templateId).Pluck("muted", &muted).Error; err != nil {
+		return false, errors.WithStack(err)
+	}
+
+	return len(muted) > 0 && muted[0], nil
+}
+
+func (r *postgresRepository) GetUser(userId int64, ctx context.Context) (*scylla.User, error) {
+	var item database.NotificationUser
+
+	if err := r.db.WithContext(ctx).Where("user_id = ? and expires_at > ?", userId, time.Now().UTC()).
+		Find(&item).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return &scylla.User{
+		UserId:            item.UserId,
+		Username:          item.Username,
+		Firstname:         item.Firstname,
+		Lastname:          item.Lastname,
+		NamePrivacyStatus: item.NamePrivacyStatus,
+		Language:          item.Language,
+		Email:             item.Email,
+	}, nil
+}
+
+func (r *postgresRepository) UpsertUser(user scylla.User, ctx context.Context) error {
+	if err := r.db.WithContext(ctx).Clauses(clause.OnConflict{UpdateAll: true}).
+		Create(userToNotificationUser(user, time.Now().UTC().Add(UserTtl))).Error; err != nil {
+		return errors.WithStack(err)
+	}
+
+	return nil
+}
+
+// DeleteExpired removes rows which scylla would have dropped by ttl, returns count of deleted rows
+func (r *postgresRepository) DeleteExpired(now time.Time, batchSize int, ctx context.Context) (int64, error) {
+	var total int64
+
+	for _, table := range []string{"notification_feed", "notification_relations", "push_notification_group_queue",
+		"notification_users"} {
+		for {
+			res := r.db.WithContext(ctx).Exec(fmt.Sprintf("delete from %v where ctid in (select ctid from %v "+
+				"where expires_at <= ? limit ?)", table, table), now, batchSize)
+			if res.Error != nil {
+				return total, errors.WithStack(res.Error)
+			}
+
+			total += res.RowsAffected
+
+			if res.RowsAffected < int64(batchSize) {
+				break
+			}
+		}
+	}
+
+	return total, nil
+}
+
+func feedItemToNotification(item database.NotificationFeedItem) scylla.Notification {
+	return scylla.Notification{
+		UserId:             item.UserId,
+		EventType:          item.EventType,
+		EntityId:           item.EntityId,
+		RelatedEntityId:    item.RelatedEntityId,
+		CreatedAt:          item.CreatedAt.UTC(),
+		NotificationsCount: item.NotificationsCount,
+		Title:              item.Title,
+		Body:               item.Body,
+		Headline:           item.Headline,
+		Kind:               item.Kind,
+		RenderingVariables: item.RenderingVariables,
+		CustomData:         item.CustomData,
+		NotificationInfo:   item.NotificationInfo,
+	}
+}
+
+func notificationToFeedItem(notification scylla.Notification, expiresAt time.Time) database.NotificationFeedItem {
+	return database.NotificationFeedItem{
+		UserId:             notification.UserId,
+		EventType:          notification.EventType,
+		CreatedAt:          toStorageTime(notification.CreatedAt),
+		EntityId:           notification.EntityId,
+		RelatedEntityId:    notification.RelatedEntityId,
+		NotificationsCount: notification.NotificationsCount,
+		Title:              notification.Title,
+		Body:               notification.Body,
+		Headline:           notification.Headline,
+		Kind:               notification.Kind,
+		RenderingVariables: notification.RenderingVariables,
+		CustomData:         notification.CustomData,
+		NotificationInfo:   notification.NotificationInfo,
+		ExpiresAt:          expiresAt,
+	}
+}
+
+func userToNotificationUser(user scylla.User, expiresAt time.Time) *database.NotificationUser {
+	return &database.NotificationUser{
+		UserId:            user.UserId,
+		Username:          user.Username,
+		Firstname:         user.Firstname,
+		Lastname:          user.Lastname,
+		NamePrivacyStatus: user.NamePrivacyStatus,
+		Language:          user.Language,
+		Email:             user.Email,
+		ExpiresAt:         expiresAt,
+	}
+}
+
+func toStorageTimes(values []time.Time) []time.Time {
+	result := make([]time.Time, 0, len(values))
+	for _, value := range values {
+		result = append(result, toStorageTime(value))
+	}
+
+	return result
+}
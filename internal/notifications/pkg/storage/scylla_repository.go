@@ -0,0 +1,432 @@
+package storage
+
+import (
+	"context"
+	"encoding/base32"
+	"fmt"
+	"time"
+
+	"github.com/digitalmonsters/notification-handler/pkg/database"
+	"github.com/digitalmonsters/notification-handler/pkg/database/scylla"
+	"github.com/gocql/gocql"
+	"github.com/pkg/errors"
+	snappy "github.com/segmentio/kafka-go/compress/snappy/go-xerial-snappy"
+)
+
+const scyllaSettingsBatchSize = 130
+
+type scyllaRepository struct {
+	session *gocql.Session
+}
+
+func NewScyllaRepository(session *gocql.Session) IRepository {
+	return &scyllaRepository{
+		session: session,
+	}
+}
+
+func (r *scyllaRepository) GetFeed(userId int64, view string, page string, limit int, ctx context.Context) ([]scylla.Notification, string, error) {
+	if _, err := getFeedViewEventTypes(view); err != nil {
+		return nil, "", err
+	}
+
+	var pageState []byte
+
+	if len(page) > 0 {
+		var err error
+
+		pageState, err = base32.StdEncoding.DecodeString(page)
+		if err != nil {
+			return nil, "", errors.WithStack(err)
+		}
+
+		pageState, err = snappy.Decode(pageState)
+		if err != nil {
+			return nil, "", errors.WithStack(err)
+		}
+	}
+
+	query := fmt.Sprintf("select created_at, event_type, entity_id, related_entity_id from %v where user_id = ?", view)
+
+	iter := r.session.Query(query, userId).WithContext(ctx).PageSize(limit).PageState(pageState).Iter()
+
+	nextPageState := iter.PageState()
+	scanner := iter.Scanner()
+
+	notifications := make([]scylla.Notification, 0)
+
+	for scanner.Next() {
+		notificationByTypeGroup := scylla.NotificationByTypeGroup{UserId: userId}
+
+		if err := scanner.Scan(&notificationByTypeGroup.CreatedAt, &notificationByTypeGroup.EventType,
+			&notificationByTypeGroup.EntityId, &notificationByTypeGroup.RelatedEntityId); err != nil {
+			return nil, "", errors.WithStack(err)
+		}
+
+		notification := scylla.Notification{
+			UserId:          userId,
+			EventType:       notificationByTypeGroup.EventType,
+			EntityId:        notificationByTypeGroup.EntityId,
+			RelatedEntityId: notificationByTypeGroup.RelatedEntityId,
+			CreatedAt:       notificationByTypeGroup.CreatedAt,
+		}
+
+		notificationIter := r.session.Query("select title, body, notifications_count, notification_info from notification "+
+			"where user_id = ? and event_type = ? and created_at = ? and entity_id = ? and related_entity_id = ?",
+			userId, notification.EventType, notification.CreatedAt, notification.EntityId, notification.RelatedEntityId).
+			WithContext(ctx).Iter()
+
+		notificationIter.Scan(&notification.Title, &notification.Body, &notification.NotificationsCount,
+			&notification.NotificationInfo)
+
+		if err := notificationIter.Close(); err != nil {
+			return nil, "", errors.WithStack(err)
+		}
+
+		if len(notification.NotificationInfo) == 0 { // view is not consistent with base table yet
+			continue
+		}
+
+		notifications = append(notifications, notification)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, "", errors.WithStack(err)
+	}
+	if err := iter.Close(); err != nil {
+		return nil, "", errors.WithStack(err)
+	}
+
+	nextPage := ""
+
+	if len(nextPageState) > 0 {
+		nextPage = base32.StdEncoding.EncodeToString(snappy.Encode(nextPageState))
+	}
+
+	return notifications, nextPage, nil
+}
+
+func (r *scyllaRepository) GetNotification(userId int64, eventType string, createdAt time.Time, entityId int64,
+	ctx context.Context) (*scylla.Notification, error) {
+	notificationIter := r.session.Query("select user_id, related_entity_id, title, body, headline, kind, rendering_variables, custom_data "+
+		"from notification where user_id = ? and event_type = ? and created_at = ? and entity_id = ? limit 1",
+		userId, eventType, createdAt, entityId).WithContext(ctx).Iter()
+
+	notification := scylla.Notification{
+		UserId:    userId,
+		EventType: eventType,
+		EntityId:  entityId,
+		CreatedAt: createdAt,
+	}
+
+	var userIdFromSelect int64
+	notificationIter.Scan(&userIdFromSelect, &notification.RelatedEntityId, &notification.Title, &notification.Body,
+		&notification.Headline, &notification.Kind, &notification.RenderingVariables, &notification.CustomData)
+
+	if err := notificationIter.Close(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if userIdFromSelect == 0 {
+		return nil, nil
+	}
+
+	return &notification, nil
+}
+
+func (r *scyllaRepository) GetNotificationsSince(userId int64, eventType string, since time.Time,
+	ctx context.Context) ([]scylla.Notification, error) {
+	iter := r.session.Query("select entity_id, related_entity_id, created_at "+
+		"from notification where user_id = ? and event_type = ? and created_at >= ?",
+		userId, eventType, since).WithContext(ctx).Iter()
+
+	notifications := make([]scylla.Notification, 0)
+	notification := scylla.Notification{UserId: userId, EventType: eventType}
+
+	for iter.Scan(&notification.EntityId, &notification.RelatedEntityId, &notification.CreatedAt) {
+		notifications = append(notifications, notification)
+		notification = scylla.Notification{UserId: userId, EventType: eventType}
+	}
+
+	if err := iter.Close(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return notifications, nil
+}
+
+func (r *scyllaRepository) GetRelations(filter RelationFilter, ctx context.Context) ([]scylla.NotificationRelation, error) {
+	query := "select entity_id, related_entity_id, event_applied from notification_relation where user_id = ? and event_type = ?"
+	args := []interface{}{filter.UserId, filter.EventType}
+
+	if filter.EntityId.Valid {
+		query += " and entity_id = ?"
+		args = append(args, filter.EntityId.Int64)
+
+		if filter.RelatedEntityId.Valid {
+			query += " and related_entity_id = ?"
+			args = append(args, filter.RelatedEntityId.Int64)
+		}
+	}
+
+	iter := r.session.Query(query, args...).WithContext(ctx).Iter()
+
+	relations := make([]scylla.NotificationRelation, 0)
+	relation := scylla.NotificationRelation{UserId: filter.UserId, EventType: filter.EventType}
+
+	for iter.Scan(&relation.EntityId, &relation.RelatedEntityId, &relation.EventApplied) {
+		relations = append(relations, relation)
+		relation = scylla.NotificationRelation{UserId: filter.UserId, EventType: filter.EventType}
+	}
+
+	if err := iter.Close(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return relations, nil
+}
+
+func (r *scyllaRepository) GetGroupQueue(deadlineKeys []time.Time, deadlines []time.Time, filter GroupQueueFilter,
+	ctx context.Context) ([]scylla.PushNotificationGroupQueue, error) {
+	query := "select deadline_key, deadline, user_id, event_type, entity_id, created_at, notification_count " +
+		"from push_notification_group_queue where deadline_key in ? and deadline in ?"
+	args := []interface{}{deadlineKeys, deadlines}
+
+	if filter.UserId.Valid {
+		query += " and user_id = ?"
+		args = append(args, filter.UserId.Int64)
+
+		if filter.EventType.Valid {
+			query += " and event_type = ?"
+			args = append(args, filter.EventType.String)
+
+			if filter.EntityId.Valid {
+				query += " and entity_id = ?"
+				args = append(args, filter.EntityId.Int64)
+			}
+		}
+	}
+
+	iter := r.session.Query(query, args...).WithContext(ctx).Iter()
+
+	items := make([]scylla.PushNotificationGroupQueue, 0)
+	var item scylla.PushNotificationGroupQueue
+
+	for iter.Scan(&item.DeadlineKey, &item.Deadline, &item.UserId, &item.EventType, &item.EntityId, &item.CreatedAt,
+		&item.NotificationCount) {
+		items = append(items, item)
+		item = scylla.PushNotificationGroupQueue{}
+	}
+
+	if err := iter.Close(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return items, nil
+}
+
+func (r *scyllaRepository) ExecuteBatch(batch *Batch, ctx context.Context) error {
+	if batch.Len() == 0 {
+		return nil
+	}
+
+	scyllaBatch := r.session.NewBatch(gocql.UnloggedBatch).WithContext(ctx)
+
+	for _, m := range batch.mutations {
+		switch mutation := m.(type) {
+		case upsertNotification:
+			n := mutation.notification
+
+			scyllaBatch.Query("update notification set notifications_count = ?, title = ?, body = ?, headline = ?, kind = ?, "+
+				"rendering_variables = ?, custom_data = ?, notification_info = ? where user_id = ? and event_type = ? "+
+				"and created_at = ? and entity_id = ? and related_entity_id = ?", n.NotificationsCount, n.Title, n.Body,
+				n.Headline, n.Kind, n.RenderingVariables, n.CustomData, n.NotificationInfo, n.UserId, n.EventType,
+				n.CreatedAt, n.EntityId, n.RelatedEntityId)
+		case deleteNotification:
+			scyllaBatch.Query("delete from notification where user_id = ? and event_type = ? and created_at = ? "+
+				"and entity_id = ? and related_entity_id = ?", mutation.userId, mutation.eventType, mutation.createdAt,
+				mutation.entityId, mutation.relatedEntityId)
+		case setEventApplied:
+			rel := mutation.relation
+
+			scyllaBatch.Query("update notification_relation set event_applied = ? where user_id = ? and event_type = ? "+
+				"and entity_id = ? and related_entity_id = ?", rel.EventApplied, rel.UserId, rel.EventType, rel.EntityId,
+				rel.RelatedEntityId)
+		case upsertGroupQueueItem:
+			item := mutation.item
+
+			scyllaBatch.Query("update push_notification_group_queue set created_at = ?, notification_count = ? "+
+				"where deadline_key = ? and deadline = ? and user_id = ? and event_type = ? and entity_id = ?",
+				item.CreatedAt, item.NotificationCount, item.DeadlineKey, item.Deadline, item.UserId, item.EventType,
+				item.EntityId)
+		case deleteGroupQueueItems:
+			if mutation.entityId.Valid {
+				scyllaBatch.Query("delete from push_notification_group_queue where deadline_key = ? and deadline = ? "+
+					"and user_id = ? and event_type = ? and entity_id = ?", mutation.deadlineKey, mutation.deadline,
+					mutation.userId, mutation.eventType, mutation.entityId.Int64)
+			} else {
+				scyllaBatch.Query("delete from push_notification_group_queue where deadline_key = ? and deadline = ? "+
+					"and user_id = ? and event_type = ?", mutation.deadlineKey, mutation.deadline, mutation.userId,
+					mutation.eventType)
+			}
+		default:
+			return errors.WithStack(fmt.Errorf("unsupported mutation %T", m))
+		}
+	}
+
+	if err := r.session.ExecuteBatch(scyllaBatch); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return nil
+}
+
+func (r *scyllaRepository) MarkRead(userId int64, notificationId int64, ctx context.Context) (bool, error) {
+	clusterKey := getUserNotificationsReadClusterKey(userId)
+
+	iter := r.session.Query("select notification_id from user_notifications_read where cluster_key = ? and notification_id = ? and user_id = ? limit 1;",
+		clusterKey, notificationId, userId).WithContext(ctx).Iter()
+
+	isNotificationAlreadyRead := iter.NumRows() > 0
+
+	if err := iter.Close(); err != nil {
+		return false, errors.WithStack(err)
+	}
+
+	if isNotificationAlreadyRead {
+		return false, nil
+	}
+
+	if err := r.session.Query("insert into user_notifications_read (cluster_key, notification_id, user_id) values (?, ?, ?)",
+		clusterKey, notificationId, userId).WithContext(ctx).Exec(); err != nil {
+		return false, errors.WithStack(err)
+	}
+
+	if err := r.session.Query("update user_notifications_read_counter set read_count = read_count + ? where notification_id = ?",
+		1, notificationId).WithContext(ctx).Exec(); err != nil {
+		return false, errors.WithStack(err)
+	}
+
+	return true, nil
+}
+
+func (r *scyllaRepository) GetReadCounts(notificationIds []int64, ctx context.Context) (map[int64]int64, error) {
+	readCounts := make(map[int64]int64)
+
+	if len(notificationIds) == 0 {
+		return readCounts, nil
+	}
+
+	iter := r.session.Query("select notification_id, read_count from user_notifications_read_counter where notification_id in ?",
+		notificationIds).WithContext(ctx).Iter()
+
+	var notificationId int64
+	var readCount int64
+
+	for iter.Scan(&notificationId, &readCount) {
+		readCounts[notificationId] = readCount
+	}
+
+	if err := iter.Close(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return readCounts, nil
+}
+
+func (r *scyllaRepository) GetSettings(userId int64, ctx context.Context) (map[string]bool, error) {
+	iter := r.session.Query("select template_id, muted from user_notifications_settings where "+
+		"cluster_key = ? and user_id = ?", database.GetUserNotificationsSettingsClusterKey(userId), userId).
+		WithContext(ctx).Iter()
+
+	settings := make(map[string]bool)
+
+	var templateId string
+	var muted bool
+
+	for iter.Scan(&templateId, &muted) {
+		settings[templateId] = muted
+	}
+
+	if err := iter.Close(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return settings, nil
+}
+
+func (r *scyllaRepository) SetSettings(userId int64, settings map[string]bool, ctx context.Context) error {
+	var currentBatch = r.session.NewBatch(gocql.UnloggedBatch).WithContext(ctx)
+	countStatements := 0
+
+	for templateId, muted := range settings {
+		currentBatch.Query("update user_notifications_settings set muted = ? where cluster_key = ? and user_id = ? and template_id = ?",
+			muted, database.GetUserNotificationsSettingsClusterKey(userId), userId, templateId)
+		countStatements++
+
+		if countStatements == scyllaSettingsBatchSize {
+			if err := r.session.ExecuteBatch(currentBatch); err != nil {
+				return errors.WithStack(err)
+			}
+
+			countStatements = 0
+			currentBatch = r.session.NewBatch(gocql.UnloggedBatch).WithContext(ctx)
+		}
+	}
+
+	if countStatements != 0 {
+		if err := r.session.ExecuteBatch(currentBatch); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	return nil
+}
+
+func (r *scyllaRepository) IsMuted(userId int64, templateId string, ctx context.Context) (bool, error) {
+	iter := r.session.Query("select muted from user_notifications_settings where "+
+		"cluster_key = ? and user_id = ? and template_id = ?", database.GetUserNotificationsSettingsClusterKey(userId),
+		userId, templateId).WithContext(ctx).Iter()
+
+	var muted bool
+
+	for iter.Scan(&muted) {
+		break
+	}
+
+	if err := iter.Close(); err != nil {
+		return false, errors.WithStack(err)
+	}
+
+	return muted, nil
+}
+
+func (r *scyllaRepository) GetUser(userId int64, ctx context.Context) (*scylla.User, error) {
+	var user scylla.User
+
+	userIter := r.session.Query("select user_id, username, email, firstname, lastname, name_privacy_status, language "+
+		"from user where cluster_key = ? and user_id = ?", scylla.GetUserClusterKey(userId), userId).WithContext(ctx).Iter()
+	userIter.Scan(&user.UserId, &user.Username, &user.Email, &user.Firstname, &user.Lastname, &user.NamePrivacyStatus, &user.Language)
+
+	if err := userIter.Close(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return &user, nil
+}
+
+func (r *scyllaRepository) UpsertUser(user scylla.User, ctx context.Context) error {
+	if err := r.session.Query("update user set username = ?, email = ?, firstname = ?, lastname = ?, "+
+		"name_privacy_status = ?, language = ? where cluster_key = ? and user_id = ?", user.Username, user.Email,
+		user.Firstname, user.Lastname, user.NamePrivacyStatus, user.Language, scylla.GetUserClusterKey(user.UserId),
+		user.UserId).WithContext(ctx).Exec(); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return nil
+}
+
+// getUserNotificationsReadClusterKey is the same as notification.GetUserNotificationsReadClusterKey
+func getUserNotificationsReadClusterKey(userId int64) int64 {
+	return userId / 10000
+}
@@ -0,0 +1,111 @@
+package storage
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/digitalmonsters/notification-handler/configs"
+	"github.com/digitalmonsters/notification-handler/pkg/database"
+	"github.com/digitalmonsters/notification-handler/pkg/database/scylla"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/guregu/null.v4"
+)
+
+type Mode string
+
+const (
+	ModeScylla   = Mode("scylla")
+	ModeDual     = Mode("dual")
+	ModePostgres = Mode("postgres")
+)
+
+// Same values as default_time_to_live of scylla tables
+const (
+	NotificationTtl = 90 * 24 * time.Hour
+	RelationTtl     = 90 * 24 * time.Hour
+	GroupQueueTtl   = 24 * time.Hour
+	UserTtl         = 90 * 24 * time.Hour
+)
+
+type RelationFilter struct {
+	UserId          int64
+	EventType       string
+	EntityId        null.Int
+	RelatedEntityId null.Int
+}
+
+type GroupQueueFilter struct {
+	UserId    null.Int
+	EventType null.String
+	EntityId  null.Int
+}
+
+// INotificationRepository covers notification feed, relations used for grouping and push grouping queue
+type INotificationRepository interface {
+	// GetFeed returns notifications of a feed view (see TypeGroupToScyllaViewName) ordered by created_at desc.
+	// Page is an opaque token returned as next page by previous call
+	GetFeed(userId int64, view string, page string, limit int, ctx context.Context) ([]scylla.Notification, string, error)
+	// GetNotification returns nil when notification does not exist
+	GetNotification(userId int64, eventType string, createdAt time.Time, entityId int64, ctx context.Context) (*scylla.Notification, error)
+	// GetNotificationsSince returns keys (entity_id, related_entity_id, created_at) ordered by created_at desc
+	GetNotificationsSince(userId int64, eventType string, since time.Time, ctx context.Context) ([]scylla.Notification, error)
+	GetRelations(filter RelationFilter, ctx context.Context) ([]scylla.NotificationRelation, error)
+	GetGroupQueue(deadlineKeys []time.Time, deadlines []time.Time, filter GroupQueueFilter, ctx context.Context) ([]scylla.PushNotificationGroupQueue, error)
+	ExecuteBatch(batch *Batch, ctx context.Context) error
+}
+
+type IReadRepository interface {
+	// MarkRead returns false when notification was already read by user
+	MarkRead(userId int64, notificationId int64, ctx context.Context) (bool, error)
+	GetReadCounts(notificationIds []int64, ctx context.Context) (map[int64]int64, error)
+}
+
+type ISettingsRepository interface {
+	GetSettings(userId int64, ctx context.Context) (map[string]bool, error)
+	SetSettings(userId int64, settings map[string]bool, ctx context.Context) error
+	IsMuted(userId int64, templateId string, ctx context.Context) (bool, error)
+}
+
+type IUserRepository interface {
+	// GetUser returns empty user when user is not cached
+	GetUser(userId int64, ctx context.Context) (*scylla.User, error)
+	UpsertUser(user scylla.User, ctx context.Context) error
+}
+
+type IRepository interface {
+	INotificationRepository
+	IReadRepository
+	ISettingsRepository
+	IUserRepository
+}
+
+var repository IRepository
+var repositoryOnce sync.Once
+
+// GetRepository returns repository configured by Storage settings, scylla is used by default
+func GetRepository() IRepository {
+	repositoryOnce.Do(func() {
+		cfg := configs.GetConfig().Storage
+
+		switch Mode(cfg.Mode) {
+		case ModePostgres:
+			repository = NewPostgresRepository(database.GetDb(database.DbTypeMaster))
+		case ModeDual:
+			scyllaRepository := NewScyllaRepository(database.GetScyllaSession())
+			postgresRepository := NewPostgresRepository(database.GetDb(database.DbTypeMaster))
+
+			if Mode(cfg.ReadFrom) == ModePostgres {
+				repository = NewDualRepository(postgresRepository, scyllaRepository)
+			} else {
+				repository = NewDualRepository(scyllaRepository, postgresRepository)
+			}
+		default:
+			repository = NewScyllaRepository(database.GetScyllaSession())
+		}
+
+		log.Info().Str("mode", cfg.Mode).Str("read_from", cfg.ReadFrom).Msg("[Storage] notification storage configured")
+	})
+
+	return repository
+}
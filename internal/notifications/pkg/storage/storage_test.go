@@ -0,0 +1,343 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/digitalmonsters/go-common/boilerplate_testing"
+	"github.com/digitalmonsters/notification-handler/configs"
+	"github.com/digitalmonsters/notification-handler/pkg/database"
+	"github.com/digitalmonsters/notification-handler/pkg/database/scylla"
+	"github.com/gocql/gocql"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+var config configs.Settings
+var gormDb *gorm.DB
+var session *gocql.Session
+
+func TestMain(m *testing.M) {
+	config = configs.GetConfig()
+	gormDb = database.GetDb(database.DbTypeMaster)
+	session = database.GetScyllaSession()
+	os.Exit(m.Run())
+}
+
+func flushPostgres(t *testing.T) {
+	if err := boilerplate_testing.FlushPostgresTables(config.MasterDb, []string{"public.notification_feed",
+		"public.notification_relations", "public.push_notification_group_queue", "public.user_notifications_settings",
+		"public.user_notifications_read", "public.user_notifications_read_counter", "public.notification_users",
+		"public.storage_backfill_progress"}, nil, t); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// feedRepositoryMock stands for scylla repository in dual mode, only GetFeed is expected to be called
+type feedRepositoryMock struct {
+	IRepository
+	pages []string
+}
+
+func (r *feedRepositoryMock) GetFeed(userId int64, view string, page string, limit int, ctx context.Context) ([]scylla.Notification, string, error) {
+	r.pages = append(r.pages, page)
+
+	return []scylla.Notification{{UserId: userId, EventType: "follow", EntityId: 100}}, "SCYLLAPAGE", nil
+}
+
+// addFeed writes count follow notifications of the user, created_at of the first two rows is the same
+func addFeed(t *testing.T, repo IRepository, userId int64, count int, createdAt time.Time) {
+	batch := NewBatch()
+
+	for i := 0; i < count; i++ {
+		notificationCreatedAt := createdAt.Add(-time.Duration(i) * time.Minute)
+		if i == 1 {
+			notificationCreatedAt = createdAt
+		}
+
+		batch.UpsertNotification(scylla.Notification{
+			UserId:             userId,
+			EventType:          "follow",
+			EntityId:           int64(i + 1),
+			CreatedAt:          notificationCreatedAt,
+			NotificationsCount: 1,
+			Title:              fmt.Sprintf("title %v", i+1),
+			NotificationInfo:   "{}",
+		})
+	}
+
+	if err := repo.ExecuteBatch(batch, context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPostgresRepository_GetFeedPaging(t *testing.T) {
+	flushPostgres(t)
+
+	repo := NewPostgresRepository(gormDb)
+	addFeed(t, repo, 1, 5, time.Now().UTC())
+	addFeed(t, repo, 2, 1, time.Now().UTC())
+
+	var entityIds []int64
+	page := ""
+
+	for i := 0; i < 3; i++ {
+		items, nextPage, err := repo.GetFeed(1, "notification_following", page, 2, context.TODO())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, item := range items {
+			assert.Equal(t, int64(1), item.UserId)
+			entityIds = append(entityIds, item.EntityId)
+		}
+
+		if i < 2 {
+			assert.True(t, isPostgresPage(nextPage))
+		} else {
+			assert.Empty(t, nextPage)
+		}
+
+		page = nextPage
+	}
+
+	// rows with the same created_at are ordered by the rest of the key
+	assert.Equal(t, []int64{1, 2, 3, 4, 5}, entityIds)
+
+	items, _, err := repo.GetFeed(1, "notification_comment", "", 10, context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Empty(t, items)
+
+	_, _, err = repo.GetFeed(1, "notification_following", "SCYLLAPAGE", 2, context.TODO())
+	assert.Error(t, err)
+}
+
+func TestDualRepository_GetFeedPageTokens(t *testing.T) {
+	flushPostgres(t)
+
+	postgres := NewPostgresRepository(gormDb)
+	addFeed(t, postgres, 1, 3, time.Now().UTC())
+
+	scyllaMock := &feedRepositoryMock{}
+
+	// reads are switched to postgres, scylla pages issued before the switch are continued by scylla
+	repo := NewDualRepository(postgres, scyllaMock)
+
+	items, nextPage, err := repo.GetFeed(1, "notification_following", "", 2, context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Len(t, items, 2)
+	assert.True(t, isPostgresPage(nextPage))
+	assert.Empty(t, scyllaMock.pages)
+
+	items, scyllaPage, err := repo.GetFeed(1, "notification_following", "SCYLLAPAGE", 2, context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Len(t, items, 1)
+	assert.Equal(t, "SCYLLAPAGE", scyllaPage)
+	assert.Equal(t, []string{"SCYLLAPAGE"}, scyllaMock.pages)
+
+	// reads are switched back to scylla, postgres pages are continued by postgres
+	repo = NewDualRepository(scyllaMock, postgres)
+
+	items, lastPage, err := repo.GetFeed(1, "notification_following", nextPage, 2, context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Len(t, items, 1)
+	assert.Equal(t, int64(3), items[0].EntityId)
+	assert.Empty(t, lastPage)
+	assert.Equal(t, []string{"SCYLLAPAGE"}, scyllaMock.pages)
+
+	if _, _, err = repo.GetFeed(1, "notification_following", "", 2, context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, []string{"SCYLLAPAGE", ""}, scyllaMock.pages)
+}
+
+func TestPostgresRepository_MarkRead(t *testing.T) {
+	flushPostgres(t)
+
+	repo := NewPostgresRepository(gormDb)
+
+	marked, err := repo.MarkRead(1, 10, context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.True(t, marked)
+
+	marked, err = repo.MarkRead(1, 10, context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.False(t, marked)
+
+	marked, err = repo.MarkRead(2, 10, context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.True(t, marked)
+
+	if _, err = repo.MarkRead(2, 11, context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+
+	readCounts, err := repo.GetReadCounts([]int64{10, 11, 12}, context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, map[int64]int64{10: 2, 11: 1}, readCounts)
+
+	readCounts, err = repo.GetReadCounts(nil, context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Empty(t, readCounts)
+}
+
+func TestPostgresRepository_Settings(t *testing.T) {
+	flushPostgres(t)
+
+	repo := NewPostgresRepository(gormDb)
+
+	if err := repo.SetSettings(1, map[string]bool{"content_like": true, "follow": false}, context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repo.SetSettings(2, map[string]bool{"follow": true}, context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+
+	settings, err := repo.GetSettings(1, context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, map[string]bool{"content_like": true, "follow": false}, settings)
+
+	for templateId, expected := range map[string]bool{"content_like": true, "follow": false, "tip": false} {
+		muted, err := repo.IsMuted(1, templateId, context.TODO())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, expected, muted, templateId)
+	}
+
+	if err = repo.SetSettings(1, map[string]bool{"content_like": false}, context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+
+	muted, err := repo.IsMuted(1, "content_like", context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.False(t, muted)
+
+	settings, err = repo.GetSettings(3, context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Empty(t, settings)
+}
+
+func TestBackfill_Resume(t *testing.T) {
+	flushPostgres(t)
+
+	if err := boilerplate_testing.FlushScyllaTables(t, session, config.Scylla.Keyspace,
+		[]string{"user_notifications_settings"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := NewScyllaRepository(session).SetSettings(1, map[string]bool{"content_like": true, "follow": true,
+		"tip": false, "comment_reply": true, "content_posted": false}, context.TODO()); err != nil {
+		t.Fatal(err)
+	}
+
+	var table backfillTable
+	for _, candidate := range backfillTables {
+		if candidate.name == "user_notifications_settings" {
+			table = candidate
+		}
+	}
+
+	// backfill was interrupted after the first page
+	iter := session.Query(table.query).PageSize(2).Iter()
+	pageState := iter.PageState()
+
+	if err := iter.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := gormDb.Create(&database.StorageBackfillProgress{
+		Name:        table.name,
+		PageState:   pageState,
+		CopiedCount: 2,
+		UpdatedAt:   time.Now().UTC(),
+	}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	req := BackfillRequest{Tables: []string{table.name}, BatchSize: 2}
+
+	results, err := Backfill(session, gormDb, req, context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Len(t, results, 1)
+	assert.Equal(t, int64(5), results[0].CopiedCount)
+	assert.True(t, results[0].CompletedAt.Valid)
+
+	var count int64
+	if err = gormDb.Model(&database.UserNotificationSetting{}).Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, int64(3), count)
+
+	// completed table is not copied again
+	results, err = Backfill(session, gormDb, req, context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, int64(5), results[0].CopiedCount)
+
+	req.Reset = true
+
+	results, err = Backfill(session, gormDb, req, context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, int64(5), results[0].CopiedCount)
+
+	if err = gormDb.Model(&database.UserNotificationSetting{}).Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, int64(5), count)
+
+	_, err = Backfill(session, gormDb, BackfillRequest{Tables: []string{"unknown"}}, context.TODO())
+	assert.Error(t, err)
+}
@@ -1,17 +1,27 @@
 package template
 
 import (
+	"context"
+	"crypto/sha1"
 	"fmt"
+	"github.com/digitalmonsters/go-common/callback"
+	"github.com/digitalmonsters/go-common/translation"
 	"github.com/digitalmonsters/notification-handler/pkg/database"
+	"github.com/digitalmonsters/notification-handler/pkg/renderer"
 	"github.com/pkg/errors"
 	"gopkg.in/guregu/null.v4"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"time"
 )
 
 type IService interface {
 	EditTemplate(req EditTemplateRequest, tx *gorm.DB) error
 	ListTemplates(req ListTemplatesRequest, db *gorm.DB) (*ListTemplatesResponse, error)
+	UpsertTemplateText(req UpsertTemplateTextRequest, adminId int64, tx *gorm.DB) (*database.RenderTemplateText, []callback.Callback, error)
+	RevertTemplateText(req RevertTemplateTextRequest, adminId int64, tx *gorm.DB) (*database.RenderTemplateText, []callback.Callback, error)
+	ListTemplateTextVersions(req ListTemplateTextVersionsRequest, db *gorm.DB) (*ListTemplateTextVersionsResponse, error)
+	PreviewTemplate(req PreviewTemplateRequest, db *gorm.DB) (*PreviewTemplateResponse, error)
 }
 
 type service struct {
@@ -39,6 +49,23 @@ func (s service) EditTemplate(req EditTemplateRequest, tx *gorm.DB) error {
 	template.Route = req.Route
 	template.ImageUrl = req.ImageUrl
 	template.Muted = req.Muted
+
+	if req.Category.Valid {
+		if !database.IsKnownCategory(req.Category.String) {
+			return errors.WithStack(errors.Errorf("unknown category %v", req.Category.String))
+		}
+
+		template.Category = req.Category.String
+	}
+
+	if req.DefaultPush.Valid {
+		template.DefaultPush = req.DefaultPush.Bool
+	}
+
+	if req.DefaultInApp.Valid {
+		template.DefaultInApp = req.DefaultInApp.Bool
+	}
+
 	template.UpdatedAt = time.Now().UTC()
 
 	if err := tx.Save(&template).Error; err != nil {
@@ -108,6 +135,10 @@ func (s service) ListTemplates(req ListTemplatesRequest, db *gorm.DB) (*ListTemp
 		query = query.Where("render_templates.muted = ?", req.Muted.ValueOrZero())
 	}
 
+	if len(req.Category) > 0 {
+		query = query.Where("render_templates.category = ?", req.Category)
+	}
+
 	if sortingArr := req.Sorting; len(sortingArr) > 0 {
 		for _, sorting := range sortingArr {
 			sortOrder := " asc"
@@ -139,15 +170,24 @@ func (s service) ListTemplates(req ListTemplatesRequest, db *gorm.DB) (*ListTemp
 
 	respItems := make([]*ListTemplateItem, len(templates))
 	for i, template := range templates {
+		texts, _, err := renderer.ResolveTexts(template.Id, translation.DefaultUserLanguage)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
 		respItems[i] = &ListTemplateItem{
-			Id: template.Id,
-			//Title:    template.Title,
-			//Body:     template.Body,
-			//Headline: template.Headline,
+			Id:       template.Id,
+			Title:    texts[database.TemplateFieldTitle],
+			Body:     texts[database.TemplateFieldBody],
+			Headline: texts[database.TemplateFieldHeadline],
 			Kind:     template.Kind,
 			Route:    template.Route,
 			ImageUrl: template.ImageUrl,
 			Muted:    template.Muted,
+
+			Category:     template.Category,
+			DefaultPush:  template.DefaultPush,
+			DefaultInApp: template.DefaultInApp,
 		}
 	}
 
@@ -156,3 +196,176 @@ func (s service) ListTemplates(req ListTemplatesRequest, db *gorm.DB) (*ListTemp
 		TotalCount: totalCount,
 	}, nil
 }
+
+func (s service) UpsertTemplateText(req UpsertTemplateTextRequest, adminId int64, tx *gorm.DB) (*database.RenderTemplateText, []callback.Callback, error) {
+	if !translation.SupportedLanguages[req.Language] {
+		return nil, nil, errors.WithStack(fmt.Errorf("language [%v] is not supported", req.Language))
+	}
+
+	text := database.RenderTemplateText{
+		TemplateId:       req.TemplateId,
+		Language:         req.Language,
+		Title:            req.Title,
+		Body:             req.Body,
+		Headline:         req.Headline,
+		TitleMultiple:    req.TitleMultiple,
+		BodyMultiple:     req.BodyMultiple,
+		HeadlineMultiple: req.HeadlineMultiple,
+	}
+
+	for _, field := range database.TemplateFields {
+		if v := text.GetField(field); v.Valid {
+			if err := renderer.ValidateText(v.String); err != nil {
+				return nil, nil, errors.Wrapf(err, "invalid template syntax in [%v]", field)
+			}
+		}
+	}
+
+	return s.saveTemplateTextVersion(text, adminId, tx)
+}
+
+func (s service) RevertTemplateText(req RevertTemplateTextRequest, adminId int64, tx *gorm.DB) (*database.RenderTemplateText, []callback.Callback, error) {
+	var text database.RenderTemplateText
+
+	if err := tx.Where("template_id = ? and language = ? and version = ?", req.TemplateId, req.Language, req.Version).
+		Find(&text).Error; err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+
+	if text.Id == 0 {
+		return nil, nil, errors.WithStack(errors.New("template text version not found"))
+	}
+
+	text.Id = 0
+
+	return s.saveTemplateTextVersion(text, adminId, tx)
+}
+
+// saveTemplateTextVersion locks the template row, so concurrent saves of the same template get sequential versions
+func (s service) saveTemplateTextVersion(text database.RenderTemplateText, adminId int64, tx *gorm.DB) (*database.RenderTemplateText, []callback.Callback, error) {
+	var template database.RenderTemplate
+
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", text.TemplateId).
+		Find(&template).Error; err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+
+	if len(template.Id) == 0 {
+		return nil, nil, errors.WithStack(errors.New("template not found"))
+	}
+
+	var lastVersion null.Int
+
+	if err := tx.Model(&database.RenderTemplateText{}).Where("template_id = ? and language = ?", text.TemplateId, text.Language).
+		Select("max(version)").Scan(&lastVersion).Error; err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+
+	text.Version = int(lastVersion.ValueOrZero()) + 1
+	text.CreatedBy = adminId
+	text.CreatedAt = time.Now().UTC()
+
+	if err := tx.Create(&text).Error; err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+
+	return &text, []callback.Callback{
+		func(ctx context.Context) error {
+			renderer.InvalidateTexts(text.TemplateId, text.Language)
+
+			return nil
+		},
+	}, nil
+}
+
+func (s service) ListTemplateTextVersions(req ListTemplateTextVersionsRequest, db *gorm.DB) (*ListTemplateTextVersionsResponse, error) {
+	query := db.Model(&database.RenderTemplateText{}).Where("template_id = ?", req.TemplateId)
+
+	if len(req.Language) > 0 {
+		query = query.Where("language = ?", req.Language)
+	}
+
+	var totalCount null.Int
+
+	if req.Offset == 0 {
+		var count int64
+		if err := query.Count(&count).Error; err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		totalCount = null.IntFrom(count)
+	}
+
+	if req.Limit > 0 {
+		query = query.Limit(req.Limit)
+	}
+
+	var items []database.RenderTemplateText
+
+	if err := query.Order("language asc").Order("version desc").Offset(req.Offset).Find(&items).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return &ListTemplateTextVersionsResponse{
+		Items:      items,
+		TotalCount: totalCount,
+	}, nil
+}
+
+// PreviewTemplate renders saved texts (or draft texts from the request, if set) with sample variables
+func (s service) PreviewTemplate(req PreviewTemplateRequest, db *gorm.DB) (*PreviewTemplateResponse, error) {
+	var template database.RenderTemplate
+
+	if err := db.Where("id = ?", req.TemplateId).Find(&template).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if len(template.Id) == 0 {
+		return nil, errors.WithStack(errors.New("template not found"))
+	}
+
+	language := req.Language
+	if len(language) == 0 {
+		language = translation.DefaultUserLanguage
+	}
+
+	texts, _, err := renderer.ResolveTexts(template.Id, language)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	draft := database.RenderTemplateText{
+		Title:            req.Title,
+		Body:             req.Body,
+		Headline:         req.Headline,
+		TitleMultiple:    req.TitleMultiple,
+		BodyMultiple:     req.BodyMultiple,
+		HeadlineMultiple: req.HeadlineMultiple,
+	}
+
+	rendered := make(map[string]string, len(database.TemplateFields))
+
+	for _, field := range database.TemplateFields {
+		body := texts[field]
+
+		if v := draft.GetField(field); v.Valid {
+			body = v.String
+		}
+
+		// content based name, so drafts never collide with cached production templates
+		name := fmt.Sprintf("preview_%x", sha1.Sum([]byte(body)))
+
+		if rendered[field], err = renderer.RenderText(name, body, req.RenderingVariables); err != nil {
+			return nil, errors.Wrapf(err, "can not render [%v]", field)
+		}
+	}
+
+	return &PreviewTemplateResponse{
+		Title:            rendered[database.TemplateFieldTitle],
+		Body:             rendered[database.TemplateFieldBody],
+		Headline:         rendered[database.TemplateFieldHeadline],
+		TitleMultiple:    rendered[database.TemplateFieldTitleMultiple],
+		BodyMultiple:     rendered[database.TemplateFieldBodyMultiple],
+		HeadlineMultiple: rendered[database.TemplateFieldHeadlineMultiple],
+	}, nil
+}
@@ -0,0 +1,217 @@
+package template
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/digitalmonsters/go-common/boilerplate_testing"
+	"github.com/digitalmonsters/go-common/callback"
+	"github.com/digitalmonsters/go-common/translation"
+	"github.com/digitalmonsters/notification-handler/configs"
+	"github.com/digitalmonsters/notification-handler/pkg/database"
+	"github.com/digitalmonsters/notification-handler/pkg/renderer"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/guregu/null.v4"
+	"gorm.io/gorm"
+)
+
+const testTemplateId = "first_daily_time_bonus"
+
+var config configs.Settings
+var gormDb *gorm.DB
+
+func TestMain(m *testing.M) {
+	config = configs.GetConfig()
+	gormDb = database.GetDb(database.DbTypeMaster)
+	os.Exit(m.Run())
+}
+
+func createTestTemplate(t *testing.T) {
+	if err := boilerplate_testing.FlushPostgresTables(config.MasterDb, []string{"public.render_template_texts"}, nil,
+		t); err != nil {
+		t.Fatal(err)
+	}
+
+	// texts are cached by the renderer, previous tests could leave them there
+	for _, language := range []translation.Language{translation.LanguageEn, translation.LanguageDe} {
+		renderer.InvalidateTexts(testTemplateId, language)
+	}
+
+	if err := gormDb.Save(&database.RenderTemplate{
+		Id:        testTemplateId,
+		Category:  database.CategorySystem,
+		CreatedAt: time.Now().UTC(),
+		UpdatedAt: time.Now().UTC(),
+	}).Error; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func previewHeadline(t *testing.T, service IService, language translation.Language) string {
+	resp, err := service.PreviewTemplate(PreviewTemplateRequest{
+		UpsertTemplateTextRequest: UpsertTemplateTextRequest{TemplateId: testTemplateId, Language: language},
+	}, gormDb)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return resp.Headline
+}
+
+func saveInTx(t *testing.T, fn func(tx *gorm.DB) (*database.RenderTemplateText, []callback.Callback, error)) (*database.RenderTemplateText, []callback.Callback) {
+	tx := gormDb.Begin()
+	defer tx.Rollback()
+
+	text, callbacks, err := fn(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = tx.Commit().Error; err != nil {
+		t.Fatal(err)
+	}
+
+	return text, callbacks
+}
+
+func runCallbacks(t *testing.T, callbacks []callback.Callback) {
+	for _, callbackFn := range callbacks {
+		assert.Nil(t, callbackFn(context.Background()))
+	}
+}
+
+func TestService_UpsertTemplateText(t *testing.T) {
+	createTestTemplate(t)
+
+	service := NewService()
+
+	// nothing is saved yet, so embedded translations are used
+	assert.Equal(t, "Glückwunsch!", previewHeadline(t, service, translation.LanguageDe))
+
+	for i, headline := range []string{"Hallo!", "Hallo again!"} {
+		text, callbacks := saveInTx(t, func(tx *gorm.DB) (*database.RenderTemplateText, []callback.Callback, error) {
+			return service.UpsertTemplateText(UpsertTemplateTextRequest{
+				TemplateId: testTemplateId,
+				Language:   translation.LanguageDe,
+				Headline:   null.StringFrom(headline),
+			}, 1, tx)
+		})
+
+		assert.Equal(t, i+1, text.Version)
+		assert.Equal(t, int64(1), text.CreatedBy)
+
+		runCallbacks(t, callbacks)
+	}
+
+	assert.Equal(t, "Hallo again!", previewHeadline(t, service, translation.LanguageDe))
+
+	// fields which were not edited still come from the embedded translations
+	resp, err := service.PreviewTemplate(PreviewTemplateRequest{
+		UpsertTemplateTextRequest: UpsertTemplateTextRequest{TemplateId: testTemplateId, Language: translation.LanguageDe},
+		RenderingVariables:        database.RenderingVariables{"daily_time_bonus": "5"},
+	}, gormDb)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Contains(t, resp.Title, "5 LIT-Punkte")
+
+	// other languages are not affected
+	assert.Equal(t, "Congrats!", previewHeadline(t, service, translation.LanguageEn))
+
+	tx := gormDb.Begin()
+	defer tx.Rollback()
+
+	_, _, err = service.UpsertTemplateText(UpsertTemplateTextRequest{
+		TemplateId: testTemplateId,
+		Language:   translation.LanguageDe,
+		Headline:   null.StringFrom("{{.name"),
+	}, 1, tx)
+	assert.NotNil(t, err)
+
+	_, _, err = service.UpsertTemplateText(UpsertTemplateTextRequest{
+		TemplateId: "unknown_template",
+		Language:   translation.LanguageDe,
+		Headline:   null.StringFrom("Hallo!"),
+	}, 1, tx)
+	assert.NotNil(t, err)
+}
+
+func TestService_UpsertTemplateText_InvalidatesCacheAfterCommit(t *testing.T) {
+	createTestTemplate(t)
+
+	service := NewService()
+
+	assert.Equal(t, "Glückwunsch!", previewHeadline(t, service, translation.LanguageDe))
+
+	_, callbacks := saveInTx(t, func(tx *gorm.DB) (*database.RenderTemplateText, []callback.Callback, error) {
+		return service.UpsertTemplateText(UpsertTemplateTextRequest{
+			TemplateId: testTemplateId,
+			Language:   translation.LanguageDe,
+			Headline:   null.StringFrom("Hallo!"),
+		}, 1, tx)
+	})
+
+	// cached texts are kept until callbacks run
+	assert.Equal(t, "Glückwunsch!", previewHeadline(t, service, translation.LanguageDe))
+
+	runCallbacks(t, callbacks)
+
+	assert.Equal(t, "Hallo!", previewHeadline(t, service, translation.LanguageDe))
+}
+
+func TestService_RevertTemplateText(t *testing.T) {
+	createTestTemplate(t)
+
+	service := NewService()
+
+	for _, headline := range []string{"first", "second"} {
+		_, callbacks := saveInTx(t, func(tx *gorm.DB) (*database.RenderTemplateText, []callback.Callback, error) {
+			return service.UpsertTemplateText(UpsertTemplateTextRequest{
+				TemplateId: testTemplateId,
+				Language:   translation.LanguageDe,
+				Headline:   null.StringFrom(headline),
+			}, 1, tx)
+		})
+
+		runCallbacks(t, callbacks)
+	}
+
+	assert.Equal(t, "second", previewHeadline(t, service, translation.LanguageDe))
+
+	text, callbacks := saveInTx(t, func(tx *gorm.DB) (*database.RenderTemplateText, []callback.Callback, error) {
+		return service.RevertTemplateText(RevertTemplateTextRequest{
+			TemplateId: testTemplateId,
+			Language:   translation.LanguageDe,
+			Version:    1,
+		}, 2, tx)
+	})
+
+	runCallbacks(t, callbacks)
+
+	// revert saves a copy as a new version, so history is kept
+	assert.Equal(t, 3, text.Version)
+	assert.Equal(t, int64(2), text.CreatedBy)
+	assert.Equal(t, "first", previewHeadline(t, service, translation.LanguageDe))
+
+	versions, err := service.ListTemplateTextVersions(ListTemplateTextVersionsRequest{
+		TemplateId: testTemplateId,
+		Language:   translation.LanguageDe,
+	}, gormDb)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, int64(3), versions.TotalCount.ValueOrZero())
+
+	tx := gormDb.Begin()
+	defer tx.Rollback()
+
+	_, _, err = service.RevertTemplateText(RevertTemplateTextRequest{
+		TemplateId: testTemplateId,
+		Language:   translation.LanguageDe,
+		Version:    10,
+	}, 2, tx)
+	assert.NotNil(t, err)
+}
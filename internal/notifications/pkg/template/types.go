@@ -1,6 +1,10 @@
 package template
 
-import "gopkg.in/guregu/null.v4"
+import (
+	"github.com/digitalmonsters/go-common/translation"
+	"github.com/digitalmonsters/notification-handler/pkg/database"
+	"gopkg.in/guregu/null.v4"
+)
 
 type EditTemplateRequest struct {
 	Id string `json:"id"`
@@ -11,6 +15,10 @@ type EditTemplateRequest struct {
 	Route    string `json:"route"`
 	ImageUrl string `json:"image_url"`
 	Muted    bool   `json:"muted"`
+	// preference settings are kept as is when not passed
+	Category     null.String `json:"category"`
+	DefaultPush  null.Bool   `json:"default_push"`
+	DefaultInApp null.Bool   `json:"default_in_app"`
 }
 
 type SortField string
@@ -33,6 +41,7 @@ type ListTemplatesRequest struct {
 	UpdatedAtFrom null.Time `json:"updated_at_from"`
 	UpdatedAtTo   null.Time `json:"updated_at_to"`
 	Muted         null.Bool `json:"muted"`
+	Category      string    `json:"category"`
 	Sorting       []Sorting `json:"sorting"`
 	Limit         int       `json:"limit"`
 	Offset        int       `json:"offset"`
@@ -52,4 +61,51 @@ type ListTemplateItem struct {
 	Route    string `json:"route"`
 	ImageUrl string `json:"image_url"`
 	Muted    bool   `json:"muted"`
+
+	Category     string `json:"category"`
+	DefaultPush  bool   `json:"default_push"`
+	DefaultInApp bool   `json:"default_in_app"`
+}
+
+type UpsertTemplateTextRequest struct {
+	TemplateId       string               `json:"template_id"`
+	Language         translation.Language `json:"language"`
+	Title            null.String          `json:"title"`
+	Body             null.String          `json:"body"`
+	Headline         null.String          `json:"headline"`
+	TitleMultiple    null.String          `json:"title_multiple"`
+	BodyMultiple     null.String          `json:"body_multiple"`
+	HeadlineMultiple null.String          `json:"headline_multiple"`
+}
+
+type RevertTemplateTextRequest struct {
+	TemplateId string               `json:"template_id"`
+	Language   translation.Language `json:"language"`
+	Version    int                  `json:"version"`
+}
+
+type ListTemplateTextVersionsRequest struct {
+	TemplateId string               `json:"template_id"`
+	Language   translation.Language `json:"language"`
+	Limit      int                  `json:"limit"`
+	Offset     int                  `json:"offset"`
+}
+
+type ListTemplateTextVersionsResponse struct {
+	Items      []database.RenderTemplateText `json:"items"`
+	TotalCount null.Int                      `json:"total_count"`
+}
+
+type PreviewTemplateRequest struct {
+	UpsertTemplateTextRequest
+	RenderingVariables database.RenderingVariables `json:"rendering_variables"`
+}
+
+type PreviewTemplateResponse struct {
+	Title            string `json:"title"`
+	Body             string `json:"body"`
+	Headline         string `json:"headline"`
+	TitleMultiple    string `json:"title_multiple"`
+	BodyMultiple     string `json:"body_multiple"`
+	HeadlineMultiple string `json:"headline_multiple"`
 }
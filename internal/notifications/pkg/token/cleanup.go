@@ -0,0 +1,80 @@
+package token
+
+import (
+	"context"
+	"time"
+
+	"github.com/RichardKnop/machinery/v1"
+	"github.com/RichardKnop/machinery/v1/tasks"
+	"github.com/digitalmonsters/go-common/apm_helper"
+	"github.com/digitalmonsters/go-common/boilerplate"
+	"github.com/digitalmonsters/notification-handler/configs"
+	"github.com/digitalmonsters/notification-handler/pkg/database"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+func RegisterCleanupTasks(jobber *machinery.Server) error {
+	if err := jobber.RegisterTask(string(configs.GeneralTokenCleanupTask), func() error {
+		apmTransaction := apm_helper.StartNewApmTransaction(string(configs.GeneralTokenCleanupTask),
+			"token_cleanup", nil, nil)
+
+		defer func() {
+			apmTransaction.End()
+		}()
+
+		ctx := boilerplate.CreateCustomContext(context.Background(), apmTransaction, log.Logger)
+
+		deleted, err := CleanupInactiveTokens(time.Now().UTC(), ctx)
+		apm_helper.AddApmLabel(apmTransaction, "deleted_count", deleted)
+
+		if err != nil {
+			apm_helper.LogError(errors.WithStack(err), ctx)
+			return errors.WithStack(err)
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	if err := jobber.RegisterPeriodicTask(configs.TokenCleanupJobCron,
+		string(configs.PeriodicTokenCleanupTask), &tasks.Signature{
+			Name: string(configs.GeneralTokenCleanupTask),
+		}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// CleanupInactiveTokens removes tokens of devices not seen for configured amount of days
+func CleanupInactiveTokens(currentDate time.Time, ctx context.Context) (int64, error) {
+	inactiveDays := configs.GetConfig().Tokens.InactiveDays
+	if inactiveDays <= 0 {
+		inactiveDays = configs.TokenDefaultInactiveDays
+	}
+
+	lastSeenBefore := currentDate.AddDate(0, 0, -inactiveDays)
+	db := database.GetDbWithContext(database.DbTypeMaster, ctx)
+
+	var total int64
+
+	for {
+		deleted, err := DeleteInactiveTokens(db, lastSeenBefore, configs.TokenCleanupBatchSize)
+		if err != nil {
+			return total, err
+		}
+
+		total += deleted
+
+		if deleted < configs.TokenCleanupBatchSize {
+			break
+		}
+	}
+
+	log.Ctx(ctx).Info().Int64("deleted_count", total).Time("last_seen_before", lastSeenBefore).
+		Msg("[TokenCleanup] inactive tokens removed")
+
+	return total, nil
+}
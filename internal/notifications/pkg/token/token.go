@@ -1,36 +1,60 @@
 package token
 
 import (
+	"time"
+
+	"github.com/digitalmonsters/go-common/common"
 	"github.com/digitalmonsters/notification-handler/pkg/database"
 	"github.com/pkg/errors"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
-	"time"
 )
 
-func GetUserTokens(db *gorm.DB, userId int64) ([]database.Device, error) {
+// GetUserTokens returns devices of the user, only devices of the given platforms when platforms are set
+func GetUserTokens(db *gorm.DB, userId int64, platforms ...common.DeviceType) ([]database.Device, error) {
 	var records []database.Device
 
-	if err := db.Where("\"userId\" = ?", userId).Find(&records).Error; err != nil {
+	query := db.Where("\"userId\" = ?", userId)
+
+	if len(platforms) > 0 {
+		query = query.Where("platform in ?", platforms)
+	}
+
+	if err := query.Find(&records).Error; err != nil {
 		return nil, errors.WithStack(err)
 	}
 
 	return records, nil
 }
 
+// CreateToken registers push token for the user device. Push token belongs to a single user only, so after account
+// switch on the same device the token is moved to the newest user
 func CreateToken(db *gorm.DB, userId int64, req TokenCreateRequest) (*TokenCreateResponse, error) {
+	if len(req.DeviceId) == 0 || len(req.PushToken) == 0 {
+		return nil, errors.New("device_id and push_token are required")
+	}
+
+	now := time.Now().UTC()
+
 	device := database.Device{
-		UserId:    userId,
-		DeviceId:  req.DeviceId,
-		PushToken: req.PushToken,
-		Platform:  req.Platform,
+		UserId:     userId,
+		DeviceId:   req.DeviceId,
+		PushToken:  req.PushToken,
+		Platform:   req.Platform,
+		UpdatedAt:  now,
+		LastSeenAt: now,
+	}
+
+	if err := db.Exec("delete from \"devices\" where \"pushToken\" = ? and not (\"userId\" = ? and \"deviceId\" = ?)",
+		req.PushToken, userId, req.DeviceId).Error; err != nil {
+		return nil, errors.WithStack(err)
 	}
 
 	if err := db.Clauses(clause.OnConflict{
 		Columns:   []clause.Column{{Name: "userId"}, {Name: "deviceId"}},
 		UpdateAll: true,
 	}).Create(&device).Error; err != nil {
-		return nil, err
+		return nil, errors.WithStack(err)
 	}
 
 	return &TokenCreateResponse{
@@ -39,14 +63,36 @@ func CreateToken(db *gorm.DB, userId int64, req TokenCreateRequest) (*TokenCreat
 		DeviceId:  device.DeviceId,
 		PushToken: device.PushToken,
 		Platform:  device.Platform,
-		CreatedAt: time.Now().UTC(),
+		CreatedAt: now,
 	}, nil
 }
 
 func DeleteToken(db *gorm.DB, userId int64, deviceId string) error {
-	if err := db.Where("delete from \"devices\" where \"deviceId\" = ? and \"userId\" = ?", deviceId, userId).Error; err != nil {
-		return err
+	if err := db.Exec("delete from \"devices\" where \"deviceId\" = ? and \"userId\" = ?", deviceId, userId).Error; err != nil {
+		return errors.WithStack(err)
+	}
+
+	return nil
+}
+
+// TouchToken refreshes last_seen_at of the user device, it is called on app open
+func TouchToken(db *gorm.DB, userId int64, deviceId string) error {
+	if err := db.Model(&database.Device{}).Where("\"userId\" = ? and \"deviceId\" = ?", userId, deviceId).
+		Update("last_seen_at", time.Now().UTC()).Error; err != nil {
+		return errors.WithStack(err)
 	}
 
 	return nil
 }
+
+// DeleteInactiveTokens removes up to limit tokens of devices which were not seen since the given date
+func DeleteInactiveTokens(db *gorm.DB, lastSeenBefore time.Time, limit int) (int64, error) {
+	res := db.Exec("delete from \"devices\" where ctid in (select ctid from \"devices\" where last_seen_at < ? limit ?)",
+		lastSeenBefore, limit)
+
+	if res.Error != nil {
+		return 0, errors.WithStack(res.Error)
+	}
+
+	return res.RowsAffected, nil
+}
@@ -0,0 +1,167 @@
+package token
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/digitalmonsters/go-common/boilerplate_testing"
+	"github.com/digitalmonsters/go-common/common"
+	"github.com/digitalmonsters/notification-handler/configs"
+	"github.com/digitalmonsters/notification-handler/pkg/database"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+var config configs.Settings
+var gormDb *gorm.DB
+
+func TestMain(m *testing.M) {
+	config = configs.GetConfig()
+	gormDb = database.GetDb(database.DbTypeMaster)
+	os.Exit(m.Run())
+}
+
+func flushDevices(t *testing.T) {
+	if err := boilerplate_testing.FlushPostgresTables(config.MasterDb, []string{"public.devices"}, nil, t); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func getDevices(t *testing.T) []database.Device {
+	var devices []database.Device
+	if err := gormDb.Order("\"userId\", \"deviceId\"").Find(&devices).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	return devices
+}
+
+func TestCreateToken(t *testing.T) {
+	flushDevices(t)
+
+	resp, err := CreateToken(gormDb, 1, TokenCreateRequest{DeviceId: "device_1", PushToken: "token_1",
+		Platform: common.DeviceTypeIos})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "token_1", resp.PushToken)
+
+	// same device of the same user updates the token
+	if _, err = CreateToken(gormDb, 1, TokenCreateRequest{DeviceId: "device_1", PushToken: "token_2",
+		Platform: common.DeviceTypeIos}); err != nil {
+		t.Fatal(err)
+	}
+
+	devices := getDevices(t)
+	if assert.Len(t, devices, 1) {
+		assert.Equal(t, "token_2", devices[0].PushToken)
+	}
+
+	_, err = CreateToken(gormDb, 1, TokenCreateRequest{DeviceId: "device_1"})
+	assert.NotNil(t, err)
+}
+
+func TestCreateToken_MovesTokenToSecondUser(t *testing.T) {
+	flushDevices(t)
+
+	if _, err := CreateToken(gormDb, 1, TokenCreateRequest{DeviceId: "device_1", PushToken: "token_1",
+		Platform: common.DeviceTypeAndroid}); err != nil {
+		t.Fatal(err)
+	}
+
+	// account switch on the same device
+	if _, err := CreateToken(gormDb, 2, TokenCreateRequest{DeviceId: "device_1", PushToken: "token_1",
+		Platform: common.DeviceTypeAndroid}); err != nil {
+		t.Fatal(err)
+	}
+
+	devices := getDevices(t)
+	if assert.Len(t, devices, 1) {
+		assert.Equal(t, int64(2), devices[0].UserId)
+		assert.Equal(t, "token_1", devices[0].PushToken)
+	}
+}
+
+func TestDeleteToken(t *testing.T) {
+	flushDevices(t)
+
+	for _, userId := range []int64{1, 2} {
+		if _, err := CreateToken(gormDb, userId, TokenCreateRequest{DeviceId: "device_1",
+			PushToken: fmt.Sprintf("token_%v", userId), Platform: common.DeviceTypeIos}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	assert.Nil(t, DeleteToken(gormDb, 1, "device_1"))
+
+	devices := getDevices(t)
+	if assert.Len(t, devices, 1) {
+		assert.Equal(t, int64(2), devices[0].UserId)
+	}
+
+	// unknown device is not an error
+	assert.Nil(t, DeleteToken(gormDb, 1, "device_1"))
+}
+
+func TestTouchToken(t *testing.T) {
+	flushDevices(t)
+
+	lastSeenAt := time.Now().UTC().AddDate(0, 0, -5)
+
+	if err := gormDb.Create(&database.Device{
+		UserId:     1,
+		DeviceId:   "device_1",
+		PushToken:  "token_1",
+		Platform:   common.DeviceTypeIos,
+		UpdatedAt:  lastSeenAt,
+		LastSeenAt: lastSeenAt,
+	}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	touchedAfter := time.Now().UTC().Add(-time.Second)
+
+	assert.Nil(t, TouchToken(gormDb, 1, "device_1"))
+
+	devices := getDevices(t)
+	if assert.Len(t, devices, 1) {
+		assert.True(t, devices[0].LastSeenAt.After(touchedAfter))
+	}
+}
+
+func TestDeleteInactiveTokens(t *testing.T) {
+	flushDevices(t)
+
+	now := time.Now().UTC()
+
+	for i, lastSeenAt := range []time.Time{now.AddDate(0, 0, -100), now.AddDate(0, 0, -95), now.AddDate(0, 0, -1)} {
+		userId := int64(i + 1)
+
+		if err := gormDb.Create(&database.Device{
+			UserId:     userId,
+			DeviceId:   "device",
+			PushToken:  fmt.Sprintf("token_%v", userId),
+			Platform:   common.DeviceTypeIos,
+			UpdatedAt:  lastSeenAt,
+			LastSeenAt: lastSeenAt,
+		}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// removed in batches of limit rows
+	for _, expected := range []int64{1, 1, 0} {
+		deleted, err := DeleteInactiveTokens(gormDb, now.AddDate(0, 0, -90), 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, expected, deleted)
+	}
+
+	devices := getDevices(t)
+	if assert.Len(t, devices, 1) {
+		assert.Equal(t, int64(3), devices[0].UserId)
+	}
+}
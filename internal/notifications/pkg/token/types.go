@@ -23,3 +23,7 @@ type TokenCreateResponse struct {
 	UpdatedAt null.Time         `json:"updatedAt"`
 	DeletedAt null.Time         `json:"deletedAt"`
 }
+
+type TokenDeviceRequest struct {
+	DeviceId string `json:"device_id"`
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/digitalmonsters/go-common/wrappers/user_go"
 	"github.com/digitalmonsters/notification-handler/pkg/database"
 	"github.com/digitalmonsters/notification-handler/pkg/database/scylla"
+	"github.com/digitalmonsters/notification-handler/pkg/storage"
 	"github.com/patrickmn/go-cache"
 	"github.com/pkg/errors"
 	"github.com/romanyx/polluter"
@@ -63,21 +64,14 @@ func GetUser(userId int64, ctx context.Context) (*scylla.User, error) {
 		return v.(*scylla.User), nil
 	}
 
-	session := database.GetScyllaSession()
-
-	var user scylla.User
-
-	userIter := session.Query("select user_id, username, email, firstname, lastname, name_privacy_status, language "+
-		"from user where cluster_key = ? and user_id = ?", scylla.GetUserClusterKey(userId), userId).WithContext(ctx).Iter()
-	userIter.Scan(&user.UserId, &user.Username, &user.Email, &user.Firstname, &user.Lastname, &user.NamePrivacyStatus, &user.Language)
-
-	if err := userIter.Close(); err != nil {
-		return nil, errors.WithStack(err)
+	user, err := storage.GetRepository().GetUser(userId, ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	localCache.SetDefault(cacheKey, &user)
+	localCache.SetDefault(cacheKey, user)
 
-	return &user, nil
+	return user, nil
 }
 
 func GetUserRenderingVariablesWithLanguage(userId int64, ctx context.Context) (database.RenderingVariables, translation.Language, error) {
@@ -0,0 +1,96 @@
+package notifications
+
+import (
+	"net/http"
+
+	"github.com/digitalmonsters/go-common/router"
+	"github.com/digitalmonsters/notification-handler/pkg/database"
+	"github.com/digitalmonsters/notification-handler/pkg/settings"
+)
+
+func getPreferences(service settings.IService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		resp, err := service.GetPreferences(router.UserIdFromHttpRequest(r), r.Context(),
+			database.GetDbWithContext(database.DbTypeReadonly, r.Context()))
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		writeResponse(w, resp)
+	}
+}
+
+func changePreferences(service settings.IService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req settings.ChangePreferencesRequest
+
+		if err := decodeRequest(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		applyPreferencesChange(w, r, service, req, router.UserIdFromHttpRequest(r))
+	}
+}
+
+func getPreferencesByAdmin(service settings.IService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req settings.GetPreferencesByAdminRequest
+
+		if err := decodeRequest(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		resp, err := service.GetPreferences(req.UserId, r.Context(),
+			database.GetDbWithContext(database.DbTypeReadonly, r.Context()))
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		writeResponse(w, resp)
+	}
+}
+
+func changePreferencesByAdmin(service settings.IService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req settings.ChangePreferencesByAdminRequest
+
+		if err := decodeRequest(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		applyPreferencesChange(w, r, service, req.ChangePreferencesRequest, req.UserId)
+	}
+}
+
+func applyPreferencesChange(w http.ResponseWriter, r *http.Request, service settings.IService,
+	req settings.ChangePreferencesRequest, userId int64) {
+	tx := database.GetDbWithContext(database.DbTypeMaster, r.Context()).Begin()
+	defer tx.Rollback()
+
+	callbacks, err := service.ChangePreferences(req, userId, r.Context(), tx)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err = tx.Commit().Error; err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	// push overrides are a part of the change, so unlike notification callbacks their errors are returned.
+	// The change is idempotent and can be retried
+	for _, callbackFn := range callbacks {
+		if err = callbackFn(r.Context()); err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	writeResponse(w, nil)
+}
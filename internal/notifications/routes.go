@@ -1,14 +1,105 @@
 package notifications
 
 import (
-  "net/http"
-  "github.com/go-chi/chi/v5"
+	"context"
+	"net/http"
+
+	"github.com/digitalmonsters/go-common/router"
+	"github.com/digitalmonsters/go-common/wrappers/auth_go"
+	"github.com/digitalmonsters/go-common/wrappers/user_go"
+	"github.com/digitalmonsters/notification-handler/configs"
+	"github.com/digitalmonsters/notification-handler/pkg/admin_query"
+	"github.com/digitalmonsters/notification-handler/pkg/analytics"
+	"github.com/digitalmonsters/notification-handler/pkg/campaign"
+	"github.com/digitalmonsters/notification-handler/pkg/realtime"
+	"github.com/digitalmonsters/notification-handler/pkg/settings"
+	"github.com/digitalmonsters/notification-handler/pkg/template"
+	"github.com/go-chi/chi/v5"
 )
 
 func RegisterRoutes(r chi.Router) {
-  r.Route("/notifications", func(rr chi.Router) {
-    rr.Get("/ping", func(w http.ResponseWriter, r *http.Request) {
-      w.Write([]byte("notifications ok"))
-    })
-  })
+	templateService := template.NewService()
+	campaignService := campaign.NewService()
+	analyticsService := analytics.NewService()
+	settingsService := settings.NewService()
+	cfg := configs.GetConfig()
+	ctx := context.Background()
+	authGoWrapper := auth_go.NewAuthGoWrapper(cfg.Wrappers.AuthGo)
+	adminQueryService := admin_query.NewService(authGoWrapper)
+	broker := realtime.GetBroker()
+	auth := router.NewHttpAuth(authGoWrapper, writeError)
+
+	userWrapper := user_go.NewUserGoWrapper(cfg.Wrappers.UserGo)
+
+	jobber := newJobber(cfg)
+	registerTasks(jobber, cfg, settingsService, userWrapper, ctx)
+	startWorker(jobber, cfg)
+	startEmailConsumer(cfg, settingsService, userWrapper, ctx)
+
+	r.Route("/notifications", func(rr chi.Router) {
+		rr.Get("/ping", func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("notifications ok"))
+		})
+
+		rr.Post("/internal/create", internalCreateNotification)
+		rr.Post("/internal/in_app/create", internalCreateInAppNotification)
+
+		rr.With(auth.RequireUser).Post("/read_all", readAllNotifications)
+
+		rr.Route("/tokens", func(tr chi.Router) {
+			tr.Use(auth.RequireUser)
+
+			tr.Post("/register", registerToken)
+			tr.Post("/delete", deleteToken)
+			tr.Post("/touch", touchToken)
+		})
+
+		rr.Route("/preferences", func(pr chi.Router) {
+			pr.Use(auth.RequireUser)
+
+			pr.Post("/get", getPreferences(settingsService))
+			pr.Post("/change", changePreferences(settingsService))
+		})
+
+		rr.Route("/stream", func(sr chi.Router) {
+			sr.Use(auth.RequireUser)
+
+			sr.Get("/ws", streamWebSocket(broker, cfg.Realtime.AllowedOrigins))
+			sr.Get("/sse", streamSse(broker))
+			sr.Post("/ack", streamAck)
+		})
+
+		rr.Route("/admin", func(ar chi.Router) {
+			ar.With(auth.RequireAdmin("notifications:templates:view")).Post("/templates/list", listTemplates(templateService))
+			ar.With(auth.RequireAdmin("notifications:templates:edit")).Post("/templates/edit", editTemplate(templateService))
+			ar.With(auth.RequireAdmin("notifications:templates:edit")).Post("/templates/texts/upsert",
+				upsertTemplateText(templateService))
+			ar.With(auth.RequireAdmin("notifications:templates:edit")).Post("/templates/texts/revert",
+				revertTemplateText(templateService))
+			ar.With(auth.RequireAdmin("notifications:templates:view")).Post("/templates/texts/versions",
+				listTemplateTextVersions(templateService))
+			ar.With(auth.RequireAdmin("notifications:templates:view")).Post("/templates/preview",
+				previewTemplate(templateService))
+
+			ar.With(auth.RequireAdmin("notifications:campaigns:edit")).Post("/campaigns/create", createCampaign(campaignService))
+			ar.With(auth.RequireAdmin("notifications:campaigns:edit")).Post("/campaigns/cancel", cancelCampaign(campaignService))
+			ar.With(auth.RequireAdmin("notifications:campaigns:view")).Post("/campaigns/get", getCampaign(campaignService))
+			ar.With(auth.RequireAdmin("notifications:campaigns:view")).Post("/campaigns/list", listCampaigns(campaignService))
+
+			ar.With(auth.RequireAdmin("notifications:analytics:view")).Post("/analytics/templates",
+				templateStats(analyticsService))
+
+			ar.With(auth.RequireAdmin("notifications:preferences:view")).Post("/preferences/get",
+				getPreferencesByAdmin(settingsService))
+			ar.With(auth.RequireAdmin("notifications:preferences:edit")).Post("/preferences/change",
+				changePreferencesByAdmin(settingsService))
+
+			ar.With(auth.RequireAdmin("notifications:queries:logs")).Post("/queries/logs",
+				listAdminQueryLogs(adminQueryService))
+
+			// queries are checked by their own rbac objects in admin_query service
+			ar.With(auth.RequireAdmin("")).Post("/queries/list", listAdminQueries(adminQueryService))
+			ar.With(auth.RequireAdmin("")).Post("/queries/run", runAdminQuery(adminQueryService))
+		})
+	})
 }
@@ -0,0 +1,141 @@
+package notifications
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/digitalmonsters/go-common/router"
+	"github.com/digitalmonsters/notification-handler/pkg/database"
+	"github.com/digitalmonsters/notification-handler/pkg/notification"
+	"github.com/digitalmonsters/notification-handler/pkg/realtime"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/net/websocket"
+)
+
+const sseHeartbeatInterval = 25 * time.Second
+
+// streamWebSocket delivers realtime events over WebSocket and accepts ack messages from the client
+func streamWebSocket(broker realtime.IBroker, allowedOrigins []string) http.HandlerFunc {
+	server := websocket.Server{
+		Handshake: func(config *websocket.Config, r *http.Request) error {
+			// websocket is not covered by cors, so forward-auth alone lets any site stream with cookies of the user
+			if origin := r.Header.Get("Origin"); !realtime.IsOriginAllowed(origin, allowedOrigins) {
+				return errors.New(fmt.Sprintf("origin %v is not allowed", origin))
+			}
+
+			return nil
+		},
+		Handler: func(conn *websocket.Conn) {
+			defer conn.Close()
+
+			r := conn.Request()
+			userId := router.UserIdFromHttpRequest(r)
+
+			events, unsubscribe := broker.Subscribe(userId)
+			defer unsubscribe()
+
+			go func() {
+				defer unsubscribe()
+
+				for {
+					var msg realtime.ClientMessage
+
+					if err := websocket.JSON.Receive(conn, &msg); err != nil {
+						return
+					}
+
+					if msg.Type != realtime.ClientMessageTypeAck {
+						continue
+					}
+
+					if err := notification.AckInAppNotifications(database.GetDbWithContext(database.DbTypeMaster, r.Context()),
+						userId, msg.Ids); err != nil {
+						log.Ctx(r.Context()).Err(err).Int64("user_id", userId).Send()
+					}
+				}
+			}()
+
+			notification.PublishUnreadNotificationsCount(database.GetDbWithContext(database.DbTypeReadonly, r.Context()),
+				userId, r.Context())
+
+			for event := range events {
+				if err := websocket.JSON.Send(conn, event); err != nil {
+					return
+				}
+			}
+		},
+	}
+
+	return server.ServeHTTP
+}
+
+// streamSse delivers realtime events as server-sent events, in-app notifications are acknowledged with streamAck
+func streamSse(broker realtime.IBroker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			writeError(w, http.StatusInternalServerError, errors.New("streaming is not supported"))
+			return
+		}
+
+		userId := router.UserIdFromHttpRequest(r)
+
+		events, unsubscribe := broker.Subscribe(userId)
+		defer unsubscribe()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		notification.PublishUnreadNotificationsCount(database.GetDbWithContext(database.DbTypeReadonly, r.Context()),
+			userId, r.Context())
+
+		heartbeat := time.NewTicker(sseHeartbeatInterval)
+		defer heartbeat.Stop()
+
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-heartbeat.C:
+				if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+					return
+				}
+			case event := <-events:
+				data, err := json.Marshal(event)
+				if err != nil {
+					log.Ctx(r.Context()).Err(errors.WithStack(err)).Send()
+					continue
+				}
+
+				if _, err = fmt.Fprintf(w, "event: %v\ndata: %s\n\n", event.Type, data); err != nil {
+					return
+				}
+			}
+
+			flusher.Flush()
+		}
+	}
+}
+
+func streamAck(w http.ResponseWriter, r *http.Request) {
+	var req realtime.ClientMessage
+
+	if err := decodeRequest(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := notification.AckInAppNotifications(database.GetDbWithContext(database.DbTypeMaster, r.Context()),
+		router.UserIdFromHttpRequest(r), req.Ids); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeResponse(w, nil)
+}
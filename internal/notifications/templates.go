@@ -0,0 +1,150 @@
+package notifications
+
+import (
+	"net/http"
+
+	"github.com/digitalmonsters/go-common/router"
+	"github.com/digitalmonsters/notification-handler/pkg/database"
+	"github.com/digitalmonsters/notification-handler/pkg/template"
+)
+
+func listTemplates(service template.IService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req template.ListTemplatesRequest
+
+		if err := decodeRequest(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		resp, err := service.ListTemplates(req, database.GetDbWithContext(database.DbTypeReadonly, r.Context()))
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		writeResponse(w, resp)
+	}
+}
+
+func editTemplate(service template.IService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req template.EditTemplateRequest
+
+		if err := decodeRequest(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		tx := database.GetDbWithContext(database.DbTypeMaster, r.Context()).Begin()
+		defer tx.Rollback()
+
+		if err := service.EditTemplate(req, tx); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := tx.Commit().Error; err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		writeResponse(w, nil)
+	}
+}
+
+func upsertTemplateText(service template.IService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req template.UpsertTemplateTextRequest
+
+		if err := decodeRequest(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		tx := database.GetDbWithContext(database.DbTypeMaster, r.Context()).Begin()
+		defer tx.Rollback()
+
+		resp, callbacks, err := service.UpsertTemplateText(req, router.AdminIdFromHttpRequest(r), tx)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		if err = tx.Commit().Error; err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		runCallbacks(callbacks, r.Context())
+
+		writeResponse(w, resp)
+	}
+}
+
+func revertTemplateText(service template.IService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req template.RevertTemplateTextRequest
+
+		if err := decodeRequest(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		tx := database.GetDbWithContext(database.DbTypeMaster, r.Context()).Begin()
+		defer tx.Rollback()
+
+		resp, callbacks, err := service.RevertTemplateText(req, router.AdminIdFromHttpRequest(r), tx)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		if err = tx.Commit().Error; err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		runCallbacks(callbacks, r.Context())
+
+		writeResponse(w, resp)
+	}
+}
+
+func listTemplateTextVersions(service template.IService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req template.ListTemplateTextVersionsRequest
+
+		if err := decodeRequest(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		resp, err := service.ListTemplateTextVersions(req, database.GetDbWithContext(database.DbTypeReadonly, r.Context()))
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		writeResponse(w, resp)
+	}
+}
+
+func previewTemplate(service template.IService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req template.PreviewTemplateRequest
+
+		if err := decodeRequest(r, &req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		resp, err := service.PreviewTemplate(req, database.GetDbWithContext(database.DbTypeReadonly, r.Context()))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		writeResponse(w, resp)
+	}
+}
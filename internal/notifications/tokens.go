@@ -0,0 +1,68 @@
+package notifications
+
+import (
+	"net/http"
+
+	"github.com/digitalmonsters/go-common/router"
+	"github.com/digitalmonsters/notification-handler/pkg/database"
+	"github.com/digitalmonsters/notification-handler/pkg/token"
+)
+
+func registerToken(w http.ResponseWriter, r *http.Request) {
+	var req token.TokenCreateRequest
+
+	if err := decodeRequest(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	tx := database.GetDbWithContext(database.DbTypeMaster, r.Context()).Begin()
+	defer tx.Rollback()
+
+	resp, err := token.CreateToken(tx, router.UserIdFromHttpRequest(r), req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err = tx.Commit().Error; err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeResponse(w, resp)
+}
+
+func deleteToken(w http.ResponseWriter, r *http.Request) {
+	var req token.TokenDeviceRequest
+
+	if err := decodeRequest(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := token.DeleteToken(database.GetDbWithContext(database.DbTypeMaster, r.Context()),
+		router.UserIdFromHttpRequest(r), req.DeviceId); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeResponse(w, nil)
+}
+
+func touchToken(w http.ResponseWriter, r *http.Request) {
+	var req token.TokenDeviceRequest
+
+	if err := decodeRequest(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := token.TouchToken(database.GetDbWithContext(database.DbTypeMaster, r.Context()),
+		router.UserIdFromHttpRequest(r), req.DeviceId); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeResponse(w, nil)
+}
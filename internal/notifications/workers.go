@@ -0,0 +1,108 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/RichardKnop/machinery/v1"
+	"github.com/digitalmonsters/go-common/boilerplate"
+	"github.com/digitalmonsters/go-common/wrappers/notification_gateway"
+	"github.com/digitalmonsters/go-common/wrappers/user_go"
+	"github.com/digitalmonsters/notification-handler/configs"
+	"github.com/digitalmonsters/notification-handler/pkg/campaign"
+	"github.com/digitalmonsters/notification-handler/pkg/firebase"
+	"github.com/digitalmonsters/notification-handler/pkg/kafka"
+	"github.com/digitalmonsters/notification-handler/pkg/mail"
+	"github.com/digitalmonsters/notification-handler/pkg/sender"
+	"github.com/digitalmonsters/notification-handler/pkg/settings"
+	"github.com/digitalmonsters/notification-handler/pkg/storage"
+	"github.com/digitalmonsters/notification-handler/pkg/token"
+	"github.com/rs/zerolog/log"
+)
+
+const workerName = "notification_handler"
+
+// newJobber creates machinery server shared by all notification background tasks. Tasks should be registered before
+// startWorker is called
+func newJobber(cfg configs.Settings) *machinery.Server {
+	jobber, err := configs.GetJobber(&cfg.Jobber)
+	if err != nil {
+		log.Fatal().Err(err).Msg("[Notifications] can not create jobber")
+	}
+
+	return jobber
+}
+
+// newFirebaseClient returns nil when service account is not configured, pushes sent with firebase then fail
+func newFirebaseClient(cfg configs.Settings, ctx context.Context) *firebase.FirebaseClient {
+	if len(cfg.Firebase.ServiceAccountJSON) == 0 {
+		log.Warn().Msg("[Notifications] firebase service account is not configured")
+		return nil
+	}
+
+	serviceAccount, err := json.Marshal(cfg.Firebase.ServiceAccountJSON)
+	if err != nil {
+		log.Fatal().Err(err).Msg("[Notifications] invalid firebase service account")
+	}
+
+	return firebase.Initialize(ctx, string(serviceAccount))
+}
+
+// registerTasks creates push sender and campaign worker and registers their tasks and token and storage cleanup
+// on jobber
+func registerTasks(jobber *machinery.Server, cfg configs.Settings, settingsService settings.IService,
+	userWrapper user_go.IUserGoWrapper, ctx context.Context) {
+	pushSender := sender.NewSender(notification_gateway.NewNotificationGatewayWrapper(cfg.Wrappers.NotificationGateway),
+		settingsService, jobber, userWrapper, newFirebaseClient(cfg, ctx))
+
+	if err := pushSender.RegisterUserPushNotificationTasks(); err != nil {
+		log.Fatal().Err(err).Msg("[Notifications] can not register push tasks")
+	}
+
+	if err := campaign.NewWorker(pushSender, jobber, userWrapper).RegisterTasks(); err != nil {
+		log.Fatal().Err(err).Msg("[Notifications] can not register campaign tasks")
+	}
+
+	if err := token.RegisterCleanupTasks(jobber); err != nil {
+		log.Fatal().Err(err).Msg("[Notifications] can not register token cleanup tasks")
+	}
+
+	if err := storage.RegisterCleanupTasks(jobber); err != nil {
+		log.Fatal().Err(err).Msg("[Notifications] can not register storage cleanup tasks")
+	}
+}
+
+// startEmailConsumer sends account emails from EmailNotificationListener topic, emails muted by user preferences
+// are skipped
+func startEmailConsumer(cfg configs.Settings, settingsService settings.IService, userWrapper user_go.IUserGoWrapper,
+	ctx context.Context) {
+	if boilerplate.GetCurrentEnvironment() == boilerplate.Ci {
+		return
+	}
+
+	if len(cfg.EmailNotificationListener.Hosts) == 0 || len(cfg.EmailNotificationListener.Topic) == 0 {
+		log.Warn().Msg("[Notifications] email notification listener is not configured")
+		return
+	}
+
+	emailSvc := mail.NewEmailService(cfg.EmailConfig.Host, cfg.EmailConfig.Port, cfg.EmailConfig.User,
+		cfg.EmailConfig.Password, cfg.EmailConfig.SenderMail, cfg.EmailConfig.SenderName)
+
+	kafka.StartEmailConsumer(ctx, cfg.EmailNotificationListener.Topic, cfg.EmailNotificationListener.GroupId,
+		cfg.EmailNotificationListener.Hosts, emailSvc, userWrapper, settingsService)
+}
+
+// startWorker launches machinery worker which runs push, campaign and cleanup tasks
+func startWorker(jobber *machinery.Server, cfg configs.Settings) {
+	if boilerplate.GetCurrentEnvironment() == boilerplate.Ci {
+		return
+	}
+
+	worker := jobber.NewWorker(workerName, cfg.Jobber.Concurrency)
+
+	go func() {
+		if err := worker.Launch(); err != nil {
+			log.Error().Err(err).Msg("[Notifications] worker stopped")
+		}
+	}()
+}